@@ -64,7 +64,7 @@ func agentLoadTest(ctx context.Context, cmd *cli.Command) error {
 	if !cmd.Bool("verbose") {
 		lksdk.SetLogger(logger.LogRLogger(logr.Discard()))
 	}
-	_ = raiseULimit()
+	tuneSystem()
 
 	params := loadtester.AgentLoadTestParams{
 		URL:             pc.URL,
@@ -0,0 +1,54 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strconv"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/livekit/livekit-cli/v2/pkg/provider"
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+// benchProviders measures how much media the sample providers produce per core, to size
+// load test workers on a given instance type without connecting to a server
+func benchProviders(ctx context.Context, cmd *cli.Command) error {
+	fmt.Printf("Benchmarking sample providers on %s/%s with %d CPUs, %s of media each\n",
+		runtime.GOOS, runtime.GOARCH, runtime.NumCPU(), cmd.Duration("media-time"))
+	results, err := provider.BenchmarkEmbedded(ctx, cmd.Duration("media-time"))
+	if err != nil {
+		return err
+	}
+
+	table := util.CreateTable().
+		Headers("Provider", "Samples", "Bitrate", "Time", "Realtime", "Allocs/Sample")
+	for _, res := range results {
+		table.Row(
+			res.Name,
+			strconv.Itoa(res.Samples),
+			fmt.Sprintf("%.0fkbps", float64(res.Bytes*8)/res.Media.Seconds()/1000),
+			res.Elapsed.Round(1000).String(),
+			fmt.Sprintf("%.0fx", res.Realtime()),
+			fmt.Sprintf("%.1f", res.AllocsPerSample()),
+		)
+	}
+	fmt.Println(table)
+	fmt.Println("Realtime is roughly how many publishers of each provider one core keeps fed, before packetizing and sending")
+	return nil
+}
@@ -1,4 +1,4 @@
-// Copyright 2022-2023 LiveKit, Inc.
+// Copyright 2025 LiveKit, Inc.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -12,8 +12,14 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+//go:build !linux
+
 package main
 
-func raiseULimit() error {
-	return nil
+import (
+	"errors"
+)
+
+func setCPUAffinity(_ []int) error {
+	return errors.New("cpu affinity is only supported on linux")
 }
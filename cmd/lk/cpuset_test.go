@@ -1,4 +1,4 @@
-// Copyright 2022-2023 LiveKit, Inc.
+// Copyright 2025 LiveKit, Inc.
 //
 // Licensed under the Apache License, Version 2.0 (the "License");
 // you may not use this file except in compliance with the License.
@@ -12,23 +12,21 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-//go:build linux || darwin || freebsd
-// +build linux darwin freebsd
-
 package main
 
 import (
-	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
-func raiseULimit() error {
-	// raise ulimit
-	var rLimit syscall.Rlimit
-	err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rLimit)
-	if err != nil {
-		return err
+func TestParseCPUList(t *testing.T) {
+	cpus, err := parseCPUList("0-3,8, 10-11,2")
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1, 2, 3, 8, 10, 11}, cpus)
+
+	for _, invalid := range []string{"", "a", "3-1", "-1", "1-"} {
+		_, err = parseCPUList(invalid)
+		require.Error(t, err, invalid)
 	}
-	rLimit.Max = 65535
-	rLimit.Cur = 65535
-	return syscall.Setrlimit(syscall.RLIMIT_NOFILE, &rLimit)
 }
@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"net/url"
 	"os"
 	"os/signal"
@@ -65,6 +66,11 @@ REQUEST_JSON is one of:
 	- ` + reflect.TypeFor[livekit.WebEgressRequest]().Name() + `
 	
 See cmd/livekit-cli/examples`
+
+	egressMeasureLatencyDescription = `Publishes an audio track that is silent except for short noise pulses, starts a
+track egress of it to a websocket sink run by this command, and times how long each
+pulse takes to come out of egress. Track egress streams audio to websockets only, so
+this is the sink it measures; egress must be able to reach --sink-url.`
 )
 
 var (
@@ -123,6 +129,40 @@ var (
 						},
 					},
 				},
+				{
+					Name:        "measure-latency",
+					Usage:       "Measure glass-to-glass latency through a track egress",
+					Description: egressMeasureLatencyDescription,
+					Before:      createEgressClient,
+					Action:      measureEgressLatency,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:     "room",
+							Usage:    "`NAME` of the room to publish the probe track to",
+							Required: true,
+						},
+						&cli.StringFlag{
+							Name:  "listen",
+							Usage: "`ADDRESS` the websocket sink listens on",
+							Value: ":8089",
+						},
+						&cli.StringFlag{
+							Name:  "sink-url",
+							Usage: "Websocket `URL` egress connects to, reaching the sink. Defaults to ws://localhost on the listen port, which only works when egress runs on this host",
+						},
+						&cli.IntFlag{
+							Name:  "probes",
+							Usage: "`NUMBER` of pulses to time",
+							Value: 10,
+						},
+						&cli.DurationFlag{
+							Name:  "interval",
+							Usage: "Time between pulses, and how long each has to come out of egress",
+							Value: 2 * time.Second,
+						},
+						jsonFlag,
+					},
+				},
 				{
 					Name:   "test-template",
 					Usage:  "See what your egress template will look like in a recording",
@@ -778,6 +818,51 @@ func testEgressTemplate(ctx context.Context, cmd *cli.Command) error {
 	return nil
 }
 
+func measureEgressLatency(ctx context.Context, cmd *cli.Command) error {
+	pc, err := loadProjectDetails(cmd)
+	if err != nil {
+		return err
+	}
+
+	sinkURL := cmd.String("sink-url")
+	if sinkURL == "" {
+		_, port, err := net.SplitHostPort(cmd.String("listen"))
+		if err != nil {
+			return fmt.Errorf("invalid listen address: %w", err)
+		}
+		sinkURL = "ws://localhost:" + port
+	}
+
+	results, err := loadtester.MeasureEgressLatency(ctx, egressClient, loadtester.EgressLatencyParams{
+		URL:       pc.URL,
+		APIKey:    pc.APIKey,
+		APISecret: pc.APISecret,
+		Room:      cmd.String("room"),
+		Listen:    cmd.String("listen"),
+		SinkURL:   sinkURL,
+		Probes:    int(cmd.Int("probes")),
+		Interval:  cmd.Duration("interval"),
+	})
+	if err != nil {
+		return err
+	}
+
+	if cmd.Bool("json") {
+		util.PrintJSON(results)
+		return nil
+	}
+	ms := func(v float64) string { return fmt.Sprintf("%.0fms", v) }
+	table := util.CreateTable().
+		Headers("Egress", "Probes", "Detected", "p50", "p95", "p99").
+		Row(results.EgressID, fmt.Sprint(results.Probes), fmt.Sprint(results.Detected),
+			ms(results.P50Ms), ms(results.P95Ms), ms(results.P99Ms))
+	fmt.Println(table)
+	if results.Detected == 0 {
+		return errors.New("no pulses came out of egress")
+	}
+	return nil
+}
+
 func printInfo(info *livekit.EgressInfo) {
 	if info.Error == "" {
 		fmt.Printf("EgressID: %v Status: %v\n", info.EgressId, info.Status)
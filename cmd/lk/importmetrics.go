@@ -0,0 +1,81 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/livekit/livekit-cli/v2/pkg/loadtester"
+)
+
+// importMetrics converts a production participants-over-time export into a scenario, so a past
+// peak can be replayed with --scenario
+func importMetrics(ctx context.Context, cmd *cli.Command) error {
+	path := cmd.Args().First()
+	if path == "" {
+		return errors.New("metrics CSV file is required")
+	}
+	samples, err := loadtester.LoadMetricsCSV(path)
+	if err != nil {
+		return err
+	}
+
+	m := loadtester.MetricsImport{
+		Resolution:     cmd.Duration("resolution"),
+		Speedup:        cmd.Float("speedup"),
+		Scale:          cmd.Float("scale"),
+		Rooms:          int(cmd.Int("rooms")),
+		PublisherRatio: cmd.Float("publisher-ratio"),
+		ChurnRate:      cmd.Float("churn-rate"),
+	}
+	if m.Speedup <= 0 || m.Scale <= 0 || m.Rooms <= 0 {
+		return errors.New("--speedup, --scale and --rooms must be positive")
+	}
+	for _, w := range []struct {
+		flag string
+		t    *time.Time
+	}{{"start", &m.Start}, {"end", &m.End}} {
+		if value := cmd.String(w.flag); value != "" {
+			if *w.t, err = time.Parse(time.RFC3339, value); err != nil {
+				return fmt.Errorf("--%s must be an RFC 3339 time, e.g. 2025-04-15T18:00:00Z", w.flag)
+			}
+		}
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	scenario, err := m.Scenario(name, samples)
+	if err != nil {
+		return err
+	}
+	output := cmd.String("output")
+	if err = scenario.Save(output,
+		fmt.Sprintf("imported from %s at %gx speed and %gx scale, replay with:", path, m.Speedup, m.Scale),
+		fmt.Sprintf("  lk load-test --room-count %d --scenario %s", m.Rooms, output),
+	); err != nil {
+		return err
+	}
+
+	video, _, subscribers := scenario.Peak()
+	fmt.Printf("Wrote %d phases to %s, peaking at %d publishers and %d subscribers in each of %d rooms\n",
+		len(scenario.Phases), output, video, subscribers, m.Rooms)
+	return nil
+}
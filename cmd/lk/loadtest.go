@@ -15,14 +15,19 @@
 package main
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"net/http"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/urfave/cli/v3"
 
 	"github.com/livekit/livekit-cli/v2/pkg/loadtester"
+	"github.com/livekit/livekit-cli/v2/pkg/provider"
+	"github.com/livekit/protocol/livekit"
 	"github.com/livekit/protocol/logger"
 	lksdk "github.com/livekit/server-sdk-go/v2"
 )
@@ -32,22 +37,343 @@ var LoadTestCommands = []*cli.Command{
 		Name:   "load-test",
 		Usage:  "Run load tests against LiveKit with simulated publishers & subscribers",
 		Action: loadTest,
+		Commands: []*cli.Command{
+			{
+				Name:   "attach",
+				Usage:  "Add synthetic participants to an existing room",
+				Action: loadTestAttach,
+				Flags: []cli.Flag{
+					roomFlag,
+					&cli.DurationFlag{
+						Name:  "duration",
+						Usage: "`TIME` duration to run, 1m, 1h (by default will run until canceled)",
+					},
+					&cli.IntFlag{
+						Name:    "video-publishers",
+						Aliases: []string{"publishers"},
+						Usage:   "`NUMBER` of participants that would publish video tracks",
+					},
+					&cli.IntFlag{
+						Name:  "audio-publishers",
+						Usage: "`NUMBER` of participants that would publish audio tracks",
+					},
+					&cli.IntFlag{
+						Name:  "subscribers",
+						Usage: "`NUMBER` of participants that would subscribe to tracks",
+					},
+					&cli.StringFlag{
+						Name:  "identity-prefix",
+						Usage: "Identity `PREFIX` of tester participants (defaults to a random prefix)",
+					},
+					&cli.StringFlag{
+						Name:  "video-resolution",
+						Usage: "Resolution `QUALITY` of video to publish (\"high\", \"medium\", or \"low\")",
+						Value: "high",
+					},
+					&cli.StringFlag{
+						Name:  "video-codec",
+						Usage: "`CODEC` of the embedded clips, \"h264\" or \"vp8\", all of them will be used when unset. \"vp9\" and \"av1\" need your own --video-file",
+					},
+					&cli.FloatFlag{
+						Name:  "num-per-second",
+						Usage: "`NUMBER` of testers to start every second",
+						Value: 5,
+					},
+					&cli.StringFlag{
+						Name:  "layout",
+						Usage: "`LAYOUT` to simulate, choose from \"speaker\", \"speaker-follow\", \"3x3\", \"4x4\", \"5x5\"",
+						Value: "speaker",
+					},
+					&cli.BoolFlag{
+						Name:  "no-simulcast",
+						Usage: "Disables simulcast publishing (simulcast is enabled by default)",
+					},
+				},
+			},
+			{
+				Name:  "presets",
+				Usage: "Discover the built-in and user defined test presets",
+				Description: "User presets are YAML files in ~/.livekit/loadtest-presets (%AppData%\\livekit\\loadtest-presets\n" +
+					"on Windows), with the same fields shown by \"lk load-test presets show\". A user preset replaces\n" +
+					"a built-in one with the same name.",
+				Commands: []*cli.Command{
+					{
+						Name:   "list",
+						Usage:  "List presets and suites",
+						Action: listPresets,
+					},
+					{
+						Name:      "show",
+						Usage:     "Show the parameters of a preset or suite",
+						ArgsUsage: "NAME",
+						Action:    showPreset,
+					},
+				},
+			},
+			{
+				Name:   "bench",
+				Usage:  "Measure how fast this machine generates the load test media, without connecting to a server",
+				Action: benchProviders,
+				Flags: []cli.Flag{
+					&cli.DurationFlag{
+						Name:  "media-time",
+						Usage: "`TIME` of media to generate from each provider",
+						Value: time.Minute,
+					},
+				},
+			},
+			{
+				Name:      "import-metrics",
+				Usage:     "Convert a CSV of production participants over time into a scenario that replays it",
+				ArgsUsage: "CSV",
+				Description: "The CSV has time, participants and optionally publishers columns, in that order or named by a\n" +
+					"header. Times are RFC 3339 or unix timestamps. Every change becomes a ramp paced to last as long as\n" +
+					"it did in production, every flat stretch a hold. Run the result with --scenario and --room-count.",
+				Action: importMetrics,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:      "output",
+						Aliases:   []string{"o"},
+						Usage:     "Scenario `FILE` to write",
+						Value:     "scenario.yaml",
+						TakesFile: true,
+					},
+					&cli.StringFlag{
+						Name:  "start",
+						Usage: "Replay from `TIME` (RFC 3339), from the first sample by default",
+					},
+					&cli.StringFlag{
+						Name:  "end",
+						Usage: "Replay until `TIME` (RFC 3339), until the last sample by default",
+					},
+					&cli.DurationFlag{
+						Name:  "resolution",
+						Usage: "Reduce samples to their peak over every `INTERVAL` of production time",
+						Value: time.Minute,
+					},
+					&cli.FloatFlag{
+						Name:  "speedup",
+						Usage: "Replay `FACTOR` times faster than production, e.g. 24 to replay a day in an hour",
+						Value: 1,
+					},
+					&cli.FloatFlag{
+						Name:  "scale",
+						Usage: "Multiply participant counts by `FACTOR`, e.g. 0.1 to replay a tenth of production load",
+						Value: 1,
+					},
+					&cli.IntFlag{
+						Name:  "rooms",
+						Usage: "Spread participants over `NUMBER` rooms, to replay with the same --room-count",
+						Value: 1,
+					},
+					&cli.FloatFlag{
+						Name:  "publisher-ratio",
+						Usage: "`FRACTION` of participants publishing audio and video, when the CSV has no publishers column",
+						Value: loadtester.DefaultPublisherRatio,
+					},
+					&cli.FloatFlag{
+						Name:  "churn-rate",
+						Usage: "Turn flat stretches into churn phases replacing this `FRACTION` of subscribers every second",
+					},
+				},
+			},
+			{
+				Name:   "probe-tracks",
+				Usage:  "Add video publishers to a single room until publishing fails or quality collapses",
+				Action: loadTestProbeTracks,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "room",
+						Usage: "`NAME` of the room (defaults to a random name)",
+					},
+					&cli.IntFlag{
+						Name:  "max-publishers",
+						Usage: "Stop after `NUMBER` publishers even if quality holds",
+						Value: loadtester.DefaultTrackProbeMaxPublishers,
+					},
+					&cli.DurationFlag{
+						Name:  "step-interval",
+						Usage: "`TIME` to wait after adding each publisher before measuring",
+						Value: 5 * time.Second,
+					},
+					&cli.FloatFlag{
+						Name:  "max-packet-loss",
+						Usage: "Packet loss `FRACTION` (0-1) on the monitoring subscriber that counts as quality collapse",
+						Value: 0.05,
+					},
+					&cli.StringFlag{
+						Name:  "video-resolution",
+						Usage: "Resolution `QUALITY` of video to publish (\"high\", \"medium\", or \"low\")",
+						Value: "high",
+					},
+					&cli.StringFlag{
+						Name:  "video-codec",
+						Usage: "`CODEC` of the embedded clips, \"h264\" or \"vp8\", all of them will be used when unset. \"vp9\" and \"av1\" need your own --video-file",
+					},
+					&cli.BoolFlag{
+						Name:  "no-simulcast",
+						Usage: "Disables simulcast publishing (simulcast is enabled by default)",
+					},
+				},
+			},
+			{
+				Name:   "probe-rooms",
+				Usage:  "Create rooms at increasing rates until creating or joining them fails or slows down",
+				Action: loadTestProbeRooms,
+				Flags: []cli.Flag{
+					&cli.FloatFlag{
+						Name:  "start-rate",
+						Usage: "`NUMBER` of rooms created per second in the first step",
+						Value: 1,
+					},
+					&cli.FloatFlag{
+						Name:  "rate-step",
+						Usage: "Multiply the rate by `FACTOR` every step",
+						Value: 1.5,
+					},
+					&cli.FloatFlag{
+						Name:  "max-rate",
+						Usage: "Stop after `NUMBER` rooms per second even if the deployment keeps up",
+						Value: 100,
+					},
+					&cli.DurationFlag{
+						Name:  "step-duration",
+						Usage: "`TIME` each step keeps creating rooms at its rate",
+						Value: 10 * time.Second,
+					},
+					&cli.FloatFlag{
+						Name:  "max-errors",
+						Usage: "`FRACTION` (0-1) of rooms failing to create or join that ends the probe",
+						Value: 0.01,
+					},
+					&cli.FloatFlag{
+						Name:  "latency-spike",
+						Usage: "End the probe when p95 latency grows to `FACTOR` times the first step's",
+						Value: 3,
+					},
+					&cli.DurationFlag{
+						Name:  "max-latency",
+						Usage: "End the probe when p95 latency exceeds `TIME`, regardless of the first step",
+					},
+					&cli.BoolFlag{
+						Name:  "no-join",
+						Usage: "Only create rooms, without joining a participant to activate each of them",
+					},
+					&cli.StringFlag{
+						Name:  "room-prefix",
+						Usage: "Name probed rooms `PREFIX`_N (defaults to a random prefix)",
+					},
+				},
+			},
+			{
+				Name:   "sip",
+				Usage:  "Place outbound calls through the SIP service, each into its own room, to load test the telephony bridge",
+				Action: loadTestSIP,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "trunk",
+						Usage:    "`SIP_TRUNK_ID` of the outbound trunk to call through",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     "call",
+						Usage:    "`NUMBER` to call, {n} is replaced with the index of the call to dial distinct numbers",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:  "number",
+						Usage: "Caller `NUMBER`, the trunk's default when unset",
+					},
+					&cli.IntFlag{
+						Name:  "calls",
+						Usage: "`NUMBER` of calls to place",
+						Value: 10,
+					},
+					&cli.FloatFlag{
+						Name:  "calls-per-second",
+						Usage: "`NUMBER` of calls to place every second",
+						Value: 1,
+					},
+					&cli.DurationFlag{
+						Name:  "hold",
+						Usage: "`TIME` each call stays up after it is answered",
+						Value: 30 * time.Second,
+					},
+					&cli.DurationFlag{
+						Name:  "ringing-timeout",
+						Usage: "`TIME` a call may ring before it counts as failed",
+						Value: 30 * time.Second,
+					},
+					&cli.StringFlag{
+						Name:  "dtmf",
+						Usage: "`DIGITS` to send when a call is answered, {n} is replaced with the index of the call and 'w' pauses for half a second",
+					},
+					&cli.DurationFlag{
+						Name:  "dtmf-interval",
+						Usage: "Resend --dtmf from the room every `TIME` while calls are up, needs --monitor",
+					},
+					&cli.BoolFlag{
+						Name:  "monitor",
+						Usage: "Join a subscriber to each call's room to measure the audio coming out of the bridge",
+					},
+					&cli.StringFlag{
+						Name:  "room-prefix",
+						Usage: "Calls go to rooms named `PREFIX`-{n} (defaults to a random prefix)",
+					},
+					&cli.FloatFlag{
+						Name:  "max-failure-rate",
+						Usage: "Fail when more than this `FRACTION` of the calls fail, e.g. 0.05 for 5%; the test fails when all calls do regardless",
+						Value: 0.05,
+					},
+				},
+			},
+		},
 		Flags: []cli.Flag{
 			&cli.IntFlag{
 				Name:  "room-count",
 				Value: 1,
 				Usage: "`room-count` is total rooms for the load testing",
 			},
+			&cli.StringFlag{
+				Name:      "room-template",
+				Usage:     "YAML `FILE` listing the max_participants, empty_timeout, departure_timeout, metadata and video_codec of test rooms, used in turn, to create them through the room service before testers join",
+				TakesFile: true,
+			},
 			&cli.StringFlag{
 				Name:  "room",
 				Usage: "`NAME` of the room (default to load-test), if there are multiple rooms will be used as prefix",
 				Value: "load-test",
 			},
+			&cli.DurationFlag{
+				Name:  "room-stagger",
+				Usage: "`TIME` to wait between starting each room, e.g. 10s (by default all rooms start immediately)",
+			},
 			&cli.DurationFlag{
 				Name:  "duration",
 				Usage: "`TIME` duration to run, 1m, 1h (by default will run until canceled)",
 				Value: 0,
 			},
+			&cli.DurationFlag{
+				Name:  "warmup",
+				Usage: "Wait `TIME` after all testers joined before collecting stats, so that the connection storm doesn't skew them. The test runs for --duration after that",
+			},
+			&cli.BoolFlag{
+				Name:  "hold",
+				Usage: "Keep the rooms populated until interrupted, replacing testers that drop out, with minimal output and no report. Useful to keep demo environments alive",
+			},
+			&cli.BoolFlag{
+				Name:  "replace-failed",
+				Usage: "Replace testers that fail to join or are disconnected for good once the rooms are populated, so long runs keep their target population. Replacements are reported",
+			},
+			&cli.IntFlag{
+				Name:  "retries",
+				Usage: "Start a tester again up to `N` times after it fails to join or publish with a transient error, like a timeout or an overloaded server. Failure rates are reported before and after retries",
+				Value: loadtester.DefaultRetries,
+			},
+			&cli.IntFlag{
+				Name:  "max-concurrent-connects",
+				Usage: "Queue testers so that at most `N` are connecting to the server at once, whatever the --num-per-second, and report how long they waited (0 for no limit)",
+			},
 			&cli.IntFlag{
 				Name:    "video-publishers",
 				Aliases: []string{"publishers"},
@@ -61,82 +387,582 @@ var LoadTestCommands = []*cli.Command{
 				Name:  "subscribers",
 				Usage: "`NUMBER` of participants that would subscribe to tracks",
 			},
+			&cli.IntFlag{
+				Name:  "data-publishers",
+				Usage: "`NUMBER` of participants that would send data messages",
+			},
+			&cli.IntFlag{
+				Name:  "screenshare-publishers",
+				Usage: "`NUMBER` of participants that would also share their screen, at high resolution and a low frame rate",
+			},
+			&cli.IntFlag{
+				Name:  "data-packet-size",
+				Usage: "Size in `BYTES` of each data message",
+				Value: loadtester.DefaultDataPacketSize,
+			},
+			&cli.FloatFlag{
+				Name:  "data-rate",
+				Usage: "Data messages per second sent by each data publisher",
+				Value: loadtester.DefaultDataRate,
+			},
+			&cli.BoolFlag{
+				Name:  "data-lossy",
+				Usage: "Send data messages over the lossy data channel instead of the reliable one",
+			},
 			&cli.StringFlag{
 				Name:  "identity-prefix",
 				Usage: "Identity `PREFIX` of tester participants (defaults to a random prefix)",
 			},
+			&cli.StringFlag{
+				Name: "scenario",
+				Usage: "Run the phases in the YAML `FILE` one after the other instead of a single ramp and hold (see cmd/lk/examples/load-test-scenario.yaml): " +
+					"ramp, hold, churn, mute_storm and teardown, each with its own tester counts and codec",
+			},
+			&cli.StringSliceFlag{
+				Name:  "set",
+				Usage: "Set the `KEY=VALUE` scenario variable, read by the --scenario file as {{ .Env.KEY }} in place of the environment variable of that name. Can be repeated",
+			},
+			&cli.IntFlag{
+				Name: "room-cycles",
+				Usage: "Create, fill and empty the same rooms `COUNT` times (0 repeats until interrupted), checking that the server " +
+					"closes each emptied room and that the recreated room starts clean; each cycle lasts --duration (15s by default)",
+			},
+			&cli.DurationFlag{
+				Name:  "cycle-interval",
+				Usage: "Minimum `TIME` from the start of one room cycle to the start of the next",
+			},
+			&cli.DurationFlag{
+				Name:  "room-close-timeout",
+				Usage: "How long an emptied room may stay open before its room cycle fails",
+				Value: loadtester.DefaultRoomCloseTimeout,
+			},
+			&cli.StringFlag{
+				Name:  "preset",
+				Usage: "`NAME` of a preset to fill in unset parameters, e.g. \"audio-plc\" (see \"lk load-test presets list\")",
+			},
+			&cli.FloatFlag{
+				Name:  "audio-packet-loss",
+				Usage: "`FRACTION` (0-1) of audio packets subscribers drop on receipt, simulating a lossy network",
+			},
 			&cli.StringFlag{
 				Name:  "video-resolution",
 				Usage: "Resolution `QUALITY` of video to publish (\"high\", \"medium\", or \"low\")",
 				Value: "high",
 			},
+			&cli.StringFlag{
+				Name:  "fairproc-profile",
+				Usage: "`NAME` of the fairproc media profile, small (the default), medium, large or one from --fairproc-profiles, implies --fairproc-rooms",
+			},
+			&cli.StringFlag{
+				Name:      "fairproc-profiles",
+				Usage:     "YAML `FILE` of fairproc profiles, replacing the built-in ones of the same name",
+				TakesFile: true,
+			},
 			&cli.IntFlag{
 				Name:  "fairproc-config-web-width",
-				Usage: "`fairproc-config-web-width` of web cam video (300 by default)",
+				Usage: "`WIDTH` of web cam video, overriding the fairproc profile",
 				Value: -1,
 			},
 			&cli.IntFlag{
 				Name:  "fairproc-config-web-height",
-				Usage: "`fairproc-config-web-hegiht` of web cam video (200 by default)",
+				Usage: "`HEIGHT` of web cam video, overriding the fairproc profile",
+				Value: -1,
+			},
+			&cli.IntFlag{
+				Name:  "fairproc-config-web-frame-rate",
+				Usage: "`FPS` of web cam video, overriding the fairproc profile",
 				Value: -1,
 			},
 			&cli.IntFlag{
 				Name:  "fairproc-config-web-bitrate",
-				Usage: "`fairproc-config-web-bitrate` of web cam video (29k bitrate by defaults)",
+				Usage: "`KBPS` of web cam video, overriding the fairproc profile",
 				Value: -1,
 			},
 			&cli.IntFlag{
 				Name:  "fairproc-config-screen-width",
-				Usage: "`fairproc-config-screen-width` of web cam video (300 by default)",
+				Usage: "`WIDTH` of screen share video, overriding the fairproc profile",
 				Value: -1,
 			},
 			&cli.IntFlag{
 				Name:  "fairproc-config-screen-height",
-				Usage: "`fairproc-config-screen-hegiht` of web cam video (200 by default)",
+				Usage: "`HEIGHT` of screen share video, overriding the fairproc profile",
+				Value: -1,
+			},
+			&cli.IntFlag{
+				Name:  "fairproc-config-screen-frame-rate",
+				Usage: "`FPS` of screen share video, overriding the fairproc profile",
 				Value: -1,
 			},
 			&cli.IntFlag{
 				Name:  "fairproc-config-screen-bitrate",
-				Usage: "`fairproc-config-screen-bitrate` of web cam video (50k bitrate by defaults)",
+				Usage: "`KBPS` of screen share video, overriding the fairproc profile",
 				Value: -1,
 			},
 			&cli.IntFlag{
 				Name:  "fairproc-config-audio-bitrate",
-				Usage: "`fairproc-config-screen-bitrate` of audio (16k bitrate by defaults)",
-				Value: 16,
+				Usage: "`KBPS` of audio, overriding the fairproc profile",
+				Value: -1,
 			},
 			&cli.BoolFlag{
 				Name:  "fairproc-rooms",
-				Usage: "`fairproc-rooms` is fairproc rooms",
+				Usage: "Simulate fairproc rooms: a webcam with audio, a proctor and a screen share publisher per room",
 				Value: false,
 			},
 			&cli.StringFlag{
 				Name:  "video-codec",
-				Usage: "`CODEC` \"h264\" or \"vp8\" \"vp9\", both will be used when unset",
+				Usage: "`CODEC` of the embedded clips, \"h264\" or \"vp8\", all of them will be used when unset. \"vp9\" and \"av1\" need your own --video-file",
 			},
 			&cli.FloatFlag{
 				Name:  "num-per-second",
 				Usage: "`NUMBER` of testers to start every second",
 				Value: 5,
 			},
+			&cli.FloatFlag{
+				Name:  "packet-loss",
+				Usage: "`FRACTION` (0-1) of RTP packets each tester drops in both directions",
+			},
+			&cli.DurationFlag{
+				Name:  "media-latency",
+				Usage: "Delay each tester's RTP packets by `TIME`, in both directions",
+			},
+			&cli.DurationFlag{
+				Name:  "jitter",
+				Usage: "Delay each tester's RTP packets by a random `TIME` up to this, in both directions",
+			},
+			&cli.DurationFlag{
+				Name:  "signal-latency",
+				Usage: "Delay each tester's signaling messages by `TIME`, in both directions, independently of --media-latency",
+			},
+			&cli.DurationFlag{
+				Name:  "signal-jitter",
+				Usage: "Delay each tester's signaling messages by a random `TIME` up to this, without reordering them",
+			},
+			&cli.StringFlag{
+				Name:  "ice-transport",
+				Usage: "Candidates testers connect over, `POLICY` \"all\" or \"relay\" to only use TURN and load test the relay path",
+				Value: "all",
+			},
+			&cli.StringSliceFlag{
+				Name:  "turn-url",
+				Usage: "`URL` of a TURN server testers use instead of the ones the server hands out, e.g. turn:turn.example.com:3478?transport=udp, needs --turn-username and --turn-credential",
+			},
+			&cli.StringFlag{
+				Name:  "turn-username",
+				Usage: "`USERNAME` for --turn-url",
+			},
+			&cli.StringFlag{
+				Name:  "turn-credential",
+				Usage: "`CREDENTIAL` for --turn-url",
+			},
+			&cli.StringSliceFlag{
+				Name:  "proxy",
+				Usage: "Connect tester signaling through the HTTP or SOCKS5 proxy at `URL`, e.g. socks5://host:1080, repeat to spread testers over several",
+			},
+			&cli.StringFlag{
+				Name:      "proxy-file",
+				Usage:     "Spread tester signaling over the proxy URLs in `FILE`, one per line",
+				TakesFile: true,
+			},
+			&cli.StringFlag{
+				Name:      "urls-file",
+				Usage:     "Spread testers over the LiveKit URLs in `FILE`, one per line, reporting stats per URL. --url also takes a comma separated list",
+				TakesFile: true,
+			},
+			&cli.StringFlag{
+				Name:  "spread-urls",
+				Usage: "How testers are spread over several URLs: \"tester\" joins them in turn so that rooms span all of them, \"room\" keeps each room on one",
+				Value: loadtester.SpreadURLsByTester,
+			},
+			&cli.StringFlag{
+				Name: "token-file",
+				Usage: "Join with the pre-minted access tokens in `FILE`, one per line and handed to testers in join order, " +
+					"instead of minting tokens during the ramp. Each tester joins the room and identity its token grants",
+				TakesFile: true,
+			},
+			&cli.StringFlag{
+				Name:  "bandwidth-cap",
+				Usage: "`BITRATE` each tester can send and receive, e.g. 1.5mbps",
+			},
+			&cli.StringSliceFlag{
+				Name: "cohort",
+				Usage: "Run a share of the testers with the SDK `FEATURES` \"adaptive-stream\", \"dynacast\", \"auto-subscribe\", \"protocol=N\" or \"none\", " +
+					"e.g. \"adaptive-stream+dynacast:3\" for a weight of 3, repeat to compare cohorts under the same load. " +
+					"Label a cohort's location with \"region=NAME\" and \"isp=NAME\", and give it its own network with " +
+					"\"loss=FRACTION\", \"latency=TIME\", \"jitter=TIME\" and \"bandwidth=BITRATE\"",
+			},
+			&cli.DurationFlag{
+				Name:  "publish-delay",
+				Usage: "`TIME` publishers wait after connecting before publishing, like clients waiting for device permissions",
+			},
+			&cli.DurationFlag{
+				Name:  "audio-publish-delay",
+				Usage: "`TIME` publishers wait after connecting before publishing audio (defaults to --publish-delay)",
+			},
+			&cli.DurationFlag{
+				Name:  "video-publish-delay",
+				Usage: "`TIME` publishers wait after connecting before publishing video (defaults to --publish-delay)",
+			},
+			&cli.StringFlag{
+				Name:  "ramp",
+				Usage: "`PROFILE` of tester start-up instead of a flat --num-per-second, choose from \"linear:10m\", \"step:50/2m\", \"spike\"",
+			},
 			&cli.StringFlag{
 				Name:  "layout",
-				Usage: "`LAYOUT` to simulate, choose from \"speaker\", \"3x3\", \"4x4\", \"5x5\"",
+				Usage: "`LAYOUT` to simulate, choose from \"speaker\", \"speaker-follow\", \"3x3\", \"4x4\", \"5x5\"",
 				Value: "speaker",
 			},
+			&cli.IntFlag{
+				Name:  "subscribe-count",
+				Usage: "Subscribers only subscribe to `N` tracks, like a paginated UI, instead of every track of the layout",
+			},
+			&cli.StringFlag{
+				Name:  "subscribe-strategy",
+				Usage: "`STRATEGY` subscribers pick their --subscribe-count tracks by, choose from \"random\", \"loudest\" (active speakers) or \"newest\"",
+				Value: loadtester.SubscribeRandom,
+			},
 			&cli.BoolFlag{
 				Name:  "no-simulcast",
 				Usage: "Disables simulcast publishing (simulcast is enabled by default)",
 			},
+			&cli.IntFlag{
+				Name:  "simulcast-layers",
+				Usage: "`NUMBER` of simulcast layers to publish, 1 to 3, ending at the video resolution",
+			},
+			&cli.StringFlag{
+				Name:  "simulcast-scale",
+				Usage: "Comma separated `FACTORS` each simulcast layer's resolution is scaled down by, lowest layer first, e.g. \"4,2,1\"",
+			},
+			&cli.StringFlag{
+				Name:  "simulcast-max-bitrate",
+				Usage: "Comma separated max `BITRATES` of each simulcast layer, lowest layer first, e.g. \"150kbps,500kbps,1.5mbps\"",
+			},
+			&cli.BoolFlag{
+				Name:  "subscribers-first",
+				Usage: "Join subscribers to empty rooms before any publishers, to measure time to first frame from publish",
+			},
+			&cli.DurationFlag{
+				Name: "publish-ramp",
+				Usage: "`TIME` over which video publishers ramp up to full bitrate, e.g. 10s: simulcast publishers enable their layers from the lowest one, " +
+					"single layer publishers drop frames to start at a fifth of their bitrate. Audio starts at full bitrate (by default all video starts at full bitrate)",
+			},
+			&cli.DurationFlag{
+				Name:  "resubscribe-interval",
+				Usage: "Make subscribers unsubscribe and resubscribe to a random track every `TIME`, measuring resubscription latency and keyframe wait",
+			},
+			&cli.DurationFlag{
+				Name:  "adaptive-cycle",
+				Usage: "Make subscribers request a different video quality for every track every `TIME`, like viewport changes with adaptive stream, measuring layer switch time",
+			},
+			&cli.DurationFlag{
+				Name:  "reconnect-interval",
+				Usage: "Force every tester to reconnect every `TIME`, measuring reconnect success rate and time until media resumes",
+			},
+			&cli.StringFlag{
+				Name:  "reconnect-mode",
+				Usage: "How testers reconnect with --reconnect-interval: `MODE` \"resume\" drops the signal connection and resumes the session, \"full\" has the server end the session so the tester rejoins",
+				Value: loadtester.ReconnectResume,
+			},
+			&cli.DurationFlag{
+				Name:  "max-media-gap",
+				Usage: "Longest `TIME` received media may pause, e.g. while a publisher reconnects, before it's reported as a violation",
+				Value: loadtester.DefaultMaxMediaGap,
+			},
+			&cli.DurationFlag{
+				Name:  "freeze-threshold",
+				Usage: "Longest `TIME` between two frames of a subscribed video track before it's reported as a freeze",
+				Value: loadtester.DefaultFreezeThreshold,
+			},
+			&cli.StringFlag{
+				Name:  "bitrate-pattern",
+				Usage: "Vary the bitrate of published video over time by dropping frames, in a `PATTERN` \"sine\", \"sawtooth\" or \"burst\", down to a fifth of the full bitrate",
+			},
+			&cli.DurationFlag{
+				Name:  "bitrate-pattern-period",
+				Usage: "`TIME` each cycle of --bitrate-pattern lasts",
+				Value: loadtester.DefaultBitratePatternPeriod,
+			},
+			&cli.StringFlag{
+				Name:  "backup-codec",
+				Usage: "`CODEC` \"vp8\" or \"h264\" video publishers register as a backup of the --video-codec, and publish when the server asks for it",
+			},
+			&cli.BoolFlag{
+				Name:  "subscribe-backup-only",
+				Usage: "Subscribers negotiate only the --backup-codec, so that the server falls back to it for every video track",
+			},
+			&cli.FloatFlag{
+				Name:  "permission-update-rate",
+				Usage: "Promote and demote random subscribers' publish permission `NUMBER` times per second in each room, measuring how long updates take to reach testers and media gaps",
+			},
+			&cli.StringFlag{
+				Name:  "rotate-api-key",
+				Usage: "Second `KEY` of the project that testers joining after --rotate-after use, checking that sessions joined with the first key stay connected",
+			},
+			&cli.StringFlag{
+				Name:  "rotate-api-secret",
+				Usage: "`SECRET` of --rotate-api-key",
+			},
+			&cli.DurationFlag{
+				Name:  "rotate-after",
+				Usage: "How long after the start of the test to rotate to --rotate-api-key",
+			},
+			&cli.FloatFlag{
+				Name:  "fuzz-rate",
+				Usage: "`FRACTION` of subscribers (0-1) that also send malformed and out-of-order signaling messages, verifying the server rejects them without affecting other participants. Needs --unsafe-fuzz",
+			},
+			&cli.BoolFlag{
+				Name:  "unsafe-fuzz",
+				Usage: "Allow --fuzz-rate. Only use against servers you own, as fuzzing may destabilize them",
+			},
+			&cli.FloatFlag{
+				Name:  "dynacast-check",
+				Usage: "`FRACTION` of video publishers (0-1) that subscribers leave unsubscribed, verifying the server pauses their layers and reporting the upstream bitrate wasted on them",
+			},
+			&cli.BoolFlag{
+				Name:  "e2ee",
+				Usage: "Publishers encrypt their frames and subscribers decrypt them with --e2ee-key, reporting decryption failures. Video is published as vp8",
+			},
+			&cli.StringFlag{
+				Name:  "e2ee-key",
+				Usage: "Shared `PASSPHRASE` of --e2ee, the same as client SDKs use for shared key encryption",
+			},
+			&cli.BoolFlag{
+				Name:  "mark-synthetic",
+				Usage: "Set the \"lk.synthetic\" attribute on tester participants so server side analytics can filter them out",
+			},
+			&cli.StringFlag{
+				Name:  "participant-metadata",
+				Usage: "`METADATA` of tester participants, for webhooks, agents and permission logic keyed off it",
+			},
+			&cli.StringSliceFlag{
+				Name:  "attr",
+				Usage: "Set the `KEY=VALUE` attribute on tester participants. Can be repeated",
+			},
 			&cli.BoolFlag{
 				Name:  "simulate-speakers",
-				Usage: "Fire random speaker events to simulate speaker changes",
+				Usage: "Have publishers take turns talking, silencing audio publishers outside of their turns, to simulate speaker changes",
+			},
+			&cli.StringFlag{
+				Name:  "talk-duration",
+				Usage: "`RANGE` each turn of a simulated speaker lasts, e.g. 5s-20s (default), or a fixed duration",
+			},
+			&cli.FloatFlag{
+				Name:  "overlap-prob",
+				Usage: "`PROBABILITY` (0-1) that another simulated speaker talks over part of a turn",
+			},
+			&cli.FloatFlag{
+				Name:  "overshoot-threshold",
+				Usage: "`FRACTION` over their target bitrate at which published tracks are flagged in the report (default 0.2)",
+			},
+			&cli.FloatFlag{
+				Name:  "duplicate-join-rate",
+				Usage: "`FRACTION` of subscribers (0-1) that get a second tester joining with the same identity, to verify duplicate identity eviction",
+			},
+			&cli.IntFlag{
+				Name:  "hidden-subscribers",
+				Usage: "`NUMBER` of subscribers per room that join as hidden participants, verifying no other tester can see them",
+			},
+			&cli.DurationFlag{
+				Name:  "service-poll-interval",
+				Usage: "Poll egress, ingress and SIP status for the test rooms every `TIME` and include state transitions in the report",
+			},
+			&cli.IntFlag{
+				Name:  "quota-participants",
+				Usage: "Warn when a LiveKit Cloud test would exceed the plan's concurrent participant `LIMIT`",
+			},
+			&cli.StringFlag{
+				Name:  "quota-bandwidth",
+				Usage: "Warn when a LiveKit Cloud test is estimated to exceed the plan's bandwidth `LIMIT`, e.g. 1gbps",
+			},
+			&cli.StringFlag{
+				Name:  "coordinator",
+				Usage: "Coordinate a distributed test, listening for workers on `ADDRESS`, e.g. :7890",
+			},
+			&cli.IntFlag{
+				Name:  "workers",
+				Usage: "`NUMBER` of workers the coordinator waits for before starting",
+				Value: 1,
+			},
+			&cli.BoolFlag{
+				Name:  "worker",
+				Usage: "Run as a worker of a distributed test, taking its share of testers from --coordinator-url",
+			},
+			&cli.StringFlag{
+				Name:  "coordinator-url",
+				Usage: "`URL` of the coordinator to join as a worker, e.g. ws://10.0.0.1:7890",
+			},
+			&cli.StringFlag{
+				Name:    "worker-secret",
+				Usage:   "`SECRET` shared by the coordinator and its workers, which it rejects without it",
+				Sources: cli.EnvVars("LIVEKIT_WORKER_SECRET"),
+			},
+			&cli.StringFlag{
+				Name:  "metrics-addr",
+				Usage: "Serve Prometheus /metrics, /healthz and /readyz on `ADDRESS`, e.g. :9100, while the test runs",
+			},
+			&cli.StringFlag{
+				Name:  "stats-output",
+				Usage: "Also write final stats as `FORMAT` \"json\" or \"csv\", for CI pipelines",
+			},
+			&cli.StringFlag{
+				Name:  "stats-file",
+				Usage: "Write --stats-output to `PATH` instead of stdout",
+			},
+			&cli.StringFlag{
+				Name:  "behind-policy",
+				Usage: "What to do when publishers produce media later than real time: `POLICY` \"log\" a warning, \"slow\" the ramp until they catch up, or \"fail\" the test",
+				Value: loadtester.BehindPolicyLog,
+			},
+			&cli.DurationFlag{
+				Name:  "behind-threshold",
+				Usage: "How late a published frame can be before the publisher counts as behind",
+				Value: loadtester.DefaultBehindThreshold,
+			},
+			&cli.DurationFlag{
+				Name:  "latency-interval",
+				Usage: "How often publishers send a timestamp for subscribers to measure end-to-end latency, 0 to disable. Distributed tests need synchronized worker clocks",
+				Value: loadtester.DefaultLatencyInterval,
+			},
+			&cli.StringFlag{
+				Name:  "webhook-addr",
+				Usage: "Receive the server's webhooks on `ADDRESS` during the test and include them in the results, e.g. :8088. The server must be configured to send webhooks there",
+			},
+			&cli.DurationFlag{
+				Name:  "webhook-wait",
+				Usage: "How long to keep receiving webhooks after testers leave, before reporting",
+				Value: loadtester.DefaultWebhookWait,
+			},
+			&cli.StringSliceFlag{
+				Name:  "report-role",
+				Usage: "Include testers with `ROLE` in the report: pub-video, pub-audio, pub-av, pub-data or sub. Can be repeated; defaults to sub",
+			},
+			&cli.BoolFlag{
+				Name:  "detailed",
+				Usage: "Print per-participant tables along with the per-room breakdown when testing several rooms",
+			},
+			&cli.StringSliceFlag{
+				Name:  "stats-sink",
+				Usage: "Write final stats to `SINK`: console, json[=path], csv[=path], prometheus=<pushgateway url> or otlp=<collector url>. Can be repeated; defaults to console",
+			},
+			&cli.FloatFlag{
+				Name:  "max-packet-loss",
+				Usage: "Fail with a non-zero exit code if reported testers lose more than `FRACTION` (0-1) of packets combined",
+			},
+			&cli.IntFlag{
+				Name:  "max-connect-failures",
+				Usage: "Fail with a non-zero exit code if more than `NUMBER` testers fail to connect or publish",
+			},
+			&cli.StringFlag{
+				Name:  "min-bitrate",
+				Usage: "Fail with a non-zero exit code if reported testers receive less than `BITRATE` on average, e.g. 1.5mbps",
+			},
+			&cli.StringFlag{
+				Name:  "report-url",
+				Usage: "POST the final results as JSON to `URL`, retrying on failure",
+			},
+			&cli.StringSliceFlag{
+				Name:  "report-header",
+				Usage: "Add `HEADER` (\"Name: value\") to requests to --report-url, e.g. for authorization. Can be repeated",
+			},
+			&cli.DurationFlag{
+				Name:  "report-interval",
+				Usage: "Also POST snapshots of the running test to --report-url at this interval",
+			},
+			&cli.BoolFlag{
+				Name:  "sign-results",
+				Usage: "Sign json and csv result files with the project's API secret, writing a .sig file next to each. Check them with lk perf verify",
+			},
+			&cli.BoolFlag{
+				Name:  "history",
+				Usage: "Add the results to the history in ~/.livekit/" + historyDirName + ", to browse and compare runs with lk perf serve",
+			},
+			&cli.BoolFlag{
+				Name:  "no-random-offset",
+				Usage: "Start every publisher at the beginning of its video file, instead of at a random keyframe",
+			},
+			&cli.StringFlag{
+				Name:  "run-id",
+				Usage: "Label published track names and stream IDs with `ID` and the tester index, to find testers in server logs",
+			},
+			&cli.StringSliceFlag{
+				Name:  "video-file",
+				Usage: "Publish video from IVF (VP8/VP9/AV1), MP4, H.264 Annex B or raw Y4M `FILE`s instead of the embedded clips, repeat for up to 3 simulcast layers, lowest quality first",
+			},
+			&cli.StringFlag{
+				Name:  "test-pattern",
+				Usage: "Publish a generated test pattern with a burned-in frame counter and clock instead of the embedded clips, `SIZE` being WIDTHxHEIGHT[@FPS], e.g. 1280x720@30. Encoded live to --video-codec vp8 or vp9",
+				// encoding needs libvpx, which release builds don't link
+				Hidden: !provider.CanEncodeVideo,
+			},
+			&cli.StringFlag{
+				Name:  "audio-file",
+				Usage: "Publish audio from an Ogg Opus `FILE` instead of the embedded clips",
+			},
+			&cli.BoolFlag{
+				Name:  "audio-dtx",
+				Usage: "Publish audio with DTX, sending a frame only every 400ms while silent",
+			},
+			&cli.BoolFlag{
+				Name:  "audio-red",
+				Usage: "Let the server send redundant audio (RED) to subscribers that support it, --audio-red=false to disable",
+				Value: true,
+			},
+			&cli.BoolFlag{
+				Name:  "audio-stereo",
+				Usage: "Publish stereo audio, generated unless --audio-file is stereo",
+			},
+			&cli.StringFlag{
+				Name:  "room-downlink-cap",
+				Usage: "Cap the combined downlink of each room's subscribers at `BITRATE`, e.g. 10mbps, emulating a shared office uplink",
+			},
+			&cli.StringFlag{
+				Name:  "state-log",
+				Usage: "Write every tester's lifecycle state transitions to `FILE` as JSON lines",
+			},
+			&cli.StringFlag{
+				Name:  "error-log-dir",
+				Usage: "Testers that fail to connect, drop or reconnect after an ICE failure write their recent signaling, ICE candidates and disconnect reason to a file named by identity in `DIR`",
+			},
+			&cli.StringFlag{
+				Name:      "record-scenario",
+				Usage:     "Save the phases the run went through, with the time each actually lasted, as a scenario in `FILE` that replays it with --scenario",
+				TakesFile: true,
+			},
+			&cli.StringFlag{
+				Name:      "checkpoint",
+				Usage:     "Write the connected testers and their stats to `FILE` every --checkpoint-interval, to resume a crashed run with --resume",
+				TakesFile: true,
+			},
+			&cli.DurationFlag{
+				Name:  "checkpoint-interval",
+				Usage: "`TIME` between checkpoints",
+				Value: loadtester.DefaultCheckpointInterval,
+			},
+			&cli.StringFlag{
+				Name:      "resume",
+				Usage:     "Reattach to the rooms of the run checkpointed in `FILE` without ramping up again, for the rest of --duration",
+				TakesFile: true,
+			},
+			&cli.StringFlag{
+				Name:  "pprof",
+				Usage: "Serve CPU, heap, goroutine and mutex profiles of the tester process on `ADDRESS`, e.g. :6060 (loopback only unless a host is given), to check whether the tester machine is the bottleneck",
+			},
+			&cli.StringFlag{
+				Name:  "cpu-affinity",
+				Usage: "Restrict the whole tester process to a CPU `LIST`, e.g. 0-7 or 0,2,4 (linux only). All of its threads share the set, and GOMAXPROCS is sized to match",
+			},
+			&cli.IntFlag{
+				Name:  "gomaxprocs",
+				Usage: "`NUMBER` of OS threads executing Go code simultaneously (defaults to the number of CPUs in --cpu-affinity or available)",
 			},
 			&cli.BoolFlag{
 				Name:   "run-all",
-				Usage:  "Runs set list of load test cases",
+				Usage:  "Runs the matrices of the standard suite, listed by \"lk load-test presets show suite\"",
 				Hidden: true,
 			},
+			&cli.StringFlag{
+				Name:  "matrix",
+				Usage: "Run every combination of the `DIMENSIONS` for --duration each and compare them, e.g. \"codecs=vp8,h264;resolutions=high,medium\". Dimensions are media (audio or video), codecs, resolutions, publishers and subscribers, missing ones are taken from the other flags",
+			},
 		},
 	},
 }
@@ -150,12 +976,38 @@ func loadTest(ctx context.Context, cmd *cli.Command) error {
 	if !cmd.Bool("verbose") {
 		lksdk.SetLogger(logger.LogRLogger(logr.Discard()))
 	}
-	_ = raiseULimit()
+	tuneSystem()
+	if err := applyCPUSettings(cmd.String("cpu-affinity"), int(cmd.Int("gomaxprocs"))); err != nil {
+		return err
+	}
+	stopPprof, err := servePprof(cmd.String("pprof"))
+	if err != nil {
+		return err
+	}
+	defer stopPprof()
+
+	if cmd.Bool("worker") {
+		if cmd.String("coordinator-url") == "" {
+			return fmt.Errorf("--coordinator-url is required for workers")
+		}
+		if cmd.String("worker-secret") == "" {
+			return fmt.Errorf("--worker-secret is required for workers")
+		}
+		return loadtester.RunWorker(ctx, cmd.String("coordinator-url"), cmd.String("worker-secret"), loadtester.TesterParams{
+			URL:       pc.URL,
+			APIKey:    pc.APIKey,
+			APISecret: pc.APISecret,
+		})
+	}
+	if cmd.String("coordinator") != "" && cmd.String("worker-secret") == "" {
+		return fmt.Errorf("--worker-secret is required for the coordinator")
+	}
 
 	params := loadtester.Params{
 		VideoResolution:               cmd.String("video-resolution"),
 		VideoCodec:                    cmd.String("video-codec"),
 		Duration:                      cmd.Duration("duration"),
+		Warmup:                        cmd.Duration("warmup"),
 		NumPerSecond:                  cmd.Float("num-per-second"),
 		Simulcast:                     !cmd.Bool("no-simulcast"),
 		SimulateSpeakers:              cmd.Bool("simulate-speakers"),
@@ -168,40 +1020,579 @@ func loadTest(ctx context.Context, cmd *cli.Command) error {
 		FairprocConfigScreenFrameRate: int(cmd.Int("fairproc-config-screen-frame-rate")),
 		FairprocConfigScreenBitrate:   int(cmd.Int("fairproc-config-screen-bitrate")),
 		FairprocAudioBitrate:          int(cmd.Int("fairproc-config-audio-bitrate")),
-		IsFairproc:                    bool(cmd.Bool("fairproc-rooms")),
+		IsFairproc:                    cmd.Bool("fairproc-rooms") || cmd.String("fairproc-profile") != "",
+		RoomCount:                     int(cmd.Int("room-count")),
+		RoomStagger:                   cmd.Duration("room-stagger"),
+		SubscribersFirst:              cmd.Bool("subscribers-first"),
+		ServicePollInterval:           cmd.Duration("service-poll-interval"),
+		DuplicateJoinRate:             cmd.Float("duplicate-join-rate"),
+		HiddenSubscribers:             int(cmd.Int("hidden-subscribers")),
+		StateLogPath:                  cmd.String("state-log"),
+		ErrorLogDir:                   cmd.String("error-log-dir"),
+		RecordScenarioPath:            cmd.String("record-scenario"),
+		CheckpointPath:                cmd.String("checkpoint"),
+		CheckpointInterval:            cmd.Duration("checkpoint-interval"),
+		StatsOutput:                   cmd.String("stats-output"),
+		StatsFile:                     cmd.String("stats-file"),
+		BehindPolicy:                  cmd.String("behind-policy"),
+		BehindThreshold:               cmd.Duration("behind-threshold"),
+		LatencyInterval:               cmd.Duration("latency-interval"),
+		WebhookAddr:                   cmd.String("webhook-addr"),
+		WebhookWait:                   cmd.Duration("webhook-wait"),
+		DataPacketSize:                int(cmd.Int("data-packet-size")),
+		DataRate:                      cmd.Float("data-rate"),
+		DataLossy:                     cmd.Bool("data-lossy"),
+		MetricsAddr:                   cmd.String("metrics-addr"),
+		PermissionUpdateRate:          cmd.Float("permission-update-rate"),
+		Detailed:                      cmd.Bool("detailed"),
+		SignResults:                   cmd.Bool("sign-results"),
+		RotateAPIKey:                  cmd.String("rotate-api-key"),
+		RotateAPISecret:               cmd.String("rotate-api-secret"),
+		RotateAfter:                   cmd.Duration("rotate-after"),
+		ReportInterval:                cmd.Duration("report-interval"),
+		FuzzRate:                      cmd.Float("fuzz-rate"),
+		UnsafeFuzz:                    cmd.Bool("unsafe-fuzz"),
+		Hold:                          cmd.Bool("hold"),
+		ReplaceFailed:                 cmd.Bool("replace-failed"),
+		Retries:                       int(cmd.Int("retries")),
+		MaxConcurrentConnects:         int(cmd.Int("max-concurrent-connects")),
 		TesterParams: loadtester.TesterParams{
-			URL:            pc.URL,
-			APIKey:         pc.APIKey,
-			APISecret:      pc.APISecret,
-			Room:           cmd.String("room"),
-			IdentityPrefix: cmd.String("identity-prefix"),
-			Layout:         loadtester.LayoutFromString(cmd.String("layout")),
+			URL:                 pc.URL,
+			APIKey:              pc.APIKey,
+			APISecret:           pc.APISecret,
+			Room:                cmd.String("room"),
+			IdentityPrefix:      cmd.String("identity-prefix"),
+			Layout:              loadtester.LayoutFromString(cmd.String("layout")),
+			SubscribeCount:      int(cmd.Int("subscribe-count")),
+			SubscribeStrategy:   cmd.String("subscribe-strategy"),
+			PublishRamp:         cmd.Duration("publish-ramp"),
+			ResubscribeInterval: cmd.Duration("resubscribe-interval"),
+			AdaptiveCycle:       cmd.Duration("adaptive-cycle"),
+			ReconnectInterval:   cmd.Duration("reconnect-interval"),
+			ReconnectMode:       cmd.String("reconnect-mode"),
+			MaxMediaGap:         cmd.Duration("max-media-gap"),
+			FreezeThreshold:     cmd.Duration("freeze-threshold"),
+			MarkSynthetic:       cmd.Bool("mark-synthetic"),
+			AudioPacketLoss:     cmd.Float("audio-packet-loss"),
+			VideoFiles:          cmd.StringSlice("video-file"),
+			AudioFile:           cmd.String("audio-file"),
+			AudioDTX:            cmd.Bool("audio-dtx"),
+			AudioStereo:         cmd.Bool("audio-stereo"),
+			AudioDisableRED:     !cmd.Bool("audio-red"),
+			RunID:               cmd.String("run-id"),
+			RandomOffset:        !cmd.Bool("no-random-offset"),
+			Impairment: loadtester.Impairment{
+				PacketLoss: cmd.Float("packet-loss"),
+				Latency:    cmd.Duration("media-latency"),
+				Jitter:     cmd.Duration("jitter"),
+			},
+			SignalImpairment: loadtester.SignalImpairment{
+				Latency: cmd.Duration("signal-latency"),
+				Jitter:  cmd.Duration("signal-jitter"),
+			},
 		},
 	}
 
+	if err := loadtester.ValidateStatsOutput(params.StatsOutput); err != nil {
+		return err
+	}
+	if err := loadtester.ValidateBehindPolicy(params.BehindPolicy); err != nil {
+		return err
+	}
+	if err := loadtester.ValidateReconnectMode(params.ReconnectMode); err != nil {
+		return err
+	}
+	if err := loadtester.ValidateRotation(params); err != nil {
+		return err
+	}
+	for _, name := range cmd.StringSlice("report-role") {
+		role, err := loadtester.ParseRole(name)
+		if err != nil {
+			return err
+		}
+		params.ReportRoles = append(params.ReportRoles, role)
+	}
+	for _, spec := range cmd.StringSlice("stats-sink") {
+		sink, err := loadtester.ParseStatsSink(spec)
+		if err != nil {
+			return err
+		}
+		params.StatsSinks = append(params.StatsSinks, sink)
+	}
+	if u := cmd.String("report-url"); u != "" {
+		sink := &loadtester.ReportSink{URL: u, Header: make(http.Header)}
+		for _, spec := range cmd.StringSlice("report-header") {
+			if err := loadtester.ParseReportHeader(sink.Header, spec); err != nil {
+				return err
+			}
+		}
+		params.Report = sink
+	}
+	if cmd.Bool("history") {
+		if params.HistoryDir, err = defaultHistoryDir(); err != nil {
+			return err
+		}
+	}
+	params.CloudQuota.MaxParticipants = int(cmd.Int("quota-participants"))
+	if val := cmd.String("quota-bandwidth"); val != "" {
+		if params.CloudQuota.MaxBandwidth, err = loadtester.ParseBitrate(val); err != nil {
+			return err
+		}
+	}
+	if val := cmd.String("room-downlink-cap"); val != "" {
+		if params.RoomDownlinkCap, err = loadtester.ParseBitrate(val); err != nil {
+			return err
+		}
+	}
+	params.Thresholds.MaxPacketLoss = cmd.Float("max-packet-loss")
+	if cmd.IsSet("max-connect-failures") {
+		maxFailures := int(cmd.Int("max-connect-failures"))
+		params.Thresholds.MaxConnectFailures = &maxFailures
+	}
+	if val := cmd.String("min-bitrate"); val != "" {
+		if params.Thresholds.MinBitrate, err = loadtester.ParseBitrate(val); err != nil {
+			return err
+		}
+	}
+	if err = loadtester.ValidateThresholds(params.Thresholds); err != nil {
+		return err
+	}
+	params.AudioPublishDelay = cmd.Duration("publish-delay")
+	params.VideoPublishDelay = cmd.Duration("publish-delay")
+	if cmd.IsSet("audio-publish-delay") {
+		params.AudioPublishDelay = cmd.Duration("audio-publish-delay")
+	}
+	if cmd.IsSet("video-publish-delay") {
+		params.VideoPublishDelay = cmd.Duration("video-publish-delay")
+	}
+	if val := cmd.String("bandwidth-cap"); val != "" {
+		if params.Impairment.BandwidthCap, err = loadtester.ParseBitrate(val); err != nil {
+			return err
+		}
+	}
+	if params.ICETransportPolicy, err = loadtester.ParseICETransport(cmd.String("ice-transport")); err != nil {
+		return err
+	}
+	if urls := cmd.StringSlice("turn-url"); len(urls) > 0 {
+		turn, err := loadtester.NewTURNServer(urls, cmd.String("turn-username"), cmd.String("turn-credential"))
+		if err != nil {
+			return err
+		}
+		params.ICEServers = []*livekit.ICEServer{turn}
+	} else if cmd.IsSet("turn-username") || cmd.IsSet("turn-credential") {
+		return fmt.Errorf("--turn-username and --turn-credential need --turn-url")
+	}
+	for _, val := range cmd.StringSlice("proxy") {
+		proxy, err := loadtester.ParseProxy(val)
+		if err != nil {
+			return err
+		}
+		params.Proxies = append(params.Proxies, proxy)
+	}
+	if path := cmd.String("proxy-file"); path != "" {
+		proxies, err := loadtester.LoadProxies(path)
+		if err != nil {
+			return err
+		}
+		params.Proxies = append(params.Proxies, proxies...)
+	}
+	if strings.Contains(pc.URL, ",") {
+		if params.URLs, err = loadtester.ParseURLs(pc.URL); err != nil {
+			return err
+		}
+		// API calls go to the first
+		params.URL = params.URLs[0]
+	}
+	if path := cmd.String("urls-file"); path != "" {
+		urls, err := loadtester.LoadURLs(path)
+		if err != nil {
+			return err
+		}
+		params.URLs = append(params.URLs, urls...)
+	}
+	params.SpreadURLs = cmd.String("spread-urls")
+	if err := loadtester.ValidateURLs(params); err != nil {
+		return err
+	}
+	if path := cmd.String("token-file"); path != "" {
+		if cmd.String("coordinator") != "" {
+			return fmt.Errorf("--token-file cannot be combined with --coordinator")
+		}
+		if params.Tokens, err = loadtester.LoadTokens(path); err != nil {
+			return err
+		}
+	}
+	if path := cmd.String("room-template"); path != "" {
+		if cmd.IsSet("room-cycles") {
+			return fmt.Errorf("--room-template cannot be combined with --room-cycles")
+		}
+		if params.RoomTemplate, err = loadtester.LoadRoomTemplate(path); err != nil {
+			return err
+		}
+	}
+	params.Metadata = cmd.String("participant-metadata")
+	if params.Attributes, err = loadtester.ParseAttributes(cmd.StringSlice("attr")); err != nil {
+		return err
+	}
+	if len(params.Tokens) > 0 && (params.Metadata != "" || len(params.Attributes) > 0) {
+		return fmt.Errorf("--participant-metadata and --attr cannot be combined with --token-file, set them when minting the tokens")
+	}
+	for _, val := range cmd.StringSlice("cohort") {
+		cohort, err := loadtester.ParseCohort(val)
+		if err != nil {
+			return err
+		}
+		params.Cohorts = append(params.Cohorts, cohort)
+	}
+	if val := cmd.String("talk-duration"); val != "" {
+		if params.TalkDuration, err = loadtester.ParseTalkDuration(val); err != nil {
+			return err
+		}
+	}
+	params.OverlapProb = cmd.Float("overlap-prob")
+	params.OvershootThreshold = cmd.Float("overshoot-threshold")
+	if err := loadtester.ValidateOvershootThreshold(params); err != nil {
+		return err
+	}
+	if err := loadtester.ValidateSpeakerSimulation(params); err != nil {
+		return err
+	}
+	if val := cmd.String("ramp"); val != "" {
+		if params.Ramp, err = loadtester.ParseRampProfile(val); err != nil {
+			return err
+		}
+	}
+	if cmd.IsSet("set") && cmd.String("scenario") == "" {
+		return fmt.Errorf("--set needs a --scenario")
+	}
+
 	if cmd.Bool("run-all") {
 		// leave out room name and pub/sub counts
 		if params.Duration == 0 {
 			params.Duration = time.Second * 15
 		}
-		test := loadtester.NewLoadTest(params)
-		return test.RunSuite(ctx)
+		return loadtester.NewLoadTest(params).RunMatrix(ctx, loadtester.StandardSuite()...)
 	}
 
 	params.VideoPublishers = int(cmd.Int("video-publishers"))
 	params.AudioPublishers = int(cmd.Int("audio-publishers"))
+	params.DataPublishers = int(cmd.Int("data-publishers"))
+	params.ScreenSharePublishers = int(cmd.Int("screenshare-publishers"))
+	params.DynacastCheck = cmd.Float("dynacast-check")
 	params.Subscribers = int(cmd.Int("subscribers"))
 
+	if name := cmd.String("preset"); name != "" {
+		presets, err := loadPresets()
+		if err != nil {
+			return err
+		}
+		preset, err := loadtester.FindPreset(presets, name)
+		if err != nil {
+			return err
+		}
+		if err = preset.Apply(&params); err != nil {
+			return err
+		}
+	}
+
 	if params.IsFairproc {
-		if params.FairprocAudioBitrate == -1 || params.FairprocConfigScreenHeight == -1 || params.FairprocConfigScreenWidth == -1 ||
-			params.FairprocConfigWebBitrate == -1 || params.FairprocConfigWebHieght == -1 || params.FairprocConfigWebWidth == -1 {
-			return fmt.Errorf("fairproc missing required files")
-		} else {
-			params.AudioPublishers = 2
-			params.VideoPublishers = 3
+		profiles, err := loadtester.LoadFairprocProfiles(cmd.String("fairproc-profiles"))
+		if err != nil {
+			return err
+		}
+		profile, err := loadtester.FindFairprocProfile(profiles, cmp.Or(cmd.String("fairproc-profile"), loadtester.DefaultFairprocProfile))
+		if err != nil {
+			return err
+		}
+		profile.Apply(&params)
+		if err = loadtester.ValidateFairproc(params); err != nil {
+			return err
+		}
+		params.AudioPublishers = 2
+		params.VideoPublishers = 3
+	}
+
+	if err := loadtester.ValidateDataParams(params); err != nil {
+		return err
+	}
+	if params.RotateAPIKey != "" && (cmd.String("scenario") != "" || cmd.String("coordinator") != "") {
+		return fmt.Errorf("--rotate-api-key cannot be combined with --scenario or --coordinator")
+	}
+	if cmd.Bool("e2ee") {
+		if params.E2EEKey = cmd.String("e2ee-key"); params.E2EEKey == "" {
+			return fmt.Errorf("--e2ee needs --e2ee-key")
+		}
+		if params.VideoCodec == "" {
+			params.VideoCodec = "vp8"
+		}
+	} else if cmd.IsSet("e2ee-key") {
+		return fmt.Errorf("--e2ee-key needs --e2ee")
+	}
+	if err := loadtester.ValidateE2EE(params); err != nil {
+		return err
+	}
+	if err := loadtester.ValidateFuzz(params); err != nil {
+		return err
+	}
+	if params.FuzzRate > 0 && (cmd.String("scenario") != "" || cmd.String("coordinator") != "") {
+		return fmt.Errorf("--fuzz-rate cannot be combined with --scenario or --coordinator")
+	}
+	if params.Hold && (params.Duration > 0 || cmd.String("scenario") != "" || cmd.String("coordinator") != "") {
+		return fmt.Errorf("--hold runs until interrupted, and cannot be combined with --duration, --scenario or --coordinator")
+	}
+	if (params.Hold || params.ReplaceFailed) && params.DuplicateJoinRate > 0 {
+		return fmt.Errorf("--hold and --replace-failed cannot be combined with --duplicate-join-rate, which disconnects testers on purpose")
+	}
+	if params.Retries < 0 {
+		return fmt.Errorf("--retries cannot be negative")
+	}
+	if params.MaxConcurrentConnects < 0 {
+		return fmt.Errorf("--max-concurrent-connects cannot be negative")
+	}
+	if params.BitratePattern, err = loadtester.ParseBitratePattern(cmd.String("bitrate-pattern")); err != nil {
+		return err
+	}
+	if params.BitratePatternPeriod = cmd.Duration("bitrate-pattern-period"); params.BitratePattern != "" && params.BitratePatternPeriod <= 0 {
+		return fmt.Errorf("--bitrate-pattern-period must be positive")
+	}
+	params.BackupCodec = cmd.String("backup-codec")
+	params.SubscribeBackupOnly = cmd.Bool("subscribe-backup-only")
+	if err := loadtester.ValidateBackupCodec(params); err != nil {
+		return err
+	}
+	if params.Warmup < 0 {
+		return fmt.Errorf("--warmup cannot be negative")
+	}
+	if params.Warmup > 0 && (cmd.String("scenario") != "" || cmd.IsSet("room-cycles")) {
+		return fmt.Errorf("--warmup cannot be combined with --scenario or --room-cycles")
+	}
+	if params.ReplaceFailed && cmd.String("scenario") != "" {
+		return fmt.Errorf("--replace-failed cannot be combined with --scenario")
+	}
+	if err := loadtester.ValidateDynacastCheck(params); err != nil {
+		return err
+	}
+	if params.DynacastCheck > 0 && (cmd.String("scenario") != "" || cmd.String("coordinator") != "") {
+		return fmt.Errorf("--dynacast-check cannot be combined with --scenario or --coordinator")
+	}
+	if params.SimulcastLadder, err = loadtester.ParseSimulcastLadder(
+		int(cmd.Int("simulcast-layers")), cmd.String("simulcast-scale"), cmd.String("simulcast-max-bitrate"),
+	); err != nil {
+		return err
+	}
+	if params.TestPattern, err = loadtester.ParseTestPattern(cmd.String("test-pattern")); err != nil {
+		return err
+	}
+	if params.TestPattern != nil && len(params.VideoFiles) > 0 {
+		return fmt.Errorf("--test-pattern cannot be combined with --video-file")
+	}
+	if params.SimulcastLadder != nil && (!params.Simulcast || len(params.VideoFiles) > 0 || params.TestPattern != nil) {
+		return fmt.Errorf("simulcast layers cannot be configured with --no-simulcast, --video-file or --test-pattern")
+	}
+	if params.ScreenSharePublishers > 0 && cmd.String("scenario") != "" {
+		return fmt.Errorf("--screenshare-publishers cannot be combined with --scenario")
+	}
+
+	if path := cmd.String("resume"); path != "" {
+		if params.Resume, err = loadtester.LoadCheckpoint(path); err != nil {
+			return err
+		}
+		if params.CheckpointPath == "" {
+			// keep checkpointing where the crashed run did
+			params.CheckpointPath = path
+		}
+	}
+	if params.CheckpointPath != "" || params.Resume != nil {
+		if cmd.String("scenario") != "" || cmd.String("coordinator") != "" || cmd.IsSet("room-cycles") {
+			return fmt.Errorf("--checkpoint and --resume cannot be combined with --scenario, --coordinator or --room-cycles")
+		}
+		if params.CheckpointInterval <= 0 {
+			return fmt.Errorf("--checkpoint-interval must be positive")
+		}
+	}
+	if params.RecordScenarioPath != "" {
+		if cmd.String("coordinator") != "" || cmd.IsSet("room-cycles") {
+			return fmt.Errorf("--record-scenario cannot be combined with --coordinator or --room-cycles")
+		}
+		if params.RecordScenarioPath == cmd.String("scenario") {
+			return fmt.Errorf("--record-scenario would overwrite the scenario being run")
 		}
 	}
 
+	if cmd.IsSet("room-cycles") {
+		if cmd.String("scenario") != "" || cmd.String("coordinator") != "" || params.Hold || cmd.String("matrix") != "" {
+			return fmt.Errorf("--room-cycles cannot be combined with --scenario, --coordinator, --hold or --matrix")
+		}
+		return loadtester.NewLoadTest(params).RunRoomCycles(ctx, loadtester.RoomCycle{
+			Count:        int(cmd.Int("room-cycles")),
+			Interval:     cmd.Duration("cycle-interval"),
+			CloseTimeout: cmd.Duration("room-close-timeout"),
+		})
+	}
+
+	if val := cmd.String("matrix"); val != "" {
+		if cmd.String("scenario") != "" || cmd.String("coordinator") != "" || params.Hold ||
+			params.CheckpointPath != "" || params.RecordScenarioPath != "" || params.IsFairproc {
+			return fmt.Errorf("--matrix cannot be combined with --scenario, --coordinator, --hold, --checkpoint, --resume, --record-scenario or --fairproc-rooms")
+		}
+		matrix, err := loadtester.ParseMatrix(val)
+		if err != nil {
+			return err
+		}
+		return loadtester.NewLoadTest(params).RunMatrix(ctx, matrix)
+	}
+
+	if path := cmd.String("scenario"); path != "" {
+		vars, err := loadtester.ParseScenarioVars(cmd.StringSlice("set"))
+		if err != nil {
+			return err
+		}
+		scenario, err := loadtester.LoadScenario(path, vars)
+		if err != nil {
+			return err
+		}
+		if addr := cmd.String("coordinator"); addr != "" {
+			return loadtester.NewCoordinator(params, int(cmd.Int("workers")), addr, cmd.String("worker-secret")).WithScenario(scenario).Run(ctx)
+		}
+		return loadtester.NewLoadTest(params).RunScenario(ctx, scenario)
+	}
+
+	if addr := cmd.String("coordinator"); addr != "" {
+		return loadtester.NewCoordinator(params, int(cmd.Int("workers")), addr, cmd.String("worker-secret")).Run(ctx)
+	}
+
 	test := loadtester.NewLoadTest(params)
 	return test.Run(ctx)
 }
+
+func loadTestAttach(ctx context.Context, cmd *cli.Command) error {
+	pc, err := loadProjectDetails(cmd)
+	if err != nil {
+		return err
+	}
+
+	if !cmd.Bool("verbose") {
+		lksdk.SetLogger(logger.LogRLogger(logr.Discard()))
+	}
+	tuneSystem()
+
+	params := loadtester.Params{
+		VideoPublishers: int(cmd.Int("video-publishers")),
+		AudioPublishers: int(cmd.Int("audio-publishers")),
+		Subscribers:     int(cmd.Int("subscribers")),
+		VideoResolution: cmd.String("video-resolution"),
+		VideoCodec:      cmd.String("video-codec"),
+		Duration:        cmd.Duration("duration"),
+		NumPerSecond:    cmd.Float("num-per-second"),
+		Simulcast:       !cmd.Bool("no-simulcast"),
+		Attach:          true,
+		TesterParams: loadtester.TesterParams{
+			URL:            pc.URL,
+			APIKey:         pc.APIKey,
+			APISecret:      pc.APISecret,
+			Room:           cmd.String("room"),
+			IdentityPrefix: cmd.String("identity-prefix"),
+			Layout:         loadtester.LayoutFromString(cmd.String("layout")),
+			RandomOffset:   true,
+		},
+	}
+
+	test := loadtester.NewLoadTest(params)
+	return test.Run(ctx)
+}
+
+func loadTestSIP(ctx context.Context, cmd *cli.Command) error {
+	pc, err := loadProjectDetails(cmd)
+	if err != nil {
+		return err
+	}
+
+	if !cmd.Bool("verbose") {
+		lksdk.SetLogger(logger.LogRLogger(logr.Discard()))
+	}
+	tuneSystem()
+
+	params := loadtester.SIPLoadParams{
+		URL:            pc.URL,
+		APIKey:         pc.APIKey,
+		APISecret:      pc.APISecret,
+		TrunkID:        cmd.String("trunk"),
+		CallTo:         cmd.String("call"),
+		Number:         cmd.String("number"),
+		Calls:          int(cmd.Int("calls")),
+		CallsPerSecond: cmd.Float("calls-per-second"),
+		Hold:           cmd.Duration("hold"),
+		RingingTimeout: cmd.Duration("ringing-timeout"),
+		DTMF:           cmd.String("dtmf"),
+		DTMFInterval:   cmd.Duration("dtmf-interval"),
+		Monitor:        cmd.Bool("monitor"),
+		RoomPrefix:     cmd.String("room-prefix"),
+		MaxFailureRate: cmd.Float("max-failure-rate"),
+	}
+	if err = loadtester.ValidateSIPLoad(params); err != nil {
+		return err
+	}
+	return loadtester.NewSIPLoadTest(params).Run(ctx)
+}
+
+func loadTestProbeTracks(ctx context.Context, cmd *cli.Command) error {
+	pc, err := loadProjectDetails(cmd)
+	if err != nil {
+		return err
+	}
+
+	if !cmd.Bool("verbose") {
+		lksdk.SetLogger(logger.LogRLogger(logr.Discard()))
+	}
+	tuneSystem()
+
+	probe := loadtester.NewTrackProbe(loadtester.TrackProbeParams{
+		VideoResolution: cmd.String("video-resolution"),
+		VideoCodec:      cmd.String("video-codec"),
+		Simulcast:       !cmd.Bool("no-simulcast"),
+		MaxPublishers:   int(cmd.Int("max-publishers")),
+		StepInterval:    cmd.Duration("step-interval"),
+		MaxPacketLoss:   cmd.Float("max-packet-loss"),
+		TesterParams: loadtester.TesterParams{
+			URL:          pc.URL,
+			APIKey:       pc.APIKey,
+			APISecret:    pc.APISecret,
+			Room:         cmd.String("room"),
+			RandomOffset: true,
+		},
+	})
+	return probe.Run(ctx)
+}
+
+func loadTestProbeRooms(ctx context.Context, cmd *cli.Command) error {
+	pc, err := loadProjectDetails(cmd)
+	if err != nil {
+		return err
+	}
+
+	if !cmd.Bool("verbose") {
+		lksdk.SetLogger(logger.LogRLogger(logr.Discard()))
+	}
+	tuneSystem()
+
+	if cmd.Float("start-rate") <= 0 || cmd.Float("rate-step") <= 1 || cmd.Float("max-rate") < cmd.Float("start-rate") {
+		return fmt.Errorf("--start-rate must be positive, --rate-step over 1 and --max-rate at least --start-rate")
+	}
+	probe := loadtester.NewRoomProbe(loadtester.RoomProbeParams{
+		StartRate:    cmd.Float("start-rate"),
+		RateStep:     cmd.Float("rate-step"),
+		MaxRate:      cmd.Float("max-rate"),
+		StepDuration: cmd.Duration("step-duration"),
+		MaxErrorRate: cmd.Float("max-errors"),
+		LatencySpike: cmd.Float("latency-spike"),
+		MaxLatency:   cmd.Duration("max-latency"),
+		Activate:     !cmd.Bool("no-join"),
+		RoomPrefix:   cmd.String("room-prefix"),
+		TesterParams: loadtester.TesterParams{
+			URL:       pc.URL,
+			APIKey:    pc.APIKey,
+			APISecret: pc.APISecret,
+		},
+	})
+	return probe.Run(ctx)
+}
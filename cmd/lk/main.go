@@ -71,6 +71,7 @@ func main() {
 	app.Commands = append(app.Commands, ReplayCommands...)
 	app.Commands = append(app.Commands, LoadTestCommands...)
 	app.Commands = append(app.Commands, AgentLoadTestCommands...)
+	app.Commands = append(app.Commands, PerfCommands...)
 
 	// Register cleanup hook for SIGINT, SIGTERM, SIGQUIT
 	ctx, stop := signal.NotifyContext(
@@ -0,0 +1,284 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"github.com/livekit/livekit-cli/v2/pkg/config"
+	"github.com/livekit/livekit-cli/v2/pkg/loadtester"
+)
+
+// results of past load tests, in the config dir
+const historyDirName = "loadtest-history"
+
+var PerfCommands = []*cli.Command{
+	{
+		Name:  "perf",
+		Usage: "Tools for running load tests at scale",
+		Commands: []*cli.Command{
+			{
+				Name:   "serve",
+				Usage:  "Serve a web UI for browsing and comparing the results of past load tests run with --history",
+				Action: serveHistory,
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "port",
+						Usage: "`PORT` to serve the UI on",
+						Value: 9090,
+					},
+					&cli.StringFlag{
+						Name:  "history-dir",
+						Usage: "`DIR` of the results history (defaults to " + historyDirName + " in ~/.livekit, or %AppData%\\livekit on Windows)",
+					},
+				},
+			},
+			{
+				Name:      "verify",
+				Usage:     "Verify that load test result files written with --sign-results are unmodified, using the project's API secret",
+				ArgsUsage: "FILE...",
+				Action:    verifyResults,
+			},
+			{
+				Name:   "selftest",
+				Usage:  "Check the stats load tests report, by publishing audio with a known loss, latency and jitter between two peer connections over the loopback interface",
+				Action: runSelfTest,
+				Flags: []cli.Flag{
+					&cli.DurationFlag{
+						Name:  "duration",
+						Usage: "`TIME` to send packets for",
+						Value: loadtester.DefaultSelfTestDuration,
+					},
+					&cli.FloatFlag{
+						Name:  "packet-loss",
+						Usage: "`FRACTION` (0-1) of packets to drop",
+						Value: loadtester.DefaultSelfTestPacketLoss,
+					},
+					&cli.DurationFlag{
+						Name:  "media-latency",
+						Usage: "Delay packets by `TIME`",
+						Value: loadtester.DefaultSelfTestLatency,
+					},
+					&cli.DurationFlag{
+						Name:  "jitter",
+						Usage: "Delay packets by a further random `TIME` up to this, below the 20ms packet interval",
+						Value: loadtester.DefaultSelfTestJitter,
+					},
+				},
+			},
+			{
+				Name:   "k8s-manifest",
+				Usage:  "Generate Kubernetes manifests running a distributed load test",
+				Action: generateK8sManifest,
+				Flags: []cli.Flag{
+					&cli.IntFlag{
+						Name:  "workers",
+						Usage: "`NUMBER` of worker pods",
+						Value: 1,
+					},
+					&cli.StringFlag{
+						Name:  "name",
+						Usage: "`NAME` of the generated resources",
+						Value: "lk-load-test",
+					},
+					&cli.StringFlag{
+						Name:  "namespace",
+						Usage: "`NAMESPACE` of the generated resources",
+					},
+					&cli.StringFlag{
+						Name:  "image",
+						Usage: "Container `IMAGE` with the lk binary as entrypoint",
+						Value: "livekit/livekit-cli:latest",
+					},
+					&cli.StringFlag{
+						Name:  "credentials-secret",
+						Usage: "`NAME` of an existing secret with LIVEKIT_API_KEY and LIVEKIT_API_SECRET",
+						Value: "livekit-credentials",
+					},
+					&cli.StringFlag{
+						Name:  "worker-cpu",
+						Usage: "CPU `REQUEST` of each worker",
+						Value: "4",
+					},
+					&cli.StringFlag{
+						Name:  "worker-memory",
+						Usage: "Memory `REQUEST` and limit of each worker",
+						Value: "8Gi",
+					},
+					&cli.StringFlag{
+						Name:  "load-test-args",
+						Usage: "Space separated `FLAGS` passed to the coordinator's load-test command, e.g. \"--video-publishers 10 --subscribers 500\"",
+					},
+					&cli.StringFlag{
+						Name:  "scenario",
+						Usage: "Run the load-test scenario in `FILE`, shipped to the coordinator in a ConfigMap. Needs at least --workers rooms (--room-count in --load-test-args)",
+					},
+					&cli.StringSliceFlag{
+						Name:  "set",
+						Usage: "Set the `KEY=VALUE` scenario variable, resolved when generating the manifests. Can be repeated",
+					},
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "Write manifests to `FILE` instead of stdout",
+					},
+				},
+			},
+		},
+	},
+}
+
+func generateK8sManifest(ctx context.Context, cmd *cli.Command) error {
+	pc, err := loadProjectDetails(cmd)
+	if err != nil {
+		return err
+	}
+	if cmd.Int("workers") < 1 {
+		return fmt.Errorf("at least one worker is required")
+	}
+
+	if cmd.IsSet("set") && cmd.String("scenario") == "" {
+		return fmt.Errorf("--set needs a --scenario")
+	}
+	var scenario []byte
+	if path := cmd.String("scenario"); path != "" {
+		vars, err := loadtester.ParseScenarioVars(cmd.StringSlice("set"))
+		if err != nil {
+			return err
+		}
+		s, err := loadtester.LoadScenario(path, vars)
+		if err != nil {
+			return err
+		}
+		if scenario, err = s.Marshal("generated from " + filepath.Base(path)); err != nil {
+			return err
+		}
+	}
+
+	var w io.Writer = os.Stdout
+	if path := cmd.String("output"); path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	return loadtester.WriteK8sManifest(w, loadtester.ManifestParams{
+		Name:              cmd.String("name"),
+		Namespace:         cmd.String("namespace"),
+		Image:             cmd.String("image"),
+		Workers:           int(cmd.Int("workers")),
+		URL:               pc.URL,
+		CredentialsSecret: cmd.String("credentials-secret"),
+		WorkerCPU:         cmd.String("worker-cpu"),
+		WorkerMemory:      cmd.String("worker-memory"),
+		Args:              strings.Fields(cmd.String("load-test-args")),
+		Scenario:          scenario,
+	})
+}
+
+func verifyResults(ctx context.Context, cmd *cli.Command) error {
+	if cmd.Args().Len() == 0 {
+		return fmt.Errorf("no result files to verify")
+	}
+	pc, err := loadProjectDetails(cmd)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, path := range cmd.Args().Slice() {
+		sig, err := loadtester.VerifyResultFile(path, pc.APISecret)
+		if err != nil {
+			fmt.Printf("%s: FAILED, %v\n", path, err)
+			failed++
+			continue
+		}
+		fmt.Printf("%s: verified, written by %s with key %s at %s\n",
+			path, sig.Tool, sig.KeyID, sig.SignedAt.Format(time.RFC3339))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d result files failed verification", failed, cmd.Args().Len())
+	}
+	return nil
+}
+
+func runSelfTest(ctx context.Context, cmd *cli.Command) error {
+	params := loadtester.SelfTestParams{
+		Duration: cmd.Duration("duration"),
+		Impairment: loadtester.Impairment{
+			PacketLoss: cmd.Float("packet-loss"),
+			Latency:    cmd.Duration("media-latency"),
+			Jitter:     cmd.Duration("jitter"),
+		},
+	}
+	results, err := loadtester.RunSelfTest(ctx, params)
+	if err != nil {
+		return err
+	}
+	loadtester.PrintSelfTestResults(params, results)
+	if failed := results.Failed(); len(failed) > 0 {
+		return fmt.Errorf("reported stats differ from the injected impairment: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func defaultHistoryDir() (string, error) {
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, historyDirName), nil
+}
+
+func serveHistory(ctx context.Context, cmd *cli.Command) error {
+	dir := cmd.String("history-dir")
+	if dir == "" {
+		var err error
+		if dir, err = defaultHistoryDir(); err != nil {
+			return err
+		}
+	}
+
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", cmd.Int("port")))
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Handler: loadtester.NewHistoryHandler(&loadtester.History{Dir: dir})}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	fmt.Printf("Serving results from %s on http://localhost:%d\n", dir, cmd.Int("port"))
+	if err = server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
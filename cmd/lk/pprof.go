@@ -0,0 +1,84 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// fraction of mutex contention events reported, cheap enough to leave on for a whole run
+const pprofMutexFraction = 10
+
+// servePprof serves the profiles of the load test process itself on addr, so a tester machine
+// that is the bottleneck can be profiled while the test runs. An address without a host only
+// listens on the loopback interface, and the command line, which holds the API secret, is not served.
+func servePprof(addr string) (func(), error) {
+	if addr == "" {
+		return func() {}, nil
+	}
+	addr, err := pprofListenAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("could not serve pprof: %w", err)
+	}
+	prevFraction := runtime.SetMutexProfileFraction(pprofMutexFraction)
+	server := &http.Server{Handler: mux}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Println("pprof server stopped:", err)
+		}
+	}()
+	fmt.Printf("Serving pprof on http://%s/debug/pprof/\n", listener.Addr())
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+		runtime.SetMutexProfileFraction(prevFraction)
+	}, nil
+}
+
+// pprofListenAddr returns addr with the loopback host when it has none, e.g. 127.0.0.1:6060 for :6060 or 6060
+func pprofListenAddr(addr string) (string, error) {
+	if !strings.Contains(addr, ":") {
+		addr = ":" + addr
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid pprof address %q: %w", addr, err)
+	}
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	return net.JoinHostPort(host, port), nil
+}
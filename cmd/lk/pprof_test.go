@@ -0,0 +1,71 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestServePprof(t *testing.T) {
+	stop, err := servePprof("")
+	require.NoError(t, err)
+	stop()
+
+	// find a free port
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	stop, err = servePprof(addr)
+	require.NoError(t, err)
+	defer stop()
+
+	_, err = servePprof(addr)
+	require.Error(t, err)
+
+	res, err := http.Get("http://" + addr + "/debug/pprof/goroutine?debug=1")
+	require.NoError(t, err)
+	defer res.Body.Close()
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.Contains(t, string(body), "goroutine profile")
+
+	res, err = http.Get("http://" + addr + "/debug/pprof/cmdline")
+	require.NoError(t, err)
+	res.Body.Close()
+	require.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestPprofListenAddr(t *testing.T) {
+	for addr, expected := range map[string]string{
+		":6060":        "127.0.0.1:6060",
+		"6060":         "127.0.0.1:6060",
+		"0.0.0.0:6060": "0.0.0.0:6060",
+		"[::1]:6060":   "[::1]:6060",
+	} {
+		listenAddr, err := pprofListenAddr(addr)
+		require.NoError(t, err, addr)
+		require.Equal(t, expected, listenAddr, addr)
+	}
+	_, err := pprofListenAddr("[::1")
+	require.Error(t, err)
+}
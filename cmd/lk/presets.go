@@ -0,0 +1,95 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
+
+	"github.com/livekit/livekit-cli/v2/pkg/config"
+	"github.com/livekit/livekit-cli/v2/pkg/loadtester"
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+const (
+	presetsDirName = "loadtest-presets"
+	// name of the suite run by load-test --run-all
+	standardSuiteName = "suite"
+)
+
+func loadPresets() ([]*loadtester.Preset, error) {
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return loadtester.LoadPresets(filepath.Join(dir, presetsDirName))
+}
+
+func listPresets(ctx context.Context, cmd *cli.Command) error {
+	presets, err := loadPresets()
+	if err != nil {
+		return err
+	}
+
+	table := util.CreateTable().
+		Headers("Name", "Kind", "Source", "Description")
+	for _, p := range presets {
+		source := "built-in"
+		if p.Path != "" {
+			source = p.Path
+		}
+		table.Row(p.Name, "preset", source, p.Description)
+	}
+	table.Row(standardSuiteName, "suite", "built-in", "Audio and video cases of increasing size, run with --run-all")
+	fmt.Println(table)
+	return nil
+}
+
+func showPreset(ctx context.Context, cmd *cli.Command) error {
+	name := cmd.Args().First()
+	if name == "" {
+		return errors.New("preset name is required")
+	}
+
+	if name == standardSuiteName {
+		for _, m := range loadtester.StandardSuite() {
+			fmt.Printf("--matrix %q\n", m)
+		}
+		return nil
+	}
+
+	presets, err := loadPresets()
+	if err != nil {
+		return err
+	}
+	preset, err := loadtester.FindPreset(presets, name)
+	if err != nil {
+		return err
+	}
+	if preset.Path != "" {
+		fmt.Printf("# %s\n", preset.Path)
+	}
+	data, err := yaml.Marshal(preset)
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(data))
+	return nil
+}
@@ -26,7 +26,7 @@ import (
 	"github.com/livekit/livekit-cli/v2/pkg/util"
 	"github.com/livekit/protocol/auth"
 	"github.com/livekit/protocol/replay"
-	lksdk "github.com/livekit/server-sdk-go/v2"
+	"github.com/livekit/server-sdk-go/v2/signalling"
 )
 
 var (
@@ -119,7 +119,7 @@ func createReplayClient(ctx context.Context, cmd *cli.Command) (context.Context,
 		return nil, err
 	}
 
-	url := lksdk.ToHttpURL(pc.URL)
+	url := signalling.ToHttpURL(pc.URL)
 	client := replay.NewReplayProtobufClient(url, &http.Client{}, withDefaultClientOpts(pc)...)
 	replayClient = &replayServiceClient{
 		Replay:    client,
@@ -160,12 +160,12 @@ func loadReplay(ctx context.Context, cmd *cli.Command) error {
 		return err
 	}
 
-	req := &replay.LoadReplayRequest{
-		ReplayId:    cmd.String("id"),
-		RoomName:    cmd.String("room"),
-		StartingPts: cmd.Int("pts"),
+	req := &replay.PlaybackRequest{
+		ReplayId:     cmd.String("id"),
+		PlaybackRoom: cmd.String("room"),
+		SeekOffset:   cmd.Int("pts"),
 	}
-	res, err := replayClient.LoadReplay(ctx, req)
+	res, err := replayClient.Playback(ctx, req)
 	if err != nil {
 		return err
 	}
@@ -180,11 +180,11 @@ func seek(ctx context.Context, cmd *cli.Command) error {
 		return err
 	}
 
-	req := &replay.RoomSeekRequest{
+	req := &replay.SeekRequest{
 		PlaybackId: cmd.String("id"),
-		Pts:        cmd.Int("pts"),
+		SeekOffset: cmd.Int("pts"),
 	}
-	_, err = replayClient.SeekForRoom(ctx, req)
+	_, err = replayClient.Seek(ctx, req)
 	return err
 }
 
@@ -194,10 +194,10 @@ func closeReplay(ctx context.Context, cmd *cli.Command) error {
 		return err
 	}
 
-	req := &replay.CloseReplayRequest{
+	req := &replay.ClosePlaybackRequest{
 		PlaybackId: cmd.String("id"),
 	}
-	_, err = replayClient.CloseReplay(ctx, req)
+	_, err = replayClient.Close(ctx, req)
 	return err
 }
 
@@ -0,0 +1,129 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/twitchtv/twirp"
+)
+
+const (
+	retryBaseDelay = 250 * time.Millisecond
+	retryMaxDelay  = 5 * time.Second
+)
+
+var (
+	apiTimeout time.Duration
+	apiRetries int64
+)
+
+// idempotentMethods are the API calls that only read, which can run again when an attempt
+// times out after the server got it. Others, like CreateRoom or StartEgress, could be applied twice.
+var idempotentMethods = map[string]bool{
+	"ListRooms":            true,
+	"ListParticipants":     true,
+	"GetParticipant":       true,
+	"ListEgress":           true,
+	"ListIngress":          true,
+	"ListSIPTrunk":         true,
+	"ListSIPInboundTrunk":  true,
+	"ListSIPOutboundTrunk": true,
+	"GetSIPInboundTrunk":   true,
+	"GetSIPOutboundTrunk":  true,
+	"ListSIPDispatchRule":  true,
+	"ListDispatch":         true,
+	"ListReplays":          true,
+}
+
+// newRetryInterceptor applies a per-attempt timeout to API calls and retries transient
+// failures of idempotent ones with jittered exponential backoff. Calls that wait are left alone.
+func newRetryInterceptor(timeout time.Duration, retries int) twirp.Interceptor {
+	return func(next twirp.Method) twirp.Method {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if waits(ctx, req) {
+				return next(ctx, req)
+			}
+			if method, _ := twirp.MethodName(ctx); !idempotentMethods[method] {
+				return callWithTimeout(ctx, next, req, timeout)
+			}
+			for attempt := 0; ; attempt++ {
+				res, err := callWithTimeout(ctx, next, req, timeout)
+				if err == nil || attempt >= retries || ctx.Err() != nil || !isTransientError(err) {
+					return res, err
+				}
+
+				select {
+				case <-ctx.Done():
+					return nil, err
+				case <-time.After(retryBackoff(attempt)):
+				}
+			}
+		}
+	}
+}
+
+// waits reports whether a call stays open until something happens on the server, like dialing
+// with --wait until the callee answers. Its caller sets a deadline that fits, which a per-attempt
+// timeout would cut short.
+func waits(ctx context.Context, req interface{}) bool {
+	if r, ok := req.(interface{ GetWaitUntilAnswered() bool }); ok && r.GetWaitUntilAnswered() {
+		return true
+	}
+	if method, ok := twirp.MethodName(ctx); ok && method == "TransferSIPParticipant" {
+		return true
+	}
+	return false
+}
+
+func callWithTimeout(ctx context.Context, next twirp.Method, req interface{}, timeout time.Duration) (interface{}, error) {
+	if timeout <= 0 {
+		return next(ctx, req)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return next(ctx, req)
+}
+
+// retryBackoff returns a random delay of up to base*2^attempt, capped at retryMaxDelay
+func retryBackoff(attempt int) time.Duration {
+	backoff := retryMaxDelay
+	if attempt < 5 {
+		backoff = min(retryBaseDelay<<attempt, retryMaxDelay)
+	}
+	return time.Duration(rand.Int63n(int64(backoff))) + time.Millisecond
+}
+
+func isTransientError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var twErr twirp.Error
+	if errors.As(err, &twErr) {
+		switch twErr.Code() {
+		case twirp.Unavailable, twirp.DeadlineExceeded, twirp.ResourceExhausted:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/twitchtv/twirp"
+	"github.com/twitchtv/twirp/ctxsetters"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+func TestRetryInterceptor(t *testing.T) {
+	failing := func(code twirp.ErrorCode, failures int) (twirp.Method, *int) {
+		calls := 0
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			calls++
+			if calls <= failures {
+				return nil, twirp.NewError(code, "failed")
+			}
+			return "ok", nil
+		}, &calls
+	}
+
+	list := ctxsetters.WithMethodName(context.Background(), "ListRooms")
+	method, calls := failing(twirp.Unavailable, 2)
+	res, err := newRetryInterceptor(0, 2)(method)(list, nil)
+	if err != nil || res != "ok" || *calls != 3 {
+		t.Errorf("expected success after 3 calls, got %v, %v after %d calls", res, err, *calls)
+	}
+
+	method, calls = failing(twirp.Unavailable, 3)
+	if _, err = newRetryInterceptor(0, 2)(method)(list, nil); err == nil || *calls != 3 {
+		t.Errorf("expected failure after 3 calls, got %v after %d calls", err, *calls)
+	}
+
+	method, calls = failing(twirp.NotFound, 1)
+	if _, err = newRetryInterceptor(0, 2)(method)(list, nil); err == nil || *calls != 1 {
+		t.Errorf("expected non-transient error not to be retried, got %v after %d calls", err, *calls)
+	}
+
+	// a mutation that timed out may have been applied, so it runs once
+	create := ctxsetters.WithMethodName(context.Background(), "CreateRoom")
+	method, calls = failing(twirp.DeadlineExceeded, 1)
+	if _, err = newRetryInterceptor(0, 2)(method)(create, nil); err == nil || *calls != 1 {
+		t.Errorf("expected a mutation not to be retried, got %v after %d calls", err, *calls)
+	}
+
+	// dialing with --wait keeps its own deadline and is not retried
+	var deadline time.Time
+	wait := func(ctx context.Context, req interface{}) (interface{}, error) {
+		deadline, _ = ctx.Deadline()
+		return nil, twirp.NewError(twirp.Unavailable, "failed")
+	}
+	req := &livekit.CreateSIPParticipantRequest{WaitUntilAnswered: true}
+	if _, err = newRetryInterceptor(time.Millisecond, 2)(wait)(context.Background(), req); err == nil || !deadline.IsZero() {
+		t.Errorf("expected a waiting call to run once without a deadline, got %v with deadline %v", err, deadline)
+	}
+	method, calls = failing(twirp.Unavailable, 1)
+	if _, err = newRetryInterceptor(0, 2)(method)(context.Background(), req); err == nil || *calls != 1 {
+		t.Errorf("expected a waiting call not to be retried, got %v after %d calls", err, *calls)
+	}
+}
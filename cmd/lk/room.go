@@ -31,6 +31,7 @@ import (
 	"github.com/livekit/protocol/logger"
 	lksdk "github.com/livekit/server-sdk-go/v2"
 
+	"github.com/livekit/livekit-cli/v2/pkg/loadtester"
 	"github.com/livekit/livekit-cli/v2/pkg/util"
 )
 
@@ -180,6 +181,12 @@ var (
 							Usage:     "List or search for active rooms by name",
 							Action:    listParticipants,
 							ArgsUsage: "ROOM_NAME",
+							Flags: []cli.Flag{
+								&cli.StringFlag{
+									Name:  "role",
+									Usage: "Only list load test participants with `ROLE`: pub-video, pub-audio, pub-av, pub-data or sub",
+								},
+							},
 						},
 						{
 							Name:      "get",
@@ -934,6 +941,13 @@ func listParticipants(ctx context.Context, cmd *cli.Command) error {
 		return err
 	}
 
+	var role loadtester.Role
+	if cmd.IsSet("role") {
+		if role, err = loadtester.ParseRole(cmd.String("role")); err != nil {
+			return err
+		}
+	}
+
 	res, err := roomClient.ListParticipants(ctx, &livekit.ListParticipantsRequest{
 		Room: roomName,
 	})
@@ -942,6 +956,9 @@ func listParticipants(ctx context.Context, cmd *cli.Command) error {
 	}
 
 	for _, p := range res.Participants {
+		if role != "" && loadtester.ParticipantRole(p) != role {
+			continue
+		}
 		fmt.Printf("%s (%s)\t tracks: %d\n", p.Identity, p.State.String(), len(p.Tracks))
 	}
 	return nil
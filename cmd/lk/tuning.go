@@ -0,0 +1,57 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const (
+	// targets match the recommended settings in the README
+	wantOpenFiles    = 65535
+	wantSocketBuffer = 25165824
+	// every UDP flow takes a conntrack entry, so a small table drops packets well before other limits
+	wantConntrackMax = 262144
+)
+
+// tuningIssue is an OS limit that could not be raised to what a large load test needs
+type tuningIssue struct {
+	setting string
+	current int64
+	want    int64
+	hint    string
+}
+
+func (i tuningIssue) String() string {
+	s := fmt.Sprintf("%s is %d, want at least %d", i.setting, i.current, i.want)
+	if i.hint != "" {
+		s += " (" + i.hint + ")"
+	}
+	return s
+}
+
+// tuneSystem raises the OS limits load tests depend on where permitted, and reports
+// the ones it could not adjust, since they otherwise silently cap a large run
+func tuneSystem() {
+	issues := tuneOS()
+	if len(issues) == 0 {
+		return
+	}
+	fmt.Fprintln(os.Stderr, "Some system limits could not be adjusted and may affect results:")
+	for _, issue := range issues {
+		fmt.Fprintf(os.Stderr, "  %s\n", issue)
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build darwin || freebsd
+
+package main
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+func tuneOS() []tuningIssue {
+	issues := raiseOpenFiles()
+	// caps the socket buffers pion can request. raising it needs root, so only report it
+	if maxSockBuf, err := unix.SysctlUint32("kern.ipc.maxsockbuf"); err == nil && maxSockBuf < wantSocketBuffer {
+		issues = append(issues, tuningIssue{
+			setting: "kern.ipc.maxsockbuf",
+			current: int64(maxSockBuf),
+			want:    wantSocketBuffer,
+			hint:    "sudo sysctl -w kern.ipc.maxsockbuf=25165824",
+		})
+	}
+	return issues
+}
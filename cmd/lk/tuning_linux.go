@@ -0,0 +1,83 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func tuneOS() []tuningIssue {
+	issues := raiseOpenFiles()
+	for _, name := range []string{"net.core.rmem_max", "net.core.wmem_max"} {
+		if issue := raiseSysctl("/proc/sys", name, wantSocketBuffer); issue != nil {
+			issues = append(issues, *issue)
+		}
+	}
+	if issue := checkConntrack("/proc/sys"); issue != nil {
+		issues = append(issues, *issue)
+	}
+	return issues
+}
+
+func sysctlPath(root, name string) string {
+	return filepath.Join(root, strings.ReplaceAll(name, ".", "/"))
+}
+
+func readSysctl(root, name string) (int64, error) {
+	b, err := os.ReadFile(sysctlPath(root, name))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+}
+
+// raiseSysctl raises a sysctl to want if it is lower, which only succeeds as root
+// and outside of containers that mount /proc/sys read-only
+func raiseSysctl(root, name string, want int64) *tuningIssue {
+	current, err := readSysctl(root, name)
+	if err != nil || current >= want {
+		return nil
+	}
+	if err = os.WriteFile(sysctlPath(root, name), []byte(strconv.FormatInt(want, 10)), 0); err == nil {
+		return nil
+	}
+	return &tuningIssue{
+		setting: name,
+		current: current,
+		want:    want,
+		hint:    fmt.Sprintf("sysctl -w %s=%d", name, want),
+	}
+}
+
+// checkConntrack reports a connection tracking table too small for the test. the table
+// only exists when netfilter is loaded, and raising it is left to the operator since it
+// also costs kernel memory
+func checkConntrack(root string) *tuningIssue {
+	const name = "net.netfilter.nf_conntrack_max"
+	current, err := readSysctl(root, name)
+	if err != nil || current >= wantConntrackMax {
+		return nil
+	}
+	return &tuningIssue{
+		setting: name,
+		current: current,
+		want:    wantConntrackMax,
+		hint:    fmt.Sprintf("sysctl -w %s=%d", name, wantConntrackMax),
+	}
+}
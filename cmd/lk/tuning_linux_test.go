@@ -0,0 +1,61 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRaiseSysctl(t *testing.T) {
+	root := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "net/core"), 0755))
+	path := filepath.Join(root, "net/core/rmem_max")
+
+	// already high enough, left alone
+	require.NoError(t, os.WriteFile(path, []byte("33554432\n"), 0644))
+	require.Nil(t, raiseSysctl(root, "net.core.rmem_max", wantSocketBuffer))
+	v, err := readSysctl(root, "net.core.rmem_max")
+	require.NoError(t, err)
+	require.EqualValues(t, 33554432, v)
+
+	require.NoError(t, os.WriteFile(path, []byte("212992\n"), 0644))
+	require.Nil(t, raiseSysctl(root, "net.core.rmem_max", wantSocketBuffer))
+	v, err = readSysctl(root, "net.core.rmem_max")
+	require.NoError(t, err)
+	require.EqualValues(t, wantSocketBuffer, v)
+
+	// missing sysctls are skipped
+	require.Nil(t, raiseSysctl(root, "net.core.wmem_max", wantSocketBuffer))
+}
+
+func TestCheckConntrack(t *testing.T) {
+	root := t.TempDir()
+	require.Nil(t, checkConntrack(root))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "net/netfilter"), 0755))
+	path := filepath.Join(root, "net/netfilter/nf_conntrack_max")
+	require.NoError(t, os.WriteFile(path, []byte("65536\n"), 0644))
+	issue := checkConntrack(root)
+	require.NotNil(t, issue)
+	require.EqualValues(t, 65536, issue.current)
+	require.Equal(t, "net.netfilter.nf_conntrack_max is 65536, want at least 262144 (sysctl -w net.netfilter.nf_conntrack_max=262144)", issue.String())
+
+	require.NoError(t, os.WriteFile(path, []byte("1048576\n"), 0644))
+	require.Nil(t, checkConntrack(root))
+}
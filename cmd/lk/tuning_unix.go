@@ -0,0 +1,54 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux || darwin || freebsd
+
+package main
+
+import (
+	"syscall"
+)
+
+// raiseOpenFiles raises the soft file descriptor limit, and the hard limit when permitted
+func raiseOpenFiles() []tuningIssue {
+	var rLimit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rLimit); err != nil {
+		return nil
+	}
+	if rLimit.Cur >= wantOpenFiles {
+		return nil
+	}
+	raised := rLimit
+	raised.Cur = wantOpenFiles
+	if raised.Max < wantOpenFiles {
+		raised.Max = wantOpenFiles
+	}
+	if syscall.Setrlimit(syscall.RLIMIT_NOFILE, &raised) == nil {
+		return nil
+	}
+
+	// not allowed to raise the hard limit, go as far as it allows
+	if rLimit.Cur < rLimit.Max {
+		rLimit.Cur = rLimit.Max
+		if syscall.Setrlimit(syscall.RLIMIT_NOFILE, &rLimit) != nil {
+			_ = syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rLimit)
+		}
+	}
+	return []tuningIssue{{
+		setting: "open file limit",
+		current: int64(rLimit.Cur),
+		want:    wantOpenFiles,
+		hint:    "ulimit -n 65535",
+	}}
+}
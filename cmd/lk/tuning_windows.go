@@ -0,0 +1,63 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// every tester binds a few UDP sockets per ICE candidate, and the default range of 16384
+// dynamic ports runs out a few thousand testers into a run
+const wantDynamicPorts = 32768
+
+// tuneOS reports Winsock limits. Windows has no per-process descriptor limit, and
+// widening the dynamic port range needs an elevated prompt, so nothing is raised
+func tuneOS() []tuningIssue {
+	out, err := exec.Command("netsh", "interface", "ipv4", "show", "dynamicport", "udp").Output()
+	if err != nil {
+		return nil
+	}
+	ports, ok := parseDynamicPorts(string(out))
+	if !ok || ports >= wantDynamicPorts {
+		return nil
+	}
+	return []tuningIssue{{
+		setting: "UDP dynamic port range",
+		current: ports,
+		want:    wantDynamicPorts,
+		hint:    "netsh int ipv4 set dynamicport udp start=10000 num=55535",
+	}}
+}
+
+// parseDynamicPorts reads the number of ports from netsh output, which lists the start
+// port and then the number of ports. labels are localized, so only the values are used
+func parseDynamicPorts(out string) (int64, bool) {
+	var values []int64
+	for _, line := range strings.Split(out, "\n") {
+		_, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		if v, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err == nil {
+			values = append(values, v)
+		}
+	}
+	if len(values) != 2 {
+		return 0, false
+	}
+	return values[1], true
+}
@@ -0,0 +1,33 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDynamicPorts(t *testing.T) {
+	ports, ok := parseDynamicPorts("\r\nProtocol udp Dynamic Port Range\r\n" +
+		"---------------------------------\r\n" +
+		"Start Port      : 49152\r\n" +
+		"Number of Ports : 16384\r\n")
+	require.True(t, ok)
+	require.EqualValues(t, 16384, ports)
+
+	_, ok = parseDynamicPorts("The requested operation requires elevation.\r\n")
+	require.False(t, ok)
+}
@@ -25,7 +25,7 @@ import (
 
 	"github.com/livekit/protocol/utils/interceptors"
 
-	lksdk "github.com/livekit/server-sdk-go/v2"
+	"github.com/livekit/server-sdk-go/v2/signalling"
 
 	"github.com/livekit/livekit-cli/v2/pkg/config"
 	"github.com/livekit/livekit-cli/v2/pkg/util"
@@ -87,6 +87,16 @@ var (
 			Name:     "verbose",
 			Required: false,
 		},
+		&cli.DurationFlag{
+			Name:        "api-timeout",
+			Usage:       "Abort each server API call after `TIME` (no timeout when unset). Calls that wait, like dialing with --wait, keep their own deadline",
+			Destination: &apiTimeout,
+		},
+		&cli.IntFlag{
+			Name:        "api-retries",
+			Usage:       "Retry server API calls that only read, like listing rooms, up to `NUMBER` times when they fail with a transient error, with jittered backoff. Calls that change something are not retried",
+			Destination: &apiRetries,
+		},
 	}
 )
 
@@ -108,7 +118,10 @@ func withDefaultClientOpts(c *config.ProjectConfig) []twirp.ClientOption {
 		ics  []twirp.Interceptor
 	)
 	if printCurl {
-		ics = append(ics, interceptors.NewCurlPrinter(os.Stdout, lksdk.ToHttpURL(c.URL)))
+		ics = append(ics, interceptors.NewCurlPrinter(os.Stdout, signalling.ToHttpURL(c.URL)))
+	}
+	if apiTimeout > 0 || apiRetries > 0 {
+		ics = append(ics, newRetryInterceptor(apiTimeout, int(apiRetries)))
 	}
 	if len(ics) != 0 {
 		opts = append(opts, twirp.WithClientInterceptors(ics...))
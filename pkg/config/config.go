@@ -18,8 +18,9 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"path"
+	"path/filepath"
 	"regexp"
+	"runtime"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -104,7 +105,7 @@ func LoadProject(name string) (*ProjectConfig, error) {
 	return nil, errors.New("project not found")
 }
 
-// LoadOrCreate loads config file from ~/.livekit/cli-config.yaml
+// LoadOrCreate loads config file from cli-config.yaml in the config dir
 // if it doesn't exist, it'll return an empty config file
 func LoadOrCreate() (*CLIConfig, error) {
 	configPath, err := getConfigLocation()
@@ -117,9 +118,10 @@ func LoadOrCreate() (*CLIConfig, error) {
 		return c, nil
 	} else if err != nil {
 		return nil, err
-	} else if s.Mode().Perm()&0077 != 0 {
+	} else if s.Mode().Perm()&0077 != 0 && runtime.GOOS != "windows" {
 		// because this file contains private keys, warn that
-		// only the owner should have permission to access it
+		// only the owner should have permission to access it.
+		// Windows controls access with ACLs, and reports every file as 0666
 		fmt.Fprintf(os.Stderr, "WARNING: config file %s should have permissions %o\n", configPath, 0600)
 	}
 
@@ -178,7 +180,7 @@ func (c *CLIConfig) PersistIfNeeded() error {
 	if err != nil {
 		return err
 	}
-	if err = os.MkdirAll(path.Dir(configPath), 0700); err != nil {
+	if err = os.MkdirAll(filepath.Dir(configPath), 0700); err != nil {
 		return err
 	}
 
@@ -195,10 +197,35 @@ func (c *CLIConfig) PersistIfNeeded() error {
 }
 
 func getConfigLocation() (string, error) {
-	dir, err := os.UserHomeDir()
+	dir, err := GetConfigDir()
 	if err != nil {
 		return "", err
 	}
 
-	return path.Join(dir, ".livekit", "cli-config.yaml"), nil
+	return filepath.Join(dir, "cli-config.yaml"), nil
+}
+
+// GetConfigDir returns the directory holding the CLI config and other user files,
+// ~/.livekit, or %AppData%\livekit on Windows
+func GetConfigDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	if runtime.GOOS != "windows" {
+		return filepath.Join(home, ".livekit"), nil
+	}
+	// an empty AppData falls back to the home directory
+	appData, _ := os.UserConfigDir()
+	return windowsConfigDir(home, appData), nil
+}
+
+// windowsConfigDir keeps using ~/.livekit where earlier versions created it, so that
+// upgrading doesn't lose saved projects
+func windowsConfigDir(home, appData string) string {
+	legacy := filepath.Join(home, ".livekit")
+	if _, err := os.Stat(legacy); err == nil || appData == "" {
+		return legacy
+	}
+	return filepath.Join(appData, "livekit")
 }
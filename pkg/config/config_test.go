@@ -0,0 +1,33 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindowsConfigDir(t *testing.T) {
+	home, appData := t.TempDir(), t.TempDir()
+	require.Equal(t, filepath.Join(appData, "livekit"), windowsConfigDir(home, appData))
+	require.Equal(t, filepath.Join(home, ".livekit"), windowsConfigDir(home, ""))
+
+	// configs saved by earlier versions stay where they are
+	require.NoError(t, os.Mkdir(filepath.Join(home, ".livekit"), 0700))
+	require.Equal(t, filepath.Join(home, ".livekit"), windowsConfigDir(home, appData))
+}
@@ -0,0 +1,139 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+var cycleQualities = []livekit.VideoQuality{
+	livekit.VideoQuality_LOW,
+	livekit.VideoQuality_MEDIUM,
+	livekit.VideoQuality_HIGH,
+}
+
+// cycleLayers periodically requests a different quality for every subscribed video track, as
+// viewport changes would with adaptive stream. Testers start at a random point in the interval,
+// so that their requests are spread out.
+func (t *LoadTester) cycleLayers(interval time.Duration) {
+	if !t.features().AdaptiveStream {
+		return
+	}
+	time.Sleep(time.Duration(rand.Int63n(int64(interval))))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for t.IsRunning() {
+		t.switchLayers()
+		<-ticker.C
+	}
+}
+
+func (t *LoadTester) switchLayers() {
+	t.lock.Lock()
+	participants := make([]*lksdk.RemoteParticipant, 0, len(t.subscribedParticipants))
+	for _, rp := range t.subscribedParticipants {
+		participants = append(participants, rp)
+	}
+	t.lock.Unlock()
+
+	for _, rp := range participants {
+		for _, pub := range rp.TrackPublications() {
+			remotePub, ok := pub.(*lksdk.RemoteTrackPublication)
+			if !ok || remotePub.Kind() != lksdk.TrackKindVideo || !remotePub.IsSubscribed() {
+				continue
+			}
+
+			t.lock.Lock()
+			quality := nextCycleQuality(t.trackQualities[rp.SID()])
+			t.trackQualities[rp.SID()] = quality
+			t.pendingLayerSwitches[remotePub.SID()] = time.Now()
+			t.lock.Unlock()
+
+			if t.params.demand != nil {
+				t.params.demand.set(rp.Identity(), t.identity(), quality)
+			}
+			setVideoQuality(remotePub, quality)
+		}
+	}
+}
+
+// nextCycleQuality picks a random quality other than current
+func nextCycleQuality(current livekit.VideoQuality) livekit.VideoQuality {
+	options := slices.DeleteFunc(slices.Clone(cycleQualities), func(q livekit.VideoQuality) bool {
+		return q == current
+	})
+	return options[rand.Intn(len(options))]
+}
+
+func setVideoQuality(pub *lksdk.RemoteTrackPublication, quality livekit.VideoQuality) {
+	switch quality {
+	case livekit.VideoQuality_HIGH:
+		pub.SetVideoDimensions(highWidth, highHeight)
+	case livekit.VideoQuality_MEDIUM:
+		pub.SetVideoDimensions(mediumWidth, mediumHeight)
+	case livekit.VideoQuality_LOW:
+		pub.SetVideoDimensions(lowWidth, lowHeight)
+	case livekit.VideoQuality_OFF:
+		pub.SetEnabled(false)
+	}
+}
+
+// onLayerKeyframe completes a layer switch once the first keyframe after it is received,
+// which is when the SFU can move the track to the requested layer
+func (t *LoadTester) onLayerKeyframe(sid string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	requestedAt, ok := t.pendingLayerSwitches[sid]
+	if !ok {
+		return
+	}
+	t.layerSwitches = append(t.layerSwitches, time.Since(requestedAt))
+	delete(t.pendingLayerSwitches, sid)
+}
+
+func printLayerSwitchStats(stats map[string]*testerStats, names []string) {
+	table := util.CreateTable().
+		Headers("Tester", "Layer Switches", "Pending", "p50", "p95", "Max")
+	rows := 0
+	for _, name := range names {
+		s := stats[name]
+		if len(s.layerSwitches) == 0 && s.pendingLayerSwitches == 0 {
+			continue
+		}
+		p := getLatencyPercentiles(s.layerSwitches)
+		table.Row(
+			name,
+			fmt.Sprint(len(s.layerSwitches)),
+			fmt.Sprint(s.pendingLayerSwitches),
+			p.p50.Round(time.Millisecond).String(),
+			p.p95.Round(time.Millisecond).String(),
+			slices.Max(append([]time.Duration{0}, s.layerSwitches...)).Round(time.Millisecond).String(),
+		)
+		rows++
+	}
+	if rows > 0 {
+		fmt.Println("\nAdaptive stream layer switches:")
+		fmt.Println(table)
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+func TestNextCycleQuality(t *testing.T) {
+	seen := make(map[livekit.VideoQuality]bool)
+	for i := 0; i < 100; i++ {
+		q := nextCycleQuality(livekit.VideoQuality_MEDIUM)
+		require.NotEqual(t, livekit.VideoQuality_MEDIUM, q)
+		seen[q] = true
+	}
+	require.Len(t, seen, 2)
+
+	// a track that was off moves to any visible quality
+	require.Contains(t, cycleQualities, nextCycleQuality(livekit.VideoQuality_OFF))
+}
+
+func TestLayerSwitchTiming(t *testing.T) {
+	tester := NewLoadTester(TesterParams{})
+	tester.pendingLayerSwitches["TR_1"] = time.Now().Add(-50 * time.Millisecond)
+	tester.pendingLayerSwitches["TR_2"] = time.Now()
+
+	tester.onLayerKeyframe("TR_1")
+	// later keyframes on the same layer are not switches
+	tester.onLayerKeyframe("TR_1")
+
+	stats := tester.getStats()
+	require.Len(t, stats.layerSwitches, 1)
+	require.GreaterOrEqual(t, stats.layerSwitches[0], 50*time.Millisecond)
+	require.Equal(t, 1, stats.pendingLayerSwitches)
+}
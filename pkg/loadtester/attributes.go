@@ -0,0 +1,46 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"fmt"
+	"strings"
+)
+
+// reservedAttributePrefix is used by LiveKit and the attributes testers set themselves
+const reservedAttributePrefix = "lk."
+
+// ParseAttributes parses --attr key=value pairs set on tester participants
+func ParseAttributes(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	attributes := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("attribute %q is not key=value", pair)
+		}
+		if strings.HasPrefix(key, reservedAttributePrefix) {
+			return nil, fmt.Errorf("attribute %s: keys starting with %q are reserved", key, reservedAttributePrefix)
+		}
+		if _, ok := attributes[key]; ok {
+			return nil, fmt.Errorf("attribute %s is set twice", key)
+		}
+		attributes[key] = value
+	}
+	return attributes, nil
+}
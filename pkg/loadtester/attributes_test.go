@@ -0,0 +1,65 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/auth"
+)
+
+func TestParseAttributes(t *testing.T) {
+	attributes, err := ParseAttributes(nil)
+	require.NoError(t, err)
+	require.Nil(t, attributes)
+
+	attributes, err = ParseAttributes([]string{"tier=gold", "empty=", "url=https://example.com/?a=b"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"tier": "gold", "empty": "", "url": "https://example.com/?a=b"}, attributes)
+
+	for _, pairs := range [][]string{
+		{"tier"},
+		{"=gold"},
+		{"lk.loadtest.role=sub"},
+		{"tier=gold", "tier=silver"},
+	} {
+		_, err = ParseAttributes(pairs)
+		require.Error(t, err, pairs)
+	}
+}
+
+func TestTokenAttributes(t *testing.T) {
+	params := TesterParams{
+		Room:           "testroom_0",
+		IdentityPrefix: "abc",
+		Role:           RoleSubscriber,
+		APIKey:         "key",
+		APISecret:      "secretsecretsecretsecretsecret",
+		Metadata:       `{"tier":"gold"}`,
+		Attributes:     map[string]string{"region": "eu"},
+	}
+	token, err := NewLoadTester(params).token()
+	require.NoError(t, err)
+	verifier, err := auth.ParseAPIToken(token)
+	require.NoError(t, err)
+	_, claims, err := verifier.Verify(params.APISecret)
+	require.NoError(t, err)
+	require.Equal(t, `{"tier":"gold"}`, claims.Metadata)
+	require.Equal(t, map[string]string{"region": "eu", RoleAttribute: string(RoleSubscriber)}, claims.Attributes)
+	// the tester's own attributes are not shared
+	require.Len(t, params.Attributes, 1)
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+	"github.com/pion/webrtc/v4/pkg/media"
+
+	"github.com/livekit/protocol/livekit"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+
+	provider2 "github.com/livekit/livekit-cli/v2/pkg/provider"
+)
+
+// while silent, an Opus encoder with DTX keeps sending a frame this often for comfort noise
+const dtxInterval = 400 * time.Millisecond
+
+// audioSource is the Opus audio a publisher sends
+type audioSource interface {
+	lksdk.AudioSampleProvider
+	Codec() webrtc.RTPCodecCapability
+	Channels() int
+}
+
+// audioLooper returns the audio the tester publishes: AudioFile, or one of the embedded clips.
+// The embedded clips are mono, so stereo audio is generated instead.
+func (t *LoadTester) audioLooper() (audioSource, error) {
+	if t.params.AudioFile != "" {
+		looper, err := provider2.CreateAudioLooperFromFile(t.params.AudioFile)
+		if err != nil {
+			return nil, err
+		}
+		if t.params.AudioStereo && looper.Channels() < 2 {
+			return nil, fmt.Errorf("%s is not a stereo Ogg Opus file", t.params.AudioFile)
+		}
+		t.seekAudio(looper)
+		return looper, nil
+	}
+	if t.params.AudioStereo {
+		return provider2.NewOpusGenerator(2), nil
+	}
+	looper, err := provider2.CreateAudioLooper()
+	if err != nil {
+		return nil, err
+	}
+	t.seekAudio(looper)
+	return looper, nil
+}
+
+// seekAudio starts the recorded speech at a random point, so that publishers don't speak in unison
+func (t *LoadTester) seekAudio(looper *provider2.OpusAudioLooper) {
+	if t.params.RandomOffset {
+		looper.SeekTo(rand.Float64())
+	}
+}
+
+// audioLevelProvider sends the audio level of its source with every frame, which the server's
+// voice activity and active speaker detection rely on. The SDK only sends the level of the
+// provider it writes from, not of the ones wrapped by it.
+type audioLevelProvider struct {
+	lksdk.SampleProvider
+	source lksdk.AudioSampleProvider
+}
+
+func (p *audioLevelProvider) CurrentAudioLevel() uint8 {
+	return p.source.CurrentAudioLevel()
+}
+
+// dtxProvider drops silent frames like an Opus encoder with DTX enabled, except for one every dtxInterval
+type dtxProvider struct {
+	lksdk.SampleProvider
+	silent bool
+	// since the last silent frame that was sent
+	sinceSent time.Duration
+}
+
+func (p *dtxProvider) NextSample(ctx context.Context) (media.Sample, error) {
+	var skipped time.Duration
+	for {
+		sample, err := p.SampleProvider.NextSample(ctx)
+		// silent frames are no larger than the 3 byte CELT silence frame
+		if err != nil || len(sample.Data) > len(opusSilence) || !p.silent || p.sinceSent >= dtxInterval {
+			p.silent = err == nil && len(sample.Data) <= len(opusSilence)
+			p.sinceSent = sample.Duration
+			// carry the dropped time so the writer neither bursts nor drifts
+			sample.Duration += skipped
+			return sample, err
+		}
+		p.sinceSent += sample.Duration
+		skipped += sample.Duration
+		select {
+		case <-ctx.Done():
+			return sample, io.EOF
+		case <-time.After(sample.Duration):
+		}
+	}
+}
+
+// onSignalRequest sets the publication options the SDK doesn't expose on the tester's track requests
+func (t *LoadTester) onSignalRequest(req *livekit.SignalRequest) bool {
+	t.errorLog.signal("send", req)
+	if answer := req.GetAnswer(); answer != nil {
+		return t.onAnswer(answer)
+	}
+	addTrack := req.GetAddTrack()
+	if addTrack == nil || addTrack.Type != livekit.TrackType_AUDIO || !t.params.AudioDisableRED {
+		return false
+	}
+	addTrack.DisableRed = true
+	return true
+}
@@ -0,0 +1,101 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pion/webrtc/v4/pkg/media"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+type opusFrameProvider struct {
+	lksdk.BaseSampleProvider
+	frames [][]byte
+	next   int
+}
+
+func (p *opusFrameProvider) NextSample(_ context.Context) (media.Sample, error) {
+	frame := p.frames[p.next%len(p.frames)]
+	p.next++
+	return media.Sample{Data: frame, Duration: 10 * time.Millisecond}, nil
+}
+
+func TestDTXProvider(t *testing.T) {
+	speech := make([]byte, 80)
+	frames := [][]byte{speech, speech}
+	for i := 0; i < 100; i++ {
+		frames = append(frames, opusSilence)
+	}
+	p := &dtxProvider{SampleProvider: &opusFrameProvider{frames: frames}}
+
+	var durations []time.Duration
+	for i := 0; i < 5; i++ {
+		sample, err := p.NextSample(context.Background())
+		require.NoError(t, err)
+		durations = append(durations, sample.Duration)
+	}
+	// speech, then the first silent frame, and one per dtxInterval while silent
+	require.Equal(t, []time.Duration{
+		10 * time.Millisecond,
+		10 * time.Millisecond,
+		10 * time.Millisecond,
+		dtxInterval,
+		dtxInterval,
+	}, durations)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := p.NextSample(ctx)
+	require.Error(t, err)
+}
+
+func TestAudioLooper(t *testing.T) {
+	tester := &LoadTester{params: TesterParams{AudioStereo: true}}
+	looper, err := tester.audioLooper()
+	require.NoError(t, err)
+	require.Equal(t, 2, looper.Channels())
+	require.EqualValues(t, 2, looper.Codec().Channels)
+
+	// the SDK sends the level of the outermost provider
+	provider := &audioLevelProvider{SampleProvider: &dtxProvider{SampleProvider: looper}, source: looper}
+	_, err = provider.NextSample(context.Background())
+	require.NoError(t, err)
+	require.Less(t, provider.CurrentAudioLevel(), uint8(127))
+}
+
+func TestOnSignalRequest(t *testing.T) {
+	addTrack := func(kind livekit.TrackType) *livekit.SignalRequest {
+		return &livekit.SignalRequest{Message: &livekit.SignalRequest_AddTrack{
+			AddTrack: &livekit.AddTrackRequest{Type: kind},
+		}}
+	}
+	tester := &LoadTester{}
+	require.False(t, tester.onSignalRequest(addTrack(livekit.TrackType_AUDIO)))
+
+	tester.params.AudioDisableRED = true
+	video := addTrack(livekit.TrackType_VIDEO)
+	require.False(t, tester.onSignalRequest(video))
+	require.False(t, video.GetAddTrack().DisableRed)
+	audio := addTrack(livekit.TrackType_AUDIO)
+	require.True(t, tester.onSignalRequest(audio))
+	require.True(t, audio.GetAddTrack().DisableRed)
+	require.False(t, tester.onSignalRequest(&livekit.SignalRequest{}))
+}
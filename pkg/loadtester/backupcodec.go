@@ -0,0 +1,233 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pion/sdp/v3"
+	"github.com/pion/webrtc/v4"
+	"go.uber.org/atomic"
+
+	"github.com/livekit/protocol/livekit"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+
+	provider2 "github.com/livekit/livekit-cli/v2/pkg/provider"
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+// backup codecs are the ones every subscriber can decode
+var backupMimeTypes = map[string]string{
+	"vp8":  webrtc.MimeTypeVP8,
+	"h264": webrtc.MimeTypeH264,
+}
+
+// ValidateBackupCodec checks --backup-codec and --subscribe-backup-only
+func ValidateBackupCodec(params Params) error {
+	if params.BackupCodec == "" {
+		if params.SubscribeBackupOnly {
+			return fmt.Errorf("--subscribe-backup-only needs --backup-codec")
+		}
+		return nil
+	}
+	if _, ok := backupMimeTypes[params.BackupCodec]; !ok {
+		return fmt.Errorf("--backup-codec must be \"vp8\" or \"h264\", got %q", params.BackupCodec)
+	}
+	if params.VideoCodec == "" && len(params.VideoFiles) == 0 {
+		return fmt.Errorf("--backup-codec needs the primary codec set with --video-codec or --video-file")
+	}
+	if params.VideoCodec == params.BackupCodec {
+		return fmt.Errorf("--backup-codec must differ from --video-codec %s", params.VideoCodec)
+	}
+	if params.E2EEKey != "" {
+		return fmt.Errorf("--backup-codec is not supported with --e2ee")
+	}
+	return nil
+}
+
+// sameCodec compares codecs by name, with or without the video/ prefix servers and SDKs differ on
+func sameCodec(a, b string) bool {
+	return strings.EqualFold(strings.TrimPrefix(strings.ToLower(a), "video/"), strings.TrimPrefix(strings.ToLower(b), "video/"))
+}
+
+// backupCodec creates the tracks of the second codec published with a tester's camera tracks.
+// The SDK sends them once the server asks for the backup, for subscribers that cannot decode the primary codec.
+type backupCodec struct {
+	codec    string
+	mimeType string
+	// backup tracks bound for sending
+	published atomic.Int32
+}
+
+func newBackupCodec(codec string) *backupCodec {
+	if codec == "" {
+		return nil
+	}
+	return &backupCodec{
+		codec:    codec,
+		mimeType: backupMimeTypes[codec],
+	}
+}
+
+// count is the number of backup tracks published
+func (b *backupCodec) count() int {
+	if b == nil {
+		return 0
+	}
+	return int(b.published.Load())
+}
+
+// backupOptions creates the backup of a camera track of mimeType, with a layer per simulcast layer
+// of the primary, and returns the publish options that register it
+func (t *LoadTester) backupOptions(mimeType, resolution string, simulcast bool) ([]lksdk.LocalTrackPublishOption, error) {
+	if t.backup == nil || sameCodec(mimeType, t.backup.mimeType) {
+		return nil, nil
+	}
+	loopers, err := provider2.CreateVideoLoopers(resolution, t.backup.codec, simulcast, false, -1, -1, -1, -1)
+	if err != nil {
+		return nil, err
+	}
+	t.seekLoopers(loopers)
+	tracks := make([]*lksdk.LocalTrack, 0, len(loopers))
+	for i, looper := range loopers {
+		quality := livekit.VideoQuality_HIGH
+		var opts []lksdk.LocalTrackOptions
+		if simulcast {
+			quality = livekit.VideoQuality(i)
+			opts = append(opts, lksdk.WithSimulcast("loadtest-video-backup", looper.ToLayer(quality)))
+		}
+		track, err := lksdk.NewLocalTrack(looper.Codec(), opts...)
+		if err != nil {
+			return nil, err
+		}
+		name := "video-backup " + strings.ToLower(quality.String())
+		if err := track.StartWrite(t.countSent(t.measureLayer(looper, name, int64(looper.ToLayer(quality).Bitrate))), nil); err != nil {
+			return nil, err
+		}
+		tracks = append(tracks, track)
+	}
+	// the SDK only binds the backup once the server asks for it
+	var bound atomic.Bool
+	tracks[len(tracks)-1].OnBind(func() {
+		if bound.CompareAndSwap(false, true) {
+			t.backup.published.Inc()
+		}
+	})
+	if simulcast {
+		return []lksdk.LocalTrackPublishOption{lksdk.WithBackupCodecForSimulcastTrack(tracks)}, nil
+	}
+	return []lksdk.LocalTrackPublishOption{lksdk.WithBackupCodec(tracks[0])}, nil
+}
+
+// onAnswer removes every video codec but the backup from a subscriber's answer, so that the server
+// has to send the backup of each track
+func (t *LoadTester) onAnswer(answer *livekit.SessionDescription) bool {
+	if !t.params.SubscribeBackupOnly {
+		return false
+	}
+	restricted, ok := keepVideoCodec(answer.Sdp, backupMimeTypes[t.params.BackupCodec])
+	if ok {
+		answer.Sdp = restricted
+	}
+	return ok
+}
+
+// keepVideoCodec removes the payload types of other codecs than mimeType from the video sections
+// of an SDP, keeping retransmissions of mimeType. Sections without mimeType are left alone.
+func keepVideoCodec(description, mimeType string) (string, bool) {
+	var parsed sdp.SessionDescription
+	if err := parsed.UnmarshalString(description); err != nil {
+		return description, false
+	}
+	changed := false
+	for _, media := range parsed.MediaDescriptions {
+		if media.MediaName.Media != "video" {
+			continue
+		}
+		keep := make(map[string]bool)
+		for _, attr := range media.Attributes {
+			if attr.Key != "rtpmap" {
+				continue
+			}
+			pt, codec, _ := strings.Cut(attr.Value, " ")
+			name, _, _ := strings.Cut(codec, "/")
+			if sameCodec(name, mimeType) {
+				keep[pt] = true
+			}
+		}
+		if len(keep) == 0 {
+			continue
+		}
+		for _, attr := range media.Attributes {
+			pt, params, _ := strings.Cut(attr.Value, " ")
+			if apt, ok := strings.CutPrefix(params, "apt="); attr.Key == "fmtp" && ok && keep[apt] {
+				keep[pt] = true
+			}
+		}
+		formats := slices.DeleteFunc(slices.Clone(media.MediaName.Formats), func(pt string) bool {
+			return !keep[pt]
+		})
+		if len(formats) == len(media.MediaName.Formats) {
+			continue
+		}
+		media.MediaName.Formats = formats
+		media.Attributes = slices.DeleteFunc(media.Attributes, func(attr sdp.Attribute) bool {
+			if attr.Key != "rtpmap" && attr.Key != "fmtp" && attr.Key != "rtcp-fb" {
+				return false
+			}
+			pt, _, _ := strings.Cut(attr.Value, " ")
+			return pt != "*" && !keep[pt]
+		})
+		changed = true
+	}
+	if !changed {
+		return description, false
+	}
+	out, err := parsed.Marshal()
+	if err != nil {
+		return description, false
+	}
+	return string(out), true
+}
+
+func printBackupCodecResults(results *Results) {
+	if results.Total == nil || results.Total.BackupTracks == 0 {
+		return
+	}
+	codecs := make(map[string]int)
+	for _, tester := range results.Testers {
+		for _, track := range tester.TrackStats {
+			if track.Kind == string(lksdk.TrackKindVideo) && track.Codec != "" {
+				codecs[track.Codec]++
+			}
+		}
+	}
+	table := util.CreateTable().
+		Headers("Codec", "Subscribed video tracks")
+	names := make([]string, 0, len(codecs))
+	for name := range codecs {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		table.Row(name, fmt.Sprint(codecs[name]))
+	}
+	fmt.Printf("\nBackup codec: %d backup tracks published on the server's request\n", results.Total.BackupTracks)
+	if len(names) > 0 {
+		fmt.Println(table)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pion/webrtc/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateBackupCodec(t *testing.T) {
+	params := func(video, backup string, only bool) Params {
+		p := Params{VideoCodec: video}
+		p.BackupCodec = backup
+		p.SubscribeBackupOnly = only
+		return p
+	}
+	require.NoError(t, ValidateBackupCodec(params("", "", false)))
+	require.NoError(t, ValidateBackupCodec(params("av1", "vp8", true)))
+	require.Error(t, ValidateBackupCodec(params("av1", "", true)))
+	require.Error(t, ValidateBackupCodec(params("av1", "vp9", false)))
+	require.Error(t, ValidateBackupCodec(params("vp8", "vp8", false)))
+	require.Error(t, ValidateBackupCodec(params("", "vp8", false)))
+
+	encrypted := params("av1", "vp8", false)
+	encrypted.E2EEKey = "secret"
+	require.Error(t, ValidateBackupCodec(encrypted))
+}
+
+func TestBackupOptions(t *testing.T) {
+	// without a backup codec, tracks are published alone
+	opts, err := NewLoadTester(TesterParams{}).backupOptions(webrtc.MimeTypeH264, "high", true)
+	require.NoError(t, err)
+	require.Empty(t, opts)
+
+	tester := NewLoadTester(TesterParams{BackupCodec: "vp8"})
+	// a primary of the backup codec needs no backup
+	opts, err = tester.backupOptions(webrtc.MimeTypeVP8, "high", false)
+	require.NoError(t, err)
+	require.Empty(t, opts)
+
+	opts, err = tester.backupOptions(webrtc.MimeTypeH264, "high", false)
+	require.NoError(t, err)
+	require.Len(t, opts, 1)
+	opts, err = tester.backupOptions(webrtc.MimeTypeH264, "high", true)
+	require.NoError(t, err)
+	require.Len(t, opts, 1)
+	// nothing is published until the server asks for the backup
+	require.Zero(t, tester.backup.count())
+}
+
+func TestKeepVideoCodec(t *testing.T) {
+	answer := strings.Join([]string{
+		"v=0",
+		"o=- 1 2 IN IP4 127.0.0.1",
+		"s=-",
+		"t=0 0",
+		"m=audio 9 UDP/TLS/RTP/SAVPF 111",
+		"a=rtpmap:111 opus/48000/2",
+		"m=video 9 UDP/TLS/RTP/SAVPF 96 97 45 98",
+		"a=rtpmap:96 VP8/90000",
+		"a=rtcp-fb:96 nack",
+		"a=rtpmap:97 rtx/90000",
+		"a=fmtp:97 apt=96",
+		"a=rtpmap:45 AV1/90000",
+		"a=rtcp-fb:45 nack",
+		"a=rtpmap:98 rtx/90000",
+		"a=fmtp:98 apt=45",
+		"a=rtcp-fb:* transport-cc",
+		"",
+	}, "\r\n")
+
+	restricted, ok := keepVideoCodec(answer, webrtc.MimeTypeVP8)
+	require.True(t, ok)
+	require.Contains(t, restricted, "m=video 9 UDP/TLS/RTP/SAVPF 96 97\r\n")
+	require.Contains(t, restricted, "a=fmtp:97 apt=96")
+	require.Contains(t, restricted, "a=rtcp-fb:* transport-cc")
+	require.Contains(t, restricted, "a=rtpmap:111 opus/48000/2")
+	require.NotContains(t, restricted, "AV1")
+	require.NotContains(t, restricted, "apt=45")
+
+	// without the backup, the answer is left alone
+	_, ok = keepVideoCodec(answer, webrtc.MimeTypeH264)
+	require.False(t, ok)
+}
@@ -0,0 +1,148 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/pion/webrtc/v4/pkg/media"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+
+	"github.com/livekit/livekit-cli/v2/pkg/provider"
+)
+
+// BitratePattern varies the bitrate of published video over time, by dropping frames
+type BitratePattern string
+
+const (
+	// BitratePatternSine swings smoothly between the floor and the full bitrate
+	BitratePatternSine BitratePattern = "sine"
+	// BitratePatternSawtooth climbs from the floor to the full bitrate, then drops back at once
+	BitratePatternSawtooth BitratePattern = "sawtooth"
+	// BitratePatternBurst sends at the floor, with a burst at the full bitrate at the start of each period
+	BitratePatternBurst BitratePattern = "burst"
+
+	DefaultBitratePatternPeriod = 30 * time.Second
+
+	// fraction of the frames kept at the lowest point of a pattern
+	bitratePatternFloor = 0.2
+	// fraction of each period a burst lasts
+	bitratePatternBurst = 0.2
+)
+
+// ParseBitratePattern checks a --bitrate-pattern value
+func ParseBitratePattern(s string) (BitratePattern, error) {
+	switch p := BitratePattern(s); p {
+	case "", BitratePatternSine, BitratePatternSawtooth, BitratePatternBurst:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown bitrate pattern %q, expected %q, %q or %q",
+		s, BitratePatternSine, BitratePatternSawtooth, BitratePatternBurst)
+}
+
+// level is the fraction of frames sent at elapsed into the pattern
+func (p BitratePattern) level(elapsed, period time.Duration) float64 {
+	phase := math.Mod(elapsed.Seconds(), period.Seconds()) / period.Seconds()
+	switch p {
+	case BitratePatternSine:
+		return bitratePatternFloor + (1-bitratePatternFloor)*(1-math.Cos(2*math.Pi*phase))/2
+	case BitratePatternSawtooth:
+		return bitratePatternFloor + (1-bitratePatternFloor)*phase
+	case BitratePatternBurst:
+		if phase < bitratePatternBurst {
+			return 1
+		}
+		return bitratePatternFloor
+	}
+	return 1
+}
+
+// patternedLayer drops the inter frames of a video layer to follow a bitrate pattern or ramp. Keyframes
+// are always sent, so subscribers keep receiving a stream they can resync to. Once a frame is
+// dropped, the rest of its group of pictures is too, since the frames after it refer to it, and
+// the credit they'd have used is spent on the next one. The time of dropped frames is carried
+// over so the writer keeps real time pacing.
+type patternedLayer struct {
+	lksdk.SampleProvider
+	mimeType string
+	// fraction of the frames sent, by the time since start
+	level func(elapsed time.Duration) float64
+	start time.Time
+	// fraction of a frame owed to the pattern
+	credit float64
+	// an inter frame was dropped since the last keyframe
+	dropping bool
+}
+
+func (p *patternedLayer) NextSample(ctx context.Context) (media.Sample, error) {
+	var skipped time.Duration
+	for {
+		sample, err := p.SampleProvider.NextSample(ctx)
+		if err != nil || !provider.IsInterFrame(p.mimeType, sample.Data) {
+			if err == nil {
+				p.dropping = false
+			}
+			sample.Duration += skipped
+			return sample, err
+		}
+		p.credit += p.level(time.Since(p.start))
+		if !p.dropping && p.credit >= 1 {
+			p.credit--
+			sample.Duration += skipped
+			return sample, nil
+		}
+		p.dropping = true
+		skipped += sample.Duration
+	}
+}
+
+// patterned applies the tester's bitrate pattern to a video layer
+func (t *LoadTester) patterned(layer lksdk.SampleProvider, mimeType string) lksdk.SampleProvider {
+	if t.params.BitratePattern == "" {
+		return layer
+	}
+	pattern := t.params.BitratePattern
+	period := cmp.Or(t.params.BitratePatternPeriod, DefaultBitratePatternPeriod)
+	return &patternedLayer{
+		SampleProvider: layer,
+		mimeType:       mimeType,
+		level: func(elapsed time.Duration) float64 {
+			return pattern.level(elapsed, period)
+		},
+		start: time.Now(),
+	}
+}
+
+// ramped starts a single video layer at the lowest level of the bitrate patterns, and raises it
+// to the full bitrate over the tester's PublishRamp
+func (t *LoadTester) ramped(layer lksdk.SampleProvider, mimeType string) lksdk.SampleProvider {
+	ramp := t.params.PublishRamp
+	if ramp <= 0 {
+		return layer
+	}
+	return &patternedLayer{
+		SampleProvider: layer,
+		mimeType:       mimeType,
+		level: func(elapsed time.Duration) float64 {
+			return bitratePatternFloor + (1-bitratePatternFloor)*min(elapsed.Seconds()/ramp.Seconds(), 1)
+		},
+		start: time.Now(),
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+	"github.com/pion/webrtc/v4/pkg/media"
+	"github.com/stretchr/testify/require"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+func TestParseBitratePattern(t *testing.T) {
+	for _, s := range []string{"", "sine", "sawtooth", "burst"} {
+		p, err := ParseBitratePattern(s)
+		require.NoError(t, err)
+		require.Equal(t, BitratePattern(s), p)
+	}
+	_, err := ParseBitratePattern("square")
+	require.Error(t, err)
+}
+
+func TestBitratePatternLevel(t *testing.T) {
+	period := 10 * time.Second
+	require.InDelta(t, bitratePatternFloor, BitratePatternSine.level(0, period), 1e-9)
+	require.InDelta(t, 1, BitratePatternSine.level(5*time.Second, period), 1e-9)
+	require.InDelta(t, bitratePatternFloor, BitratePatternSine.level(10*time.Second, period), 1e-9)
+
+	require.InDelta(t, bitratePatternFloor, BitratePatternSawtooth.level(0, period), 1e-9)
+	require.InDelta(t, 0.6, BitratePatternSawtooth.level(5*time.Second, period), 1e-9)
+	require.Less(t, BitratePatternSawtooth.level(11*time.Second, period), BitratePatternSawtooth.level(9*time.Second, period))
+
+	require.Equal(t, 1.0, BitratePatternBurst.level(time.Second, period))
+	require.Equal(t, bitratePatternFloor, BitratePatternBurst.level(5*time.Second, period))
+	require.Equal(t, 1.0, BitratePattern("").level(5*time.Second, period))
+}
+
+// vp8Frames provides a keyframe followed by inter frames
+type vp8Frames struct {
+	lksdk.BaseSampleProvider
+	n int
+}
+
+func (p *vp8Frames) NextSample(_ context.Context) (media.Sample, error) {
+	p.n++
+	data := []byte{0x11}
+	if p.n%30 == 1 {
+		data = []byte{0x10}
+	}
+	return media.Sample{Data: data, Duration: 33 * time.Millisecond}, nil
+}
+
+func TestPatternedLayer(t *testing.T) {
+	tester := NewLoadTester(TesterParams{BitratePattern: BitratePatternBurst, BitratePatternPeriod: time.Hour})
+	frames := &vp8Frames{}
+	layer := tester.patterned(frames, webrtc.MimeTypeVP8).(*patternedLayer)
+	// well past the burst
+	layer.start = time.Now().Add(-30 * time.Minute)
+
+	var sent, keyframes, last int
+	var duration time.Duration
+	for i := 0; i < 100; i++ {
+		sample, err := layer.NextSample(context.Background())
+		require.NoError(t, err)
+		sent++
+		duration += sample.Duration
+		if sample.Data[0] == 0x10 {
+			keyframes++
+		} else {
+			// inter frames are only sent right after the frame they refer to
+			require.Equal(t, last+1, frames.n)
+		}
+		last = frames.n
+	}
+	// every keyframe is sent, and inter frames use all the credit of the pattern, a fifth of them
+	require.Equal(t, (frames.n+29)/30, keyframes)
+	interFrames := frames.n - keyframes
+	require.InDelta(t, float64(interFrames)*bitratePatternFloor, float64(sent-keyframes)+layer.credit, 1e-6)
+	// and credit owed is at most what the dropped end of a group of pictures left over
+	require.Less(t, layer.credit, 30*bitratePatternFloor+1)
+	// time of the dropped frames is carried over
+	require.InDelta(t, float64(frames.n)*float64(33*time.Millisecond), float64(duration), float64(33*time.Millisecond))
+
+	require.Same(t, frames, NewLoadTester(TesterParams{}).patterned(frames, webrtc.MimeTypeVP8))
+}
+
+func TestRampedLayer(t *testing.T) {
+	tester := NewLoadTester(TesterParams{PublishRamp: 10 * time.Second})
+	frames := &vp8Frames{}
+	layer := tester.ramped(frames, webrtc.MimeTypeVP8).(*patternedLayer)
+	require.InDelta(t, bitratePatternFloor, layer.level(0), 1e-6)
+	require.InDelta(t, (1+bitratePatternFloor)/2, layer.level(5*time.Second), 1e-6)
+	require.Equal(t, 1.0, layer.level(time.Minute))
+
+	// once ramped up, every frame is sent
+	layer.start = time.Now().Add(-time.Minute)
+	for i := 0; i < 60; i++ {
+		_, err := layer.NextSample(context.Background())
+		require.NoError(t, err)
+	}
+	require.Equal(t, 60, frames.n)
+
+	require.Same(t, frames, NewLoadTester(TesterParams{}).ramped(frames, webrtc.MimeTypeVP8))
+}
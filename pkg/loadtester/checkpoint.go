@@ -0,0 +1,308 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+const (
+	// DefaultCheckpointInterval is how often a checkpoint is written during a run
+	DefaultCheckpointInterval = time.Minute
+	checkpointVersion         = 1
+)
+
+// Checkpoint is the state of a long run written to disk periodically, so that a crashed run
+// can be resumed by reattaching the same testers to the same rooms
+type Checkpoint struct {
+	Version int       `json:"version"`
+	SavedAt time.Time `json:"savedAt"`
+	// the run ended on its own rather than crashing or being interrupted
+	Finished       bool   `json:"finished"`
+	Room           string `json:"room"`
+	IdentityPrefix string `json:"identityPrefix"`
+	RoomCount      int    `json:"roomCount"`
+	// runs of the test so far, the first one and every resume
+	Segments int `json:"segments"`
+	// time all segments ran, and held the rooms once they were populated
+	ElapsedMs float64 `json:"elapsedMs"`
+	HeldMs    float64 `json:"heldMs"`
+	// stats of every tester, summed over all segments
+	Testers []*CheckpointTester `json:"testers"`
+}
+
+type CheckpointTester struct {
+	Name      string `json:"name"`
+	Identity  string `json:"identity"`
+	Room      string `json:"room"`
+	Role      Role   `json:"role,omitempty"`
+	Connected bool   `json:"connected"`
+	Packets   int64  `json:"packets"`
+	Bytes     int64  `json:"bytes"`
+	Dropped   int64  `json:"dropped"`
+	Errors    int64  `json:"errors"`
+}
+
+// ResumeResults are the totals of a resumed run over all of its segments
+type ResumeResults struct {
+	Segments   int     `json:"segments"`
+	ElapsedMs  float64 `json:"elapsedMs"`
+	HeldMs     float64 `json:"heldMs"`
+	Reattached int     `json:"reattached"`
+	Packets    int64   `json:"packets"`
+	Bytes      int64   `json:"bytes"`
+	Dropped    int64   `json:"dropped"`
+	Errors     int64   `json:"errors"`
+	LossRate   float64 `json:"lossRate"`
+	// the same totals for the segments before this one
+	PreviousPackets int64 `json:"previousPackets"`
+	PreviousBytes   int64 `json:"previousBytes"`
+	PreviousDropped int64 `json:"previousDropped"`
+	PreviousErrors  int64 `json:"previousErrors"`
+}
+
+// LoadCheckpoint reads a checkpoint to resume a run from
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c := &Checkpoint{}
+	if err = json.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s: %w", path, err)
+	}
+	if c.Version != checkpointVersion {
+		return nil, fmt.Errorf("checkpoint %s has version %d, expected %d", path, c.Version, checkpointVersion)
+	}
+	if c.Finished {
+		return nil, fmt.Errorf("checkpoint %s is of a run that already finished", path)
+	}
+	if c.Room == "" || c.IdentityPrefix == "" || c.RoomCount <= 0 {
+		return nil, fmt.Errorf("checkpoint %s does not name the rooms and testers to resume", path)
+	}
+	return c, nil
+}
+
+// resume reattaches to the rooms and identities of the checkpoint, for the rest of the duration
+// and without ramping up again
+func (p *Params) resume(c *Checkpoint) error {
+	p.Room = c.Room
+	p.IdentityPrefix = c.IdentityPrefix
+	p.RoomCount = c.RoomCount
+	if p.Duration > 0 {
+		held := time.Duration(c.HeldMs * float64(time.Millisecond))
+		if held >= p.Duration {
+			return fmt.Errorf("checkpointed run already held its rooms for %s of %s", held.Round(time.Second), p.Duration)
+		}
+		p.Duration -= held
+	}
+	p.Ramp = RampProfile{}
+	p.RoomStagger = 0
+	return nil
+}
+
+type checkpointer struct {
+	path      string
+	previous  *Checkpoint
+	startedAt time.Time
+}
+
+func newCheckpointer(params Params) *checkpointer {
+	if params.CheckpointPath == "" && params.Resume == nil {
+		return nil
+	}
+	return &checkpointer{path: params.CheckpointPath, previous: params.Resume, startedAt: time.Now()}
+}
+
+// saveCheckpoints writes a checkpoint every interval until the returned function is called
+func (t *LoadTest) saveCheckpoints(ctx context.Context, interval time.Duration) func() {
+	c := t.checkpoints
+	if c == nil || c.path == "" {
+		return func() {}
+	}
+	if interval <= 0 {
+		interval = DefaultCheckpointInterval
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if err := writeCheckpoint(c.path, t.checkpoint(false)); err != nil {
+				fmt.Println("could not write checkpoint:", err)
+			}
+		}
+	}()
+	return cancel
+}
+
+// checkpoint adds the stats of the testers started so far to the previous checkpoint
+func (t *LoadTest) checkpoint(finished bool) *Checkpoint {
+	c := t.checkpoints
+	cp := &Checkpoint{
+		Version:        checkpointVersion,
+		SavedAt:        time.Now(),
+		Finished:       finished,
+		Room:           t.Params.Room,
+		IdentityPrefix: t.Params.IdentityPrefix,
+		RoomCount:      t.Params.RoomCount,
+		Segments:       1,
+		ElapsedMs:      durationMs(time.Since(c.startedAt)),
+		HeldMs:         durationMs(t.status.heldFor()),
+	}
+	testers := make(map[string]*CheckpointTester)
+	if c.previous != nil {
+		cp.Segments += c.previous.Segments
+		cp.ElapsedMs += c.previous.ElapsedMs
+		cp.HeldMs += c.previous.HeldMs
+		for _, prev := range c.previous.Testers {
+			tester := *prev
+			tester.Connected = false
+			testers[tester.Name] = &tester
+		}
+	}
+
+	started := t.status.startedTesters()
+	stats := make(map[string]*testerStats, len(started))
+	names := make([]string, 0, len(started))
+	for _, tester := range started {
+		s := tester.getStats()
+		s.role = tester.params.Role
+		s.room = tester.params.Room
+		stats[tester.params.name] = s
+		names = append(names, tester.params.name)
+
+		ct := testers[tester.params.name]
+		if ct == nil {
+			ct = &CheckpointTester{Name: tester.params.name}
+			testers[ct.Name] = ct
+		}
+		ct.Identity = tester.identity()
+		ct.Room = tester.params.Room
+		ct.Role = tester.params.Role
+		ct.Connected = tester.IsRunning()
+	}
+	for _, r := range getResults(stats, names).Testers {
+		ct := testers[r.Name]
+		ct.Packets += r.Packets
+		ct.Bytes += r.Bytes
+		ct.Dropped += r.Dropped
+		ct.Errors += r.Errors
+	}
+
+	for _, ct := range testers {
+		cp.Testers = append(cp.Testers, ct)
+	}
+	slices.SortFunc(cp.Testers, func(a, b *CheckpointTester) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return cp
+}
+
+// finishCheckpoints writes the last checkpoint of a run, which cannot be resumed if it finished
+func (t *LoadTest) finishCheckpoints(finished bool) error {
+	c := t.checkpoints
+	if c == nil || c.path == "" {
+		return nil
+	}
+	return writeCheckpoint(c.path, t.checkpoint(finished))
+}
+
+// writeCheckpoint replaces the checkpoint at path, so a crash while writing keeps the previous one
+func writeCheckpoint(path string, c *Checkpoint) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := filepath.Join(filepath.Dir(path), fmt.Sprintf(".%s.%d", filepath.Base(path), rand.Int31()))
+	if err = os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// resumeResults adds the totals of the previous segments to those of this one
+func (c *checkpointer) resumeResults(total *TesterResults, roles []Role, held time.Duration) *ResumeResults {
+	if c == nil || c.previous == nil {
+		return nil
+	}
+	if len(roles) == 0 {
+		roles = []Role{RoleSubscriber}
+	}
+	prev := c.previous
+	r := &ResumeResults{
+		Segments:  prev.Segments + 1,
+		ElapsedMs: prev.ElapsedMs + durationMs(time.Since(c.startedAt)),
+		HeldMs:    prev.HeldMs + durationMs(held),
+	}
+	for _, tester := range prev.Testers {
+		if tester.Connected {
+			r.Reattached++
+		}
+		// totals are of the reported roles, like the rest of the report
+		if !slices.Contains(roles, tester.Role) {
+			continue
+		}
+		r.PreviousPackets += tester.Packets
+		r.PreviousBytes += tester.Bytes
+		r.PreviousDropped += tester.Dropped
+		r.PreviousErrors += tester.Errors
+	}
+	r.Packets = r.PreviousPackets + total.Packets
+	r.Bytes = r.PreviousBytes + total.Bytes
+	r.Dropped = r.PreviousDropped + total.Dropped
+	r.Errors = r.PreviousErrors + total.Errors
+	if r.Packets+r.Dropped > 0 {
+		r.LossRate = float64(r.Dropped) / float64(r.Packets+r.Dropped)
+	}
+	return r
+}
+
+func printResumeResults(results *Results) {
+	r := results.Resumed
+	if r == nil {
+		return
+	}
+	elapsed := time.Duration(r.ElapsedMs * float64(time.Millisecond))
+	fmt.Printf("\nResumed run, %d segments over %s, reattached %d testers:\n", r.Segments, elapsed.Round(time.Second), r.Reattached)
+	table := util.CreateTable().
+		Headers("", "Packets", "Bytes", "Pkt. Loss", "Errors")
+	table.Row("Previous segments", strconv.FormatInt(r.PreviousPackets, 10), strconv.FormatInt(r.PreviousBytes, 10),
+		formatLossRate(r.PreviousPackets, r.PreviousDropped), strconv.FormatInt(r.PreviousErrors, 10))
+	table.Row("All segments", strconv.FormatInt(r.Packets, 10), strconv.FormatInt(r.Bytes, 10),
+		formatLossRate(r.Packets, r.Dropped), strconv.FormatInt(r.Errors, 10))
+	fmt.Println(table)
+}
@@ -0,0 +1,119 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckpoint(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	previous := &Checkpoint{
+		Version:        checkpointVersion,
+		Room:           "soak",
+		IdentityPrefix: "abc",
+		RoomCount:      2,
+		Segments:       1,
+		ElapsedMs:      60000,
+		HeldMs:         30000,
+		Testers: []*CheckpointTester{
+			{Name: "Sub 0", Identity: "abc_sub-1", Room: "soak_0", Role: RoleSubscriber, Connected: true, Packets: 100, Bytes: 1000, Dropped: 10},
+			{Name: "Sub 1", Identity: "abc_sub-2", Room: "soak_0", Role: RoleSubscriber, Connected: true, Packets: 50, Errors: 1},
+			{Name: "Pub 0", Identity: "abc_pub-video-0", Room: "soak_0", Role: RoleVideoPublisher, Connected: true, Packets: 500},
+		},
+	}
+
+	test := NewLoadTest(Params{CheckpointPath: path, Resume: previous})
+	require.NoError(t, test.Params.resume(previous))
+	test.checkpoints = newCheckpointer(test.Params)
+	test.status.begin(1)
+	test.status.addTester(NewLoadTester(TesterParams{
+		name:           "Sub 0",
+		Room:           "soak_0",
+		IdentityPrefix: "abc",
+		Sequence:       1,
+		Role:           RoleSubscriber,
+	}))
+
+	require.NoError(t, test.finishCheckpoints(false))
+	c, err := LoadCheckpoint(path)
+	require.NoError(t, err)
+	require.Equal(t, "soak", c.Room)
+	require.Equal(t, 2, c.RoomCount)
+	require.Equal(t, 2, c.Segments)
+	require.GreaterOrEqual(t, c.ElapsedMs, 60000.0)
+	require.Equal(t, 30000.0, c.HeldMs)
+	require.Len(t, c.Testers, 3)
+	// sorted by name, with the stats of this segment added to the previous ones
+	require.Equal(t, "Pub 0", c.Testers[0].Name)
+	require.False(t, c.Testers[0].Connected)
+	require.Equal(t, "abc_sub-1", c.Testers[1].Identity)
+	require.Equal(t, int64(100), c.Testers[1].Packets)
+	require.Equal(t, int64(1), c.Testers[2].Errors)
+
+	resumed := test.checkpoints.resumeResults(&TesterResults{Packets: 40, Dropped: 10, Errors: 2}, nil, 15*time.Second)
+	require.Equal(t, 2, resumed.Segments)
+	require.Equal(t, 3, resumed.Reattached)
+	require.Equal(t, 45000.0, resumed.HeldMs)
+	// publishers aren't part of the reported totals
+	require.Equal(t, int64(150), resumed.PreviousPackets)
+	require.Equal(t, int64(190), resumed.Packets)
+	require.Equal(t, int64(20), resumed.Dropped)
+	require.Equal(t, int64(3), resumed.Errors)
+	require.InDelta(t, 20.0/210, resumed.LossRate, 1e-9)
+	require.Nil(t, (*checkpointer)(nil).resumeResults(&TesterResults{}, nil, 0))
+
+	require.NoError(t, test.finishCheckpoints(true))
+	_, err = LoadCheckpoint(path)
+	require.ErrorContains(t, err, "already finished")
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func TestLoadCheckpoint(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"invalid":  "{",
+		"version":  `{"version": 2, "room": "r", "identityPrefix": "p", "roomCount": 1}`,
+		"unnamed":  `{"version": 1, "roomCount": 1}`,
+		"finished": `{"version": 1, "room": "r", "identityPrefix": "p", "roomCount": 1, "finished": true}`,
+	} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		_, err := LoadCheckpoint(path)
+		require.Error(t, err, name)
+	}
+}
+
+func TestResumeParams(t *testing.T) {
+	c := &Checkpoint{Room: "soak", IdentityPrefix: "abc", RoomCount: 3, HeldMs: float64(time.Hour / time.Millisecond)}
+	params := Params{Duration: 3 * time.Hour, RoomStagger: time.Second, Ramp: RampProfile{Kind: "linear"}}
+	require.NoError(t, params.resume(c))
+	require.Equal(t, "soak", params.Room)
+	require.Equal(t, 3, params.RoomCount)
+	require.Equal(t, 2*time.Hour, params.Duration)
+	require.Zero(t, params.RoomStagger)
+	require.Empty(t, params.Ramp.Kind)
+
+	params = Params{Duration: time.Hour}
+	require.Error(t, params.resume(c))
+}
@@ -0,0 +1,383 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pion/webrtc/v4/pkg/media"
+
+	"github.com/livekit/protocol/livekit"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+
+	provider2 "github.com/livekit/livekit-cli/v2/pkg/provider"
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+const (
+	featureAdaptiveStream = "adaptive-stream"
+	featureDynacast       = "dynacast"
+	featureAutoSubscribe  = "auto-subscribe"
+	featureNone           = "none"
+	featureProtocol       = "protocol="
+
+	// synthetic location labels and network conditions of a cohort
+	cohortRegion    = "region="
+	cohortISP       = "isp="
+	cohortLoss      = "loss="
+	cohortLatency   = "latency="
+	cohortJitter    = "jitter="
+	cohortBandwidth = "bandwidth="
+
+	// participant attributes carrying the location labels of a tester's cohort
+	RegionAttribute = "lk.loadtest.region"
+	ISPAttribute    = "lk.loadtest.isp"
+)
+
+// SDKFeatures are client behaviors that can differ between cohorts of testers
+type SDKFeatures struct {
+	// subscribers request video layers sized to the layout, otherwise every video is received at full quality
+	AdaptiveStream bool
+	// publishers pause simulcast layers that no subscriber in this process is receiving
+	Dynacast bool
+	// subscribers receive every published track, regardless of the layout
+	AutoSubscribe bool
+	// protocol version announced when joining, lksdk.PROTOCOL when 0
+	Protocol int
+}
+
+// features of testers that aren't assigned to a cohort
+var defaultFeatures = SDKFeatures{AdaptiveStream: true}
+
+func (f SDKFeatures) String() string {
+	var names []string
+	if f.AdaptiveStream {
+		names = append(names, featureAdaptiveStream)
+	}
+	if f.Dynacast {
+		names = append(names, featureDynacast)
+	}
+	if f.AutoSubscribe {
+		names = append(names, featureAutoSubscribe)
+	}
+	if f.Protocol != 0 {
+		names = append(names, featureProtocol+strconv.Itoa(f.Protocol))
+	}
+	if len(names) == 0 {
+		return featureNone
+	}
+	return strings.Join(names, "+")
+}
+
+// Cohort is a share of the testers in each room running with the same SDK features
+type Cohort struct {
+	Features SDKFeatures
+	// share of the testers relative to the other cohorts
+	Weight int
+	// synthetic location of the testers, sent as participant attributes and broken down in the report
+	Region string
+	ISP    string
+	// network conditions of the testers, replacing the impairment of the test when enabled
+	Impairment Impairment
+}
+
+// ParseCohort parses a + separated feature list with an optional weight, e.g. adaptive-stream+dynacast:3.
+// The list can also label the cohort with region=NAME and isp=NAME, and impair its network with
+// loss=FRACTION, latency=DURATION, jitter=DURATION and bandwidth=BITRATE.
+func ParseCohort(str string) (*Cohort, error) {
+	c := &Cohort{Weight: 1}
+	list, weight, ok := strings.Cut(strings.TrimSpace(str), ":")
+	if ok {
+		w, err := strconv.Atoi(weight)
+		if err != nil || w <= 0 {
+			return nil, fmt.Errorf("invalid cohort weight %q", weight)
+		}
+		c.Weight = w
+	}
+	hasFeatures := false
+	for _, name := range strings.Split(list, "+") {
+		name = strings.TrimSpace(name)
+		if isCohortCondition(name) {
+			if err := c.parseCondition(name); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		hasFeatures = true
+		if version, ok := strings.CutPrefix(name, featureProtocol); ok {
+			protocol, err := strconv.Atoi(version)
+			if err != nil || protocol < 1 || protocol > lksdk.PROTOCOL {
+				return nil, fmt.Errorf("invalid protocol version %q, must be between 1 and %d", version, lksdk.PROTOCOL)
+			}
+			c.Features.Protocol = protocol
+			continue
+		}
+		switch name {
+		case featureAdaptiveStream:
+			c.Features.AdaptiveStream = true
+		case featureDynacast:
+			c.Features.Dynacast = true
+		case featureAutoSubscribe:
+			c.Features.AutoSubscribe = true
+		case featureNone:
+		default:
+			return nil, fmt.Errorf("unknown feature %q, choose from %s, %s, %s, %sN or %s",
+				name, featureAdaptiveStream, featureDynacast, featureAutoSubscribe, featureProtocol, featureNone)
+		}
+	}
+	if !hasFeatures {
+		// a cohort that only sets a location or network keeps the usual SDK behavior
+		c.Features = defaultFeatures
+	}
+	return c, nil
+}
+
+func isCohortCondition(name string) bool {
+	for _, prefix := range []string{cohortRegion, cohortISP, cohortLoss, cohortLatency, cohortJitter, cohortBandwidth} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Cohort) parseCondition(name string) error {
+	key, value, _ := strings.Cut(name, "=")
+	if value == "" {
+		return fmt.Errorf("missing value for cohort %s", key)
+	}
+	var err error
+	switch key + "=" {
+	case cohortRegion:
+		c.Region = value
+	case cohortISP:
+		c.ISP = value
+	case cohortLoss:
+		c.Impairment.PacketLoss, err = strconv.ParseFloat(value, 64)
+		if err != nil || c.Impairment.PacketLoss < 0 || c.Impairment.PacketLoss > 1 {
+			return fmt.Errorf("invalid cohort loss %q, expected a fraction between 0 and 1", value)
+		}
+	case cohortLatency:
+		if c.Impairment.Latency, err = time.ParseDuration(value); err != nil || c.Impairment.Latency < 0 {
+			return fmt.Errorf("invalid cohort latency %q", value)
+		}
+	case cohortJitter:
+		if c.Impairment.Jitter, err = time.ParseDuration(value); err != nil || c.Impairment.Jitter < 0 {
+			return fmt.Errorf("invalid cohort jitter %q", value)
+		}
+	case cohortBandwidth:
+		if c.Impairment.BandwidthCap, err = ParseBitrate(value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Label is the location of the cohort's testers, e.g. eu-west/isp-a, empty without region and ISP
+func (c *Cohort) Label() string {
+	var parts []string
+	for _, part := range []string{c.Region, c.ISP} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func (c *Cohort) String() string {
+	var parts []string
+	if label := c.Label(); label != "" {
+		parts = append(parts, label)
+	}
+	parts = append(parts, c.Features.String())
+	if c.Impairment.enabled() {
+		parts = append(parts, c.Impairment.String())
+	}
+	return strings.Join(parts, ", ")
+}
+
+// cohortFor assigns the i-th tester of a room to a cohort, spreading each cohort evenly over the join order
+func cohortFor(cohorts []*Cohort, i int) *Cohort {
+	total := 0
+	for _, c := range cohorts {
+		total += c.Weight
+	}
+	if total == 0 {
+		return nil
+	}
+	slot := i % total
+	for _, c := range cohorts {
+		if slot < c.Weight {
+			return c
+		}
+		slot -= c.Weight
+	}
+	return nil
+}
+
+func (t *LoadTester) features() SDKFeatures {
+	if t.params.Cohort == nil {
+		return defaultFeatures
+	}
+	return t.params.Cohort.Features
+}
+
+// impairment is the network of the tester's cohort, or of the whole test
+func (t *LoadTester) impairment() Impairment {
+	if t.params.Cohort != nil && t.params.Cohort.Impairment.enabled() {
+		return t.params.Cohort.Impairment
+	}
+	return t.params.Impairment
+}
+
+// layerDemand tracks the video quality each subscriber requests from each publisher in this process,
+// standing in for the subscribed quality updates the SDK doesn't surface
+type layerDemand struct {
+	lock sync.Mutex
+	// publisher identity -> subscriber identity -> requested quality
+	requested map[string]map[string]livekit.VideoQuality
+}
+
+func newLayerDemand() *layerDemand {
+	return &layerDemand{requested: make(map[string]map[string]livekit.VideoQuality)}
+}
+
+func (d *layerDemand) set(publisher, subscriber string, quality livekit.VideoQuality) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	subs := d.requested[publisher]
+	if subs == nil {
+		subs = make(map[string]livekit.VideoQuality)
+		d.requested[publisher] = subs
+	}
+	subs[subscriber] = quality
+}
+
+// wanted is true while a subscriber requests quality or above. Publishers without
+// known subscribers send every layer, as their subscribers may be in another process.
+func (d *layerDemand) wanted(publisher string, quality livekit.VideoQuality) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	subs := d.requested[publisher]
+	if len(subs) == 0 {
+		return true
+	}
+	for _, q := range subs {
+		if q != livekit.VideoQuality_OFF && q >= quality {
+			return true
+		}
+	}
+	return false
+}
+
+// dynacastLayer pauses a simulcast layer while nobody requests it
+type dynacastLayer struct {
+	lksdk.SampleProvider
+	wanted func() bool
+}
+
+func (p *dynacastLayer) NextSample(ctx context.Context) (media.Sample, error) {
+	var skipped time.Duration
+	for {
+		sample, err := p.SampleProvider.NextSample(ctx)
+		if err != nil || p.wanted() {
+			// carry the paused time so the writer neither bursts nor drifts on resume
+			sample.Duration += skipped
+			return sample, err
+		}
+		skipped += sample.Duration
+		select {
+		case <-ctx.Done():
+			return sample, io.EOF
+		case <-time.After(sample.Duration):
+		}
+	}
+}
+
+// layerProvider wraps the provider of a simulcast layer with the bitrate meter, the tester's dynacast behavior and send counter.
+// In a dynacast check, the layer follows the server's requests instead.
+func (t *LoadTester) layerProvider(looper provider2.VideoLooper, quality livekit.VideoQuality, check *dynacastTrack) lksdk.SampleProvider {
+	name := "video " + strings.ToLower(quality.String())
+	mimeType := looper.Codec().MimeType
+	provider := t.encrypted(t.measureLayer(t.patterned(looper, mimeType), name, int64(looper.ToLayer(quality).Bitrate)), mimeType)
+	if check != nil {
+		provider = check.layer(provider, quality)
+	} else if t.features().Dynacast && t.params.demand != nil {
+		identity := t.identity()
+		provider = &dynacastLayer{
+			SampleProvider: provider,
+			wanted: func() bool {
+				return t.params.demand.wanted(identity, quality)
+			},
+		}
+	}
+	return t.countSent(provider)
+}
+
+func printCohortStats(stats map[string]*testerStats, names []string) {
+	byCohort := make(map[string]map[string]*summary)
+	for _, name := range names {
+		s := stats[name]
+		if s.cohort == "" {
+			continue
+		}
+		if byCohort[s.cohort] == nil {
+			byCohort[s.cohort] = make(map[string]*summary)
+		}
+		byCohort[s.cohort][name] = getTesterSummary(s)
+	}
+	if len(byCohort) == 0 {
+		return
+	}
+
+	cohorts := make([]string, 0, len(byCohort))
+	for c := range byCohort {
+		cohorts = append(cohorts, c)
+	}
+	sort.Strings(cohorts)
+
+	table := util.CreateTable().
+		Headers("Cohort", "Subscribers", "Tracks", "Bitrate (avg)", "Pkt. Loss", "Errors", "Compatible")
+	for _, c := range cohorts {
+		summaries := byCohort[c]
+		s := getTestSummary(summaries)
+		table.Row(
+			c,
+			strconv.Itoa(len(summaries)),
+			fmt.Sprintf("%d/%d", s.tracks, s.expected),
+			formatBitrate(s.bytes/int64(len(summaries)), s.elapsed),
+			formatLossRate(s.packets, s.dropped),
+			strconv.FormatInt(s.errCount, 10),
+			formatYesNo(s.errCount == 0 && s.tracks >= s.expected),
+		)
+	}
+	fmt.Println("\nCohorts:")
+	fmt.Println(table)
+}
+
+// printLabelResults breaks the totals down by the location labels of the cohorts
+func printLabelResults(results *Results) {
+	if len(results.Labels) == 0 {
+		return
+	}
+	printResultGroups("Locations", "Location", results.Labels, results.Total)
+}
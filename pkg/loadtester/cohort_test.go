@@ -0,0 +1,112 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+func TestParseCohort(t *testing.T) {
+	c, err := ParseCohort("adaptive-stream+dynacast:3")
+	require.NoError(t, err)
+	require.Equal(t, SDKFeatures{AdaptiveStream: true, Dynacast: true}, c.Features)
+	require.Equal(t, 3, c.Weight)
+	require.Equal(t, "adaptive-stream+dynacast", c.String())
+
+	c, err = ParseCohort("none")
+	require.NoError(t, err)
+	require.Equal(t, SDKFeatures{}, c.Features)
+	require.Equal(t, 1, c.Weight)
+
+	c, err = ParseCohort("adaptive-stream+protocol=8:2")
+	require.NoError(t, err)
+	require.Equal(t, SDKFeatures{AdaptiveStream: true, Protocol: 8}, c.Features)
+	require.Equal(t, "adaptive-stream+protocol=8", c.String())
+
+	c, err = ParseCohort("region=eu-west+isp=isp-a+loss=0.02+latency=80ms+jitter=20ms+bandwidth=2mbps:2")
+	require.NoError(t, err)
+	require.Equal(t, defaultFeatures, c.Features)
+	require.Equal(t, Impairment{PacketLoss: 0.02, Latency: 80 * time.Millisecond, Jitter: 20 * time.Millisecond, BandwidthCap: 2e6}, c.Impairment)
+	require.Equal(t, 2, c.Weight)
+	require.Equal(t, "eu-west/isp-a", c.Label())
+	require.Equal(t, "eu-west/isp-a, adaptive-stream, 2.0% loss, 80ms latency, 20ms jitter, 2.0mbps cap", c.String())
+
+	c, err = ParseCohort("none+isp=isp-b")
+	require.NoError(t, err)
+	require.Equal(t, SDKFeatures{}, c.Features)
+	require.Equal(t, "isp-b", c.Label())
+
+	for _, invalid := range []string{"", "simulcast", "dynacast:0", "dynacast:x", "protocol=0", "protocol=99", "protocol=x",
+		"region=", "loss=2", "latency=fast", "bandwidth=lots"} {
+		_, err = ParseCohort(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestCohortFor(t *testing.T) {
+	a := &Cohort{Weight: 3}
+	b := &Cohort{Weight: 1}
+	var assigned []*Cohort
+	for i := 0; i < 8; i++ {
+		assigned = append(assigned, cohortFor([]*Cohort{a, b}, i))
+	}
+	require.Equal(t, []*Cohort{a, a, a, b, a, a, a, b}, assigned)
+	require.Nil(t, cohortFor(nil, 0))
+}
+
+func TestCohortLabels(t *testing.T) {
+	west := &Cohort{Weight: 1, Region: "eu-west", Impairment: Impairment{PacketLoss: 0.1}}
+	tester := &LoadTester{params: TesterParams{Cohort: west, Impairment: Impairment{Jitter: time.Millisecond}}}
+	require.Equal(t, west.Impairment, tester.impairment())
+	tester.params.Cohort = &Cohort{Weight: 1}
+	require.Equal(t, Impairment{Jitter: time.Millisecond}, tester.impairment())
+
+	stats := map[string]*testerStats{
+		"a": {label: "eu-west"},
+		"b": {label: "us-east"},
+		"c": {label: "eu-west"},
+		"d": {},
+	}
+	results := getResults(stats, []string{"a", "b", "c", "d"})
+	require.Len(t, results.Labels, 2)
+	require.Equal(t, "eu-west", results.Labels[0].Name)
+	require.Equal(t, 2, results.Labels[0].Testers)
+	require.Equal(t, "us-east", results.Labels[1].Name)
+	require.Equal(t, 1, results.Labels[1].Testers)
+
+	merged := mergeResults([]*Results{results, getResults(stats, []string{"b"})})
+	require.Len(t, merged.Labels, 2)
+	require.Equal(t, 2, merged.Labels[1].Testers)
+}
+
+func TestLayerDemand(t *testing.T) {
+	d := newLayerDemand()
+	require.True(t, d.wanted("pub", livekit.VideoQuality_HIGH))
+
+	d.set("pub", "sub1", livekit.VideoQuality_LOW)
+	require.True(t, d.wanted("pub", livekit.VideoQuality_LOW))
+	require.False(t, d.wanted("pub", livekit.VideoQuality_MEDIUM))
+
+	d.set("pub", "sub2", livekit.VideoQuality_HIGH)
+	require.True(t, d.wanted("pub", livekit.VideoQuality_HIGH))
+
+	d.set("pub", "sub2", livekit.VideoQuality_OFF)
+	require.False(t, d.wanted("pub", livekit.VideoQuality_HIGH))
+}
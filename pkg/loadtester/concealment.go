@@ -0,0 +1,62 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+// duration of audio in each opus packet sent by publishers
+const opusPacketDuration = 20 * time.Millisecond
+
+func printConcealmentStats(stats map[string]*testerStats, names []string) {
+	table := util.CreateTable().
+		Headers("Tester", "Audio Tracks", "Concealed Pkts.", "Concealed Time", "Events", "Avg. Burst")
+	rows := 0
+	for _, name := range names {
+		var tracks, packets, concealed, events int64
+		for _, ts := range stats[name].trackStats {
+			if ts.kind != lksdk.TrackKindAudio {
+				continue
+			}
+			tracks++
+			packets += ts.packets.Load()
+			concealed += ts.concealedPackets.Load()
+			events += ts.concealmentEvents.Load()
+		}
+		if events == 0 {
+			continue
+		}
+		table.Row(
+			name,
+			strconv.FormatInt(tracks, 10),
+			fmt.Sprintf("%d (%s%%)", concealed, formatPercentage(concealed, packets+concealed)),
+			(time.Duration(concealed) * opusPacketDuration).String(),
+			strconv.FormatInt(events, 10),
+			fmt.Sprintf("%.1f", float64(concealed)/float64(events)),
+		)
+		rows++
+	}
+	if rows > 0 {
+		fmt.Println("\nAudio concealment:")
+		fmt.Println(table)
+	}
+}
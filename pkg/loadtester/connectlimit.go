@@ -0,0 +1,160 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+var errConnectQueueClosed = errors.New("test stopped while waiting to connect")
+
+// connectLimiter caps the signaling connections testers attempt at once. Testers past the cap
+// wait their turn, instead of piling dials on a server that is slow to accept and timing out.
+type connectLimiter struct {
+	limit int
+	slots chan struct{}
+	done  chan struct{}
+	once  sync.Once
+
+	lock    sync.Mutex
+	waiting int
+	results ConnectQueueResults
+	waits   histogram
+}
+
+// newConnectLimiter returns nil for no limit
+func newConnectLimiter(limit int) *connectLimiter {
+	if limit <= 0 {
+		return nil
+	}
+	return &connectLimiter{
+		limit: limit,
+		slots: make(chan struct{}, limit),
+		done:  make(chan struct{}),
+	}
+}
+
+// acquire waits for a free connection slot, failing when the limiter is closed meanwhile
+func (l *connectLimiter) acquire() error {
+	if l == nil {
+		return nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+		l.record(0)
+		return nil
+	default:
+	}
+
+	l.lock.Lock()
+	l.waiting++
+	l.results.PeakQueued = max(l.results.PeakQueued, l.waiting)
+	l.lock.Unlock()
+	start := time.Now()
+	defer func() {
+		l.lock.Lock()
+		l.waiting--
+		l.lock.Unlock()
+	}()
+
+	select {
+	case l.slots <- struct{}{}:
+		l.record(time.Since(start))
+		return nil
+	case <-l.done:
+		return errConnectQueueClosed
+	}
+}
+
+func (l *connectLimiter) record(wait time.Duration) {
+	l.lock.Lock()
+	l.results.Connects++
+	if wait > 0 {
+		l.results.Queued++
+	}
+	l.lock.Unlock()
+	l.waits.record(wait)
+}
+
+func (l *connectLimiter) release() {
+	if l == nil {
+		return
+	}
+	<-l.slots
+}
+
+// close fails the attempts still waiting, when the test is stopped
+func (l *connectLimiter) close() {
+	if l == nil {
+		return
+	}
+	l.once.Do(func() {
+		close(l.done)
+	})
+}
+
+// ConnectQueueResults describe how long testers waited for a connection slot under
+// --max-concurrent-connects
+type ConnectQueueResults struct {
+	Limit int `json:"limit"`
+	// connection attempts, and those that had to wait for a slot
+	Connects int `json:"connects"`
+	Queued   int `json:"queued"`
+	// most attempts waiting at once
+	PeakQueued int     `json:"peakQueued"`
+	WaitP50Ms  float64 `json:"waitP50Ms"`
+	WaitP95Ms  float64 `json:"waitP95Ms"`
+	WaitMaxMs  float64 `json:"waitMaxMs"`
+}
+
+func (l *connectLimiter) finish() *ConnectQueueResults {
+	if l == nil {
+		return nil
+	}
+	p := l.waits.percentiles()
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	r := l.results
+	r.Limit = l.limit
+	r.WaitP50Ms = durationMs(p.p50)
+	r.WaitP95Ms = durationMs(p.p95)
+	r.WaitMaxMs = durationMs(p.max)
+	return &r
+}
+
+func printConnectQueueResults(results *Results) {
+	r := results.ConnectQueue
+	if r == nil {
+		return
+	}
+	fmt.Printf("\nConnect queue: at most %d connecting at once, %d of %d attempts waited, up to %d at a time\n",
+		r.Limit, r.Queued, r.Connects, r.PeakQueued)
+	if r.Queued == 0 {
+		return
+	}
+	table := util.CreateTable().
+		Headers("Wait p50", "Wait p95", "Wait Max")
+	table.Row(
+		fmt.Sprintf("%.0fms", r.WaitP50Ms),
+		fmt.Sprintf("%.0fms", r.WaitP95Ms),
+		fmt.Sprintf("%.0fms", r.WaitMaxMs),
+	)
+	fmt.Println(table)
+}
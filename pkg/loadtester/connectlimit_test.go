@@ -0,0 +1,73 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+)
+
+func TestConnectLimiter(t *testing.T) {
+	var none *connectLimiter
+	require.Nil(t, newConnectLimiter(0))
+	require.NoError(t, none.acquire())
+	none.release()
+	none.close()
+	require.Nil(t, none.finish())
+
+	l := newConnectLimiter(2)
+	var active, peak atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, l.acquire())
+			n := active.Inc()
+			for p := peak.Load(); n > p && !peak.CompareAndSwap(p, n); p = peak.Load() {
+			}
+			time.Sleep(20 * time.Millisecond)
+			active.Dec()
+			l.release()
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, int32(2), peak.Load())
+
+	r := l.finish()
+	require.Equal(t, 2, r.Limit)
+	require.Equal(t, 6, r.Connects)
+	require.GreaterOrEqual(t, r.Queued, 4)
+	require.GreaterOrEqual(t, r.PeakQueued, 1)
+	require.Greater(t, r.WaitMaxMs, 10.0)
+}
+
+func TestConnectLimiterClose(t *testing.T) {
+	l := newConnectLimiter(1)
+	require.NoError(t, l.acquire())
+
+	failed := make(chan error)
+	go func() {
+		failed <- l.acquire()
+	}()
+	time.Sleep(10 * time.Millisecond)
+	l.close()
+	require.ErrorIs(t, <-failed, errConnectQueueClosed)
+	l.close()
+}
@@ -0,0 +1,182 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+const (
+	DefaultDataPacketSize = 1024
+	DefaultDataRate       = 10
+
+	dataTopic = "lk.loadtest.data"
+	// sequence number and send time
+	dataHeaderSize = 16
+	maxDataPacket  = 64 * 1024
+)
+
+// ValidateDataParams checks the data channel load settings
+func ValidateDataParams(params Params) error {
+	if params.DataPublishers == 0 {
+		return nil
+	}
+	if params.DataPacketSize < dataHeaderSize || params.DataPacketSize > maxDataPacket {
+		return fmt.Errorf("data packet size must be between %d and %d bytes", dataHeaderSize, maxDataPacket)
+	}
+	if params.DataRate <= 0 {
+		return fmt.Errorf("data rate must be positive")
+	}
+	return nil
+}
+
+// dataSender tracks the sequence numbers a subscriber received from one data publisher
+type dataSender struct {
+	first      uint64
+	highest    uint64
+	received   int64
+	outOfOrder int64
+}
+
+type dataStats struct {
+	received   int64
+	expected   int64
+	outOfOrder int64
+}
+
+// publishData sends packets of size bytes at rate per second until the tester stops
+func (t *LoadTester) publishData(size int, perSecond float64, reliable bool) {
+	limiter := rate.NewLimiter(rate.Limit(perSecond), 1)
+	var seq uint64
+	for t.IsRunning() {
+		if err := limiter.Wait(context.Background()); err != nil {
+			return
+		}
+		payload := make([]byte, size)
+		binary.BigEndian.PutUint64(payload, seq)
+		binary.BigEndian.PutUint64(payload[8:], uint64(time.Now().UnixNano()))
+		err := t.room.LocalParticipant.PublishDataPacket(
+			&lksdk.UserDataPacket{Payload: payload, Topic: dataTopic},
+			lksdk.WithDataPublishReliable(reliable),
+		)
+		if err != nil {
+			t.dataErrors.Inc()
+			continue
+		}
+		seq++
+		t.dataSent.Inc()
+	}
+}
+
+func (t *LoadTester) onDataPacket(data lksdk.DataPacket, params lksdk.DataReceiveParams) {
+	packet, ok := data.(*lksdk.UserDataPacket)
+	if !ok || !t.params.Subscribe {
+		return
+	}
+	switch packet.Topic {
+	case dataTopic:
+		t.onDataMessage(params.SenderIdentity, packet.Payload)
+	case latencyTopic:
+		t.onLatencyStamp(packet.Payload)
+	}
+}
+
+func (t *LoadTester) onDataMessage(sender string, payload []byte) {
+	if len(payload) < dataHeaderSize {
+		return
+	}
+	seq := binary.BigEndian.Uint64(payload)
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	s := t.dataSenders[sender]
+	if s == nil {
+		s = &dataSender{first: seq, highest: seq}
+		t.dataSenders[sender] = s
+	} else if seq <= s.highest {
+		s.outOfOrder++
+	} else {
+		s.highest = seq
+	}
+	s.received++
+}
+
+// reset expects the packets after the highest one received so far
+func (s *dataSender) reset() {
+	// highest-first+1 wraps to 0 until the next packet arrives
+	s.first = s.highest + 1
+	s.received = 0
+	s.outOfOrder = 0
+}
+
+func (t *LoadTester) getDataStats() *dataStats {
+	if len(t.dataSenders) == 0 {
+		return nil
+	}
+	stats := &dataStats{}
+	for _, s := range t.dataSenders {
+		stats.received += s.received
+		stats.expected += int64(s.highest-s.first) + 1
+		stats.outOfOrder += s.outOfOrder
+	}
+	return stats
+}
+
+func printDataStats(stats map[string]*testerStats, names []string, reliable bool) {
+	var sent, sendErrors int64
+	for _, s := range stats {
+		if s.role.IsPublisher() {
+			sent += s.dataSent
+			sendErrors += s.dataErrors
+		}
+	}
+
+	table := util.CreateTable().
+		Headers("Tester", "Received", "Delivery", "Out of Order")
+	rows := 0
+	for _, name := range names {
+		d := stats[name].data
+		if d == nil {
+			continue
+		}
+		table.Row(
+			name,
+			fmt.Sprint(d.received),
+			fmt.Sprintf("%.2f%%", 100*float64(min(d.received, d.expected))/float64(d.expected)),
+			fmt.Sprint(d.outOfOrder),
+		)
+		rows++
+	}
+	if sent == 0 && rows == 0 {
+		return
+	}
+	channel := "lossy"
+	if reliable {
+		channel = "reliable"
+	}
+	fmt.Printf("\nData channels (%s, %d messages sent, %d send errors):\n", channel, sent, sendErrors)
+	if rows > 0 {
+		fmt.Println(table)
+	}
+}
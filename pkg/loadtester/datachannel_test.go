@@ -0,0 +1,59 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+func dataMessage(seq uint64) *lksdk.UserDataPacket {
+	payload := make([]byte, dataHeaderSize)
+	binary.BigEndian.PutUint64(payload, seq)
+	return &lksdk.UserDataPacket{Payload: payload, Topic: dataTopic}
+}
+
+func TestDataMessageOrdering(t *testing.T) {
+	tester := NewLoadTester(TesterParams{Subscribe: true})
+	from := func(identity string) lksdk.DataReceiveParams {
+		return lksdk.DataReceiveParams{SenderIdentity: identity}
+	}
+
+	// seq 3 is lost, 2 arrives after 4
+	for _, seq := range []uint64{0, 1, 4, 2, 5} {
+		tester.onDataPacket(dataMessage(seq), from("pub_a"))
+	}
+	// a subscriber joining late starts counting from the first message it sees
+	for _, seq := range []uint64{10, 11} {
+		tester.onDataPacket(dataMessage(seq), from("pub_b"))
+	}
+
+	stats := tester.getStats().data
+	require.NotNil(t, stats)
+	require.EqualValues(t, 7, stats.received)
+	require.EqualValues(t, 8, stats.expected)
+	require.EqualValues(t, 1, stats.outOfOrder)
+}
+
+func TestValidateDataParams(t *testing.T) {
+	require.NoError(t, ValidateDataParams(Params{}))
+	require.NoError(t, ValidateDataParams(Params{DataPublishers: 1, DataPacketSize: DefaultDataPacketSize, DataRate: 1}))
+	require.Error(t, ValidateDataParams(Params{DataPublishers: 1, DataPacketSize: 8, DataRate: 1}))
+	require.Error(t, ValidateDataParams(Params{DataPublishers: 1, DataPacketSize: DefaultDataPacketSize}))
+}
@@ -0,0 +1,498 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+const workerPath = "/worker"
+
+// workerMessage is exchanged as JSON between the coordinator and its workers. The coordinator
+// sends an Assignment, with the Scenario to run on its rooms if any, and may later send Stop;
+// the worker replies with Results or Error.
+type workerMessage struct {
+	Assignment *Params   `json:"assignment,omitempty"`
+	Scenario   *Scenario `json:"scenario,omitempty"`
+	Stop       bool      `json:"stop,omitempty"`
+	Results    *Results  `json:"results,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Coordinator shards a load test across worker hosts and combines their results into one report
+type Coordinator struct {
+	params   Params
+	workers  int
+	addr     string
+	secret   string
+	scenario *Scenario
+}
+
+// NewCoordinator waits on addr for workers presenting secret
+func NewCoordinator(params Params, workers int, addr, secret string) *Coordinator {
+	return &Coordinator{
+		params:  params,
+		workers: workers,
+		addr:    addr,
+		secret:  secret,
+	}
+}
+
+type workerConn struct {
+	conn      *websocket.Conn
+	writeLock sync.Mutex
+}
+
+func (w *workerConn) send(msg *workerMessage) error {
+	w.writeLock.Lock()
+	defer w.writeLock.Unlock()
+	return w.conn.WriteJSON(msg)
+}
+
+// WithScenario has every worker run the phases of scenario on its share of the rooms, instead
+// of a single ramp and hold
+func (c *Coordinator) WithScenario(scenario *Scenario) *Coordinator {
+	c.scenario = scenario
+	return c
+}
+
+func (c *Coordinator) Run(ctx context.Context) error {
+	if c.secret == "" {
+		return errors.New("a distributed test needs a secret shared with its workers")
+	}
+	params := c.params
+	// scenario tester counts are per room, so rooms cannot be shared by workers
+	if c.scenario != nil && (params.RoomCount < c.workers || params.Attach) {
+		return fmt.Errorf("a distributed scenario needs a room per worker, %d rooms for %d workers", params.RoomCount, c.workers)
+	}
+	if params.Room == "" {
+		params.Room = fmt.Sprintf("testroom%d", rand.Int31n(1000))
+	}
+	if params.IdentityPrefix == "" {
+		params.IdentityPrefix = randStringRunes(5)
+	}
+	// workers use their own credentials
+	params.URL, params.APIKey, params.APISecret = "", "", ""
+	shards := shardParams(params, c.workers)
+
+	conns := make(chan *websocket.Conn, c.workers)
+	upgrader := websocket.Upgrader{}
+	mux := http.NewServeMux()
+	mux.HandleFunc(workerPath, func(w http.ResponseWriter, r *http.Request) {
+		// assignments carry the test's parameters, only hand them to our own workers
+		if !authorizedWorker(r, c.secret) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		conns <- conn
+	})
+	listener, err := net.Listen("tcp", c.addr)
+	if err != nil {
+		return err
+	}
+	server := &http.Server{Handler: mux}
+	go func() { _ = server.Serve(listener) }()
+	defer server.Close()
+
+	fmt.Printf("Waiting for %d workers on %s%s\n", c.workers, listener.Addr(), workerPath)
+	workers := make([]*workerConn, 0, c.workers)
+	for len(workers) < c.workers {
+		select {
+		case <-ctx.Done():
+			for _, w := range workers {
+				_ = w.conn.Close()
+			}
+			return ctx.Err()
+		case conn := <-conns:
+			workers = append(workers, &workerConn{conn: conn})
+			fmt.Printf("Worker %d connected from %s\n", len(workers)-1, conn.RemoteAddr())
+		}
+	}
+
+	results := make([]*Results, len(workers))
+	var wg sync.WaitGroup
+	for i, w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer w.conn.Close()
+			if err := w.send(&workerMessage{Assignment: shards[i], Scenario: c.scenario}); err != nil {
+				fmt.Printf("could not assign worker %d: %v\n", i, err)
+				return
+			}
+			msg := &workerMessage{}
+			if err := w.conn.ReadJSON(msg); err != nil {
+				fmt.Printf("lost worker %d: %v\n", i, err)
+				return
+			}
+			if msg.Error != "" {
+				fmt.Printf("worker %d failed: %s\n", i, msg.Error)
+			}
+			results[i] = msg.Results
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		// workers stop and still report what they collected
+		for _, w := range workers {
+			_ = w.send(&workerMessage{Stop: true})
+		}
+		<-done
+		fmt.Println("\nTest interrupted, reporting partial results")
+	}
+
+	merged := mergeResults(results)
+	merged.WorstWorkerPercentiles = len(workers) > 1
+	if err := writeStats(context.WithoutCancel(ctx), statsSinks(params), merged); err != nil {
+		return err
+	}
+	return params.Thresholds.check(merged)
+}
+
+func authorizedWorker(r *http.Request, secret string) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+}
+
+// RunWorker connects to a coordinator with the secret it was started with, runs the share of the
+// test it is assigned with the given credentials, and reports the results back
+func RunWorker(ctx context.Context, coordinatorURL, secret string, credentials TesterParams) error {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+secret)
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, strings.TrimSuffix(coordinatorURL, "/")+workerPath, header)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusUnauthorized {
+			return errors.New("coordinator rejected the worker secret")
+		}
+		return err
+	}
+	defer conn.Close()
+	fmt.Println("Connected to coordinator, waiting for assignment")
+
+	msg := &workerMessage{}
+	if err = conn.ReadJSON(msg); err != nil {
+		return err
+	}
+	if msg.Assignment == nil {
+		return errors.New("coordinator did not send an assignment")
+	}
+	params := *msg.Assignment
+	params.URL, params.APIKey, params.APISecret = credentials.URL, credentials.APIKey, credentials.APISecret
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		// any message or a closed connection stops the run early
+		_ = conn.ReadJSON(&workerMessage{})
+		cancel()
+	}()
+
+	t := NewLoadTest(params)
+	var results *Results
+	if msg.Scenario != nil {
+		results, err = t.runScenario(runCtx, msg.Scenario)
+	} else {
+		var stats map[string]*testerStats
+		if stats, err = t.run(runCtx, t.Params); err == nil {
+			results = getResults(stats, reportNames(stats, params.ReportRoles))
+		}
+	}
+	if err != nil {
+		_ = conn.WriteJSON(&workerMessage{Error: err.Error()})
+		return err
+	}
+	msg = &workerMessage{Results: results}
+	lagErr := t.lag.err()
+	if lagErr != nil {
+		msg.Error = lagErr.Error()
+	}
+	if err = conn.WriteJSON(msg); err != nil {
+		return err
+	}
+	return lagErr
+}
+
+// shardParams splits a test across workers. Whole rooms are assigned to workers when there are
+// enough of them; otherwise every worker joins every room, with the first one running all the
+// publishers and the subscribers spread evenly.
+func shardParams(params Params, workers int) []*Params {
+	shards := make([]*Params, 0, workers)
+	if params.RoomCount >= workers && !params.Attach {
+		rooms := split(params.RoomCount, workers)
+		offset := params.RoomOffset
+		for i := range workers {
+			shard := params
+			shard.RoomOffset = offset
+			shard.RoomCount = rooms[i]
+			shard.IdentityPrefix = fmt.Sprintf("%s_w%d", params.IdentityPrefix, i)
+			offset += rooms[i]
+			shards = append(shards, &shard)
+		}
+		return shards
+	}
+
+	subscribers := split(params.Subscribers, workers)
+	hidden := split(params.HiddenSubscribers, workers)
+	for i := range workers {
+		shard := params
+		shard.Subscribers = subscribers[i]
+		shard.HiddenSubscribers = hidden[i]
+		shard.NoPublishers = i > 0
+		shard.IdentityPrefix = fmt.Sprintf("%s_w%d", params.IdentityPrefix, i)
+		shards = append(shards, &shard)
+	}
+	return shards
+}
+
+// split divides n into parts that differ by at most one
+func split(n, parts int) []int {
+	counts := make([]int, parts)
+	for i := range counts {
+		counts[i] = n / parts
+		if i < n%parts {
+			counts[i]++
+		}
+	}
+	return counts
+}
+
+func mergeResults(results []*Results) *Results {
+	combined := &Results{
+		Total: &TesterResults{Name: "Total"},
+	}
+	var firstFrame float64
+	var firstFrameSamples int
+	rooms := make(map[string]*TesterResults)
+	labels := make(map[string]*TesterResults)
+	endpoints := make(map[string]*TesterResults)
+	for i, r := range results {
+		if r == nil {
+			continue
+		}
+		for _, room := range r.Rooms {
+			// rooms can be shared by workers running their publishers and subscribers
+			if combinedRoom := rooms[room.Name]; combinedRoom != nil {
+				addWorkerResults(combinedRoom, room)
+			} else {
+				rooms[room.Name] = room
+				combined.Rooms = append(combined.Rooms, room)
+			}
+		}
+		for _, label := range r.Labels {
+			if combinedLabel := labels[label.Name]; combinedLabel != nil {
+				addWorkerResults(combinedLabel, label)
+			} else {
+				labels[label.Name] = label
+				combined.Labels = append(combined.Labels, label)
+			}
+		}
+		for _, endpoint := range r.Endpoints {
+			if combinedEndpoint := endpoints[endpoint.Name]; combinedEndpoint != nil {
+				addWorkerResults(combinedEndpoint, endpoint)
+			} else {
+				endpoints[endpoint.Name] = endpoint
+				combined.Endpoints = append(combined.Endpoints, endpoint)
+			}
+		}
+		for _, tester := range r.Testers {
+			tester.Name = fmt.Sprintf("w%d %s", i, tester.Name)
+			combined.Testers = append(combined.Testers, tester)
+			for _, track := range tester.TrackStats {
+				if track.FirstFrameMs > 0 {
+					firstFrame += track.FirstFrameMs
+					firstFrameSamples++
+				}
+			}
+		}
+		addWorkerResults(combined.Total, r.Total)
+		combined.FailedTesters += r.FailedTesters
+		combined.ReplacedTesters += r.ReplacedTesters
+		combined.Retries = mergeRetryResults(combined.Retries, r.Retries)
+		combined.Phases = mergePhaseResults(combined.Phases, r.Phases)
+	}
+	combined.Total.LossRate = lossRate(combined.Total.Packets, combined.Total.Dropped)
+	if firstFrameSamples > 0 {
+		combined.Total.AvgFirstFrameMs = firstFrame / float64(firstFrameSamples)
+	}
+	if len(combined.Rooms) < 2 {
+		combined.Rooms = nil
+	}
+	sortRooms(combined.Rooms)
+	sortRooms(combined.Labels)
+	sortRooms(combined.Endpoints)
+	return combined
+}
+
+// mergePhaseResults adds the scenario phases of a worker to those of the others. Workers run
+// the same phases at the same time, on their own rooms.
+func mergePhaseResults(total, phases []*PhaseResults) []*PhaseResults {
+	for i, p := range phases {
+		if i >= len(total) {
+			merged := *p
+			total = append(total, &merged)
+			continue
+		}
+		t := total[i]
+		t.DurationMs = max(t.DurationMs, p.DurationMs)
+		t.Publishers += p.Publishers
+		t.Subscribers += p.Subscribers
+		t.Joined += p.Joined
+		t.Left += p.Left
+		t.MuteChanges += p.MuteChanges
+		t.Errors += p.Errors
+		t.Packets += p.Packets
+		t.Bytes += p.Bytes
+		t.Dropped += p.Dropped
+		t.Bitrate += p.Bitrate
+		t.LossRate = lossRate(t.Packets, t.Dropped)
+	}
+	return total
+}
+
+// addWorkerResults adds the totals of a worker to total
+func addWorkerResults(total, r *TesterResults) {
+	total.Testers += r.Testers
+	total.Tracks += r.Tracks
+	total.ExpectedTracks += r.ExpectedTracks
+	total.Packets += r.Packets
+	total.Bytes += r.Bytes
+	total.Dropped += r.Dropped
+	total.Errors += r.Errors
+	total.DecryptFailures += r.DecryptFailures
+	total.Replacements += r.Replacements
+	total.Freezes += r.Freezes
+	total.FrozenMs += r.FrozenMs
+	total.BackupTracks += r.BackupTracks
+	// workers run at the same time
+	total.Bitrate += r.Bitrate
+	// percentiles cannot be combined without the samples, report the worst worker's
+	total.LatencyP50Ms = max(total.LatencyP50Ms, r.LatencyP50Ms)
+	total.LatencyP95Ms = max(total.LatencyP95Ms, r.LatencyP95Ms)
+	total.LatencyP99Ms = max(total.LatencyP99Ms, r.LatencyP99Ms)
+	total.LatencyP90Ms = max(total.LatencyP90Ms, r.LatencyP90Ms)
+	total.LatencyMaxMs = max(total.LatencyMaxMs, r.LatencyMaxMs)
+	total.JitterP50Ms = max(total.JitterP50Ms, r.JitterP50Ms)
+	total.JitterP90Ms = max(total.JitterP90Ms, r.JitterP90Ms)
+	total.JitterP99Ms = max(total.JitterP99Ms, r.JitterP99Ms)
+	total.JitterMaxMs = max(total.JitterMaxMs, r.JitterMaxMs)
+	total.AvgFirstFrameMs = max(total.AvgFirstFrameMs, r.AvgFirstFrameMs)
+	total.TimeToInteractiveP50Ms = max(total.TimeToInteractiveP50Ms, r.TimeToInteractiveP50Ms)
+	total.TimeToInteractiveP95Ms = max(total.TimeToInteractiveP95Ms, r.TimeToInteractiveP95Ms)
+	total.TimeToInteractiveP99Ms = max(total.TimeToInteractiveP99Ms, r.TimeToInteractiveP99Ms)
+	total.LossRate = lossRate(total.Packets, total.Dropped)
+}
+
+func printResults(results *Results) {
+	table := util.CreateTable().
+		Headers("Tester", "Tracks", "Bitrate", "Pkt. Loss", "Error")
+	for _, tester := range append(results.Testers, results.Total) {
+		errString := tester.Error
+		if errString == "" {
+			errString = strconv.FormatInt(tester.Errors, 10)
+		}
+		table.Row(
+			tester.Name,
+			fmt.Sprintf("%d/%d", tester.Tracks, tester.ExpectedTracks),
+			formatBitrate(int64(tester.Bitrate/8), time.Second),
+			formatLossRate(tester.Packets, tester.Dropped),
+			errString,
+		)
+	}
+	fmt.Println("\nSubscriber summaries:")
+	fmt.Println(table)
+	if results.ReplacedTesters > 0 {
+		fmt.Printf("Replaced %d failed testers to keep the population steady\n", results.ReplacedTesters)
+	}
+	printRoomResults(results)
+	printLabelResults(results)
+	printEndpointResults(results)
+	printFreezeResults(results)
+	printBackupCodecResults(results)
+	printRetryResults(results)
+	printTailResults(results)
+	if results.WorstWorkerPercentiles {
+		fmt.Println("Percentiles merged over workers, and the first frame times of rooms, labels and endpoints, are those of the worst worker")
+	}
+	printPhaseResults(results.Phases)
+}
+
+// printRoomResults breaks the totals down by room, so that a single bad room or node stands out
+func printRoomResults(results *Results) {
+	if len(results.Rooms) == 0 {
+		return
+	}
+	printResultGroups("Rooms", "Room", results.Rooms, results.Total)
+}
+
+func printResultGroups(title, column string, groups []*TesterResults, total *TesterResults) {
+	formatMs := func(ms float64) string {
+		if ms == 0 {
+			return " - "
+		}
+		return time.Duration(ms * float64(time.Millisecond)).Round(time.Millisecond).String()
+	}
+	table := util.CreateTable().
+		Headers(column, "Testers", "Tracks", "Bitrate (avg)", "Pkt. Loss", "First Frame", "Latency p95", "Errors")
+	var worst *TesterResults
+	for _, group := range append(groups, total) {
+		avgBitrate := group.Bitrate
+		if group.Testers > 0 {
+			avgBitrate /= float64(group.Testers)
+		}
+		table.Row(
+			group.Name,
+			strconv.Itoa(group.Testers),
+			fmt.Sprintf("%d/%d", group.Tracks, group.ExpectedTracks),
+			formatBitrate(int64(avgBitrate/8), time.Second),
+			formatLossRate(group.Packets, group.Dropped),
+			formatMs(group.AvgFirstFrameMs),
+			formatMs(group.LatencyP95Ms),
+			strconv.FormatInt(group.Errors, 10),
+		)
+		if group != total && (worst == nil || group.LossRate > worst.LossRate) {
+			worst = group
+		}
+	}
+	fmt.Printf("\n%s:\n", title)
+	fmt.Println(table)
+	if worst.LossRate > 0 {
+		fmt.Printf("Highest packet loss in %s: %.2f%%\n", worst.Name, worst.LossRate*100)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardParams(t *testing.T) {
+	params := Params{VideoPublishers: 2, Subscribers: 10, RoomCount: 5}
+	params.Room = "room"
+	params.IdentityPrefix = "id"
+
+	shards := shardParams(params, 2)
+	require.Len(t, shards, 2)
+	require.Equal(t, 3, shards[0].RoomCount)
+	require.Equal(t, 2, shards[1].RoomCount)
+	require.Equal(t, "room_3", shards[1].roomName(0))
+	require.Equal(t, 10, shards[1].Subscribers)
+	require.NotEqual(t, shards[0].IdentityPrefix, shards[1].IdentityPrefix)
+
+	params.RoomCount = 1
+	shards = shardParams(params, 3)
+	require.Len(t, shards, 3)
+	require.Equal(t, []int{4, 3, 3}, []int{shards[0].Subscribers, shards[1].Subscribers, shards[2].Subscribers})
+	require.False(t, shards[0].NoPublishers)
+	require.True(t, shards[2].NoPublishers)
+	require.Equal(t, "room_0", shards[2].roomName(0))
+}
+
+func TestMergePhaseResults(t *testing.T) {
+	worker := func(duration float64, joined int, packets, dropped int64) *Results {
+		return &Results{
+			Total: &TesterResults{Name: "Total"},
+			Phases: []*PhaseResults{
+				{Name: "ramp", Kind: "ramp", DurationMs: duration, Joined: joined, Packets: packets, Dropped: dropped},
+				{Name: "hold", Kind: "hold", DurationMs: 2 * duration, Packets: 2 * packets},
+			},
+		}
+	}
+
+	merged := mergeResults([]*Results{worker(1000, 3, 90, 10), nil, worker(1500, 2, 100, 0)})
+	require.Len(t, merged.Phases, 2)
+	require.Equal(t, "ramp", merged.Phases[0].Name)
+	require.Equal(t, 1500.0, merged.Phases[0].DurationMs)
+	require.Equal(t, 5, merged.Phases[0].Joined)
+	require.Equal(t, int64(190), merged.Phases[0].Packets)
+	require.InDelta(t, 0.05, merged.Phases[0].LossRate, 1e-9)
+	require.Equal(t, int64(380), merged.Phases[1].Packets)
+}
+
+func TestCoordinatorScenarioNeedsRoomPerWorker(t *testing.T) {
+	scenario := &Scenario{Phases: []*ScenarioPhase{{Kind: "hold"}}}
+	err := NewCoordinator(Params{RoomCount: 2}, 3, "127.0.0.1:0", "secret").WithScenario(scenario).Run(context.Background())
+	require.ErrorContains(t, err, "2 rooms for 3 workers")
+
+	err = NewCoordinator(Params{RoomCount: 3, Attach: true}, 3, "127.0.0.1:0", "secret").WithScenario(scenario).Run(context.Background())
+	require.ErrorContains(t, err, "a room per worker")
+}
+
+func TestCoordinatorWorkerSecret(t *testing.T) {
+	err := NewCoordinator(Params{RoomCount: 1}, 1, "127.0.0.1:0", "").Run(context.Background())
+	require.ErrorContains(t, err, "needs a secret")
+
+	r := httptest.NewRequest("GET", workerPath, nil)
+	require.False(t, authorizedWorker(r, "secret"))
+	r.Header.Set("Authorization", "Bearer wrong")
+	require.False(t, authorizedWorker(r, "secret"))
+	r.Header.Set("Authorization", "secret")
+	require.False(t, authorizedWorker(r, "secret"))
+	r.Header.Set("Authorization", "Bearer secret")
+	require.True(t, authorizedWorker(r, "secret"))
+}
@@ -0,0 +1,98 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pion/interceptor"
+	"go.uber.org/atomic"
+	"golang.org/x/time/rate"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+// amount of traffic the shared link can absorb in a burst before it starts dropping
+const downlinkCapBurst = 100 * time.Millisecond
+
+// downlinkCap is a bandwidth limit shared by every subscriber in a room, like an office
+// uplink. Packets over the limit are dropped before congestion control and NACK generation
+// see them, so the SFU observes the same loss it would on a constrained network.
+type downlinkCap struct {
+	room      string
+	bitrate   int64
+	limiter   *rate.Limiter
+	startedAt time.Time
+	bytes     atomic.Int64
+	packets   atomic.Int64
+	dropped   atomic.Int64
+}
+
+func newDownlinkCap(room string, bitrate int64) *downlinkCap {
+	bytesPerSecond := float64(bitrate) / 8
+	burst := max(int(bytesPerSecond*downlinkCapBurst.Seconds()), 1500)
+	return &downlinkCap{
+		room:      room,
+		bitrate:   bitrate,
+		limiter:   rate.NewLimiter(rate.Limit(bytesPerSecond), burst),
+		startedAt: time.Now(),
+	}
+}
+
+func (c *downlinkCap) NewInterceptor(_ string) (interceptor.Interceptor, error) {
+	return &downlinkCapInterceptor{downlinkCap: c}, nil
+}
+
+type downlinkCapInterceptor struct {
+	interceptor.NoOp
+	*downlinkCap
+}
+
+func (i *downlinkCapInterceptor) BindRemoteStream(_ *interceptor.StreamInfo, reader interceptor.RTPReader) interceptor.RTPReader {
+	return interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+		for {
+			n, attr, err := reader.Read(b, a)
+			if err != nil {
+				return n, attr, err
+			}
+			if i.limiter.AllowN(time.Now(), n) {
+				i.bytes.Add(int64(n))
+				i.packets.Inc()
+				return n, attr, nil
+			}
+			i.dropped.Inc()
+		}
+	})
+}
+
+func printDownlinkCaps(caps []*downlinkCap) {
+	if len(caps) == 0 {
+		return
+	}
+
+	table := util.CreateTable().
+		Headers("Room", "Cap", "Delivered", "Dropped")
+	for _, c := range caps {
+		table.Row(
+			c.room,
+			formatBitrate(c.bitrate/8, time.Second),
+			formatBitrate(c.bytes.Load(), time.Since(c.startedAt)),
+			formatLossRate(c.packets.Load(), c.dropped.Load()),
+		)
+	}
+	fmt.Println("\nRoom downlink caps:")
+	fmt.Println(table)
+}
@@ -0,0 +1,94 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"fmt"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+// duplicateJoin is a second tester joining with the same identity as an already connected one.
+// The server is expected to evict the original participant.
+type duplicateJoin struct {
+	original  *LoadTester
+	duplicate *LoadTester
+	joinErr   error
+}
+
+type duplicateResult struct {
+	identity string
+	joined   bool
+	evicted  bool
+	reason   string
+}
+
+func (d *duplicateJoin) result() *duplicateResult {
+	reason := d.original.disconnectReason.Load()
+	return &duplicateResult{
+		identity: d.original.identity(),
+		joined:   d.joinErr == nil,
+		evicted:  reason == string(lksdk.DuplicateIdentity),
+		reason:   reason,
+	}
+}
+
+func printDuplicateResults(results []*duplicateResult) {
+	if len(results) == 0 {
+		return
+	}
+
+	table := util.CreateTable().
+		Headers("Identity", "Duplicate Joined", "Original Evicted", "Reason")
+	evicted := 0
+	for _, r := range results {
+		if r.evicted {
+			evicted++
+		}
+		reason := r.reason
+		if reason == "" {
+			reason = "-"
+		}
+		table.Row(r.identity, formatYesNo(r.joined), formatYesNo(r.evicted), reason)
+	}
+	fmt.Printf("\nDuplicate joins (%d/%d evicted):\n", evicted, len(results))
+	fmt.Println(table)
+}
+
+func formatYesNo(v bool) string {
+	if v {
+		return "Yes"
+	}
+	return "No"
+}
+
+// joinDuplicate connects a second tester using the identity of original
+func (t *LoadTest) joinDuplicate(original *LoadTester) {
+	params := original.params
+	params.name = "Dup " + params.name
+	params.Subscribe = false
+	duplicate := NewLoadTester(params)
+	err := duplicate.Start()
+
+	t.lock.Lock()
+	t.duplicates = append(t.duplicates, &duplicateJoin{
+		original:  original,
+		duplicate: duplicate,
+		joinErr:   err,
+	})
+	t.lock.Unlock()
+}
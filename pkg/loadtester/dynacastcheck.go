@@ -0,0 +1,297 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pion/webrtc/v4/pkg/media"
+	"go.uber.org/atomic"
+
+	"github.com/livekit/protocol/livekit"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+const (
+	// DynacastCheckAttribute marks the publishers whose video subscribers leave unsubscribed in a dynacast check
+	DynacastCheckAttribute = "lk.loadtest.dynacast"
+	dynacastUnwatched      = "unwatched"
+
+	// time the server gets to pause the layers of a track nobody subscribes to
+	dynacastGrace = 10 * time.Second
+)
+
+// ValidateDynacastCheck checks the fraction of video publishers left unwatched in a dynacast check
+func ValidateDynacastCheck(params Params) error {
+	if params.DynacastCheck == 0 {
+		return nil
+	}
+	if params.DynacastCheck < 0 || params.DynacastCheck > 1 {
+		return fmt.Errorf("dynacast check fraction must be between 0 and 1")
+	}
+	if unwatchedPublishers(params.VideoPublishers, params.DynacastCheck) == 0 {
+		return fmt.Errorf("dynacast check leaves none of the %d video publishers unwatched", params.VideoPublishers)
+	}
+	return nil
+}
+
+// isUnwatched spreads the fraction of unwatched publishers evenly over the publisher indexes
+func isUnwatched(i int, fraction float64) bool {
+	return int(float64(i+1)*fraction) > int(float64(i)*fraction)
+}
+
+func unwatchedPublishers(publishers int, fraction float64) int {
+	return int(float64(publishers) * fraction)
+}
+
+// dynacastCheck verifies that the server pauses the layers of video tracks nobody subscribes to.
+// Publishers follow the subscribed quality updates of the server like a client with dynacast
+// enabled, and count what they keep sending.
+type dynacastCheck struct {
+	lock   sync.Mutex
+	tracks []*dynacastTrack
+}
+
+func newDynacastCheck(fraction float64) *dynacastCheck {
+	if fraction <= 0 {
+		return nil
+	}
+	return &dynacastCheck{}
+}
+
+func (c *dynacastCheck) add(track *dynacastTrack) {
+	c.lock.Lock()
+	c.tracks = append(c.tracks, track)
+	c.lock.Unlock()
+}
+
+// dynacastTrack is a published video track in a dynacast check
+type dynacastTrack struct {
+	publisher   string
+	unwatched   bool
+	sid         atomic.String
+	publishedAt atomic.Time
+
+	lock sync.Mutex
+	// layers the server last asked for, nil until its first update
+	enabled map[livekit.VideoQuality]bool
+	updates int
+
+	sent atomic.Int64
+	// sent by an unwatched track after its grace period
+	wasted atomic.Int64
+}
+
+// wanted is true while the server asks for the layer. Single layer tracks pass VideoQuality_OFF,
+// and are wanted while the server asks for any layer.
+func (d *dynacastTrack) wanted(quality livekit.VideoQuality) bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.enabled == nil {
+		return true
+	}
+	if quality == livekit.VideoQuality_OFF {
+		return !d.pausedLocked()
+	}
+	return d.enabled[quality]
+}
+
+func (d *dynacastTrack) update(qualities []*livekit.SubscribedQuality) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.enabled = make(map[livekit.VideoQuality]bool)
+	for _, q := range qualities {
+		d.enabled[q.Quality] = q.Enabled
+	}
+	d.updates++
+}
+
+// paused is true when the server disabled every layer of the track
+func (d *dynacastTrack) paused() bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.pausedLocked()
+}
+
+func (d *dynacastTrack) pausedLocked() bool {
+	if d.enabled == nil {
+		return false
+	}
+	for _, enabled := range d.enabled {
+		if enabled {
+			return false
+		}
+	}
+	return true
+}
+
+// layer pauses the provider of a layer while the server doesn't ask for it, and counts what is sent
+func (d *dynacastTrack) layer(provider lksdk.SampleProvider, quality livekit.VideoQuality) lksdk.SampleProvider {
+	return &dynacastCounter{
+		SampleProvider: &dynacastLayer{
+			SampleProvider: provider,
+			wanted: func() bool {
+				return d.wanted(quality)
+			},
+		},
+		track: d,
+	}
+}
+
+func (d *dynacastTrack) published(sid string) {
+	d.sid.Store(sid)
+	d.publishedAt.Store(time.Now())
+}
+
+type dynacastCounter struct {
+	lksdk.SampleProvider
+	track *dynacastTrack
+}
+
+func (c *dynacastCounter) NextSample(ctx context.Context) (media.Sample, error) {
+	sample, err := c.SampleProvider.NextSample(ctx)
+	if err == nil {
+		size := int64(len(sample.Data))
+		c.track.sent.Add(size)
+		publishedAt := c.track.publishedAt.Load()
+		if c.track.unwatched && !publishedAt.IsZero() && time.Since(publishedAt) > dynacastGrace {
+			c.track.wasted.Add(size)
+		}
+	}
+	return sample, err
+}
+
+// dynacastTrack returns a new track of the tester's dynacast check, nil without a check
+func (t *LoadTester) dynacastTrack() *dynacastTrack {
+	if t.params.dynacastCheck == nil {
+		return nil
+	}
+	d := &dynacastTrack{publisher: t.params.name, unwatched: t.params.dynacastUnwatched}
+	t.params.dynacastCheck.add(d)
+	t.lock.Lock()
+	t.dynacastTracks = append(t.dynacastTracks, d)
+	t.lock.Unlock()
+	return d
+}
+
+// onQualityUpdate applies the subscribed quality updates of the server to the tester's published tracks
+func (t *LoadTester) onQualityUpdate(res *livekit.SignalResponse) {
+	update := res.GetSubscribedQualityUpdate()
+	if update == nil {
+		return
+	}
+	qualities := update.SubscribedQualities
+	if len(update.SubscribedCodecs) > 0 {
+		// the primary codec is listed first
+		qualities = update.SubscribedCodecs[0].Qualities
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for _, d := range t.dynacastTracks {
+		if d.sid.Load() == update.TrackSid {
+			d.update(qualities)
+		}
+	}
+}
+
+type DynacastTrackResults struct {
+	Publisher string `json:"publisher"`
+	TrackSID  string `json:"trackSid"`
+	Unwatched bool   `json:"unwatched"`
+	// subscribed quality updates received from the server
+	Updates int `json:"qualityUpdates"`
+	// every layer was disabled by the server at the end of the test
+	Paused  bool    `json:"paused"`
+	Bitrate float64 `json:"bitrateBps"`
+}
+
+type DynacastResults struct {
+	Tracks []*DynacastTrackResults `json:"tracks"`
+	// bitrate unwatched tracks kept sending after their grace period
+	WastedBitrate float64 `json:"wastedBitrateBps"`
+}
+
+func (c *dynacastCheck) finish() *DynacastResults {
+	if c == nil {
+		return nil
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	res := &DynacastResults{}
+	for _, d := range c.tracks {
+		publishedAt := d.publishedAt.Load()
+		if publishedAt.IsZero() {
+			continue
+		}
+		d.lock.Lock()
+		updates := d.updates
+		d.lock.Unlock()
+		res.Tracks = append(res.Tracks, &DynacastTrackResults{
+			Publisher: d.publisher,
+			TrackSID:  d.sid.Load(),
+			Unwatched: d.unwatched,
+			Updates:   updates,
+			Paused:    d.paused(),
+			Bitrate:   bitrate(d.sent.Load(), time.Since(publishedAt)),
+		})
+		if elapsed := time.Since(publishedAt) - dynacastGrace; d.unwatched && elapsed > 0 {
+			res.WastedBitrate += bitrate(d.wasted.Load(), elapsed)
+		}
+	}
+	return res
+}
+
+// passed is true when the server paused every unwatched track, and none of the watched ones
+func (r *DynacastResults) passed() bool {
+	unwatched := 0
+	for _, track := range r.Tracks {
+		if track.Unwatched != track.Paused {
+			return false
+		}
+		if track.Unwatched {
+			unwatched++
+		}
+	}
+	return unwatched > 0
+}
+
+func printDynacastResults(r *DynacastResults) {
+	if r == nil || len(r.Tracks) == 0 {
+		return
+	}
+	table := util.CreateTable().
+		Headers("Publisher", "Track", "Subscribed", "Quality Updates", "Paused", "Bitrate")
+	for _, track := range r.Tracks {
+		table.Row(
+			track.Publisher,
+			track.TrackSID,
+			formatYesNo(!track.Unwatched),
+			fmt.Sprint(track.Updates),
+			formatYesNo(track.Paused),
+			formatBitrate(int64(track.Bitrate/8), time.Second),
+		)
+	}
+	verdict := "passed"
+	if !r.passed() {
+		verdict = "FAILED"
+	}
+	fmt.Printf("\nDynacast check (%s, wasted upstream %s):\n", verdict, formatBitrate(int64(r.WastedBitrate/8), time.Second))
+	fmt.Println(table)
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+func TestIsUnwatched(t *testing.T) {
+	count := 0
+	for i := 0; i < 10; i++ {
+		if isUnwatched(i, 0.3) {
+			count++
+		}
+	}
+	require.Equal(t, 3, count)
+	require.Equal(t, 3, unwatchedPublishers(10, 0.3))
+	require.False(t, isUnwatched(0, 0.3))
+	for i := 0; i < 4; i++ {
+		require.True(t, isUnwatched(i, 1))
+	}
+
+	require.NoError(t, ValidateDynacastCheck(Params{}))
+	require.Error(t, ValidateDynacastCheck(Params{VideoPublishers: 2, DynacastCheck: 1.5}))
+	require.Error(t, ValidateDynacastCheck(Params{VideoPublishers: 2, DynacastCheck: 0.2}))
+	require.NoError(t, ValidateDynacastCheck(Params{VideoPublishers: 5, DynacastCheck: 0.2}))
+}
+
+func TestDynacastTrack(t *testing.T) {
+	d := &dynacastTrack{}
+	// everything is sent until the server's first update
+	require.True(t, d.wanted(livekit.VideoQuality_HIGH))
+	require.True(t, d.wanted(livekit.VideoQuality_OFF))
+	require.False(t, d.paused())
+
+	d.update([]*livekit.SubscribedQuality{
+		{Quality: livekit.VideoQuality_LOW, Enabled: true},
+		{Quality: livekit.VideoQuality_HIGH, Enabled: false},
+	})
+	require.True(t, d.wanted(livekit.VideoQuality_LOW))
+	require.False(t, d.wanted(livekit.VideoQuality_HIGH))
+	require.True(t, d.wanted(livekit.VideoQuality_OFF))
+	require.False(t, d.paused())
+
+	d.update([]*livekit.SubscribedQuality{
+		{Quality: livekit.VideoQuality_LOW, Enabled: false},
+		{Quality: livekit.VideoQuality_HIGH, Enabled: false},
+	})
+	require.False(t, d.wanted(livekit.VideoQuality_LOW))
+	require.False(t, d.wanted(livekit.VideoQuality_OFF))
+	require.True(t, d.paused())
+}
+
+func TestDynacastSignalResponse(t *testing.T) {
+	check := newDynacastCheck(0.5)
+	tester := &LoadTester{params: TesterParams{name: "Pub 0", dynacastCheck: check, dynacastUnwatched: true}}
+	d := tester.dynacastTrack()
+	d.published("TR_video")
+	other := tester.dynacastTrack()
+	other.published("TR_other")
+
+	tester.onQualityUpdate(&livekit.SignalResponse{Message: &livekit.SignalResponse_SubscribedQualityUpdate{
+		SubscribedQualityUpdate: &livekit.SubscribedQualityUpdate{
+			TrackSid: "TR_video",
+			SubscribedCodecs: []*livekit.SubscribedCodec{{
+				Codec:     "vp8",
+				Qualities: []*livekit.SubscribedQuality{{Quality: livekit.VideoQuality_LOW, Enabled: false}},
+			}},
+		},
+	}})
+	require.True(t, d.paused())
+	require.False(t, other.paused())
+
+	res := check.finish()
+	require.Len(t, res.Tracks, 2)
+	require.Equal(t, 1, res.Tracks[0].Updates)
+	require.True(t, res.Tracks[0].Paused)
+	require.Equal(t, 0, res.Tracks[1].Updates)
+	// an unwatched track the server kept sending fails the check
+	require.False(t, res.passed())
+
+	res.Tracks = res.Tracks[:1]
+	require.True(t, res.passed())
+	require.Nil(t, (*dynacastCheck)(nil).finish())
+}
+
+func TestDynacastWasted(t *testing.T) {
+	d := &dynacastTrack{unwatched: true}
+	d.published("TR_video")
+	d.publishedAt.Store(time.Now().Add(-dynacastGrace - time.Second))
+	res := &dynacastCheck{tracks: []*dynacastTrack{d}}
+	d.wasted.Store(1000)
+	d.sent.Store(2000)
+	r := res.finish()
+	require.Greater(t, r.WastedBitrate, 0.0)
+	require.Greater(t, r.Tracks[0].Bitrate, 0.0)
+}
@@ -0,0 +1,240 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pion/rtp"
+	"github.com/pion/rtp/codecs"
+	"github.com/pion/webrtc/v4"
+	"github.com/pion/webrtc/v4/pkg/media"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+// frame cryptors by passphrase, as deriving keys is deliberately slow
+var frameCryptors sync.Map
+
+var errFrameTooShort = errors.New("encrypted frame too short")
+
+// frameCryptor encrypts and decrypts frames with AES-GCM the way the LiveKit client SDKs do
+// with a shared key, so testers can share encrypted rooms with real clients:
+//
+//	header | ciphertext | IV | IV length | key index
+//
+// The codec header at the start of the frame stays readable to the SFU, and is authenticated.
+type frameCryptor struct {
+	aead cipher.AEAD
+}
+
+func cryptorFor(passphrase string) *frameCryptor {
+	if c, ok := frameCryptors.Load(passphrase); ok {
+		return c.(*frameCryptor)
+	}
+	// neither fails with a non-empty passphrase, which yields a 16 byte key
+	key, _ := lksdk.DeriveKeyFromString(passphrase)
+	block, _ := aes.NewCipher(key)
+	aead, _ := cipher.NewGCMWithNonceSize(block, lksdk.LIVEKIT_IV_LENGTH)
+	c, _ := frameCryptors.LoadOrStore(passphrase, &frameCryptor{aead: aead})
+	return c.(*frameCryptor)
+}
+
+// ValidateE2EE checks that encrypted testers only publish codecs they can encrypt
+func ValidateE2EE(params Params) error {
+	if params.E2EEKey == "" {
+		return nil
+	}
+	if params.VideoCodec != "" && !strings.EqualFold(params.VideoCodec, "vp8") {
+		return fmt.Errorf("E2EE load tests publish vp8 video, not %s", params.VideoCodec)
+	}
+	return nil
+}
+
+// checkE2EECodec fails publishing a codec encrypted testers cannot encrypt
+func (t *LoadTester) checkE2EECodec(mimeType string) error {
+	if t.params.E2EEKey != "" && unencryptedHeader(mimeType, []byte{0}) < 0 {
+		return fmt.Errorf("cannot encrypt %s, E2EE testers publish opus and vp8", mimeType)
+	}
+	return nil
+}
+
+// unencryptedHeader is the number of bytes at the start of a frame that are left in the clear,
+// as client SDKs do, or -1 for codecs that are not supported
+func unencryptedHeader(mimeType string, frame []byte) int {
+	var n int
+	switch {
+	case strings.EqualFold(mimeType, webrtc.MimeTypeOpus):
+		n = 1
+	case strings.EqualFold(mimeType, webrtc.MimeTypeVP8):
+		// the frame tag, and on keyframes the start code and dimensions
+		n = 3
+		if len(frame) > 0 && frame[0]&0x01 == 0 {
+			n = 10
+		}
+	default:
+		return -1
+	}
+	return min(n, len(frame))
+}
+
+func (c *frameCryptor) encrypt(mimeType string, frame []byte) ([]byte, error) {
+	n := unencryptedHeader(mimeType, frame)
+	if n < 0 {
+		return nil, fmt.Errorf("cannot encrypt %s", mimeType)
+	}
+	iv := make([]byte, lksdk.LIVEKIT_IV_LENGTH)
+	if _, err := rand.Read(iv); err != nil {
+		return nil, err
+	}
+	out := make([]byte, n, len(frame)+c.aead.Overhead()+len(iv)+2)
+	copy(out, frame[:n])
+	out = c.aead.Seal(out, iv, frame[n:], frame[:n])
+	out = append(out, iv...)
+	// IV length and key index
+	return append(out, byte(len(iv)), 0), nil
+}
+
+func (c *frameCryptor) decrypt(mimeType string, frame []byte) ([]byte, error) {
+	n := unencryptedHeader(mimeType, frame)
+	if n < 0 {
+		return nil, fmt.Errorf("cannot decrypt %s", mimeType)
+	}
+	if len(frame) < n+2 {
+		return nil, errFrameTooShort
+	}
+	ivLength := int(frame[len(frame)-2])
+	ivStart := len(frame) - 2 - ivLength
+	if ivStart < n {
+		return nil, errFrameTooShort
+	}
+	plain, err := c.aead.Open(nil, frame[ivStart:ivStart+ivLength], frame[n:ivStart], frame[:n])
+	if err != nil {
+		return nil, err
+	}
+	return append(frame[:n:n], plain...), nil
+}
+
+// encryptingProvider encrypts every frame of a published track
+type encryptingProvider struct {
+	lksdk.SampleProvider
+	cryptor  *frameCryptor
+	mimeType string
+}
+
+func (p *encryptingProvider) NextSample(ctx context.Context) (media.Sample, error) {
+	sample, err := p.SampleProvider.NextSample(ctx)
+	if err != nil {
+		return sample, err
+	}
+	sample.Data, err = p.cryptor.encrypt(p.mimeType, sample.Data)
+	return sample, err
+}
+
+// encrypted wraps provider to encrypt its frames when the tester uses E2EE
+func (t *LoadTester) encrypted(provider lksdk.SampleProvider, mimeType string) lksdk.SampleProvider {
+	if t.params.E2EEKey == "" {
+		return provider
+	}
+	return &encryptingProvider{SampleProvider: provider, cryptor: cryptorFor(t.params.E2EEKey), mimeType: mimeType}
+}
+
+// frameDecryptor reassembles received RTP packets into frames and decrypts them, counting frames
+// that fail to decrypt. Frames missing packets are skipped, as they could not decrypt either.
+type frameDecryptor struct {
+	cryptor  *frameCryptor
+	mimeType string
+	stats    *trackStats
+
+	frame     []byte
+	timestamp uint32
+	complete  bool
+	lastSeq   uint16
+}
+
+func newFrameDecryptor(cryptor *frameCryptor, mimeType string, stats *trackStats) *frameDecryptor {
+	return &frameDecryptor{cryptor: cryptor, mimeType: mimeType, stats: stats}
+}
+
+func (d *frameDecryptor) push(pkt *rtp.Packet) {
+	if !strings.EqualFold(d.mimeType, webrtc.MimeTypeVP8) {
+		// an audio frame per packet
+		d.decrypt(pkt.Payload)
+		return
+	}
+
+	vp8 := &codecs.VP8Packet{}
+	payload, err := vp8.Unmarshal(pkt.Payload)
+	if err != nil {
+		d.complete = false
+		return
+	}
+	if vp8.S == 1 && vp8.PID == 0 {
+		d.frame = append(d.frame[:0], payload...)
+		d.timestamp = pkt.Timestamp
+		d.complete = true
+	} else if d.complete && pkt.Timestamp == d.timestamp && pkt.SequenceNumber == d.lastSeq+1 {
+		d.frame = append(d.frame, payload...)
+	} else {
+		d.complete = false
+	}
+	d.lastSeq = pkt.SequenceNumber
+	if pkt.Marker && d.complete {
+		d.decrypt(d.frame)
+		d.complete = false
+	}
+}
+
+func (d *frameDecryptor) decrypt(frame []byte) {
+	if _, err := d.cryptor.decrypt(d.mimeType, frame); err != nil {
+		d.stats.decryptFailures.Inc()
+	} else {
+		d.stats.decryptedFrames.Inc()
+	}
+}
+
+func printE2EEStats(stats map[string]*testerStats, names []string) {
+	table := util.CreateTable().
+		Headers("Tester", "Decrypted Frames", "Failures")
+	var frames, failures int64
+	for _, name := range names {
+		var f, e int64
+		for _, ts := range stats[name].trackStats {
+			f += ts.decryptedFrames.Load()
+			e += ts.decryptFailures.Load()
+		}
+		if f+e == 0 {
+			continue
+		}
+		frames += f
+		failures += e
+		table.Row(name, fmt.Sprint(f), fmt.Sprint(e))
+	}
+	if frames+failures == 0 {
+		return
+	}
+	table.Row("Total", fmt.Sprint(frames), fmt.Sprint(failures))
+	fmt.Printf("\nEnd-to-end encryption (%s%% of frames failed to decrypt):\n", formatPercentage(failures, frames+failures))
+	fmt.Println(table)
+}
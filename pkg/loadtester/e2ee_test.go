@@ -0,0 +1,133 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/pion/rtp/codecs"
+	"github.com/pion/webrtc/v4"
+	"github.com/stretchr/testify/require"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+func TestFrameCryptor(t *testing.T) {
+	c := cryptorFor("secret")
+	require.Same(t, c, cryptorFor("secret"))
+
+	opus := []byte{0xfc, 1, 2, 3, 4, 5}
+	encrypted, err := c.encrypt(webrtc.MimeTypeOpus, opus)
+	require.NoError(t, err)
+	require.Equal(t, opus[:1], encrypted[:1])
+	require.NotContains(t, string(encrypted), string(opus[1:]))
+	decrypted, err := c.decrypt(webrtc.MimeTypeOpus, encrypted)
+	require.NoError(t, err)
+	require.Equal(t, opus, decrypted)
+
+	// compatible with the SDK's audio decryption
+	key, err := lksdk.DeriveKeyFromString("secret")
+	require.NoError(t, err)
+	decrypted, err = lksdk.DecryptGCMAudioSample(encrypted, key, nil)
+	require.NoError(t, err)
+	require.Equal(t, opus, decrypted)
+
+	keyframe := append([]byte{0x10, 0x02, 0x00, 0x9d, 0x01, 0x2a, 0x80, 0x02, 0xe0, 0x01}, bytes.Repeat([]byte{7}, 100)...)
+	encrypted, err = c.encrypt(webrtc.MimeTypeVP8, keyframe)
+	require.NoError(t, err)
+	require.Equal(t, keyframe[:10], encrypted[:10])
+	decrypted, err = c.decrypt(webrtc.MimeTypeVP8, encrypted)
+	require.NoError(t, err)
+	require.Equal(t, keyframe, decrypted)
+
+	_, err = cryptorFor("other").decrypt(webrtc.MimeTypeVP8, encrypted)
+	require.Error(t, err)
+	_, err = c.decrypt(webrtc.MimeTypeVP8, encrypted[:4])
+	require.Error(t, err)
+	_, err = c.encrypt(webrtc.MimeTypeH264, keyframe)
+	require.Error(t, err)
+}
+
+func TestValidateE2EE(t *testing.T) {
+	params := Params{}
+	params.VideoCodec = "h264"
+	require.NoError(t, ValidateE2EE(params))
+	params.E2EEKey = "secret"
+	require.Error(t, ValidateE2EE(params))
+	params.VideoCodec = "vp8"
+	require.NoError(t, ValidateE2EE(params))
+
+	tester := NewLoadTester(TesterParams{E2EEKey: "secret"})
+	require.NoError(t, tester.checkE2EECodec(webrtc.MimeTypeVP8))
+	require.Error(t, tester.checkE2EECodec(webrtc.MimeTypeAV1))
+	require.NoError(t, NewLoadTester(TesterParams{}).checkE2EECodec(webrtc.MimeTypeAV1))
+}
+
+func TestFrameDecryptor(t *testing.T) {
+	c := cryptorFor("secret")
+	frame := func(b byte) []byte {
+		// a delta frame spanning several packets
+		return append([]byte{0x01}, bytes.Repeat([]byte{b}, 3000)...)
+	}
+	packetize := func(seq uint16, ts uint32, data []byte) []*rtp.Packet {
+		payloads := (&codecs.VP8Payloader{}).Payload(1200, data)
+		var pkts []*rtp.Packet
+		for i, p := range payloads {
+			pkts = append(pkts, &rtp.Packet{
+				Header:  rtp.Header{SequenceNumber: seq + uint16(i), Timestamp: ts, Marker: i == len(payloads)-1},
+				Payload: p,
+			})
+		}
+		return pkts
+	}
+
+	stats := &trackStats{}
+	d := newFrameDecryptor(c, webrtc.MimeTypeVP8, stats)
+	encrypted, err := c.encrypt(webrtc.MimeTypeVP8, frame(1))
+	require.NoError(t, err)
+	first := packetize(10, 3000, encrypted)
+	require.Greater(t, len(first), 2)
+	for _, pkt := range first {
+		d.push(pkt)
+	}
+	require.EqualValues(t, 1, stats.decryptedFrames.Load())
+
+	// a frame missing a packet is skipped
+	encrypted, err = c.encrypt(webrtc.MimeTypeVP8, frame(2))
+	require.NoError(t, err)
+	second := packetize(10+uint16(len(first)), 6000, encrypted)
+	d.push(second[0])
+	for _, pkt := range second[2:] {
+		d.push(pkt)
+	}
+	require.EqualValues(t, 1, stats.decryptedFrames.Load())
+	require.EqualValues(t, 0, stats.decryptFailures.Load())
+
+	// a frame encrypted with another key fails
+	encrypted, err = cryptorFor("other").encrypt(webrtc.MimeTypeVP8, frame(3))
+	require.NoError(t, err)
+	for _, pkt := range packetize(100, 9000, encrypted) {
+		d.push(pkt)
+	}
+	require.EqualValues(t, 1, stats.decryptFailures.Load())
+
+	audio := newFrameDecryptor(c, webrtc.MimeTypeOpus, stats)
+	encrypted, err = c.encrypt(webrtc.MimeTypeOpus, []byte{0xfc, 1, 2})
+	require.NoError(t, err)
+	audio.push(&rtp.Packet{Payload: encrypted})
+	require.EqualValues(t, 2, stats.decryptedFrames.Load())
+}
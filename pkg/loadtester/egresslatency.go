@@ -0,0 +1,284 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/pion/webrtc/v4"
+	"github.com/pion/webrtc/v4/pkg/media"
+
+	"github.com/livekit/protocol/livekit"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+const (
+	pulseFrameDuration = 20 * time.Millisecond
+	// frames of noise in every pulse
+	pulseFrames = 10
+	// peak sample amplitude above which egress output counts as the pulse
+	pulseThreshold = 1000
+	// how long to wait for egress to connect to the sink
+	egressConnectTimeout = 30 * time.Second
+)
+
+// an opus frame that decodes to 20ms of silence
+var opusSilence = []byte{0xf8, 0xff, 0xfe}
+
+// EgressLatencyParams configures a glass-to-glass latency measurement through a track egress.
+// Track egress streams audio to websockets only, so the probe is an audio track and the sink
+// a websocket server that egress connects to.
+type EgressLatencyParams struct {
+	URL       string
+	APIKey    string
+	APISecret string
+	Room      string
+	// address the sink listens on
+	Listen string
+	// websocket URL egress connects to, which must reach the sink on Listen
+	SinkURL string
+	// number of pulses to send, one every Interval
+	Probes   int
+	Interval time.Duration
+}
+
+type EgressLatencyResults struct {
+	EgressID  string    `json:"egressId"`
+	Probes    int       `json:"probes"`
+	Detected  int       `json:"detected"`
+	SamplesMs []float64 `json:"samplesMs"`
+	P50Ms     float64   `json:"p50Ms"`
+	P95Ms     float64   `json:"p95Ms"`
+	P99Ms     float64   `json:"p99Ms"`
+}
+
+// MeasureEgressLatency publishes an audio track that is silent except for short noise pulses,
+// starts a track egress of it to a local websocket sink, and measures how long each pulse takes
+// to come out of egress. Both ends run on this host, so they share a clock.
+func MeasureEgressLatency(ctx context.Context, egressClient *lksdk.EgressClient, params EgressLatencyParams) (*EgressLatencyResults, error) {
+	if params.Probes <= 0 {
+		return nil, errors.New("probes must be positive")
+	}
+	if params.Interval < 2*pulseFrames*pulseFrameDuration {
+		return nil, fmt.Errorf("interval must be at least %s", 2*pulseFrames*pulseFrameDuration)
+	}
+
+	sink := newPulseSink()
+	listener, err := net.Listen("tcp", params.Listen)
+	if err != nil {
+		return nil, err
+	}
+	server := &http.Server{Handler: sink}
+	go func() { _ = server.Serve(listener) }()
+	defer server.Close()
+
+	room, err := lksdk.ConnectToRoom(params.URL, lksdk.ConnectInfo{
+		APIKey:              params.APIKey,
+		APISecret:           params.APISecret,
+		RoomName:            params.Room,
+		ParticipantIdentity: "egress-latency-" + randStringRunes(4),
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer room.Disconnect()
+
+	probe := &pulseProvider{}
+	track, err := lksdk.NewLocalTrack(webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus, Channels: 1})
+	if err != nil {
+		return nil, err
+	}
+	if err = track.StartWrite(probe, nil); err != nil {
+		return nil, err
+	}
+	pub, err := room.LocalParticipant.PublishTrack(track, &lksdk.TrackPublicationOptions{Name: "egress-latency"})
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := egressClient.StartTrackEgress(ctx, &livekit.TrackEgressRequest{
+		RoomName: params.Room,
+		TrackId:  pub.SID(),
+		Output:   &livekit.TrackEgressRequest_WebsocketUrl{WebsocketUrl: params.SinkURL},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_, _ = egressClient.StopEgress(context.Background(), &livekit.StopEgressRequest{EgressId: info.EgressId})
+	}()
+
+	fmt.Printf("Started egress %s, waiting for it to connect to %s\n", info.EgressId, params.SinkURL)
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(egressConnectTimeout):
+		return nil, fmt.Errorf("egress did not connect to %s within %s", params.SinkURL, egressConnectTimeout)
+	case <-sink.connected:
+	}
+
+	// let the pipeline settle before the first pulse
+	next := time.Now().Add(params.Interval)
+	for i := 0; i < params.Probes; i++ {
+		if !sleepUntil(ctx, next) {
+			return nil, ctx.Err()
+		}
+		probe.pulse()
+		next = next.Add(params.Interval)
+	}
+	// the last pulse gets as long as the others to come out
+	if !sleepUntil(ctx, next) {
+		return nil, ctx.Err()
+	}
+
+	samples := matchPulses(probe.sentAt(), sink.onsetTimes(), params.Interval)
+	p := getLatencyPercentiles(samples)
+	results := &EgressLatencyResults{
+		EgressID: info.EgressId,
+		Probes:   params.Probes,
+		Detected: len(samples),
+		P50Ms:    durationMs(p.p50),
+		P95Ms:    durationMs(p.p95),
+		P99Ms:    durationMs(p.p99),
+	}
+	for _, d := range samples {
+		results.SamplesMs = append(results.SamplesMs, durationMs(d))
+	}
+	return results, nil
+}
+
+// matchPulses pairs every pulse with the first onset seen within window after it was sent.
+// Pulses without one are lost, and onsets without a pulse are ignored.
+func matchPulses(sent, onsets []time.Time, window time.Duration) []time.Duration {
+	var latencies []time.Duration
+	j := 0
+	for _, s := range sent {
+		for j < len(onsets) && onsets[j].Before(s) {
+			j++
+		}
+		if j == len(onsets) {
+			break
+		}
+		if d := onsets[j].Sub(s); d < window {
+			latencies = append(latencies, d)
+			j++
+		}
+	}
+	return latencies
+}
+
+// pulseProvider writes opus silence, and a burst of noise frames whenever a pulse is requested
+type pulseProvider struct {
+	lksdk.BaseSampleProvider
+
+	lock      sync.Mutex
+	remaining int
+	sent      []time.Time
+}
+
+func (p *pulseProvider) pulse() {
+	p.lock.Lock()
+	p.remaining = pulseFrames
+	p.lock.Unlock()
+}
+
+func (p *pulseProvider) sentAt() []time.Time {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return append([]time.Time(nil), p.sent...)
+}
+
+func (p *pulseProvider) NextSample(_ context.Context) (media.Sample, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.remaining == 0 {
+		return media.Sample{Data: opusSilence, Duration: pulseFrameDuration}, nil
+	}
+	if p.remaining == pulseFrames {
+		p.sent = append(p.sent, time.Now())
+	}
+	p.remaining--
+	// random bits after a CELT TOC byte decode to noise, with no encoder needed
+	frame := make([]byte, 120)
+	frame[0] = opusSilence[0]
+	_, _ = rand.Read(frame[1:])
+	return media.Sample{Data: frame, Duration: pulseFrameDuration}, nil
+}
+
+// pulseSink receives the 16-bit PCM that track egress streams over a websocket, and records
+// when the audio turns from silence to noise
+type pulseSink struct {
+	upgrader  websocket.Upgrader
+	connected chan struct{}
+	once      sync.Once
+
+	lock   sync.Mutex
+	loud   bool
+	onsets []time.Time
+}
+
+func newPulseSink() *pulseSink {
+	return &pulseSink{connected: make(chan struct{})}
+}
+
+func (s *pulseSink) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	s.once.Do(func() { close(s.connected) })
+	for {
+		kind, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		// text messages carry track status, such as mutes
+		if kind == websocket.BinaryMessage {
+			s.onAudio(data, time.Now())
+		}
+	}
+}
+
+func (s *pulseSink) onAudio(pcm []byte, at time.Time) {
+	var peak int
+	for i := 0; i+1 < len(pcm); i += 2 {
+		v := int(int16(binary.LittleEndian.Uint16(pcm[i:])))
+		peak = max(peak, v, -v)
+	}
+	loud := peak > pulseThreshold
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if loud && !s.loud {
+		s.onsets = append(s.onsets, at)
+	}
+	s.loud = loud
+}
+
+func (s *pulseSink) onsetTimes() []time.Time {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]time.Time(nil), s.onsets...)
+}
@@ -0,0 +1,94 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"encoding/binary"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+)
+
+func pcm(amplitude int16, samples int) []byte {
+	data := make([]byte, 2*samples)
+	for i := 0; i < samples; i++ {
+		// alternate the sign, as audio would
+		v := amplitude
+		if i%2 == 1 {
+			v = -amplitude
+		}
+		binary.LittleEndian.PutUint16(data[2*i:], uint16(v))
+	}
+	return data
+}
+
+func TestPulseProvider(t *testing.T) {
+	p := &pulseProvider{}
+	sample, err := p.NextSample(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, opusSilence, sample.Data)
+	require.Empty(t, p.sentAt())
+
+	p.pulse()
+	for i := 0; i < pulseFrames; i++ {
+		sample, err = p.NextSample(context.Background())
+		require.NoError(t, err)
+		require.NotEqual(t, opusSilence, sample.Data)
+		require.Equal(t, opusSilence[0], sample.Data[0])
+	}
+	sample, err = p.NextSample(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, opusSilence, sample.Data)
+	require.Len(t, p.sentAt(), 1)
+}
+
+func TestPulseSink(t *testing.T) {
+	sink := newPulseSink()
+	server := httptest.NewServer(sink)
+	defer server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	<-sink.connected
+
+	for _, amplitude := range []int16{0, 20, 8000, 9000, 0, 12000, 0} {
+		require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, pcm(amplitude, 480)))
+	}
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`{"muted":false}`)))
+	require.Eventually(t, func() bool {
+		return len(sink.onsetTimes()) == 2
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestMatchPulses(t *testing.T) {
+	start := time.Now()
+	at := func(ms int) time.Time { return start.Add(time.Duration(ms) * time.Millisecond) }
+	sent := []time.Time{at(0), at(1000), at(2000), at(3000)}
+	// a stray onset before the first pulse, and the third pulse lost
+	onsets := []time.Time{at(-50), at(300), at(1250), at(3400)}
+
+	require.Equal(t, []time.Duration{
+		300 * time.Millisecond,
+		250 * time.Millisecond,
+		400 * time.Millisecond,
+	}, matchPulses(sent, onsets, time.Second))
+	require.Empty(t, matchPulses(sent, nil, time.Second))
+}
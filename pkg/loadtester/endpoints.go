@@ -0,0 +1,131 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const (
+	// testers join the endpoints in turn, so that every room spans all of them
+	SpreadURLsByTester = "tester"
+	// all testers of a room join the same endpoint, to compare endpoints room by room
+	SpreadURLsByRoom = "room"
+)
+
+// ParseURLs splits a comma separated list of LiveKit URLs
+func ParseURLs(str string) ([]string, error) {
+	var urls []string
+	for _, part := range strings.Split(str, ",") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		if err := checkEndpoint(part); err != nil {
+			return nil, err
+		}
+		urls = append(urls, part)
+	}
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no URL in %q", str)
+	}
+	return urls, nil
+}
+
+// LoadURLs reads a LiveKit URL from each line of a file, skipping empty lines and # comments
+func LoadURLs(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var urls []string
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if err := checkEndpoint(text); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		urls = append(urls, text)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("%s lists no URLs", path)
+	}
+	return urls, nil
+}
+
+func checkEndpoint(str string) error {
+	u, err := url.Parse(str)
+	if err != nil {
+		return fmt.Errorf("invalid URL %q: %w", str, err)
+	}
+	switch u.Scheme {
+	case "ws", "wss", "http", "https":
+	default:
+		return fmt.Errorf("unsupported URL %q, expected ws://, wss://, http:// or https://HOST", str)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid URL %q, missing host", str)
+	}
+	return nil
+}
+
+// ValidateURLs checks how testers are spread over several URLs
+func ValidateURLs(params Params) error {
+	switch params.SpreadURLs {
+	case "", SpreadURLsByTester, SpreadURLsByRoom:
+		return nil
+	default:
+		return fmt.Errorf("unknown URL spread %q, expected %s or %s", params.SpreadURLs, SpreadURLsByTester, SpreadURLsByRoom)
+	}
+}
+
+// endpoints are the URLs testers join, URL when there is only one
+func (p *Params) endpoints() []string {
+	if len(p.URLs) == 0 {
+		return []string{p.URL}
+	}
+	return p.URLs
+}
+
+// endpointFor picks the URL of the i-th tester to join, which is the j-th room's, and whether
+// results are broken down by endpoint
+func (p *Params) endpointFor(i, j int) (string, bool) {
+	if len(p.URLs) < 2 {
+		return p.endpoints()[0], false
+	}
+	if p.SpreadURLs == SpreadURLsByRoom {
+		return p.URLs[j%len(p.URLs)], true
+	}
+	return p.URLs[i%len(p.URLs)], true
+}
+
+// printEndpointResults breaks the totals down by the URL testers joined
+func printEndpointResults(results *Results) {
+	if len(results.Endpoints) == 0 {
+		return
+	}
+	printResultGroups("Endpoints", "URL", results.Endpoints, results.Total)
+}
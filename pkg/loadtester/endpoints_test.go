@@ -0,0 +1,77 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseURLs(t *testing.T) {
+	urls, err := ParseURLs("wss://eu.example.com, wss://us.example.com,")
+	require.NoError(t, err)
+	require.Equal(t, []string{"wss://eu.example.com", "wss://us.example.com"}, urls)
+
+	_, err = ParseURLs("wss://eu.example.com,ftp://us.example.com")
+	require.EqualError(t, err, `unsupported URL "ftp://us.example.com", expected ws://, wss://, http:// or https://HOST`)
+	_, err = ParseURLs(" , ")
+	require.Error(t, err)
+
+	path := filepath.Join(t.TempDir(), "urls.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# regions\nwss://eu.example.com\n\nwss:///missing-host\n"), 0644))
+	_, err = LoadURLs(path)
+	require.EqualError(t, err, path+`:4: invalid URL "wss:///missing-host", missing host`)
+}
+
+func TestEndpointFor(t *testing.T) {
+	params := &Params{TesterParams: TesterParams{URL: "wss://eu.example.com"}}
+	url, perEndpoint := params.endpointFor(3, 1)
+	require.Equal(t, "wss://eu.example.com", url)
+	require.False(t, perEndpoint)
+
+	params.URLs = []string{"wss://eu.example.com", "wss://us.example.com", "wss://ap.example.com"}
+	url, perEndpoint = params.endpointFor(4, 0)
+	require.Equal(t, "wss://us.example.com", url)
+	require.True(t, perEndpoint)
+
+	params.SpreadURLs = SpreadURLsByRoom
+	url, _ = params.endpointFor(4, 2)
+	require.Equal(t, "wss://ap.example.com", url)
+
+	params.SpreadURLs = "region"
+	require.Error(t, ValidateURLs(*params))
+}
+
+func TestEndpointResults(t *testing.T) {
+	stats := map[string]*testerStats{
+		"a": {endpoint: "wss://eu.example.com"},
+		"b": {endpoint: "wss://us.example.com"},
+		"c": {endpoint: "wss://eu.example.com"},
+	}
+	results := getResults(stats, []string{"a", "b", "c"})
+	require.Len(t, results.Endpoints, 2)
+	require.Equal(t, "wss://eu.example.com", results.Endpoints[0].Name)
+	require.Equal(t, 2, results.Endpoints[0].Testers)
+
+	merged := mergeResults([]*Results{results, getResults(stats, []string{"b"})})
+	require.Len(t, merged.Endpoints, 2)
+	require.Equal(t, 2, merged.Endpoints[1].Testers)
+
+	// a single URL is not broken down
+	require.Empty(t, getResults(map[string]*testerStats{"a": {}}, []string{"a"}).Endpoints)
+}
@@ -0,0 +1,208 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+const (
+	// lines kept of a tester that hasn't failed, older ones are dropped
+	errorLogLines = 2000
+	// longest signal message logged, SDP with many candidates can be larger
+	errorLogLineSize = 64 * 1024
+)
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// errorLogs collects the detailed logs of testers that fail in a directory, one file per identity.
+// Every tester buffers its recent signaling, ICE candidates and state changes in memory, and only
+// writes them once it fails to connect, drops or has to reconnect on its own.
+type errorLogs struct {
+	dir    string
+	lock   sync.Mutex
+	failed []*testerLog
+}
+
+func newErrorLogs(dir string) (*errorLogs, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return &errorLogs{dir: dir}, nil
+}
+
+// close closes the files of the failed testers, and returns how many were written
+func (l *errorLogs) close() int {
+	if l == nil {
+		return 0
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	for _, tl := range l.failed {
+		tl.close()
+	}
+	return len(l.failed)
+}
+
+// testerLog is the log of a single tester
+type testerLog struct {
+	logs     *errorLogs
+	identity string
+
+	lock    sync.Mutex
+	lines   []string
+	dropped int
+	// open once the tester failed, further lines go straight to it
+	file *os.File
+}
+
+func (l *errorLogs) forTester(identity string) *testerLog {
+	if l == nil {
+		return nil
+	}
+	return &testerLog{logs: l, identity: identity}
+}
+
+func (l *testerLog) logf(format string, args ...any) {
+	if l == nil {
+		return
+	}
+	line := time.Now().Format("15:04:05.000") + " " + fmt.Sprintf(format, args...)
+	if len(line) > errorLogLineSize {
+		line = line[:errorLogLineSize] + "..."
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.file != nil {
+		_, _ = fmt.Fprintln(l.file, line)
+		return
+	}
+	if len(l.lines) == errorLogLines {
+		l.lines = l.lines[1:]
+		l.dropped++
+	}
+	l.lines = append(l.lines, line)
+}
+
+// signal logs a signal message, leaving out keepalives
+func (l *testerLog) signal(direction string, msg proto.Message) {
+	if l == nil {
+		return
+	}
+	switch m := msg.(type) {
+	case *livekit.SignalRequest:
+		if m.GetPing() != 0 || m.GetPingReq() != nil {
+			return
+		}
+	case *livekit.SignalResponse:
+		if m.GetPong() != 0 || m.GetPongResp() != nil {
+			return
+		}
+	}
+	data, err := protojson.Marshal(msg)
+	if err != nil {
+		l.logf("%s unreadable signal message: %v", direction, err)
+		return
+	}
+	l.logf("%s %s", direction, data)
+}
+
+// fail writes the buffered lines to the tester's file, the first time it fails
+func (l *testerLog) fail(reason string) {
+	if l == nil {
+		return
+	}
+	l.lock.Lock()
+	if l.file != nil {
+		l.lock.Unlock()
+		l.logf("failed: %s", reason)
+		return
+	}
+	path := filepath.Join(l.logs.dir, unsafeFileChars.ReplaceAllString(l.identity, "_")+".log")
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		l.lock.Unlock()
+		fmt.Printf("could not write error log of %s: %v\n", l.identity, err)
+		return
+	}
+	l.file = f
+	if l.dropped > 0 {
+		_, _ = fmt.Fprintf(f, "(%d earlier lines dropped)\n", l.dropped)
+	}
+	for _, line := range l.lines {
+		_, _ = fmt.Fprintln(f, line)
+	}
+	l.lines = nil
+	l.lock.Unlock()
+
+	l.logs.lock.Lock()
+	l.logs.failed = append(l.logs.failed, l)
+	l.logs.lock.Unlock()
+	l.logf("failed: %s", reason)
+}
+
+func (l *testerLog) close() {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.file != nil {
+		_ = l.file.Close()
+	}
+}
+
+// onReconnecting fails the tester's log when the SDK reconnects on its own, after ICE or the
+// signal connection failed, rather than for --reconnect-interval
+func (t *LoadTester) onReconnecting() {
+	t.lock.Lock()
+	forced := t.pendingReconnect != nil
+	t.lock.Unlock()
+	if forced {
+		t.errorLog.logf("reconnecting as requested")
+		return
+	}
+	t.errorLog.fail("reconnecting, the connection to the server or ICE failed")
+}
+
+func (t *LoadTest) openLogs() (func(), error) {
+	closeStateLog, err := t.openStateLog()
+	if err != nil {
+		return nil, err
+	}
+	if t.Params.ErrorLogDir == "" {
+		return closeStateLog, nil
+	}
+	l, err := newErrorLogs(t.Params.ErrorLogDir)
+	if err != nil {
+		closeStateLog()
+		return nil, err
+	}
+	t.errorLogs = l
+	return func() {
+		closeStateLog()
+		if n := l.close(); n > 0 {
+			fmt.Printf("Wrote logs of %d failed testers to %s\n", n, l.dir)
+		}
+		t.errorLogs = nil
+	}, nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+func TestErrorLog(t *testing.T) {
+	logs, err := newErrorLogs(filepath.Join(t.TempDir(), "errors"))
+	require.NoError(t, err)
+
+	l := logs.forTester("sub/0 ")
+	for i := 0; i < errorLogLines+5; i++ {
+		l.logf("line %d", i)
+	}
+	l.signal("send", &livekit.SignalRequest{Message: &livekit.SignalRequest_Ping{Ping: 1}})
+	l.signal("recv", &livekit.SignalResponse{Message: &livekit.SignalResponse_Trickle{Trickle: &livekit.TrickleRequest{
+		CandidateInit: `{"candidate":"candidate:1 1 udp 2130706431 10.0.0.1 50000 typ host"}`,
+	}}})
+	entries, err := os.ReadDir(logs.dir)
+	require.NoError(t, err)
+	require.Empty(t, entries, "nothing is written before the tester fails")
+
+	l.fail("disconnected")
+	l.logf("after failing")
+	require.Equal(t, 1, logs.close())
+
+	data, err := os.ReadFile(filepath.Join(logs.dir, "sub_0_.log"))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Equal(t, "(6 earlier lines dropped)", lines[0])
+	require.Contains(t, lines[1], "line 6")
+	require.Contains(t, lines[len(lines)-3], "recv {\"trickle\"")
+	require.Contains(t, lines[len(lines)-2], "failed: disconnected")
+	require.Contains(t, lines[len(lines)-1], "after failing")
+	require.NotContains(t, string(data), "ping")
+}
+
+func TestErrorLogState(t *testing.T) {
+	logs, err := newErrorLogs(t.TempDir())
+	require.NoError(t, err)
+
+	ok := NewLoadTester(TesterParams{IdentityPrefix: "pub", Sequence: 1, errorLogs: logs})
+	require.True(t, ok.needsSignalTap())
+	ok.setState(stateToken, "", nil)
+	// testers disconnect once stopped
+	ok.setState(stateDisconnected, "CLIENT_INITIATED", nil)
+
+	failed := NewLoadTester(TesterParams{IdentityPrefix: "sub", Sequence: 2, errorLogs: logs})
+	failed.setState(stateError, stateConnected, errors.New("could not establish signal connection"))
+	require.Equal(t, 1, logs.close())
+
+	_, err = os.Stat(filepath.Join(logs.dir, "pub_1.log"))
+	require.True(t, os.IsNotExist(err))
+	data, err := os.ReadFile(filepath.Join(logs.dir, "sub_2.log"))
+	require.NoError(t, err)
+	require.Contains(t, string(data), "state created")
+	require.Contains(t, string(data), fmt.Sprintf("failed: error %s: could not establish signal connection", stateConnected))
+}
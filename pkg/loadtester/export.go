@@ -0,0 +1,469 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"cmp"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	StatsOutputJSON = "json"
+	StatsOutputCSV  = "csv"
+)
+
+type TrackResults struct {
+	TrackID      string  `json:"trackId"`
+	Kind         string  `json:"kind"`
+	Packets      int64   `json:"packets"`
+	Bytes        int64   `json:"bytes"`
+	Dropped      int64   `json:"dropped"`
+	Bitrate      float64 `json:"bitrateBps"`
+	LossRate     float64 `json:"lossRate"`
+	FirstFrameMs float64 `json:"firstFrameMs,omitempty"`
+	// stalls between video frames longer than the freeze threshold
+	Freezes         int64   `json:"freezes,omitempty"`
+	FrozenMs        float64 `json:"frozenMs,omitempty"`
+	LongestFreezeMs float64 `json:"longestFreezeMs,omitempty"`
+	Codec           string  `json:"codec,omitempty"`
+}
+
+type TesterResults struct {
+	Name              string          `json:"name"`
+	Role              Role            `json:"role,omitempty"`
+	Room              string          `json:"room,omitempty"`
+	Label             string          `json:"label,omitempty"`
+	Endpoint          string          `json:"endpoint,omitempty"`
+	Testers           int             `json:"testers,omitempty"`
+	Tracks            int             `json:"tracks"`
+	ExpectedTracks    int             `json:"expectedTracks"`
+	Packets           int64           `json:"packets"`
+	Bytes             int64           `json:"bytes"`
+	Dropped           int64           `json:"dropped"`
+	Bitrate           float64         `json:"bitrateBps"`
+	LossRate          float64         `json:"lossRate"`
+	AvgFirstFrameMs   float64         `json:"avgFirstFrameMs,omitempty"`
+	LatencyP50Ms      float64         `json:"latencyP50Ms,omitempty"`
+	LatencyP95Ms      float64         `json:"latencyP95Ms,omitempty"`
+	LatencyP99Ms      float64         `json:"latencyP99Ms,omitempty"`
+	Errors            int64           `json:"errors"`
+	DecryptFailures   int64           `json:"decryptFailures,omitempty"`
+	Error             string          `json:"error,omitempty"`
+	TrackStats        []*TrackResults `json:"trackStats,omitempty"`
+	firstFrameSamples int
+
+	// time from joining until connected, with first audio and the active speaker's video
+	TimeToInteractiveMs float64 `json:"timeToInteractiveMs,omitempty"`
+	// percentiles of the testers in a total
+	TimeToInteractiveP50Ms float64 `json:"timeToInteractiveP50Ms,omitempty"`
+	TimeToInteractiveP95Ms float64 `json:"timeToInteractiveP95Ms,omitempty"`
+	TimeToInteractiveP99Ms float64 `json:"timeToInteractiveP99Ms,omitempty"`
+
+	// times the tester failed and was replaced, to keep the population steady
+	Replacements int `json:"replacements,omitempty"`
+
+	// tail of the latency, and the transit time variation of received packets
+	LatencyP90Ms float64 `json:"latencyP90Ms,omitempty"`
+	LatencyMaxMs float64 `json:"latencyMaxMs,omitempty"`
+	JitterP50Ms  float64 `json:"jitterP50Ms,omitempty"`
+	JitterP90Ms  float64 `json:"jitterP90Ms,omitempty"`
+	JitterP99Ms  float64 `json:"jitterP99Ms,omitempty"`
+	JitterMaxMs  float64 `json:"jitterMaxMs,omitempty"`
+
+	// stalls between the frames of received video tracks, and the time they were frozen for
+	Freezes  int64   `json:"freezes,omitempty"`
+	FrozenMs float64 `json:"frozenMs,omitempty"`
+
+	// backup codec tracks published on the server's request
+	BackupTracks int `json:"backupTracks,omitempty"`
+}
+
+type Results struct {
+	Testers  []*TesterResults `json:"testers"`
+	Total    *TesterResults   `json:"total"`
+	Webhooks []*WebhookRecord `json:"webhooks,omitempty"`
+	Phases   []*PhaseResults  `json:"phases,omitempty"`
+	// totals of each room, only when testers were spread over several rooms,
+	// and of each location label of the cohorts
+	Rooms    []*TesterResults `json:"rooms,omitempty"`
+	Labels   []*TesterResults `json:"labels,omitempty"`
+	Rotation *RotationResults `json:"rotation,omitempty"`
+	Fuzz     *FuzzResults     `json:"fuzz,omitempty"`
+	Dynacast *DynacastResults `json:"dynacast,omitempty"`
+	Speakers *SpeakerResults  `json:"speakers,omitempty"`
+	Bitrates *BitrateResults  `json:"bitrates,omitempty"`
+	// totals of each URL, only when testers were spread over several
+	Endpoints []*TesterResults `json:"endpoints,omitempty"`
+	// testers of any role that failed to connect or publish
+	FailedTesters int `json:"failedTesters"`
+	// replacements of failed testers of any role
+	ReplacedTesters int `json:"replacedTesters,omitempty"`
+	// failure rates before and after retrying transient errors, when any attempt failed
+	Retries *RetryResults `json:"retries,omitempty"`
+	// totals over all segments of a run resumed from a checkpoint
+	Resumed *ResumeResults `json:"resumed,omitempty"`
+	// waits for a connection slot, with a cap on testers connecting at once
+	ConnectQueue *ConnectQueueResults `json:"connectQueue,omitempty"`
+	// totals were merged from several workers, with the percentiles of the worst of them
+	WorstWorkerPercentiles bool `json:"worstWorkerPercentiles,omitempty"`
+
+	// prints the full per-track report, only set for local runs
+	printDetails func()
+}
+
+// ValidateStatsOutput checks a --stats-output format
+func ValidateStatsOutput(format string) error {
+	switch format {
+	case "", StatsOutputJSON, StatsOutputCSV:
+		return nil
+	}
+	return fmt.Errorf("unsupported stats output %q, expected %q or %q", format, StatsOutputJSON, StatsOutputCSV)
+}
+
+func writeResults(format, path string, results *Results) error {
+	var w io.Writer = os.Stdout
+	if path != "" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch format {
+	case StatsOutputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	case StatsOutputCSV:
+		return writeStatsCSV(w, results)
+	}
+	return ValidateStatsOutput(format)
+}
+
+// results collects the final stats of a local run
+func (t *LoadTest) results(stats map[string]*testerStats) *Results {
+	names := reportNames(stats, t.Params.ReportRoles)
+	results := getResults(stats, names)
+	if len(results.Rooms) < 2 {
+		results.Rooms = nil
+	}
+	if t.webhooks != nil {
+		results.Webhooks = t.webhooks.forRooms(t.roomNames)
+	}
+	results.Rotation = t.rotation
+	results.ConnectQueue = t.connectQueue
+	results.Fuzz = t.fuzz
+	results.Dynacast = t.dynacast
+	results.Speakers = t.speakers
+	results.Bitrates = checkOvershoot(stats, t.Params.OvershootThreshold)
+	results.Resumed = t.checkpoints.resumeResults(results.Total, t.Params.ReportRoles, t.status.heldFor())
+	results.printDetails = func() {
+		t.printReport(stats, names)
+		printBitrateResults(results.Bitrates)
+		printRoomResults(results)
+		printLabelResults(results)
+		printEndpointResults(results)
+		printFreezeResults(results)
+		printBackupCodecResults(results)
+		printRetryResults(results)
+		printConnectQueueResults(results)
+		printTailResults(results)
+		printResumeResults(results)
+	}
+	return results
+}
+
+// resultsTotal sums up tester results
+type resultsTotal struct {
+	results     *TesterResults
+	elapsed     time.Duration
+	firstFrame  float64
+	latency     histogram
+	jitter      histogram
+	interactive []time.Duration
+}
+
+func (a *resultsTotal) add(tester *TesterResults, elapsed time.Duration, firstFrame float64, latency, jitter *histogram) {
+	total := a.results
+	total.Testers++
+	total.Tracks += tester.Tracks
+	total.ExpectedTracks += tester.ExpectedTracks
+	total.Packets += tester.Packets
+	total.Bytes += tester.Bytes
+	total.Dropped += tester.Dropped
+	total.Errors += tester.Errors
+	total.DecryptFailures += tester.DecryptFailures
+	total.Replacements += tester.Replacements
+	total.Freezes += tester.Freezes
+	total.FrozenMs += tester.FrozenMs
+	total.BackupTracks += tester.BackupTracks
+	total.firstFrameSamples += tester.firstFrameSamples
+	a.firstFrame += firstFrame
+	a.elapsed = max(a.elapsed, elapsed)
+	a.latency.merge(latency)
+	a.jitter.merge(jitter)
+	if tester.TimeToInteractiveMs > 0 {
+		a.interactive = append(a.interactive, time.Duration(tester.TimeToInteractiveMs*float64(time.Millisecond)))
+	}
+}
+
+func (a *resultsTotal) finish() *TesterResults {
+	total := a.results
+	total.Bitrate = bitrate(total.Bytes, a.elapsed)
+	total.LossRate = lossRate(total.Packets, total.Dropped)
+	if total.firstFrameSamples > 0 {
+		total.AvgFirstFrameMs = a.firstFrame / float64(total.firstFrameSamples)
+	}
+	total.setLatencies(a.latency.percentiles())
+	total.setJitter(a.jitter.percentiles())
+	if len(a.interactive) > 0 {
+		p := getLatencyPercentiles(a.interactive)
+		total.TimeToInteractiveP50Ms = durationMs(p.p50)
+		total.TimeToInteractiveP95Ms = durationMs(p.p95)
+		total.TimeToInteractiveP99Ms = durationMs(p.p99)
+	}
+	return total
+}
+
+func getResults(stats map[string]*testerStats, names []string) *Results {
+	results := &Results{
+		Total: &TesterResults{Name: "Total"},
+	}
+	total := &resultsTotal{results: results.Total}
+	rooms := make(map[string]*resultsTotal)
+	labels := make(map[string]*resultsTotal)
+	endpoints := make(map[string]*resultsTotal)
+	for _, s := range stats {
+		if s.err != nil {
+			results.FailedTesters++
+		}
+		results.ReplacedTesters += s.replacements
+	}
+	for _, name := range names {
+		testerStats := stats[name]
+		s := getTesterSummary(testerStats)
+		tester := &TesterResults{
+			Name:           name,
+			Role:           testerStats.role,
+			Room:           testerStats.room,
+			Label:          testerStats.label,
+			Endpoint:       testerStats.endpoint,
+			Tracks:         s.tracks,
+			ExpectedTracks: s.expected,
+			Packets:        s.packets,
+			Bytes:          s.bytes,
+			Dropped:        s.dropped,
+			Bitrate:        bitrate(s.bytes, s.elapsed),
+			LossRate:       lossRate(s.packets, s.dropped),
+			Errors:         s.errCount,
+			Replacements:   testerStats.replacements,
+			BackupTracks:   testerStats.backupTracks,
+		}
+		if testerStats.err != nil {
+			tester.Error = testerStats.err.Error()
+		}
+		latency := testerStats.latencyHistogram()
+		tester.setLatencies(latency.percentiles())
+		jitter := &histogram{}
+		if testerStats.timeToInteractive > 0 {
+			tester.TimeToInteractiveMs = durationMs(testerStats.timeToInteractive)
+		}
+
+		trackIDs := make([]string, 0, len(testerStats.trackStats))
+		for trackID := range testerStats.trackStats {
+			trackIDs = append(trackIDs, trackID)
+		}
+		sort.Strings(trackIDs)
+		var firstFrame float64
+		for _, trackID := range trackIDs {
+			ts := testerStats.trackStats[trackID]
+			// a stall still going on when the results are taken counts as a freeze
+			freezes, frozen := ts.freezes.Load(), time.Duration(ts.frozen.Load())
+			longestFreeze := time.Duration(ts.longestFreeze.Load())
+			if stall := ts.openFreeze(time.Now()); stall > 0 {
+				freezes++
+				frozen += stall
+				longestFreeze = max(longestFreeze, stall)
+			}
+			track := &TrackResults{
+				TrackID:  ts.trackID,
+				Kind:     string(ts.kind),
+				Packets:  ts.packets.Load(),
+				Bytes:    ts.bytes.Load(),
+				Dropped:  ts.dropped.Load(),
+				Bitrate:  bitrate(ts.bytes.Load(), time.Since(ts.startedAt.Load())),
+				LossRate: lossRate(ts.packets.Load(), ts.dropped.Load()),
+
+				Freezes:         freezes,
+				FrozenMs:        durationMs(frozen),
+				LongestFreezeMs: durationMs(longestFreeze),
+				Codec:           ts.mimeType.Load(),
+			}
+			if firstFrameAt := ts.firstFrameAt.Load(); !ts.publishedAt.IsZero() && !firstFrameAt.IsZero() {
+				track.FirstFrameMs = float64(firstFrameAt.Sub(ts.publishedAt).Milliseconds())
+				firstFrame += track.FirstFrameMs
+				tester.firstFrameSamples++
+			}
+			tester.TrackStats = append(tester.TrackStats, track)
+			jitter.merge(&ts.jitter)
+			tester.DecryptFailures += ts.decryptFailures.Load()
+			tester.Freezes += track.Freezes
+			tester.FrozenMs += track.FrozenMs
+		}
+		if tester.firstFrameSamples > 0 {
+			tester.AvgFirstFrameMs = firstFrame / float64(tester.firstFrameSamples)
+		}
+		tester.setJitter(jitter.percentiles())
+		results.Testers = append(results.Testers, tester)
+
+		total.add(tester, s.elapsed, firstFrame, latency, jitter)
+		if tester.Room != "" {
+			room := rooms[tester.Room]
+			if room == nil {
+				room = &resultsTotal{results: &TesterResults{Name: tester.Room, Room: tester.Room}}
+				rooms[tester.Room] = room
+			}
+			room.add(tester, s.elapsed, firstFrame, latency, jitter)
+		}
+		if tester.Label != "" {
+			label := labels[tester.Label]
+			if label == nil {
+				label = &resultsTotal{results: &TesterResults{Name: tester.Label, Label: tester.Label}}
+				labels[tester.Label] = label
+			}
+			label.add(tester, s.elapsed, firstFrame, latency, jitter)
+		}
+		if tester.Endpoint != "" {
+			endpoint := endpoints[tester.Endpoint]
+			if endpoint == nil {
+				endpoint = &resultsTotal{results: &TesterResults{Name: tester.Endpoint, Endpoint: tester.Endpoint}}
+				endpoints[tester.Endpoint] = endpoint
+			}
+			endpoint.add(tester, s.elapsed, firstFrame, latency, jitter)
+		}
+	}
+	total.finish()
+	// also kept for a single room, a coordinator may get the other rooms from other workers
+	for _, room := range rooms {
+		results.Rooms = append(results.Rooms, room.finish())
+	}
+	sortRooms(results.Rooms)
+	for _, label := range labels {
+		results.Labels = append(results.Labels, label.finish())
+	}
+	sortRooms(results.Labels)
+	for _, endpoint := range endpoints {
+		results.Endpoints = append(results.Endpoints, endpoint.finish())
+	}
+	sortRooms(results.Endpoints)
+	results.Retries = getRetryResults(stats)
+	return results
+}
+
+// sortRooms orders rooms by name, with room_2 before room_10
+func sortRooms(rooms []*TesterResults) {
+	slices.SortFunc(rooms, func(a, b *TesterResults) int {
+		return cmp.Or(cmp.Compare(len(a.Name), len(b.Name)), strings.Compare(a.Name, b.Name))
+	})
+}
+
+func (r *TesterResults) setLatencies(p histogramPercentiles) {
+	if p.count == 0 {
+		return
+	}
+	r.LatencyP50Ms = durationMs(p.p50)
+	r.LatencyP90Ms = durationMs(p.p90)
+	r.LatencyP95Ms = durationMs(p.p95)
+	r.LatencyP99Ms = durationMs(p.p99)
+	r.LatencyMaxMs = durationMs(p.max)
+}
+
+func (r *TesterResults) setJitter(p histogramPercentiles) {
+	if p.count == 0 {
+		return
+	}
+	r.JitterP50Ms = durationMs(p.p50)
+	r.JitterP90Ms = durationMs(p.p90)
+	r.JitterP99Ms = durationMs(p.p99)
+	r.JitterMaxMs = durationMs(p.max)
+}
+
+func durationMs(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func writeStatsCSV(w io.Writer, results *Results) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{
+		"tester", "tracks", "expected_tracks", "packets", "bytes", "dropped",
+		"bitrate_bps", "loss_rate", "avg_first_frame_ms", "latency_p50_ms", "latency_p95_ms", "latency_p99_ms",
+		"errors", "error", "latency_p90_ms", "latency_max_ms", "jitter_p50_ms", "jitter_p90_ms", "jitter_p99_ms", "jitter_max_ms",
+	}); err != nil {
+		return err
+	}
+	for _, tester := range append(results.Testers, results.Total) {
+		if err := cw.Write([]string{
+			tester.Name,
+			strconv.Itoa(tester.Tracks),
+			strconv.Itoa(tester.ExpectedTracks),
+			strconv.FormatInt(tester.Packets, 10),
+			strconv.FormatInt(tester.Bytes, 10),
+			strconv.FormatInt(tester.Dropped, 10),
+			strconv.FormatFloat(tester.Bitrate, 'f', 0, 64),
+			strconv.FormatFloat(tester.LossRate, 'f', 6, 64),
+			strconv.FormatFloat(tester.AvgFirstFrameMs, 'f', 1, 64),
+			strconv.FormatFloat(tester.LatencyP50Ms, 'f', 1, 64),
+			strconv.FormatFloat(tester.LatencyP95Ms, 'f', 1, 64),
+			strconv.FormatFloat(tester.LatencyP99Ms, 'f', 1, 64),
+			strconv.FormatInt(tester.Errors, 10),
+			tester.Error,
+			strconv.FormatFloat(tester.LatencyP90Ms, 'f', 1, 64),
+			strconv.FormatFloat(tester.LatencyMaxMs, 'f', 1, 64),
+			strconv.FormatFloat(tester.JitterP50Ms, 'f', 1, 64),
+			strconv.FormatFloat(tester.JitterP90Ms, 'f', 1, 64),
+			strconv.FormatFloat(tester.JitterP99Ms, 'f', 1, 64),
+			strconv.FormatFloat(tester.JitterMaxMs, 'f', 1, 64),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func bitrate(bytes int64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(bytes*8) / elapsed.Seconds()
+}
+
+func lossRate(packets, dropped int64) float64 {
+	if packets+dropped == 0 {
+		return 0
+	}
+	return float64(dropped) / float64(packets+dropped)
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+func TestExportStats(t *testing.T) {
+	ts := &trackStats{trackID: "TR_1", kind: lksdk.TrackKindVideo, publishedAt: time.Now().Add(-time.Second)}
+	ts.startedAt.Store(time.Now().Add(-time.Second))
+	ts.firstFrameAt.Store(ts.publishedAt.Add(200 * time.Millisecond))
+	ts.packets.Store(90)
+	ts.dropped.Store(10)
+	ts.bytes.Store(1000)
+	stats := map[string]*testerStats{
+		"Sub 0": {expectedTracks: 1, trackStats: map[string]*trackStats{"TR_1": ts}},
+		"Sub 1": {expectedTracks: 1, trackStats: map[string]*trackStats{}},
+	}
+
+	results := getResults(stats, []string{"Sub 0", "Sub 1"})
+	require.Len(t, results.Testers, 2)
+	require.Equal(t, 0.1, results.Total.LossRate)
+	require.Equal(t, 2, results.Total.ExpectedTracks)
+	require.Equal(t, float64(200), results.Total.AvgFirstFrameMs)
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, writeStatsCSV(buf, results))
+	records, err := csv.NewReader(buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	require.Equal(t, "Total", records[3][0])
+	require.Equal(t, "1", records[3][1])
+}
+
+func TestRoomResults(t *testing.T) {
+	track := func(packets, dropped int64) map[string]*trackStats {
+		ts := &trackStats{trackID: "TR_1", kind: lksdk.TrackKindAudio}
+		ts.startedAt.Store(time.Now().Add(-time.Second))
+		ts.packets.Store(packets)
+		ts.dropped.Store(dropped)
+		return map[string]*trackStats{"TR_1": ts}
+	}
+	stats := map[string]*testerStats{
+		"R0 Sub 0":  {room: "test_0", expectedTracks: 1, trackStats: track(100, 0)},
+		"R2 Sub 0":  {room: "test_2", expectedTracks: 1, trackStats: track(50, 50)},
+		"R10 Sub 0": {room: "test_10", expectedTracks: 1, trackStats: track(100, 0)},
+		"R10 Sub 1": {room: "test_10", expectedTracks: 1, trackStats: map[string]*trackStats{}},
+	}
+
+	results := getResults(stats, []string{"R0 Sub 0", "R10 Sub 0", "R10 Sub 1", "R2 Sub 0"})
+	require.Len(t, results.Rooms, 3)
+	require.Equal(t, []string{"test_0", "test_2", "test_10"},
+		[]string{results.Rooms[0].Name, results.Rooms[1].Name, results.Rooms[2].Name})
+	require.Equal(t, 0.5, results.Rooms[1].LossRate)
+	require.Equal(t, 2, results.Rooms[2].Testers)
+	require.Equal(t, 1, results.Rooms[2].Tracks)
+	require.Equal(t, 4, results.Total.Testers)
+
+	// a room split between workers is merged back together
+	worker := getResults(map[string]*testerStats{
+		"Sub 0": {room: "test_2", expectedTracks: 1, trackStats: track(100, 0)},
+	}, []string{"Sub 0"})
+	merged := mergeResults([]*Results{results, worker})
+	require.Len(t, merged.Rooms, 3)
+	require.Equal(t, 2, merged.Rooms[1].Testers)
+	require.Equal(t, 0.25, merged.Rooms[1].LossRate)
+	require.Equal(t, 5, merged.Total.Testers)
+}
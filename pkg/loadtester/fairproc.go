@@ -0,0 +1,157 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/livekit/livekit-cli/v2/pkg/provider"
+)
+
+// DefaultFairprocProfile is the profile of fairproc rooms when none is given
+const DefaultFairprocProfile = "small"
+
+//go:embed fairproc_profiles.yaml
+var builtinFairprocProfiles []byte
+
+// FairprocProfile is a named set of the media settings of fairproc publishers
+type FairprocProfile struct {
+	Name        string        `yaml:"name"`
+	Description string        `yaml:"description,omitempty"`
+	Web         FairprocVideo `yaml:"web"`
+	Screen      FairprocVideo `yaml:"screen"`
+	// kbps
+	AudioBitrate int `yaml:"audio_bitrate"`
+}
+
+type FairprocVideo struct {
+	Width     int `yaml:"width"`
+	Height    int `yaml:"height"`
+	FrameRate int `yaml:"frame_rate"`
+	// kbps
+	Bitrate int `yaml:"bitrate"`
+}
+
+func (v FairprocVideo) String() string {
+	return fmt.Sprintf("%dx%d %dfps %dkbps", v.Width, v.Height, v.FrameRate, v.Bitrate)
+}
+
+// LoadFairprocProfiles returns the built-in profiles, followed by those in path if set.
+// A profile in path replaces a built-in one with the same name.
+func LoadFairprocProfiles(path string) ([]*FairprocProfile, error) {
+	var profiles []*FairprocProfile
+	if err := yaml.Unmarshal(builtinFairprocProfiles, &profiles); err != nil {
+		return nil, err
+	}
+	if path == "" {
+		return profiles, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var custom []*FairprocProfile
+	if err = yaml.Unmarshal(data, &custom); err != nil {
+		return nil, fmt.Errorf("invalid fairproc profiles %s: %w", path, err)
+	}
+	for _, p := range custom {
+		if p.Name == "" {
+			return nil, fmt.Errorf("invalid fairproc profiles %s: profile without a name", path)
+		}
+		replaced := false
+		for i, existing := range profiles {
+			if existing.Name == p.Name {
+				profiles[i] = p
+				replaced = true
+			}
+		}
+		if !replaced {
+			profiles = append(profiles, p)
+		}
+	}
+	return profiles, nil
+}
+
+func FindFairprocProfile(profiles []*FairprocProfile, name string) (*FairprocProfile, error) {
+	names := make([]string, 0, len(profiles))
+	for _, p := range profiles {
+		if p.Name == name {
+			return p, nil
+		}
+		names = append(names, p.Name)
+	}
+	return nil, fmt.Errorf("unknown fairproc profile %q, expected %s", name, strings.Join(names, ", "))
+}
+
+// Apply fills in the fairproc settings that haven't been set, leaving flags as overrides
+func (p *FairprocProfile) Apply(params *Params) {
+	for _, setting := range []struct {
+		param *int
+		value int
+	}{
+		{&params.FairprocConfigWebWidth, p.Web.Width},
+		{&params.FairprocConfigWebHieght, p.Web.Height},
+		{&params.FairprocConfigWebFrameRate, p.Web.FrameRate},
+		{&params.FairprocConfigWebBitrate, p.Web.Bitrate},
+		{&params.FairprocConfigScreenWidth, p.Screen.Width},
+		{&params.FairprocConfigScreenHeight, p.Screen.Height},
+		{&params.FairprocConfigScreenFrameRate, p.Screen.FrameRate},
+		{&params.FairprocConfigScreenBitrate, p.Screen.Bitrate},
+		{&params.FairprocAudioBitrate, p.AudioBitrate},
+	} {
+		if *setting.param <= 0 {
+			*setting.param = setting.value
+		}
+	}
+	if params.VideoCodec == "" {
+		// the only codec fairproc video is embedded in
+		params.VideoCodec = "h264"
+	}
+}
+
+// ValidateFairproc checks that fairproc publishers have every setting they need, and that their
+// video can be published from the embedded clips
+func ValidateFairproc(params Params) error {
+	if !params.IsFairproc {
+		return nil
+	}
+	web := FairprocVideo{params.FairprocConfigWebWidth, params.FairprocConfigWebHieght, params.FairprocConfigWebFrameRate, params.FairprocConfigWebBitrate}
+	screen := FairprocVideo{params.FairprocConfigScreenWidth, params.FairprocConfigScreenHeight, params.FairprocConfigScreenFrameRate, params.FairprocConfigScreenBitrate}
+	for _, v := range []struct {
+		name  string
+		video FairprocVideo
+	}{{"web", web}, {"screen", screen}} {
+		if v.video.Width <= 0 || v.video.Height <= 0 || v.video.FrameRate <= 0 || v.video.Bitrate <= 0 {
+			return fmt.Errorf("fairproc %s video needs a width, height, frame rate and bitrate, got %s; pick a --fairproc-profile or set every --fairproc-config-%s-* flag",
+				v.name, v.video, v.name)
+		}
+		if err := provider.CheckFairprocClip(v.video.Width, v.video.Height, v.video.Bitrate); err != nil {
+			return fmt.Errorf("fairproc %s video: %w", v.name, err)
+		}
+	}
+	if params.FairprocAudioBitrate <= 0 {
+		return fmt.Errorf("fairproc audio needs a bitrate")
+	}
+	if params.VideoCodec != "" && params.VideoCodec != "h264" {
+		return fmt.Errorf("fairproc video is only embedded as h264, not %s", params.VideoCodec)
+	}
+	return nil
+}
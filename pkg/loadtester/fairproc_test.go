@@ -0,0 +1,97 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFairprocProfiles(t *testing.T) {
+	profiles, err := LoadFairprocProfiles("")
+	require.NoError(t, err)
+	for _, name := range []string{"small", "medium", "large"} {
+		p, err := FindFairprocProfile(profiles, name)
+		require.NoError(t, err)
+		params := Params{IsFairproc: true}
+		p.Apply(&params)
+		require.NoError(t, ValidateFairproc(params), name)
+	}
+	_, err = FindFairprocProfile(profiles, "huge")
+	require.ErrorContains(t, err, "expected small, medium, large")
+
+	path := filepath.Join(t.TempDir(), "profiles.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+- name: small
+  web: {width: 640, height: 360, frame_rate: 10, bitrate: 400}
+  screen: {width: 640, height: 360, frame_rate: 2, bitrate: 400}
+  audio_bitrate: 12
+- name: exam
+  web: {width: 320, height: 180, frame_rate: 10, bitrate: 150}
+`), 0644))
+	profiles, err = LoadFairprocProfiles(path)
+	require.NoError(t, err)
+	require.Len(t, profiles, 4)
+	small, err := FindFairprocProfile(profiles, "small")
+	require.NoError(t, err)
+	require.Equal(t, 12, small.AudioBitrate)
+
+	// incomplete profiles are caught with the settings they lack
+	exam, err := FindFairprocProfile(profiles, "exam")
+	require.NoError(t, err)
+	params := Params{IsFairproc: true}
+	exam.Apply(&params)
+	require.ErrorContains(t, ValidateFairproc(params), "fairproc screen video needs")
+}
+
+func TestFairprocOverrides(t *testing.T) {
+	profiles, err := LoadFairprocProfiles("")
+	require.NoError(t, err)
+	small, err := FindFairprocProfile(profiles, "small")
+	require.NoError(t, err)
+
+	// flags left at -1 are filled in, the rest override the profile
+	params := Params{
+		IsFairproc:                    true,
+		FairprocConfigWebWidth:        640,
+		FairprocConfigWebHieght:       360,
+		FairprocConfigWebFrameRate:    -1,
+		FairprocConfigWebBitrate:      400,
+		FairprocConfigScreenWidth:     -1,
+		FairprocConfigScreenHeight:    -1,
+		FairprocConfigScreenFrameRate: 2,
+		FairprocConfigScreenBitrate:   -1,
+		FairprocAudioBitrate:          -1,
+	}
+	small.Apply(&params)
+	require.Equal(t, 640, params.FairprocConfigWebWidth)
+	require.Equal(t, 360, params.FairprocConfigWebHieght)
+	require.Equal(t, 320, params.FairprocConfigScreenWidth)
+	require.Equal(t, 15, params.FairprocConfigWebFrameRate)
+	require.Equal(t, 2, params.FairprocConfigScreenFrameRate)
+	require.Equal(t, 16, params.FairprocAudioBitrate)
+	require.Equal(t, "h264", params.VideoCodec)
+	require.NoError(t, ValidateFairproc(params))
+
+	params.FairprocConfigWebBitrate = 29
+	require.ErrorContains(t, ValidateFairproc(params), "no embedded clip for 640x360 video at 29kbps")
+	params.FairprocConfigWebBitrate = 400
+	params.VideoCodec = "vp8"
+	require.Error(t, ValidateFairproc(params))
+	require.NoError(t, ValidateFairproc(Params{}))
+}
@@ -0,0 +1,224 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+// DefaultFreezeThreshold is the longest time between two frames of a video track before
+// it's counted as frozen
+const DefaultFreezeThreshold = time.Second
+
+// freezeMeter finds the stalls between the frames of a video track, the frozen video viewers
+// see even when packets keep arriving, like while waiting for a keyframe after loss. Time the
+// track is muted by its publisher, or paused or unsubscribed by the tester, is not a freeze.
+type freezeMeter struct {
+	threshold time.Duration
+
+	lock        sync.Mutex
+	started     bool
+	lastRTP     uint32
+	lastFrameAt time.Time
+	paused      freezePause
+	ended       bool
+}
+
+// freezePause is why a track is not expected to have frames, one bit per reason
+type freezePause uint8
+
+const (
+	freezePauseMuted freezePause = 1 << iota
+	freezePauseDisabled
+	freezePauseUnsubscribed
+)
+
+// next returns how long the video was frozen before the frame of a packet completed at now,
+// or 0 if it wasn't, or the packet is part of the last frame
+func (m *freezeMeter) next(rtpTimestamp uint32, now time.Time) time.Duration {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.started && rtpTimestamp == m.lastRTP {
+		return 0
+	}
+	stall := m.openLocked(now)
+	m.started, m.lastRTP, m.lastFrameAt = true, rtpTimestamp, now
+	return stall
+}
+
+func (m *freezePause) set(reason freezePause, paused bool) {
+	if paused {
+		*m |= reason
+	} else {
+		*m &^= reason
+	}
+}
+
+// setPaused stops or resumes measuring while the track is not expected to have frames for
+// reason. Pausing returns the stall it ends, if any.
+func (m *freezeMeter) setPaused(reason freezePause, paused bool, now time.Time) time.Duration {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	wasPaused := m.paused != 0
+	m.paused.set(reason, paused)
+	switch {
+	case !wasPaused && m.paused != 0:
+		return m.stallLocked(now)
+	case wasPaused && m.paused == 0:
+		// the video resumes from a still frame, without a stall before the next one
+		m.lastFrameAt = now
+	}
+	return 0
+}
+
+// end stops measuring once no more frames arrive on the track, and returns the stall it ends
+func (m *freezeMeter) end(now time.Time) time.Duration {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	stall := m.openLocked(now)
+	m.ended = true
+	return stall
+}
+
+// open returns how long the video has been frozen at now without a new frame, for a report
+// made while the stall lasts, or 0 if it isn't
+func (m *freezeMeter) open(now time.Time) time.Duration {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.openLocked(now)
+}
+
+// reset drops the part of a stall before now, for freezes to be measured from now on
+func (m *freezeMeter) reset(now time.Time) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.started && m.lastFrameAt.Before(now) {
+		m.lastFrameAt = now
+	}
+}
+
+func (m *freezeMeter) openLocked(now time.Time) time.Duration {
+	if m.paused != 0 {
+		return 0
+	}
+	return m.stallLocked(now)
+}
+
+func (m *freezeMeter) stallLocked(now time.Time) time.Duration {
+	if !m.started || m.ended {
+		return 0
+	}
+	if stall := now.Sub(m.lastFrameAt); stall > m.threshold {
+		return stall
+	}
+	return 0
+}
+
+func (t *LoadTester) freezeThreshold() time.Duration {
+	if t.params.FreezeThreshold > 0 {
+		return t.params.FreezeThreshold
+	}
+	return DefaultFreezeThreshold
+}
+
+// pauseFreezes stops or resumes measuring the freezes of a subscribed video track for reason
+func (t *LoadTester) pauseFreezes(pub *lksdk.RemoteTrackPublication, reason freezePause, paused bool) {
+	track := pub.TrackRemote()
+	if track == nil {
+		return
+	}
+	value, ok := t.stats.Load(track.ID())
+	if !ok {
+		return
+	}
+	ts := value.(*trackStats)
+	if m := ts.freezeMeter.Load(); m != nil {
+		if stall := m.setPaused(reason, paused, time.Now()); stall > 0 {
+			ts.recordFreeze(stall)
+		}
+	}
+}
+
+// unsubscribe stops receiving a track, which is not frozen while it is unsubscribed
+func (t *LoadTester) unsubscribe(pub *lksdk.RemoteTrackPublication) error {
+	t.pauseFreezes(pub, freezePauseUnsubscribed, true)
+	return pub.SetSubscribed(false)
+}
+
+// openFreeze is the stall the track is in at now, not recorded yet as it hasn't ended
+func (s *trackStats) openFreeze(now time.Time) time.Duration {
+	if m := s.freezeMeter.Load(); m != nil {
+		return m.open(now)
+	}
+	return 0
+}
+
+func (s *trackStats) recordFreeze(stall time.Duration) {
+	s.freezes.Inc()
+	s.frozen.Add(int64(stall))
+	for {
+		longest := s.longestFreeze.Load()
+		if int64(stall) <= longest || s.longestFreeze.CompareAndSwap(longest, int64(stall)) {
+			return
+		}
+	}
+}
+
+func printFreezeResults(results *Results) {
+	table := util.CreateTable().
+		Headers("Tester", "Track", "Freezes", "Frozen", "Longest")
+	var videoTracks, frozenTracks int
+	var freezes int64
+	var frozen float64
+	for _, tester := range results.Testers {
+		for _, track := range tester.TrackStats {
+			if track.Kind != string(lksdk.TrackKindVideo) {
+				continue
+			}
+			videoTracks++
+			if track.Freezes == 0 {
+				continue
+			}
+			frozenTracks++
+			freezes += track.Freezes
+			frozen += track.FrozenMs
+			table.Row(
+				tester.Name,
+				track.TrackID,
+				strconv.FormatInt(track.Freezes, 10),
+				formatFrozen(track.FrozenMs),
+				formatFrozen(track.LongestFreezeMs),
+			)
+		}
+	}
+	if frozenTracks == 0 {
+		return
+	}
+	fmt.Println("\nVideo freezes:")
+	fmt.Println(table)
+	fmt.Printf("%d freezes on %d of %d video tracks, frozen for %s in total\n",
+		freezes, frozenTracks, videoTracks, formatFrozen(frozen))
+}
+
+func formatFrozen(ms float64) string {
+	return (time.Duration(ms * float64(time.Millisecond))).Round(time.Millisecond).String()
+}
@@ -0,0 +1,100 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFreezeMeter(t *testing.T) {
+	m := &freezeMeter{threshold: time.Second}
+	start := time.Now()
+	require.Zero(t, m.next(1000, start))
+	// packets of the same frame
+	require.Zero(t, m.next(1000, start.Add(2*time.Second)))
+	require.Zero(t, m.next(4000, start.Add(500*time.Millisecond)))
+	require.Equal(t, 1500*time.Millisecond, m.next(7000, start.Add(2*time.Second)))
+	require.Zero(t, m.next(10000, start.Add(2033*time.Millisecond)))
+}
+
+func TestFreezeMeterOpenStall(t *testing.T) {
+	m := &freezeMeter{threshold: time.Second}
+	start := time.Now()
+	require.Zero(t, m.open(start))
+	m.next(1000, start)
+	require.Zero(t, m.open(start.Add(time.Second)))
+	require.Equal(t, 3*time.Second, m.open(start.Add(3*time.Second)))
+
+	// the stall ends with the track, and is no longer open after
+	require.Equal(t, 4*time.Second, m.end(start.Add(4*time.Second)))
+	require.Zero(t, m.open(start.Add(5*time.Second)))
+}
+
+func TestFreezeMeterPaused(t *testing.T) {
+	m := &freezeMeter{threshold: time.Second}
+	start := time.Now()
+	m.next(1000, start)
+
+	// muted before the threshold, nothing is frozen while muted
+	require.Zero(t, m.setPaused(freezePauseMuted, true, start.Add(500*time.Millisecond)))
+	require.Zero(t, m.open(start.Add(10*time.Second)))
+	// the track is disabled too, unmuting doesn't resume it
+	require.Zero(t, m.setPaused(freezePauseDisabled, true, start.Add(11*time.Second)))
+	require.Zero(t, m.setPaused(freezePauseMuted, false, start.Add(12*time.Second)))
+	require.Zero(t, m.open(start.Add(13*time.Second)))
+	// measuring resumes from when the track is enabled again
+	require.Zero(t, m.setPaused(freezePauseDisabled, false, start.Add(14*time.Second)))
+	require.Zero(t, m.next(4000, start.Add(14500*time.Millisecond)))
+
+	// a stall in progress ends when the track is paused
+	require.Equal(t, 2*time.Second, m.setPaused(freezePauseUnsubscribed, true, start.Add(16500*time.Millisecond)))
+	require.Zero(t, m.end(start.Add(20*time.Second)))
+}
+
+func TestFreezeResults(t *testing.T) {
+	video := &trackStats{trackID: "TR_v", kind: "video"}
+	video.recordFreeze(1500 * time.Millisecond)
+	video.recordFreeze(3 * time.Second)
+	audio := &trackStats{trackID: "TR_a", kind: "audio"}
+	stats := map[string]*testerStats{
+		"a": {trackStats: map[string]*trackStats{"TR_v": video, "TR_a": audio}},
+		"b": {trackStats: map[string]*trackStats{"TR_v2": {trackID: "TR_v2", kind: "video"}}},
+	}
+	results := getResults(stats, []string{"a", "b"})
+
+	track := results.Testers[0].TrackStats[1]
+	require.Equal(t, "TR_v", track.TrackID)
+	require.Equal(t, int64(2), track.Freezes)
+	require.Equal(t, 4500.0, track.FrozenMs)
+	require.Equal(t, 3000.0, track.LongestFreezeMs)
+	require.Equal(t, int64(2), results.Total.Freezes)
+	require.Equal(t, 4500.0, results.Total.FrozenMs)
+
+	// a track frozen when the results are taken
+	meter := &freezeMeter{threshold: time.Second}
+	meter.next(1000, time.Now().Add(-2*time.Second))
+	video.freezeMeter.Store(meter)
+	track = getResults(stats, []string{"a", "b"}).Testers[0].TrackStats[1]
+	require.Equal(t, int64(3), track.Freezes)
+	require.InDelta(t, 6500.0, track.FrozenMs, 100)
+	require.Equal(t, 3000.0, track.LongestFreezeMs)
+	video.freezeMeter.Store(nil)
+
+	merged := mergeResults([]*Results{results, getResults(stats, []string{"a"})})
+	require.Equal(t, int64(4), merged.Total.Freezes)
+}
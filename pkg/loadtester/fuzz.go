@@ -0,0 +1,364 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"maps"
+	mathrand "math/rand"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+	"github.com/livekit/server-sdk-go/v2/signalling"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+// how long to watch the connection after each fuzz message for the server to close it
+const fuzzSettle = 250 * time.Millisecond
+
+// fuzzCase is a malformed or out-of-order exchange of signal messages. The server is expected
+// to ignore or reject it, closing at most the connection it arrived on.
+type fuzzCase struct {
+	name string
+	// frames are sent as text messages, which the server parses as JSON
+	text   bool
+	frames func() [][]byte
+}
+
+func signalRequests(reqs ...*livekit.SignalRequest) func() [][]byte {
+	return func() [][]byte {
+		frames := make([][]byte, 0, len(reqs))
+		for _, req := range reqs {
+			data, _ := proto.Marshal(req)
+			frames = append(frames, data)
+		}
+		return frames
+	}
+}
+
+var fuzzCases = []fuzzCase{
+	{name: "garbage", frames: func() [][]byte {
+		data := make([]byte, 256)
+		_, _ = rand.Read(data)
+		return [][]byte{data}
+	}},
+	{name: "truncated", frames: func() [][]byte {
+		data, _ := proto.Marshal(&livekit.SignalRequest{Message: &livekit.SignalRequest_Trickle{
+			Trickle: &livekit.TrickleRequest{CandidateInit: `{"candidate":"candidate:1 1 udp 1 127.0.0.1 9 typ host"}`},
+		}})
+		return [][]byte{data[:len(data)/2]}
+	}},
+	{name: "empty-request", frames: signalRequests(&livekit.SignalRequest{})},
+	{name: "invalid-json", text: true, frames: func() [][]byte { return [][]byte{[]byte(`{"offer":`)} }},
+	{name: "answer-before-offer", frames: signalRequests(&livekit.SignalRequest{Message: &livekit.SignalRequest_Answer{
+		Answer: &livekit.SessionDescription{Type: "answer", Sdp: "v=0\r\nnot an sdp"},
+	}})},
+	{name: "malformed-candidate", frames: signalRequests(&livekit.SignalRequest{Message: &livekit.SignalRequest_Trickle{
+		Trickle: &livekit.TrickleRequest{CandidateInit: "not json", Target: livekit.SignalTarget_SUBSCRIBER},
+	}})},
+	{name: "unknown-track-mute", frames: signalRequests(&livekit.SignalRequest{Message: &livekit.SignalRequest_Mute{
+		Mute: &livekit.MuteTrackRequest{Sid: "TR_fuzz", Muted: true},
+	}})},
+	{name: "unknown-track-subscribe", frames: signalRequests(&livekit.SignalRequest{Message: &livekit.SignalRequest_Subscription{
+		Subscription: &livekit.UpdateSubscription{TrackSids: []string{"TR_fuzz", ""}, Subscribe: true},
+	}})},
+	{name: "oversized-metadata", frames: signalRequests(&livekit.SignalRequest{Message: &livekit.SignalRequest_UpdateMetadata{
+		UpdateMetadata: &livekit.UpdateParticipantMetadata{Metadata: strings.Repeat("x", 1<<20)},
+	}})},
+	{name: "offer-after-leave", frames: signalRequests(
+		&livekit.SignalRequest{Message: &livekit.SignalRequest_Leave{Leave: &livekit.LeaveRequest{}}},
+		&livekit.SignalRequest{Message: &livekit.SignalRequest_Offer{
+			Offer: &livekit.SessionDescription{Type: "offer", Sdp: "v=0\r\n"},
+		}},
+	)},
+}
+
+// FuzzOutcome is how the server handled one fuzz message
+type FuzzOutcome struct {
+	Case string `json:"case"`
+	// the server closed the connection the message was sent on
+	Closed bool `json:"closed"`
+	// the message could not be sent, or a new connection could not be made for it
+	Error string `json:"error,omitempty"`
+}
+
+// fuzzer opens its own signal connection next to a subscriber, under a derived identity, and
+// sends every fuzz case over it
+type fuzzer struct {
+	tester   *LoadTester
+	identity string
+	outcomes []*FuzzOutcome
+}
+
+func newFuzzer(tester *LoadTester) *fuzzer {
+	return &fuzzer{tester: tester, identity: tester.identity() + "_fuzz"}
+}
+
+func (f *fuzzer) run(ctx context.Context) {
+	var conn *fuzzConn
+	defer func() {
+		if conn != nil {
+			conn.close()
+		}
+	}()
+	for i, c := range fuzzCases {
+		if ctx.Err() != nil {
+			return
+		}
+		outcome := &FuzzOutcome{Case: c.name}
+		f.outcomes = append(f.outcomes, outcome)
+		if conn == nil {
+			var err error
+			// every connection needs its own identity, or it would evict the previous one
+			if conn, err = dialFuzzConn(ctx, f.tester.params, fmt.Sprintf("%s-%d", f.identity, i)); err != nil {
+				outcome.Error = err.Error()
+				continue
+			}
+		}
+		if err := conn.send(c); err != nil {
+			outcome.Error = err.Error()
+		}
+		if conn.closedWithin(fuzzSettle) {
+			outcome.Closed = true
+			conn = nil
+		}
+	}
+}
+
+// fuzzConn is a raw signal connection, bypassing the SDK's validation of outgoing messages
+type fuzzConn struct {
+	ws     *websocket.Conn
+	closed chan struct{}
+}
+
+func fuzzToken(params TesterParams, identity string) (string, error) {
+	apiKey, apiSecret := params.rotation.credentials(params.APIKey, params.APISecret)
+	at := auth.NewAccessToken(apiKey, apiSecret)
+	at.SetVideoGrant(&auth.VideoGrant{RoomJoin: true, Room: params.Room}).
+		SetIdentity(identity).
+		SetMetadata(params.Metadata)
+	attributes := maps.Clone(params.Attributes)
+	if params.MarkSynthetic {
+		if attributes == nil {
+			attributes = make(map[string]string)
+		}
+		attributes[SyntheticAttribute] = SyntheticAttributeValue
+	}
+	if len(attributes) > 0 {
+		at.SetAttributes(attributes)
+	}
+	return at.ToJWT()
+}
+
+func dialFuzzConn(ctx context.Context, params TesterParams, identity string) (*fuzzConn, error) {
+	token, err := fuzzToken(params, identity)
+	if err != nil {
+		return nil, err
+	}
+	u := fmt.Sprintf("%s/rtc?protocol=%d&auto_subscribe=0", strings.TrimSuffix(signalling.ToWebsocketURL(params.URL), "/"), lksdk.PROTOCOL)
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+token)
+	ws, _, err := websocket.DefaultDialer.DialContext(ctx, u, header)
+	if err != nil {
+		return nil, err
+	}
+	c := &fuzzConn{ws: ws, closed: make(chan struct{})}
+	// responses are drained so the server never blocks on them, until it closes the connection
+	go func() {
+		defer close(c.closed)
+		for {
+			if _, _, err := ws.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+	return c, nil
+}
+
+func (c *fuzzConn) send(fc fuzzCase) error {
+	kind := websocket.BinaryMessage
+	if fc.text {
+		kind = websocket.TextMessage
+	}
+	for _, frame := range fc.frames() {
+		if err := c.ws.WriteMessage(kind, frame); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *fuzzConn) closedWithin(d time.Duration) bool {
+	select {
+	case <-c.closed:
+		return true
+	case <-time.After(d):
+		return false
+	}
+}
+
+func (c *fuzzConn) close() {
+	_ = c.ws.Close()
+	<-c.closed
+}
+
+type FuzzCaseResults struct {
+	Case string `json:"case"`
+	Sent int    `json:"sent"`
+	// connections the server closed in response
+	Closed int `json:"closed"`
+	Errors int `json:"errors"`
+}
+
+type FuzzResults struct {
+	Fuzzers int                `json:"fuzzers"`
+	Cases   []*FuzzCaseResults `json:"cases"`
+	// testers connected to a fuzzed room when fuzzing began, and those of them that lost
+	// their connection since
+	Bystanders             int      `json:"bystanders"`
+	BystandersDisconnected []string `json:"bystandersDisconnected,omitempty"`
+	Errors                 []string `json:"errors,omitempty"`
+}
+
+// ValidateFuzz checks that fuzzing was explicitly enabled, and is not aimed at LiveKit Cloud
+func ValidateFuzz(params Params) error {
+	if params.FuzzRate == 0 {
+		return nil
+	}
+	if !params.UnsafeFuzz {
+		return fmt.Errorf("fuzzing signaling needs --unsafe-fuzz")
+	}
+	if params.FuzzRate < 0 || params.FuzzRate > 1 {
+		return fmt.Errorf("fuzz rate must be between 0 and 1")
+	}
+	if u, err := url.Parse(params.URL); err == nil && strings.HasSuffix(u.Hostname(), ".livekit.cloud") {
+		return fmt.Errorf("fuzzing signaling is not allowed against LiveKit Cloud")
+	}
+	return nil
+}
+
+// fuzzSession fuzzes signaling next to a random FuzzRate of the connected subscribers
+type fuzzSession struct {
+	fuzzers    []*fuzzer
+	bystanders []*LoadTester
+	wg         sync.WaitGroup
+}
+
+func startFuzzing(ctx context.Context, params Params, testers []*LoadTester) *fuzzSession {
+	if params.FuzzRate <= 0 {
+		return nil
+	}
+	s := &fuzzSession{}
+	rooms := make(map[string]bool)
+	for _, t := range testers {
+		if t.params.Role.IsPublisher() || !t.IsRunning() || mathrand.Float64() >= params.FuzzRate {
+			continue
+		}
+		s.fuzzers = append(s.fuzzers, newFuzzer(t))
+		rooms[t.params.Room] = true
+	}
+	for _, t := range testers {
+		if rooms[t.params.Room] && t.IsRunning() {
+			s.bystanders = append(s.bystanders, t)
+		}
+	}
+	if len(s.fuzzers) > 0 {
+		fmt.Printf("Fuzzing signaling from %d testers\n", len(s.fuzzers))
+	}
+	for _, f := range s.fuzzers {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			f.run(ctx)
+		}()
+	}
+	return s
+}
+
+// finish waits for the fuzzers, and checks that nobody else in their rooms was disconnected
+func (s *fuzzSession) finish() *FuzzResults {
+	if s == nil {
+		return nil
+	}
+	s.wg.Wait()
+	res := &FuzzResults{Fuzzers: len(s.fuzzers), Bystanders: len(s.bystanders)}
+	byCase := make(map[string]*FuzzCaseResults)
+	for _, c := range fuzzCases {
+		byCase[c.name] = &FuzzCaseResults{Case: c.name}
+		res.Cases = append(res.Cases, byCase[c.name])
+	}
+	for _, f := range s.fuzzers {
+		for _, o := range f.outcomes {
+			c := byCase[o.Case]
+			c.Sent++
+			if o.Closed {
+				c.Closed++
+			}
+			if o.Error != "" {
+				c.Errors++
+				res.Errors = append(res.Errors, fmt.Sprintf("%s %s: %s", f.identity, o.Case, o.Error))
+			}
+		}
+	}
+	for _, t := range s.bystanders {
+		if !t.IsRunning() {
+			res.BystandersDisconnected = append(res.BystandersDisconnected, t.params.name)
+		}
+	}
+	sort.Strings(res.BystandersDisconnected)
+	return res
+}
+
+// passed is true when every fuzz message was delivered, and no other tester lost its connection
+func (r *FuzzResults) passed() bool {
+	return len(r.Errors) == 0 && len(r.BystandersDisconnected) == 0
+}
+
+func printFuzzResults(r *FuzzResults) {
+	if r == nil || r.Fuzzers == 0 {
+		return
+	}
+	table := util.CreateTable().
+		Headers("Case", "Sent", "Closed by Server", "Errors")
+	for _, c := range r.Cases {
+		table.Row(c.Case, fmt.Sprint(c.Sent), fmt.Sprint(c.Closed), fmt.Sprint(c.Errors))
+	}
+	verdict := "passed"
+	if !r.passed() {
+		verdict = "FAILED"
+	}
+	fmt.Printf("\nSignaling fuzz from %d testers (%s, %d/%d bystanders still connected):\n",
+		r.Fuzzers, verdict, r.Bystanders-len(r.BystandersDisconnected), r.Bystanders)
+	fmt.Println(table)
+	if len(r.BystandersDisconnected) > 0 {
+		fmt.Println("Disconnected:", strings.Join(r.BystandersDisconnected, ", "))
+	}
+	if len(r.Errors) > 0 {
+		fmt.Println(strings.Join(r.Errors, "\n"))
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+)
+
+func TestValidateFuzz(t *testing.T) {
+	params := Params{}
+	params.URL = "ws://localhost:7880"
+	require.NoError(t, ValidateFuzz(params))
+
+	params.FuzzRate = 0.5
+	require.Error(t, ValidateFuzz(params))
+	params.UnsafeFuzz = true
+	require.NoError(t, ValidateFuzz(params))
+	params.FuzzRate = 2
+	require.Error(t, ValidateFuzz(params))
+	params.FuzzRate = 0.5
+	params.URL = "wss://project.livekit.cloud"
+	require.Error(t, ValidateFuzz(params))
+}
+
+func TestFuzzer(t *testing.T) {
+	var lock sync.Mutex
+	var identities []string
+	upgrader := websocket.Upgrader{}
+	// like a strict server, closes the connection on anything that does not parse
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, err := auth.ParseAPIToken(r.Header.Get("Authorization")[len("Bearer "):])
+		require.NoError(t, err)
+		lock.Lock()
+		identities = append(identities, claims.Identity())
+		lock.Unlock()
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			kind, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if kind != websocket.BinaryMessage || proto.Unmarshal(data, &livekit.SignalRequest{}) != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	tester := NewLoadTester(TesterParams{URL: server.URL, APIKey: "key", APISecret: "secret", Room: "room", IdentityPrefix: "sub"})
+	tester.params.Sequence = 3
+	f := newFuzzer(tester)
+	f.run(context.Background())
+
+	require.Len(t, f.outcomes, len(fuzzCases))
+	closed := make(map[string]bool)
+	for _, o := range f.outcomes {
+		require.Empty(t, o.Error, o.Case)
+		closed[o.Case] = o.Closed
+	}
+	require.True(t, closed["garbage"])
+	require.True(t, closed["truncated"])
+	require.True(t, closed["invalid-json"])
+	require.False(t, closed["unknown-track-mute"])
+
+	// a new connection, with a new identity, after each one the server closed
+	lock.Lock()
+	defer lock.Unlock()
+	require.Equal(t, []string{"sub_3_fuzz-0", "sub_3_fuzz-1", "sub_3_fuzz-2", "sub_3_fuzz-4"}, identities)
+}
+
+func TestFuzzSession(t *testing.T) {
+	var none *fuzzSession
+	require.Nil(t, none.finish())
+	require.Nil(t, startFuzzing(context.Background(), Params{}, nil))
+
+	bystander := NewLoadTester(TesterParams{Room: "room", name: "Sub 1"})
+	f := newFuzzer(NewLoadTester(TesterParams{Room: "room"}))
+	f.outcomes = []*FuzzOutcome{
+		{Case: "garbage", Closed: true},
+		{Case: "truncated", Error: "could not connect"},
+	}
+	s := &fuzzSession{fuzzers: []*fuzzer{f}, bystanders: []*LoadTester{bystander}}
+	res := s.finish()
+	require.Equal(t, 1, res.Fuzzers)
+	require.Equal(t, &FuzzCaseResults{Case: "garbage", Sent: 1, Closed: 1}, res.Cases[0])
+	require.Equal(t, &FuzzCaseResults{Case: "truncated", Sent: 1, Errors: 1}, res.Cases[1])
+	require.Equal(t, []string{"Sub 1"}, res.BystandersDisconnected)
+	require.False(t, res.passed())
+}
@@ -0,0 +1,91 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+// hiddenViolation is a hidden participant that was visible to another tester
+type hiddenViolation struct {
+	room     string
+	observer string
+	hidden   string
+}
+
+type hiddenResults struct {
+	hiddenCount int
+	violations  []*hiddenViolation
+}
+
+// checkHidden verifies that no tester has seen a hidden tester in the same room
+func checkHidden(testers []*LoadTester) *hiddenResults {
+	hidden := make(map[string]map[string]bool)
+	res := &hiddenResults{}
+	for _, t := range testers {
+		if !t.params.Hidden {
+			continue
+		}
+		if hidden[t.params.Room] == nil {
+			hidden[t.params.Room] = make(map[string]bool)
+		}
+		hidden[t.params.Room][t.identity()] = true
+		res.hiddenCount++
+	}
+	if res.hiddenCount == 0 {
+		return nil
+	}
+
+	for _, t := range testers {
+		t.lock.Lock()
+		for identity := range t.seenParticipants {
+			if hidden[t.params.Room][identity] {
+				res.violations = append(res.violations, &hiddenViolation{
+					room:     t.params.Room,
+					observer: t.identity(),
+					hidden:   identity,
+				})
+			}
+		}
+		t.lock.Unlock()
+	}
+	sort.Slice(res.violations, func(i, j int) bool {
+		if res.violations[i].observer != res.violations[j].observer {
+			return res.violations[i].observer < res.violations[j].observer
+		}
+		return res.violations[i].hidden < res.violations[j].hidden
+	})
+	return res
+}
+
+func printHiddenResults(res *hiddenResults) {
+	if res == nil {
+		return
+	}
+
+	fmt.Printf("\nHidden participants: %d hidden testers, %d violations\n", res.hiddenCount, len(res.violations))
+	if len(res.violations) == 0 {
+		return
+	}
+	table := util.CreateTable().
+		Headers("Room", "Observer", "Hidden Participant")
+	for _, v := range res.violations {
+		table.Row(v.room, v.observer, v.hidden)
+	}
+	fmt.Println(table)
+}
@@ -0,0 +1,174 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"math"
+	"math/bits"
+	"sync"
+	"time"
+)
+
+const (
+	// values below this many microseconds get a bucket each
+	histogramLinear = 128
+	// buckets per doubling above that, so a bucket is within 1/64 of its values
+	histogramSubBuckets = 64
+)
+
+// histogram counts durations in log-linear buckets, like an HDR histogram with two significant
+// digits. It keeps the tail of millions of packets in a few kilobytes, where sampling would lose it.
+type histogram struct {
+	lock   sync.Mutex
+	counts []int64
+	total  int64
+	max    time.Duration
+}
+
+// histogramPercentiles summarize a histogram
+type histogramPercentiles struct {
+	count              int64
+	p50, p90, p95, p99 time.Duration
+	max                time.Duration
+}
+
+func histogramBucket(us int64) int {
+	if us < histogramLinear {
+		return int(us)
+	}
+	// us>>shift is in [histogramSubBuckets, 2*histogramSubBuckets)
+	shift := bits.Len64(uint64(us)) - 7
+	return histogramLinear + (shift-1)*histogramSubBuckets + int(us>>shift) - histogramSubBuckets
+}
+
+// bucketValue is the middle of a bucket, in microseconds
+func bucketValue(i int) int64 {
+	if i < histogramLinear {
+		return int64(i)
+	}
+	shift := (i-histogramLinear)/histogramSubBuckets + 1
+	m := int64((i-histogramLinear)%histogramSubBuckets + histogramSubBuckets)
+	return m<<shift + (int64(1)<<shift)/2
+}
+
+func (h *histogram) record(d time.Duration) {
+	d = max(d, 0)
+	i := histogramBucket(d.Microseconds())
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if i >= len(h.counts) {
+		h.counts = append(h.counts, make([]int64, i+1-len(h.counts))...)
+	}
+	h.counts[i]++
+	h.total++
+	h.max = max(h.max, d)
+}
+
+func (h *histogram) reset() {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h.counts = nil
+	h.total = 0
+	h.max = 0
+}
+
+// merge adds the counts of o to h
+func (h *histogram) merge(o *histogram) {
+	if o == nil || o == h {
+		return
+	}
+	o.lock.Lock()
+	counts := append([]int64(nil), o.counts...)
+	total, maxValue := o.total, o.max
+	o.lock.Unlock()
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if len(counts) > len(h.counts) {
+		h.counts = append(h.counts, make([]int64, len(counts)-len(h.counts))...)
+	}
+	for i, c := range counts {
+		h.counts[i] += c
+	}
+	h.total += total
+	h.max = max(h.max, maxValue)
+}
+
+// quantile returns the nearest-rank q-th quantile, 0 < q <= 1, called with h.lock held
+func (h *histogram) quantile(q float64) time.Duration {
+	if h.total == 0 {
+		return 0
+	}
+	rank := max(int64(math.Ceil(q*float64(h.total))), 1)
+	var seen int64
+	for i, c := range h.counts {
+		seen += c
+		if seen >= rank {
+			// the bucket middle can lie beyond the largest value in it
+			return min(time.Duration(bucketValue(i))*time.Microsecond, h.max)
+		}
+	}
+	return h.max
+}
+
+func (h *histogram) percentiles() histogramPercentiles {
+	if h == nil {
+		return histogramPercentiles{}
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	return histogramPercentiles{
+		count: h.total,
+		p50:   h.quantile(0.50),
+		p90:   h.quantile(0.90),
+		p95:   h.quantile(0.95),
+		p99:   h.quantile(0.99),
+		max:   h.max,
+	}
+}
+
+// jitterMeter measures the transit time variation of each packet of a stream against the one
+// before it, the D(i-1,i) of RFC 3550 that its smoothed interarrival jitter is made of
+type jitterMeter struct {
+	clockRate   uint32
+	valid       bool
+	lastArrival time.Time
+	lastRTP     uint32
+}
+
+// maxJitterSample is beyond any real jitter, a larger difference means the stream jumped,
+// like after a resubscribe
+const maxJitterSample = 10 * time.Second
+
+// next returns the packet's transit time variation, and false for the first packet
+func (m *jitterMeter) next(arrival time.Time, rtpTimestamp uint32) (time.Duration, bool) {
+	if m.clockRate == 0 {
+		return 0, false
+	}
+	last, lastRTP, valid := m.lastArrival, m.lastRTP, m.valid
+	m.lastArrival, m.lastRTP, m.valid = arrival, rtpTimestamp, true
+	if !valid {
+		return 0, false
+	}
+	sent := time.Duration(int64(int32(rtpTimestamp-lastRTP)) * int64(time.Second) / int64(m.clockRate))
+	d := arrival.Sub(last) - sent
+	if d < 0 {
+		d = -d
+	}
+	if d > maxJitterSample {
+		return 0, false
+	}
+	return d, true
+}
@@ -0,0 +1,108 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistogramBuckets(t *testing.T) {
+	last := -1
+	for us := int64(0); us < 10_000_000; us = us*101/100 + 1 {
+		i := histogramBucket(us)
+		require.GreaterOrEqual(t, i, last)
+		last = i
+		// a bucket's value is within 1/64 of every value in it
+		require.InDelta(t, us, bucketValue(i), float64(us)/64+1, "value %dus", us)
+	}
+}
+
+func TestHistogramPercentiles(t *testing.T) {
+	h := &histogram{}
+	for ms := 1; ms <= 1000; ms++ {
+		h.record(time.Duration(ms) * time.Millisecond)
+	}
+	p := h.percentiles()
+	require.EqualValues(t, 1000, p.count)
+	require.InEpsilon(t, 500*time.Millisecond, p.p50, 0.02)
+	require.InEpsilon(t, 900*time.Millisecond, p.p90, 0.02)
+	require.InEpsilon(t, 990*time.Millisecond, p.p99, 0.02)
+	require.Equal(t, time.Second, p.max)
+
+	tail := &histogram{}
+	tail.record(5 * time.Second)
+	h.merge(tail)
+	p = h.percentiles()
+	require.EqualValues(t, 1001, p.count)
+	require.Equal(t, 5*time.Second, p.max)
+	require.InEpsilon(t, 990*time.Millisecond, p.p99, 0.02)
+
+	require.Equal(t, histogramPercentiles{}, (&histogram{}).percentiles())
+}
+
+func TestJitterMeter(t *testing.T) {
+	m := &jitterMeter{clockRate: 48000}
+	start := time.Now()
+	_, ok := m.next(start, 1000)
+	require.False(t, ok)
+
+	// 20ms audio frames arriving on time
+	d, ok := m.next(start.Add(20*time.Millisecond), 1000+960)
+	require.True(t, ok)
+	require.Zero(t, d)
+	// arriving 15ms late, then on time again
+	d, _ = m.next(start.Add(55*time.Millisecond), 1000+2*960)
+	require.Equal(t, 15*time.Millisecond, d)
+	d, _ = m.next(start.Add(60*time.Millisecond), 1000+3*960)
+	require.Equal(t, 15*time.Millisecond, d)
+
+	// timestamps wrap around
+	m = &jitterMeter{clockRate: 90000}
+	m.next(start, 0xffffffff-1500)
+	d, ok = m.next(start.Add(time.Second/30), 1500)
+	require.True(t, ok)
+	require.Less(t, d, time.Millisecond)
+
+	// a jump in the stream isn't jitter
+	_, ok = m.next(start.Add(time.Minute), 3000)
+	require.False(t, ok)
+}
+
+func TestTailResults(t *testing.T) {
+	ts := &trackStats{trackID: "TR_1"}
+	for i := 1; i <= 100; i++ {
+		ts.jitter.record(time.Duration(i) * time.Millisecond)
+	}
+	latency := &histogram{}
+	latency.record(80 * time.Millisecond)
+	latency.record(300 * time.Millisecond)
+	stats := map[string]*testerStats{
+		"Sub 0": {trackStats: map[string]*trackStats{"TR_1": ts}, latencyHist: latency},
+		"Sub 1": {trackStats: map[string]*trackStats{}, latencies: []time.Duration{40 * time.Millisecond}},
+	}
+	results := getResults(stats, []string{"Sub 0", "Sub 1"})
+	sub := results.Testers[0]
+	require.InEpsilon(t, 99, sub.JitterP99Ms, 0.02)
+	require.Equal(t, 100.0, sub.JitterMaxMs)
+	require.Equal(t, 300.0, sub.LatencyMaxMs)
+	// testers without a histogram fall back to their samples
+	require.Equal(t, 40.0, results.Testers[1].LatencyMaxMs)
+	require.Equal(t, 300.0, results.Total.LatencyMaxMs)
+	require.InEpsilon(t, 80, results.Total.LatencyP50Ms, 0.02)
+	require.Equal(t, 100.0, results.Total.JitterMaxMs)
+}
@@ -0,0 +1,142 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+var historyIDPattern = regexp.MustCompile(`^[0-9]{8}-[0-9]{6}-[a-zA-Z]+$`)
+
+// HistoryRun is a finished load test kept in the results history
+type HistoryRun struct {
+	ID          string    `json:"id"`
+	FinishedAt  time.Time `json:"finishedAt"`
+	Description string    `json:"description"`
+	Results     *Results  `json:"results"`
+}
+
+// History keeps the results of past runs as one JSON file per run in Dir
+type History struct {
+	Dir string
+}
+
+func (h *History) Save(run *HistoryRun) error {
+	if err := os.MkdirAll(h.Dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(h.Dir, run.ID+".json"), data, 0644)
+}
+
+// List returns all runs in the history, most recent first
+func (h *History) List() ([]*HistoryRun, error) {
+	entries, err := os.ReadDir(h.Dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	var runs []*HistoryRun
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".json")
+		if !ok || !historyIDPattern.MatchString(id) {
+			continue
+		}
+		run, err := h.Load(id)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	slices.SortFunc(runs, func(a, b *HistoryRun) int {
+		return b.FinishedAt.Compare(a.FinishedAt)
+	})
+	return runs, nil
+}
+
+func (h *History) Load(id string) (*HistoryRun, error) {
+	if !historyIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("invalid run id %q", id)
+	}
+	data, err := os.ReadFile(filepath.Join(h.Dir, id+".json"))
+	if err != nil {
+		return nil, err
+	}
+	run := &HistoryRun{}
+	if err = json.Unmarshal(data, run); err != nil {
+		return nil, fmt.Errorf("could not read run %s: %w", id, err)
+	}
+	return run, nil
+}
+
+// HistorySink adds the results to the history, to compare them to other runs with `lk perf serve`
+type HistorySink struct {
+	History     *History
+	Description string
+}
+
+func (s *HistorySink) WriteStats(_ context.Context, results *Results) error {
+	now := time.Now()
+	return s.History.Save(&HistoryRun{
+		ID:          now.Format("20060102-150405") + "-" + randStringRunes(4),
+		FinishedAt:  now,
+		Description: s.Description,
+		Results:     results,
+	})
+}
+
+type metricDiff struct {
+	Metric  string  `json:"metric"`
+	Help    string  `json:"help"`
+	Base    float64 `json:"base"`
+	Compare float64 `json:"compare"`
+	Delta   float64 `json:"delta"`
+	// relative change from Base, omitted when Base is zero
+	Change *float64 `json:"change,omitempty"`
+}
+
+// diffRuns compares the totals of two runs, metric by metric
+func diffRuns(base, compare *HistoryRun) []*metricDiff {
+	var diffs []*metricDiff
+	for _, m := range resultMetrics {
+		d := &metricDiff{Metric: m.name, Help: m.help}
+		if base.Results != nil && base.Results.Total != nil {
+			d.Base = m.value(base.Results.Total)
+		}
+		if compare.Results != nil && compare.Results.Total != nil {
+			d.Compare = m.value(compare.Results.Total)
+		}
+		d.Delta = d.Compare - d.Base
+		if d.Base != 0 {
+			change := d.Delta / d.Base
+			d.Change = &change
+		}
+		diffs = append(diffs, d)
+	}
+	return diffs
+}
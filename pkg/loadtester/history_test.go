@@ -0,0 +1,90 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory(t *testing.T) {
+	h := &History{Dir: t.TempDir()}
+	older := &HistoryRun{
+		ID:         "20250101-120000-abcd",
+		FinishedAt: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC),
+		Results:    &Results{Total: &TesterResults{Name: "Total", Bitrate: 1000, Errors: 1}},
+	}
+	require.NoError(t, h.Save(older))
+	sink := &HistorySink{History: h, Description: "2 video publishers"}
+	require.NoError(t, sink.WriteStats(context.Background(), &Results{Total: &TesterResults{Name: "Total", Bitrate: 1500}}))
+
+	runs, err := h.List()
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	require.Equal(t, "2 video publishers", runs[0].Description)
+	require.Equal(t, older.ID, runs[1].ID)
+
+	_, err = h.Load("../secrets")
+	require.Error(t, err)
+
+	diffs := diffRuns(runs[1], runs[0])
+	for _, d := range diffs {
+		switch d.Metric {
+		case "bitrate_bps":
+			require.Equal(t, 500.0, d.Delta)
+			require.InDelta(t, 0.5, *d.Change, 1e-9)
+		case "errors":
+			require.Equal(t, -1.0, d.Delta)
+		case "tracks":
+			require.Nil(t, d.Change)
+		}
+	}
+}
+
+func TestHistoryHandler(t *testing.T) {
+	h := &History{Dir: t.TempDir()}
+	require.NoError(t, h.Save(&HistoryRun{
+		ID:         "20250101-120000-abcd",
+		FinishedAt: time.Now(),
+		Results:    &Results{Testers: []*TesterResults{{Name: "Sub 0"}}, Total: &TesterResults{Name: "Total"}},
+	}))
+	handler := NewHistoryHandler(h)
+	get := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := get("/")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Contains(t, w.Body.String(), "Load test history")
+
+	w = get("/api/runs")
+	require.Equal(t, http.StatusOK, w.Code)
+	var summaries []*runSummary
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &summaries))
+	require.Len(t, summaries, 1)
+	require.Equal(t, 1, summaries[0].Testers)
+
+	require.Equal(t, http.StatusOK, get("/api/runs/20250101-120000-abcd").Code)
+	require.Equal(t, http.StatusNotFound, get("/api/runs/20250101-120000-efgh").Code)
+	require.Equal(t, http.StatusOK, get("/api/diff?base=20250101-120000-abcd&compare=20250101-120000-abcd").Code)
+}
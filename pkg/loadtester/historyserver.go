@@ -0,0 +1,97 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"time"
+)
+
+//go:embed history.html
+var historyPage []byte
+
+// runSummary is a history entry without per-tester results, as listed by the UI
+type runSummary struct {
+	ID          string         `json:"id"`
+	FinishedAt  time.Time      `json:"finishedAt"`
+	Description string         `json:"description"`
+	Total       *TesterResults `json:"total,omitempty"`
+	Testers     int            `json:"testers"`
+}
+
+// NewHistoryHandler serves a web UI for browsing, charting and comparing the runs in h
+func NewHistoryHandler(h *History) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(historyPage)
+	})
+	mux.HandleFunc("GET /api/runs", func(w http.ResponseWriter, r *http.Request) {
+		runs, err := h.List()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		summaries := make([]*runSummary, 0, len(runs))
+		for _, run := range runs {
+			s := &runSummary{ID: run.ID, FinishedAt: run.FinishedAt, Description: run.Description}
+			if run.Results != nil {
+				s.Total = run.Results.Total
+				s.Testers = len(run.Results.Testers)
+			}
+			summaries = append(summaries, s)
+		}
+		writeJSON(w, summaries)
+	})
+	mux.HandleFunc("GET /api/runs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		run, ok := loadRun(w, h, r.PathValue("id"))
+		if ok {
+			writeJSON(w, run)
+		}
+	})
+	mux.HandleFunc("GET /api/diff", func(w http.ResponseWriter, r *http.Request) {
+		base, ok := loadRun(w, h, r.URL.Query().Get("base"))
+		if !ok {
+			return
+		}
+		compare, ok := loadRun(w, h, r.URL.Query().Get("compare"))
+		if !ok {
+			return
+		}
+		writeJSON(w, diffRuns(base, compare))
+	})
+	return mux
+}
+
+func loadRun(w http.ResponseWriter, h *History, id string) (*HistoryRun, bool) {
+	run, err := h.Load(id)
+	if errors.Is(err, os.ErrNotExist) {
+		http.Error(w, "run not found", http.StatusNotFound)
+		return nil, false
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	return run, true
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
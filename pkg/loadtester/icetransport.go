@@ -0,0 +1,70 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pion/webrtc/v4"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+// ParseICETransport parses the candidates testers may connect over, "all" or "relay" for TURN only
+func ParseICETransport(str string) (webrtc.ICETransportPolicy, error) {
+	switch s := strings.ToLower(strings.TrimSpace(str)); s {
+	case "", webrtc.ICETransportPolicyAll.String():
+		return webrtc.ICETransportPolicyAll, nil
+	case webrtc.ICETransportPolicyRelay.String():
+		return webrtc.ICETransportPolicyRelay, nil
+	default:
+		return webrtc.ICETransportPolicyAll, fmt.Errorf("unsupported ICE transport %q, expected \"all\" or \"relay\"", str)
+	}
+}
+
+// NewTURNServer returns the TURN server testers use instead of the ones the server hands out
+func NewTURNServer(urls []string, username, credential string) (*livekit.ICEServer, error) {
+	for _, u := range urls {
+		if !strings.HasPrefix(u, "turn:") && !strings.HasPrefix(u, "turns:") {
+			return nil, fmt.Errorf("invalid TURN URL %q, expected turn: or turns:", u)
+		}
+	}
+	if username == "" || credential == "" {
+		return nil, fmt.Errorf("TURN servers need a username and credential")
+	}
+	return &livekit.ICEServer{
+		Urls:       urls,
+		Username:   username,
+		Credential: credential,
+	}, nil
+}
+
+// replaceICEServers sets the tester's TURN servers on the join and reconnect responses, returning
+// true when it did
+func (t *LoadTester) replaceICEServers(res *livekit.SignalResponse) bool {
+	if len(t.params.ICEServers) == 0 {
+		return false
+	}
+	if join := res.GetJoin(); join != nil {
+		join.IceServers = t.params.ICEServers
+		return true
+	}
+	if reconnect := res.GetReconnect(); reconnect != nil {
+		reconnect.IceServers = t.params.ICEServers
+		return true
+	}
+	return false
+}
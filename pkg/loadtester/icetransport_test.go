@@ -0,0 +1,74 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"testing"
+
+	"github.com/pion/webrtc/v4"
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+func TestParseICETransport(t *testing.T) {
+	for str, expected := range map[string]webrtc.ICETransportPolicy{
+		"":      webrtc.ICETransportPolicyAll,
+		"all":   webrtc.ICETransportPolicyAll,
+		"relay": webrtc.ICETransportPolicyRelay,
+		"Relay": webrtc.ICETransportPolicyRelay,
+	} {
+		policy, err := ParseICETransport(str)
+		require.NoError(t, err, str)
+		require.Equal(t, expected, policy, str)
+	}
+	_, err := ParseICETransport("host")
+	require.Error(t, err)
+}
+
+func TestNewTURNServer(t *testing.T) {
+	turn, err := NewTURNServer([]string{"turn:relay:3478?transport=udp", "turns:relay:5349"}, "user", "secret")
+	require.NoError(t, err)
+	require.Len(t, turn.Urls, 2)
+	require.Equal(t, "user", turn.Username)
+	require.Equal(t, "secret", turn.Credential)
+
+	_, err = NewTURNServer([]string{"stun:relay:3478"}, "user", "secret")
+	require.Error(t, err)
+	_, err = NewTURNServer([]string{"turn:relay:3478"}, "user", "")
+	require.Error(t, err)
+}
+
+func TestReplaceICEServers(t *testing.T) {
+	turn := &livekit.ICEServer{Urls: []string{"turn:relay:3478"}, Username: "user", Credential: "secret"}
+	served := []*livekit.ICEServer{{Urls: []string{"turn:cloud:3478"}}}
+
+	tester := &LoadTester{}
+	res := &livekit.SignalResponse{Message: &livekit.SignalResponse_Join{Join: &livekit.JoinResponse{IceServers: served}}}
+	require.False(t, tester.replaceICEServers(res))
+	require.Equal(t, served, res.GetJoin().IceServers)
+
+	tester.params.ICEServers = []*livekit.ICEServer{turn}
+	require.True(t, tester.replaceICEServers(res))
+	require.Equal(t, tester.params.ICEServers, res.GetJoin().IceServers)
+
+	// reconnecting hands out the ICE servers again
+	res = &livekit.SignalResponse{Message: &livekit.SignalResponse_Reconnect{Reconnect: &livekit.ReconnectResponse{IceServers: served}}}
+	require.True(t, tester.replaceICEServers(res))
+	require.Equal(t, tester.params.ICEServers, res.GetReconnect().IceServers)
+
+	res = &livekit.SignalResponse{Message: &livekit.SignalResponse_Leave{Leave: &livekit.LeaveRequest{}}}
+	require.False(t, tester.replaceICEServers(res))
+}
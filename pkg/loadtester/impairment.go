@@ -0,0 +1,229 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pion/interceptor"
+	"github.com/pion/rtp"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// received packets waiting out their jitter delay, beyond this the stream stalls
+	impairmentQueueSize = 1024
+	impairmentMTU       = 1500
+)
+
+// Impairment degrades the RTP traffic of a tester in both directions, like a poor network
+type Impairment struct {
+	// fraction of packets to drop
+	PacketLoss float64
+	// packets are delayed by Latency, plus a random time up to Jitter which also reorders them
+	Latency time.Duration
+	Jitter  time.Duration
+	// bandwidth in bps available in each direction, 0 for no limit
+	BandwidthCap int64
+}
+
+func (i Impairment) enabled() bool {
+	return i.PacketLoss > 0 || i.Latency > 0 || i.Jitter > 0 || i.BandwidthCap > 0
+}
+
+func (i Impairment) String() string {
+	var parts []string
+	if i.PacketLoss > 0 {
+		parts = append(parts, fmt.Sprintf("%.1f%% loss", i.PacketLoss*100))
+	}
+	if i.Latency > 0 {
+		parts = append(parts, fmt.Sprintf("%s latency", i.Latency))
+	}
+	if i.Jitter > 0 {
+		parts = append(parts, fmt.Sprintf("%s jitter", i.Jitter))
+	}
+	if i.BandwidthCap > 0 {
+		parts = append(parts, formatBitrate(i.BandwidthCap/8, time.Second)+" cap")
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (i Impairment) NewInterceptor(_ string) (interceptor.Interceptor, error) {
+	return &impairmentInterceptor{
+		Impairment: i,
+		uplink:     i.newLimiter(),
+		downlink:   i.newLimiter(),
+		done:       make(chan struct{}),
+	}, nil
+}
+
+func (i Impairment) newLimiter() *rate.Limiter {
+	if i.BandwidthCap <= 0 {
+		return nil
+	}
+	bytesPerSecond := float64(i.BandwidthCap) / 8
+	burst := max(int(bytesPerSecond*downlinkCapBurst.Seconds()), impairmentMTU)
+	return rate.NewLimiter(rate.Limit(bytesPerSecond), burst)
+}
+
+type impairmentInterceptor struct {
+	interceptor.NoOp
+	Impairment
+	uplink   *rate.Limiter
+	downlink *rate.Limiter
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type impairedPacket struct {
+	data []byte
+	attr interceptor.Attributes
+	err  error
+}
+
+func (i *impairmentInterceptor) drop(limiter *rate.Limiter, size int) bool {
+	if i.PacketLoss > 0 && rand.Float64() < i.PacketLoss {
+		return true
+	}
+	return limiter != nil && !limiter.AllowN(time.Now(), size)
+}
+
+func (i *impairmentInterceptor) delay() time.Duration {
+	return randomDelay(i.Latency, i.Jitter)
+}
+
+// randomDelay is latency plus a random time up to jitter
+func randomDelay(latency, jitter time.Duration) time.Duration {
+	if jitter <= 0 {
+		return latency
+	}
+	return latency + time.Duration(rand.Int63n(int64(jitter)))
+}
+
+func (i *impairmentInterceptor) BindLocalStream(_ *interceptor.StreamInfo, writer interceptor.RTPWriter) interceptor.RTPWriter {
+	return interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, a interceptor.Attributes) (int, error) {
+		size := header.MarshalSize() + len(payload)
+		if i.drop(i.uplink, size) {
+			return size, nil
+		}
+		d := i.delay()
+		if d == 0 {
+			return writer.Write(header, payload, a)
+		}
+
+		h := header.Clone()
+		p := append([]byte(nil), payload...)
+		time.AfterFunc(d, func() {
+			select {
+			case <-i.done:
+			default:
+				_, _ = writer.Write(&h, p, a)
+			}
+		})
+		return size, nil
+	})
+}
+
+func (i *impairmentInterceptor) BindRemoteStream(_ *interceptor.StreamInfo, reader interceptor.RTPReader) interceptor.RTPReader {
+	if i.Latency <= 0 && i.Jitter <= 0 {
+		return interceptor.RTPReaderFunc(func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+			for {
+				n, attr, err := reader.Read(b, a)
+				if err != nil || !i.drop(i.downlink, n) {
+					return n, attr, err
+				}
+			}
+		})
+	}
+
+	// delayed packets are read ahead and handed over once their delay passes
+	packets := make(chan impairedPacket, impairmentQueueSize)
+	go func() {
+		for {
+			buf := make([]byte, impairmentMTU)
+			n, attr, err := reader.Read(buf, nil)
+			if err != nil {
+				select {
+				case packets <- impairedPacket{err: err}:
+				case <-i.done:
+				}
+				return
+			}
+			if i.drop(i.downlink, n) {
+				continue
+			}
+			p := impairedPacket{data: buf[:n], attr: attr}
+			time.AfterFunc(i.delay(), func() {
+				select {
+				case packets <- p:
+				case <-i.done:
+				}
+			})
+		}
+	}()
+
+	return interceptor.RTPReaderFunc(func(b []byte, _ interceptor.Attributes) (int, interceptor.Attributes, error) {
+		select {
+		case p := <-packets:
+			if p.err != nil {
+				return 0, nil, p.err
+			}
+			return copy(b, p.data), p.attr, nil
+		case <-i.done:
+			return 0, nil, io.EOF
+		}
+	})
+}
+
+func (i *impairmentInterceptor) Close() error {
+	i.closeOnce.Do(func() {
+		close(i.done)
+	})
+	return nil
+}
+
+// SignalImpairment delays the signaling of a tester over its WebSocket, independently of the media,
+// as networks often degrade the two differently. Messages keep their order, as on a TCP connection.
+type SignalImpairment struct {
+	// messages in both directions, and the connection handshake, are delayed by Latency
+	// plus a random time up to Jitter
+	Latency time.Duration
+	Jitter  time.Duration
+}
+
+func (i SignalImpairment) enabled() bool {
+	return i.Latency > 0 || i.Jitter > 0
+}
+
+func (i SignalImpairment) String() string {
+	var parts []string
+	if i.Latency > 0 {
+		parts = append(parts, fmt.Sprintf("%s signal latency", i.Latency))
+	}
+	if i.Jitter > 0 {
+		parts = append(parts, fmt.Sprintf("%s signal jitter", i.Jitter))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (i SignalImpairment) delay() time.Duration {
+	return randomDelay(i.Latency, i.Jitter)
+}
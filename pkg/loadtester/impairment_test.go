@@ -0,0 +1,87 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/pion/interceptor"
+	"github.com/pion/rtp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImpairmentDropsWrites(t *testing.T) {
+	i, err := Impairment{PacketLoss: 1}.NewInterceptor("")
+	require.NoError(t, err)
+	defer i.Close()
+
+	written := 0
+	writer := i.BindLocalStream(&interceptor.StreamInfo{}, interceptor.RTPWriterFunc(
+		func(_ *rtp.Header, _ []byte, _ interceptor.Attributes) (int, error) {
+			written++
+			return 0, nil
+		}))
+	for j := 0; j < 10; j++ {
+		_, err = writer.Write(&rtp.Header{SequenceNumber: uint16(j)}, []byte{1, 2, 3}, nil)
+		require.NoError(t, err)
+	}
+	require.Zero(t, written)
+}
+
+func TestImpairmentDelaysReads(t *testing.T) {
+	i, err := Impairment{Jitter: 20 * time.Millisecond}.NewInterceptor("")
+	require.NoError(t, err)
+	defer i.Close()
+
+	const count = 50
+	sent := 0
+	reader := i.BindRemoteStream(&interceptor.StreamInfo{}, interceptor.RTPReaderFunc(
+		func(b []byte, a interceptor.Attributes) (int, interceptor.Attributes, error) {
+			if sent == count {
+				time.Sleep(50 * time.Millisecond)
+				return 0, nil, io.EOF
+			}
+			sent++
+			return copy(b, []byte{byte(sent)}), a, nil
+		}))
+
+	received := 0
+	buf := make([]byte, impairmentMTU)
+	for {
+		_, _, err = reader.Read(buf, nil)
+		if err != nil {
+			require.ErrorIs(t, err, io.EOF)
+			break
+		}
+		received++
+	}
+	require.Equal(t, count, received)
+}
+
+func TestImpairmentLatency(t *testing.T) {
+	for j := 0; j < 10; j++ {
+		d := randomDelay(30*time.Millisecond, 10*time.Millisecond)
+		require.GreaterOrEqual(t, d, 30*time.Millisecond)
+		require.Less(t, d, 40*time.Millisecond)
+	}
+	require.Equal(t, 30*time.Millisecond, randomDelay(30*time.Millisecond, 0))
+
+	require.Equal(t, "30ms latency", Impairment{Latency: 30 * time.Millisecond}.String())
+	require.Equal(t, "200ms signal latency, 50ms signal jitter",
+		SignalImpairment{Latency: 200 * time.Millisecond, Jitter: 50 * time.Millisecond}.String())
+	require.False(t, SignalImpairment{}.enabled())
+}
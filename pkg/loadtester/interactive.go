@@ -0,0 +1,153 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+// interactivity records the milestones of a subscriber's first join that make the call usable:
+// connected, hearing audio, and seeing the active speaker's video
+type interactivity struct {
+	lock         sync.Mutex
+	joinedAt     time.Time
+	connectedAt  time.Time
+	firstAudioAt time.Time
+	firstVideoAt time.Time
+	// first video frame by publisher identity
+	videoAt map[string]time.Time
+	// active speakers in the order they first spoke
+	speakers []string
+}
+
+func (i *interactivity) join(at time.Time) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if i.joinedAt.IsZero() {
+		i.joinedAt = at
+	}
+}
+
+func (i *interactivity) connected(at time.Time) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if i.connectedAt.IsZero() {
+		i.connectedAt = at
+	}
+}
+
+// frame records the first frame of a track from publisher
+func (i *interactivity) frame(kind lksdk.TrackKind, publisher string, at time.Time) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if kind == lksdk.TrackKindAudio {
+		if i.firstAudioAt.IsZero() {
+			i.firstAudioAt = at
+		}
+		return
+	}
+	if i.firstVideoAt.IsZero() {
+		i.firstVideoAt = at
+	}
+	if i.videoAt == nil {
+		i.videoAt = make(map[string]time.Time)
+	}
+	if _, ok := i.videoAt[publisher]; !ok {
+		i.videoAt[publisher] = at
+	}
+}
+
+// speaking records the identities of remote active speakers
+func (i *interactivity) speaking(identities []string) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	for _, identity := range identities {
+		if !slices.Contains(i.speakers, identity) {
+			i.speakers = append(i.speakers, identity)
+		}
+	}
+}
+
+// timeToInteractive returns the time from joining until the call was usable, and false if it never was.
+// The video counted is that of the first active speaker with video, or any video before anyone spoke.
+func (i *interactivity) timeToInteractive(needAudio, needVideo bool) (time.Duration, bool) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if i.joinedAt.IsZero() || i.connectedAt.IsZero() {
+		return 0, false
+	}
+	usableAt := i.connectedAt
+	if needAudio {
+		if i.firstAudioAt.IsZero() {
+			return 0, false
+		}
+		usableAt = later(usableAt, i.firstAudioAt)
+	}
+	if needVideo {
+		videoAt := i.firstVideoAt
+		for _, speaker := range i.speakers {
+			if at, ok := i.videoAt[speaker]; ok {
+				videoAt = at
+				break
+			}
+		}
+		if videoAt.IsZero() {
+			return 0, false
+		}
+		usableAt = later(usableAt, videoAt)
+	}
+	return usableAt.Sub(i.joinedAt), true
+}
+
+func later(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+func printInteractivityStats(stats map[string]*testerStats, names []string) {
+	var samples []time.Duration
+	measured := 0
+	for _, name := range names {
+		s := stats[name]
+		if !s.interactiveMeasured {
+			continue
+		}
+		measured++
+		if s.timeToInteractive > 0 {
+			samples = append(samples, s.timeToInteractive)
+		}
+	}
+	if measured == 0 {
+		return
+	}
+	row := []string{fmt.Sprint(measured), fmt.Sprintf("%d/%d", len(samples), measured), "-", "-", "-"}
+	if len(samples) > 0 {
+		copy(row[2:], formatLatencies(samples))
+	}
+	table := util.CreateTable().
+		Headers("Subscribers", "Interactive", "p50", "p95", "p99").
+		Row(row...)
+	fmt.Println("\nTime to interactive (connected, first audio and active speaker video):")
+	fmt.Println(table)
+}
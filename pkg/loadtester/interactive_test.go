@@ -0,0 +1,62 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+func TestTimeToInteractive(t *testing.T) {
+	start := time.Now()
+	at := func(ms int) time.Time {
+		return start.Add(time.Duration(ms) * time.Millisecond)
+	}
+
+	i := &interactivity{}
+	_, ok := i.timeToInteractive(false, false)
+	require.False(t, ok)
+
+	i.join(start)
+	i.connected(at(300))
+	i.connected(at(900))
+	tti, ok := i.timeToInteractive(false, false)
+	require.True(t, ok)
+	require.Equal(t, 300*time.Millisecond, tti)
+
+	_, ok = i.timeToInteractive(true, false)
+	require.False(t, ok)
+	i.frame(lksdk.TrackKindAudio, "pub-1", at(500))
+	i.frame(lksdk.TrackKindAudio, "pub-2", at(400))
+	tti, _ = i.timeToInteractive(true, false)
+	require.Equal(t, 500*time.Millisecond, tti)
+
+	// before anyone speaks, any video counts
+	i.frame(lksdk.TrackKindVideo, "pub-2", at(600))
+	i.frame(lksdk.TrackKindVideo, "pub-1", at(800))
+	tti, _ = i.timeToInteractive(true, true)
+	require.Equal(t, 600*time.Millisecond, tti)
+
+	// then the video of the first speaker with video
+	i.speaking([]string{"audio-only", "pub-1"})
+	i.speaking([]string{"pub-2", "audio-only"})
+	require.Equal(t, []string{"audio-only", "pub-1", "pub-2"}, i.speakers)
+	tti, _ = i.timeToInteractive(true, true)
+	require.Equal(t, 800*time.Millisecond, tti)
+}
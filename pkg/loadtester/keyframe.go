@@ -0,0 +1,81 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"strings"
+
+	"github.com/pion/rtp/codecs"
+	"github.com/pion/webrtc/v4"
+)
+
+const (
+	h264NalIDR   = 5
+	h264NalSPS   = 7
+	h264NalStapA = 24
+	h264NalFuA   = 28
+
+	av1AggregationN = 0x08
+)
+
+// isKeyframe reports whether an RTP payload starts a keyframe
+func isKeyframe(mimeType string, payload []byte) bool {
+	if len(payload) == 0 {
+		return false
+	}
+	switch {
+	case strings.EqualFold(mimeType, webrtc.MimeTypeVP8):
+		vp8 := &codecs.VP8Packet{}
+		frame, err := vp8.Unmarshal(payload)
+		if err != nil || len(frame) == 0 {
+			return false
+		}
+		return vp8.S == 1 && vp8.PID == 0 && frame[0]&0x01 == 0
+	case strings.EqualFold(mimeType, webrtc.MimeTypeVP9):
+		vp9 := &codecs.VP9Packet{}
+		if _, err := vp9.Unmarshal(payload); err != nil {
+			return false
+		}
+		return vp9.B && !vp9.P
+	case strings.EqualFold(mimeType, webrtc.MimeTypeH264):
+		return isH264Keyframe(payload)
+	case strings.EqualFold(mimeType, webrtc.MimeTypeAV1):
+		// aggregation header N bit, set on the first packet of a coded video sequence
+		return payload[0]&av1AggregationN != 0
+	}
+	return false
+}
+
+func isH264Keyframe(payload []byte) bool {
+	switch nalType := payload[0] & 0x1f; nalType {
+	case h264NalIDR, h264NalSPS:
+		return true
+	case h264NalStapA:
+		for i := 1; i+2 < len(payload); {
+			size := int(payload[i])<<8 | int(payload[i+1])
+			if i+2 >= len(payload) || size == 0 {
+				return false
+			}
+			if t := payload[i+2] & 0x1f; t == h264NalIDR || t == h264NalSPS {
+				return true
+			}
+			i += 2 + size
+		}
+	case h264NalFuA:
+		// start bit set on an IDR fragment
+		return len(payload) > 1 && payload[1]&0x80 != 0 && payload[1]&0x1f == h264NalIDR
+	}
+	return false
+}
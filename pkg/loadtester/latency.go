@@ -0,0 +1,173 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/rand"
+	"slices"
+	"strconv"
+	"time"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+const (
+	DefaultLatencyInterval = time.Second
+
+	latencyTopic = "lk.loadtest.latency"
+	// samples kept per subscriber, older ones are replaced at random beyond that
+	maxLatencySamples = 10000
+)
+
+// latencySamples is a uniform random sample of the publisher-to-subscriber latencies a
+// subscriber measured, bounded in size however long the test runs
+type latencySamples struct {
+	count   int64
+	samples []time.Duration
+}
+
+func (s *latencySamples) add(d time.Duration) {
+	s.count++
+	if len(s.samples) < maxLatencySamples {
+		s.samples = append(s.samples, d)
+		return
+	}
+	if i := rand.Int63n(s.count); i < maxLatencySamples {
+		s.samples[i] = d
+	}
+}
+
+// stampLatency sends the current time to the room as a lossy data packet every interval, until
+// the tester stops. Lossy packets travel over the same transport as media, without retransmission.
+func (t *LoadTester) stampLatency(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if !t.IsRunning() {
+			return
+		}
+		payload := make([]byte, 8)
+		binary.BigEndian.PutUint64(payload, uint64(time.Now().UnixNano()))
+		_ = t.room.LocalParticipant.PublishDataPacket(
+			&lksdk.UserDataPacket{Payload: payload, Topic: latencyTopic},
+			lksdk.WithDataPublishReliable(false),
+		)
+	}
+}
+
+func (t *LoadTester) onLatencyStamp(payload []byte) {
+	if len(payload) != 8 {
+		return
+	}
+	// publisher and subscriber clocks are assumed to agree, as they do on a single host
+	sentAt := time.Unix(0, int64(binary.BigEndian.Uint64(payload)))
+	latency := max(time.Since(sentAt), 0)
+
+	t.lock.Lock()
+	t.latencies.add(latency)
+	t.lock.Unlock()
+	t.latencyHist.record(latency)
+}
+
+// percentile returns the nearest-rank p-th percentile, 0 < p <= 1, of sorted samples
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[min(max(i, 0), len(sorted)-1)]
+}
+
+type latencyPercentiles struct {
+	p50, p95, p99 time.Duration
+}
+
+func getLatencyPercentiles(samples []time.Duration) latencyPercentiles {
+	sorted := slices.Clone(samples)
+	slices.Sort(sorted)
+	return latencyPercentiles{
+		p50: percentile(sorted, 0.50),
+		p95: percentile(sorted, 0.95),
+		p99: percentile(sorted, 0.99),
+	}
+}
+
+func printLatencyStats(stats map[string]*testerStats, names []string) {
+	table := util.CreateTable().
+		Headers("Tester", "Samples", "p50", "p95", "p99")
+	var all []time.Duration
+	var total int64
+	for _, name := range names {
+		s := stats[name]
+		if len(s.latencies) == 0 {
+			continue
+		}
+		all = append(all, s.latencies...)
+		total += s.latencyCount
+		table.Row(append([]string{name, fmt.Sprint(s.latencyCount)}, formatLatencies(s.latencies)...)...)
+	}
+	if len(all) == 0 {
+		return
+	}
+	table.Row(append([]string{"Total", fmt.Sprint(total)}, formatLatencies(all)...)...)
+	fmt.Println("\nEnd-to-end latency:")
+	fmt.Println(table)
+}
+
+func formatLatencies(samples []time.Duration) []string {
+	p := getLatencyPercentiles(samples)
+	return []string{
+		p.p50.Round(time.Millisecond).String(),
+		p.p95.Round(time.Millisecond).String(),
+		p.p99.Round(time.Millisecond).String(),
+	}
+}
+
+// printTailResults shows the tail of the latency and jitter each tester saw, which breaks calls
+// long before the averages move
+func printTailResults(results *Results) {
+	formatMs := func(ms float64) string {
+		return strconv.FormatFloat(ms, 'f', 1, 64) + "ms"
+	}
+	table := util.CreateTable().
+		Headers("Tester", "Latency p50", "p90", "p99", "max", "Jitter p50", "p90", "p99", "max")
+	rows := 0
+	for _, tester := range append(results.Testers, results.Total) {
+		if tester.LatencyMaxMs == 0 && tester.JitterMaxMs == 0 {
+			continue
+		}
+		row := []string{tester.Name, " - ", " - ", " - ", " - ", " - ", " - ", " - ", " - "}
+		if tester.LatencyMaxMs > 0 {
+			row[1], row[2], row[3], row[4] = formatMs(tester.LatencyP50Ms), formatMs(tester.LatencyP90Ms),
+				formatMs(tester.LatencyP99Ms), formatMs(tester.LatencyMaxMs)
+		}
+		if tester.JitterMaxMs > 0 {
+			row[5], row[6], row[7], row[8] = formatMs(tester.JitterP50Ms), formatMs(tester.JitterP90Ms),
+				formatMs(tester.JitterP99Ms), formatMs(tester.JitterMaxMs)
+		}
+		table.Row(row...)
+		rows++
+	}
+	if rows == 0 {
+		return
+	}
+	fmt.Println("\nLatency and jitter:")
+	fmt.Println(table)
+}
@@ -0,0 +1,62 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+func TestLatencyPercentiles(t *testing.T) {
+	var samples []time.Duration
+	for i := 100; i > 0; i-- {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+	p := getLatencyPercentiles(samples)
+	require.Equal(t, 50*time.Millisecond, p.p50)
+	require.Equal(t, 95*time.Millisecond, p.p95)
+	require.Equal(t, 99*time.Millisecond, p.p99)
+	// the samples themselves are left in order
+	require.Equal(t, 100*time.Millisecond, samples[0])
+
+	require.Equal(t, latencyPercentiles{}, getLatencyPercentiles(nil))
+}
+
+func TestLatencySamplesBounded(t *testing.T) {
+	s := &latencySamples{}
+	for i := 0; i < 3*maxLatencySamples; i++ {
+		s.add(time.Millisecond)
+	}
+	require.EqualValues(t, 3*maxLatencySamples, s.count)
+	require.Len(t, s.samples, maxLatencySamples)
+}
+
+func TestOnLatencyPacket(t *testing.T) {
+	tester := NewLoadTester(TesterParams{Subscribe: true})
+	payload := make([]byte, 8)
+	binary.BigEndian.PutUint64(payload, uint64(time.Now().Add(-20*time.Millisecond).UnixNano()))
+
+	tester.onDataPacket(&lksdk.UserDataPacket{Payload: payload, Topic: "chat"}, lksdk.DataReceiveParams{})
+	tester.onDataPacket(&lksdk.UserDataPacket{Payload: payload, Topic: latencyTopic}, lksdk.DataReceiveParams{})
+
+	stats := tester.getStats()
+	require.EqualValues(t, 1, stats.latencyCount)
+	require.GreaterOrEqual(t, stats.latencies[0], 20*time.Millisecond)
+}
@@ -15,6 +15,7 @@
 package loadtester
 
 import (
+	"cmp"
 	"context"
 	"fmt"
 	"math/rand"
@@ -27,17 +28,41 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/lipgloss/table"
+	"github.com/livekit/livekit-cli/v2/pkg/provider"
 	"github.com/livekit/livekit-cli/v2/pkg/util"
+	"github.com/livekit/protocol/livekit"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+	"github.com/pion/webrtc/v4"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/sync/syncmap"
-	"golang.org/x/time/rate"
 )
 
 type LoadTest struct {
-	Params     Params
-	trackNames map[string]string
-	lock       sync.Mutex
+	Params           Params
+	trackNames       map[string]string
+	serviceEvents    []*serviceEvent
+	duplicates       []*duplicateJoin
+	duplicateResults []*duplicateResult
+	hiddenResults    *hiddenResults
+	downlinkCaps     []*downlinkCap
+	ramp             *rampSchedule
+	lag              *lagMonitor
+	webhooks         *webhookCapture
+	permissions      *permissionAdmin
+	rotation         *RotationResults
+	connectQueue     *ConnectQueueResults
+	fuzz             *FuzzResults
+	dynacast         *DynacastResults
+	speakers         *SpeakerResults
+	replaced         int
+	roomNames        []string
+	status           *runStatus
+	stateLog         *stateLog
+	errorLogs        *errorLogs
+	recorder         *scenarioRecorder
+	checkpoints      *checkpointer
+	lock             sync.Mutex
 }
 
 type Params struct {
@@ -47,6 +72,8 @@ type Params struct {
 	VideoResolution string
 	VideoCodec      string
 	Duration        time.Duration
+	// time after the ramp completes before stats are collected, on top of Duration
+	Warmup time.Duration
 	// number of seconds to spin up per second
 	NumPerSecond                  float64
 	Simulcast                     bool
@@ -62,6 +89,125 @@ type Params struct {
 	FairprocConfigScreenBitrate   int
 	FairprocAudioBitrate          int
 	IsFairproc                    bool
+
+	// delay between the start of consecutive rooms
+	RoomStagger time.Duration
+	// join subscribers to empty rooms before publishers
+	SubscribersFirst bool
+	// poll egress/ingress/SIP status at this interval, 0 to disable
+	ServicePollInterval time.Duration
+	// fraction of subscribers that get a second tester joining with the same identity
+	DuplicateJoinRate float64
+	// number of subscribers per room that join as hidden participants
+	HiddenSubscribers int
+	// plan limits to compare against when testing LiveKit Cloud
+	CloudQuota CloudQuota
+	// file to write tester state transitions to as JSON lines
+	StateLogPath string
+	// directory failed testers write their detailed logs to
+	ErrorLogDir string
+	// file the run is saved to as a scenario that replays it
+	RecordScenarioPath string
+	// file the state of the run is written to every CheckpointInterval, to resume it after a crash
+	CheckpointPath     string
+	CheckpointInterval time.Duration
+	// checkpoint of a crashed run to reattach to
+	Resume *Checkpoint
+	// times a tester that failed with a transient error is started again
+	Retries int
+	// signaling connections testers attempt at once, 0 for no limit
+	MaxConcurrentConnects int
+	// join testers to an existing room named Room, instead of creating rooms
+	Attach bool
+	// configurations test rooms are created with in turn, instead of the first tester creating them with the server's defaults
+	RoomTemplate []*RoomConfig
+	// aggregate downlink bandwidth in bps shared by the subscribers of each room, 0 for no limit
+	RoomDownlinkCap int64
+	// machine-readable format of the final stats, StatsOutputJSON or StatsOutputCSV
+	StatsOutput string
+	// file to write StatsOutput to, stdout when empty
+	StatsFile string
+	// where final results are written, the console when empty
+	StatsSinks []StatsSink `json:"-"`
+	// directory of the results history each run is added to, disabled when empty
+	HistoryDir string
+	// sign the result files written by file sinks, so they can be verified with lk perf verify
+	SignResults bool
+	// limits the results must stay within for the run to succeed
+	Thresholds Thresholds
+	// where the results are posted to in addition to the other sinks, disabled when nil
+	Report *ReportSink `json:"-"`
+	// how often to post snapshots of the running test to Report, 0 for only the final results
+	ReportInterval time.Duration
+	// what to do when publishers produce media later than real time, BehindPolicyLog by default
+	BehindPolicy string
+	// how late a frame can be before it counts as behind, DefaultBehindThreshold when 0
+	BehindThreshold time.Duration
+	// number of publishers sending data messages, in addition to any media
+	DataPublishers int
+	// number of publishers sharing their screen, in addition to any camera video
+	ScreenSharePublishers int
+	// fraction of video publishers subscribers leave unsubscribed, to check that the server pauses them
+	DynacastCheck float64
+	// how long simulated speakers talk, DefaultTalkDuration when zero,
+	// and the probability that someone talks over a turn
+	TalkDuration TalkDuration
+	OverlapProb  float64
+	// proxies tester signaling is spread over in turn
+	Proxies []*url.URL
+	// LiveKit URLs testers are spread over, SpreadURLsByTester or SpreadURLsByRoom.
+	// API calls still go to URL
+	URLs       []string
+	SpreadURLs string
+	// pre-minted access tokens handed to testers in join order, instead of minting their own
+	Tokens []string
+	// how far over their target bitrate published tracks may go before they are flagged, DefaultOvershootThreshold when 0
+	OvershootThreshold float64
+	// size in bytes and per publisher rate of data messages
+	DataPacketSize int
+	DataRate       float64
+	// send data messages over the lossy instead of the reliable data channel
+	DataLossy bool
+	// address to receive the server's webhooks on during the run, disabled when empty
+	WebhookAddr string
+	// how long to keep receiving webhooks after the testers leave
+	WebhookWait time.Duration
+	// testers included in the report, subscribers when empty
+	ReportRoles []Role
+	// print per-tester tables even when testing several rooms, where rooms are summarized by default
+	Detailed bool
+	// second credentials that testers joining after RotateAfter use, disabled when empty
+	RotateAPIKey    string
+	RotateAPISecret string
+	RotateAfter     time.Duration
+	// how often publishers send a timestamp for subscribers to measure end-to-end latency, 0 to disable
+	LatencyInterval time.Duration
+	// address to serve health checks on while the test runs, disabled when empty
+	MetricsAddr string
+	// index of the first room, when rooms are sharded across workers
+	RoomOffset int
+	// only join subscribers, when another worker runs the rooms' publishers
+	NoPublishers bool
+	// shape of tester start-up across all rooms, NumPerSecond is used when unset
+	Ramp RampProfile
+	// time publishers wait after connecting before publishing audio and video,
+	// like clients waiting for device permissions
+	AudioPublishDelay time.Duration
+	VideoPublishDelay time.Duration
+	// split the testers of each room into cohorts with different SDK features
+	Cohorts []*Cohort
+	// permission updates per second in each room, promoting and demoting subscribers, 0 to disable
+	PermissionUpdateRate float64
+	// fraction of subscribers that also send malformed and out-of-order signal messages,
+	// only allowed with UnsafeFuzz
+	FuzzRate   float64
+	UnsafeFuzz bool
+	// after ramping up, keep the population steady until interrupted, replacing failed testers,
+	// and skip the report. Duration is ignored
+	Hold bool
+	// replace testers that fail for good with new ones, keeping the population steady
+	ReplaceFailed bool
+
 	TesterParams
 }
 
@@ -69,6 +215,7 @@ func NewLoadTest(params Params) *LoadTest {
 	l := &LoadTest{
 		Params:     params,
 		trackNames: make(map[string]string),
+		status:     newRunStatus(),
 	}
 	if l.Params.NumPerSecond == 0 {
 		// sane default
@@ -77,42 +224,193 @@ func NewLoadTest(params Params) *LoadTest {
 	if l.Params.NumPerSecond > 10 {
 		l.Params.NumPerSecond = 10
 	}
-	if l.Params.VideoPublishers == 0 && l.Params.AudioPublishers == 0 && l.Params.Subscribers == 0 {
+	if l.Params.RoomCount == 0 {
+		l.Params.RoomCount = 1
+	}
+	if l.Params.DataPacketSize == 0 {
+		l.Params.DataPacketSize = DefaultDataPacketSize
+	}
+	if l.Params.DataRate == 0 {
+		l.Params.DataRate = DefaultDataRate
+	}
+	if l.Params.MaxMediaGap == 0 {
+		l.Params.MaxMediaGap = DefaultMaxMediaGap
+	}
+	if l.Params.VideoPublishers == 0 && l.Params.AudioPublishers == 0 && l.Params.DataPublishers == 0 &&
+		l.Params.ScreenSharePublishers == 0 && l.Params.Subscribers == 0 {
 		l.Params.VideoPublishers = 1
 		l.Params.Subscribers = 1
 	}
 	return l
 }
 
+// participants describes the testers joining each room
+func (p Params) participants() string {
+	var participantStrings []string
+	if p.VideoPublishers > 0 {
+		participantStrings = append(participantStrings, fmt.Sprintf("%d video publishers", p.VideoPublishers))
+	}
+	if p.AudioPublishers > 0 {
+		participantStrings = append(participantStrings, fmt.Sprintf("%d audio publishers", p.AudioPublishers))
+	}
+	if p.DataPublishers > 0 {
+		participantStrings = append(participantStrings, fmt.Sprintf("%d data publishers", p.DataPublishers))
+	}
+	if p.ScreenSharePublishers > 0 {
+		participantStrings = append(participantStrings, fmt.Sprintf("%d screen share publishers", p.ScreenSharePublishers))
+	}
+	if p.Subscribers > 0 {
+		participantStrings = append(participantStrings, fmt.Sprintf("%d subscribers", p.Subscribers))
+	}
+	return strings.Join(participantStrings, ", ")
+}
+
+// validateParams rejects options that cannot be combined, before anything connects
+func validateParams(params Params) error {
+	if err := ValidateFuzz(params); err != nil {
+		return err
+	}
+	if err := ValidateDynacastCheck(params); err != nil {
+		return err
+	}
+	if err := ValidateSpeakerSimulation(params); err != nil {
+		return err
+	}
+	if err := ValidateOvershootThreshold(params); err != nil {
+		return err
+	}
+	if err := ValidateTokens(params); err != nil {
+		return err
+	}
+	if err := ValidateURLs(params); err != nil {
+		return err
+	}
+	if err := ValidateSubscribeStrategy(params); err != nil {
+		return err
+	}
+	return nil
+}
+
+// checkUsagePolicy refuses large tests against LiveKit Cloud, and warns about plan limits
+func checkUsagePolicy(params Params) error {
+	isCloud := false
+	for _, endpoint := range params.endpoints() {
+		parsedUrl, err := url.Parse(endpoint)
+		if err != nil {
+			return err
+		}
+		isCloud = isCloud || strings.HasSuffix(parsedUrl.Hostname(), ".livekit.cloud")
+	}
+	if isCloud {
+		if params.VideoPublishers > 50 || params.Subscribers > 50 || params.AudioPublishers > 50 || params.DataPublishers > 50 ||
+			params.ScreenSharePublishers > 50 {
+			return errors.New("Unable to perform load test on LiveKit Cloud. Load testing is prohibited by our acceptable use policy: https://livekit.io/legal/acceptable-use-policy")
+		}
+		for _, warning := range checkCloudQuota(params) {
+			fmt.Println("Warning:", warning)
+		}
+	}
+	return nil
+}
+
 func (t *LoadTest) Run(ctx context.Context) error {
-	parsedUrl, err := url.Parse(t.Params.URL)
+	if c := t.Params.Resume; c != nil {
+		if err := t.Params.resume(c); err != nil {
+			return err
+		}
+		fmt.Printf("Resuming run checkpointed at %s, reattaching testers to room %s\n", c.SavedAt.Format(time.RFC3339), c.Room)
+	} else if t.Params.CheckpointPath != "" {
+		// a resumed run rejoins the same rooms with the same identities
+		if t.Params.Room == "" {
+			t.Params.Room = fmt.Sprintf("testroom%d", rand.Int31n(1000))
+		}
+		if t.Params.IdentityPrefix == "" {
+			t.Params.IdentityPrefix = randStringRunes(5)
+		}
+	}
+	t.checkpoints = newCheckpointer(t.Params)
+
+	err := validateParams(t.Params)
 	if err != nil {
 		return err
 	}
-	if strings.HasSuffix(parsedUrl.Hostname(), ".livekit.cloud") {
-		if t.Params.VideoPublishers > 50 || t.Params.Subscribers > 50 || t.Params.AudioPublishers > 50 {
-			return errors.New("Unable to perform load test on LiveKit Cloud. Load testing is prohibited by our acceptable use policy: https://livekit.io/legal/acceptable-use-policy")
+	if err = checkUsagePolicy(t.Params); err != nil {
+		return err
+	}
+
+	if t.Params.Attach {
+		if err = t.checkRoomExists(ctx); err != nil {
+			return err
 		}
 	}
+	if err = checkMediaFiles(t.Params); err != nil {
+		return err
+	}
+
+	closeLogs, err := t.openLogs()
+	if err != nil {
+		return err
+	}
+	defer closeLogs()
+
+	stopStatus, err := t.serveStatus(t.Params.MetricsAddr)
+	if err != nil {
+		return err
+	}
+	defer stopStatus()
+
+	stopWebhooks, err := t.serveWebhooks(t.Params.WebhookAddr)
+	if err != nil {
+		return err
+	}
+	defer stopWebhooks()
+
+	stopSnapshots := t.postSnapshots(ctx, t.Params.ReportInterval)
+	defer stopSnapshots()
 
+	stopCheckpoints := t.saveCheckpoints(ctx, t.Params.CheckpointInterval)
+	defer stopCheckpoints()
+
+	t.recorder = newScenarioRecorder(t.Params, "recorded")
+	startedAt := time.Now()
 	stats, err := t.run(ctx, t.Params)
+	stopSnapshots()
+	stopCheckpoints()
 	if err != nil {
 		return err
 	}
+	// an interrupted run can still be resumed
+	if err = t.finishCheckpoints(ctx.Err() == nil); err != nil {
+		return err
+	}
+	if err = t.recorder.write(); err != nil {
+		return err
+	}
+	if t.Params.Hold {
+		fmt.Printf("\nHeld for %s, replaced %d testers\n", time.Since(startedAt).Round(time.Second), t.replaced)
+		return nil
+	}
+	if ctx.Err() != nil {
+		fmt.Println("\nTest interrupted, reporting partial results")
+	}
+	t.awaitWebhooks()
 
-	// tester results
-	summaries := make(map[string]*summary)
-	names := make([]string, 0, len(stats))
-	for name := range stats {
-		if strings.HasPrefix(name, "Pub") {
-			continue
-		}
-		names = append(names, name)
+	results := t.results(stats)
+	if err = writeStats(context.WithoutCancel(ctx), statsSinks(t.Params), results); err != nil {
+		return err
+	}
+	if err = t.lag.err(); err != nil {
+		return err
 	}
-	sort.Strings(names)
+	return t.Params.Thresholds.check(results)
+}
+
+// printReport prints the detailed results of a run to the console
+func (t *LoadTest) printReport(stats map[string]*testerStats, names []string) {
+	summaries := make(map[string]*summary)
 
 	testerTable := util.CreateTable().
-		Headers("Tester", "Track", "Kind", "Pkts.", "Bitrate", "Pkt. Loss")
+		Headers("Tester", "Track", "Kind", "Pkts.", "Bitrate", "Pkt. Loss", "First Frame")
 
 	for n, name := range names {
 		testerStats := stats[name]
@@ -145,21 +443,56 @@ func (t *LoadTest) Run(ctx context.Context) error {
 					time.Since(trackStats.startedAt.Load()),
 				),
 				dropped,
+				formatFirstFrame(trackStats),
 			)
 		}
 		if n != len(names)-1 {
-			testerTable.Row("", "", "", "", "", "")
+			testerTable.Row("", "", "", "", "", "", "")
 		}
 
 	}
 
-	if len(names) > 0 {
+	// with several rooms, per-tester tables get long and the room breakdown is printed instead
+	perTester := len(t.roomNames) <= 1 || t.Params.Detailed
+	if len(names) > 0 && perTester {
 		fmt.Println("\nTrack loading:")
 		fmt.Println(testerTable)
 	}
 
-	if len(summaries) == 0 {
-		return nil
+	printConcealmentStats(stats, names)
+	printBurstLoss(stats, names)
+	printResubscribeStats(stats, names)
+	printSpeakerSwitchStats(stats, names)
+	printLayerSwitchStats(stats, names)
+	printReconnectStats(stats, names, t.Params.MaxMediaGap)
+	t.printPermissionStats(stats, names)
+	printRotationResults(t.rotation)
+	printFuzzResults(t.fuzz)
+	printDynacastResults(t.dynacast)
+	printSpeakerResults(t.speakers)
+	printDuplicateResults(t.duplicateResults)
+	printHiddenResults(t.hiddenResults)
+	printDownlinkCaps(t.downlinkCaps)
+	printCohortStats(stats, names)
+	printLatencyStats(stats, names)
+	printInteractivityStats(stats, names)
+	printE2EEStats(stats, names)
+	printDataStats(stats, names, !t.Params.DataLossy)
+	t.printWebhooks()
+	t.lag.print()
+
+	if len(t.serviceEvents) > 0 {
+		timelineTable := util.CreateTable().
+			Headers("Time", "Room", "Service", "ID", "State")
+		for _, e := range t.serviceEvents {
+			timelineTable.Row(e.at.Format("15:04:05.000"), e.room, e.service, e.id, e.state)
+		}
+		fmt.Println("\nService timeline:")
+		fmt.Println(timelineTable)
+	}
+
+	if len(summaries) == 0 || !perTester {
+		return
 	}
 
 	// tester summary
@@ -189,144 +522,199 @@ func (t *LoadTest) Run(ctx context.Context) error {
 			formatBitrate(s.bytes, s.elapsed),
 			formatBitrate(s.bytes/int64(len(summaries)), s.elapsed),
 		)
-		summaryTable.Row("Total", fmt.Sprintf("%d/%d", s.tracks, s.expected), sBitrate, sDropped, string(s.errCount))
+		summaryTable.Row("Total", fmt.Sprintf("%d/%d", s.tracks, s.expected), sBitrate, sDropped, strconv.FormatInt(s.errCount, 10))
 	}
 	fmt.Println("\nSubscriber summaries:")
 	fmt.Println(summaryTable)
-
-	return nil
-}
-
-func (t *LoadTest) RunSuite(ctx context.Context) error {
-	cases := []*struct {
-		publishers  int
-		subscribers int
-		video       bool
-
-		tracks  int64
-		latency time.Duration
-		dropped float64
-	}{
-		{publishers: 10, subscribers: 10, video: false},
-		{publishers: 10, subscribers: 100, video: false},
-		{publishers: 10, subscribers: 500, video: false},
-		{publishers: 10, subscribers: 1000, video: false},
-		{publishers: 50, subscribers: 50, video: false},
-		{publishers: 100, subscribers: 50, video: false},
-
-		{publishers: 10, subscribers: 10, video: true},
-		{publishers: 10, subscribers: 100, video: true},
-		{publishers: 10, subscribers: 500, video: true},
-		{publishers: 1, subscribers: 100, video: true},
-		{publishers: 1, subscribers: 1000, video: true},
-	}
-
-	table := util.CreateTable().
-		Headers("Pubs", "Subs", "Tracks", "Audio", "Video", "Pkt. Loss", "Errors")
-	showTrackStats := false
-
-	for _, c := range cases {
-		caseParams := t.Params
-		videoString := "Yes"
-		if c.video {
-			caseParams.VideoPublishers = c.publishers
-		} else {
-			caseParams.AudioPublishers = c.publishers
-			videoString = "No"
-		}
-		caseParams.Subscribers = c.subscribers
-		caseParams.Simulcast = true
-		if caseParams.Duration == 0 {
-			caseParams.Duration = 15 * time.Second
-		}
-		fmt.Printf("\nRunning test: %d pub, %d sub, video: %s\n", c.publishers, c.subscribers, videoString)
-
-		stats, err := t.run(ctx, caseParams)
-		if err != nil {
-			return err
-		}
-		if ctx.Err() != nil {
-			return ctx.Err()
-		}
-
-		var tracks, packets, dropped, errCount int64
-		for _, testerStats := range stats {
-			for _, trackStats := range testerStats.trackStats {
-				tracks++
-				packets += trackStats.packets.Load()
-				dropped += trackStats.dropped.Load()
-			}
-			if testerStats.err != nil {
-				errCount++
-			}
-		}
-		if tracks > 0 {
-			showTrackStats = true
-			table.Row(
-				strconv.Itoa(c.publishers),
-				strconv.Itoa(c.subscribers),
-				strconv.FormatInt(tracks, 10),
-				"Yes",
-				videoString,
-				formatLossRate(packets, dropped),
-				strconv.FormatInt(errCount, 10),
-			)
-		}
+	if step := firstFailedStep(stats); step >= 0 {
+		fmt.Printf("Failures began at ramp step %s\n", t.ramp.formatStep(step))
 	}
-
-	if showTrackStats {
-		fmt.Println("\nSuite results:")
-		fmt.Println(table)
+	if t.replaced > 0 {
+		fmt.Printf("Replaced %d failed testers to keep the population steady\n", t.replaced)
 	}
-	return nil
 }
 
 func (t *LoadTest) run(ctx context.Context, params Params) (map[string]*testerStats, error) {
 	if params.Room == "" {
 		params.Room = fmt.Sprintf("testroom%d", rand.Int31n(1000))
 	}
+	if params.Attach {
+		params.RoomCount = 1
+	}
 	if params.IdentityPrefix == "" {
 		params.IdentityPrefix = randStringRunes(5)
 	}
 
-	expectedTracks := params.VideoPublishers + params.AudioPublishers
+	expectedTracks := params.VideoPublishers + params.AudioPublishers + params.ScreenSharePublishers
 
-	var participantStrings []string
-	if params.VideoPublishers > 0 {
-		participantStrings = append(participantStrings, fmt.Sprintf("%d video publishers", params.VideoPublishers))
+	fmt.Printf("Starting load test with %s, room: %s\n", params.participants(), params.Room)
+	if params.RunID != "" {
+		fmt.Printf("Published tracks are labeled with run ID %s\n", params.RunID)
+	}
+	if params.Impairment.enabled() {
+		fmt.Printf("Impairing tester networks with %s\n", params.Impairment)
+	}
+	if params.SignalImpairment.enabled() {
+		fmt.Printf("Impairing tester signaling with %s\n", params.SignalImpairment)
+	}
+	if params.BitratePattern != "" {
+		fmt.Printf("Video publishers vary their bitrate in a %s pattern every %s\n",
+			params.BitratePattern, cmp.Or(params.BitratePatternPeriod, DefaultBitratePatternPeriod))
+	}
+	if params.BackupCodec != "" {
+		fmt.Printf("Video publishers register a %s backup codec\n", params.BackupCodec)
+		if params.SubscribeBackupOnly {
+			fmt.Println("Subscribers only negotiate the backup codec")
+		}
+	}
+	if params.ICETransportPolicy == webrtc.ICETransportPolicyRelay {
+		fmt.Println("Testers only connect through TURN relays")
+	}
+	if len(params.URLs) > 1 {
+		fmt.Printf("Spreading testers over %s by %s\n", strings.Join(params.URLs, ", "), cmp.Or(params.SpreadURLs, SpreadURLsByTester))
 	}
-	if params.AudioPublishers > 0 {
-		participantStrings = append(participantStrings, fmt.Sprintf("%d audio publishers", params.AudioPublishers))
+	if len(params.Proxies) > 0 {
+		fmt.Printf("Spreading tester signaling over %d proxies\n", len(params.Proxies))
 	}
-	if params.Subscribers > 0 {
-		participantStrings = append(participantStrings, fmt.Sprintf("%d subscribers", params.Subscribers))
+	if len(params.Tokens) > 0 {
+		fmt.Printf("Testers join with %d pre-minted tokens\n", len(params.Tokens))
+	}
+	for _, turn := range params.ICEServers {
+		fmt.Printf("Testers use TURN server %s\n", strings.Join(turn.Urls, ", "))
+	}
+	for _, c := range params.Cohorts {
+		fmt.Printf("Cohort %s, weight %d\n", c, c.Weight)
+	}
+
+	var poller *servicePoller
+	if params.ServicePollInterval > 0 {
+		rooms := make([]string, 0, params.RoomCount)
+		for j := 0; j < params.RoomCount; j++ {
+			rooms = append(rooms, params.roomName(j))
+		}
+		poller = newServicePoller(params.TesterParams, rooms, params.ServicePollInterval)
+		pollCtx, cancelPoll := context.WithCancel(ctx)
+		defer cancelPoll()
+		go poller.run(pollCtx)
 	}
-	fmt.Printf("Starting load test with %s, room: %s\n",
-		strings.Join(participantStrings, ", "), params.Room)
 
 	var testers []*LoadTester
 	group, _ := errgroup.WithContext(ctx)
 	errs := syncmap.Map{}
-	maxPublishers := params.VideoPublishers
-	if params.AudioPublishers > maxPublishers {
-		maxPublishers = params.AudioPublishers
+	maxPublishers := max(params.VideoPublishers, params.AudioPublishers, params.DataPublishers, params.ScreenSharePublishers)
+
+	// on cancellation, stop joining but still tear down and report on the testers started so far
+	joining := maxPublishers + params.Subscribers
+	if params.NoPublishers {
+		joining = params.Subscribers
+	}
+	if len(params.Tokens) > 0 && len(params.Tokens) < params.RoomCount*joining {
+		return nil, fmt.Errorf("%d testers join, but only %d tokens were given", params.RoomCount*joining, len(params.Tokens))
+	}
+	if err := createRooms(ctx, params, params.RoomCount); err != nil {
+		return nil, err
 	}
+	t.status.begin(params.RoomCount * joining)
+	defer t.status.setPhase(phaseFinished)
 
+	schedule := newRampSchedule(params, params.RoomCount*joining)
+	lag := newLagMonitor(params.BehindPolicy, params.BehindThreshold)
+	ctx, cancelRun := context.WithCancel(ctx)
+	defer cancelRun()
+	if lag.policy == BehindPolicyFail {
+		lag.onFail = cancelRun
+	}
+	t.lock.Lock()
+	t.ramp = schedule
+	t.lag = lag
+	t.lock.Unlock()
+	if params.Ramp.Kind != "" {
+		fmt.Printf("Ramping up with profile %s\n", params.Ramp)
+	}
+
+	demand := newLayerDemand()
+	dynacast := newDynacastCheck(params.DynacastCheck)
+	if dynacast != nil {
+		fmt.Printf("Checking dynacast, subscribers leave %d video publishers per room unwatched\n",
+			unwatchedPublishers(params.VideoPublishers, params.DynacastCheck))
+	}
+	startedAt := time.Now()
+	rotation := newCredentialRotation(params)
+	if rotation != nil {
+		go rotation.run(ctx, params, startedAt)
+	}
+	rampStart := startedAt
+	started := 0
+	rooms := 0
+	var downlinkCaps []*downlinkCap
+	connects := newConnectLimiter(params.MaxConcurrentConnects)
+	stopConnects := context.AfterFunc(ctx, connects.close)
+	defer stopConnects()
+join:
 	for j := 0; j < params.RoomCount; j++ {
-		// throttle pace of join events
-		limiter := rate.NewLimiter(rate.Limit(params.NumPerSecond), 1)
-		for i := 0; i < maxPublishers+params.Subscribers; i++ {
+		if j > 0 && params.RoomStagger > 0 {
+			// each room starts at a fixed offset from the first one, pausing the ramp meanwhile
+			staggerStart := time.Now()
+			select {
+			case <-ctx.Done():
+				break join
+			case <-time.After(time.Until(startedAt.Add(time.Duration(j) * params.RoomStagger))):
+			}
+			rampStart = rampStart.Add(time.Since(staggerStart))
+		}
+		rooms = j + 1
+
+		var roomCap *downlinkCap
+		if params.RoomDownlinkCap > 0 {
+			roomCap = newDownlinkCap(params.roomName(j), params.RoomDownlinkCap)
+			downlinkCaps = append(downlinkCaps, roomCap)
+		}
+
+		for _, i := range joinOrder(maxPublishers, params.Subscribers, params.SubscribersFirst) {
+			if params.NoPublishers && i < maxPublishers {
+				continue
+			}
+
+			if params.BehindPolicy == BehindPolicySlow {
+				// nobody joins while publishers cannot keep up
+				rampStart = rampStart.Add(lag.hold(ctx))
+			}
+			// pace join events according to the ramp
+			if !sleepUntil(ctx, rampStart.Add(schedule.offset(started))) {
+				break join
+			}
+
 			testerParams := params.TesterParams
-			testerParams.Room = fmt.Sprintf("%s_%d", params.Room, j)
+			testerParams.Room = params.roomName(j)
 			testerParams.Sequence = i
 			testerParams.expectedTracks = expectedTracks
+			testerParams.stateLog = t.stateLog
+			testerParams.errorLogs = t.errorLogs
+			testerParams.rampStep = schedule.step(started)
+			testerParams.Cohort = cohortFor(params.Cohorts, i)
+			testerParams.Proxy = proxyFor(params.Proxies, started)
+			testerParams.URL, testerParams.perEndpoint = params.endpointFor(started, j)
+			testerParams.useToken(params.Tokens, started)
+			testerParams.demand = demand
+			testerParams.dynacastCheck = dynacast
+			testerParams.lag = lag
+			testerParams.rotation = rotation
+			testerParams.connects = connects
+			started++
 			isVideoPublisher := i < params.VideoPublishers
 			isAudioPublisher := i < params.AudioPublishers
-			if isVideoPublisher || isAudioPublisher {
+			isDataPublisher := i < params.DataPublishers
+			isScreenSharePublisher := i < params.ScreenSharePublishers
+			testerParams.Role = roleFor(isVideoPublisher || isScreenSharePublisher, isAudioPublisher, isDataPublisher)
+			if testerParams.Role.IsPublisher() {
 				testerParams.expectedTracks = 0
-				if !params.IsFairproc {
-					testerParams.IdentityPrefix += "_webcam_pub"
-				} else {
+				testerParams.dynacastUnwatched = dynacast != nil && isVideoPublisher && isUnwatched(i, params.DynacastCheck)
+				testerParams.talkTurns = params.SimulateSpeakers
+				if params.IsFairproc {
+					// fairproc bitrates are in kbps
+					testerParams.audioTarget = int64(params.FairprocAudioBitrate) * 1000
+					// fairproc apps expect their own publisher identities
+					testerParams.customIdentity = true
 					if i == 0 {
 						testerParams.IdentityPrefix += "_webcam_audio_pub"
 					} else if i == 1 {
@@ -337,107 +725,375 @@ func (t *LoadTest) run(ctx context.Context, params Params) (map[string]*testerSt
 						testerParams.IdentityPrefix += "_pub"
 					}
 				}
-				testerParams.name = fmt.Sprintf("Pub %d", i)
+				testerParams.name = params.testerName("Pub", i, j)
 			} else {
 				testerParams.Subscribe = true
-				testerParams.name = fmt.Sprintf("Sub %d", i-params.VideoPublishers)
+				if dynacast != nil {
+					testerParams.expectedTracks -= unwatchedPublishers(params.VideoPublishers, params.DynacastCheck)
+				}
+				testerParams.expectedTracks = testerParams.selectiveTracks(testerParams.expectedTracks)
+				testerParams.expectAudio = params.AudioPublishers > 0
+				testerParams.expectVideo = params.VideoPublishers > 0 || params.ScreenSharePublishers > 0
+				testerParams.downlinkCap = roomCap
+				testerParams.Hidden = i >= maxPublishers+params.Subscribers-params.HiddenSubscribers
+				testerParams.name = params.testerName("Sub", i-maxPublishers, j)
 			}
 
 			tester := NewLoadTester(testerParams)
 			testers = append(testers, tester)
+			t.status.addTester(tester)
 
 			group.Go(func() error {
-				if err := tester.Start(); err != nil {
-					fmt.Println(errors.Wrapf(err, "could not connect %s", testerParams.name))
+				if err := t.startTesterWithRetries(ctx, params.forRoom(j), tester, isVideoPublisher, isAudioPublisher, isDataPublisher, isScreenSharePublisher); err != nil {
 					errs.Store(testerParams.name, err)
-					return nil
-				}
-
-				if isAudioPublisher {
-					audio, err := tester.PublishAudioTrack("audio")
-					if err != nil {
-						errs.Store(testerParams.name, err)
-						return nil
-					}
-					t.lock.Lock()
-					t.trackNames[audio] = fmt.Sprintf("%dA", testerParams.Sequence)
-					t.lock.Unlock()
-				}
-
-				if isVideoPublisher {
-					var video string
-					var err error
-					if params.IsFairproc {
-
-						if params.Simulcast {
-							video, err = tester.PublishSimulcastTrack("video-simulcast", params.VideoResolution, params.VideoCodec)
-						} else {
-							if i == 0 || i == 1 {
-								video, err = tester.PublishVideoTrack("video-webm", params.VideoResolution, params.VideoCodec, true, params.FairprocConfigWebWidth, params.FairprocConfigScreenHeight, params.FairprocConfigWebFrameRate, params.FairprocConfigWebBitrate)
-							}
-							if i == 2 {
-								video, err = tester.PublishVideoTrack("video-screen-share", params.VideoResolution, params.VideoCodec, true, params.FairprocConfigScreenWidth, params.FairprocConfigScreenHeight, params.FairprocConfigScreenFrameRate, params.FairprocConfigScreenBitrate)
-							}
-						}
-					}
-					if err != nil {
-						errs.Store(testerParams.name, err)
-						return nil
-					}
-					t.lock.Lock()
-					t.trackNames[video] = fmt.Sprintf("%dV", testerParams.Sequence)
-					t.lock.Unlock()
 				}
 				return nil
 			})
 
-			if err := ctx.Err(); err != nil {
-				return nil, err
-			}
-
-			if err := limiter.Wait(ctx); err != nil {
-				return nil, err
+			if ctx.Err() != nil {
+				break join
 			}
 		}
 	}
 
-	/* var speakerSim *SpeakerSimulator
-	if len(publishers) > 0 && t.Params.SimulateSpeakers {
-		speakerSim = NewSpeakerSimulator(SpeakerSimulatorParams{
-			Testers: publishers,
-		})
-		speakerSim.Start()
-	} */
 	if err := group.Wait(); err != nil {
 		return nil, err
 	}
+	t.status.setPhase(phaseRunning)
+	warmUp(ctx, params.Warmup, testers)
+
+	stopAdmin := func() {}
+	if params.PermissionUpdateRate > 0 {
+		admin := newPermissionAdmin(params, testers)
+		t.lock.Lock()
+		t.permissions = admin
+		t.lock.Unlock()
+		var adminCtx context.Context
+		adminCtx, stopAdmin = context.WithCancel(ctx)
+		go admin.run(adminCtx)
+	}
+	fuzz := startFuzzing(ctx, params, testers)
+	speakers := startConversations(params, testers)
 
 	duration := params.Duration
 	if duration == 0 {
 		// a really long time
 		duration = 1000 * time.Hour
 	}
-	fmt.Printf("Finished connecting to room, waiting %s\n", duration.String())
+	var replaced map[string]int
+	t.recorder.ramp(params)
+	heldAt := time.Now()
+	if params.Hold && ctx.Err() == nil {
+		fmt.Printf("Holding %d testers in %d rooms, press Ctrl-C to stop\n", len(testers), rooms)
+		replaced = t.replaceFailed(ctx, params, testers, &errs)
+	} else if ctx.Err() == nil {
+		fmt.Printf("Finished connecting to room, waiting %s\n", duration.String())
 
-	select {
-	case <-ctx.Done():
-		// canceled
-	case <-time.After(duration):
-		// finished
+		waitCtx, cancelWait := context.WithTimeout(ctx, duration)
+		if params.ReplaceFailed {
+			replaced = t.replaceFailed(waitCtx, params, testers, &errs)
+		} else {
+			<-waitCtx.Done()
+		}
+		cancelWait()
 	}
+	t.recorder.phase(ScenarioPhase{Name: "hold", Kind: PhaseHold}, time.Since(heldAt))
 
-	/* if speakerSim != nil {
-		speakerSim.Stop()
-	} */
+	stopAdmin()
+	fuzzResults := fuzz.finish()
+	dynacastResults := dynacast.finish()
+	speakerResults := speakers.finish(testers)
+
+	// evaluate duplicate joins before disconnecting anyone
+	t.lock.Lock()
+	t.duplicateResults = nil
+	for _, d := range t.duplicates {
+		t.duplicateResults = append(t.duplicateResults, d.result())
+		d.duplicate.Stop()
+	}
+	t.duplicates = nil
+	t.hiddenResults = checkHidden(testers)
+	t.fuzz = fuzzResults
+	t.dynacast = dynacastResults
+	t.speakers = speakerResults
+	t.replaced = 0
+	for _, n := range replaced {
+		t.replaced += n
+	}
+	t.rotation = nil
+	if rotation != nil {
+		t.rotation = rotation.finish(testers)
+	}
+	t.downlinkCaps = downlinkCaps
+	t.connectQueue = connects.finish()
+	t.roomNames = nil
+	for j := 0; j < rooms; j++ {
+		t.roomNames = append(t.roomNames, params.roomName(j))
+	}
+	t.lock.Unlock()
+
+	if poller != nil {
+		t.lock.Lock()
+		t.serviceEvents = poller.timeline()
+		t.lock.Unlock()
+	}
 
 	stats := make(map[string]*testerStats)
 	for _, t := range testers {
 		t.Stop()
 		stats[t.params.name] = t.getStats()
+		stats[t.params.name].rampStep = t.params.rampStep
+		stats[t.params.name].role = t.params.Role
+		stats[t.params.name].room = t.params.Room
+		if t.params.perEndpoint {
+			stats[t.params.name].endpoint = t.params.URL
+		}
+		stats[t.params.name].replacements = replaced[t.params.name]
+		if t.params.Cohort != nil {
+			stats[t.params.name].cohort = t.params.Cohort.String()
+			stats[t.params.name].label = t.params.Cohort.Label()
+		}
 		if e, _ := errs.Load(t.params.name); e != nil {
 			stats[t.params.name].err = e.(error)
 		}
 	}
 
+	if ctx.Err() != nil && !params.Attach {
+		t.deleteRooms(params, rooms)
+	}
+
 	return stats, nil
 }
+
+// startTester connects tester and publishes its tracks, returning the error that stopped it
+func (t *LoadTest) startTester(ctx context.Context, params Params, tester *LoadTester, isVideoPublisher, isAudioPublisher, isDataPublisher, isScreenSharePublisher bool) error {
+	if err := tester.Start(); err != nil {
+		fmt.Println(errors.Wrapf(err, "could not connect %s", tester.params.name))
+		t.status.addConnectError()
+		return err
+	}
+
+	if !isVideoPublisher && !isAudioPublisher && !isDataPublisher && !isScreenSharePublisher && params.DuplicateJoinRate > 0 && rand.Float64() < params.DuplicateJoinRate {
+		t.joinDuplicate(tester)
+	}
+
+	publishAudio := func() error {
+		audio, err := tester.PublishAudioTrack("audio")
+		if err != nil {
+			return err
+		}
+		t.lock.Lock()
+		t.trackNames[audio] = fmt.Sprintf("%dA", tester.params.Sequence)
+		t.lock.Unlock()
+		return nil
+	}
+
+	publishVideo := func() error {
+		var video string
+		var err error
+		if params.IsFairproc {
+
+			if params.Simulcast {
+				video, err = tester.PublishSimulcastTrack("video-simulcast", params.VideoResolution, params.VideoCodec)
+			} else {
+				if i := tester.params.Sequence; i == 0 || i == 1 {
+					video, err = tester.PublishVideoTrack("video-webm", params.VideoResolution, params.VideoCodec, true, params.FairprocConfigWebWidth, params.FairprocConfigWebHieght, params.FairprocConfigWebFrameRate, params.FairprocConfigWebBitrate)
+				}
+				if tester.params.Sequence == 2 {
+					video, err = tester.PublishVideoTrack("video-screen-share", params.VideoResolution, params.VideoCodec, true, params.FairprocConfigScreenWidth, params.FairprocConfigScreenHeight, params.FairprocConfigScreenFrameRate, params.FairprocConfigScreenBitrate)
+				}
+			}
+		} else if params.Simulcast {
+			video, err = tester.PublishSimulcastTrack("video-simulcast", params.VideoResolution, params.VideoCodec)
+		} else {
+			video, err = tester.PublishVideoTrack("video", params.VideoResolution, params.VideoCodec, false, -1, -1, -1, -1)
+		}
+		if err != nil {
+			return err
+		}
+		t.lock.Lock()
+		t.trackNames[video] = fmt.Sprintf("%dV", tester.params.Sequence)
+		t.lock.Unlock()
+		return nil
+	}
+
+	publishScreenShare := func() error {
+		screen, err := tester.PublishScreenShareTrack("screenshare", params.VideoCodec)
+		if err != nil {
+			return err
+		}
+		t.lock.Lock()
+		t.trackNames[screen] = fmt.Sprintf("%dS", tester.params.Sequence)
+		t.lock.Unlock()
+		return nil
+	}
+
+	// both delays count from connecting, publish whichever is due first
+	connectedAt := time.Now()
+	steps := []publishStep{
+		{enabled: isAudioPublisher, delay: params.AudioPublishDelay, publish: publishAudio},
+		{enabled: isVideoPublisher, delay: params.VideoPublishDelay, publish: publishVideo},
+	}
+	if params.VideoPublishDelay < params.AudioPublishDelay {
+		steps[0], steps[1] = steps[1], steps[0]
+	}
+	// screen shares start along with the camera
+	steps = append(steps, publishStep{enabled: isScreenSharePublisher, delay: params.VideoPublishDelay, publish: publishScreenShare})
+	for _, step := range steps {
+		if !step.enabled {
+			continue
+		}
+		if !sleepUntil(ctx, connectedAt.Add(step.delay)) {
+			return nil
+		}
+		if err := step.publish(); err != nil {
+			t.status.addPublishError()
+			if isTransient(err) {
+				tester.transientErrors.Inc()
+			}
+			return err
+		}
+	}
+	if params.LatencyInterval > 0 && (isVideoPublisher || isAudioPublisher || isScreenSharePublisher) {
+		go tester.stampLatency(params.LatencyInterval)
+	}
+	if isDataPublisher {
+		go tester.publishData(params.DataPacketSize, params.DataRate, !params.DataLossy)
+	}
+	return nil
+}
+
+type publishStep struct {
+	enabled bool
+	delay   time.Duration
+	publish func() error
+}
+
+// sleepUntil waits until deadline, returning false if ctx is canceled first
+func sleepUntil(ctx context.Context, deadline time.Time) bool {
+	wait := time.Until(deadline)
+	if wait <= 0 {
+		return ctx.Err() == nil
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(wait):
+		return true
+	}
+}
+
+// deleteRooms removes the test rooms after an interrupted run, so that testers which
+// had not fully disconnected do not keep them open
+func (t *LoadTest) deleteRooms(params Params, count int) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	roomClient := lksdk.NewRoomServiceClient(params.URL, params.APIKey, params.APISecret)
+	for j := 0; j < count; j++ {
+		room := params.roomName(j)
+		if _, err := roomClient.DeleteRoom(ctx, &livekit.DeleteRoomRequest{Room: room}); err != nil {
+			fmt.Println(errors.Wrapf(err, "could not delete room %s", room))
+		}
+	}
+}
+
+// checkMediaFiles fails early on custom or generated media that publishers would not be able to use
+func checkMediaFiles(params Params) error {
+	if len(params.VideoFiles) == 0 && params.TestPattern == nil && params.VideoPublishers+params.ScreenSharePublishers > 0 {
+		if err := provider.CheckEmbeddedCodec(params.VideoCodec); err != nil {
+			return err
+		}
+	}
+	if len(params.VideoFiles) > 0 {
+		if _, err := provider.CreateVideoLoopersFromFiles(params.VideoFiles, "high", params.VideoCodec, true); err != nil {
+			return err
+		}
+	}
+	if params.TestPattern != nil {
+		_, err := provider.CreateTestPatternLoopers(*params.TestPattern, params.VideoResolution, params.VideoCodec, params.Simulcast)
+		if err != nil {
+			return err
+		}
+	}
+	if params.AudioFile != "" {
+		looper, err := provider.CreateAudioLooperFromFile(params.AudioFile)
+		if err != nil {
+			return err
+		}
+		if params.AudioStereo && looper.Channels() < 2 {
+			return fmt.Errorf("--audio-stereo needs a stereo audio file, %s is mono", params.AudioFile)
+		}
+	}
+	return nil
+}
+
+// roomName returns the name of the j-th test room
+func (p *Params) roomName(j int) string {
+	if p.Attach {
+		return p.Room
+	}
+	return fmt.Sprintf("%s_%d", p.Room, p.RoomOffset+j)
+}
+
+// testerName names the i-th publisher or subscriber of the j-th room, unique across rooms
+func (p *Params) testerName(kind string, i, j int) string {
+	if p.RoomCount > 1 {
+		return fmt.Sprintf("R%d %s %d", p.RoomOffset+j, kind, i)
+	}
+	return fmt.Sprintf("%s %d", kind, i)
+}
+
+// checkRoomExists verifies the room to attach to is running
+func (t *LoadTest) checkRoomExists(ctx context.Context) error {
+	roomClient := lksdk.NewRoomServiceClient(t.Params.URL, t.Params.APIKey, t.Params.APISecret)
+	res, err := roomClient.ListRooms(ctx, &livekit.ListRoomsRequest{
+		Names: []string{t.Params.Room},
+	})
+	if err != nil {
+		return err
+	}
+	if len(res.Rooms) == 0 {
+		return fmt.Errorf("room %s does not exist", t.Params.Room)
+	}
+	room := res.Rooms[0]
+	fmt.Printf("Attaching to room %s with %d participants, %d publishers\n",
+		room.Name, room.NumParticipants, room.NumPublishers)
+	return nil
+}
+
+func (t *LoadTest) openStateLog() (func(), error) {
+	if t.Params.StateLogPath == "" {
+		return func() {}, nil
+	}
+	l, err := newStateLog(t.Params.StateLogPath)
+	if err != nil {
+		return nil, err
+	}
+	t.stateLog = l
+	return func() {
+		_ = l.Close()
+		t.stateLog = nil
+	}, nil
+}
+
+// joinOrder returns the sequence numbers of testers in the order they should join.
+// Publishers occupy the first numPublishers sequence numbers, and normally join
+// first. When subscribersFirst is set, subscribers join empty rooms and
+// publishers arrive afterwards.
+func joinOrder(numPublishers, numSubscribers int, subscribersFirst bool) []int {
+	order := make([]int, 0, numPublishers+numSubscribers)
+	if subscribersFirst {
+		for i := numPublishers; i < numPublishers+numSubscribers; i++ {
+			order = append(order, i)
+		}
+		for i := 0; i < numPublishers; i++ {
+			order = append(order, i)
+		}
+	} else {
+		for i := 0; i < numPublishers+numSubscribers; i++ {
+			order = append(order, i)
+		}
+	}
+	return order
+}
@@ -16,17 +16,29 @@ package loadtester
 
 import (
 	"fmt"
+	"maps"
+	"math"
+	"math/rand"
+	"net/url"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/pion/interceptor"
+	"github.com/pion/interceptor/pkg/nack"
+	"github.com/pion/interceptor/pkg/report"
+	"github.com/pion/interceptor/pkg/twcc"
 	"github.com/pion/rtp"
 	"github.com/pion/rtp/codecs"
 	"github.com/pion/webrtc/v4"
 	"go.uber.org/atomic"
 
 	provider2 "github.com/livekit/livekit-cli/v2/pkg/provider"
+	"github.com/livekit/protocol/auth"
 	"github.com/livekit/protocol/livekit"
 	lksdk "github.com/livekit/server-sdk-go/v2"
+	sdkinterceptor "github.com/livekit/server-sdk-go/v2/pkg/interceptor"
 	"github.com/livekit/server-sdk-go/v2/pkg/samplebuilder"
 )
 
@@ -38,7 +50,54 @@ type LoadTester struct {
 	room                   *lksdk.Room
 	running                atomic.Bool
 	trackQualities         map[string]livekit.VideoQuality
-	stats                  *sync.Map
+	publishedAt            map[string]time.Time
+	seenParticipants       map[string]bool
+	pendingResubscribes    map[string]*pendingResubscribe
+	resubscribes           []*resubscribeSample
+	activeSpeaker          string
+	pendingSpeakerSwitches map[string]time.Time
+	speakerSwitches        []time.Duration
+	pendingLayerSwitches   map[string]time.Time
+	layerSwitches          []time.Duration
+	pendingReconnect       *pendingReconnect
+	reconnectAttempts      int
+	reconnectFailures      int
+	reconnects             []*reconnectSample
+	unresumedTracks        []string
+	// reconnect waiting for media to arrive again
+	resubscribing     *reconnectSample
+	reconnectedFrom   time.Time
+	awaitingMedia     atomic.Bool
+	latencies         latencySamples
+	latencyHist       histogram
+	permissionUpdates latencySamples
+	dataSenders       map[string]*dataSender
+	dataSent          atomic.Int64
+	dataErrors        atomic.Int64
+	stats             *sync.Map
+	disconnectReason  atomic.String
+	sentBytes         atomic.Int64
+	// API key the tester's token was signed with
+	joinedWithKey atomic.String
+	interactive   interactivity
+	// published video tracks of a dynacast check
+	dynacastTracks []*dynacastTrack
+	// relays signaling of dynacast check publishers, to see the subscribed quality updates
+	signalTap *signalTap
+	// whether it's the tester's turn in a simulated conversation, and active speaker updates received
+	talking        atomic.Bool
+	speakerUpdates atomic.Int64
+	// published tracks and layers measured against their target bitrate
+	sentLayers []*sentLayer
+	// signaling and state changes, written out when the tester fails
+	errorLog *testerLog
+	// failed attempts to join or publish that were transient, and attempts repeated after them
+	transientErrors atomic.Int64
+	retries         atomic.Int64
+	// tracks a subscriber with a SubscribeCount is subscribed to
+	selection trackSelection
+	// second codec registered with published camera tracks, nil without BackupCodec
+	backup *backupCodec
 }
 
 type Layout string
@@ -52,6 +111,13 @@ const (
 	LayoutGrid4x4 Layout = "4x4"
 	// LayoutGrid5x5 - 25 participants at 256x144
 	LayoutGrid5x5 Layout = "5x5"
+	// LayoutSpeakerFollow - like LayoutSpeaker, with the 1280x720 slot following the active speaker
+	LayoutSpeakerFollow Layout = "speaker-follow"
+
+	// SyntheticAttribute marks tester participants as synthetic load, so server side
+	// analytics and dashboards can filter them out
+	SyntheticAttribute      = "lk.synthetic"
+	SyntheticAttributeValue = "loadtest"
 
 	highWidth    = 1280
 	highHeight   = 720
@@ -59,6 +125,11 @@ const (
 	mediumHeight = 360
 	lowWidth     = 320
 	lowHeight    = 180
+
+	// packets the sample builder holds back waiting for a missing one, before giving up on it
+	sampleBuilderMaxLate = 100
+	// shortest time between enabling simulcast layers, a ticker can't tick any faster
+	minPublishRampStep = time.Millisecond
 )
 
 func LayoutFromString(str string) Layout {
@@ -68,6 +139,8 @@ func LayoutFromString(str string) Layout {
 		return LayoutGrid4x4
 	} else if str == string(LayoutGrid5x5) {
 		return LayoutGrid5x5
+	} else if str == string(LayoutSpeakerFollow) {
+		return LayoutSpeakerFollow
 	}
 	return LayoutSpeaker
 }
@@ -81,19 +154,128 @@ type TesterParams struct {
 	Layout         Layout
 	// true to subscribe to all published tracks
 	Subscribe bool
+	// subscribe to every track regardless of layout
+	SubscribeAll bool
+	// subscribe to only this many tracks, picked by SubscribeStrategy
+	SubscribeCount    int
+	SubscribeStrategy string
+	// join as a hidden participant, invisible to others in the room
+	Hidden bool
+	// time over which simulcast publishers enable their layers, lowest first, and other video
+	// publishers raise their frame rate to the full bitrate
+	PublishRamp time.Duration
+	// simulcast layers to publish instead of the clips of the video resolution
+	SimulcastLadder []provider2.SimulcastLayer
+	// how often subscribers unsubscribe from a track and subscribe to it again
+	ResubscribeInterval time.Duration
+	// subscribers request a different video quality for each track every AdaptiveCycle
+	AdaptiveCycle time.Duration
+	// how often testers are forced to reconnect, in ReconnectMode
+	ReconnectInterval time.Duration
+	ReconnectMode     string
+	// longest pause in received media that is not counted as a gap, DefaultMaxMediaGap when 0
+	MaxMediaGap time.Duration
+	// longest time between video frames that is not counted as a freeze, DefaultFreezeThreshold when 0
+	FreezeThreshold time.Duration
+	// varies the bitrate of published video over each BitratePatternPeriod, DefaultBitratePatternPeriod when 0
+	BitratePattern       BitratePattern
+	BitratePatternPeriod time.Duration
+	// "vp8" or "h264" codec publishers register with their camera tracks, sent once the server asks for it.
+	// Subscribers negotiate only the backup with SubscribeBackupOnly.
+	BackupCodec         string
+	SubscribeBackupOnly bool
+	// set SyntheticAttribute on tester participants
+	MarkSynthetic bool
+	// set on tester participants through their tokens, next to the attributes testers set themselves
+	Metadata   string
+	Attributes map[string]string
+	// fraction of received audio packets to drop, simulating loss on the subscriber's network
+	AudioPacketLoss float64
+	// IVF, MP4, H.264 Annex B or Y4M files to publish instead of the embedded clips, lowest quality first
+	VideoFiles []string
+	// size of a generated test pattern to publish instead of the embedded clips, nil to publish them
+	TestPattern *provider2.TestPattern
+	// Ogg Opus file to publish instead of the embedded clips
+	AudioFile string
+	// Opus publication options: drop silent frames like an encoder with DTX, publish generated
+	// stereo audio unless AudioFile is stereo, and keep the server from adding redundancy with RED
+	AudioDTX        bool
+	AudioStereo     bool
+	AudioDisableRED bool
+	// added with the tester index to published track names and stream IDs when set
+	RunID string
+	// start publishing at a random keyframe in the source files
+	RandomOffset bool
+	// simulated network conditions of the tester's media, and of its signaling
+	Impairment       Impairment
+	SignalImpairment SignalImpairment
+	// candidates the tester connects over, relay to only use TURN,
+	// and the TURN servers it uses instead of the ones the server hands out
+	ICETransportPolicy webrtc.ICETransportPolicy
+	ICEServers         []*livekit.ICEServer
+	// HTTP or SOCKS5 proxy the tester's signaling goes through, to join from another IP
+	Proxy *url.URL
+	// pre-minted access token to join with instead of minting one, and the identity it grants
+	Token         string
+	tokenIdentity string
+	// URL is one of several the test spreads testers over
+	perEndpoint bool
+	// SDK features of the tester, defaultFeatures when nil
+	Cohort *Cohort
+	// what the tester does in the room, encoded into its identity
+	Role Role
+	// shared passphrase publishers encrypt their frames with, and subscribers decrypt them with
+	E2EEKey string
 
-	name           string
-	Sequence       int
+	name     string
+	Sequence int
+	// IdentityPrefix names the tester on its own, without the role
+	customIdentity bool
 	expectedTracks int
+	stateLog       *stateLog
+	// buffers what the tester does, written out when it fails
+	errorLogs *errorLogs
+	// bandwidth limit shared with the other subscribers in the room
+	downlinkCap *downlinkCap
+	// ramp step the tester is started in
+	rampStep int
+	// kinds of media a subscriber needs before the call is usable
+	expectAudio bool
+	expectVideo bool
+	// video quality subscribers request from publishers, for dynacast
+	demand *layerDemand
+	// collects the video tracks of a dynacast check, and whether subscribers leave the tester's unwatched
+	dynacastCheck     *dynacastCheck
+	dynacastUnwatched bool
+	// collects publisher frames produced later than real time
+	lag *lagMonitor
+	// switches the credentials testers join with partway through the run
+	rotation *credentialRotation
+	// caps the testers connecting at once
+	connects *connectLimiter
+	// audio is silent outside of the tester's turns in a simulated conversation
+	talkTurns bool
+	// bitrate in bps published audio should stay within, 0 when it has no target
+	audioTarget int64
 }
 
 func NewLoadTester(params TesterParams) *LoadTester {
-	return &LoadTester{
+	t := &LoadTester{
 		params:                 params,
 		stats:                  &sync.Map{},
 		trackQualities:         make(map[string]livekit.VideoQuality),
+		publishedAt:            make(map[string]time.Time),
+		seenParticipants:       make(map[string]bool),
+		pendingResubscribes:    make(map[string]*pendingResubscribe),
+		pendingSpeakerSwitches: make(map[string]time.Time),
+		pendingLayerSwitches:   make(map[string]time.Time),
 		subscribedParticipants: make(map[string]*lksdk.RemoteParticipant),
+		dataSenders:            make(map[string]*dataSender),
+		backup:                 newBackupCodec(params.BackupCodec),
 	}
+	t.errorLog = params.errorLogs.forTester(t.identity())
+	t.setState(stateCreated, "", nil)
+	return t
 }
 
 func (t *LoadTester) Start() error {
@@ -101,36 +283,121 @@ func (t *LoadTester) Start() error {
 		return nil
 	}
 
-	identity := fmt.Sprintf("%s_%d", t.params.IdentityPrefix, t.params.Sequence)
+	t.interactive.join(time.Now())
+	identity := t.identity()
 	t.room = lksdk.NewRoom(&lksdk.RoomCallback{
+		OnDisconnectedWithReason: func(reason lksdk.DisconnectionReason) {
+			t.disconnectReason.Store(string(reason))
+			t.setState(stateDisconnected, string(reason), nil)
+		},
+		OnReconnecting:         t.onReconnecting,
+		OnReconnected:          t.onReconnected,
+		OnParticipantConnected: t.onParticipantConnected,
+		OnActiveSpeakersChanged: func(speakers []lksdk.Participant) {
+			var identities []string
+			for _, p := range speakers {
+				if _, ok := p.(*lksdk.RemoteParticipant); ok {
+					identities = append(identities, p.Identity())
+				}
+			}
+			t.speakerUpdates.Inc()
+			t.interactive.speaking(identities)
+			t.onActiveSpeakersChanged(speakers)
+			t.followLoudest(speakers)
+		},
 		ParticipantCallback: lksdk.ParticipantCallback{
 			OnTrackSubscribed: t.onTrackSubscribed,
 			OnTrackSubscriptionFailed: func(sid string, rp *lksdk.RemoteParticipant) {
 				fmt.Printf("track subscription failed, lp:%v, sid:%v, rp:%v/%v\n", identity, sid, rp.Identity(), rp.SID())
 			},
-			OnTrackPublished: t.onTrackPublished,
+			OnTrackUnsubscribed: func(_ *webrtc.TrackRemote, pub *lksdk.RemoteTrackPublication, _ *lksdk.RemoteParticipant) {
+				t.pauseFreezes(pub, freezePauseUnsubscribed, true)
+			},
+			OnTrackMuted: func(pub lksdk.TrackPublication, _ lksdk.Participant) {
+				if remotePub, ok := pub.(*lksdk.RemoteTrackPublication); ok {
+					t.pauseFreezes(remotePub, freezePauseMuted, true)
+				}
+			},
+			OnTrackUnmuted: func(pub lksdk.TrackPublication, _ lksdk.Participant) {
+				if remotePub, ok := pub.(*lksdk.RemoteTrackPublication); ok {
+					t.pauseFreezes(remotePub, freezePauseMuted, false)
+				}
+			},
+			OnTrackPublished:    t.onTrackPublished,
+			OnTrackUnpublished:  t.onTrackUnpublished,
+			OnDataPacket:        t.onDataPacket,
+			OnAttributesChanged: t.onAttributesChanged,
 		},
 	})
-	var err error
-	// make up to 10 reconnect attempts
-	for i := 0; i < 10; i++ {
-		err = t.room.Join(t.params.URL, lksdk.ConnectInfo{
-			APIKey:              t.params.APIKey,
-			APISecret:           t.params.APISecret,
-			RoomName:            t.params.Room,
-			ParticipantIdentity: identity,
-		}, lksdk.WithAutoSubscribe(false))
+	token, err := t.token()
+	if err != nil {
+		t.setState(stateError, stateToken, err)
+		return err
+	}
+	opts := []lksdk.ConnectOption{lksdk.WithAutoSubscribe(false)}
+	if t.params.ICETransportPolicy != webrtc.ICETransportPolicyAll {
+		opts = append(opts, lksdk.WithICETransportPolicy(t.params.ICETransportPolicy))
+	}
+	var impairments []interceptor.Factory
+	if t.params.downlinkCap != nil {
+		impairments = append(impairments, t.params.downlinkCap)
+	}
+	if impairment := t.impairment(); impairment.enabled() {
+		impairments = append(impairments, impairment)
+	}
+	if len(impairments) > 0 {
+		opt, err := withInterceptors(impairments)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, opt)
+	}
+
+	joinURL, err := pinnedProtocolURL(t.params.URL, t.features().Protocol)
+	if err != nil {
+		t.setState(stateError, stateToken, err)
+		return err
+	}
+	if t.needsSignalTap() {
+		if t.signalTap, err = startSignalTap(joinURL, t.params.SignalImpairment, t.params.Proxy, t.onSignalRequest, t.onSignalResponse); err != nil {
+			t.setState(stateError, stateToken, err)
+			return err
+		}
+		joinURL = t.signalTap.url
+	}
+
+	t.setState(stateToken, "", nil)
+	for i := 0; i < joinAttempts; i++ {
+		if i > 0 {
+			time.Sleep(1 * time.Second)
+			t.retries.Inc()
+		}
+		if err = t.params.connects.acquire(); err != nil {
+			break
+		}
+		err = t.room.JoinWithToken(joinURL, token, opts...)
+		t.params.connects.release()
 		if err == nil {
 			break
 		}
-		time.Sleep(1 * time.Second)
+		t.errorLog.logf("join attempt %d failed: %v", i+1, err)
+		if isTransient(err) {
+			t.transientErrors.Inc()
+		}
 	}
 	if err != nil {
+		if t.signalTap != nil {
+			t.signalTap.close()
+		}
+		t.setState(stateError, stateConnected, err)
 		return err
 	}
 
 	t.running.Store(true)
+	t.interactive.connected(time.Now())
+	t.setState(stateConnected, "", nil)
 	for _, p := range t.room.GetRemoteParticipants() {
+		t.onParticipantConnected(p)
 		for _, pub := range p.TrackPublications() {
 			if remotePub, ok := pub.(*lksdk.RemoteTrackPublication); ok {
 				t.onTrackPublished(remotePub, p)
@@ -138,9 +405,119 @@ func (t *LoadTester) Start() error {
 		}
 	}
 
+	if t.params.Subscribe && t.params.ResubscribeInterval > 0 {
+		go t.resubscribeWorker()
+	}
+	if t.params.Subscribe && t.params.AdaptiveCycle > 0 {
+		go t.cycleLayers(t.params.AdaptiveCycle)
+	}
+	if t.params.ReconnectInterval > 0 {
+		go t.reconnectWorker()
+	}
+
 	return nil
 }
 
+// withInterceptors replaces the SDK's default interceptors with the same set, preceded by the
+// given ones so they act closest to the network
+func withInterceptors(factories []interceptor.Factory) (lksdk.ConnectOption, error) {
+	responder, err := nack.NewResponderInterceptor()
+	if err != nil {
+		return nil, err
+	}
+	reportReceiver, err := report.NewReceiverInterceptor()
+	if err != nil {
+		return nil, err
+	}
+	reportSender, err := report.NewSenderInterceptor()
+	if err != nil {
+		return nil, err
+	}
+	twccGenerator, err := twcc.NewSenderInterceptor()
+	if err != nil {
+		return nil, err
+	}
+	return lksdk.WithInterceptors(append(factories,
+		&sdkinterceptor.NackGeneratorInterceptorFactory{},
+		responder,
+		reportReceiver,
+		reportSender,
+		twccGenerator,
+		sdkinterceptor.NewLimitSizeInterceptorFactory(),
+	)), nil
+}
+
+// Identity is the participant identity the tester joins as
+func (t *LoadTester) Identity() string {
+	return t.identity()
+}
+
+func (t *LoadTester) identity() string {
+	if t.params.tokenIdentity != "" {
+		return t.params.tokenIdentity
+	}
+	if t.params.Role == "" || t.params.customIdentity {
+		return fmt.Sprintf("%s_%d", t.params.IdentityPrefix, t.params.Sequence)
+	}
+	return fmt.Sprintf("%s_%s-%d", t.params.IdentityPrefix, t.params.Role, t.params.Sequence)
+}
+
+// trackOptions labels published tracks with the run ID and tester index, so that server logs
+// collected during a run can be joined back to the publisher
+func (t *LoadTester) trackOptions(name string) *lksdk.TrackPublicationOptions {
+	opts := &lksdk.TrackPublicationOptions{Name: name}
+	if t.params.RunID != "" {
+		label := fmt.Sprintf("%s_%d", t.params.RunID, t.params.Sequence)
+		opts.Name = name + "_" + label
+		opts.Stream = label
+	}
+	if t.params.E2EEKey != "" {
+		opts.Encryption = livekit.Encryption_GCM
+	}
+	return opts
+}
+
+func (t *LoadTester) token() (string, error) {
+	if t.params.Token != "" {
+		return t.params.Token, nil
+	}
+	apiKey, apiSecret := t.params.rotation.credentials(t.params.APIKey, t.params.APISecret)
+	t.joinedWithKey.Store(apiKey)
+	at := auth.NewAccessToken(apiKey, apiSecret)
+	at.SetVideoGrant(&auth.VideoGrant{
+		RoomJoin: true,
+		Room:     t.params.Room,
+		Hidden:   t.params.Hidden,
+	}).
+		SetIdentity(t.identity()).
+		SetMetadata(t.params.Metadata)
+	attributes := maps.Clone(t.params.Attributes)
+	if attributes == nil {
+		attributes = make(map[string]string)
+	}
+	if t.params.Role != "" {
+		attributes[RoleAttribute] = string(t.params.Role)
+	}
+	if t.params.MarkSynthetic {
+		attributes[SyntheticAttribute] = SyntheticAttributeValue
+	}
+	if t.params.dynacastUnwatched {
+		attributes[DynacastCheckAttribute] = dynacastUnwatched
+	}
+	if c := t.params.Cohort; c != nil {
+		if c.Region != "" {
+			attributes[RegionAttribute] = c.Region
+		}
+		if c.ISP != "" {
+			attributes[ISPAttribute] = c.ISP
+		}
+	}
+	if len(attributes) > 0 {
+		at.SetAttributes(attributes)
+	}
+	return at.ToJWT()
+}
+
 func (t *LoadTester) IsRunning() bool {
 	return t.running.Load()
 }
@@ -150,24 +527,42 @@ func (t *LoadTester) PublishAudioTrack(name string) (string, error) {
 		return "", nil
 	}
 
-	audioLooper, err := provider2.CreateAudioLooper()
+	audioLooper, err := t.audioLooper()
 	if err != nil {
 		return "", err
 	}
+	if t.params.talkTurns {
+		audioLooper = &talkGate{audioSource: audioLooper, talking: &t.talking}
+	}
 	track, err := lksdk.NewLocalTrack(audioLooper.Codec())
 	if err != nil {
 		return "", err
 	}
-	if err := track.StartWrite(audioLooper, nil); err != nil {
+	target := t.params.audioTarget
+	if _, ok := audioLooper.(*provider2.OpusGenerator); ok && target == 0 {
+		target = int64(audioLooper.Channels() * provider2.GeneratedOpusChannelBitrate)
+	}
+	provider := t.measureLayer(audioLooper, name, target)
+	if t.params.AudioDTX {
+		provider = &dtxProvider{SampleProvider: provider}
+	}
+	provider = &audioLevelProvider{
+		SampleProvider: t.countSent(t.encrypted(provider, audioLooper.Codec().MimeType)),
+		source:         audioLooper,
+	}
+	if err := track.StartWrite(provider, nil); err != nil {
 		return "", err
 	}
 
-	p, err := t.room.LocalParticipant.PublishTrack(track, &lksdk.TrackPublicationOptions{
-		Name: name,
-	})
+	opts := t.trackOptions(name)
+	opts.DisableDTX = !t.params.AudioDTX
+	opts.Stereo = audioLooper.Channels() > 1
+	p, err := t.room.LocalParticipant.PublishTrack(track, opts)
 	if err != nil {
+		t.setState(stateError, statePublished, err)
 		return "", err
 	}
+	t.setState(statePublished, p.SID(), nil)
 	return p.SID(), nil
 }
 
@@ -177,24 +572,89 @@ func (t *LoadTester) PublishVideoTrack(name, resolution, codec string, isFairpro
 	}
 
 	fmt.Println("publishing video track -", t.room.LocalParticipant.Identity())
-	loopers, err := provider2.CreateVideoLoopers(resolution, codec, false, isFairproc, videoWidth, videoHeight, frameRate, bitrate)
+	var loopers []provider2.VideoLooper
+	var err error
+	if len(t.params.VideoFiles) > 0 && !isFairproc {
+		loopers, err = provider2.CreateVideoLoopersFromFiles(t.params.VideoFiles, resolution, codec, false)
+	} else if t.params.TestPattern != nil && !isFairproc {
+		loopers, err = provider2.CreateTestPatternLoopers(*t.params.TestPattern, resolution, codec, false)
+	} else {
+		loopers, err = provider2.CreateVideoLoopers(resolution, codec, false, isFairproc, videoWidth, videoHeight, frameRate, bitrate)
+	}
 	if err != nil {
 		return "", err
 	}
+	if err = t.checkE2EECodec(loopers[0].Codec().MimeType); err != nil {
+		return "", err
+	}
+	t.seekLoopers(loopers)
 	track, err := lksdk.NewLocalTrack(loopers[0].Codec())
 	if err != nil {
 		return "", err
 	}
-	if err := track.StartWrite(loopers[0], nil); err != nil {
+	target := int64(loopers[0].ToLayer(livekit.VideoQuality_HIGH).Bitrate)
+	mimeType := loopers[0].Codec().MimeType
+	provider := t.encrypted(t.measureLayer(t.ramped(t.patterned(loopers[0], mimeType), mimeType), name, target), mimeType)
+	dynacast := t.dynacastTrack()
+	if dynacast != nil {
+		provider = dynacast.layer(provider, livekit.VideoQuality_OFF)
+	}
+	if err := track.StartWrite(t.countSent(provider), nil); err != nil {
+		return "", err
+	}
+	backup, err := t.backupOptions(mimeType, resolution, false)
+	if err != nil {
 		return "", err
 	}
 
-	p, err := t.room.LocalParticipant.PublishTrack(track, &lksdk.TrackPublicationOptions{
-		Name: name,
-	})
+	p, err := t.room.LocalParticipant.PublishTrack(track, t.trackOptions(name), backup...)
 	if err != nil {
+		t.setState(stateError, statePublished, err)
 		return "", err
 	}
+	if dynacast != nil {
+		dynacast.published(p.SID())
+	}
+	t.setState(statePublished, p.SID(), nil)
+	return p.SID(), nil
+}
+
+// PublishScreenShareTrack publishes a screen share next to any camera track, at high resolution
+// and a low frame rate
+func (t *LoadTester) PublishScreenShareTrack(name, codec string) (string, error) {
+	if !t.IsRunning() {
+		return "", nil
+	}
+
+	fmt.Println("publishing screen share track -", t.room.LocalParticipant.Identity())
+	looper, err := provider2.CreateScreenShareLooper(codec)
+	if err != nil {
+		return "", err
+	}
+	if err = t.checkE2EECodec(looper.Codec().MimeType); err != nil {
+		return "", err
+	}
+	t.seekLoopers([]provider2.VideoLooper{looper})
+	track, err := lksdk.NewLocalTrack(looper.Codec())
+	if err != nil {
+		return "", err
+	}
+	layer := looper.ToLayer(livekit.VideoQuality_HIGH)
+	provider := t.measureLayer(looper, name, int64(layer.Bitrate))
+	if err := track.StartWrite(t.countSent(t.encrypted(provider, looper.Codec().MimeType)), nil); err != nil {
+		return "", err
+	}
+
+	opts := t.trackOptions(name)
+	opts.Source = livekit.TrackSource_SCREEN_SHARE
+	opts.VideoWidth = int(layer.Width)
+	opts.VideoHeight = int(layer.Height)
+	p, err := t.room.LocalParticipant.PublishTrack(track, opts)
+	if err != nil {
+		t.setState(stateError, statePublished, err)
+		return "", err
+	}
+	t.setState(statePublished, p.SID(), nil)
 	return p.SID(), nil
 }
 
@@ -202,10 +662,25 @@ func (t *LoadTester) PublishSimulcastTrack(name, resolution, codec string) (stri
 	var tracks []*lksdk.LocalTrack
 
 	fmt.Println("publishing simulcast video track -", t.room.LocalParticipant.Identity())
-	loopers, err := provider2.CreateVideoLoopers(resolution, codec, true, false, -1, -1, -1, -1)
+	var loopers []provider2.VideoLooper
+	var err error
+	if len(t.params.VideoFiles) > 0 {
+		loopers, err = provider2.CreateVideoLoopersFromFiles(t.params.VideoFiles, resolution, codec, true)
+	} else if t.params.TestPattern != nil {
+		loopers, err = provider2.CreateTestPatternLoopers(*t.params.TestPattern, resolution, codec, true)
+	} else if len(t.params.SimulcastLadder) > 0 {
+		loopers, err = provider2.CreateSimulcastLoopers(resolution, codec, t.params.SimulcastLadder)
+	} else {
+		loopers, err = provider2.CreateVideoLoopers(resolution, codec, true, false, -1, -1, -1, -1)
+	}
 	if err != nil {
 		return "", err
 	}
+	if err = t.checkE2EECodec(loopers[0].Codec().MimeType); err != nil {
+		return "", err
+	}
+	t.seekLoopers(loopers)
+	dynacast := t.dynacastTrack()
 	// for video, publish three simulcast layers
 	for i, looper := range loopers {
 		layer := looper.ToLayer(livekit.VideoQuality(i))
@@ -214,27 +689,98 @@ func (t *LoadTester) PublishSimulcastTrack(name, resolution, codec string) (stri
 		if err != nil {
 			return "", err
 		}
-		if err := track.StartWrite(looper, nil); err != nil {
-			return "", err
+		// when ramping, only the lowest layer is sent from the start
+		if i == 0 || t.params.PublishRamp == 0 {
+			if err := track.StartWrite(t.layerProvider(looper, livekit.VideoQuality(i), dynacast), nil); err != nil {
+				return "", err
+			}
 		}
 		tracks = append(tracks, track)
 	}
+	backup, err := t.backupOptions(loopers[0].Codec().MimeType, resolution, true)
+	if err != nil {
+		return "", err
+	}
 
-	p, err := t.room.LocalParticipant.PublishSimulcastTrack(tracks, &lksdk.TrackPublicationOptions{
-		Name:   name,
-		Source: livekit.TrackSource_CAMERA,
-	})
+	opts := t.trackOptions(name)
+	opts.Source = livekit.TrackSource_CAMERA
+	p, err := t.room.LocalParticipant.PublishSimulcastTrack(tracks, opts, backup...)
 	if err != nil {
+		t.setState(stateError, statePublished, err)
 		return "", err
 	}
+	if dynacast != nil {
+		dynacast.published(p.SID())
+	}
+	t.setState(statePublished, p.SID(), nil)
+
+	if t.params.PublishRamp > 0 && len(tracks) > 1 {
+		go t.rampLayers(tracks, loopers, dynacast)
+	}
 
 	return p.SID(), nil
 }
 
+// seekLoopers moves all layers to the same random point in their files, so that thousands
+// of publishers don't send keyframes in lockstep
+func (t *LoadTester) seekLoopers(loopers []provider2.VideoLooper) {
+	if !t.params.RandomOffset {
+		return
+	}
+	fraction := rand.Float64()
+	for _, looper := range loopers {
+		looper.SeekToKeyframeAfter(fraction)
+	}
+}
+
+// rampLayers enables higher simulcast layers one at a time, emulating the bandwidth
+// probing real clients go through before sending at their target bitrate
+func (t *LoadTester) rampLayers(tracks []*lksdk.LocalTrack, loopers []provider2.VideoLooper, dynacast *dynacastTrack) {
+	step := max(t.params.PublishRamp/time.Duration(len(tracks)-1), minPublishRampStep)
+	ticker := time.NewTicker(step)
+	defer ticker.Stop()
+	for i := 1; i < len(tracks); i++ {
+		<-ticker.C
+		if !t.IsRunning() {
+			return
+		}
+		if err := tracks[i].StartWrite(t.layerProvider(loopers[i], livekit.VideoQuality(i), dynacast), nil); err != nil {
+			fmt.Println("could not enable simulcast layer", t.identity(), err)
+			return
+		}
+	}
+}
+
 func (t *LoadTester) getStats() *testerStats {
+	t.lock.Lock()
 	stats := &testerStats{
-		expectedTracks: t.params.expectedTracks,
-		trackStats:     make(map[string]*trackStats),
+		expectedTracks:        t.params.expectedTracks,
+		trackStats:            make(map[string]*trackStats),
+		resubscribes:          t.resubscribes,
+		speakerSwitches:       t.speakerSwitches,
+		layerSwitches:         t.layerSwitches,
+		pendingLayerSwitches:  len(t.pendingLayerSwitches),
+		reconnectAttempts:     t.reconnectAttempts,
+		reconnectFailures:     t.reconnectFailures,
+		reconnects:            t.reconnects,
+		unresumedTracks:       t.unresumedTracks,
+		latencies:             slices.Clone(t.latencies.samples),
+		latencyCount:          t.latencies.count,
+		latencyHist:           &t.latencyHist,
+		permissionUpdates:     slices.Clone(t.permissionUpdates.samples),
+		permissionUpdateCount: t.permissionUpdates.count,
+		data:                  t.getDataStats(),
+		dataSent:              t.dataSent.Load(),
+		dataErrors:            t.dataErrors.Load(),
+		sentLayers:            slices.Clone(t.sentLayers),
+		backupTracks:          t.backup.count(),
+		transientErrors:       t.transientErrors.Load(),
+		retries:               t.retries.Load(),
+	}
+	t.lock.Unlock()
+	if t.params.Subscribe {
+		stats.interactiveMeasured = true
+		stats.timeToInteractive, _ = t.interactive.timeToInteractive(t.params.expectAudio, t.params.expectVideo)
 	}
 	t.stats.Range(func(key, value interface{}) bool {
 		stats.trackStats[key.(string)] = value.(*trackStats)
@@ -243,16 +789,45 @@ func (t *LoadTester) getStats() *testerStats {
 	return stats
 }
 
+// Results summarizes the tracks the tester received so far, for callers driving testers
+// without a LoadTest
+func (t *LoadTester) Results() *TesterResults {
+	name := t.identity()
+	return getResults(map[string]*testerStats{name: t.getStats()}, []string{name}).Testers[0]
+}
+
+// Reset clears the media, latency and sent bitrate stats collected so far, along with the
+// tester's own counts of resubscribes, switches, reconnects and data. Tracks keep being
+// received, and are measured from now on. How the tester joined, its retries and its error,
+// are kept.
 func (t *LoadTester) Reset() {
-	stats := sync.Map{}
-	t.stats.Range(func(key, value interface{}) bool {
-		old := value.(*trackStats)
-		stats.Store(key, &trackStats{
-			trackID: old.trackID,
-		})
+	now := time.Now()
+	t.stats.Range(func(_, value interface{}) bool {
+		value.(*trackStats).reset(now)
 		return true
 	})
-	t.stats = &stats
+	t.lock.Lock()
+	t.latencies = latencySamples{}
+	t.permissionUpdates = latencySamples{}
+	t.resubscribes = nil
+	t.speakerSwitches = nil
+	t.layerSwitches = nil
+	t.reconnectAttempts = 0
+	t.reconnectFailures = 0
+	t.reconnects = nil
+	t.unresumedTracks = nil
+	for _, s := range t.dataSenders {
+		s.reset()
+	}
+	for _, l := range t.sentLayers {
+		l.reset()
+	}
+	t.lock.Unlock()
+	t.latencyHist.reset()
+	t.dataSent.Store(0)
+	t.dataErrors.Store(0)
+	t.sentBytes.Store(0)
+	t.speakerUpdates.Store(0)
 }
 
 func (t *LoadTester) Stop() {
@@ -261,14 +836,26 @@ func (t *LoadTester) Stop() {
 	}
 	t.running.Store(false)
 	t.room.Disconnect()
+	if t.signalTap != nil {
+		t.signalTap.close()
+	}
+	t.setState(stateClosed, "", nil)
 }
 
 func (t *LoadTester) numToSubscribe() int {
 	if !t.params.Subscribe {
 		return 0
 	}
-	switch t.params.Layout {
-	case LayoutSpeaker:
+	if t.params.SubscribeAll || t.features().AutoSubscribe {
+		return math.MaxInt
+	}
+	return layoutSlots(t.params.Layout)
+}
+
+// layoutSlots is the number of participants visible at once in a layout
+func layoutSlots(layout Layout) int {
+	switch layout {
+	case LayoutSpeaker, LayoutSpeakerFollow:
 		return 6
 	case LayoutGrid3x3:
 		return 9
@@ -281,8 +868,34 @@ func (t *LoadTester) numToSubscribe() int {
 	}
 }
 
+func (t *LoadTester) onParticipantConnected(rp *lksdk.RemoteParticipant) {
+	t.lock.Lock()
+	t.seenParticipants[rp.Identity()] = true
+	t.lock.Unlock()
+}
+
 func (t *LoadTester) onTrackPublished(publication *lksdk.RemoteTrackPublication, rp *lksdk.RemoteParticipant) {
+	if publication.Kind() == lksdk.TrackKindVideo && publication.Source() != livekit.TrackSource_SCREEN_SHARE &&
+		rp.Attributes()[DynacastCheckAttribute] == dynacastUnwatched {
+		// left for the server to pause
+		return
+	}
 	t.lock.Lock()
+	t.seenParticipants[rp.Identity()] = true
+	if _, ok := t.publishedAt[publication.SID()]; !ok {
+		t.publishedAt[publication.SID()] = time.Now()
+	}
+	if t.params.Subscribe && t.params.SubscribeCount > 0 {
+		subscribe, evicted := t.selectTrack(publication, rp)
+		t.lock.Unlock()
+		if evicted != nil {
+			_ = t.unsubscribe(evicted)
+		}
+		if subscribe {
+			publication.SetSubscribed(true)
+		}
+		return
+	}
 	if len(t.subscribedParticipants) >= t.numToSubscribe() && t.subscribedParticipants[rp.Identity()] == nil {
 		t.lock.Unlock()
 		return
@@ -306,13 +919,24 @@ func (t *LoadTester) onTrackSubscribed(track *webrtc.TrackRemote, pub *lksdk.Rem
 			}
 		}
 	}
+	publishedAt := t.publishedAt[pub.SID()]
 	t.lock.Unlock()
 
 	s := &trackStats{
-		trackID: track.ID(),
-		kind:    pub.Kind(),
+		trackID:     track.ID(),
+		kind:        pub.Kind(),
+		publishedAt: publishedAt,
+	}
+	// keep accumulated stats when resubscribing to a track
+	value, _ := t.stats.LoadOrStore(track.ID(), s)
+	if pub.Kind() == lksdk.TrackKindVideo {
+		// a new subscription starts measuring freezes afresh
+		meter := &freezeMeter{threshold: t.freezeThreshold()}
+		meter.paused.set(freezePauseMuted, pub.IsMuted())
+		value.(*trackStats).freezeMeter.Store(meter)
 	}
-	t.stats.Store(track.ID(), s)
+	t.onResubscribed(pub)
+	t.setState(stateSubscribed, pub.SID(), nil)
 	fmt.Println("subscribed to track", t.room.LocalParticipant.Identity(), pub.SID(), pub.Kind(), fmt.Sprintf("%d/%d", numSubscribed, numTotal))
 
 	// consume track
@@ -353,21 +977,27 @@ func (t *LoadTester) onTrackSubscribed(track *webrtc.TrackRemote, pub *lksdk.Rem
 		if qualityCounts[livekit.VideoQuality_LOW] < 25 {
 			targetQuality = livekit.VideoQuality_LOW
 		}
+	case LayoutSpeakerFollow:
+		if rp.Identity() == t.activeSpeaker {
+			targetQuality = livekit.VideoQuality_HIGH
+		} else {
+			targetQuality = livekit.VideoQuality_LOW
+		}
+	}
+	if !t.features().AdaptiveStream {
+		// without adaptive stream, clients receive every video at full quality
+		targetQuality = livekit.VideoQuality_HIGH
 	}
 	t.trackQualities[rp.SID()] = targetQuality
 	t.lock.Unlock()
 
-	// switch quality and/or enable/disable
-	switch targetQuality {
-	case livekit.VideoQuality_HIGH:
-		pub.SetVideoDimensions(highWidth, highHeight)
-	case livekit.VideoQuality_MEDIUM:
-		pub.SetVideoDimensions(mediumWidth, mediumHeight)
-	case livekit.VideoQuality_LOW:
-		pub.SetVideoDimensions(lowWidth, lowHeight)
-	case livekit.VideoQuality_OFF:
-		pub.SetEnabled(false)
+	if t.params.demand != nil {
+		t.params.demand.set(rp.Identity(), t.identity(), targetQuality)
 	}
+
+	// switch quality and/or enable/disable
+	setVideoQuality(pub, targetQuality)
+	t.pauseFreezes(pub, freezePauseDisabled, !pub.IsEnabled())
 }
 
 func (t *LoadTester) consumeTrack(track *webrtc.TrackRemote, pub *lksdk.RemoteTrackPublication, rp *lksdk.RemoteParticipant) {
@@ -379,25 +1009,31 @@ func (t *LoadTester) consumeTrack(track *webrtc.TrackRemote, pub *lksdk.RemoteTr
 		}
 	}()
 
-	var dpkt rtp.Depacketizer
-	isVideo := false
-	if pub.Kind() == lksdk.TrackKindVideo {
-		dpkt = &codecs.H264Packet{}
-		isVideo = true
-	} else {
-		dpkt = &codecs.OpusPacket{}
+	isVideo := pub.Kind() == lksdk.TrackKindVideo
+	value, _ := t.stats.Load(track.ID())
+	ts := value.(*trackStats)
+	if ts.startedAt.Load().IsZero() {
+		ts.startedAt.Store(time.Now())
 	}
-	sb := samplebuilder.New(100, dpkt, track.Codec().ClockRate, samplebuilder.WithPacketDroppedHandler(func() {
-		value, _ := t.stats.Load(track.ID())
-		ts := value.(*trackStats)
-		ts.dropped.Inc()
+	mimeType := track.Codec().MimeType
+	ts.mimeType.Store(mimeType)
+	consumer := newTrackConsumer(ts, mimeType, track.Codec().ClockRate, isVideo, t.maxMediaGap(), func() {
 		if isVideo {
 			rp.WritePLI(track.SSRC())
 		}
-	}))
-	value, _ := t.stats.Load(track.ID())
-	ts := value.(*trackStats)
-	ts.startedAt.Store(time.Now())
+	})
+	consumer.freezes = ts.freezeMeter.Load()
+	if consumer.freezes != nil {
+		defer func() {
+			if stall := consumer.freezes.end(time.Now()); stall > 0 {
+				ts.recordFreeze(stall)
+			}
+		}()
+	}
+	var decryptor *frameDecryptor
+	if t.params.E2EEKey != "" && pub.TrackInfo().GetEncryption() == livekit.Encryption_GCM {
+		decryptor = newFrameDecryptor(cryptorFor(t.params.E2EEKey), mimeType, ts)
+	}
 	for {
 		pkt, _, err := track.ReadRTP()
 		if err != nil {
@@ -406,13 +1042,118 @@ func (t *LoadTester) consumeTrack(track *webrtc.TrackRemote, pub *lksdk.RemoteTr
 		if pkt == nil {
 			continue
 		}
-		sb.Push(pkt)
+		if !isVideo && t.params.AudioPacketLoss > 0 && rand.Float64() < t.params.AudioPacketLoss {
+			continue
+		}
 
-		for _, pkt := range sb.PopPackets() {
-			value, _ := t.stats.Load(track.ID())
-			ts := value.(*trackStats)
-			ts.bytes.Add(int64(len(pkt.Payload)))
-			ts.packets.Inc()
+		packets, firstFrameAt := consumer.push(pkt, time.Now())
+		if !firstFrameAt.IsZero() {
+			t.interactive.frame(pub.Kind(), rp.Identity(), firstFrameAt)
+		}
+		for _, pkt := range packets {
+			if isVideo && isKeyframe(mimeType, pkt.Payload) {
+				t.onResubscribeKeyframe(pub.SID())
+				t.onSpeakerKeyframe(pub.SID())
+				t.onLayerKeyframe(pub.SID())
+			}
+			if t.awaitingMedia.CompareAndSwap(true, false) {
+				t.onReconnectMedia()
+			}
+			if decryptor != nil {
+				decryptor.push(pkt)
+			}
+		}
+	}
+}
+
+// trackConsumer turns the RTP packets received on a track into its stats
+type trackConsumer struct {
+	ts      *trackStats
+	isVideo bool
+	maxGap  time.Duration
+	sb      *samplebuilder.SampleBuilder
+	seq     *sequenceTracker
+	jitter  *jitterMeter
+	// measures video freezes, nil for audio
+	freezes *freezeMeter
+}
+
+func newTrackConsumer(ts *trackStats, mimeType string, clockRate uint32, isVideo bool, maxGap time.Duration, onDropped func()) *trackConsumer {
+	var dpkt rtp.Depacketizer
+	if isVideo {
+		dpkt = &codecs.H264Packet{}
+		if strings.EqualFold(mimeType, webrtc.MimeTypeAV1) {
+			dpkt = &codecs.AV1Depacketizer{}
+		}
+	} else {
+		dpkt = &codecs.OpusPacket{}
+	}
+	return &trackConsumer{
+		ts:      ts,
+		isVideo: isVideo,
+		maxGap:  maxGap,
+		sb: samplebuilder.New(sampleBuilderMaxLate, dpkt, clockRate, samplebuilder.WithPacketDroppedHandler(func() {
+			ts.dropped.Inc()
+			onDropped()
+		})),
+		seq:    &sequenceTracker{},
+		jitter: &jitterMeter{clockRate: clockRate},
+	}
+}
+
+// push records a packet received at now, and returns the packets of the frames it completed,
+// along with the time of the first frame of the track if it is one of them
+func (c *trackConsumer) push(pkt *rtp.Packet, now time.Time) ([]*rtp.Packet, time.Time) {
+	ts := c.ts
+	ts.recordPacket(now, c.maxGap)
+	if d, ok := c.jitter.next(now, pkt.Timestamp); ok {
+		ts.jitter.record(d)
+	}
+	if gap := c.seq.next(pkt.SequenceNumber); gap > 0 {
+		ts.burstLoss.record(gap)
+		// every gap in audio has to be concealed by the decoder. Each audio packet is a frame of
+		// its own, so the sample builder doesn't drop any and the gap is the loss.
+		if !c.isVideo {
+			ts.concealmentEvents.Inc()
+			ts.concealedPackets.Add(int64(gap))
+			ts.dropped.Add(int64(gap))
+		}
+	}
+	c.sb.Push(pkt)
+
+	packets := c.sb.PopPackets()
+	return packets, c.record(packets, now)
+}
+
+// flush records the frames the sample builder still holds back waiting for a missing packet,
+// once no more packets will arrive
+func (c *trackConsumer) flush(now time.Time) {
+	for {
+		packets := c.sb.ForcePopPackets()
+		if len(packets) == 0 {
+			return
+		}
+		c.record(packets, now)
+	}
+}
+
+// record counts the packets of completed frames, and returns the time of the first frame of
+// the track if it is one of them
+func (c *trackConsumer) record(packets []*rtp.Packet, now time.Time) time.Time {
+	ts := c.ts
+	var firstFrameAt time.Time
+	for _, pkt := range packets {
+		if ts.firstFrameAt.Load().IsZero() {
+			firstFrameAt = time.Now()
+			ts.firstFrameAt.Store(firstFrameAt)
+		}
+		ts.bytes.Add(int64(len(pkt.Payload)))
+		ts.packets.Inc()
+		if c.freezes != nil {
+			if stall := c.freezes.next(pkt.Timestamp, now); stall > 0 {
+				ts.recordFreeze(stall)
+			}
 		}
 	}
+	return firstFrameAt
 }
@@ -0,0 +1,206 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+const (
+	// coordinatorPort is where the coordinator of a Kubernetes run listens for workers
+	coordinatorPort = 7890
+	// manifestScenarioDir is where the scenario ConfigMap is mounted in the coordinator
+	manifestScenarioDir = "/etc/lk"
+)
+
+// ManifestParams describes a distributed load test to run as Kubernetes jobs
+type ManifestParams struct {
+	Name      string
+	Namespace string
+	Image     string
+	Workers   int
+	// URL of the LiveKit server under test
+	URL string
+	// existing secret holding LIVEKIT_API_KEY and LIVEKIT_API_SECRET
+	CredentialsSecret string
+	WorkerCPU         string
+	WorkerMemory      string
+	// passed to the coordinator's lk load-test command
+	Args []string
+	// scenario file run by the coordinator with --scenario, none when empty
+	Scenario []byte
+}
+
+var manifestTemplate = template.Must(template.New("manifest").
+	Funcs(template.FuncMap{"quote": strconv.Quote, "indent": indent}).
+	Parse(`apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Name }}
+{{- if .Namespace }}
+  namespace: {{ .Namespace }}
+{{- end }}
+data:
+  LIVEKIT_URL: {{ quote .URL }}
+{{- if .Scenario }}
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ .Name }}-scenario
+{{- if .Namespace }}
+  namespace: {{ .Namespace }}
+{{- end }}
+data:
+  scenario.yaml: |
+{{ indent 4 .Scenario }}
+{{- end }}
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: {{ .Name }}-coordinator
+{{- if .Namespace }}
+  namespace: {{ .Namespace }}
+{{- end }}
+spec:
+  selector:
+    app.kubernetes.io/name: {{ .Name }}
+    app.kubernetes.io/component: coordinator
+  ports:
+    - port: {{ .Port }}
+      targetPort: {{ .Port }}
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: {{ .Name }}-coordinator
+{{- if .Namespace }}
+  namespace: {{ .Namespace }}
+{{- end }}
+spec:
+  backoffLimit: 0
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/name: {{ .Name }}
+        app.kubernetes.io/component: coordinator
+    spec:
+      restartPolicy: Never
+      containers:
+        - name: coordinator
+          image: {{ .Image }}
+          args:
+            - load-test
+            - --coordinator
+            - ":{{ .Port }}"
+            - --workers
+            - "{{ .Workers }}"
+{{- if .Scenario }}
+            - --scenario
+            - {{ .ScenarioDir }}/scenario.yaml
+{{- end }}
+{{- range .Args }}
+            - {{ quote . }}
+{{- end }}
+          ports:
+            - containerPort: {{ .Port }}
+{{- if .Scenario }}
+          volumeMounts:
+            - name: scenario
+              mountPath: {{ .ScenarioDir }}
+              readOnly: true
+{{- end }}
+          envFrom:
+            - configMapRef:
+                name: {{ .Name }}
+            - secretRef:
+                name: {{ .CredentialsSecret }}
+          resources:
+            requests:
+              cpu: 500m
+              memory: 512Mi
+{{- if .Scenario }}
+      volumes:
+        - name: scenario
+          configMap:
+            name: {{ .Name }}-scenario
+{{- end }}
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: {{ .Name }}-worker
+{{- if .Namespace }}
+  namespace: {{ .Namespace }}
+{{- end }}
+spec:
+  parallelism: {{ .Workers }}
+  completions: {{ .Workers }}
+  # workers that start before the coordinator is reachable are restarted
+  backoffLimit: {{ .BackoffLimit }}
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/name: {{ .Name }}
+        app.kubernetes.io/component: worker
+    spec:
+      restartPolicy: OnFailure
+      containers:
+        - name: worker
+          image: {{ .Image }}
+          args:
+            - load-test
+            - --worker
+            - --coordinator-url
+            - "ws://{{ .Name }}-coordinator:{{ .Port }}"
+          envFrom:
+            - configMapRef:
+                name: {{ .Name }}
+            - secretRef:
+                name: {{ .CredentialsSecret }}
+          resources:
+            requests:
+              cpu: {{ quote .WorkerCPU }}
+              memory: {{ quote .WorkerMemory }}
+            limits:
+              memory: {{ quote .WorkerMemory }}
+`))
+
+// WriteK8sManifest writes the ConfigMaps, Service and Jobs that run a coordinator and its workers
+func WriteK8sManifest(w io.Writer, p ManifestParams) error {
+	return manifestTemplate.Execute(w, struct {
+		ManifestParams
+		Scenario     string
+		ScenarioDir  string
+		Port         int
+		BackoffLimit int
+	}{
+		ManifestParams: p,
+		Scenario:       string(p.Scenario),
+		ScenarioDir:    manifestScenarioDir,
+		Port:           coordinatorPort,
+		BackoffLimit:   p.Workers * 3,
+	})
+}
+
+// indent prefixes every line of s with n spaces, for a YAML block scalar
+func indent(n int, s string) string {
+	prefix := strings.Repeat(" ", n)
+	return prefix + strings.ReplaceAll(strings.TrimRight(s, "\n"), "\n", "\n"+prefix)
+}
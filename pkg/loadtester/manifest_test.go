@@ -0,0 +1,96 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestWriteK8sManifest(t *testing.T) {
+	kinds, jobs, _ := writeTestManifest(t, ManifestParams{
+		Name:              "lt",
+		Namespace:         "load",
+		Image:             "livekit/livekit-cli:latest",
+		Workers:           20,
+		URL:               "wss://example.livekit.cloud",
+		CredentialsSecret: "creds",
+		WorkerCPU:         "4",
+		WorkerMemory:      "8Gi",
+		Args:              []string{"--subscribers", "500"},
+	})
+	require.Equal(t, []string{"ConfigMap", "Service", "Job", "Job"}, kinds)
+
+	worker := jobs["lt-worker"]
+	require.Equal(t, 20, worker["parallelism"])
+	require.Equal(t, 20, worker["completions"])
+
+	container := jobs["lt-coordinator"]["template"].(map[string]any)["spec"].(map[string]any)["containers"].([]any)[0].(map[string]any)
+	require.Equal(t, []any{"load-test", "--coordinator", ":7890", "--workers", "20", "--subscribers", "500"}, container["args"])
+}
+
+func TestWriteK8sManifestScenario(t *testing.T) {
+	scenario := "phases:\n  - kind: hold\n    duration: 1m0s\n"
+	kinds, jobs, data := writeTestManifest(t, ManifestParams{
+		Name:              "lt",
+		Image:             "livekit/livekit-cli:latest",
+		Workers:           2,
+		URL:               "wss://example.livekit.cloud",
+		CredentialsSecret: "creds",
+		WorkerCPU:         "4",
+		WorkerMemory:      "8Gi",
+		Args:              []string{"--room-count", "2"},
+		Scenario:          []byte(scenario),
+	})
+	require.Equal(t, []string{"ConfigMap", "ConfigMap", "Service", "Job", "Job"}, kinds)
+	require.Equal(t, scenario, data["lt-scenario"]["scenario.yaml"])
+
+	pod := jobs["lt-coordinator"]["template"].(map[string]any)["spec"].(map[string]any)
+	container := pod["containers"].([]any)[0].(map[string]any)
+	require.Equal(t, []any{"load-test", "--coordinator", ":7890", "--workers", "2",
+		"--scenario", "/etc/lk/scenario.yaml", "--room-count", "2"}, container["args"])
+	require.Equal(t, []any{map[string]any{"name": "scenario", "mountPath": "/etc/lk", "readOnly": true}}, container["volumeMounts"])
+	require.Equal(t, []any{map[string]any{"name": "scenario", "configMap": map[string]any{"name": "lt-scenario"}}}, pod["volumes"])
+}
+
+// writeTestManifest returns the kinds of the manifest objects, the Job specs and the ConfigMap data by name
+func writeTestManifest(t *testing.T, p ManifestParams) ([]string, map[string]map[string]any, map[string]map[string]any) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteK8sManifest(&buf, p))
+
+	dec := yaml.NewDecoder(strings.NewReader(buf.String()))
+	var kinds []string
+	jobs := map[string]map[string]any{}
+	data := map[string]map[string]any{}
+	for {
+		var obj map[string]any
+		if err := dec.Decode(&obj); err != nil {
+			break
+		}
+		kinds = append(kinds, obj["kind"].(string))
+		name := obj["metadata"].(map[string]any)["name"].(string)
+		switch obj["kind"] {
+		case "Job":
+			jobs[name] = obj["spec"].(map[string]any)
+		case "ConfigMap":
+			data[name] = obj["data"].(map[string]any)
+		}
+	}
+	return kinds, jobs, data
+}
@@ -0,0 +1,352 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+var (
+	matrixMedia       = []string{"audio", "video"}
+	matrixCodecs      = []string{"h264", "vp8", "vp9", "av1"}
+	matrixResolutions = []string{"high", "medium", "low"}
+)
+
+// Matrix is the set of test cases made of every combination of its dimensions. Empty
+// dimensions take the value of the run's params.
+type Matrix struct {
+	// Media is what the publishers send, audio or video. Codecs and resolutions only apply to video.
+	Media       []string
+	Codecs      []string
+	Resolutions []string
+	Publishers  []int
+	Subscribers []int
+}
+
+// MatrixCase is one combination of a Matrix
+type MatrixCase struct {
+	Media       string
+	Codec       string
+	Resolution  string
+	Publishers  int
+	Subscribers int
+}
+
+func (c MatrixCase) String() string {
+	if c.Media == "audio" {
+		return fmt.Sprintf("audio, %d pub, %d sub", c.Publishers, c.Subscribers)
+	}
+	codec := c.Codec
+	if codec == "" {
+		codec = "all codecs"
+	}
+	return fmt.Sprintf("%s %s, %d pub, %d sub", codec, c.Resolution, c.Publishers, c.Subscribers)
+}
+
+// params returns the params of a run of the case
+func (c MatrixCase) params(params Params) Params {
+	if c.Media == "audio" {
+		params.AudioPublishers = c.Publishers
+		params.VideoPublishers = 0
+	} else {
+		params.VideoCodec = c.Codec
+		params.VideoResolution = c.Resolution
+		params.VideoPublishers = c.Publishers
+	}
+	params.Subscribers = c.Subscribers
+	if params.Duration == 0 {
+		params.Duration = 15 * time.Second
+	}
+	return params
+}
+
+// ParseMatrix parses a --matrix value, dimensions separated by semicolons, each a key and a comma
+// separated list, e.g. "codecs=vp8,h264;resolutions=high,medium;subscribers=10,100"
+func ParseMatrix(s string) (*Matrix, error) {
+	m := &Matrix{}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, list, ok := strings.Cut(part, "=")
+		key = strings.TrimSpace(key)
+		if !ok || strings.TrimSpace(list) == "" {
+			return nil, fmt.Errorf("invalid matrix dimension %q, expected KEY=VALUE,...", part)
+		}
+		if seen[key] {
+			return nil, fmt.Errorf("matrix dimension %q is given twice", key)
+		}
+		seen[key] = true
+
+		var values []string
+		for _, v := range strings.Split(list, ",") {
+			v = strings.ToLower(strings.TrimSpace(v))
+			if slices.Contains(values, v) {
+				return nil, fmt.Errorf("%s lists %q twice", key, v)
+			}
+			values = append(values, v)
+		}
+		var err error
+		switch key {
+		case "media":
+			m.Media, err = matrixValues(key, values, matrixMedia)
+		case "codecs":
+			m.Codecs, err = matrixValues(key, values, matrixCodecs)
+		case "resolutions":
+			m.Resolutions, err = matrixValues(key, values, matrixResolutions)
+		case "publishers":
+			m.Publishers, err = matrixCounts(key, values)
+		case "subscribers":
+			m.Subscribers, err = matrixCounts(key, values)
+		default:
+			err = fmt.Errorf("unknown matrix dimension %q, expected media, codecs, resolutions, publishers or subscribers", key)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(seen) == 0 {
+		return nil, fmt.Errorf("matrix has no dimensions")
+	}
+	return m, nil
+}
+
+// String formats the matrix the way ParseMatrix reads it
+func (m *Matrix) String() string {
+	var dims []string
+	if len(m.Media) > 0 {
+		dims = append(dims, "media="+strings.Join(m.Media, ","))
+	}
+	if len(m.Codecs) > 0 {
+		dims = append(dims, "codecs="+strings.Join(m.Codecs, ","))
+	}
+	if len(m.Resolutions) > 0 {
+		dims = append(dims, "resolutions="+strings.Join(m.Resolutions, ","))
+	}
+	formatCounts := func(counts []int) string {
+		s := make([]string, 0, len(counts))
+		for _, n := range counts {
+			s = append(s, strconv.Itoa(n))
+		}
+		return strings.Join(s, ",")
+	}
+	if len(m.Publishers) > 0 {
+		dims = append(dims, "publishers="+formatCounts(m.Publishers))
+	}
+	if len(m.Subscribers) > 0 {
+		dims = append(dims, "subscribers="+formatCounts(m.Subscribers))
+	}
+	return strings.Join(dims, ";")
+}
+
+func matrixValues(key string, values, allowed []string) ([]string, error) {
+	for _, v := range values {
+		if !slices.Contains(allowed, v) {
+			return nil, fmt.Errorf("invalid %s value %q, expected one of %s", key, v, strings.Join(allowed, ", "))
+		}
+	}
+	return values, nil
+}
+
+func matrixCounts(key string, values []string) ([]int, error) {
+	counts := make([]int, 0, len(values))
+	for _, v := range values {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid %s value %q, expected a positive number", key, v)
+		}
+		counts = append(counts, n)
+	}
+	return counts, nil
+}
+
+// Cases returns every combination of the matrix, in the order its dimensions vary slowest to
+// fastest: media, codecs, resolutions, publishers, subscribers. Missing dimensions are taken from
+// params, and publish video with the embedded clips of all codecs when there's no --video-codec.
+func (m *Matrix) Cases(params Params) ([]MatrixCase, error) {
+	media := m.Media
+	if len(media) == 0 {
+		media = []string{"video"}
+	}
+	if !slices.Contains(media, "video") && (len(m.Codecs) > 0 || len(m.Resolutions) > 0) {
+		return nil, fmt.Errorf("matrix codecs and resolutions only apply to video media")
+	}
+	codecs := m.Codecs
+	if len(codecs) == 0 {
+		codecs = []string{params.VideoCodec}
+	}
+	resolutions := m.Resolutions
+	if len(resolutions) == 0 {
+		resolutions = []string{params.VideoResolution}
+	}
+	subscribers := m.Subscribers
+	if len(subscribers) == 0 {
+		if params.Subscribers <= 0 {
+			return nil, fmt.Errorf("matrix needs subscribers, or --subscribers")
+		}
+		subscribers = []int{params.Subscribers}
+	}
+
+	var cases []MatrixCase
+	for _, medium := range media {
+		publishers := m.Publishers
+		if len(publishers) == 0 {
+			count, flag := params.VideoPublishers, "--video-publishers"
+			if medium == "audio" {
+				count, flag = params.AudioPublishers, "--audio-publishers"
+			}
+			if count <= 0 {
+				return nil, fmt.Errorf("matrix needs publishers, or %s", flag)
+			}
+			publishers = []int{count}
+		}
+		if medium == "audio" {
+			for _, pubs := range publishers {
+				for _, subs := range subscribers {
+					cases = append(cases, MatrixCase{Media: medium, Publishers: pubs, Subscribers: subs})
+				}
+			}
+			continue
+		}
+		for _, codec := range codecs {
+			for _, resolution := range resolutions {
+				for _, pubs := range publishers {
+					for _, subs := range subscribers {
+						cases = append(cases, MatrixCase{
+							Media:       medium,
+							Codec:       codec,
+							Resolution:  resolution,
+							Publishers:  pubs,
+							Subscribers: subs,
+						})
+					}
+				}
+			}
+		}
+	}
+	return cases, nil
+}
+
+// MatrixResults are the totals of one matrix case
+type MatrixResults struct {
+	MatrixCase
+	Results *TesterResults
+	Failed  int
+}
+
+// RunMatrix runs every case of the matrices for the test's duration, one after the other,
+// and prints a table comparing them. Every case is checked before the first one starts.
+func (t *LoadTest) RunMatrix(ctx context.Context, matrices ...*Matrix) error {
+	var cases []MatrixCase
+	for _, m := range matrices {
+		mCases, err := m.Cases(t.Params)
+		if err != nil {
+			return err
+		}
+		cases = append(cases, mCases...)
+	}
+	for _, c := range cases {
+		caseParams := c.params(t.Params)
+		if err := validateParams(caseParams); err != nil {
+			return fmt.Errorf("matrix case %s: %w", c, err)
+		}
+		if err := checkUsagePolicy(caseParams); err != nil {
+			return fmt.Errorf("matrix case %s: %w", c, err)
+		}
+		if err := checkMediaFiles(caseParams); err != nil {
+			return fmt.Errorf("matrix case %s: %w", c, err)
+		}
+	}
+
+	closeLogs, err := t.openLogs()
+	if err != nil {
+		return err
+	}
+	defer closeLogs()
+
+	stopStatus, err := t.serveStatus(t.Params.MetricsAddr)
+	if err != nil {
+		return err
+	}
+	defer stopStatus()
+
+	var results []*MatrixResults
+	for i, c := range cases {
+		fmt.Printf("\nRunning case %d of %d: %s\n", i+1, len(cases), c)
+
+		stats, err := t.run(ctx, c.params(t.Params))
+		if err != nil {
+			return err
+		}
+		r := getResults(stats, reportNames(stats, t.Params.ReportRoles))
+		results = append(results, &MatrixResults{MatrixCase: c, Results: r.Total, Failed: r.FailedTesters})
+		if ctx.Err() != nil {
+			fmt.Println("\nMatrix interrupted, reporting partial results")
+			break
+		}
+	}
+
+	printMatrixResults(results)
+	return nil
+}
+
+func printMatrixResults(results []*MatrixResults) {
+	if len(results) == 0 {
+		return
+	}
+	table := util.CreateTable().
+		Headers("Media", "Codec", "Resolution", "Pubs", "Subs", "Tracks", "Bitrate", "Pkt. Loss", "Latency p50", "Jitter p50", "First Frame", "Failed")
+	for _, r := range results {
+		total := r.Results
+		codec, resolution := r.Codec, r.Resolution
+		if r.Media == "audio" {
+			codec, resolution = " - ", " - "
+		} else if codec == "" {
+			codec = "all"
+		}
+		table.Row(
+			r.Media,
+			codec,
+			resolution,
+			strconv.Itoa(r.Publishers),
+			strconv.Itoa(r.Subscribers),
+			fmt.Sprintf("%d/%d", total.Tracks, total.ExpectedTracks),
+			formatBitrate(int64(total.Bitrate/8), time.Second),
+			fmt.Sprintf("%.2f%%", total.LossRate*100),
+			formatMatrixMs(total.LatencyP50Ms),
+			formatMatrixMs(total.JitterP50Ms),
+			formatMatrixMs(total.AvgFirstFrameMs),
+			strconv.Itoa(r.Failed),
+		)
+	}
+	fmt.Println("\nMatrix results:")
+	fmt.Println(table)
+}
+
+func formatMatrixMs(ms float64) string {
+	if ms == 0 {
+		return " - "
+	}
+	return fmt.Sprintf("%.1fms", ms)
+}
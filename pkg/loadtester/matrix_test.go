@@ -0,0 +1,132 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMatrix(t *testing.T) {
+	m, err := ParseMatrix("codecs=vp8, H264;resolutions=high,medium; subscribers=10,100")
+	require.NoError(t, err)
+	require.Equal(t, &Matrix{
+		Codecs:      []string{"vp8", "h264"},
+		Resolutions: []string{"high", "medium"},
+		Subscribers: []int{10, 100},
+	}, m)
+
+	for _, s := range []string{
+		"",
+		"codecs",
+		"codecs=",
+		"codecs=vp8;codecs=h264",
+		"codecs=vp8,vp8",
+		"codecs=theora",
+		"resolutions=ultra",
+		"publishers=0",
+		"subscribers=many",
+		"layouts=3x3",
+		"media=screen",
+	} {
+		_, err := ParseMatrix(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestMatrixCases(t *testing.T) {
+	m, err := ParseMatrix("codecs=vp8,h264;resolutions=high,low")
+	require.NoError(t, err)
+	params := Params{VideoResolution: "high", VideoPublishers: 2, Subscribers: 5}
+	cases, err := m.Cases(params)
+	require.NoError(t, err)
+	require.Equal(t, []MatrixCase{
+		{Media: "video", Codec: "vp8", Resolution: "high", Publishers: 2, Subscribers: 5},
+		{Media: "video", Codec: "vp8", Resolution: "low", Publishers: 2, Subscribers: 5},
+		{Media: "video", Codec: "h264", Resolution: "high", Publishers: 2, Subscribers: 5},
+		{Media: "video", Codec: "h264", Resolution: "low", Publishers: 2, Subscribers: 5},
+	}, cases)
+
+	m, err = ParseMatrix("publishers=1,3")
+	require.NoError(t, err)
+	cases, err = m.Cases(params)
+	require.NoError(t, err)
+	require.Equal(t, "all codecs high, 1 pub, 5 sub", cases[0].String())
+	params.VideoCodec = "vp9"
+	cases, err = m.Cases(params)
+	require.NoError(t, err)
+	require.Len(t, cases, 2)
+	require.Equal(t, "vp9 high, 3 pub, 5 sub", cases[1].String())
+
+	params.Subscribers = 0
+	_, err = m.Cases(params)
+	require.ErrorContains(t, err, "subscribers")
+}
+
+func TestMatrixMedia(t *testing.T) {
+	m, err := ParseMatrix("media=audio,video;subscribers=10")
+	require.NoError(t, err)
+	params := Params{VideoCodec: "vp8", VideoResolution: "high", AudioPublishers: 3, VideoPublishers: 2}
+	cases, err := m.Cases(params)
+	require.NoError(t, err)
+	require.Equal(t, []MatrixCase{
+		{Media: "audio", Publishers: 3, Subscribers: 10},
+		{Media: "video", Codec: "vp8", Resolution: "high", Publishers: 2, Subscribers: 10},
+	}, cases)
+	require.Equal(t, "audio, 3 pub, 10 sub", cases[0].String())
+
+	audio := cases[0].params(params)
+	require.Equal(t, 3, audio.AudioPublishers)
+	require.Zero(t, audio.VideoPublishers)
+	require.Equal(t, 10, audio.Subscribers)
+
+	m, err = ParseMatrix("media=audio;codecs=vp8")
+	require.NoError(t, err)
+	_, err = m.Cases(params)
+	require.ErrorContains(t, err, "video")
+
+	m, err = ParseMatrix("media=audio")
+	require.NoError(t, err)
+	_, err = m.Cases(Params{Subscribers: 1})
+	require.ErrorContains(t, err, "--audio-publishers")
+}
+
+func TestStandardSuite(t *testing.T) {
+	var cases []MatrixCase
+	for i, m := range StandardSuite() {
+		require.Equal(t, standardSuite[i], m.String())
+		mCases, err := m.Cases(Params{})
+		require.NoError(t, err)
+		cases = append(cases, mCases...)
+	}
+	require.Len(t, cases, 11)
+	require.Equal(t, MatrixCase{Media: "audio", Publishers: 100, Subscribers: 50}, cases[5])
+	require.Equal(t, MatrixCase{Media: "video", Publishers: 1, Subscribers: 1000}, cases[10])
+}
+
+func TestRunMatrixChecksEveryCase(t *testing.T) {
+	m, err := ParseMatrix("subscribers=10,100")
+	require.NoError(t, err)
+	test := NewLoadTest(Params{
+		VideoPublishers: 1,
+		TesterParams:    TesterParams{URL: "wss://project.livekit.cloud"},
+	})
+	// the first case is within the policy, the run must still be refused before it starts
+	err = test.RunMatrix(context.Background(), m)
+	require.ErrorContains(t, err, "100 sub")
+	require.ErrorContains(t, err, "acceptable use policy")
+}
@@ -0,0 +1,124 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/pion/webrtc/v4/pkg/media"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+// sentCounter counts the media bytes a publisher writes
+type sentCounter struct {
+	lksdk.SampleProvider
+	tester *LoadTester
+}
+
+func (c *sentCounter) NextSample(ctx context.Context) (media.Sample, error) {
+	sample, err := c.SampleProvider.NextSample(ctx)
+	if err == nil {
+		c.tester.sentBytes.Add(int64(len(sample.Data)))
+	}
+	return sample, err
+}
+
+// countSent wraps a published provider with the send counter, and checks that it keeps up with real time
+func (t *LoadTester) countSent(provider lksdk.SampleProvider) lksdk.SampleProvider {
+	provider = &sentCounter{SampleProvider: provider, tester: t}
+	if t.params.lag != nil {
+		provider = &pacedProvider{SampleProvider: provider, name: t.params.name, lag: t.params.lag}
+	}
+	return provider
+}
+
+var metricKinds = []lksdk.TrackKind{lksdk.TrackKindAudio, lksdk.TrackKindVideo}
+
+type trackKindMetrics struct {
+	bytes   int64
+	packets int64
+	dropped int64
+}
+
+// serveMetrics writes live test metrics in the Prometheus text exposition format
+func (t *LoadTest) serveMetrics(w http.ResponseWriter, _ *http.Request) {
+	report := t.status.report()
+	t.status.lock.Lock()
+	connectErrs, publishErrs := t.status.connectErrs, t.status.publishErrs
+	t.status.lock.Unlock()
+
+	var sent int64
+	received := map[lksdk.TrackKind]*trackKindMetrics{
+		lksdk.TrackKindAudio: {},
+		lksdk.TrackKindVideo: {},
+	}
+	for _, tester := range t.status.startedTesters() {
+		sent += tester.sentBytes.Load()
+		tester.stats.Range(func(_, value interface{}) bool {
+			ts := value.(*trackStats)
+			if m := received[ts.kind]; m != nil {
+				m.bytes += ts.bytes.Load()
+				m.packets += ts.packets.Load()
+				m.dropped += ts.dropped.Load()
+			}
+			return true
+		})
+	}
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	bw := bufio.NewWriter(w)
+	writeMetric(bw, "lk_loadtest_testers_expected", "gauge", "Testers the test will start.", "", report.ExpectedTesters)
+	writeMetric(bw, "lk_loadtest_testers_started", "gauge", "Testers started so far.", "", report.StartedTesters)
+	writeMetric(bw, "lk_loadtest_testers_connected", "gauge", "Testers currently connected.", "", report.ActiveTesters)
+	writeMetric(bw, "lk_loadtest_connect_errors_total", "counter", "Testers that failed to connect.", "", connectErrs)
+	writeMetric(bw, "lk_loadtest_publish_errors_total", "counter", "Tracks that failed to publish.", "", publishErrs)
+	writeMetric(bw, "lk_loadtest_sent_bytes_total", "counter", "Media bytes written by publishers.", "", sent)
+
+	writeHeader(bw, "lk_loadtest_received_bytes_total", "counter", "Media bytes received by subscribers.")
+	for _, kind := range metricKinds {
+		writeSample(bw, "lk_loadtest_received_bytes_total", kindLabel(kind), received[kind].bytes)
+	}
+	writeHeader(bw, "lk_loadtest_received_packets_total", "counter", "Media packets received by subscribers.")
+	for _, kind := range metricKinds {
+		writeSample(bw, "lk_loadtest_received_packets_total", kindLabel(kind), received[kind].packets)
+	}
+	writeHeader(bw, "lk_loadtest_lost_packets_total", "counter", "Media packets subscribers never received.")
+	for _, kind := range metricKinds {
+		writeSample(bw, "lk_loadtest_lost_packets_total", kindLabel(kind), received[kind].dropped)
+	}
+	_ = bw.Flush()
+}
+
+func kindLabel(kind lksdk.TrackKind) string {
+	return fmt.Sprintf(`{kind=%q}`, string(kind))
+}
+
+func writeMetric(w io.Writer, name, metricType, help, labels string, value any) {
+	writeHeader(w, name, metricType, help)
+	writeSample(w, name, labels, value)
+}
+
+func writeHeader(w io.Writer, name, metricType, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+}
+
+func writeSample(w io.Writer, name, labels string, value any) {
+	fmt.Fprintf(w, "%s%s %v\n", name, labels, value)
+}
@@ -0,0 +1,293 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPublisherRatio is the share of participants publishing when the metrics don't say
+const DefaultPublisherRatio = 0.2
+
+// MetricsSample is the concurrency of a production deployment at one point in time
+type MetricsSample struct {
+	At           time.Time
+	Participants int
+	// -1 when the metrics don't break publishers out
+	Publishers int
+}
+
+// MetricsImport converts a participants-over-time series into a scenario that replays it
+type MetricsImport struct {
+	// window of the series to replay, all of it when zero
+	Start time.Time
+	End   time.Time
+	// samples are reduced to their peak over every window of this length
+	Resolution time.Duration
+	// replay this many times faster than real time
+	Speedup float64
+	// multiply participant counts, to replay a fraction or a multiple of production load
+	Scale float64
+	// participants are spread evenly over this many rooms, replayed with --room-count
+	Rooms int
+	// share of participants publishing audio and video, unless the series has a publishers column
+	PublisherRatio float64
+	// flat stretches become churn phases replacing this fraction of subscribers every second,
+	// as production sessions come and go without changing the count
+	ChurnRate float64
+}
+
+// LoadMetricsCSV reads a time series of participant counts. Columns are time, participants and
+// optionally publishers, in that order or named by a header. Times are RFC 3339, "2006-01-02
+// 15:04:05" or unix timestamps in seconds or milliseconds.
+func LoadMetricsCSV(path string) ([]MetricsSample, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	samples, err := ParseMetricsCSV(f)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metrics %s: %w", path, err)
+	}
+	return samples, nil
+}
+
+// ParseMetricsCSV parses a time series in the format of LoadMetricsCSV
+func ParseMetricsCSV(r io.Reader) ([]MetricsSample, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	timeCol, participantsCol, publishersCol := 0, 1, 2
+	var samples []MetricsSample
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if line == 1 {
+			if _, err := parseMetricsTime(record[0]); err != nil {
+				// header
+				timeCol, participantsCol, publishersCol = -1, -1, -1
+				for i, name := range record {
+					switch strings.ToLower(strings.TrimSpace(name)) {
+					case "time", "timestamp":
+						timeCol = i
+					case "participants", "count":
+						participantsCol = i
+					case "publishers":
+						publishersCol = i
+					}
+				}
+				if timeCol < 0 || participantsCol < 0 {
+					return nil, fmt.Errorf("header needs time and participants columns")
+				}
+				continue
+			}
+		}
+
+		if len(record) <= max(timeCol, participantsCol) {
+			return nil, fmt.Errorf("line %d: expected time and participants", line)
+		}
+		sample := MetricsSample{Publishers: -1}
+		if sample.At, err = parseMetricsTime(record[timeCol]); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if sample.Participants, err = parseMetricsCount(record[participantsCol]); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if publishersCol >= 0 && publishersCol < len(record) && strings.TrimSpace(record[publishersCol]) != "" {
+			if sample.Publishers, err = parseMetricsCount(record[publishersCol]); err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			sample.Publishers = min(sample.Publishers, sample.Participants)
+		}
+		samples = append(samples, sample)
+	}
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no samples")
+	}
+	slices.SortStableFunc(samples, func(a, b MetricsSample) int {
+		return a.At.Compare(b.At)
+	})
+	return samples, nil
+}
+
+func parseMetricsTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if ts, err := strconv.ParseFloat(value, 64); err == nil {
+		if ts > 1e12 {
+			// milliseconds
+			ts /= 1000
+		}
+		sec, frac := math.Modf(ts)
+		return time.Unix(int64(sec), int64(frac*1e9)).UTC(), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", value)
+}
+
+func parseMetricsCount(value string) (int, error) {
+	// exports often average over their interval
+	count, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil || count < 0 || math.IsInf(count, 0) || math.IsNaN(count) {
+		return 0, fmt.Errorf("invalid participant count %q", value)
+	}
+	return int(math.Round(count)), nil
+}
+
+// Scenario builds the scenario replaying samples. Every change between two samples becomes a
+// ramp paced to last as long as the change did, and every flat stretch a hold or churn phase.
+func (m MetricsImport) Scenario(name string, samples []MetricsSample) (*Scenario, error) {
+	if m.Speedup <= 0 {
+		m.Speedup = 1
+	}
+	if m.Scale <= 0 {
+		m.Scale = 1
+	}
+	m.Rooms = max(m.Rooms, 1)
+	if m.PublisherRatio < 0 || m.PublisherRatio > 1 {
+		return nil, fmt.Errorf("publisher ratio must be between 0 and 1")
+	}
+	if m.ChurnRate < 0 || m.ChurnRate > 1 {
+		return nil, fmt.Errorf("churn rate must be between 0 and 1")
+	}
+
+	steps := m.resample(samples)
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("no samples between %s and %s", m.Start.Format(time.RFC3339), m.End.Format(time.RFC3339))
+	}
+
+	s := &Scenario{Name: name}
+	publishers, subscribers := m.roomCounts(steps[0])
+	s.Phases = append(s.Phases, rampPhase("start "+phaseTime(steps[0].At), publishers, subscribers, 0, 0))
+	for i := 1; i < len(steps); i++ {
+		elapsed := time.Duration(float64(steps[i].At.Sub(steps[i-1].At)) / m.Speedup)
+		nextPublishers, nextSubscribers := m.roomCounts(steps[i])
+		changes := abs(nextPublishers-publishers) + abs(nextSubscribers-subscribers)
+		publishers, subscribers = nextPublishers, nextSubscribers
+
+		if changes == 0 {
+			m.flat(s, elapsed)
+			continue
+		}
+		// rooms are resized one after the other, so the pace covers all of them
+		rate := float64(changes*m.Rooms) / elapsed.Seconds()
+		// the first change of a ramp is immediate, the phase holds for the pace of the last one
+		hold := time.Duration(float64(time.Second) / rate).Round(time.Second)
+		s.Phases = append(s.Phases, rampPhase(phaseTime(steps[i].At), publishers, subscribers, rate, hold))
+	}
+	// hold the last sample for one window
+	m.flat(s, time.Duration(float64(m.Resolution)/m.Speedup))
+	return s, nil
+}
+
+// resample reduces samples within the window to their peak over every Resolution
+func (m MetricsImport) resample(samples []MetricsSample) []MetricsSample {
+	var steps []MetricsSample
+	for _, sample := range samples {
+		if (!m.Start.IsZero() && sample.At.Before(m.Start)) || (!m.End.IsZero() && sample.At.After(m.End)) {
+			continue
+		}
+		if m.Resolution > 0 && len(steps) > 0 {
+			last := &steps[len(steps)-1]
+			if sample.At.Sub(last.At) < m.Resolution {
+				if sample.Participants > last.Participants {
+					last.Participants, last.Publishers = sample.Participants, sample.Publishers
+				}
+				continue
+			}
+		}
+		steps = append(steps, sample)
+	}
+	return steps
+}
+
+// roomCounts is the number of publishers and subscribers of every room at sample
+func (m MetricsImport) roomCounts(sample MetricsSample) (int, int) {
+	participants := int(math.Round(float64(sample.Participants) * m.Scale / float64(m.Rooms)))
+	var publishers int
+	if sample.Publishers >= 0 {
+		publishers = int(math.Round(float64(sample.Publishers) * m.Scale / float64(m.Rooms)))
+	} else {
+		publishers = int(math.Round(float64(participants) * m.PublisherRatio))
+	}
+	publishers = min(publishers, participants)
+	return publishers, participants - publishers
+}
+
+// flat holds the current population for d, extending the last phase when it is flat too
+func (m MetricsImport) flat(s *Scenario, d time.Duration) {
+	d = max(d.Round(time.Second), time.Second)
+	kind := PhaseHold
+	if m.ChurnRate > 0 {
+		kind = PhaseChurn
+	}
+	if last := s.Phases[len(s.Phases)-1]; last.Kind == kind {
+		last.Duration += d
+		return
+	}
+	s.Phases = append(s.Phases, &ScenarioPhase{
+		Name:      fmt.Sprintf("%d-%s", len(s.Phases)+1, kind),
+		Kind:      kind,
+		Duration:  d,
+		ChurnRate: m.ChurnRate,
+	})
+}
+
+func rampPhase(name string, publishers, subscribers int, rate float64, hold time.Duration) *ScenarioPhase {
+	if rate > 0 {
+		// rounding to 0 would fall back to the test's rate
+		rate = max(math.Round(rate*1000)/1000, 0.001)
+	}
+	return &ScenarioPhase{
+		Name:            name,
+		Kind:            PhaseRamp,
+		Duration:        hold,
+		VideoPublishers: &publishers,
+		AudioPublishers: &publishers,
+		Subscribers:     &subscribers,
+		NumPerSecond:    rate,
+	}
+}
+
+func phaseTime(t time.Time) string {
+	return t.Format("2006-01-02 15:04:05")
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
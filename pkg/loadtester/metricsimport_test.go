@@ -0,0 +1,139 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMetricsCSV(t *testing.T) {
+	samples, err := ParseMetricsCSV(strings.NewReader(`# exported from grafana
+publishers,participants,timestamp
+2,10,2025-04-15T18:01:00Z
+4,20.4,2025-04-15T18:00:00Z
+,5,2025-04-15T18:02:00Z
+`))
+	require.NoError(t, err)
+	require.Len(t, samples, 3)
+	// sorted by time
+	require.Equal(t, 20, samples[0].Participants)
+	require.Equal(t, 4, samples[0].Publishers)
+	require.Equal(t, 10, samples[1].Participants)
+	require.Equal(t, -1, samples[2].Publishers)
+
+	samples, err = ParseMetricsCSV(strings.NewReader("1744740000,10\n1744740060000,12\n"))
+	require.NoError(t, err)
+	require.Equal(t, time.Unix(1744740000, 0).UTC(), samples[0].At)
+	require.Equal(t, time.Unix(1744740060, 0).UTC(), samples[1].At)
+
+	for _, invalid := range []string{
+		"",
+		"when,who\n1,2\n",
+		"2025-04-15T18:00:00Z\n",
+		"2025-04-15T18:00:00Z,-1\n",
+		"2025-04-15T18:00:00Z,10\nyesterday,10\n",
+	} {
+		_, err = ParseMetricsCSV(strings.NewReader(invalid))
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestMetricsImportScenario(t *testing.T) {
+	start := time.Date(2025, 4, 15, 18, 0, 0, 0, time.UTC)
+	at := func(minutes float64) time.Time {
+		return start.Add(time.Duration(minutes * float64(time.Minute)))
+	}
+	samples := []MetricsSample{
+		{At: at(0), Participants: 100, Publishers: -1},
+		{At: at(0.5), Participants: 120, Publishers: -1},
+		{At: at(1), Participants: 200, Publishers: -1},
+		{At: at(2), Participants: 200, Publishers: -1},
+		{At: at(3), Participants: 200, Publishers: -1},
+		{At: at(4), Participants: 100, Publishers: -1},
+		{At: at(60), Participants: 500, Publishers: -1},
+	}
+	m := MetricsImport{End: at(10), Resolution: time.Minute, Speedup: 2, Rooms: 2, PublisherRatio: 0.2}
+	s, err := m.Scenario("tuesday", samples)
+	require.NoError(t, err)
+	require.Equal(t, "tuesday", s.Name)
+	require.NoError(t, s.validate())
+
+	kinds := make([]string, len(s.Phases))
+	for i, p := range s.Phases {
+		kinds[i] = p.Kind
+	}
+	require.Equal(t, []string{PhaseRamp, PhaseRamp, PhaseHold, PhaseRamp, PhaseHold}, kinds)
+
+	// the peak of the first minute, split over 2 rooms
+	initial := s.Phases[0]
+	require.Equal(t, 12, *initial.VideoPublishers)
+	require.Equal(t, 12, *initial.AudioPublishers)
+	require.Equal(t, 48, *initial.Subscribers)
+	require.Zero(t, initial.NumPerSecond)
+
+	// 120 to 200 over a minute at 2x speed, 40 changes per room
+	up := s.Phases[1]
+	require.Equal(t, 20, *up.VideoPublishers)
+	require.Equal(t, 80, *up.Subscribers)
+	require.InDelta(t, 80.0/30, up.NumPerSecond, 0.001)
+	require.Equal(t, time.Duration(0), up.Duration)
+	require.Equal(t, time.Minute, s.Phases[2].Duration)
+
+	// 200 to 100 over a minute
+	down := s.Phases[3]
+	require.Equal(t, 10, *down.VideoPublishers)
+	require.Equal(t, 40, *down.Subscribers)
+	require.Equal(t, 30*time.Second, s.Phases[4].Duration)
+
+	video, audio, subscribers := s.Peak()
+	require.Equal(t, 20, video)
+	require.Equal(t, 20, audio)
+	require.Equal(t, 80, subscribers)
+
+	m.ChurnRate = 0.05
+	m.Scale = 0.1
+	s, err = m.Scenario("tuesday", samples)
+	require.NoError(t, err)
+	require.Equal(t, PhaseChurn, s.Phases[2].Kind)
+	require.Equal(t, 0.05, s.Phases[2].ChurnRate)
+	require.Equal(t, 2, *s.Phases[1].VideoPublishers)
+
+	_, err = MetricsImport{Start: at(100)}.Scenario("empty", samples)
+	require.Error(t, err)
+	_, err = MetricsImport{PublisherRatio: 2}.Scenario("invalid", samples)
+	require.Error(t, err)
+}
+
+func TestMetricsImportSave(t *testing.T) {
+	samples, err := ParseMetricsCSV(strings.NewReader("time,participants,publishers\n1744740000,10,10\n1744740060,12,10\n"))
+	require.NoError(t, err)
+	s, err := MetricsImport{Resolution: time.Minute}.Scenario("replay", samples)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "replay.yaml")
+	require.NoError(t, s.Save(path, "imported"))
+	loaded, err := LoadScenario(path, nil)
+	require.NoError(t, err)
+	require.Len(t, loaded.Phases, len(s.Phases))
+	require.Equal(t, 10, *loaded.Phases[1].VideoPublishers)
+	require.Equal(t, 2, *loaded.Phases[1].Subscribers)
+	require.Equal(t, 0.033, loaded.Phases[1].NumPerSecond)
+	require.Equal(t, 30*time.Second, loaded.Phases[1].Duration)
+}
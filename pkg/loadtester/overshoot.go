@@ -0,0 +1,168 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pion/webrtc/v4/pkg/media"
+	"go.uber.org/atomic"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+const (
+	// DefaultOvershootThreshold is how far over its target bitrate a published track may go before it's flagged
+	DefaultOvershootThreshold = 0.2
+	// tracks sent for less media time aren't judged, their clip may not have reached its average yet
+	minOvershootMeasure = 5 * time.Second
+)
+
+// ValidateOvershootThreshold checks the --overshoot-threshold flag
+func ValidateOvershootThreshold(params Params) error {
+	if params.OvershootThreshold < 0 {
+		return fmt.Errorf("overshoot threshold cannot be negative")
+	}
+	return nil
+}
+
+// sentLayer measures the bitrate a published track or simulcast layer produces, against its target
+type sentLayer struct {
+	name string
+	// bps
+	target int64
+	bytes  atomic.Int64
+	// media time of the samples, which the bitrate is measured over so that pacing doesn't skew it
+	duration atomic.Duration
+}
+
+func (l *sentLayer) bitrate() float64 {
+	d := l.duration.Load()
+	if d <= 0 {
+		return 0
+	}
+	return float64(l.bytes.Load()*8) / d.Seconds()
+}
+
+type layerMeter struct {
+	lksdk.SampleProvider
+	layer *sentLayer
+}
+
+func (m *layerMeter) NextSample(ctx context.Context) (media.Sample, error) {
+	sample, err := m.SampleProvider.NextSample(ctx)
+	if err == nil {
+		m.layer.bytes.Add(int64(len(sample.Data)))
+		m.layer.duration.Add(sample.Duration)
+	}
+	return sample, err
+}
+
+// measureLayer compares what provider produces against the target bitrate in bps, 0 when it has none
+func (t *LoadTester) measureLayer(provider lksdk.SampleProvider, name string, target int64) lksdk.SampleProvider {
+	if target <= 0 {
+		return provider
+	}
+	layer := &sentLayer{name: name, target: target}
+	t.lock.Lock()
+	t.sentLayers = append(t.sentLayers, layer)
+	t.lock.Unlock()
+	return &layerMeter{SampleProvider: provider, layer: layer}
+}
+
+// OvershootResults is a published track or layer sending more than its target bitrate
+type OvershootResults struct {
+	Tester    string  `json:"tester"`
+	Track     string  `json:"track"`
+	TargetBps int64   `json:"targetBps"`
+	SentBps   float64 `json:"sentBps"`
+	// fraction the sent bitrate is above the target
+	Overshoot float64 `json:"overshoot"`
+}
+
+// BitrateResults are the publisher tracks measured against their target bitrate
+type BitrateResults struct {
+	Measured   int                 `json:"measured"`
+	Threshold  float64             `json:"threshold"`
+	Overshoots []*OvershootResults `json:"overshoots,omitempty"`
+}
+
+// checkOvershoot flags the published tracks sending more than threshold over their target bitrate
+func checkOvershoot(stats map[string]*testerStats, threshold float64) *BitrateResults {
+	if threshold <= 0 {
+		threshold = DefaultOvershootThreshold
+	}
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	res := &BitrateResults{Threshold: threshold}
+	for _, name := range names {
+		for _, layer := range stats[name].sentLayers {
+			if layer.duration.Load() < minOvershootMeasure {
+				continue
+			}
+			res.Measured++
+			sent := layer.bitrate()
+			overshoot := sent/float64(layer.target) - 1
+			if overshoot > threshold {
+				res.Overshoots = append(res.Overshoots, &OvershootResults{
+					Tester:    name,
+					Track:     layer.name,
+					TargetBps: layer.target,
+					SentBps:   sent,
+					Overshoot: overshoot,
+				})
+			}
+		}
+	}
+	if res.Measured == 0 {
+		return nil
+	}
+	sort.SliceStable(res.Overshoots, func(i, j int) bool {
+		return res.Overshoots[i].Overshoot > res.Overshoots[j].Overshoot
+	})
+	return res
+}
+
+func printBitrateResults(res *BitrateResults) {
+	if res == nil {
+		return
+	}
+	fmt.Printf("\nBitrate targets: %d of %d published tracks sent more than %.0f%% over their target\n",
+		len(res.Overshoots), res.Measured, res.Threshold*100)
+	if len(res.Overshoots) == 0 {
+		return
+	}
+	table := util.CreateTable().
+		Headers("Tester", "Track", "Target", "Sent", "Overshoot")
+	for _, o := range res.Overshoots {
+		table.Row(
+			o.Tester,
+			o.Track,
+			formatBitrate(o.TargetBps/8, time.Second),
+			formatBitrate(int64(o.SentBps/8), time.Second),
+			fmt.Sprintf("+%.0f%%", o.Overshoot*100),
+		)
+	}
+	fmt.Println(table)
+}
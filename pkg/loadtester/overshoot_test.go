@@ -0,0 +1,79 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeasureLayer(t *testing.T) {
+	tester := &LoadTester{}
+	require.Equal(t, &opusFrameProvider{}, tester.measureLayer(&opusFrameProvider{}, "audio", 0))
+
+	// 100 byte frames every 10ms are 80kbps
+	provider := tester.measureLayer(&opusFrameProvider{frames: [][]byte{make([]byte, 100)}}, "audio", 64000)
+	for i := 0; i < 10; i++ {
+		_, err := provider.NextSample(context.Background())
+		require.NoError(t, err)
+	}
+	require.Len(t, tester.sentLayers, 1)
+	require.Equal(t, 100*time.Millisecond, tester.sentLayers[0].duration.Load())
+	require.InDelta(t, 80000, tester.sentLayers[0].bitrate(), 1)
+}
+
+func TestCheckOvershoot(t *testing.T) {
+	layer := func(name string, target int64, bytes int64) *sentLayer {
+		l := &sentLayer{name: name, target: target}
+		l.bytes.Store(bytes)
+		l.duration.Store(10 * time.Second)
+		return l
+	}
+	short := layer("video low", 100000, 1000000)
+	short.duration.Store(time.Second)
+	stats := map[string]*testerStats{
+		"pub_0": {sentLayers: []*sentLayer{
+			// 1mbps
+			layer("video high", 1000000, 1250000),
+			// 150kbps over a target of 100kbps
+			layer("video low", 100000, 187500),
+			short,
+		}},
+		"pub_1": {sentLayers: []*sentLayer{
+			// 40kbps over a target of 32kbps
+			layer("audio", 32000, 50000),
+		}},
+		"sub_0": {},
+	}
+
+	res := checkOvershoot(stats, 0)
+	require.Equal(t, 3, res.Measured)
+	require.Equal(t, DefaultOvershootThreshold, res.Threshold)
+	require.Len(t, res.Overshoots, 2)
+	require.Equal(t, "pub_0", res.Overshoots[0].Tester)
+	require.Equal(t, "video low", res.Overshoots[0].Track)
+	require.InDelta(t, 0.5, res.Overshoots[0].Overshoot, 0.001)
+	require.Equal(t, "audio", res.Overshoots[1].Track)
+	require.InDelta(t, 0.25, res.Overshoots[1].Overshoot, 0.001)
+
+	res = checkOvershoot(stats, 0.3)
+	require.Len(t, res.Overshoots, 1)
+
+	require.Nil(t, checkOvershoot(map[string]*testerStats{"sub_0": {}}, 0))
+	require.Error(t, ValidateOvershootThreshold(Params{OvershootThreshold: -1}))
+}
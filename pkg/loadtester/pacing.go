@@ -0,0 +1,182 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pion/webrtc/v4/pkg/media"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+const (
+	// warn about late frames and carry on
+	BehindPolicyLog = "log"
+	// hold the ramp while generators are behind
+	BehindPolicySlow = "slow"
+	// stop the test on the first late frame
+	BehindPolicyFail = "fail"
+
+	DefaultBehindThreshold = 200 * time.Millisecond
+
+	// a generator counts as behind for this long after a late frame
+	behindWindow      = time.Second
+	behindWarnEvery   = 5 * time.Second
+	behindHoldRecheck = 250 * time.Millisecond
+)
+
+// ValidateBehindPolicy checks a --behind-policy value
+func ValidateBehindPolicy(policy string) error {
+	switch policy {
+	case "", BehindPolicyLog, BehindPolicySlow, BehindPolicyFail:
+		return nil
+	}
+	return fmt.Errorf("unsupported behind policy %q, expected %q, %q or %q",
+		policy, BehindPolicyLog, BehindPolicySlow, BehindPolicyFail)
+}
+
+// lagMonitor collects frames that publishers produced later than real time. When the load
+// generator itself cannot keep up, the delay would otherwise be indistinguishable from
+// latency added by the server.
+type lagMonitor struct {
+	policy    string
+	threshold time.Duration
+	onFail    func()
+
+	lock       sync.Mutex
+	lateFrames int64
+	maxLag     time.Duration
+	lastLate   time.Time
+	lastWarned time.Time
+	held       time.Duration
+	failure    error
+}
+
+func newLagMonitor(policy string, threshold time.Duration) *lagMonitor {
+	if policy == "" {
+		policy = BehindPolicyLog
+	}
+	if threshold <= 0 {
+		threshold = DefaultBehindThreshold
+	}
+	return &lagMonitor{
+		policy:    policy,
+		threshold: threshold,
+	}
+}
+
+func (m *lagMonitor) observe(name string, lag time.Duration) {
+	if lag <= m.threshold {
+		return
+	}
+
+	m.lock.Lock()
+	now := time.Now()
+	m.lateFrames++
+	m.maxLag = max(m.maxLag, lag)
+	m.lastLate = now
+	warn := now.Sub(m.lastWarned) >= behindWarnEvery
+	if warn {
+		m.lastWarned = now
+	}
+	fail := m.policy == BehindPolicyFail && m.failure == nil
+	if fail {
+		m.failure = fmt.Errorf("%s fell %s behind real time, exceeding the %s threshold", name, lag.Round(time.Millisecond), m.threshold)
+	}
+	onFail := m.onFail
+	m.lock.Unlock()
+
+	if fail {
+		fmt.Printf("Stopping test: %s media generator is %s behind real time\n", name, lag.Round(time.Millisecond))
+		if onFail != nil {
+			onFail()
+		}
+	} else if warn {
+		fmt.Printf("Warning: %s media generator is %s behind real time, latency may be caused by this host rather than the server\n",
+			name, lag.Round(time.Millisecond))
+	}
+}
+
+// behind is true shortly after any generator produced a late frame
+func (m *lagMonitor) behind() bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return !m.lastLate.IsZero() && time.Since(m.lastLate) < behindWindow
+}
+
+// hold waits while generators are behind, returning how long the ramp was held
+func (m *lagMonitor) hold(ctx context.Context) time.Duration {
+	start := time.Now()
+	for m.behind() && sleepUntil(ctx, time.Now().Add(behindHoldRecheck)) {
+	}
+	held := time.Since(start)
+	if held > behindHoldRecheck/2 {
+		m.lock.Lock()
+		m.held += held
+		m.lock.Unlock()
+	}
+	return held
+}
+
+func (m *lagMonitor) err() error {
+	if m == nil {
+		return nil
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.failure
+}
+
+func (m *lagMonitor) print() {
+	if m == nil {
+		return
+	}
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.lateFrames == 0 {
+		return
+	}
+	fmt.Printf("\nMedia generators fell behind real time on %d frames (worst %s, threshold %s)\n",
+		m.lateFrames, m.maxLag.Round(time.Millisecond), m.threshold)
+	if m.held > 0 {
+		fmt.Printf("Ramp was held for %s while generators caught up\n", m.held.Round(time.Second))
+	}
+}
+
+// pacedProvider measures how late the track writer asks for each sample, compared to the
+// schedule set by the durations of the samples before it. Delays in producing or packetizing
+// a sample make the following request late.
+type pacedProvider struct {
+	lksdk.SampleProvider
+	name string
+	lag  *lagMonitor
+	next time.Time
+}
+
+func (p *pacedProvider) NextSample(ctx context.Context) (media.Sample, error) {
+	now := time.Now()
+	if p.next.IsZero() {
+		p.next = now
+	} else {
+		p.lag.observe(p.name, now.Sub(p.next))
+	}
+	sample, err := p.SampleProvider.NextSample(ctx)
+	p.next = p.next.Add(sample.Duration)
+	return sample, err
+}
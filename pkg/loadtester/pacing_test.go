@@ -0,0 +1,75 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pion/webrtc/v4/pkg/media"
+	"github.com/stretchr/testify/require"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+type frameProvider struct {
+	lksdk.SampleProvider
+}
+
+func (frameProvider) NextSample(context.Context) (media.Sample, error) {
+	return media.Sample{Data: []byte{0}, Duration: 10 * time.Millisecond}, nil
+}
+
+func TestPacedProvider(t *testing.T) {
+	lag := newLagMonitor(BehindPolicyLog, 50*time.Millisecond)
+	p := &pacedProvider{SampleProvider: frameProvider{}, name: "Pub 0", lag: lag}
+	ctx := context.Background()
+
+	_, err := p.NextSample(ctx)
+	require.NoError(t, err)
+	time.Sleep(10 * time.Millisecond)
+	_, err = p.NextSample(ctx)
+	require.NoError(t, err)
+	require.False(t, lag.behind())
+
+	// the writer is held up well past the schedule of the two samples so far
+	time.Sleep(100 * time.Millisecond)
+	_, err = p.NextSample(ctx)
+	require.NoError(t, err)
+	require.True(t, lag.behind())
+	require.EqualValues(t, 1, lag.lateFrames)
+	require.NoError(t, lag.err())
+}
+
+func TestLagMonitorFail(t *testing.T) {
+	lag := newLagMonitor(BehindPolicyFail, 0)
+	require.Equal(t, DefaultBehindThreshold, lag.threshold)
+	stopped := 0
+	lag.onFail = func() { stopped++ }
+
+	lag.observe("Pub 0", DefaultBehindThreshold/2)
+	require.NoError(t, lag.err())
+	lag.observe("Pub 0", 2*DefaultBehindThreshold)
+	lag.observe("Pub 1", 3*DefaultBehindThreshold)
+	require.ErrorContains(t, lag.err(), "Pub 0")
+	require.Equal(t, 1, stopped)
+}
+
+func TestValidateBehindPolicy(t *testing.T) {
+	require.NoError(t, ValidateBehindPolicy(""))
+	require.NoError(t, ValidateBehindPolicy(BehindPolicySlow))
+	require.Error(t, ValidateBehindPolicy("ignore"))
+}
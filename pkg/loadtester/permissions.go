@@ -0,0 +1,164 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/livekit/protocol/livekit"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+// set along with every permission update, to the time it was requested, so that testers
+// can tell when the update reached them
+const permissionAttribute = "lk.loadtest.permission-update"
+
+// permissionAdmin acts as a moderator of the test rooms, promoting subscribers to publishers
+// and demoting them again through the server API
+type permissionAdmin struct {
+	roomClient *lksdk.RoomServiceClient
+	rate       float64
+	// subscribers that can be updated, by room
+	targets map[string][]string
+
+	lock     sync.Mutex
+	promoted map[string]bool
+	sent     int
+	failed   int
+	requests latencySamples
+}
+
+func newPermissionAdmin(params Params, testers []*LoadTester) *permissionAdmin {
+	a := &permissionAdmin{
+		roomClient: lksdk.NewRoomServiceClient(params.URL, params.APIKey, params.APISecret),
+		rate:       params.PermissionUpdateRate,
+		targets:    make(map[string][]string),
+		promoted:   make(map[string]bool),
+	}
+	for _, t := range testers {
+		// hidden testers would lose their hidden permission
+		if t.params.Role.IsPublisher() || t.params.Hidden || !t.IsRunning() {
+			continue
+		}
+		a.targets[t.params.Room] = append(a.targets[t.params.Room], t.identity())
+	}
+	return a
+}
+
+// run updates permissions in every room at the configured rate until ctx is done
+func (a *permissionAdmin) run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for room, identities := range a.targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limiter := rate.NewLimiter(rate.Limit(a.rate), 1)
+			for limiter.Wait(ctx) == nil {
+				a.update(ctx, room, identities[rand.Intn(len(identities))])
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// update flips the publish permission of identity
+func (a *permissionAdmin) update(ctx context.Context, room, identity string) {
+	a.lock.Lock()
+	promote := !a.promoted[identity]
+	a.promoted[identity] = promote
+	a.lock.Unlock()
+
+	requestedAt := time.Now()
+	_, err := a.roomClient.UpdateParticipant(ctx, &livekit.UpdateParticipantRequest{
+		Room:     room,
+		Identity: identity,
+		Permission: &livekit.ParticipantPermission{
+			CanSubscribe:   true,
+			CanPublish:     promote,
+			CanPublishData: true,
+		},
+		Attributes: map[string]string{
+			permissionAttribute: strconv.FormatInt(requestedAt.UnixNano(), 10),
+		},
+	})
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if err != nil {
+		if ctx.Err() == nil {
+			a.failed++
+		}
+		return
+	}
+	a.sent++
+	a.requests.add(time.Since(requestedAt))
+}
+
+// onAttributesChanged measures how long permission updates took to reach the tester, whether
+// they were its own or another participant's
+func (t *LoadTester) onAttributesChanged(changed map[string]string, _ lksdk.Participant) {
+	value, ok := changed[permissionAttribute]
+	if !ok {
+		return
+	}
+	nanos, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return
+	}
+	latency := max(time.Since(time.Unix(0, nanos)), 0)
+
+	t.lock.Lock()
+	t.permissionUpdates.add(latency)
+	t.lock.Unlock()
+}
+
+func (t *LoadTest) printPermissionStats(stats map[string]*testerStats, names []string) {
+	if t.permissions == nil {
+		return
+	}
+	table := util.CreateTable().
+		Headers("Tester", "Updates Seen", "p50", "p95", "p99", "Media Gaps", "Longest Gap")
+	for _, name := range names {
+		s := stats[name]
+		gaps, longest := s.mediaGaps()
+		p := getLatencyPercentiles(s.permissionUpdates)
+		table.Row(
+			name,
+			fmt.Sprint(s.permissionUpdateCount),
+			p.p50.Round(time.Millisecond).String(),
+			p.p95.Round(time.Millisecond).String(),
+			p.p99.Round(time.Millisecond).String(),
+			fmt.Sprint(gaps),
+			longest.Round(time.Millisecond).String(),
+		)
+	}
+
+	a := t.permissions
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	requests := getLatencyPercentiles(a.requests.samples)
+	fmt.Printf("\nPermission updates (%d sent, %d failed, request p50 %s, p95 %s):\n",
+		a.sent, a.failed, requests.p50.Round(time.Millisecond), requests.p95.Round(time.Millisecond))
+	fmt.Println(table)
+}
@@ -0,0 +1,37 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermissionUpdateLatency(t *testing.T) {
+	tester := NewLoadTester(TesterParams{})
+	sentAt := time.Now().Add(-40 * time.Millisecond)
+	tester.onAttributesChanged(map[string]string{
+		permissionAttribute: strconv.FormatInt(sentAt.UnixNano(), 10),
+	}, nil)
+	// other attribute changes are ignored
+	tester.onAttributesChanged(map[string]string{RoleAttribute: string(RoleSubscriber)}, nil)
+
+	stats := tester.getStats()
+	require.Equal(t, int64(1), stats.permissionUpdateCount)
+	require.GreaterOrEqual(t, stats.permissionUpdates[0], 40*time.Millisecond)
+}
@@ -0,0 +1,204 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Preset is a named set of parameters for a common kind of test.
+// Presets only fill in parameters that haven't been set explicitly.
+type Preset struct {
+	Name        string       `yaml:"name"`
+	Description string       `yaml:"description,omitempty"`
+	Params      PresetParams `yaml:"params"`
+	// file the preset was loaded from, empty for built-in presets
+	Path string `yaml:"-"`
+}
+
+// PresetParams are the parameters a preset can fill in, zero values are left alone
+type PresetParams struct {
+	VideoPublishers     int           `yaml:"video_publishers,omitempty"`
+	AudioPublishers     int           `yaml:"audio_publishers,omitempty"`
+	Subscribers         int           `yaml:"subscribers,omitempty"`
+	Duration            time.Duration `yaml:"duration,omitempty"`
+	Ramp                string        `yaml:"ramp,omitempty"`
+	VideoCodec          string        `yaml:"video_codec,omitempty"`
+	HiddenSubscribers   int           `yaml:"hidden_subscribers,omitempty"`
+	DuplicateJoinRate   float64       `yaml:"duplicate_join_rate,omitempty"`
+	RoomDownlinkCap     string        `yaml:"room_downlink_cap,omitempty"`
+	AudioPacketLoss     float64       `yaml:"audio_packet_loss,omitempty"`
+	PacketLoss          float64       `yaml:"packet_loss,omitempty"`
+	Jitter              time.Duration `yaml:"jitter,omitempty"`
+	BandwidthCap        string        `yaml:"bandwidth_cap,omitempty"`
+	PublishRamp         time.Duration `yaml:"publish_ramp,omitempty"`
+	AudioPublishDelay   time.Duration `yaml:"audio_publish_delay,omitempty"`
+	VideoPublishDelay   time.Duration `yaml:"video_publish_delay,omitempty"`
+	ResubscribeInterval time.Duration `yaml:"resubscribe_interval,omitempty"`
+}
+
+var builtinPresets = []*Preset{
+	{
+		Name:        "audio-plc",
+		Description: "Audio only rooms with 20% simulated loss on subscribers, reporting packet loss concealment",
+		Params: PresetParams{
+			AudioPublishers: 10,
+			Subscribers:     20,
+			AudioPacketLoss: 0.2,
+		},
+	},
+}
+
+// standardSuite are the matrices run by --run-all, audio and video cases of increasing size
+var standardSuite = []string{
+	"media=audio;publishers=10;subscribers=10,100,500,1000",
+	"media=audio;publishers=50,100;subscribers=50",
+	"media=video;publishers=10;subscribers=10,100,500",
+	"media=video;publishers=1;subscribers=100,1000",
+}
+
+// StandardSuite returns the matrices run by --run-all
+func StandardSuite() []*Matrix {
+	matrices := make([]*Matrix, 0, len(standardSuite))
+	for _, s := range standardSuite {
+		m, err := ParseMatrix(s)
+		if err != nil {
+			panic(err)
+		}
+		matrices = append(matrices, m)
+	}
+	return matrices
+}
+
+// LoadPresets returns the built-in presets followed by the user presets in dir.
+// A user preset replaces a built-in one with the same name.
+func LoadPresets(dir string) ([]*Preset, error) {
+	presets := append([]*Preset(nil), builtinPresets...)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		p := &Preset{}
+		if err = yaml.Unmarshal(data, p); err != nil {
+			return nil, fmt.Errorf("invalid preset %s: %w", file, err)
+		}
+		if p.Name == "" {
+			p.Name = strings.TrimSuffix(filepath.Base(file), ".yaml")
+		}
+		p.Path = file
+
+		replaced := false
+		for i, existing := range presets {
+			if existing.Name == p.Name {
+				presets[i] = p
+				replaced = true
+			}
+		}
+		if !replaced {
+			presets = append(presets, p)
+		}
+	}
+	return presets, nil
+}
+
+func FindPreset(presets []*Preset, name string) (*Preset, error) {
+	for _, p := range presets {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown preset %q", name)
+}
+
+// Apply fills in the parameters that haven't been set
+func (p *Preset) Apply(params *Params) error {
+	pp := p.Params
+	if params.VideoPublishers == 0 {
+		params.VideoPublishers = pp.VideoPublishers
+	}
+	if params.AudioPublishers == 0 {
+		params.AudioPublishers = pp.AudioPublishers
+	}
+	if params.Subscribers == 0 {
+		params.Subscribers = pp.Subscribers
+	}
+	if params.Duration == 0 {
+		params.Duration = pp.Duration
+	}
+	if params.Ramp.Kind == "" && pp.Ramp != "" {
+		ramp, err := ParseRampProfile(pp.Ramp)
+		if err != nil {
+			return fmt.Errorf("preset %s: %w", p.Name, err)
+		}
+		params.Ramp = ramp
+	}
+	if params.VideoCodec == "" {
+		params.VideoCodec = pp.VideoCodec
+	}
+	if params.HiddenSubscribers == 0 {
+		params.HiddenSubscribers = pp.HiddenSubscribers
+	}
+	if params.DuplicateJoinRate == 0 {
+		params.DuplicateJoinRate = pp.DuplicateJoinRate
+	}
+	if params.RoomDownlinkCap == 0 && pp.RoomDownlinkCap != "" {
+		downlinkCap, err := ParseBitrate(pp.RoomDownlinkCap)
+		if err != nil {
+			return fmt.Errorf("preset %s: %w", p.Name, err)
+		}
+		params.RoomDownlinkCap = downlinkCap
+	}
+	if params.AudioPacketLoss == 0 {
+		params.AudioPacketLoss = pp.AudioPacketLoss
+	}
+	if params.Impairment.PacketLoss == 0 {
+		params.Impairment.PacketLoss = pp.PacketLoss
+	}
+	if params.Impairment.Jitter == 0 {
+		params.Impairment.Jitter = pp.Jitter
+	}
+	if params.Impairment.BandwidthCap == 0 && pp.BandwidthCap != "" {
+		bandwidthCap, err := ParseBitrate(pp.BandwidthCap)
+		if err != nil {
+			return fmt.Errorf("preset %s: %w", p.Name, err)
+		}
+		params.Impairment.BandwidthCap = bandwidthCap
+	}
+	if params.PublishRamp == 0 {
+		params.PublishRamp = pp.PublishRamp
+	}
+	if params.AudioPublishDelay == 0 {
+		params.AudioPublishDelay = pp.AudioPublishDelay
+	}
+	if params.VideoPublishDelay == 0 {
+		params.VideoPublishDelay = pp.VideoPublishDelay
+	}
+	if params.ResubscribeInterval == 0 {
+		params.ResubscribeInterval = pp.ResubscribeInterval
+	}
+	return nil
+}
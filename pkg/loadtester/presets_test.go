@@ -0,0 +1,61 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadPresets(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "soak.yaml"), []byte(`
+description: Nightly soak
+params:
+  video_publishers: 5
+  subscribers: 200
+  duration: 1h
+  ramp: step:50/2m
+`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "plc.yaml"), []byte(`
+name: audio-plc
+params:
+  audio_publishers: 2
+`), 0644))
+
+	presets, err := LoadPresets(dir)
+	require.NoError(t, err)
+	require.Len(t, presets, 2)
+
+	plc, err := FindPreset(presets, "audio-plc")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "plc.yaml"), plc.Path)
+
+	soak, err := FindPreset(presets, "soak")
+	require.NoError(t, err)
+	params := Params{Subscribers: 10}
+	require.NoError(t, soak.Apply(&params))
+	require.Equal(t, 5, params.VideoPublishers)
+	require.Equal(t, 10, params.Subscribers)
+	require.Equal(t, time.Hour, params.Duration)
+	require.Equal(t, RampProfile{Kind: RampStep, Duration: 2 * time.Minute, StepSize: 50}, params.Ramp)
+
+	_, err = FindPreset(presets, "missing")
+	require.Error(t, err)
+}
@@ -0,0 +1,94 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"sync"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+	"github.com/livekit/server-sdk-go/v2/signalling"
+)
+
+// the SDK always announces lksdk.PROTOCOL when joining, so testers pinned to an older
+// protocol join through a local proxy that rewrites the version on the way to the server.
+// only signaling goes through the proxy, media flows directly between tester and server.
+var (
+	protocolProxiesLock sync.Mutex
+	protocolProxies     = make(map[string]*protocolProxy)
+)
+
+type protocolProxy struct {
+	// websocket URL testers join instead of the server's
+	url    string
+	server *http.Server
+}
+
+// pinnedProtocolURL returns the URL that joins serverURL announcing the given protocol version,
+// starting a proxy for it on first use
+func pinnedProtocolURL(serverURL string, protocol int) (string, error) {
+	if protocol == 0 || protocol == lksdk.PROTOCOL {
+		return serverURL, nil
+	}
+	key := fmt.Sprintf("%s|%d", serverURL, protocol)
+
+	protocolProxiesLock.Lock()
+	defer protocolProxiesLock.Unlock()
+	if p := protocolProxies[key]; p != nil {
+		return p.url, nil
+	}
+	p, err := startProtocolProxy(serverURL, protocol)
+	if err != nil {
+		return "", err
+	}
+	protocolProxies[key] = p
+	return p.url, nil
+}
+
+func startProtocolProxy(serverURL string, protocol int) (*protocolProxy, error) {
+	target, err := url.Parse(signalling.ToHttpURL(serverURL))
+	if err != nil {
+		return nil, err
+	}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	p := &protocolProxy{
+		url: "ws://" + listener.Addr().String(),
+		server: &http.Server{
+			Handler: &httputil.ReverseProxy{
+				Rewrite: func(r *httputil.ProxyRequest) {
+					r.SetURL(target)
+					query := r.Out.URL.Query()
+					if query.Has("protocol") {
+						query.Set("protocol", strconv.Itoa(protocol))
+						r.Out.URL.RawQuery = query.Encode()
+					}
+				},
+			},
+		},
+	}
+	go func() {
+		_ = p.server.Serve(listener)
+	}()
+	return p, nil
+}
@@ -0,0 +1,66 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+func TestProtocolProxy(t *testing.T) {
+	protocols := make(chan string, 1)
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		protocols <- r.URL.Path + "?" + r.URL.Query().Get("protocol") + "&" + r.URL.Query().Get("auto_subscribe")
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		mt, msg, err := conn.ReadMessage()
+		if err == nil {
+			_ = conn.WriteMessage(mt, msg)
+		}
+	}))
+	defer server.Close()
+
+	u, err := pinnedProtocolURL(server.URL, lksdk.PROTOCOL)
+	require.NoError(t, err)
+	require.Equal(t, server.URL, u)
+
+	u, err = pinnedProtocolURL(server.URL, 8)
+	require.NoError(t, err)
+	require.NotEqual(t, server.URL, u)
+	again, err := pinnedProtocolURL(server.URL, 8)
+	require.NoError(t, err)
+	require.Equal(t, u, again)
+	defer protocolProxies[server.URL+"|8"].server.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial(u+"/rtc?protocol=12&auto_subscribe=0", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.Equal(t, "/rtc?8&0", <-protocols)
+
+	require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, []byte("ping")))
+	_, msg, err := conn.ReadMessage()
+	require.NoError(t, err)
+	require.Equal(t, "ping", string(msg))
+}
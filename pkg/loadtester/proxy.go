@@ -0,0 +1,79 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// ParseProxy parses the URL of a proxy testers connect through, http or socks5, the schemes the
+// WebSocket dialer supports
+func ParseProxy(str string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(str))
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %q: %w", str, err)
+	}
+	switch u.Scheme {
+	case "http", "socks5":
+	default:
+		return nil, fmt.Errorf("unsupported proxy %q, expected http:// or socks5://HOST:PORT", str)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy %q, missing host", str)
+	}
+	return u, nil
+}
+
+// LoadProxies reads a proxy URL from each line of a file, skipping empty lines and # comments
+func LoadProxies(path string) ([]*url.URL, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var proxies []*url.URL
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		u, err := ParseProxy(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		proxies = append(proxies, u)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(proxies) == 0 {
+		return nil, fmt.Errorf("%s lists no proxies", path)
+	}
+	return proxies, nil
+}
+
+// proxyFor spreads testers over the proxies in turn, nil without proxies
+func proxyFor(proxies []*url.URL, i int) *url.URL {
+	if len(proxies) == 0 {
+		return nil
+	}
+	return proxies[i%len(proxies)]
+}
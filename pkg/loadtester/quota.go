@@ -0,0 +1,77 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"fmt"
+	"time"
+)
+
+// approximate bitrates of the embedded media, used for capacity estimates
+const (
+	estimatedAudioBitrate = 32_000
+	// the highest quality clip at provider.ScreenShareFPS
+	estimatedScreenShareBitrate = 350_000
+)
+
+var estimatedVideoBitrates = map[string]int64{
+	"high":   2_000_000,
+	"medium": 600_000,
+	"low":    150_000,
+}
+
+// CloudQuota holds the limits of a LiveKit Cloud plan that a test should stay within
+type CloudQuota struct {
+	MaxParticipants int
+	// bits per second
+	MaxBandwidth int64
+}
+
+// estimatedLoad returns the peak number of participants and the combined
+// upstream and downstream bandwidth a test is expected to generate
+func (p *Params) estimatedLoad() (participants int, bandwidth int64) {
+	publishers := max(p.VideoPublishers, p.AudioPublishers, p.ScreenSharePublishers)
+	participants = (publishers + p.Subscribers) * p.RoomCount
+
+	videoBitrate, ok := estimatedVideoBitrates[p.VideoResolution]
+	if !ok {
+		videoBitrate = estimatedVideoBitrates["high"]
+	}
+	// audio and screen shares are received in full, regardless of the layout
+	unlaidOut := int64(p.AudioPublishers)*estimatedAudioBitrate + int64(p.ScreenSharePublishers)*estimatedScreenShareBitrate
+	upstream := int64(p.VideoPublishers)*videoBitrate + unlaidOut
+	visible := min(p.VideoPublishers, layoutSlots(p.Layout))
+	downstream := int64(p.Subscribers) * (int64(visible)*videoBitrate + unlaidOut)
+	bandwidth = (upstream + downstream) * int64(p.RoomCount)
+	return
+}
+
+// checkCloudQuota returns a warning for every limit the test is expected to exceed
+func checkCloudQuota(params Params) []string {
+	var warnings []string
+	participants, bandwidth := params.estimatedLoad()
+	quota := params.CloudQuota
+	if quota.MaxParticipants > 0 && participants > quota.MaxParticipants {
+		warnings = append(warnings, fmt.Sprintf(
+			"test will reach %d concurrent participants, exceeding the plan limit of %d",
+			participants, quota.MaxParticipants))
+	}
+	if quota.MaxBandwidth > 0 && bandwidth > quota.MaxBandwidth {
+		warnings = append(warnings, fmt.Sprintf(
+			"test is estimated to use %s, exceeding the plan limit of %s",
+			formatBitrate(bandwidth/8, time.Second), formatBitrate(quota.MaxBandwidth/8, time.Second)))
+	}
+	return warnings
+}
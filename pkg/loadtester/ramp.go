@@ -0,0 +1,166 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// start testers evenly over a duration, e.g. linear:10m
+	RampLinear = "linear"
+	// start a batch of testers at a fixed interval, e.g. step:50/2m
+	RampStep = "step"
+	// start all testers at once
+	RampSpike = "spike"
+
+	// a linear ramp is reported in tenths of its duration
+	linearRampSteps = 10
+)
+
+// RampProfile shapes how testers are started over time. The zero value starts testers at NumPerSecond.
+type RampProfile struct {
+	Kind string
+	// linear: time to start all testers, step: time between steps
+	Duration time.Duration
+	// step: number of testers started in each step
+	StepSize int
+}
+
+// ParseRampProfile parses linear:DURATION, step:COUNT/DURATION or spike
+func ParseRampProfile(str string) (RampProfile, error) {
+	kind, arg, _ := strings.Cut(strings.TrimSpace(str), ":")
+	switch kind {
+	case RampLinear:
+		d, err := time.ParseDuration(arg)
+		if err != nil || d <= 0 {
+			return RampProfile{}, fmt.Errorf("invalid ramp %q, expected linear:DURATION", str)
+		}
+		return RampProfile{Kind: RampLinear, Duration: d}, nil
+	case RampStep:
+		count, interval, _ := strings.Cut(arg, "/")
+		n, err := strconv.Atoi(count)
+		if err != nil || n <= 0 {
+			return RampProfile{}, fmt.Errorf("invalid ramp %q, expected step:COUNT/DURATION", str)
+		}
+		d, err := time.ParseDuration(interval)
+		if err != nil || d <= 0 {
+			return RampProfile{}, fmt.Errorf("invalid ramp %q, expected step:COUNT/DURATION", str)
+		}
+		return RampProfile{Kind: RampStep, Duration: d, StepSize: n}, nil
+	case RampSpike:
+		if arg != "" {
+			return RampProfile{}, fmt.Errorf("invalid ramp %q, spike takes no arguments", str)
+		}
+		return RampProfile{Kind: RampSpike}, nil
+	default:
+		return RampProfile{}, fmt.Errorf("unknown ramp %q, choose from linear:DURATION, step:COUNT/DURATION, spike", str)
+	}
+}
+
+func (r RampProfile) String() string {
+	switch r.Kind {
+	case RampLinear:
+		return fmt.Sprintf("%s:%s", r.Kind, r.Duration)
+	case RampStep:
+		return fmt.Sprintf("%s:%d/%s", r.Kind, r.StepSize, r.Duration)
+	case RampSpike:
+		return r.Kind
+	default:
+		return ""
+	}
+}
+
+// rampSchedule maps the k-th tester of a run to its start time and ramp step
+type rampSchedule struct {
+	profile   RampProfile
+	total     int
+	perSecond float64
+}
+
+func newRampSchedule(params Params, total int) *rampSchedule {
+	return &rampSchedule{
+		profile:   params.Ramp,
+		total:     max(total, 1),
+		perSecond: params.NumPerSecond,
+	}
+}
+
+// offset returns when the k-th tester should start, relative to the start of the ramp
+func (s *rampSchedule) offset(k int) time.Duration {
+	switch s.profile.Kind {
+	case RampLinear:
+		return time.Duration(int64(s.profile.Duration) * int64(k) / int64(s.total))
+	case RampStep:
+		return time.Duration(k/s.profile.StepSize) * s.profile.Duration
+	case RampSpike:
+		return 0
+	default:
+		return time.Duration(float64(k) / s.perSecond * float64(time.Second))
+	}
+}
+
+// step returns the ramp step the k-th tester belongs to, a step is one second of a flat rate
+func (s *rampSchedule) step(k int) int {
+	switch s.profile.Kind {
+	case RampLinear:
+		return k * linearRampSteps / s.total
+	case RampStep:
+		return k / s.profile.StepSize
+	case RampSpike:
+		return 0
+	default:
+		return int(float64(k) / s.perSecond)
+	}
+}
+
+func (s *rampSchedule) numSteps() int {
+	return s.step(s.total-1) + 1
+}
+
+// stepOffset returns when the given step starts, relative to the start of the ramp
+func (s *rampSchedule) stepOffset(step int) time.Duration {
+	switch s.profile.Kind {
+	case RampLinear:
+		return s.profile.Duration * time.Duration(step) / linearRampSteps
+	case RampStep:
+		return s.profile.Duration * time.Duration(step)
+	case RampSpike:
+		return 0
+	default:
+		return time.Duration(step) * time.Second
+	}
+}
+
+// firstFailedStep returns the earliest ramp step with a tester that failed to connect or publish, or -1
+func firstFailedStep(stats map[string]*testerStats) int {
+	first := -1
+	for _, s := range stats {
+		if s.err != nil && (first == -1 || s.rampStep < first) {
+			first = s.rampStep
+		}
+	}
+	return first
+}
+
+func (s *rampSchedule) formatStep(step int) string {
+	if step < 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d/%d (+%s)", step+1, s.numSteps(), s.stepOffset(step).Round(time.Second))
+}
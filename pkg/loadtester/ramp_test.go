@@ -0,0 +1,74 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRampProfile(t *testing.T) {
+	r, err := ParseRampProfile("linear:10m")
+	require.NoError(t, err)
+	require.Equal(t, RampProfile{Kind: RampLinear, Duration: 10 * time.Minute}, r)
+
+	r, err = ParseRampProfile("step:50/2m")
+	require.NoError(t, err)
+	require.Equal(t, RampProfile{Kind: RampStep, Duration: 2 * time.Minute, StepSize: 50}, r)
+	require.Equal(t, "step:50/2m0s", r.String())
+
+	r, err = ParseRampProfile("spike")
+	require.NoError(t, err)
+	require.Equal(t, RampSpike, r.Kind)
+
+	for _, invalid := range []string{"linear", "linear:-1m", "step:50", "step:0/1m", "spike:1", "exponential"} {
+		_, err = ParseRampProfile(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestRampSchedule(t *testing.T) {
+	linear := newRampSchedule(Params{Ramp: RampProfile{Kind: RampLinear, Duration: 100 * time.Second}}, 100)
+	require.Equal(t, 50*time.Second, linear.offset(50))
+	require.Equal(t, 5, linear.step(50))
+	require.Equal(t, 10, linear.numSteps())
+
+	step := newRampSchedule(Params{Ramp: RampProfile{Kind: RampStep, Duration: 2 * time.Minute, StepSize: 50}}, 120)
+	require.Equal(t, time.Duration(0), step.offset(49))
+	require.Equal(t, 2*time.Minute, step.offset(50))
+	require.Equal(t, 4*time.Minute, step.offset(119))
+	require.Equal(t, 3, step.numSteps())
+	require.Equal(t, "2/3 (+2m0s)", step.formatStep(1))
+
+	spike := newRampSchedule(Params{Ramp: RampProfile{Kind: RampSpike}}, 100)
+	require.Equal(t, time.Duration(0), spike.offset(99))
+	require.Equal(t, 1, spike.numSteps())
+
+	flat := newRampSchedule(Params{NumPerSecond: 5}, 20)
+	require.Equal(t, time.Second, flat.offset(5))
+	require.Equal(t, 3, flat.step(15))
+}
+
+func TestFirstFailedStep(t *testing.T) {
+	require.Equal(t, -1, firstFailedStep(map[string]*testerStats{"Sub 0": {rampStep: 1}}))
+	require.Equal(t, 2, firstFailedStep(map[string]*testerStats{
+		"Sub 0": {rampStep: 1},
+		"Sub 1": {rampStep: 3, err: errors.New("failed")},
+		"Sub 2": {rampStep: 2, err: errors.New("failed")},
+	}))
+}
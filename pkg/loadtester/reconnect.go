@@ -0,0 +1,271 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"fmt"
+	"maps"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+const (
+	// drop the signal connection and resume the session
+	ReconnectResume = "resume"
+	// have the server close the session, so the tester rejoins and republishes
+	ReconnectFull = "full"
+
+	// received media pausing for longer than this counts as an interruption
+	DefaultMaxMediaGap = 500 * time.Millisecond
+
+	// an attempt fails when the tester hasn't reconnected within this time
+	reconnectTimeout = 30 * time.Second
+)
+
+// ValidateReconnectMode checks a --reconnect-mode value
+func ValidateReconnectMode(mode string) error {
+	switch mode {
+	case "", ReconnectResume, ReconnectFull:
+		return nil
+	}
+	return fmt.Errorf("unsupported reconnect mode %q, expected %q or %q", mode, ReconnectResume, ReconnectFull)
+}
+
+type pendingReconnect struct {
+	requestedAt time.Time
+	// SIDs of the tracks the tester published before reconnecting
+	published []string
+}
+
+type reconnectSample struct {
+	// from triggering the reconnect until the room reconnected
+	reconnect time.Duration
+	// from triggering the reconnect until media arrived again, subscribers only
+	resubscribe time.Duration
+}
+
+// reconnectWorker periodically forces the tester to reconnect, as network changes would on real
+// clients. Testers start at a random point in the interval, so that reconnects are spread out.
+func (t *LoadTester) reconnectWorker() {
+	interval := t.params.ReconnectInterval
+	time.Sleep(time.Duration(rand.Int63n(int64(interval))))
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for t.IsRunning() {
+		t.reconnect()
+		<-ticker.C
+	}
+}
+
+func (t *LoadTester) reconnect() {
+	t.lock.Lock()
+	if t.pendingReconnect != nil {
+		// the previous attempt is still in progress
+		t.lock.Unlock()
+		return
+	}
+	attempt := &pendingReconnect{requestedAt: time.Now()}
+	if t.params.ReconnectMode != ReconnectFull {
+		// a resumed session keeps its tracks, a full reconnect publishes them again
+		for _, pub := range t.room.LocalParticipant.TrackPublications() {
+			attempt.published = append(attempt.published, pub.SID())
+		}
+	}
+	t.pendingReconnect = attempt
+	t.reconnectAttempts++
+	if t.params.ReconnectMode == ReconnectFull {
+		// remote participants are replaced after a full reconnect, and their tracks subscribed again
+		t.trackQualities = make(map[string]livekit.VideoQuality)
+	}
+	t.lock.Unlock()
+
+	time.AfterFunc(reconnectTimeout, func() {
+		t.onReconnectFailed(attempt)
+	})
+	if t.params.ReconnectMode == ReconnectFull {
+		t.room.Simulate(lksdk.SimulateServerLeave)
+	} else {
+		t.room.Simulate(lksdk.SimulateSignalReconnect)
+	}
+}
+
+func (t *LoadTester) onReconnected() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	attempt := t.pendingReconnect
+	if attempt == nil {
+		return
+	}
+	sample := &reconnectSample{reconnect: time.Since(attempt.requestedAt)}
+	t.reconnects = append(t.reconnects, sample)
+	t.pendingReconnect = nil
+	if len(attempt.published) > 0 {
+		t.unresumedTracks = append(t.unresumedTracks, unresumedTracks(attempt.published, t.room.LocalParticipant.TrackPublications())...)
+	}
+	if t.params.Subscribe && len(t.subscribedParticipants) > 0 {
+		t.resubscribing = sample
+		t.reconnectedFrom = attempt.requestedAt
+		t.awaitingMedia.Store(true)
+	}
+}
+
+// onReconnectMedia completes a reconnect once the first packet after it is received
+func (t *LoadTester) onReconnectMedia() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.resubscribing == nil {
+		return
+	}
+	t.resubscribing.resubscribe = time.Since(t.reconnectedFrom)
+	t.resubscribing = nil
+}
+
+// onReconnectFailed counts attempt as failed unless the tester reconnected in the meantime
+func (t *LoadTester) onReconnectFailed(attempt *pendingReconnect) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.pendingReconnect != attempt {
+		return
+	}
+	t.pendingReconnect = nil
+	t.reconnectFailures++
+}
+
+// unresumedTracks returns the SIDs in published that are no longer among the tester's publications
+func unresumedTracks(published []string, current []lksdk.TrackPublication) []string {
+	var missing []string
+	for _, sid := range published {
+		if !slices.ContainsFunc(current, func(pub lksdk.TrackPublication) bool { return pub.SID() == sid }) {
+			missing = append(missing, sid)
+		}
+	}
+	return missing
+}
+
+func (t *LoadTester) maxMediaGap() time.Duration {
+	if t.params.MaxMediaGap > 0 {
+		return t.params.MaxMediaGap
+	}
+	return DefaultMaxMediaGap
+}
+
+// recordPacket tracks pauses longer than maxGap in the media received on a track
+func (s *trackStats) recordPacket(now time.Time, maxGap time.Duration) {
+	last := s.lastPacketAt.Swap(now.UnixNano())
+	if last == 0 {
+		return
+	}
+	if gap := now.Sub(time.Unix(0, last)); gap > maxGap {
+		s.mediaGaps.Inc()
+		for {
+			longest := s.longestGap.Load()
+			if int64(gap) <= longest || s.longestGap.CompareAndSwap(longest, int64(gap)) {
+				break
+			}
+		}
+	}
+}
+
+// mediaGaps sums the pauses in received media over all of the tester's tracks
+func (s *testerStats) mediaGaps() (int64, time.Duration) {
+	var gaps int64
+	var longest time.Duration
+	for _, ts := range s.trackStats {
+		gaps += ts.mediaGaps.Load()
+		longest = max(longest, time.Duration(ts.longestGap.Load()))
+	}
+	return gaps, longest
+}
+
+func printReconnectStats(stats map[string]*testerStats, names []string, maxGap time.Duration) {
+	table := util.CreateTable().
+		Headers("Tester", "Attempts", "Succeeded", "Failed", "Success", "Reconnect p50", "Reconnect p95",
+			"Resubscribe p50", "Resubscribe p95", "Unresumed Tracks", "Media Gaps", "Longest Gap")
+	rows := 0
+	for _, name := range names {
+		s := stats[name]
+		if s.reconnectAttempts == 0 {
+			continue
+		}
+		var reconnects, resubscribes []time.Duration
+		for _, r := range s.reconnects {
+			reconnects = append(reconnects, r.reconnect)
+			if r.resubscribe > 0 {
+				resubscribes = append(resubscribes, r.resubscribe)
+			}
+		}
+		reconnect := getLatencyPercentiles(reconnects)
+		resubscribe := getLatencyPercentiles(resubscribes)
+		gaps, longest := s.mediaGaps()
+		table.Row(
+			name,
+			fmt.Sprint(s.reconnectAttempts),
+			fmt.Sprint(len(s.reconnects)),
+			fmt.Sprint(s.reconnectFailures),
+			formatPercentage(int64(len(s.reconnects)), int64(s.reconnectAttempts))+"%",
+			reconnect.p50.Round(time.Millisecond).String(),
+			reconnect.p95.Round(time.Millisecond).String(),
+			resubscribe.p50.Round(time.Millisecond).String(),
+			resubscribe.p95.Round(time.Millisecond).String(),
+			fmt.Sprint(len(s.unresumedTracks)),
+			fmt.Sprint(gaps),
+			longest.Round(time.Millisecond).String(),
+		)
+		rows++
+	}
+	if rows == 0 {
+		return
+	}
+	fmt.Println("\nForced reconnects:")
+	fmt.Println(table)
+
+	// violations are listed for every tester, including those left out of the report
+	violations := reconnectViolations(stats, maxGap)
+	if len(violations) > 0 {
+		fmt.Printf("\nTrack resumption violations (%d):\n", len(violations))
+		for _, v := range violations {
+			fmt.Println("  " + v)
+		}
+	}
+}
+
+// reconnectViolations lists published tracks that didn't resume with the same SID, and
+// subscribers whose media paused for longer than maxGap
+func reconnectViolations(stats map[string]*testerStats, maxGap time.Duration) []string {
+	var violations []string
+	for _, name := range slices.Sorted(maps.Keys(stats)) {
+		s := stats[name]
+		if s.reconnectAttempts == 0 {
+			continue
+		}
+		for _, sid := range s.unresumedTracks {
+			violations = append(violations, fmt.Sprintf("%s: track %s was not resumed after reconnecting", name, sid))
+		}
+		if gaps, longest := s.mediaGaps(); gaps > 0 {
+			violations = append(violations, fmt.Sprintf("%s: %d media gaps over %s, longest %s",
+				name, gaps, maxGap, longest.Round(time.Millisecond)))
+		}
+	}
+	return violations
+}
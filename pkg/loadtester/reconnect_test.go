@@ -0,0 +1,95 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+func TestReconnectTiming(t *testing.T) {
+	tester := NewLoadTester(TesterParams{Subscribe: true})
+	tester.subscribedParticipants["abc_pub-video-0"] = &lksdk.RemoteParticipant{}
+
+	attempt := &pendingReconnect{requestedAt: time.Now().Add(-50 * time.Millisecond)}
+	tester.pendingReconnect = attempt
+	tester.reconnectAttempts = 1
+	tester.onReconnected()
+	// reconnects not triggered by the tester are not counted
+	tester.onReconnected()
+	require.True(t, tester.awaitingMedia.Load())
+	tester.onReconnectMedia()
+	// the timeout of a successful attempt has no effect
+	tester.onReconnectFailed(attempt)
+
+	stats := tester.getStats()
+	require.Equal(t, 1, stats.reconnectAttempts)
+	require.Equal(t, 0, stats.reconnectFailures)
+	require.Len(t, stats.reconnects, 1)
+	require.GreaterOrEqual(t, stats.reconnects[0].reconnect, 50*time.Millisecond)
+	require.GreaterOrEqual(t, stats.reconnects[0].resubscribe, stats.reconnects[0].reconnect)
+
+	attempt = &pendingReconnect{requestedAt: time.Now()}
+	tester.pendingReconnect = attempt
+	tester.reconnectAttempts++
+	tester.onReconnectFailed(attempt)
+	stats = tester.getStats()
+	require.Equal(t, 2, stats.reconnectAttempts)
+	require.Equal(t, 1, stats.reconnectFailures)
+	require.Nil(t, tester.pendingReconnect)
+
+	require.NoError(t, ValidateReconnectMode(ReconnectFull))
+	require.Error(t, ValidateReconnectMode("restart"))
+}
+
+func TestMediaGaps(t *testing.T) {
+	ts := &trackStats{}
+	start := time.Now()
+	ts.recordPacket(start, DefaultMaxMediaGap)
+	ts.recordPacket(start.Add(20*time.Millisecond), DefaultMaxMediaGap)
+	ts.recordPacket(start.Add(time.Second), DefaultMaxMediaGap)
+	ts.recordPacket(start.Add(1020*time.Millisecond), DefaultMaxMediaGap)
+	ts.recordPacket(start.Add(3*time.Second), DefaultMaxMediaGap)
+
+	require.Equal(t, int64(2), ts.mediaGaps.Load())
+	require.Equal(t, int64(1980*time.Millisecond), ts.longestGap.Load())
+
+	// a higher threshold tolerates the shorter gap
+	ts = &trackStats{}
+	ts.recordPacket(start, time.Second)
+	ts.recordPacket(start.Add(time.Second), time.Second)
+	ts.recordPacket(start.Add(3*time.Second), time.Second)
+	require.Equal(t, int64(1), ts.mediaGaps.Load())
+}
+
+func TestReconnectViolations(t *testing.T) {
+	gaps := &trackStats{}
+	gaps.mediaGaps.Store(2)
+	gaps.longestGap.Store(int64(1200 * time.Millisecond))
+	stats := map[string]*testerStats{
+		"Pub 0": {reconnectAttempts: 1, unresumedTracks: []string{"TR_1"}},
+		"Sub 0": {reconnectAttempts: 1, trackStats: map[string]*trackStats{"TR_1": gaps}},
+		// gaps without forced reconnects are not violations
+		"Sub 1": {trackStats: map[string]*trackStats{"TR_1": gaps}},
+	}
+	require.Equal(t, []string{
+		"Pub 0: track TR_1 was not resumed after reconnecting",
+		"Sub 0: 2 media gaps over 500ms, longest 1.2s",
+	}, reconnectViolations(stats, DefaultMaxMediaGap))
+}
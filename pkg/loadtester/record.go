@@ -0,0 +1,110 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// scenarioRecorder captures the phases a run actually went through, with the time each of them
+// lasted, so that an exploratory run stopped by hand can be replayed with --scenario
+type scenarioRecorder struct {
+	params   Params
+	scenario Scenario
+}
+
+func newScenarioRecorder(params Params, name string) *scenarioRecorder {
+	if params.RecordScenarioPath == "" {
+		return nil
+	}
+	return &scenarioRecorder{params: params, scenario: Scenario{Name: name}}
+}
+
+// phase records a phase that ran for elapsed, which is shorter than planned when interrupted
+func (r *scenarioRecorder) phase(phase ScenarioPhase, elapsed time.Duration) {
+	if r == nil {
+		return
+	}
+	switch phase.Kind {
+	case PhaseHold, PhaseChurn, PhaseMuteStorm:
+		// what mattered is how long the phase lasted, not how long it was planned to
+		if elapsed < time.Second {
+			// too short to replay
+			return
+		}
+		phase.Duration = elapsed.Round(time.Second)
+	}
+	r.scenario.Phases = append(r.scenario.Phases, &phase)
+}
+
+// ramp records the population of a plain run
+func (r *scenarioRecorder) ramp(params Params) {
+	if r == nil {
+		return
+	}
+	video, audio, subscribers := params.VideoPublishers, params.AudioPublishers, params.Subscribers
+	r.phase(ScenarioPhase{
+		Name:            "ramp",
+		Kind:            PhaseRamp,
+		VideoPublishers: &video,
+		AudioPublishers: &audio,
+		Subscribers:     &subscribers,
+		NumPerSecond:    params.NumPerSecond,
+	}, 0)
+}
+
+// write saves the recorded scenario, preceded by the flags it was run with that it doesn't
+// capture itself
+func (r *scenarioRecorder) write() error {
+	if r == nil || len(r.scenario.Phases) == 0 {
+		return nil
+	}
+	comment := []string{
+		fmt.Sprintf("recorded %s, replay with:", time.Now().Format(time.RFC3339)),
+		fmt.Sprintf("  lk load-test %s --scenario %s", strings.Join(scenarioFlags(r.params), " "), r.params.RecordScenarioPath),
+	}
+	if r.params.DataPublishers > 0 || r.params.ScreenSharePublishers > 0 {
+		comment = append(comment, "data and screen share publishers are not part of scenarios, and were left out")
+	}
+	if err := r.scenario.Save(r.params.RecordScenarioPath, comment...); err != nil {
+		return err
+	}
+	fmt.Printf("Recorded %d phases to %s\n", len(r.scenario.Phases), r.params.RecordScenarioPath)
+	return nil
+}
+
+// scenarioFlags are the flags shaping a run that a scenario file doesn't hold
+func scenarioFlags(params Params) []string {
+	flags := []string{"--room-count", strconv.Itoa(max(params.RoomCount, 1))}
+	if params.VideoResolution != "" {
+		flags = append(flags, "--video-resolution", params.VideoResolution)
+	}
+	if params.VideoCodec != "" {
+		flags = append(flags, "--video-codec", params.VideoCodec)
+	}
+	if params.Layout != "" {
+		flags = append(flags, "--layout", string(params.Layout))
+	}
+	if !params.Simulcast {
+		flags = append(flags, "--no-simulcast")
+	}
+	if params.NumPerSecond > 0 {
+		flags = append(flags, "--num-per-second", strconv.FormatFloat(params.NumPerSecond, 'f', -1, 64))
+	}
+	return flags
+}
@@ -0,0 +1,64 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScenarioRecorder(t *testing.T) {
+	require.Nil(t, newScenarioRecorder(Params{}, "recorded"))
+	var r *scenarioRecorder
+	r.ramp(Params{})
+	require.NoError(t, r.write())
+
+	path := filepath.Join(t.TempDir(), "recorded.yaml")
+	params := Params{RecordScenarioPath: path, RoomCount: 2, DataPublishers: 1}
+	params.VideoPublishers = 3
+	params.Subscribers = 10
+	params.NumPerSecond = 2.5
+	params.VideoCodec = "vp8"
+	params.Simulcast = true
+	r = newScenarioRecorder(params, "recorded")
+	r.ramp(params)
+	r.phase(ScenarioPhase{Name: "hold", Kind: PhaseHold}, 95*time.Second+300*time.Millisecond)
+	// interrupted before it really started
+	r.phase(ScenarioPhase{Name: "churn", Kind: PhaseChurn, Duration: time.Minute, ChurnRate: 0.1}, 200*time.Millisecond)
+	require.NoError(t, r.write())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "lk load-test --room-count 2 --video-codec vp8 --num-per-second 2.5 --scenario "+path)
+	require.Contains(t, string(data), "data and screen share publishers")
+	require.False(t, strings.Contains(string(data), "--no-simulcast"))
+
+	s, err := LoadScenario(path, nil)
+	require.NoError(t, err)
+	require.Equal(t, "recorded", s.Name)
+	require.Len(t, s.Phases, 2)
+	require.Equal(t, PhaseRamp, s.Phases[0].Kind)
+	require.Equal(t, 3, *s.Phases[0].VideoPublishers)
+	require.Equal(t, 0, *s.Phases[0].AudioPublishers)
+	require.Equal(t, 10, *s.Phases[0].Subscribers)
+	require.Equal(t, 2.5, s.Phases[0].NumPerSecond)
+	require.Equal(t, PhaseHold, s.Phases[1].Kind)
+	require.Equal(t, 95*time.Second, s.Phases[1].Duration)
+}
@@ -0,0 +1,73 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang.org/x/sync/syncmap"
+)
+
+// how often the population is checked for testers that dropped out
+const replaceCheckInterval = 5 * time.Second
+
+// testerFailed is true when a tester could not join or publish, or was disconnected for good since
+func testerFailed(tester *LoadTester, errs *syncmap.Map) bool {
+	if _, failed := errs.Load(tester.params.name); failed {
+		return true
+	}
+	return !tester.IsRunning() || tester.disconnectReason.Load() != ""
+}
+
+// replaceFailed keeps the population of a ramped up test steady until ctx is canceled, replacing
+// every tester that fails with a new one in the same room, role and identity. It returns the number
+// of replacements by tester name.
+func (t *LoadTest) replaceFailed(ctx context.Context, params Params, testers []*LoadTester, errs *syncmap.Map) map[string]int {
+	ticker := time.NewTicker(replaceCheckInterval)
+	defer ticker.Stop()
+	replaced := make(map[string]int)
+	for {
+		select {
+		case <-ctx.Done():
+			return replaced
+		case <-ticker.C:
+		}
+		for n, failed := range testers {
+			if ctx.Err() != nil {
+				return replaced
+			}
+			if !testerFailed(failed, errs) {
+				continue
+			}
+			failed.Stop()
+			tester := NewLoadTester(failed.params)
+			testers[n] = tester
+			t.status.replaceTester(failed, tester)
+
+			i := tester.params.Sequence
+			err := t.startTesterWithRetries(ctx, params, tester,
+				i < params.VideoPublishers, i < params.AudioPublishers, i < params.DataPublishers, i < params.ScreenSharePublishers)
+			if err != nil {
+				errs.Store(tester.params.name, err)
+				continue
+			}
+			errs.Delete(tester.params.name)
+			replaced[tester.params.name]++
+			fmt.Printf("%s replaced %s in %s\n", time.Now().Format(time.TimeOnly), tester.params.name, tester.params.Room)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/syncmap"
+)
+
+func TestTesterFailed(t *testing.T) {
+	errs := &syncmap.Map{}
+	tester := NewLoadTester(TesterParams{name: "Sub 0"})
+	// never connected
+	require.True(t, testerFailed(tester, errs))
+
+	tester.running.Store(true)
+	require.False(t, testerFailed(tester, errs))
+
+	errs.Store("Sub 0", errors.New("could not publish"))
+	require.True(t, testerFailed(tester, errs))
+	errs.Delete("Sub 0")
+
+	tester.disconnectReason.Store("server shutdown")
+	require.True(t, testerFailed(tester, errs))
+}
+
+func TestReplaceFailed(t *testing.T) {
+	lt := NewLoadTest(Params{})
+	failed := NewLoadTester(TesterParams{name: "Sub 0"})
+	other := NewLoadTester(TesterParams{name: "Sub 1"})
+	lt.status.addTester(failed)
+	lt.status.addTester(other)
+
+	replacement := NewLoadTester(TesterParams{name: "Sub 0"})
+	lt.status.replaceTester(failed, replacement)
+	require.Equal(t, []*LoadTester{replacement, other}, lt.status.startedTesters())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.Empty(t, lt.replaceFailed(ctx, lt.Params, []*LoadTester{replacement}, &syncmap.Map{}))
+
+	stats := map[string]*testerStats{
+		"Sub 0": {trackStats: map[string]*trackStats{}, role: RoleSubscriber, replacements: 2},
+		"Pub 0": {trackStats: map[string]*trackStats{}, role: RoleVideoPublisher, replacements: 1},
+	}
+	results := getResults(stats, []string{"Sub 0"})
+	require.Equal(t, 3, results.ReplacedTesters)
+	require.Equal(t, 2, results.Testers[0].Replacements)
+	require.Equal(t, 2, results.Total.Replacements)
+}
@@ -0,0 +1,138 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// tells report receivers whether the body is the final Results or a reportSnapshot
+	ReportTypeHeader   = "X-LK-Report-Type"
+	ReportTypeFinal    = "final"
+	ReportTypeSnapshot = "snapshot"
+
+	reportAttempts = 3
+)
+
+// ReportSink posts the final results as JSON to URL, retrying failed requests, so that runs on
+// ephemeral machines end up somewhere durable. As Params.Report, it also receives snapshots
+// every Params.ReportInterval while the test runs.
+type ReportSink struct {
+	URL string
+	// added to every request, e.g. for authorization
+	Header http.Header
+}
+
+// reportSnapshot is the progress of a running test
+type reportSnapshot struct {
+	At     time.Time        `json:"at"`
+	Status *statusReport    `json:"status"`
+	Total  *TesterResults   `json:"total"`
+	Rooms  []*TesterResults `json:"rooms,omitempty"`
+}
+
+// ParseReportHeader parses a "Name: value" request header
+func ParseReportHeader(header http.Header, spec string) error {
+	name, value, ok := strings.Cut(spec, ":")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("invalid header %q, expected Name: value", spec)
+	}
+	header.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	return nil
+}
+
+func (s *ReportSink) WriteStats(ctx context.Context, results *Results) error {
+	body, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+	return s.post(ctx, ReportTypeFinal, body)
+}
+
+func (s *ReportSink) post(ctx context.Context, reportType string, body []byte) error {
+	header := s.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Set(ReportTypeHeader, reportType)
+
+	var err error
+	for attempt := 0; attempt < reportAttempts; attempt++ {
+		if attempt > 0 && !sleepUntil(ctx, time.Now().Add(time.Duration(attempt)*time.Second)) {
+			break
+		}
+		if err = postStats(ctx, http.MethodPost, s.URL, "application/json", body, header); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+// postSnapshots posts the progress of the test to the report sink at interval, until the
+// returned function is called
+func (t *LoadTest) postSnapshots(ctx context.Context, interval time.Duration) func() {
+	sink := t.Params.Report
+	if interval <= 0 || sink == nil {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			body, err := json.Marshal(t.snapshot())
+			if err != nil {
+				continue
+			}
+			if err := sink.post(ctx, ReportTypeSnapshot, body); err != nil && ctx.Err() == nil {
+				fmt.Println("could not post snapshot:", err)
+			}
+		}
+	}()
+	return cancel
+}
+
+// snapshot sums up the stats of the testers started so far
+func (t *LoadTest) snapshot() *reportSnapshot {
+	stats := make(map[string]*testerStats)
+	for _, tester := range t.status.startedTesters() {
+		s := tester.getStats()
+		s.role = tester.params.Role
+		s.room = tester.params.Room
+		stats[tester.params.name] = s
+	}
+	results := getResults(stats, reportNames(stats, t.Params.ReportRoles))
+	if len(results.Rooms) < 2 {
+		results.Rooms = nil
+	}
+	return &reportSnapshot{
+		At:     time.Now(),
+		Status: t.status.report(),
+		Total:  results.Total,
+		Rooms:  results.Rooms,
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportSink(t *testing.T) {
+	var lock sync.Mutex
+	var requests []*http.Request
+	var bodies [][]byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		requests = append(requests, r)
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, body)
+		// the first attempt fails
+		if len(requests) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer server.Close()
+
+	header := make(http.Header)
+	require.NoError(t, ParseReportHeader(header, "Authorization: Bearer token"))
+	require.Error(t, ParseReportHeader(header, "no value"))
+	sink := &ReportSink{URL: server.URL, Header: header}
+	results := &Results{Total: &TesterResults{Name: "Total", Packets: 10}}
+	require.NoError(t, sink.WriteStats(context.Background(), results))
+
+	require.Len(t, requests, 2)
+	require.Equal(t, "Bearer token", requests[1].Header.Get("Authorization"))
+	require.Equal(t, ReportTypeFinal, requests[1].Header.Get(ReportTypeHeader))
+	decoded := &Results{}
+	require.NoError(t, json.Unmarshal(bodies[1], decoded))
+	require.Equal(t, int64(10), decoded.Total.Packets)
+
+	// snapshots are posted until stopped
+	test := NewLoadTest(Params{Report: sink})
+	test.status.begin(1)
+	test.status.addTester(NewLoadTester(TesterParams{Role: RoleSubscriber, name: "Sub 0"}))
+	stop := test.postSnapshots(context.Background(), 10*time.Millisecond)
+	require.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(requests) > 2
+	}, time.Second, 5*time.Millisecond)
+	stop()
+
+	lock.Lock()
+	defer lock.Unlock()
+	require.Equal(t, ReportTypeSnapshot, requests[2].Header.Get(ReportTypeHeader))
+	snapshot := &reportSnapshot{}
+	require.NoError(t, json.Unmarshal(bodies[2], snapshot))
+	require.Equal(t, 1, snapshot.Status.StartedTesters)
+	require.Equal(t, 1, snapshot.Total.Testers)
+}
@@ -0,0 +1,167 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+// time a track stays unsubscribed before it's subscribed again
+const resubscribeGap = time.Second
+
+type pendingResubscribe struct {
+	requestedAt  time.Time
+	subscribedAt time.Time
+}
+
+type resubscribeSample struct {
+	// from requesting the subscription until the track is subscribed
+	subscribe time.Duration
+	// from subscribing until the first keyframe arrives, video only
+	keyframe time.Duration
+}
+
+// resubscribeWorker periodically unsubscribes from a random subscribed track and subscribes to it again,
+// like a grid UI scrolling tracks in and out of view
+func (t *LoadTester) resubscribeWorker() {
+	ticker := time.NewTicker(t.params.ResubscribeInterval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if !t.IsRunning() {
+			return
+		}
+		if pub := t.randomSubscribedPublication(); pub != nil {
+			t.resubscribe(pub)
+		}
+	}
+}
+
+func (t *LoadTester) randomSubscribedPublication() *lksdk.RemoteTrackPublication {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var pubs []*lksdk.RemoteTrackPublication
+	for _, p := range t.subscribedParticipants {
+		for _, pub := range p.TrackPublications() {
+			if remotePub, ok := pub.(*lksdk.RemoteTrackPublication); ok && remotePub.IsSubscribed() {
+				pubs = append(pubs, remotePub)
+			}
+		}
+	}
+	if len(pubs) == 0 {
+		return nil
+	}
+	return pubs[rand.Intn(len(pubs))]
+}
+
+func (t *LoadTester) resubscribe(pub *lksdk.RemoteTrackPublication) {
+	if err := t.unsubscribe(pub); err != nil {
+		return
+	}
+	time.Sleep(resubscribeGap)
+	if !t.IsRunning() {
+		return
+	}
+
+	t.lock.Lock()
+	t.pendingResubscribes[pub.SID()] = &pendingResubscribe{requestedAt: time.Now()}
+	t.lock.Unlock()
+	if err := pub.SetSubscribed(true); err != nil {
+		t.lock.Lock()
+		delete(t.pendingResubscribes, pub.SID())
+		t.lock.Unlock()
+	}
+}
+
+// onResubscribed is called when a track is subscribed, and completes audio resubscriptions
+func (t *LoadTester) onResubscribed(pub *lksdk.RemoteTrackPublication) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	pending := t.pendingResubscribes[pub.SID()]
+	if pending == nil || !pending.subscribedAt.IsZero() {
+		return
+	}
+	pending.subscribedAt = time.Now()
+	if pub.Kind() != lksdk.TrackKindVideo {
+		t.resubscribes = append(t.resubscribes, &resubscribeSample{
+			subscribe: pending.subscribedAt.Sub(pending.requestedAt),
+		})
+		delete(t.pendingResubscribes, pub.SID())
+	}
+}
+
+// onResubscribeKeyframe completes a video resubscription once a keyframe is received
+func (t *LoadTester) onResubscribeKeyframe(sid string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	pending := t.pendingResubscribes[sid]
+	if pending == nil || pending.subscribedAt.IsZero() {
+		return
+	}
+	t.resubscribes = append(t.resubscribes, &resubscribeSample{
+		subscribe: pending.subscribedAt.Sub(pending.requestedAt),
+		keyframe:  time.Since(pending.subscribedAt),
+	})
+	delete(t.pendingResubscribes, sid)
+}
+
+func printResubscribeStats(stats map[string]*testerStats, names []string) {
+	table := util.CreateTable().
+		Headers("Tester", "Resubscribes", "Avg. Subscribe", "Max. Subscribe", "Avg. Keyframe Wait", "Max. Keyframe Wait")
+	rows := 0
+	for _, name := range names {
+		samples := stats[name].resubscribes
+		if len(samples) == 0 {
+			continue
+		}
+		var subTotal, subMax, kfTotal, kfMax time.Duration
+		kfCount := 0
+		for _, s := range samples {
+			subTotal += s.subscribe
+			subMax = max(subMax, s.subscribe)
+			if s.keyframe > 0 {
+				kfTotal += s.keyframe
+				kfMax = max(kfMax, s.keyframe)
+				kfCount++
+			}
+		}
+		avgKeyframe, maxKeyframe := " - ", " - "
+		if kfCount > 0 {
+			avgKeyframe = (kfTotal / time.Duration(kfCount)).Round(time.Millisecond).String()
+			maxKeyframe = kfMax.Round(time.Millisecond).String()
+		}
+		table.Row(
+			name,
+			fmt.Sprint(len(samples)),
+			(subTotal / time.Duration(len(samples))).Round(time.Millisecond).String(),
+			subMax.Round(time.Millisecond).String(),
+			avgKeyframe,
+			maxKeyframe,
+		)
+		rows++
+	}
+	if rows > 0 {
+		fmt.Println("\nResubscriptions:")
+		fmt.Println(table)
+	}
+}
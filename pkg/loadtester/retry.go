@@ -0,0 +1,173 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/twitchtv/twirp"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+	"github.com/livekit/server-sdk-go/v2/signalling"
+)
+
+const (
+	// DefaultRetries is how often a tester that failed with a transient error is started again
+	DefaultRetries = 2
+
+	// attempts the tester makes to join before giving up
+	joinAttempts = 10
+	// wait before the first retry of a tester, doubling up to retryBackoffCap
+	retryBackoff    = time.Second
+	retryBackoffCap = 10 * time.Second
+)
+
+// isTransient tells errors that may go away when trying again, like timeouts, an unreachable or
+// overloaded server, from permanent ones like an invalid token or an unsupported codec. Only
+// typed errors and twirp codes count, a join the SDK reports as plain text is permanent.
+func isTransient(err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, lksdk.ErrConnectionTimeout),
+		errors.Is(err, lksdk.ErrCannotConnectSignal),
+		errors.Is(err, signalling.ErrCannotDialSignal),
+		errors.Is(err, lksdk.ErrNoPeerConnection),
+		errors.Is(err, lksdk.ErrTrackPublishTimeout),
+		errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.ECONNRESET):
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	var twirpErr twirp.Error
+	if errors.As(err, &twirpErr) {
+		switch twirpErr.Code() {
+		case twirp.Unavailable, twirp.ResourceExhausted, twirp.DeadlineExceeded:
+			return true
+		}
+	}
+	return false
+}
+
+// retryDelay is the backoff before a tester's nth retry
+func retryDelay(n int) time.Duration {
+	return min(retryBackoff<<n, retryBackoffCap)
+}
+
+// RetryResults compare how many testers failed at first with how many still failed after
+// retrying the transient errors, so that a blip doesn't fail an otherwise healthy run
+type RetryResults struct {
+	Testers int `json:"testers"`
+	// testers with any failed attempt to join or publish, and those that failed for good
+	RawFailures int     `json:"rawFailures"`
+	Failures    int     `json:"failures"`
+	RawRate     float64 `json:"rawFailureRate"`
+	Rate        float64 `json:"failureRate"`
+	// failed attempts that were transient, and testers that failed with a permanent error
+	TransientErrors int64 `json:"transientErrors"`
+	PermanentErrors int   `json:"permanentErrors"`
+	// attempts repeated after a transient error
+	Retries int64 `json:"retries"`
+}
+
+// getRetryResults is nil when every tester succeeded at the first attempt
+func getRetryResults(stats map[string]*testerStats) *RetryResults {
+	r := &RetryResults{Testers: len(stats)}
+	for _, s := range stats {
+		r.TransientErrors += s.transientErrors
+		r.Retries += s.retries
+		if s.err != nil {
+			r.Failures++
+			if !isTransient(s.err) {
+				r.PermanentErrors++
+			}
+		}
+		if s.err != nil || s.transientErrors > 0 {
+			r.RawFailures++
+		}
+	}
+	if r.RawFailures == 0 {
+		return nil
+	}
+	r.finish()
+	return r
+}
+
+func (r *RetryResults) finish() {
+	if r.Testers > 0 {
+		r.RawRate = float64(r.RawFailures) / float64(r.Testers)
+		r.Rate = float64(r.Failures) / float64(r.Testers)
+	}
+}
+
+func mergeRetryResults(combined, r *RetryResults) *RetryResults {
+	if r == nil {
+		return combined
+	}
+	if combined == nil {
+		combined = &RetryResults{}
+	}
+	combined.Testers += r.Testers
+	combined.RawFailures += r.RawFailures
+	combined.Failures += r.Failures
+	combined.TransientErrors += r.TransientErrors
+	combined.PermanentErrors += r.PermanentErrors
+	combined.Retries += r.Retries
+	combined.finish()
+	return combined
+}
+
+func printRetryResults(results *Results) {
+	r := results.Retries
+	if r == nil {
+		return
+	}
+	table := util.CreateTable().
+		Headers("", "Testers", "Failure Rate")
+	table.Row("Before retries", fmt.Sprintf("%d/%d", r.RawFailures, r.Testers), fmt.Sprintf("%.2f%%", r.RawRate*100))
+	table.Row("After retries", fmt.Sprintf("%d/%d", r.Failures, r.Testers), fmt.Sprintf("%.2f%%", r.Rate*100))
+	fmt.Println("\nRetries:")
+	fmt.Println(table)
+	fmt.Printf("%d transient errors, %d retries, %d testers failed with permanent errors\n",
+		r.TransientErrors, r.Retries, r.PermanentErrors)
+}
+
+// startTesterWithRetries starts the tester again after transient errors, up to params.Retries times
+func (t *LoadTest) startTesterWithRetries(ctx context.Context, params Params, tester *LoadTester, isVideoPublisher, isAudioPublisher, isDataPublisher, isScreenSharePublisher bool) error {
+	for n := 0; ; n++ {
+		err := t.startTester(ctx, params, tester, isVideoPublisher, isAudioPublisher, isDataPublisher, isScreenSharePublisher)
+		if err == nil || !isTransient(err) || n >= params.Retries {
+			return err
+		}
+		tester.Stop()
+		if !sleepUntil(ctx, time.Now().Add(retryDelay(n))) {
+			return err
+		}
+		fmt.Printf("retrying %s after %v\n", tester.params.name, err)
+		tester.retries.Inc()
+	}
+}
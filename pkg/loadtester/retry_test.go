@@ -0,0 +1,78 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/twitchtv/twirp"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+	"github.com/livekit/server-sdk-go/v2/signalling"
+)
+
+func TestIsTransient(t *testing.T) {
+	require.True(t, isTransient(lksdk.ErrConnectionTimeout))
+	require.True(t, isTransient(fmt.Errorf("could not connect: %w", signalling.ErrCannotDialSignal)))
+	require.True(t, isTransient(lksdk.ErrTrackPublishTimeout))
+	require.True(t, isTransient(fmt.Errorf("create room: %w", twirp.NewError(twirp.Unavailable, "server at capacity"))))
+	require.True(t, isTransient(twirp.NewError(twirp.ResourceExhausted, "too many requests")))
+
+	require.False(t, isTransient(nil))
+	require.False(t, isTransient(twirp.NewError(twirp.Unauthenticated, "invalid token")))
+	require.False(t, isTransient(errors.New("unavailable: server at capacity")))
+	require.False(t, isTransient(errors.New("not found: room does not exist")))
+	require.False(t, isTransient(errors.New("E2EE is not supported with h264")))
+}
+
+func TestRetryDelay(t *testing.T) {
+	require.Equal(t, time.Second, retryDelay(0))
+	require.Equal(t, 4*time.Second, retryDelay(2))
+	require.Equal(t, retryBackoffCap, retryDelay(10))
+}
+
+func TestRetryResults(t *testing.T) {
+	require.Nil(t, getRetryResults(map[string]*testerStats{"a": {}, "b": {}}))
+
+	stats := map[string]*testerStats{
+		"recovered": {transientErrors: 2, retries: 2},
+		"timed out": {transientErrors: 3, retries: 2, err: lksdk.ErrConnectionTimeout},
+		"refused":   {err: errors.New("unauthorized: invalid token")},
+		"fine":      {},
+	}
+	r := getRetryResults(stats)
+	require.Equal(t, &RetryResults{
+		Testers:         4,
+		RawFailures:     3,
+		Failures:        2,
+		RawRate:         0.75,
+		Rate:            0.5,
+		TransientErrors: 5,
+		PermanentErrors: 1,
+		Retries:         4,
+	}, r)
+
+	results := getResults(stats, []string{"fine"})
+	require.Equal(t, r, results.Retries)
+
+	merged := mergeResults([]*Results{results, getResults(map[string]*testerStats{"x": {}}, nil), {Total: &TesterResults{}, Retries: r}})
+	require.Equal(t, 8, merged.Retries.Testers)
+	require.Equal(t, 4, merged.Retries.Failures)
+	require.Equal(t, 0.5, merged.Retries.Rate)
+}
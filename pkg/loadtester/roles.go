@@ -0,0 +1,104 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+// Role is what a tester does in the room. It is part of the tester's identity,
+// <prefix>_<role>-<index>, and set as RoleAttribute on the participant.
+type Role string
+
+const (
+	RoleVideoPublisher Role = "pub-video"
+	RoleAudioPublisher Role = "pub-audio"
+	// publishes both audio and video
+	RoleAVPublisher   Role = "pub-av"
+	RoleDataPublisher Role = "pub-data"
+	RoleSubscriber    Role = "sub"
+
+	RoleAttribute = "lk.loadtest.role"
+)
+
+var roles = []Role{RoleVideoPublisher, RoleAudioPublisher, RoleAVPublisher, RoleDataPublisher, RoleSubscriber}
+
+// ParseRole parses a role name
+func ParseRole(s string) (Role, error) {
+	role := Role(strings.TrimSpace(s))
+	if !slices.Contains(roles, role) {
+		names := make([]string, 0, len(roles))
+		for _, r := range roles {
+			names = append(names, string(r))
+		}
+		return "", fmt.Errorf("unsupported role %q, expected one of %s", s, strings.Join(names, ", "))
+	}
+	return role, nil
+}
+
+func (r Role) IsPublisher() bool {
+	return strings.HasPrefix(string(r), "pub-")
+}
+
+// roleFor returns the role of a tester publishing the given kinds, a subscriber if none.
+// Data is only named when the tester publishes no media.
+func roleFor(video, audio, data bool) Role {
+	switch {
+	case video && audio:
+		return RoleAVPublisher
+	case video:
+		return RoleVideoPublisher
+	case audio:
+		return RoleAudioPublisher
+	case data:
+		return RoleDataPublisher
+	}
+	return RoleSubscriber
+}
+
+// ParticipantRole returns the role of a tester participant, or an empty role for any other participant
+func ParticipantRole(p *livekit.ParticipantInfo) Role {
+	if role := Role(p.Attributes[RoleAttribute]); role != "" {
+		return role
+	}
+	// testers from before the attribute was set carry the role in their identity only
+	for _, role := range roles {
+		if strings.Contains(p.Identity, "_"+string(role)+"-") {
+			return role
+		}
+	}
+	return ""
+}
+
+// reportNames returns the sorted names of the testers in roles that are reported on,
+// the subscribers when roles is empty
+func reportNames(stats map[string]*testerStats, roles []Role) []string {
+	if len(roles) == 0 {
+		roles = []Role{RoleSubscriber}
+	}
+	names := make([]string, 0, len(stats))
+	for name, s := range stats {
+		if slices.Contains(roles, s.role) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,57 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+func TestRoleIdentity(t *testing.T) {
+	tester := NewLoadTester(TesterParams{IdentityPrefix: "abc", Sequence: 3, Role: roleFor(true, false, true)})
+	require.Equal(t, "abc_pub-video-3", tester.identity())
+
+	tester = NewLoadTester(TesterParams{IdentityPrefix: "abc", Sequence: 7, Role: roleFor(false, false, false)})
+	require.Equal(t, "abc_sub-7", tester.identity())
+
+	tester = NewLoadTester(TesterParams{IdentityPrefix: "abc_screen_share_pub", Sequence: 2, Role: RoleAVPublisher, customIdentity: true})
+	require.Equal(t, "abc_screen_share_pub_2", tester.identity())
+}
+
+func TestParticipantRole(t *testing.T) {
+	require.Equal(t, RoleAudioPublisher, ParticipantRole(&livekit.ParticipantInfo{
+		Identity:   "abc_0",
+		Attributes: map[string]string{RoleAttribute: string(RoleAudioPublisher)},
+	}))
+	require.Equal(t, RoleAVPublisher, ParticipantRole(&livekit.ParticipantInfo{Identity: "abc_pub-av-0"}))
+	require.Equal(t, Role(""), ParticipantRole(&livekit.ParticipantInfo{Identity: "alice"}))
+
+	_, err := ParseRole("publisher")
+	require.Error(t, err)
+}
+
+func TestReportNames(t *testing.T) {
+	stats := map[string]*testerStats{
+		"Pub 0": {role: RoleVideoPublisher},
+		"Pub 1": {role: RoleDataPublisher},
+		"Sub 1": {role: RoleSubscriber},
+		"Sub 0": {role: RoleSubscriber},
+	}
+	require.Equal(t, []string{"Sub 0", "Sub 1"}, reportNames(stats, nil))
+	require.Equal(t, []string{"Pub 1", "Sub 0", "Sub 1"}, reportNames(stats, []Role{RoleSubscriber, RoleDataPublisher}))
+}
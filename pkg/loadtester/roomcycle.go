@@ -0,0 +1,259 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+const (
+	// DefaultRoomCloseTimeout is how long an emptied room may stay open before its cycle fails
+	DefaultRoomCloseTimeout = 30 * time.Second
+	// seconds the server keeps a cycled room open after the last tester left, short so that
+	// cycles don't wait long for it to close
+	roomCycleDepartureTimeout = 1
+	// seconds the server keeps a cycled room open before anyone joined, and after the last
+	// tester left on servers without departure timeouts
+	roomCycleEmptyTimeout = 10
+	roomClosePollInterval = 250 * time.Millisecond
+	// time each cycle keeps its rooms full when no duration is set
+	defaultRoomCycleDuration = 15 * time.Second
+)
+
+// RoomCycle fills and empties the same rooms over and over, checking that the server closes each
+// emptied room and that a room recreated under the same name starts out clean
+type RoomCycle struct {
+	// cycles to run, until interrupted when 0
+	Count int
+	// time from the start of one cycle to the start of the next, back to back when 0
+	Interval time.Duration
+	// how long to wait for the server to close an emptied room, DefaultRoomCloseTimeout when 0
+	CloseTimeout time.Duration
+}
+
+// RoomCycleResults are the checks of a room in one cycle
+type RoomCycleResults struct {
+	Cycle          int    `json:"cycle"`
+	Room           string `json:"room"`
+	RoomSID        string `json:"roomSid"`
+	Tracks         int    `json:"tracks"`
+	ExpectedTracks int    `json:"expectedTracks"`
+	// testers that failed to connect or publish
+	Errors int `json:"errors"`
+	// time from the testers leaving until the server closed the room, 0 when it didn't
+	CloseMs float64 `json:"closeMs"`
+	// what went wrong, empty when the cycle passed
+	Problems []string `json:"problems,omitempty"`
+}
+
+// RunRoomCycles runs the load test cycle.Count times on the same rooms, recreating them in between
+func (t *LoadTest) RunRoomCycles(ctx context.Context, cycle RoomCycle) error {
+	if cycle.Count < 0 || cycle.Interval < 0 || cycle.CloseTimeout < 0 {
+		return fmt.Errorf("room cycle count, interval and close timeout cannot be negative")
+	}
+	if err := validateParams(t.Params); err != nil {
+		return err
+	}
+	if err := checkUsagePolicy(t.Params); err != nil {
+		return err
+	}
+	if err := checkMediaFiles(t.Params); err != nil {
+		return err
+	}
+	stopStatus, err := t.serveStatus(t.Params.MetricsAddr)
+	if err != nil {
+		return err
+	}
+	defer stopStatus()
+
+	params := t.Params
+	// every cycle joins the same rooms
+	if params.Room == "" {
+		params.Room = fmt.Sprintf("testroom%d", rand.Int31n(1000))
+	}
+	if params.IdentityPrefix == "" {
+		params.IdentityPrefix = randStringRunes(5)
+	}
+	if params.Duration == 0 {
+		params.Duration = defaultRoomCycleDuration
+	}
+	if cycle.CloseTimeout == 0 {
+		cycle.CloseTimeout = DefaultRoomCloseTimeout
+	}
+	roomClient := lksdk.NewRoomServiceClient(params.URL, params.APIKey, params.APISecret)
+
+	var results []*RoomCycleResults
+	previous := make(map[string]string)
+	for i := 1; cycle.Count == 0 || i <= cycle.Count; i++ {
+		startedAt := time.Now()
+		fmt.Printf("\nRoom cycle %d: creating %d rooms\n", i, params.RoomCount)
+		cycleResults := make([]*RoomCycleResults, params.RoomCount)
+		for j := range cycleResults {
+			cycleResults[j] = createCycledRoom(ctx, roomClient, params.roomName(j), previous)
+			cycleResults[j].Cycle = i
+		}
+
+		stats, err := t.run(ctx, params)
+		if err != nil {
+			return err
+		}
+		for _, res := range cycleResults {
+			addCycleStats(res, stats)
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		for _, res := range cycleResults {
+			awaitRoomClose(ctx, roomClient, res, cycle.CloseTimeout)
+			previous[res.Room] = res.RoomSID
+			printRoomCycle(res)
+		}
+		results = append(results, cycleResults...)
+		if !sleepUntil(ctx, startedAt.Add(cycle.Interval)) {
+			break
+		}
+	}
+	if ctx.Err() != nil {
+		fmt.Println("\nRoom cycles interrupted, reporting completed cycles")
+	}
+	return printRoomCycleResults(results)
+}
+
+// createCycledRoom creates a room that the server closes soon after the testers leave, checking that
+// it's a new room without anyone left over from the previous cycle
+func createCycledRoom(ctx context.Context, roomClient *lksdk.RoomServiceClient, name string, previous map[string]string) *RoomCycleResults {
+	res := &RoomCycleResults{Room: name}
+	room, err := roomClient.CreateRoom(ctx, &livekit.CreateRoomRequest{
+		Name:             name,
+		EmptyTimeout:     roomCycleEmptyTimeout,
+		DepartureTimeout: roomCycleDepartureTimeout,
+	})
+	if err != nil {
+		res.Problems = append(res.Problems, fmt.Sprintf("could not create room: %v", err))
+		return res
+	}
+	res.RoomSID = room.Sid
+	if sid := previous[name]; sid != "" && sid == room.Sid {
+		res.Problems = append(res.Problems, "recreated room kept the SID of the closed one")
+	}
+	participants, err := roomClient.ListParticipants(ctx, &livekit.ListParticipantsRequest{Room: name})
+	if err != nil {
+		res.Problems = append(res.Problems, fmt.Sprintf("could not list participants: %v", err))
+	} else if len(participants.Participants) > 0 {
+		res.Problems = append(res.Problems, fmt.Sprintf("%d participants left over from the previous cycle", len(participants.Participants)))
+	}
+	return res
+}
+
+func addCycleStats(res *RoomCycleResults, stats map[string]*testerStats) {
+	for _, s := range stats {
+		if s.room != res.Room {
+			continue
+		}
+		summary := getTesterSummary(s)
+		res.Tracks += summary.tracks
+		res.ExpectedTracks += summary.expected
+		if s.err != nil {
+			res.Errors++
+		}
+	}
+	if res.Errors > 0 {
+		res.Problems = append(res.Problems, fmt.Sprintf("%d testers failed", res.Errors))
+	}
+	if res.Tracks < res.ExpectedTracks {
+		res.Problems = append(res.Problems, fmt.Sprintf("subscribers received %d of %d tracks", res.Tracks, res.ExpectedTracks))
+	}
+}
+
+// awaitRoomClose waits for the server to close the emptied room
+func awaitRoomClose(ctx context.Context, roomClient *lksdk.RoomServiceClient, res *RoomCycleResults, timeout time.Duration) {
+	leftAt := time.Now()
+	ticker := time.NewTicker(roomClosePollInterval)
+	defer ticker.Stop()
+	for {
+		rooms, err := roomClient.ListRooms(ctx, &livekit.ListRoomsRequest{Names: []string{res.Room}})
+		if err == nil && len(rooms.Rooms) == 0 {
+			res.CloseMs = durationMs(time.Since(leftAt))
+			return
+		}
+		if time.Since(leftAt) > timeout {
+			res.Problems = append(res.Problems, fmt.Sprintf("not closed %s after the testers left", timeout))
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func printRoomCycle(res *RoomCycleResults) {
+	if len(res.Problems) > 0 {
+		fmt.Printf("Room %s FAILED: %s\n", res.Room, strings.Join(res.Problems, ", "))
+		return
+	}
+	fmt.Printf("Room %s passed, closed %s after the testers left\n", res.Room, formatCloseTime(res))
+}
+
+func formatCloseTime(res *RoomCycleResults) string {
+	if res.CloseMs == 0 {
+		return " - "
+	}
+	return time.Duration(res.CloseMs * float64(time.Millisecond)).Round(time.Millisecond).String()
+}
+
+// printRoomCycleResults prints every cycle, returning an error when any of them failed
+func printRoomCycleResults(results []*RoomCycleResults) error {
+	if len(results) == 0 {
+		return nil
+	}
+	table := util.CreateTable().
+		Headers("Cycle", "Room", "Room SID", "Tracks", "Errors", "Closed After", "Result")
+	failed := 0
+	for _, res := range results {
+		verdict := "passed"
+		if len(res.Problems) > 0 {
+			verdict = "FAILED"
+			failed++
+		}
+		table.Row(
+			strconv.Itoa(res.Cycle),
+			res.Room,
+			res.RoomSID,
+			fmt.Sprintf("%d/%d", res.Tracks, res.ExpectedTracks),
+			strconv.Itoa(res.Errors),
+			formatCloseTime(res),
+			verdict,
+		)
+	}
+	fmt.Println("\nRoom cycles:")
+	fmt.Println(table)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d room cycles failed", failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/livekit"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+// fakeRoomService answers the RoomService calls of room cycles, closing rooms after closeAfter polls
+type fakeRoomService struct {
+	mu         sync.Mutex
+	sid        string
+	leftOver   int
+	closeAfter int
+	polls      int
+}
+
+func (f *fakeRoomService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	var res proto.Message
+	switch path.Base(r.URL.Path) {
+	case "CreateRoom":
+		req := &livekit.CreateRoomRequest{}
+		_ = proto.Unmarshal(body, req)
+		res = &livekit.Room{Name: req.Name, Sid: f.sid}
+	case "ListParticipants":
+		list := &livekit.ListParticipantsResponse{}
+		for i := 0; i < f.leftOver; i++ {
+			list.Participants = append(list.Participants, &livekit.ParticipantInfo{})
+		}
+		res = list
+	case "ListRooms":
+		list := &livekit.ListRoomsResponse{}
+		if f.polls++; f.polls <= f.closeAfter {
+			list.Rooms = []*livekit.Room{{Sid: f.sid}}
+		}
+		res = list
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	data, _ := proto.Marshal(res)
+	w.Header().Set("Content-Type", "application/protobuf")
+	_, _ = w.Write(data)
+}
+
+func TestCreateCycledRoom(t *testing.T) {
+	service := &fakeRoomService{sid: "RM_1"}
+	server := httptest.NewServer(service)
+	defer server.Close()
+	client := lksdk.NewRoomServiceClient(server.URL, "key", "secretsecretsecretsecretsecretsecret")
+	ctx := context.Background()
+
+	res := createCycledRoom(ctx, client, "room_0", map[string]string{})
+	require.Equal(t, "RM_1", res.RoomSID)
+	require.Empty(t, res.Problems)
+
+	// recreated under the old SID, with a participant of the previous cycle still in it
+	service.leftOver = 1
+	res = createCycledRoom(ctx, client, "room_0", map[string]string{"room_0": "RM_1"})
+	require.Equal(t, []string{
+		"recreated room kept the SID of the closed one",
+		"1 participants left over from the previous cycle",
+	}, res.Problems)
+}
+
+func TestAwaitRoomClose(t *testing.T) {
+	service := &fakeRoomService{sid: "RM_1", closeAfter: 2}
+	server := httptest.NewServer(service)
+	defer server.Close()
+	client := lksdk.NewRoomServiceClient(server.URL, "key", "secretsecretsecretsecretsecretsecret")
+
+	res := &RoomCycleResults{Room: "room_0"}
+	awaitRoomClose(context.Background(), client, res, 5*time.Second)
+	require.Empty(t, res.Problems)
+	require.Greater(t, res.CloseMs, 0.0)
+
+	service.polls, service.closeAfter = 0, 1000
+	res = &RoomCycleResults{Room: "room_0"}
+	awaitRoomClose(context.Background(), client, res, 300*time.Millisecond)
+	require.Equal(t, []string{"not closed 300ms after the testers left"}, res.Problems)
+	require.Zero(t, res.CloseMs)
+}
+
+func TestRoomCycleResults(t *testing.T) {
+	stats := map[string]*testerStats{
+		"sub_0": {room: "room_0", expectedTracks: 2, trackStats: map[string]*trackStats{"TR_1": {}, "TR_2": {}}},
+		"sub_1": {room: "room_1", expectedTracks: 2, trackStats: map[string]*trackStats{"TR_1": {}}},
+		"pub_1": {room: "room_1", err: io.EOF},
+	}
+	passed := &RoomCycleResults{Cycle: 1, Room: "room_0"}
+	addCycleStats(passed, stats)
+	require.Equal(t, 2, passed.Tracks)
+	require.Empty(t, passed.Problems)
+
+	failed := &RoomCycleResults{Cycle: 1, Room: "room_1"}
+	addCycleStats(failed, stats)
+	require.Equal(t, []string{"1 testers failed", "subscribers received 1 of 2 tracks"}, failed.Problems)
+
+	require.NoError(t, printRoomCycleResults([]*RoomCycleResults{passed}))
+	require.EqualError(t, printRoomCycleResults([]*RoomCycleResults{passed, failed}), "1 of 2 room cycles failed")
+}
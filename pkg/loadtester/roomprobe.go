@@ -0,0 +1,316 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+const (
+	// seconds the server keeps a probed room open, in case deleting it fails
+	roomProbeEmptyTimeout = 10
+	// baseline latency below which a step isn't compared against it, as a multiple of a few
+	// milliseconds is noise rather than a spike
+	roomProbeLatencyFloor = 100 * time.Millisecond
+)
+
+type RoomProbeParams struct {
+	// rooms created per second in the first step
+	StartRate float64
+	// every step creates rooms this many times faster than the previous one
+	RateStep float64
+	// stop after the step reaching this rate even if the deployment keeps up
+	MaxRate float64
+	// time each step keeps creating rooms at its rate
+	StepDuration time.Duration
+	// fraction of rooms failing to create or activate that ends the probe
+	MaxErrorRate float64
+	// p95 latency that ends the probe, as a multiple of the first step's
+	LatencySpike float64
+	// p95 latency that ends the probe, regardless of the first step's
+	MaxLatency time.Duration
+	// join a participant to every room to activate it, rather than only creating it
+	Activate bool
+	// rooms are named with this prefix and a sequence number
+	RoomPrefix string
+	TesterParams
+}
+
+// RoomProbe creates rooms at increasing rates until creating or activating them fails or slows
+// down, to find the room creation rate a deployment sustains
+type RoomProbe struct {
+	params RoomProbeParams
+}
+
+type roomProbeStep struct {
+	rate    float64
+	rooms   int
+	errors  int
+	create  latencyPercentiles
+	join    latencyPercentiles
+	total   latencyPercentiles
+	elapsed time.Duration
+}
+
+// a probed room, timed from the create request until the participant joined
+type roomProbeSample struct {
+	create time.Duration
+	join   time.Duration
+	err    error
+}
+
+func NewRoomProbe(params RoomProbeParams) *RoomProbe {
+	if params.StartRate <= 0 {
+		params.StartRate = 1
+	}
+	if params.RateStep <= 1 {
+		params.RateStep = 1.5
+	}
+	if params.MaxRate <= 0 {
+		params.MaxRate = 100
+	}
+	if params.StepDuration <= 0 {
+		params.StepDuration = 10 * time.Second
+	}
+	if params.MaxErrorRate <= 0 {
+		params.MaxErrorRate = 0.01
+	}
+	if params.LatencySpike <= 0 {
+		params.LatencySpike = 3
+	}
+	if params.RoomPrefix == "" {
+		params.RoomPrefix = fmt.Sprintf("roomprobe%d", rand.Int31n(1000))
+	}
+	if params.IdentityPrefix == "" {
+		params.IdentityPrefix = randStringRunes(5)
+	}
+	return &RoomProbe{
+		params: params,
+	}
+}
+
+func (p *RoomProbe) Run(ctx context.Context) error {
+	// every room of the fastest step is up at once, each with a participant when activated
+	policy := Params{TesterParams: p.params.TesterParams, Subscribers: p.params.peakRooms()}
+	if err := validateParams(policy); err != nil {
+		return err
+	}
+	if err := checkUsagePolicy(policy); err != nil {
+		return err
+	}
+
+	activation := "creating"
+	if p.params.Activate {
+		activation = "creating and joining"
+	}
+	fmt.Printf("Probing room creation rate, %s rooms %s from %.4g rooms/s for %s per step\n",
+		activation, p.params.RoomPrefix, p.params.StartRate, p.params.StepDuration)
+
+	roomClient := lksdk.NewRoomServiceClient(p.params.URL, p.params.APIKey, p.params.APISecret)
+	var (
+		steps     []*roomProbeStep
+		reason    string
+		baseline  time.Duration
+		sustained float64
+		next      int
+	)
+	for rate := p.params.StartRate; reason == ""; rate *= p.params.RateStep {
+		rate = min(rate, p.params.MaxRate)
+		fmt.Printf("Creating %.4g rooms/s\n", rate)
+		step := p.runStep(ctx, roomClient, rate, &next)
+		steps = append(steps, step)
+		if ctx.Err() != nil {
+			reason = "canceled"
+			break
+		}
+		if len(steps) == 1 {
+			baseline = step.total.p95
+		}
+		if reason = p.params.check(step, baseline); reason != "" {
+			break
+		}
+		sustained = rate
+		if rate >= p.params.MaxRate {
+			reason = fmt.Sprintf("reached the maximum of %.4g rooms/s", p.params.MaxRate)
+		}
+	}
+
+	table := util.CreateTable().
+		Headers("Rate", "Rooms", "Errors", "Create p50/p95", "Join p50/p95", "Total p95", "Throughput")
+	for _, step := range steps {
+		join := "-"
+		if p.params.Activate {
+			join = formatLatencyRange(step.join.p50, step.join.p95)
+		}
+		table.Row(
+			fmt.Sprintf("%.4g/s", step.rate),
+			strconv.Itoa(step.rooms),
+			fmt.Sprintf("%d (%s%%)", step.errors, formatPercentage(int64(step.errors), int64(step.rooms))),
+			formatLatencyRange(step.create.p50, step.create.p95),
+			join,
+			step.total.p95.Round(time.Millisecond).String(),
+			fmt.Sprintf("%.4g/s", float64(step.rooms-step.errors)/max(step.elapsed.Seconds(), 0.001)),
+		)
+	}
+	fmt.Println("\nRoom probe:")
+	fmt.Println(table)
+	fmt.Printf("Sustainable room creation rate: %.4g rooms/s (stopped: %s)\n", sustained, reason)
+	if sustained == 0 && ctx.Err() == nil {
+		return fmt.Errorf("no room creation rate was sustained: %s", reason)
+	}
+	return nil
+}
+
+// runStep creates rooms at rate for a step, and removes them again once they are all up
+func (p *RoomProbe) runStep(ctx context.Context, roomClient *lksdk.RoomServiceClient, rate float64, next *int) *roomProbeStep {
+	count := max(int(rate*p.params.StepDuration.Seconds()), 1)
+	samples := make([]*roomProbeSample, 0, count)
+	rooms := make([]*lksdk.Room, count)
+	names := make([]string, 0, count)
+	var wg sync.WaitGroup
+	var lock sync.Mutex
+
+	startedAt := time.Now()
+	pace := time.Duration(float64(time.Second) / rate)
+	for i := 0; i < count; i++ {
+		if !sleepUntil(ctx, startedAt.Add(time.Duration(i)*pace)) {
+			break
+		}
+		name := fmt.Sprintf("%s_%d", p.params.RoomPrefix, *next)
+		identity := fmt.Sprintf("%s_%d", p.params.IdentityPrefix, *next)
+		*next++
+		names = append(names, name)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sample, room := p.probeRoom(ctx, roomClient, name, identity)
+			lock.Lock()
+			samples = append(samples, sample)
+			rooms[i] = room
+			lock.Unlock()
+		}(i)
+	}
+	wg.Wait()
+	step := summarizeRoomProbe(rate, samples, time.Since(startedAt))
+
+	// start every step with a clean deployment
+	cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+	for _, room := range rooms {
+		if room != nil {
+			room.Disconnect()
+		}
+	}
+	for _, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = roomClient.DeleteRoom(cleanupCtx, &livekit.DeleteRoomRequest{Room: name})
+		}()
+	}
+	wg.Wait()
+	return step
+}
+
+func (p *RoomProbe) probeRoom(ctx context.Context, roomClient *lksdk.RoomServiceClient, name, identity string) (*roomProbeSample, *lksdk.Room) {
+	sample := &roomProbeSample{}
+	startedAt := time.Now()
+	_, err := roomClient.CreateRoom(ctx, &livekit.CreateRoomRequest{
+		Name:         name,
+		EmptyTimeout: roomProbeEmptyTimeout,
+	})
+	sample.create = time.Since(startedAt)
+	if err != nil {
+		sample.err = fmt.Errorf("could not create room: %w", err)
+		return sample, nil
+	}
+	if !p.params.Activate {
+		return sample, nil
+	}
+
+	joinedAt := time.Now()
+	room, err := lksdk.ConnectToRoom(p.params.URL, lksdk.ConnectInfo{
+		APIKey:              p.params.APIKey,
+		APISecret:           p.params.APISecret,
+		RoomName:            name,
+		ParticipantIdentity: identity,
+	}, nil, lksdk.WithAutoSubscribe(false))
+	sample.join = time.Since(joinedAt)
+	if err != nil {
+		sample.err = fmt.Errorf("could not join room: %w", err)
+		return sample, nil
+	}
+	return sample, room
+}
+
+func summarizeRoomProbe(rate float64, samples []*roomProbeSample, elapsed time.Duration) *roomProbeStep {
+	step := &roomProbeStep{rate: rate, rooms: len(samples), elapsed: elapsed}
+	var create, join, total []time.Duration
+	for _, s := range samples {
+		if s.err != nil {
+			step.errors++
+			continue
+		}
+		create = append(create, s.create)
+		join = append(join, s.join)
+		total = append(total, s.create+s.join)
+	}
+	for _, latencies := range [][]time.Duration{create, join, total} {
+		slices.Sort(latencies)
+	}
+	step.create = latencyPercentiles{p50: percentile(create, 0.5), p95: percentile(create, 0.95), p99: percentile(create, 0.99)}
+	step.join = latencyPercentiles{p50: percentile(join, 0.5), p95: percentile(join, 0.95), p99: percentile(join, 0.99)}
+	step.total = latencyPercentiles{p50: percentile(total, 0.5), p95: percentile(total, 0.95), p99: percentile(total, 0.99)}
+	return step
+}
+
+// peakRooms is the number of rooms created by the step at the maximum rate
+func (p RoomProbeParams) peakRooms() int {
+	return max(int(p.MaxRate*p.StepDuration.Seconds()), 1)
+}
+
+// check returns why the deployment didn't sustain the step's rate, empty when it did
+func (p RoomProbeParams) check(step *roomProbeStep, baseline time.Duration) string {
+	if step.rooms == 0 {
+		return "no rooms created"
+	}
+	if rate := float64(step.errors) / float64(step.rooms); rate > p.MaxErrorRate {
+		return fmt.Sprintf("%s%% of rooms failed, over %s%%",
+			formatPercentage(int64(step.errors), int64(step.rooms)), strconv.FormatFloat(p.MaxErrorRate*100, 'f', -1, 64))
+	}
+	if p.MaxLatency > 0 && step.total.p95 > p.MaxLatency {
+		return fmt.Sprintf("p95 latency %s exceeded %s", step.total.p95.Round(time.Millisecond), p.MaxLatency)
+	}
+	if limit := time.Duration(float64(max(baseline, roomProbeLatencyFloor)) * p.LatencySpike); step.total.p95 > limit {
+		return fmt.Sprintf("p95 latency %s spiked over %.4gx the first step's %s",
+			step.total.p95.Round(time.Millisecond), p.LatencySpike, baseline.Round(time.Millisecond))
+	}
+	return ""
+}
+
+func formatLatencyRange(p50, p95 time.Duration) string {
+	return fmt.Sprintf("%s / %s", p50.Round(time.Millisecond), p95.Round(time.Millisecond))
+}
@@ -0,0 +1,84 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummarizeRoomProbe(t *testing.T) {
+	var samples []*roomProbeSample
+	for i := 1; i <= 20; i++ {
+		samples = append(samples, &roomProbeSample{
+			create: time.Duration(i) * time.Millisecond,
+			join:   time.Duration(i) * 10 * time.Millisecond,
+		})
+	}
+	samples = append(samples, &roomProbeSample{create: time.Second, err: errors.New("could not create room")})
+
+	step := summarizeRoomProbe(2, samples, 10*time.Second)
+	require.Equal(t, 21, step.rooms)
+	require.Equal(t, 1, step.errors)
+	require.Equal(t, 10*time.Millisecond, step.create.p50)
+	require.Equal(t, 19*time.Millisecond, step.create.p95)
+	require.Equal(t, 190*time.Millisecond, step.join.p95)
+	require.Equal(t, 209*time.Millisecond, step.total.p95)
+}
+
+func TestRoomProbeCheck(t *testing.T) {
+	params := NewRoomProbe(RoomProbeParams{}).params
+	require.Equal(t, 1.0, params.StartRate)
+	require.Equal(t, 1.5, params.RateStep)
+	require.Equal(t, 10*time.Second, params.StepDuration)
+
+	step := func(rooms, errors int, p95 time.Duration) *roomProbeStep {
+		return &roomProbeStep{rooms: rooms, errors: errors, total: latencyPercentiles{p95: p95}}
+	}
+	require.Empty(t, params.check(step(100, 1, 50*time.Millisecond), 50*time.Millisecond))
+	require.Contains(t, params.check(step(100, 2, 50*time.Millisecond), 50*time.Millisecond), "2% of rooms failed")
+	require.Contains(t, params.check(step(0, 0, 0), 0), "no rooms")
+
+	// latency compared to the first step, but not below the floor
+	require.Empty(t, params.check(step(10, 0, 250*time.Millisecond), 20*time.Millisecond))
+	require.Contains(t, params.check(step(10, 0, 350*time.Millisecond), 20*time.Millisecond), "spiked")
+	require.Empty(t, params.check(step(10, 0, 1400*time.Millisecond), 500*time.Millisecond))
+	require.Contains(t, params.check(step(10, 0, 1600*time.Millisecond), 500*time.Millisecond), "spiked")
+
+	params.MaxLatency = time.Second
+	require.Contains(t, params.check(step(10, 0, 1400*time.Millisecond), 500*time.Millisecond), "exceeded 1s")
+}
+
+func TestRoomProbeRun(t *testing.T) {
+	// the fastest step would put 1000 rooms on LiveKit Cloud at once
+	err := NewRoomProbe(RoomProbeParams{
+		MaxRate:      100,
+		TesterParams: TesterParams{URL: "wss://project.livekit.cloud"},
+	}).Run(context.Background())
+	require.ErrorContains(t, err, "acceptable use policy")
+
+	// nothing listens there, so not even the first step succeeds
+	err = NewRoomProbe(RoomProbeParams{
+		StartRate:    10,
+		MaxRate:      10,
+		StepDuration: 100 * time.Millisecond,
+		TesterParams: TesterParams{URL: "http://127.0.0.1:1", APIKey: "key", APISecret: "secret"},
+	}).Run(context.Background())
+	require.ErrorContains(t, err, "no room creation rate was sustained")
+}
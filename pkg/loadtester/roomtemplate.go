@@ -0,0 +1,114 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/livekit/protocol/livekit"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+// RoomConfig is the configuration a test room is created with, instead of the server's
+// defaults for rooms created by the first participant joining
+type RoomConfig struct {
+	MaxParticipants  uint32        `yaml:"max_participants,omitempty"`
+	EmptyTimeout     time.Duration `yaml:"empty_timeout,omitempty"`
+	DepartureTimeout time.Duration `yaml:"departure_timeout,omitempty"`
+	Metadata         string        `yaml:"metadata,omitempty"`
+	// codec video publishers in the room publish instead of --video-codec. The room service
+	// has no per-room codec setting, rooms take the codecs enabled on the server.
+	VideoCodec string `yaml:"video_codec,omitempty"`
+}
+
+// LoadRoomTemplate reads the configurations of test rooms from a YAML list. Rooms use them in turn,
+// the first room the first configuration, wrapping around when there are more rooms.
+func LoadRoomTemplate(path string) ([]*RoomConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var configs []*RoomConfig
+	if err = yaml.Unmarshal(data, &configs); err != nil {
+		return nil, fmt.Errorf("invalid room template %s: %w", path, err)
+	}
+	if len(configs) == 0 {
+		return nil, fmt.Errorf("invalid room template %s: no rooms", path)
+	}
+	for i, c := range configs {
+		if c == nil {
+			return nil, fmt.Errorf("invalid room template %s: room %d is empty", path, i)
+		}
+		if c.EmptyTimeout < 0 || (c.EmptyTimeout > 0 && c.EmptyTimeout < time.Second) {
+			return nil, fmt.Errorf("invalid room template %s: room %d: empty_timeout must be at least 1s", path, i)
+		}
+		if c.DepartureTimeout < 0 || (c.DepartureTimeout > 0 && c.DepartureTimeout < time.Second) {
+			return nil, fmt.Errorf("invalid room template %s: room %d: departure_timeout must be at least 1s", path, i)
+		}
+		switch c.VideoCodec {
+		case "", "h264", "vp8", "vp9", "av1":
+		default:
+			return nil, fmt.Errorf("invalid room template %s: room %d: video_codec must be \"h264\", \"vp8\", \"vp9\" or \"av1\", got %q",
+				path, i, c.VideoCodec)
+		}
+	}
+	return configs, nil
+}
+
+// request returns the request creating room name with c
+func (c *RoomConfig) request(name string) *livekit.CreateRoomRequest {
+	return &livekit.CreateRoomRequest{
+		Name:             name,
+		MaxParticipants:  c.MaxParticipants,
+		EmptyTimeout:     uint32(c.EmptyTimeout / time.Second),
+		DepartureTimeout: uint32(c.DepartureTimeout / time.Second),
+		Metadata:         c.Metadata,
+	}
+}
+
+// forRoom returns the params of the testers in the j-th room, with the video codec of its template.
+// Scenario phases set the codec of the testers joining in them instead.
+func (p *Params) forRoom(j int) Params {
+	params := *p
+	if len(p.RoomTemplate) == 0 {
+		return params
+	}
+	if codec := p.RoomTemplate[j%len(p.RoomTemplate)].VideoCodec; codec != "" {
+		params.VideoCodec = codec
+	}
+	return params
+}
+
+// createRooms creates the test rooms from the room template before any tester joins.
+// Rooms that already exist keep their configuration.
+func createRooms(ctx context.Context, params Params, rooms int) error {
+	if len(params.RoomTemplate) == 0 {
+		return nil
+	}
+	roomClient := lksdk.NewRoomServiceClient(params.URL, params.APIKey, params.APISecret)
+	for j := 0; j < rooms; j++ {
+		name := params.roomName(j)
+		if _, err := roomClient.CreateRoom(ctx, params.RoomTemplate[j%len(params.RoomTemplate)].request(name)); err != nil {
+			return fmt.Errorf("could not create room %s: %w", name, err)
+		}
+	}
+	fmt.Printf("Created %d rooms from the room template\n", rooms)
+	return nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+func TestLoadRoomTemplate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rooms.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+- max_participants: 10
+  empty_timeout: 2m
+  departure_timeout: 20s
+  metadata: '{"class":"small"}'
+  video_codec: vp9
+- max_participants: 200
+`), 0644))
+	configs, err := LoadRoomTemplate(path)
+	require.NoError(t, err)
+	require.Len(t, configs, 2)
+	require.Equal(t, 2*time.Minute, configs[0].EmptyTimeout)
+
+	req := configs[0].request("room_0")
+	require.Equal(t, "room_0", req.Name)
+	require.EqualValues(t, 10, req.MaxParticipants)
+	require.EqualValues(t, 120, req.EmptyTimeout)
+	require.EqualValues(t, 20, req.DepartureTimeout)
+	require.Equal(t, `{"class":"small"}`, req.Metadata)
+
+	params := Params{VideoCodec: "vp8", RoomTemplate: configs}
+	require.Equal(t, "vp9", params.forRoom(0).VideoCodec)
+	require.Equal(t, "vp8", params.forRoom(1).VideoCodec)
+	require.Equal(t, "vp9", params.forRoom(2).VideoCodec)
+	require.Equal(t, "vp8", params.VideoCodec)
+
+	for _, template := range []string{
+		"[]",
+		"- video_codec: theora",
+		"- empty_timeout: 10ms",
+		"- max_participants: lots",
+	} {
+		require.NoError(t, os.WriteFile(path, []byte(template), 0644))
+		_, err = LoadRoomTemplate(path)
+		require.Error(t, err, template)
+	}
+}
+
+func TestRoomConfigDefaults(t *testing.T) {
+	require.Equal(t, &livekit.CreateRoomRequest{Name: "room_1"}, (&RoomConfig{}).request("room_1"))
+}
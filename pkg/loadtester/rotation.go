@@ -0,0 +1,198 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/atomic"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+// credentialRotation switches the API key testers join with partway through a run, as a key
+// rotation runbook would, and checks that sessions joined with the old key carry on
+type credentialRotation struct {
+	newKey    string
+	newSecret string
+	after     time.Duration
+	rotated   atomic.Bool
+
+	lock    sync.Mutex
+	results *RotationResults
+}
+
+// RotationResults count the testers that joined with each key, and how many were still
+// connected when the test ended
+type RotationResults struct {
+	OldKey string `json:"oldKey"`
+	NewKey string `json:"newKey"`
+	// time from the start of the run until the rotation, 0 if the run ended before it
+	RotatedAfterMs  float64 `json:"rotatedAfterMs"`
+	OldKeyJoins     int     `json:"oldKeyJoins"`
+	OldKeyConnected int     `json:"oldKeyConnected"`
+	NewKeyJoins     int     `json:"newKeyJoins"`
+	NewKeyConnected int     `json:"newKeyConnected"`
+	// a participant joins every room with the new key right after the rotation
+	ProbeJoins    int      `json:"probeJoins"`
+	ProbeFailures int      `json:"probeFailures"`
+	Errors        []string `json:"errors,omitempty"`
+}
+
+// ValidateRotation checks the --rotate-* flags
+func ValidateRotation(params Params) error {
+	if params.RotateAPIKey == "" && params.RotateAPISecret == "" {
+		return nil
+	}
+	if params.RotateAPIKey == "" || params.RotateAPISecret == "" {
+		return fmt.Errorf("credential rotation needs both an API key and secret")
+	}
+	if params.RotateAPIKey == params.APIKey {
+		return fmt.Errorf("credential rotation needs a different API key than %s", params.APIKey)
+	}
+	if params.RotateAfter <= 0 {
+		return fmt.Errorf("credential rotation needs a time to rotate after")
+	}
+	if params.Duration > 0 && params.RotateAfter >= params.Duration {
+		return fmt.Errorf("credentials would be rotated after the test ends")
+	}
+	return nil
+}
+
+func newCredentialRotation(params Params) *credentialRotation {
+	if params.RotateAPIKey == "" {
+		return nil
+	}
+	return &credentialRotation{
+		newKey:    params.RotateAPIKey,
+		newSecret: params.RotateAPISecret,
+		after:     params.RotateAfter,
+		results:   &RotationResults{OldKey: params.APIKey, NewKey: params.RotateAPIKey},
+	}
+}
+
+// credentials returns the key and secret to sign a token with at the moment
+func (r *credentialRotation) credentials(apiKey, apiSecret string) (string, string) {
+	if r != nil && r.rotated.Load() {
+		return r.newKey, r.newSecret
+	}
+	return apiKey, apiSecret
+}
+
+// run rotates the credentials once the rotation time since startedAt has passed, unless ctx
+// ends first, then probes every room with the new key
+func (r *credentialRotation) run(ctx context.Context, params Params, startedAt time.Time) {
+	if !sleepUntil(ctx, startedAt.Add(r.after)) {
+		return
+	}
+	r.rotated.Store(true)
+	r.lock.Lock()
+	r.results.RotatedAfterMs = durationMs(time.Since(startedAt))
+	r.lock.Unlock()
+	fmt.Printf("Rotated credentials to API key %s, new testers join with it\n", r.newKey)
+
+	for j := 0; j < params.RoomCount && ctx.Err() == nil; j++ {
+		r.probe(params, j)
+	}
+}
+
+// probe joins the j-th room with the new key and leaves again
+func (r *credentialRotation) probe(params Params, j int) {
+	probeParams := params.TesterParams
+	probeParams.Room = params.roomName(j)
+	probeParams.IdentityPrefix += "_rotation-probe"
+	probeParams.customIdentity = true
+	probeParams.Sequence = j
+	probeParams.Role = RoleSubscriber
+	probeParams.name = fmt.Sprintf("Rotation probe %d", j)
+	probeParams.rotation = r
+	// only joins, without any of the subscriber workers
+	probeParams.ResubscribeInterval = 0
+	probeParams.AdaptiveCycle = 0
+	probeParams.ReconnectInterval = 0
+	probe := NewLoadTester(probeParams)
+	err := probe.Start()
+	probe.Stop()
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.results.ProbeJoins++
+	if err == nil && probe.joinedWithKey.Load() != r.newKey {
+		err = fmt.Errorf("joined with %s instead of the new key", probe.joinedWithKey.Load())
+	}
+	if err != nil {
+		r.results.ProbeFailures++
+		r.results.Errors = append(r.results.Errors, fmt.Sprintf("%s: %v", probeParams.Room, err))
+	}
+}
+
+// finish counts the testers that joined with each key before they are stopped
+func (r *credentialRotation) finish(testers []*LoadTester) *RotationResults {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	res := r.results
+	for _, t := range testers {
+		connected := t.IsRunning()
+		switch t.joinedWithKey.Load() {
+		case "":
+			// never got as far as joining
+		case r.newKey:
+			res.NewKeyJoins++
+			if connected {
+				res.NewKeyConnected++
+			}
+		default:
+			res.OldKeyJoins++
+			if connected {
+				res.OldKeyConnected++
+			}
+		}
+	}
+	return res
+}
+
+// passed is true when every session outlived the rotation and the new key could join every room
+func (r *RotationResults) passed() bool {
+	return r.RotatedAfterMs > 0 && r.ProbeFailures == 0 &&
+		r.OldKeyConnected == r.OldKeyJoins && r.NewKeyConnected == r.NewKeyJoins
+}
+
+func printRotationResults(r *RotationResults) {
+	if r == nil {
+		return
+	}
+	table := util.CreateTable().
+		Headers("API Key", "Joined", "Connected at End")
+	table.Row(r.OldKey+" (old)", fmt.Sprint(r.OldKeyJoins), fmt.Sprint(r.OldKeyConnected))
+	table.Row(r.NewKey+" (new)", fmt.Sprint(r.NewKeyJoins), fmt.Sprint(r.NewKeyConnected))
+	table.Row("Probes", fmt.Sprint(r.ProbeJoins), fmt.Sprint(r.ProbeJoins-r.ProbeFailures))
+
+	verdict := "passed"
+	if r.RotatedAfterMs == 0 {
+		verdict = "not rotated, the test ended first"
+	} else if !r.passed() {
+		verdict = "FAILED"
+	}
+	fmt.Printf("\nCredential rotation after %s (%s):\n",
+		time.Duration(r.RotatedAfterMs*float64(time.Millisecond)).Round(time.Second), verdict)
+	fmt.Println(table)
+	if len(r.Errors) > 0 {
+		fmt.Println(strings.Join(r.Errors, "\n"))
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRotation(t *testing.T) {
+	params := Params{Duration: time.Minute}
+	params.APIKey = "old"
+	require.NoError(t, ValidateRotation(params))
+
+	params.RotateAPIKey = "new"
+	require.Error(t, ValidateRotation(params))
+	params.RotateAPISecret = "secret"
+	require.Error(t, ValidateRotation(params))
+	params.RotateAfter = 2 * time.Minute
+	require.Error(t, ValidateRotation(params))
+	params.RotateAfter = 30 * time.Second
+	require.NoError(t, ValidateRotation(params))
+	params.RotateAPIKey = "old"
+	require.Error(t, ValidateRotation(params))
+}
+
+func TestCredentialRotation(t *testing.T) {
+	var none *credentialRotation
+	key, secret := none.credentials("old", "old secret")
+	require.Equal(t, "old", key)
+	require.Equal(t, "old secret", secret)
+
+	params := Params{RotateAPIKey: "new", RotateAPISecret: "new secret", RotateAfter: time.Second}
+	params.APIKey, params.APISecret = "old", "old secret"
+	r := newCredentialRotation(params)
+	before := NewLoadTester(TesterParams{APIKey: "old", APISecret: "old secret", rotation: r})
+	_, err := before.token()
+	require.NoError(t, err)
+
+	r.rotated.Store(true)
+	r.results.RotatedAfterMs = 1000
+	after := NewLoadTester(TesterParams{APIKey: "old", APISecret: "old secret", rotation: r})
+	_, err = after.token()
+	require.NoError(t, err)
+	require.Equal(t, "new", after.joinedWithKey.Load())
+
+	// the tester that joined before the rotation has dropped
+	res := r.finish([]*LoadTester{before, after, NewLoadTester(TesterParams{})})
+	require.Equal(t, 1, res.OldKeyJoins)
+	require.Equal(t, 0, res.OldKeyConnected)
+	require.Equal(t, 1, res.NewKeyJoins)
+	require.False(t, res.passed())
+
+	before.running.Store(true)
+	after.running.Store(true)
+	r.results = &RotationResults{RotatedAfterMs: 1000}
+	require.True(t, r.finish([]*LoadTester{before, after}).passed())
+}
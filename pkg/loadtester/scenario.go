@@ -0,0 +1,664 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+const (
+	// join testers until the room has the phase's tester counts
+	PhaseRamp = "ramp"
+	// keep the testers in the room
+	PhaseHold = "hold"
+	// replace a fraction of the subscribers every interval
+	PhaseChurn = "churn"
+	// have every publisher mute or unmute its tracks every interval
+	PhaseMuteStorm = "mute_storm"
+	// remove testers until the room has the phase's tester counts, none by default
+	PhaseTeardown = "teardown"
+
+	defaultChurnRate     = 0.1
+	defaultPhaseInterval = time.Second
+)
+
+// Scenario is a scripted load test of phases, run one after the other on the same testers
+type Scenario struct {
+	Name   string           `yaml:"name"`
+	Phases []*ScenarioPhase `yaml:"phases"`
+}
+
+// ScenarioPhase is one step of a Scenario. Tester counts are per room.
+type ScenarioPhase struct {
+	Name     string        `yaml:"name,omitempty"`
+	Kind     string        `yaml:"kind"`
+	Duration time.Duration `yaml:"duration,omitempty"`
+	// tester counts at the end of a ramp or teardown phase, unchanged when unset
+	VideoPublishers *int `yaml:"video_publishers,omitempty"`
+	AudioPublishers *int `yaml:"audio_publishers,omitempty"`
+	Subscribers     *int `yaml:"subscribers,omitempty"`
+	// testers joining or leaving per second, the test's rate when 0
+	NumPerSecond float64 `yaml:"num_per_second,omitempty"`
+	// codec of the video published by testers joining in this phase
+	VideoCodec string `yaml:"video_codec,omitempty"`
+	// time between churn or mute events
+	Interval time.Duration `yaml:"interval,omitempty"`
+	// fraction of subscribers replaced on every churn event
+	ChurnRate float64 `yaml:"churn_rate,omitempty"`
+}
+
+// PhaseResults are the stats of a single scenario phase, summed over all testers
+type PhaseResults struct {
+	Name        string  `json:"name"`
+	Kind        string  `json:"kind"`
+	DurationMs  float64 `json:"durationMs"`
+	Publishers  int     `json:"publishers"`
+	Subscribers int     `json:"subscribers"`
+	Joined      int     `json:"joined"`
+	Left        int     `json:"left"`
+	MuteChanges int     `json:"muteChanges,omitempty"`
+	Errors      int     `json:"errors"`
+	Packets     int64   `json:"packets"`
+	Bytes       int64   `json:"bytes"`
+	Dropped     int64   `json:"dropped"`
+	Bitrate     float64 `json:"bitrateBps"`
+	LossRate    float64 `json:"lossRate"`
+}
+
+// LoadScenario reads and validates a scenario file. The file is a text/template executed with
+// the environment variables as .Env, e.g. "subscribers: {{ .Env.SUBSCRIBERS }}", where vars
+// replace or add variables. A variable the file uses but that is not set is an error.
+func LoadScenario(path string, vars map[string]string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = expandScenario(path, data, vars); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %w", path, err)
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	s := &Scenario{}
+	if err = decoder.Decode(s); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %w", path, err)
+	}
+	if err = s.validate(); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s: %w", path, err)
+	}
+	return s, nil
+}
+
+// Save writes the scenario to path, preceded by comment lines
+func (s *Scenario) Save(path string, comment ...string) error {
+	data, err := s.Marshal(comment...)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// Marshal returns the scenario as YAML, preceded by comment lines
+func (s *Scenario) Marshal(comment ...string) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, line := range comment {
+		fmt.Fprintf(&buf, "# %s\n", line)
+	}
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(s); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *Scenario) validate() error {
+	if len(s.Phases) == 0 {
+		return fmt.Errorf("no phases")
+	}
+	for i, p := range s.Phases {
+		if p.Name == "" {
+			p.Name = fmt.Sprintf("%d-%s", i+1, p.Kind)
+		}
+		switch p.Kind {
+		case PhaseRamp, PhaseTeardown:
+		case PhaseHold, PhaseChurn, PhaseMuteStorm:
+			if p.Duration <= 0 {
+				return fmt.Errorf("phase %s: %s requires a duration", p.Name, p.Kind)
+			}
+		default:
+			return fmt.Errorf("phase %s: unsupported kind %q, expected %s, %s, %s, %s or %s",
+				p.Name, p.Kind, PhaseRamp, PhaseHold, PhaseChurn, PhaseMuteStorm, PhaseTeardown)
+		}
+		for _, count := range []*int{p.VideoPublishers, p.AudioPublishers, p.Subscribers} {
+			if count != nil && *count < 0 {
+				return fmt.Errorf("phase %s: tester counts cannot be negative", p.Name)
+			}
+		}
+		if p.ChurnRate < 0 || p.ChurnRate > 1 {
+			return fmt.Errorf("phase %s: churn_rate must be between 0 and 1", p.Name)
+		}
+	}
+	return nil
+}
+
+// scenarioRoom holds the testers currently in one of the test rooms
+type scenarioRoom struct {
+	index       int
+	publishers  []*LoadTester
+	subscribers []*LoadTester
+	// tracks published by the room's publishers
+	video int
+	audio int
+}
+
+// scenarioRun executes the phases of a scenario, tracking every tester that took part
+type scenarioRun struct {
+	t      *LoadTest
+	params Params
+	rooms  []*scenarioRoom
+
+	wg       sync.WaitGroup
+	lock     sync.Mutex
+	testers  []*LoadTester
+	errs     map[string]error
+	sequence int
+	// numbers the testers' names by role
+	pubNames int
+	subNames int
+
+	// counted for the current phase
+	joined      int
+	left        int
+	muteChanges int
+}
+
+// Peak returns the largest tester counts per room over all phases
+func (s *Scenario) Peak() (video, audio, subscribers int) {
+	var v, a, sub int
+	for _, p := range s.Phases {
+		if p.VideoPublishers != nil {
+			v = *p.VideoPublishers
+		}
+		if p.AudioPublishers != nil {
+			a = *p.AudioPublishers
+		}
+		if p.Subscribers != nil {
+			sub = *p.Subscribers
+		}
+		video, audio, subscribers = max(video, v), max(audio, a), max(subscribers, sub)
+	}
+	return
+}
+
+// RunScenario runs the phases of scenario one after the other, and reports the stats of
+// every phase along with the usual results
+func (t *LoadTest) RunScenario(ctx context.Context, scenario *Scenario) error {
+	peakParams := t.Params
+	peakParams.VideoPublishers, peakParams.AudioPublishers, peakParams.Subscribers = scenario.Peak()
+	peakParams.DataPublishers = 0
+	if err := validateParams(peakParams); err != nil {
+		return err
+	}
+	if err := checkUsagePolicy(peakParams); err != nil {
+		return err
+	}
+	if err := checkMediaFiles(t.Params); err != nil {
+		return err
+	}
+	closeLogs, err := t.openLogs()
+	if err != nil {
+		return err
+	}
+	defer closeLogs()
+
+	stopStatus, err := t.serveStatus(t.Params.MetricsAddr)
+	if err != nil {
+		return err
+	}
+	defer stopStatus()
+
+	stopSnapshots := t.postSnapshots(ctx, t.Params.ReportInterval)
+	defer stopSnapshots()
+
+	results, err := t.runScenario(ctx, scenario)
+	if err != nil {
+		return err
+	}
+	if err = writeStats(context.WithoutCancel(ctx), statsSinks(t.Params), results); err != nil {
+		return err
+	}
+	return t.Params.Thresholds.check(results)
+}
+
+// runScenario runs the phases of the scenario on the test's rooms, and returns their results
+func (t *LoadTest) runScenario(ctx context.Context, scenario *Scenario) (*Results, error) {
+	params := t.Params
+	if params.Room == "" {
+		params.Room = fmt.Sprintf("testroom%d", rand.Int31n(1000))
+	}
+	if params.IdentityPrefix == "" {
+		params.IdentityPrefix = randStringRunes(5)
+	}
+	if err := createRooms(ctx, params, params.RoomCount); err != nil {
+		return nil, err
+	}
+	r := &scenarioRun{t: t, params: params, errs: make(map[string]error)}
+	for j := 0; j < params.RoomCount; j++ {
+		r.rooms = append(r.rooms, &scenarioRoom{index: j})
+	}
+	fmt.Printf("Running scenario %s with %d phases, room: %s\n", scenario.Name, len(scenario.Phases), params.Room)
+	video, audio, subscribers := scenario.Peak()
+	t.status.begin((max(video, audio) + subscribers) * params.RoomCount)
+
+	t.recorder = newScenarioRecorder(t.Params, scenario.Name)
+	var phases []*PhaseResults
+	for _, phase := range scenario.Phases {
+		if ctx.Err() != nil {
+			break
+		}
+		result := r.runPhase(ctx, phase)
+		phases = append(phases, result)
+		t.recorder.phase(*phase, time.Duration(result.DurationMs*float64(time.Millisecond)))
+	}
+	fmt.Println("Scenario finished, disconnecting")
+	t.status.setPhase(phaseFinished)
+
+	stats := r.stop()
+	t.lock.Lock()
+	t.roomNames = nil
+	for j := range r.rooms {
+		t.roomNames = append(t.roomNames, params.roomName(j))
+	}
+	t.lock.Unlock()
+	if ctx.Err() != nil {
+		fmt.Println("\nScenario interrupted, reporting partial results")
+	}
+	if err := t.recorder.write(); err != nil {
+		return nil, err
+	}
+
+	results := t.results(stats)
+	results.Phases = phases
+	printDetails := results.printDetails
+	results.printDetails = func() {
+		printDetails()
+		printPhaseResults(phases)
+	}
+	return results, nil
+}
+
+func (r *scenarioRun) runPhase(ctx context.Context, phase *ScenarioPhase) *PhaseResults {
+	fmt.Printf("Phase %s: %s\n", phase.Name, phase.Kind)
+	r.lock.Lock()
+	r.joined, r.left, r.muteChanges = 0, 0, 0
+	errsBefore := len(r.errs)
+	r.lock.Unlock()
+	before := r.totals()
+	startedAt := time.Now()
+
+	params := r.params
+	if phase.VideoCodec != "" {
+		params.VideoCodec = phase.VideoCodec
+	}
+	rate := phase.NumPerSecond
+	if rate <= 0 {
+		rate = params.NumPerSecond
+	}
+	interval := phase.Interval
+	if interval <= 0 {
+		interval = defaultPhaseInterval
+	}
+
+	switch phase.Kind {
+	case PhaseRamp:
+		r.t.status.setPhase(phaseRamping)
+		r.resize(ctx, params, phase, rate)
+		r.wg.Wait()
+		r.t.status.setPhase(phaseRunning)
+		sleepUntil(ctx, time.Now().Add(phase.Duration))
+	case PhaseHold:
+		sleepUntil(ctx, startedAt.Add(phase.Duration))
+	case PhaseChurn:
+		churnRate := phase.ChurnRate
+		if churnRate == 0 {
+			churnRate = defaultChurnRate
+		}
+		r.every(ctx, startedAt.Add(phase.Duration), interval, func() {
+			r.churn(ctx, params, churnRate)
+		})
+		r.wg.Wait()
+	case PhaseMuteStorm:
+		r.every(ctx, startedAt.Add(phase.Duration), interval, r.toggleMutes)
+	case PhaseTeardown:
+		zero := 0
+		teardown := *phase
+		for _, count := range []**int{&teardown.VideoPublishers, &teardown.AudioPublishers, &teardown.Subscribers} {
+			if *count == nil {
+				*count = &zero
+			}
+		}
+		r.resize(ctx, params, &teardown, rate)
+		r.wg.Wait()
+		sleepUntil(ctx, time.Now().Add(phase.Duration))
+	}
+
+	elapsed := time.Since(startedAt)
+	after := r.totals()
+	var publishers, subscribers int
+	for _, room := range r.rooms {
+		publishers += len(room.publishers)
+		subscribers += len(room.subscribers)
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	res := &PhaseResults{
+		Name:        phase.Name,
+		Kind:        phase.Kind,
+		DurationMs:  durationMs(elapsed),
+		Publishers:  publishers,
+		Subscribers: subscribers,
+		Joined:      r.joined,
+		Left:        r.left,
+		MuteChanges: r.muteChanges,
+		Errors:      len(r.errs) - errsBefore,
+		Packets:     after.packets - before.packets,
+		Bytes:       after.bytes - before.bytes,
+		Dropped:     after.dropped - before.dropped,
+	}
+	if elapsed > 0 {
+		res.Bitrate = float64(res.Bytes*8) / elapsed.Seconds()
+	}
+	if res.Packets+res.Dropped > 0 {
+		res.LossRate = float64(res.Dropped) / float64(res.Packets+res.Dropped)
+	}
+	return res
+}
+
+// every calls fn at interval until deadline
+func (r *scenarioRun) every(ctx context.Context, deadline time.Time, interval time.Duration, fn func()) {
+	for next := time.Now().Add(interval); next.Before(deadline); next = next.Add(interval) {
+		if !sleepUntil(ctx, next) {
+			return
+		}
+		fn()
+	}
+	sleepUntil(ctx, deadline)
+}
+
+// resize joins or removes testers in every room until they match the phase's counts, at rate
+// testers per second. Publishers are added before subscribers, and removed after them.
+func (r *scenarioRun) resize(ctx context.Context, params Params, phase *ScenarioPhase, rate float64) {
+	pace := time.Duration(float64(time.Second) / rate)
+	next := time.Now()
+	wait := func() bool {
+		ok := sleepUntil(ctx, next)
+		next = next.Add(pace)
+		return ok
+	}
+
+	for _, room := range r.rooms {
+		video, audio := room.video, room.audio
+		if phase.VideoPublishers != nil {
+			video = *phase.VideoPublishers
+		}
+		if phase.AudioPublishers != nil {
+			audio = *phase.AudioPublishers
+		}
+		subscribers := len(room.subscribers)
+		if phase.Subscribers != nil {
+			subscribers = *phase.Subscribers
+		}
+
+		for len(room.subscribers) > subscribers {
+			if !wait() {
+				return
+			}
+			r.remove(room, true)
+		}
+		for len(room.publishers) > max(video, audio) {
+			if !wait() {
+				return
+			}
+			r.remove(room, false)
+		}
+		// existing publishers keep their tracks, new ones publish what is still missing
+		for room.video < video || room.audio < audio {
+			if !wait() {
+				return
+			}
+			r.join(ctx, params, room, room.video < video, room.audio < audio)
+		}
+		for len(room.subscribers) < subscribers {
+			if !wait() {
+				return
+			}
+			r.join(ctx, params, room, false, false)
+		}
+	}
+}
+
+// churn replaces a random fraction of the subscribers in every room
+func (r *scenarioRun) churn(ctx context.Context, params Params, churnRate float64) {
+	for _, room := range r.rooms {
+		n := int(math.Round(churnRate * float64(len(room.subscribers))))
+		for i := 0; i < n; i++ {
+			k := rand.Intn(len(room.subscribers))
+			room.subscribers[k], room.subscribers[len(room.subscribers)-1] = room.subscribers[len(room.subscribers)-1], room.subscribers[k]
+			r.remove(room, true)
+			r.join(ctx, params, room, false, false)
+		}
+	}
+}
+
+func (r *scenarioRun) toggleMutes() {
+	for _, room := range r.rooms {
+		for _, tester := range room.publishers {
+			if changed := tester.toggleMute(); changed > 0 {
+				r.lock.Lock()
+				r.muteChanges += changed
+				r.lock.Unlock()
+			}
+		}
+	}
+}
+
+// join starts a tester in room, publishing video and audio as requested
+func (r *scenarioRun) join(ctx context.Context, params Params, room *scenarioRoom, video, audio bool) {
+	r.lock.Lock()
+	seq := r.sequence
+	r.sequence++
+	testerParams := params.TesterParams
+	testerParams.Room = params.roomName(room.index)
+	testerParams.Sequence = seq
+	testerParams.stateLog = r.t.stateLog
+	testerParams.errorLogs = r.t.errorLogs
+	testerParams.Cohort = cohortFor(params.Cohorts, seq)
+	testerParams.Proxy = proxyFor(params.Proxies, seq)
+	testerParams.URL, testerParams.perEndpoint = params.endpointFor(seq, room.index)
+	// testers beyond the pre-minted tokens mint their own
+	testerParams.useToken(params.Tokens, seq)
+	testerParams.Role = roleFor(video, audio, false)
+	if testerParams.Role.IsPublisher() {
+		testerParams.name = fmt.Sprintf("Pub %d", r.pubNames)
+		r.pubNames++
+	} else {
+		testerParams.Subscribe = true
+		testerParams.expectedTracks = testerParams.selectiveTracks(room.video + room.audio)
+		testerParams.expectAudio = room.audio > 0
+		testerParams.expectVideo = room.video > 0
+		testerParams.name = fmt.Sprintf("Sub %d", r.subNames)
+		r.subNames++
+	}
+	tester := NewLoadTester(testerParams)
+	r.testers = append(r.testers, tester)
+	r.joined++
+	r.lock.Unlock()
+
+	if tester.params.Role.IsPublisher() {
+		room.publishers = append(room.publishers, tester)
+		if video {
+			room.video++
+		}
+		if audio {
+			room.audio++
+		}
+	} else {
+		room.subscribers = append(room.subscribers, tester)
+	}
+	r.t.status.addTester(tester)
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		if err := r.t.startTesterWithRetries(ctx, params, tester, video, audio, false, false); err != nil {
+			r.lock.Lock()
+			r.errs[tester.params.name] = err
+			r.lock.Unlock()
+		}
+	}()
+}
+
+// remove stops the last subscriber or publisher of room
+func (r *scenarioRun) remove(room *scenarioRoom, subscriber bool) {
+	var tester *LoadTester
+	if subscriber {
+		tester = room.subscribers[len(room.subscribers)-1]
+		room.subscribers = room.subscribers[:len(room.subscribers)-1]
+	} else {
+		tester = room.publishers[len(room.publishers)-1]
+		room.publishers = room.publishers[:len(room.publishers)-1]
+		switch tester.params.Role {
+		case RoleVideoPublisher:
+			room.video--
+		case RoleAudioPublisher:
+			room.audio--
+		case RoleAVPublisher:
+			room.video--
+			room.audio--
+		}
+	}
+	r.lock.Lock()
+	r.left++
+	r.lock.Unlock()
+	tester.Stop()
+}
+
+type scenarioTotals struct {
+	packets int64
+	bytes   int64
+	dropped int64
+}
+
+// totals sums the media received by every tester so far
+func (r *scenarioRun) totals() scenarioTotals {
+	r.lock.Lock()
+	testers := append([]*LoadTester(nil), r.testers...)
+	r.lock.Unlock()
+
+	var totals scenarioTotals
+	for _, tester := range testers {
+		tester.stats.Range(func(_, value any) bool {
+			ts := value.(*trackStats)
+			totals.packets += ts.packets.Load()
+			totals.bytes += ts.bytes.Load()
+			totals.dropped += ts.dropped.Load()
+			return true
+		})
+	}
+	return totals
+}
+
+// stop disconnects the remaining testers and returns the stats of all of them
+func (r *scenarioRun) stop() map[string]*testerStats {
+	r.wg.Wait()
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	stats := make(map[string]*testerStats)
+	for _, tester := range r.testers {
+		tester.Stop()
+		s := tester.getStats()
+		s.role = tester.params.Role
+		s.room = tester.params.Room
+		if tester.params.perEndpoint {
+			s.endpoint = tester.params.URL
+		}
+		if tester.params.Cohort != nil {
+			s.cohort = tester.params.Cohort.String()
+			s.label = tester.params.Cohort.Label()
+		}
+		s.err = r.errs[tester.params.name]
+		stats[tester.params.name] = s
+	}
+	return stats
+}
+
+// toggleMute mutes or unmutes all of the tester's tracks, returning how many changed
+func (t *LoadTester) toggleMute() int {
+	if !t.IsRunning() {
+		return 0
+	}
+	changed := 0
+	for _, pub := range t.room.LocalParticipant.TrackPublications() {
+		if local, ok := pub.(*lksdk.LocalTrackPublication); ok {
+			local.SetMuted(!local.IsMuted())
+			changed++
+		}
+	}
+	return changed
+}
+
+func printPhaseResults(phases []*PhaseResults) {
+	if len(phases) == 0 {
+		return
+	}
+	table := util.CreateTable().
+		Headers("Phase", "Kind", "Duration", "Pubs", "Subs", "Joined", "Left", "Mutes", "Errors", "Pkts.", "Bitrate", "Pkt. Loss")
+	for _, p := range phases {
+		elapsed := time.Duration(p.DurationMs * float64(time.Millisecond))
+		table.Row(
+			p.Name,
+			p.Kind,
+			elapsed.Round(time.Second).String(),
+			strconv.Itoa(p.Publishers),
+			strconv.Itoa(p.Subscribers),
+			strconv.Itoa(p.Joined),
+			strconv.Itoa(p.Left),
+			strconv.Itoa(p.MuteChanges),
+			strconv.Itoa(p.Errors),
+			strconv.FormatInt(p.Packets, 10),
+			formatBitrate(p.Bytes, elapsed),
+			formatLossRate(p.Packets, p.Dropped),
+		)
+	}
+	fmt.Println("\nScenario phases:")
+	fmt.Println(table)
+}
@@ -0,0 +1,105 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func writeScenario(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "scenario.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestLoadScenario(t *testing.T) {
+	s, err := LoadScenario("../../cmd/lk/examples/load-test-scenario.yaml", nil)
+	require.NoError(t, err)
+	require.Len(t, s.Phases, 6)
+	require.Equal(t, "ramp-up", s.Phases[0].Name)
+	require.Equal(t, 20, *s.Phases[0].Subscribers)
+	require.Equal(t, "2-hold", s.Phases[1].Name)
+	require.Equal(t, time.Minute, s.Phases[1].Duration)
+	require.Equal(t, 0.2, s.Phases[2].ChurnRate)
+	require.Equal(t, "vp9", s.Phases[4].VideoCodec)
+	require.Nil(t, s.Phases[4].Subscribers)
+
+	video, audio, subscribers := s.Peak()
+	require.Equal(t, 6, video)
+	require.Equal(t, 4, audio)
+	require.Equal(t, 20, subscribers)
+
+	for name, content := range map[string]string{
+		"unknown kind":     "phases:\n  - kind: soak\n",
+		"missing duration": "phases:\n  - kind: hold\n",
+		"negative count":   "phases:\n  - kind: ramp\n    subscribers: -1\n",
+		"unknown field":    "phases:\n  - kind: ramp\n    subscriber: 1\n",
+		"no phases":        "name: empty\n",
+	} {
+		_, err = LoadScenario(writeScenario(t, content), nil)
+		require.Error(t, err, name)
+	}
+}
+
+func TestLoadScenarioTemplate(t *testing.T) {
+	path := writeScenario(t, "name: {{ .Env.LK_TEST_SIZE }}\nphases:\n  - kind: ramp\n    subscribers: {{ .Env.LK_TEST_SUBSCRIBERS }}\n")
+	t.Setenv("LK_TEST_SIZE", "smoke")
+	t.Setenv("LK_TEST_SUBSCRIBERS", "5")
+
+	s, err := LoadScenario(path, nil)
+	require.NoError(t, err)
+	require.Equal(t, "smoke", s.Name)
+	require.Equal(t, 5, *s.Phases[0].Subscribers)
+
+	vars, err := ParseScenarioVars([]string{"LK_TEST_SIZE=nightly", "LK_TEST_SUBSCRIBERS=50"})
+	require.NoError(t, err)
+	s, err = LoadScenario(path, vars)
+	require.NoError(t, err)
+	require.Equal(t, "nightly", s.Name)
+	require.Equal(t, 50, *s.Phases[0].Subscribers)
+
+	_, err = LoadScenario(writeScenario(t, "phases:\n  - kind: ramp\n    subscribers: {{ .Env.LK_TEST_UNSET }}\n"), nil)
+	require.ErrorContains(t, err, "LK_TEST_UNSET")
+	_, err = LoadScenario(writeScenario(t, "phases: {{ .Env.\n"), nil)
+	require.Error(t, err)
+}
+
+func TestScenarioResize(t *testing.T) {
+	r := &scenarioRun{t: NewLoadTest(Params{}), errs: make(map[string]error)}
+	room := &scenarioRoom{}
+	r.rooms = []*scenarioRoom{room}
+	// testers are not started, only tracked
+	for _, role := range []Role{RoleAVPublisher, RoleVideoPublisher, RoleSubscriber, RoleSubscriber} {
+		tester := NewLoadTester(TesterParams{Role: role})
+		if role.IsPublisher() {
+			room.publishers = append(room.publishers, tester)
+		} else {
+			room.subscribers = append(room.subscribers, tester)
+		}
+	}
+	room.video, room.audio = 2, 1
+
+	r.remove(room, false)
+	require.Equal(t, 1, room.video)
+	require.Equal(t, 1, room.audio)
+	r.remove(room, true)
+	require.Len(t, room.subscribers, 1)
+	require.Equal(t, 2, r.left)
+}
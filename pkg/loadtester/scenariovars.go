@@ -0,0 +1,68 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// expandScenario executes a scenario file as a text/template with the environment variables as .Env,
+// e.g. "subscribers: {{ .Env.SUBSCRIBERS }}", where vars replace or add variables. A variable the file
+// uses but that is not set is an error.
+func expandScenario(path string, data []byte, vars map[string]string) ([]byte, error) {
+	tmpl, err := template.New(filepath.Base(path)).Option("missingkey=error").Parse(string(data))
+	if err != nil {
+		return nil, err
+	}
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if key, value, ok := strings.Cut(kv, "="); ok {
+			env[key] = value
+		}
+	}
+	for key, value := range vars {
+		env[key] = value
+	}
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, struct{ Env map[string]string }{env}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ParseScenarioVars parses --set values, each KEY=VALUE
+func ParseScenarioVars(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	vars := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("scenario variable %q is not KEY=VALUE", pair)
+		}
+		if _, ok := vars[key]; ok {
+			return nil, fmt.Errorf("scenario variable %s is set twice", key)
+		}
+		vars[key] = value
+	}
+	return vars, nil
+}
@@ -0,0 +1,45 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandScenario(t *testing.T) {
+	t.Setenv("LK_TEST_SUBSCRIBERS", "5")
+	data, err := expandScenario("soak.yaml", []byte("subscribers: {{ .Env.LK_TEST_SUBSCRIBERS }}\n"), nil)
+	require.NoError(t, err)
+	require.Equal(t, "subscribers: 5\n", string(data))
+
+	vars, err := ParseScenarioVars([]string{"LK_TEST_SUBSCRIBERS=50", "LK_TEST_SIZE=nightly"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"LK_TEST_SUBSCRIBERS": "50", "LK_TEST_SIZE": "nightly"}, vars)
+	data, err = expandScenario("soak.yaml", []byte("subscribers: {{ .Env.LK_TEST_SUBSCRIBERS }}\n"), vars)
+	require.NoError(t, err)
+	require.Equal(t, "subscribers: 50\n", string(data))
+
+	_, err = expandScenario("soak.yaml", []byte("subscribers: {{ .Env.LK_TEST_UNSET }}\n"), nil)
+	require.ErrorContains(t, err, "LK_TEST_UNSET")
+	_, err = expandScenario("soak.yaml", []byte("phases: {{ .Env.\n"), nil)
+	require.Error(t, err)
+
+	for _, pairs := range [][]string{{"SIZE"}, {"=1"}, {"SIZE=1", "SIZE=2"}} {
+		_, err = ParseScenarioVars(pairs)
+		require.Error(t, err, pairs)
+	}
+}
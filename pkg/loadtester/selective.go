@@ -0,0 +1,162 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"fmt"
+	"math/rand"
+	"slices"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+// strategies subscribers pick their tracks by when they only subscribe to some
+const (
+	// a uniformly random subset of the published tracks
+	SubscribeRandom = "random"
+	// the tracks of the active speakers, keeping the previous ones until displaced
+	SubscribeLoudest = "loudest"
+	// the most recently published tracks, dropping the oldest subscription
+	SubscribeNewest = "newest"
+)
+
+// ValidateSubscribeStrategy checks --subscribe-count and --subscribe-strategy
+func ValidateSubscribeStrategy(params Params) error {
+	if params.SubscribeCount < 0 {
+		return fmt.Errorf("subscribe count cannot be negative")
+	}
+	switch params.SubscribeStrategy {
+	case "", SubscribeRandom, SubscribeLoudest, SubscribeNewest:
+	default:
+		return fmt.Errorf("unsupported subscribe strategy %q, expected %s, %s or %s",
+			params.SubscribeStrategy, SubscribeRandom, SubscribeLoudest, SubscribeNewest)
+	}
+	if params.SubscribeCount > 0 && params.Layout == LayoutSpeakerFollow {
+		return fmt.Errorf("--subscribe-count cannot be combined with the speaker-follow layout, which picks its own subscriptions")
+	}
+	return nil
+}
+
+// selectiveTracks caps the tracks a subscriber expects to receive at its subscribe count
+func (p *TesterParams) selectiveTracks(tracks int) int {
+	if p.SubscribeCount > 0 {
+		return min(tracks, p.SubscribeCount)
+	}
+	return tracks
+}
+
+// trackSelection is the subset of tracks a selective subscriber is subscribed to
+type trackSelection struct {
+	// oldest subscription first
+	subscribed []*lksdk.RemoteTrackPublication
+	// tracks published so far, for sampling a random subset
+	seen int
+}
+
+func (s *trackSelection) remove(sid string) {
+	s.subscribed = slices.DeleteFunc(s.subscribed, func(pub *lksdk.RemoteTrackPublication) bool {
+		return pub.SID() == sid
+	})
+}
+
+// selectTrack decides whether a selective subscriber subscribes to a newly published track,
+// returning the track it gives up for it, if any. Called with t.lock held.
+func (t *LoadTester) selectTrack(pub *lksdk.RemoteTrackPublication, rp *lksdk.RemoteParticipant) (subscribe bool, evicted *lksdk.RemoteTrackPublication) {
+	s := &t.selection
+	s.seen++
+	count := t.params.SubscribeCount
+	if len(s.subscribed) < count {
+		s.subscribed = append(s.subscribed, pub)
+		t.subscribedParticipants[rp.Identity()] = rp
+		return true, nil
+	}
+	switch t.params.SubscribeStrategy {
+	case SubscribeNewest:
+		evicted = s.subscribed[0]
+		s.subscribed = append(s.subscribed[1:], pub)
+	case SubscribeLoudest:
+		// only speaking takes a track on screen
+		return false, nil
+	default:
+		// reservoir sampling keeps every track equally likely to be in the subset
+		i := rand.Intn(s.seen)
+		if i >= count {
+			return false, nil
+		}
+		evicted = s.subscribed[i]
+		s.subscribed = append(slices.Delete(s.subscribed, i, i+1), pub)
+	}
+	t.subscribedParticipants[rp.Identity()] = rp
+	return true, evicted
+}
+
+// followLoudest subscribes a selective subscriber with the loudest strategy to the tracks of
+// the active speakers, loudest first, giving up the longest held other tracks to make room
+func (t *LoadTester) followLoudest(speakers []lksdk.Participant) {
+	if t.params.SubscribeCount == 0 || t.params.SubscribeStrategy != SubscribeLoudest || !t.params.Subscribe {
+		return
+	}
+	count := t.params.SubscribeCount
+	t.lock.Lock()
+	var wanted []*lksdk.RemoteTrackPublication
+	for _, p := range speakers {
+		rp, ok := p.(*lksdk.RemoteParticipant)
+		if !ok {
+			continue
+		}
+		for _, pub := range rp.TrackPublications() {
+			if remotePub, ok := pub.(*lksdk.RemoteTrackPublication); ok && len(wanted) < count {
+				wanted = append(wanted, remotePub)
+				t.subscribedParticipants[rp.Identity()] = rp
+			}
+		}
+	}
+	var added, evicted []*lksdk.RemoteTrackPublication
+	for _, pub := range wanted {
+		if !slices.Contains(t.selection.subscribed, pub) {
+			added = append(added, pub)
+		}
+	}
+	// the longest held tracks that aren't speaking make room
+	var kept []*lksdk.RemoteTrackPublication
+	for _, pub := range t.selection.subscribed {
+		if !slices.Contains(wanted, pub) {
+			kept = append(kept, pub)
+		}
+	}
+	if free := count - len(wanted); len(kept) > free {
+		evicted = kept[:len(kept)-free]
+		kept = kept[len(kept)-free:]
+	}
+	t.selection.subscribed = append(kept, wanted...)
+	t.lock.Unlock()
+
+	for _, pub := range evicted {
+		_ = t.unsubscribe(pub)
+	}
+	for _, pub := range added {
+		_ = pub.SetSubscribed(true)
+	}
+}
+
+// onTrackUnpublished frees the slot of an unpublished track for the next one
+func (t *LoadTester) onTrackUnpublished(pub *lksdk.RemoteTrackPublication, _ *lksdk.RemoteParticipant) {
+	if t.params.SubscribeCount == 0 {
+		return
+	}
+	t.lock.Lock()
+	t.selection.remove(pub.SID())
+	t.lock.Unlock()
+}
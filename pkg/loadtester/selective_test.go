@@ -0,0 +1,86 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+func TestValidateSubscribeStrategy(t *testing.T) {
+	params := Params{TesterParams: TesterParams{SubscribeCount: 4, SubscribeStrategy: SubscribeLoudest}}
+	require.NoError(t, ValidateSubscribeStrategy(params))
+	require.Equal(t, 4, params.selectiveTracks(10))
+	require.Equal(t, 3, params.selectiveTracks(3))
+
+	params.SubscribeStrategy = "oldest"
+	require.Error(t, ValidateSubscribeStrategy(params))
+	params.SubscribeStrategy = SubscribeRandom
+	params.Layout = LayoutSpeakerFollow
+	require.Error(t, ValidateSubscribeStrategy(params))
+	params.SubscribeCount = 0
+	require.NoError(t, ValidateSubscribeStrategy(params))
+	require.Equal(t, 10, params.selectiveTracks(10))
+}
+
+func publishTracks(tester *LoadTester, n int) ([]*lksdk.RemoteTrackPublication, []bool, []*lksdk.RemoteTrackPublication) {
+	var pubs, evicted []*lksdk.RemoteTrackPublication
+	var subscribed []bool
+	for i := 0; i < n; i++ {
+		pub := &lksdk.RemoteTrackPublication{}
+		subscribe, e := tester.selectTrack(pub, &lksdk.RemoteParticipant{})
+		pubs = append(pubs, pub)
+		subscribed = append(subscribed, subscribe)
+		if e != nil {
+			evicted = append(evicted, e)
+		}
+	}
+	return pubs, subscribed, evicted
+}
+
+func TestSelectTrack(t *testing.T) {
+	newest := NewLoadTester(TesterParams{Subscribe: true, SubscribeCount: 2, SubscribeStrategy: SubscribeNewest})
+	pubs, subscribed, evicted := publishTracks(newest, 4)
+	require.Equal(t, []bool{true, true, true, true}, subscribed)
+	require.Equal(t, pubs[:2], evicted)
+	require.Equal(t, pubs[2:], newest.selection.subscribed)
+
+	loudest := NewLoadTester(TesterParams{Subscribe: true, SubscribeCount: 2, SubscribeStrategy: SubscribeLoudest})
+	pubs, subscribed, evicted = publishTracks(loudest, 4)
+	require.Equal(t, []bool{true, true, false, false}, subscribed)
+	require.Empty(t, evicted)
+	require.Equal(t, pubs[:2], loudest.selection.subscribed)
+
+	// every track ends up in a random subset about as often
+	kept := make([]int, 4)
+	for n := 0; n < 2000; n++ {
+		random := NewLoadTester(TesterParams{Subscribe: true, SubscribeCount: 2})
+		pubs, _, _ = publishTracks(random, 4)
+		require.Len(t, random.selection.subscribed, 2)
+		for i, pub := range pubs {
+			for _, s := range random.selection.subscribed {
+				if s == pub {
+					kept[i]++
+				}
+			}
+		}
+	}
+	for _, k := range kept {
+		require.InDelta(t, 1000, k, 150)
+	}
+}
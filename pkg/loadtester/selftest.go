@@ -0,0 +1,423 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/pion/interceptor"
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v4"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+
+	"github.com/livekit/livekit-cli/v2/pkg/provider"
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+const (
+	DefaultSelfTestDuration   = 20 * time.Second
+	DefaultSelfTestPacketLoss = 0.05
+	DefaultSelfTestLatency    = 40 * time.Millisecond
+	DefaultSelfTestJitter     = 8 * time.Millisecond
+
+	selfTestClockRate = 48000
+	// a latency stamp rides along every this many packets
+	selfTestStampEvery = 5
+	selfTestTrackID    = "TR_selftest"
+	// the peer connections normally connect within milliseconds
+	selfTestConnectTimeout = 10 * time.Second
+)
+
+// SelfTestParams configure a loopback run checking the stats the load tester reports
+// against traffic with a known impairment
+type SelfTestParams struct {
+	Duration   time.Duration
+	Impairment Impairment
+}
+
+// ValidateSelfTest checks that the impairment of a self test is one it can measure. Jitter
+// has to stay below the packet interval, reordered packets would be counted as lost.
+func ValidateSelfTest(p SelfTestParams) error {
+	if p.Duration < time.Second {
+		return fmt.Errorf("self test duration %s is too short, at least 1s is needed", p.Duration)
+	}
+	if p.Impairment.PacketLoss < 0 || p.Impairment.PacketLoss >= 1 {
+		return fmt.Errorf("packet loss %g is out of range, expected 0 to 1", p.Impairment.PacketLoss)
+	}
+	if p.Impairment.Latency < 0 || p.Impairment.Jitter < 0 {
+		return errors.New("latency and jitter cannot be negative")
+	}
+	if p.Impairment.Jitter >= opusPacketDuration {
+		return fmt.Errorf("jitter %s has to be below the %s packet interval", p.Impairment.Jitter, opusPacketDuration)
+	}
+	if p.Impairment.BandwidthCap > 0 {
+		return errors.New("self test does not support a bandwidth cap")
+	}
+	return nil
+}
+
+// SelfTestCheck compares one reported metric with the value the loopback measured itself
+type SelfTestCheck struct {
+	Metric   string
+	Expected float64
+	Reported float64
+	// largest accepted difference
+	Tolerance float64
+	// formats the values for printing
+	format func(float64) string
+}
+
+func (c SelfTestCheck) Passed() bool {
+	return math.Abs(c.Reported-c.Expected) <= c.Tolerance
+}
+
+type SelfTestResults struct {
+	Sent      int
+	Delivered int
+	Checks    []SelfTestCheck
+}
+
+func (r *SelfTestResults) Failed() []string {
+	var failed []string
+	for _, c := range r.Checks {
+		if !c.Passed() {
+			failed = append(failed, c.Metric)
+		}
+	}
+	return failed
+}
+
+// loopback records what the publisher sent and the subscriber received, as the truth the
+// subscriber's stats are checked against
+type loopback struct {
+	tester   *LoadTester
+	consumer *trackConsumer
+
+	lock    sync.Mutex
+	sentAt  map[uint16]time.Time
+	sent    int
+	arrived []loopbackArrival
+}
+
+type loopbackArrival struct {
+	seq       uint16
+	timestamp uint32
+	at        time.Time
+	delay     time.Duration
+}
+
+// RunSelfTest publishes generated audio from one peer connection to another over the
+// loopback interface, with the impairment on the publisher's uplink, into the same pipeline
+// that consumes subscribed tracks, and checks that the stats it reports match what the
+// subscriber received. No server is involved.
+func RunSelfTest(ctx context.Context, params SelfTestParams) (*SelfTestResults, error) {
+	if err := ValidateSelfTest(params); err != nil {
+		return nil, err
+	}
+
+	tester := NewLoadTester(TesterParams{
+		IdentityPrefix: "selftest",
+		Role:           RoleSubscriber,
+		Subscribe:      true,
+	})
+	ts := &trackStats{
+		trackID:     selfTestTrackID,
+		kind:        lksdk.TrackKindAudio,
+		publishedAt: time.Now(),
+	}
+	ts.startedAt.Store(time.Now())
+	tester.stats.Store(selfTestTrackID, ts)
+	l := &loopback{
+		tester:   tester,
+		consumer: newTrackConsumer(ts, webrtc.MimeTypeOpus, selfTestClockRate, false, 0, func() {}),
+		sentAt:   make(map[uint16]time.Time),
+	}
+
+	// interceptors closer to the network come first, so packets are recorded before they are impaired
+	pubAPI, err := newLoopbackAPI(params.Impairment, loopbackInterceptor{l})
+	if err != nil {
+		return nil, err
+	}
+	subAPI, err := newLoopbackAPI()
+	if err != nil {
+		return nil, err
+	}
+	pub, err := pubAPI.NewPeerConnection(webrtc.Configuration{})
+	if err != nil {
+		return nil, err
+	}
+	defer pub.Close()
+	sub, err := subAPI.NewPeerConnection(webrtc.Configuration{})
+	if err != nil {
+		return nil, err
+	}
+	defer sub.Close()
+
+	track, err := webrtc.NewTrackLocalStaticSample(webrtc.RTPCodecCapability{
+		MimeType:  webrtc.MimeTypeOpus,
+		ClockRate: selfTestClockRate,
+		Channels:  2,
+	}, "audio", "selftest")
+	if err != nil {
+		return nil, err
+	}
+	if _, err = pub.AddTrack(track); err != nil {
+		return nil, err
+	}
+	received := make(chan struct{})
+	sub.OnTrack(func(remote *webrtc.TrackRemote, _ *webrtc.RTPReceiver) {
+		defer close(received)
+		for {
+			pkt, _, err := remote.ReadRTP()
+			if err != nil {
+				return
+			}
+			l.deliver(pkt, time.Now())
+		}
+	})
+	connected := make(chan struct{})
+	var connectOnce sync.Once
+	pub.OnConnectionStateChange(func(state webrtc.PeerConnectionState) {
+		if state == webrtc.PeerConnectionStateConnected {
+			connectOnce.Do(func() { close(connected) })
+		}
+	})
+	if err = connectLoopback(pub, sub); err != nil {
+		return nil, err
+	}
+	select {
+	case <-connected:
+	case <-time.After(selfTestConnectTimeout):
+		return nil, errors.New("self test peer connections did not connect over the loopback interface")
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	if err = l.send(ctx, track, provider.NewOpusGenerator(2), params.Duration); err != nil {
+		return nil, err
+	}
+	// let the last packets arrive, then end the track
+	time.Sleep(params.Impairment.Latency + params.Impairment.Jitter + opusPacketDuration)
+	_ = pub.Close()
+	_ = sub.Close()
+	select {
+	case <-received:
+	case <-time.After(selfTestConnectTimeout):
+	}
+	l.consumer.flush(time.Now())
+
+	return l.results(), nil
+}
+
+// newLoopbackAPI returns a WebRTC API for Opus only, without the default interceptors, so lost
+// packets aren't retransmitted, that connects over the loopback interface
+func newLoopbackAPI(interceptors ...interceptor.Factory) (*webrtc.API, error) {
+	m := &webrtc.MediaEngine{}
+	if err := m.RegisterCodec(webrtc.RTPCodecParameters{
+		RTPCodecCapability: webrtc.RTPCodecCapability{MimeType: webrtc.MimeTypeOpus, ClockRate: selfTestClockRate, Channels: 2},
+		PayloadType:        111,
+	}, webrtc.RTPCodecTypeAudio); err != nil {
+		return nil, err
+	}
+	registry := &interceptor.Registry{}
+	for _, i := range interceptors {
+		registry.Add(i)
+	}
+	se := webrtc.SettingEngine{}
+	se.SetIncludeLoopbackCandidate(true)
+	se.SetIPFilter(func(ip net.IP) bool { return ip.IsLoopback() })
+	se.SetNetworkTypes([]webrtc.NetworkType{webrtc.NetworkTypeUDP4})
+	return webrtc.NewAPI(webrtc.WithMediaEngine(m), webrtc.WithInterceptorRegistry(registry), webrtc.WithSettingEngine(se)), nil
+}
+
+// connectLoopback negotiates pub to send to sub, with all candidates in the descriptions
+func connectLoopback(pub, sub *webrtc.PeerConnection) error {
+	offer, err := pub.CreateOffer(nil)
+	if err != nil {
+		return err
+	}
+	gathered := webrtc.GatheringCompletePromise(pub)
+	if err = pub.SetLocalDescription(offer); err != nil {
+		return err
+	}
+	<-gathered
+	if err = sub.SetRemoteDescription(*pub.LocalDescription()); err != nil {
+		return err
+	}
+	answer, err := sub.CreateAnswer(nil)
+	if err != nil {
+		return err
+	}
+	gathered = webrtc.GatheringCompletePromise(sub)
+	if err = sub.SetLocalDescription(answer); err != nil {
+		return err
+	}
+	<-gathered
+	return pub.SetRemoteDescription(*sub.LocalDescription())
+}
+
+// loopbackInterceptor records the time every packet of the publisher was sent
+type loopbackInterceptor struct {
+	l *loopback
+}
+
+func (i loopbackInterceptor) NewInterceptor(_ string) (interceptor.Interceptor, error) {
+	return &loopbackRecorder{l: i.l}, nil
+}
+
+type loopbackRecorder struct {
+	interceptor.NoOp
+	l *loopback
+}
+
+func (r *loopbackRecorder) BindLocalStream(_ *interceptor.StreamInfo, writer interceptor.RTPWriter) interceptor.RTPWriter {
+	return interceptor.RTPWriterFunc(func(header *rtp.Header, payload []byte, a interceptor.Attributes) (int, error) {
+		r.l.lock.Lock()
+		r.l.sentAt[header.SequenceNumber] = time.Now()
+		r.l.sent++
+		r.l.lock.Unlock()
+		return writer.Write(header, payload, a)
+	})
+}
+
+// send publishes samples of audio in real time for duration
+func (l *loopback) send(ctx context.Context, track *webrtc.TrackLocalStaticSample, audio *provider.OpusGenerator, duration time.Duration) error {
+	ticker := time.NewTicker(opusPacketDuration)
+	defer ticker.Stop()
+	deadline := time.After(duration)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-deadline:
+			return nil
+		case <-ticker.C:
+		}
+		sample, err := audio.NextSample(ctx)
+		if err != nil {
+			return err
+		}
+		if err = track.WriteSample(sample); err != nil {
+			return err
+		}
+	}
+}
+
+func (l *loopback) deliver(pkt *rtp.Packet, now time.Time) {
+	l.lock.Lock()
+	sentAt := l.sentAt[pkt.SequenceNumber]
+	l.arrived = append(l.arrived, loopbackArrival{
+		seq:       pkt.SequenceNumber,
+		timestamp: pkt.Timestamp,
+		at:        now,
+		delay:     now.Sub(sentAt),
+	})
+	l.lock.Unlock()
+
+	l.consumer.push(pkt, now)
+	if pkt.SequenceNumber%selfTestStampEvery == 0 {
+		stamp := make([]byte, 8)
+		binary.BigEndian.PutUint64(stamp, uint64(sentAt.UnixNano()))
+		l.tester.onLatencyStamp(stamp)
+	}
+}
+
+func (l *loopback) results() *SelfTestResults {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	reported := l.tester.Results()
+	ts := l.consumer.ts
+	results := &SelfTestResults{Sent: l.sent, Delivered: len(l.arrived)}
+	if len(l.arrived) < 2 {
+		return results
+	}
+
+	var latencies, jitters []time.Duration
+	// packets arriving after a later one are too late to play, and concealed like lost ones
+	late := 0
+	highest := l.arrived[0].seq
+	for i, a := range l.arrived {
+		if int16(a.seq-highest) < 0 {
+			late++
+		} else {
+			highest = a.seq
+		}
+		if a.seq%selfTestStampEvery == 0 {
+			latencies = append(latencies, a.delay)
+		}
+		if i > 0 {
+			// the variation of the transit time, between the arrivals and the RTP timestamps
+			prev := l.arrived[i-1]
+			sent := time.Duration(int32(a.timestamp-prev.timestamp)) * time.Second / selfTestClockRate
+			d := a.at.Sub(prev.at) - sent
+			jitters = append(jitters, max(d, -d))
+		}
+	}
+	slices.Sort(latencies)
+	slices.Sort(jitters)
+	first, last := l.arrived[0].seq, highest
+	lost := int(last-first) + 1 - len(l.arrived)
+	concealed := lost + late
+	played := len(l.arrived) - late
+	lossRate := float64(concealed) / float64(concealed+played)
+
+	count := func(v float64) string { return fmt.Sprintf("%.0f", v) }
+	percent := func(v float64) string { return fmt.Sprintf("%.2f%%", v*100) }
+	ms := func(v float64) string { return fmt.Sprintf("%.1fms", v) }
+	// durations are within the histogram's 1/64 resolution, plus the time between a packet's
+	// arrival and its stats being recorded
+	durationCheck := func(metric string, expected time.Duration, reported float64) SelfTestCheck {
+		e := durationMs(expected)
+		return SelfTestCheck{Metric: metric, Expected: e, Reported: reported, Tolerance: e/32 + 0.1, format: ms}
+	}
+	results.Checks = []SelfTestCheck{
+		{Metric: "packets", Expected: float64(played), Reported: float64(reported.Packets), format: count},
+		{
+			Metric: "concealed packets", Expected: float64(concealed), Reported: float64(ts.concealedPackets.Load()),
+			format: count,
+		},
+		{Metric: "loss rate", Expected: lossRate, Reported: reported.LossRate, Tolerance: 1e-9, format: percent},
+		durationCheck("latency p50", percentile(latencies, 0.5), reported.LatencyP50Ms),
+		durationCheck("latency p90", percentile(latencies, 0.9), reported.LatencyP90Ms),
+		durationCheck("jitter p50", percentile(jitters, 0.5), reported.JitterP50Ms),
+		durationCheck("jitter p90", percentile(jitters, 0.9), reported.JitterP90Ms),
+	}
+	return results
+}
+
+func PrintSelfTestResults(params SelfTestParams, results *SelfTestResults) {
+	fmt.Printf("Self test over %s with %s\n", params.Duration, params.Impairment)
+	fmt.Printf("Sent %d packets, %d delivered\n\n", results.Sent, results.Delivered)
+	table := util.CreateTable().
+		Headers("Metric", "Expected", "Reported", "Result")
+	for _, c := range results.Checks {
+		result := "ok"
+		if !c.Passed() {
+			result = "FAIL"
+		}
+		table.Row(c.Metric, c.format(c.Expected), c.format(c.Reported), result)
+	}
+	fmt.Println(table)
+}
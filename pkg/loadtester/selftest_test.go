@@ -0,0 +1,64 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSelfTest(t *testing.T) {
+	params := SelfTestParams{Duration: DefaultSelfTestDuration, Impairment: Impairment{
+		PacketLoss: DefaultSelfTestPacketLoss,
+		Latency:    DefaultSelfTestLatency,
+		Jitter:     DefaultSelfTestJitter,
+	}}
+	require.NoError(t, ValidateSelfTest(params))
+
+	short := params
+	short.Duration = 100 * time.Millisecond
+	require.Error(t, ValidateSelfTest(short))
+
+	reordering := params
+	reordering.Impairment.Jitter = 25 * time.Millisecond
+	require.ErrorContains(t, ValidateSelfTest(reordering), "packet interval")
+
+	lossy := params
+	lossy.Impairment.PacketLoss = 1
+	require.Error(t, ValidateSelfTest(lossy))
+
+	capped := params
+	capped.Impairment.BandwidthCap = 1_000_000
+	require.Error(t, ValidateSelfTest(capped))
+}
+
+func TestRunSelfTest(t *testing.T) {
+	params := SelfTestParams{Duration: time.Second, Impairment: Impairment{
+		PacketLoss: 0.2,
+		Latency:    30 * time.Millisecond,
+		Jitter:     5 * time.Millisecond,
+	}}
+	results, err := RunSelfTest(context.Background(), params)
+	require.NoError(t, err)
+	require.Greater(t, results.Sent, 40)
+	require.Less(t, results.Delivered, results.Sent)
+	require.NotEmpty(t, results.Checks)
+	for _, c := range results.Checks {
+		require.True(t, c.Passed(), "%s: expected %s, reported %s", c.Metric, c.format(c.Expected), c.format(c.Reported))
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"go.uber.org/atomic"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+// upper bounds of the burst loss buckets, in packets. Longer bursts go into a final bucket.
+var burstBuckets = [...]int{1, 2, 3, 5, 10, 20, 50}
+
+// burstHistogram counts runs of consecutive missing packets by length
+type burstHistogram struct {
+	counts [len(burstBuckets) + 1]atomic.Int64
+}
+
+func (h *burstHistogram) record(length int) {
+	i := sort.SearchInts(burstBuckets[:], length)
+	h.counts[i].Inc()
+}
+
+func (h *burstHistogram) total() int64 {
+	var total int64
+	for i := range h.counts {
+		total += h.counts[i].Load()
+	}
+	return total
+}
+
+func (h *burstHistogram) reset() {
+	for i := range h.counts {
+		h.counts[i].Store(0)
+	}
+}
+
+func burstBucketLabels() []string {
+	labels := make([]string, 0, len(burstBuckets)+1)
+	lower := 1
+	for _, upper := range burstBuckets {
+		if upper == lower {
+			labels = append(labels, strconv.Itoa(upper))
+		} else {
+			labels = append(labels, fmt.Sprintf("%d-%d", lower, upper))
+		}
+		lower = upper + 1
+	}
+	return append(labels, fmt.Sprintf(">%d", burstBuckets[len(burstBuckets)-1]))
+}
+
+// sequenceTracker finds gaps in RTP sequence numbers as packets arrive. Late packets, including
+// retransmissions, are ignored, so gaps reflect loss on the network before any recovery.
+type sequenceTracker struct {
+	started bool
+	last    uint16
+}
+
+// next returns the number of packets missing between the previous packet and seq
+func (s *sequenceTracker) next(seq uint16) int {
+	if !s.started {
+		s.started = true
+		s.last = seq
+		return 0
+	}
+	diff := seq - s.last
+	if diff == 0 || diff >= 1<<15 {
+		return 0
+	}
+	s.last = seq
+	return int(diff - 1)
+}
+
+func printBurstLoss(stats map[string]*testerStats, names []string) {
+	headers := append([]string{"Tester", "Track", "Kind"}, burstBucketLabels()...)
+	table := util.CreateTable().Headers(headers...)
+	rows := 0
+	for _, name := range names {
+		trackIDs := make([]string, 0, len(stats[name].trackStats))
+		for trackID := range stats[name].trackStats {
+			trackIDs = append(trackIDs, trackID)
+		}
+		sort.Strings(trackIDs)
+
+		for _, trackID := range trackIDs {
+			ts := stats[name].trackStats[trackID]
+			if ts.burstLoss.total() == 0 {
+				continue
+			}
+			row := []string{name, ts.trackID, string(ts.kind)}
+			for i := range ts.burstLoss.counts {
+				row = append(row, strconv.FormatInt(ts.burstLoss.counts[i].Load(), 10))
+			}
+			table.Row(row...)
+			rows++
+		}
+	}
+	if rows > 0 {
+		fmt.Println("\nBurst loss (gaps by length in packets):")
+		fmt.Println(table)
+	}
+}
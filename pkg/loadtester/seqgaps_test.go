@@ -0,0 +1,45 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSequenceTracker(t *testing.T) {
+	s := &sequenceTracker{}
+	require.Equal(t, 0, s.next(65530))
+	require.Equal(t, 0, s.next(65531))
+	require.Equal(t, 2, s.next(65534))
+	// late packet
+	require.Equal(t, 0, s.next(65532))
+	// wraparound
+	require.Equal(t, 3, s.next(2))
+}
+
+func TestBurstHistogram(t *testing.T) {
+	h := &burstHistogram{}
+	for _, length := range []int{1, 1, 4, 5, 50, 51, 1000} {
+		h.record(length)
+	}
+	require.Equal(t, int64(2), h.counts[0].Load())
+	require.Equal(t, int64(2), h.counts[3].Load())
+	require.Equal(t, int64(1), h.counts[6].Load())
+	require.Equal(t, int64(2), h.counts[7].Load())
+	require.Equal(t, int64(7), h.total())
+	require.Equal(t, []string{"1", "2", "3", "4-5", "6-10", "11-20", "21-50", ">50"}, burstBucketLabels())
+}
@@ -0,0 +1,150 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+const (
+	serviceEgress  = "egress"
+	serviceIngress = "ingress"
+	serviceSIP     = "sip"
+
+	sipCallStatusAttribute = "sip.callStatus"
+)
+
+type serviceEvent struct {
+	at      time.Time
+	room    string
+	service string
+	id      string
+	state   string
+}
+
+// servicePoller periodically polls egress, ingress and SIP status for the test rooms,
+// recording every state transition it observes
+type servicePoller struct {
+	rooms    []string
+	interval time.Duration
+
+	egressClient  *lksdk.EgressClient
+	ingressClient *lksdk.IngressClient
+	roomClient    *lksdk.RoomServiceClient
+
+	lock   sync.Mutex
+	states map[string]string
+	events []*serviceEvent
+}
+
+func newServicePoller(params TesterParams, rooms []string, interval time.Duration) *servicePoller {
+	return &servicePoller{
+		rooms:         rooms,
+		interval:      interval,
+		egressClient:  lksdk.NewEgressClient(params.URL, params.APIKey, params.APISecret),
+		ingressClient: lksdk.NewIngressClient(params.URL, params.APIKey, params.APISecret),
+		roomClient:    lksdk.NewRoomServiceClient(params.URL, params.APIKey, params.APISecret),
+		states:        make(map[string]string),
+	}
+}
+
+func (p *servicePoller) run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		p.poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *servicePoller) poll(ctx context.Context) {
+	for _, room := range p.rooms {
+		if res, err := p.egressClient.ListEgress(ctx, &livekit.ListEgressRequest{RoomName: room}); err == nil {
+			for _, info := range res.Items {
+				p.record(room, serviceEgress, info.EgressId, info.Status.String())
+			}
+		}
+		if res, err := p.ingressClient.ListIngress(ctx, &livekit.ListIngressRequest{RoomName: room}); err == nil {
+			for _, info := range res.Items {
+				if info.State != nil {
+					p.record(room, serviceIngress, info.IngressId, info.State.Status.String())
+				}
+			}
+		}
+		if res, err := p.roomClient.ListParticipants(ctx, &livekit.ListParticipantsRequest{Room: room}); err == nil {
+			seen := make(map[string]bool)
+			for _, pi := range res.Participants {
+				if pi.Kind != livekit.ParticipantInfo_SIP {
+					continue
+				}
+				state := pi.State.String()
+				if callStatus := pi.Attributes[sipCallStatusAttribute]; callStatus != "" {
+					state = callStatus
+				}
+				seen[pi.Identity] = true
+				p.record(room, serviceSIP, pi.Identity, state)
+			}
+			p.markGone(room, serviceSIP, seen)
+		}
+	}
+}
+
+func (p *servicePoller) record(room, service, id, state string) {
+	key := service + "/" + id
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if p.states[key] == state {
+		return
+	}
+	p.states[key] = state
+	p.events = append(p.events, &serviceEvent{
+		at:      time.Now(),
+		room:    room,
+		service: service,
+		id:      id,
+		state:   state,
+	})
+}
+
+// markGone records a DISCONNECTED transition for services that are no longer listed in the room
+func (p *servicePoller) markGone(room, service string, seen map[string]bool) {
+	p.lock.Lock()
+	var gone []string
+	for _, e := range p.events {
+		if e.room == room && e.service == service && !seen[e.id] {
+			gone = append(gone, e.id)
+		}
+	}
+	p.lock.Unlock()
+
+	for _, id := range gone {
+		p.record(room, service, id, livekit.ParticipantInfo_DISCONNECTED.String())
+	}
+}
+
+func (p *servicePoller) timeline() []*serviceEvent {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return append([]*serviceEvent(nil), p.events...)
+}
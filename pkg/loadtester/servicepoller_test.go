@@ -0,0 +1,184 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+// fakeServices answers the list calls of the service poller with what each test sets
+type fakeServices struct {
+	livekit.Egress
+	livekit.Ingress
+	livekit.RoomService
+
+	lock         sync.Mutex
+	egress       map[string][]*livekit.EgressInfo
+	ingress      map[string][]*livekit.IngressInfo
+	participants map[string][]*livekit.ParticipantInfo
+	polls        int
+}
+
+func (f *fakeServices) ListEgress(_ context.Context, req *livekit.ListEgressRequest) (*livekit.ListEgressResponse, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return &livekit.ListEgressResponse{Items: f.egress[req.RoomName]}, nil
+}
+
+func (f *fakeServices) ListIngress(_ context.Context, req *livekit.ListIngressRequest) (*livekit.ListIngressResponse, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return &livekit.ListIngressResponse{Items: f.ingress[req.RoomName]}, nil
+}
+
+func (f *fakeServices) ListParticipants(_ context.Context, req *livekit.ListParticipantsRequest) (*livekit.ListParticipantsResponse, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.polls++
+	return &livekit.ListParticipantsResponse{Participants: f.participants[req.Room]}, nil
+}
+
+func (f *fakeServices) set(fn func()) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	fn()
+}
+
+func startFakeServices(t *testing.T) (*fakeServices, *servicePoller) {
+	f := &fakeServices{}
+	mux := http.NewServeMux()
+	for _, server := range []livekit.TwirpServer{
+		livekit.NewEgressServer(f),
+		livekit.NewIngressServer(f),
+		livekit.NewRoomServiceServer(f),
+	} {
+		mux.Handle(server.PathPrefix(), server)
+	}
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	params := TesterParams{URL: server.URL, APIKey: "key", APISecret: "secret"}
+	return f, newServicePoller(params, []string{"room_0", "room_1"}, time.Millisecond)
+}
+
+func sipParticipant(identity, callStatus string) *livekit.ParticipantInfo {
+	return &livekit.ParticipantInfo{
+		Identity:   identity,
+		Kind:       livekit.ParticipantInfo_SIP,
+		State:      livekit.ParticipantInfo_ACTIVE,
+		Attributes: map[string]string{sipCallStatusAttribute: callStatus},
+	}
+}
+
+func TestServicePollerPoll(t *testing.T) {
+	f, poller := startFakeServices(t)
+	ctx := context.Background()
+
+	f.set(func() {
+		f.egress = map[string][]*livekit.EgressInfo{
+			"room_0": {{EgressId: "EG_1", Status: livekit.EgressStatus_EGRESS_STARTING}},
+		}
+		f.ingress = map[string][]*livekit.IngressInfo{
+			"room_1": {
+				{IngressId: "IN_1", State: &livekit.IngressState{Status: livekit.IngressState_ENDPOINT_BUFFERING}},
+				// not started yet, without a state
+				{IngressId: "IN_2"},
+			},
+		}
+		f.participants = map[string][]*livekit.ParticipantInfo{
+			"room_0": {
+				{Identity: "tester", Kind: livekit.ParticipantInfo_STANDARD},
+				sipParticipant("sip_1", "dialing"),
+				{Identity: "sip_2", Kind: livekit.ParticipantInfo_SIP, State: livekit.ParticipantInfo_JOINING},
+			},
+		}
+	})
+	poller.poll(ctx)
+
+	// unchanged states are not recorded again
+	poller.poll(ctx)
+	f.set(func() {
+		f.egress["room_0"][0] = &livekit.EgressInfo{EgressId: "EG_1", Status: livekit.EgressStatus_EGRESS_ACTIVE}
+		f.participants["room_0"] = []*livekit.ParticipantInfo{sipParticipant("sip_1", "active")}
+	})
+	poller.poll(ctx)
+	// a participant that is gone is recorded as disconnected once
+	poller.poll(ctx)
+
+	var got [][4]string
+	for _, e := range poller.timeline() {
+		got = append(got, [4]string{e.room, e.service, e.id, e.state})
+	}
+	require.Equal(t, [][4]string{
+		{"room_0", serviceEgress, "EG_1", "EGRESS_STARTING"},
+		{"room_0", serviceSIP, "sip_1", "dialing"},
+		{"room_0", serviceSIP, "sip_2", "JOINING"},
+		{"room_1", serviceIngress, "IN_1", "ENDPOINT_BUFFERING"},
+		{"room_0", serviceEgress, "EG_1", "EGRESS_ACTIVE"},
+		{"room_0", serviceSIP, "sip_1", "active"},
+		{"room_0", serviceSIP, "sip_2", "DISCONNECTED"},
+	}, got)
+
+	events := poller.timeline()
+	for i := 1; i < len(events); i++ {
+		require.False(t, events[i].at.Before(events[i-1].at))
+	}
+	// the timeline is a copy
+	events[0] = nil
+	require.NotNil(t, poller.timeline()[0])
+}
+
+func TestServicePollerRun(t *testing.T) {
+	f, poller := startFakeServices(t)
+	f.set(func() {
+		f.participants = map[string][]*livekit.ParticipantInfo{"room_1": {sipParticipant("sip_1", "ringing")}}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		poller.run(ctx)
+		close(done)
+	}()
+	require.Eventually(t, func() bool {
+		f.lock.Lock()
+		defer f.lock.Unlock()
+		// two rooms are polled each time
+		return f.polls >= 6
+	}, 5*time.Second, time.Millisecond)
+	f.set(func() {
+		f.participants["room_1"] = []*livekit.ParticipantInfo{sipParticipant("sip_1", "active")}
+	})
+	require.Eventually(t, func() bool {
+		return len(poller.timeline()) == 2
+	}, 5*time.Second, time.Millisecond)
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("poller did not stop")
+	}
+	require.Equal(t, "active", poller.timeline()[1].state)
+}
@@ -0,0 +1,209 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/server-sdk-go/v2/signalling"
+)
+
+// messages read ahead of their delay, beyond this the relay stops reading
+const signalQueueSize = 256
+
+// signalTap relays a tester's signaling through a local websocket proxy, handing every message from
+// the server to onResponse. The SDK drops signaling it doesn't act on, like the subscribed quality
+// updates that drive dynacast. Messages in either direction can be changed by onRequest and onResponse,
+// returning true when they did, for options the SDK doesn't expose. The relayed signaling is delayed by impairment,
+// and connects to the server through proxy when set.
+type signalTap struct {
+	// websocket URL the tester joins instead of the server's
+	url    string
+	server *http.Server
+}
+
+func startSignalTap(
+	serverURL string,
+	impairment SignalImpairment,
+	proxy *url.URL,
+	onRequest func(*livekit.SignalRequest) bool,
+	onResponse func(*livekit.SignalResponse) bool,
+) (*signalTap, error) {
+	target, err := url.Parse(signalling.ToHttpURL(serverURL))
+	if err != nil {
+		return nil, err
+	}
+	wsTarget := strings.TrimSuffix(signalling.ToWebsocketURL(serverURL), "/")
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	// anything but the signal connection, like the SDK validating a failed join, is passed through
+	passthrough := &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(target)
+		},
+	}
+	dialer := *websocket.DefaultDialer
+	if proxy != nil {
+		passthrough.Transport = &http.Transport{Proxy: http.ProxyURL(proxy)}
+		dialer.Proxy = http.ProxyURL(proxy)
+	}
+	upgrader := websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !websocket.IsWebSocketUpgrade(r) {
+			passthrough.ServeHTTP(w, r)
+			return
+		}
+		// the handshake takes a round trip
+		time.Sleep(impairment.delay())
+		header := http.Header{}
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			header.Set("Authorization", auth)
+		}
+		upstream, resp, err := dialer.DialContext(r.Context(), wsTarget+r.URL.RequestURI(), header)
+		if err != nil {
+			// the SDK tells a refused join from an unreachable server by the status
+			status := http.StatusBadGateway
+			if resp != nil {
+				status = resp.StatusCode
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			_ = upstream.Close()
+			return
+		}
+		go relaySignal(upstream, conn, impairment, func(msg []byte) []byte {
+			req := &livekit.SignalRequest{}
+			if onRequest == nil || proto.Unmarshal(msg, req) != nil || !onRequest(req) {
+				return msg
+			}
+			return marshalChanged(req, msg)
+		})
+		relaySignal(conn, upstream, impairment, func(msg []byte) []byte {
+			res := &livekit.SignalResponse{}
+			if onResponse == nil || proto.Unmarshal(msg, res) != nil || !onResponse(res) {
+				return msg
+			}
+			return marshalChanged(res, msg)
+		})
+	})
+
+	t := &signalTap{
+		url:    "ws://" + listener.Addr().String(),
+		server: &http.Server{Handler: handler},
+	}
+	go func() {
+		_ = t.server.Serve(listener)
+	}()
+	return t, nil
+}
+
+// marshalChanged returns the changed message, or the original one if it can't be marshaled
+func marshalChanged(changed proto.Message, original []byte) []byte {
+	if msg, err := proto.Marshal(changed); err == nil {
+		return msg
+	}
+	return original
+}
+
+type signalMessage struct {
+	kind int
+	data []byte
+	due  time.Time
+}
+
+// relaySignal copies messages from src to dst until either side closes, passing the binary ones through relay,
+// and delaying them by impairment
+func relaySignal(dst, src *websocket.Conn, impairment SignalImpairment, relay func([]byte) []byte) {
+	messages := make(chan signalMessage, signalQueueSize)
+	done := make(chan struct{})
+	defer func() {
+		close(done)
+		_ = dst.Close()
+		_ = src.Close()
+	}()
+
+	go func() {
+		defer close(messages)
+		var last time.Time
+		for {
+			kind, msg, err := src.ReadMessage()
+			if err != nil {
+				return
+			}
+			if kind == websocket.BinaryMessage {
+				msg = relay(msg)
+			}
+			// a message can't overtake the one before it
+			due := time.Now().Add(impairment.delay())
+			if due.Before(last) {
+				due = last
+			}
+			last = due
+			select {
+			case messages <- signalMessage{kind: kind, data: msg, due: due}:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for m := range messages {
+		if wait := time.Until(m.due); wait > 0 {
+			time.Sleep(wait)
+		}
+		if err := dst.WriteMessage(m.kind, m.data); err != nil {
+			return
+		}
+	}
+}
+
+func (t *signalTap) close() {
+	_ = t.server.Close()
+}
+
+// needsSignalTap is true when the tester's signaling has to be relayed through a signalTap
+func (t *LoadTester) needsSignalTap() bool {
+	publishesVideo := t.params.Role == RoleVideoPublisher || t.params.Role == RoleAVPublisher
+	publishesAudio := t.params.Role == RoleAudioPublisher || t.params.Role == RoleAVPublisher
+	return (t.params.dynacastCheck != nil && publishesVideo) ||
+		(t.params.AudioDisableRED && publishesAudio) ||
+		(t.params.SubscribeBackupOnly && t.params.Subscribe) ||
+		t.params.SignalImpairment.enabled() ||
+		len(t.params.ICEServers) > 0 ||
+		t.params.Proxy != nil ||
+		t.errorLog != nil
+}
+
+// onSignalResponse follows the server's subscribed quality updates, and sets the tester's TURN servers
+func (t *LoadTester) onSignalResponse(res *livekit.SignalResponse) bool {
+	t.errorLog.signal("recv", res)
+	t.onQualityUpdate(res)
+	return t.replaceICEServers(res)
+}
@@ -0,0 +1,210 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+func TestSignalTap(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	received := make(chan *livekit.SignalRequest, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !websocket.IsWebSocketUpgrade(r) {
+			http.Error(w, "not a signal connection", http.StatusUnauthorized)
+			return
+		}
+		require.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		req := &livekit.SignalRequest{}
+		_ = proto.Unmarshal(msg, req)
+		received <- req
+		msg, _ = proto.Marshal(&livekit.SignalResponse{Message: &livekit.SignalResponse_Join{
+			Join: &livekit.JoinResponse{IceServers: []*livekit.ICEServer{{Urls: []string{"turn:cloud:3478"}}}},
+		}})
+		_ = conn.WriteMessage(websocket.BinaryMessage, msg)
+		msg, _ = proto.Marshal(&livekit.SignalResponse{Message: &livekit.SignalResponse_SubscribedQualityUpdate{
+			SubscribedQualityUpdate: &livekit.SubscribedQualityUpdate{TrackSid: "TR_video"},
+		}})
+		_ = conn.WriteMessage(websocket.BinaryMessage, msg)
+		_, _, _ = conn.ReadMessage()
+	}))
+	defer server.Close()
+
+	responses := make(chan *livekit.SignalResponse, 2)
+	turn := &livekit.ICEServer{Urls: []string{"turn:relay:3478"}, Username: "user", Credential: "secret"}
+	tester := &LoadTester{params: TesterParams{AudioDisableRED: true, ICEServers: []*livekit.ICEServer{turn}}}
+	tap, err := startSignalTap(server.URL, SignalImpairment{}, nil, tester.onSignalRequest, func(res *livekit.SignalResponse) bool {
+		responses <- res
+		return tester.replaceICEServers(res)
+	})
+	require.NoError(t, err)
+	defer tap.close()
+
+	// requests other than the signal connection pass through
+	resp, err := http.Get("http" + tap.url[len("ws"):] + "/rtc/validate")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	conn, _, err := websocket.DefaultDialer.Dial(tap.url+"/rtc", http.Header{"Authorization": {"Bearer token"}})
+	require.NoError(t, err)
+	defer conn.Close()
+	msg, err := proto.Marshal(&livekit.SignalRequest{Message: &livekit.SignalRequest_AddTrack{
+		AddTrack: &livekit.AddTrackRequest{Cid: "audio", Type: livekit.TrackType_AUDIO},
+	}})
+	require.NoError(t, err)
+	require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, msg))
+	// the SDK can't disable RED, the tap does
+	req := <-received
+	require.Equal(t, "audio", req.GetAddTrack().GetCid())
+	require.True(t, req.GetAddTrack().GetDisableRed())
+
+	// the tester's TURN server replaces the ones the server hands out
+	kind, msg, err := conn.ReadMessage()
+	require.NoError(t, err)
+	res := &livekit.SignalResponse{}
+	require.NoError(t, proto.Unmarshal(msg, res))
+	require.Len(t, res.GetJoin().GetIceServers(), 1)
+	require.True(t, proto.Equal(turn, res.GetJoin().GetIceServers()[0]))
+	<-responses
+
+	kind, msg, err = conn.ReadMessage()
+	require.NoError(t, err)
+	require.Equal(t, websocket.BinaryMessage, kind)
+	res = &livekit.SignalResponse{}
+	require.NoError(t, proto.Unmarshal(msg, res))
+	require.Equal(t, "TR_video", res.GetSubscribedQualityUpdate().GetTrackSid())
+	require.Equal(t, "TR_video", (<-responses).GetSubscribedQualityUpdate().GetTrackSid())
+}
+
+func TestSignalTapImpairment(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			kind, msg, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			_ = conn.WriteMessage(kind, msg)
+		}
+	}))
+	defer server.Close()
+
+	impairment := SignalImpairment{Latency: 20 * time.Millisecond, Jitter: 30 * time.Millisecond}
+	tap, err := startSignalTap(server.URL, impairment, nil, nil, nil)
+	require.NoError(t, err)
+	defer tap.close()
+
+	conn, _, err := websocket.DefaultDialer.Dial(tap.url+"/rtc", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, []byte{byte(i)}))
+	}
+	// jitter delays messages without reordering them
+	for i := 0; i < 10; i++ {
+		_, msg, err := conn.ReadMessage()
+		require.NoError(t, err)
+		require.Equal(t, []byte{byte(i)}, msg)
+	}
+	require.GreaterOrEqual(t, time.Since(start), 2*impairment.Latency)
+}
+
+func TestSignalTapProxy(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		kind, msg, err := conn.ReadMessage()
+		if err == nil {
+			_ = conn.WriteMessage(kind, msg)
+		}
+	}))
+	defer server.Close()
+
+	// an HTTP proxy tunneling CONNECT requests
+	tunneled := make(chan string, 1)
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodConnect {
+			http.Error(w, "only CONNECT", http.StatusMethodNotAllowed)
+			return
+		}
+		upstream, err := net.Dial("tcp", r.Host)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		tunneled <- r.Host
+		w.WriteHeader(http.StatusOK)
+		client, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			_ = upstream.Close()
+			return
+		}
+		go func() {
+			_, _ = io.Copy(upstream, client)
+			_ = upstream.Close()
+		}()
+		_, _ = io.Copy(client, upstream)
+		_ = client.Close()
+	}))
+	defer proxy.Close()
+
+	proxyURL, err := ParseProxy(proxy.URL)
+	require.NoError(t, err)
+	tap, err := startSignalTap(server.URL, SignalImpairment{}, proxyURL, nil, nil)
+	require.NoError(t, err)
+	defer tap.close()
+
+	conn, _, err := websocket.DefaultDialer.Dial(tap.url+"/rtc", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+	require.Equal(t, server.Listener.Addr().String(), <-tunneled)
+
+	require.NoError(t, conn.WriteMessage(websocket.BinaryMessage, []byte("join")))
+	_, msg, err := conn.ReadMessage()
+	require.NoError(t, err)
+	require.Equal(t, []byte("join"), msg)
+}
@@ -0,0 +1,123 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	livekitcli "github.com/livekit/livekit-cli/v2"
+)
+
+const (
+	SignatureAlgorithm = "HMAC-SHA256"
+	// appended to the path of a signed result file
+	SignatureExtension = ".sig"
+)
+
+// ResultSignature proves that a result file was written by lk load-test for a project, and has
+// not been modified since. It is stored next to the file, and can be checked by anyone with the
+// project's API secret.
+type ResultSignature struct {
+	Algorithm string    `json:"algorithm"`
+	KeyID     string    `json:"keyId"`
+	Tool      string    `json:"tool"`
+	SignedAt  time.Time `json:"signedAt"`
+	SHA256    string    `json:"sha256"`
+	Signature string    `json:"signature"`
+}
+
+// resultSigner signs result files with the secret of APIKey
+type resultSigner struct {
+	apiKey    string
+	apiSecret string
+}
+
+// sign writes the signature of the file at path to path + SignatureExtension
+func (s *resultSigner) sign(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256(data)
+	sig := &ResultSignature{
+		Algorithm: SignatureAlgorithm,
+		KeyID:     s.apiKey,
+		Tool:      "lk " + livekitcli.Version,
+		SignedAt:  time.Now().UTC(),
+		SHA256:    hex.EncodeToString(digest[:]),
+	}
+	sig.Signature = sig.compute(s.apiSecret)
+	out, err := json.MarshalIndent(sig, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path+SignatureExtension, append(out, '\n'), 0644)
+}
+
+// compute returns the HMAC of every field but the signature itself
+func (sig *ResultSignature) compute(secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.Join([]string{
+		sig.Algorithm,
+		sig.KeyID,
+		sig.Tool,
+		sig.SignedAt.Format(time.RFC3339Nano),
+		sig.SHA256,
+	}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyResultFile checks the result file at path against its signature, made with apiSecret
+func VerifyResultFile(path, apiSecret string) (*ResultSignature, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sigData, err := os.ReadFile(path + SignatureExtension)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%s is not signed", path)
+	} else if err != nil {
+		return nil, err
+	}
+	sig := &ResultSignature{}
+	if err = json.Unmarshal(sigData, sig); err != nil {
+		return nil, fmt.Errorf("invalid signature %s%s: %w", path, SignatureExtension, err)
+	}
+	if sig.Algorithm != SignatureAlgorithm {
+		return nil, fmt.Errorf("unsupported signature algorithm %q", sig.Algorithm)
+	}
+
+	expected, err := hex.DecodeString(sig.compute(apiSecret))
+	if err != nil {
+		return nil, err
+	}
+	actual, err := hex.DecodeString(sig.Signature)
+	if err != nil || !hmac.Equal(expected, actual) {
+		return nil, fmt.Errorf("signature of %s does not match, it was modified or signed with another key than %s", path, sig.KeyID)
+	}
+	digest := sha256.Sum256(data)
+	if hex.EncodeToString(digest[:]) != sig.SHA256 {
+		return nil, fmt.Errorf("%s was modified after it was signed", path)
+	}
+	return sig, nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignResults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.json")
+	params := Params{StatsOutput: StatsOutputJSON, StatsFile: path, SignResults: true}
+	params.APIKey, params.APISecret = "APIkey", "secret"
+	results := &Results{Total: &TesterResults{Name: "Total", Packets: 100}}
+	require.NoError(t, writeStats(context.Background(), statsSinks(params)[1:], results))
+
+	sig, err := VerifyResultFile(path, "secret")
+	require.NoError(t, err)
+	require.Equal(t, "APIkey", sig.KeyID)
+	require.Equal(t, SignatureAlgorithm, sig.Algorithm)
+
+	_, err = VerifyResultFile(path, "other secret")
+	require.ErrorContains(t, err, "does not match")
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, append(data, ' '), 0644))
+	_, err = VerifyResultFile(path, "secret")
+	require.ErrorContains(t, err, "modified")
+
+	require.NoError(t, os.Remove(path+SignatureExtension))
+	_, err = VerifyResultFile(path, "secret")
+	require.ErrorContains(t, err, "not signed")
+
+	// results printed to stdout can't be signed
+	params.StatsFile = ""
+	require.Error(t, writeStats(context.Background(), statsSinks(params)[1:], results))
+}
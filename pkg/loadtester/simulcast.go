@@ -0,0 +1,85 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/livekit/livekit-cli/v2/pkg/provider"
+)
+
+const maxSimulcastLayers = 3
+
+// ParseSimulcastLadder builds the simulcast ladder of layers, from comma separated scale down factors
+// and max bitrates, lowest quality first. Each layer is scaled down by half from the next one when
+// scales aren't given. It returns nil when nothing is set, to keep the default ladder.
+func ParseSimulcastLadder(layers int, scales, bitrates string) ([]provider.SimulcastLayer, error) {
+	scaleList := splitList(scales)
+	bitrateList := splitList(bitrates)
+	if layers == 0 {
+		layers = max(len(scaleList), len(bitrateList))
+		if layers == 0 {
+			return nil, nil
+		}
+	}
+	if layers < 1 || layers > maxSimulcastLayers {
+		return nil, fmt.Errorf("simulcast layers must be between 1 and %d", maxSimulcastLayers)
+	}
+	if len(scaleList) > 0 && len(scaleList) != layers {
+		return nil, fmt.Errorf("expected %d simulcast scales, got %d", layers, len(scaleList))
+	}
+	if len(bitrateList) > 0 && len(bitrateList) != layers {
+		return nil, fmt.Errorf("expected %d simulcast bitrates, got %d", layers, len(bitrateList))
+	}
+
+	ladder := make([]provider.SimulcastLayer, layers)
+	for i := range ladder {
+		ladder[i].ScaleDown = math.Pow(2, float64(layers-1-i))
+		if len(scaleList) > 0 {
+			scale, err := strconv.ParseFloat(scaleList[i], 64)
+			if err != nil || scale < 1 {
+				return nil, fmt.Errorf("invalid simulcast scale %q, must be at least 1", scaleList[i])
+			}
+			if i > 0 && scale >= ladder[i-1].ScaleDown {
+				return nil, fmt.Errorf("simulcast scales must decrease from the lowest layer to the highest")
+			}
+			ladder[i].ScaleDown = scale
+		}
+		if len(bitrateList) > 0 {
+			bitrate, err := ParseBitrate(bitrateList[i])
+			if err != nil {
+				return nil, err
+			}
+			if bitrate < 1000 {
+				return nil, fmt.Errorf("simulcast bitrate %q is below 1kbps", bitrateList[i])
+			}
+			ladder[i].MaxKbps = int(bitrate / 1000)
+		}
+	}
+	return ladder, nil
+}
+
+func splitList(str string) []string {
+	var list []string
+	for _, item := range strings.Split(str, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,58 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/livekit-cli/v2/pkg/provider"
+)
+
+func TestParseSimulcastLadder(t *testing.T) {
+	ladder, err := ParseSimulcastLadder(0, "", "")
+	require.NoError(t, err)
+	require.Nil(t, ladder)
+
+	ladder, err = ParseSimulcastLadder(3, "", "")
+	require.NoError(t, err)
+	require.Equal(t, []provider.SimulcastLayer{{ScaleDown: 4}, {ScaleDown: 2}, {ScaleDown: 1}}, ladder)
+
+	ladder, err = ParseSimulcastLadder(0, "4, 1", "150kbps,1.5mbps")
+	require.NoError(t, err)
+	require.Equal(t, []provider.SimulcastLayer{{ScaleDown: 4, MaxKbps: 150}, {ScaleDown: 1, MaxKbps: 1500}}, ladder)
+
+	ladder, err = ParseSimulcastLadder(0, "", "100kbps,300kbps")
+	require.NoError(t, err)
+	require.Equal(t, []provider.SimulcastLayer{{ScaleDown: 2, MaxKbps: 100}, {ScaleDown: 1, MaxKbps: 300}}, ladder)
+
+	for _, invalid := range []struct {
+		layers           int
+		scales, bitrates string
+	}{
+		{layers: 4},
+		{layers: 2, scales: "4,2,1"},
+		{layers: 2, bitrates: "1mbps"},
+		{scales: "1,2"},
+		{scales: "0.5"},
+		{scales: "x"},
+		{bitrates: "500bps"},
+		{bitrates: "fast"},
+	} {
+		_, err = ParseSimulcastLadder(invalid.layers, invalid.scales, invalid.bitrates)
+		require.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,277 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	metricsv1 "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonv1 "go.opentelemetry.io/proto/otlp/common/v1"
+	otlpmetrics "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	StatsSinkConsole    = "console"
+	StatsSinkPrometheus = "prometheus"
+	StatsSinkOTLP       = "otlp"
+
+	defaultPrometheusJob = "lk-load-test"
+)
+
+// StatsSink receives the final results of a load test. Any number of sinks can be active at once;
+// each one is written to after the test finishes, whether it completed or was interrupted.
+type StatsSink interface {
+	WriteStats(ctx context.Context, results *Results) error
+}
+
+// ConsoleSink prints the results as tables
+type ConsoleSink struct{}
+
+func (ConsoleSink) WriteStats(_ context.Context, results *Results) error {
+	if results.printDetails != nil {
+		results.printDetails()
+	} else {
+		printResults(results)
+	}
+	return nil
+}
+
+// FileSink writes the results as StatsOutputJSON or StatsOutputCSV, to Path or to stdout when empty
+type FileSink struct {
+	Format string
+	Path   string
+
+	// signs the file after writing it, with --sign-results
+	signer *resultSigner
+}
+
+func (s *FileSink) WriteStats(_ context.Context, results *Results) error {
+	if s.signer != nil && s.Path == "" {
+		return fmt.Errorf("%s results written to stdout cannot be signed, write them to a file", s.Format)
+	}
+	if err := writeResults(s.Format, s.Path, results); err != nil {
+		return err
+	}
+	if s.signer != nil {
+		return s.signer.sign(s.Path)
+	}
+	return nil
+}
+
+// PrometheusSink pushes the results to a Prometheus Pushgateway
+type PrometheusSink struct {
+	PushURL string
+	Job     string
+}
+
+func (s *PrometheusSink) WriteStats(ctx context.Context, results *Results) error {
+	job := s.Job
+	if job == "" {
+		job = defaultPrometheusJob
+	}
+	buf := &bytes.Buffer{}
+	writeResultMetrics(buf, results)
+	u := strings.TrimSuffix(s.PushURL, "/") + "/metrics/job/" + url.PathEscape(job)
+	return postStats(ctx, http.MethodPut, u, "text/plain; version=0.0.4", buf.Bytes(), nil)
+}
+
+// OTLPSink exports the results as gauges to an OpenTelemetry collector over OTLP/HTTP
+type OTLPSink struct {
+	Endpoint string
+}
+
+func (s *OTLPSink) WriteStats(ctx context.Context, results *Results) error {
+	body, err := proto.Marshal(otlpRequest(results, time.Now()))
+	if err != nil {
+		return err
+	}
+	u := strings.TrimSuffix(s.Endpoint, "/") + "/v1/metrics"
+	return postStats(ctx, http.MethodPost, u, "application/x-protobuf", body, nil)
+}
+
+// ParseStatsSink parses a --stats-sink value: console, json[=path], csv[=path],
+// prometheus=<pushgateway url> or otlp=<collector url>
+func ParseStatsSink(spec string) (StatsSink, error) {
+	kind, arg, _ := strings.Cut(strings.TrimSpace(spec), "=")
+	switch kind {
+	case StatsSinkConsole:
+		return ConsoleSink{}, nil
+	case StatsOutputJSON, StatsOutputCSV:
+		return &FileSink{Format: kind, Path: arg}, nil
+	case StatsSinkPrometheus:
+		if arg == "" {
+			return nil, fmt.Errorf("stats sink %q requires a pushgateway url", kind)
+		}
+		return &PrometheusSink{PushURL: arg}, nil
+	case StatsSinkOTLP:
+		if arg == "" {
+			return nil, fmt.Errorf("stats sink %q requires a collector url", kind)
+		}
+		return &OTLPSink{Endpoint: arg}, nil
+	}
+	return nil, fmt.Errorf("unsupported stats sink %q, expected one of %s, %s, %s, %s or %s",
+		spec, StatsSinkConsole, StatsOutputJSON, StatsOutputCSV, StatsSinkPrometheus, StatsSinkOTLP)
+}
+
+// statsSinks returns the sinks results are written to. Without any configured sinks, results are
+// printed to the console; --stats-output is kept as a file sink, the results are posted to --report-url,
+// and every run is added to the history.
+// With SignResults, result files are signed with the project's API secret.
+func statsSinks(params Params) []StatsSink {
+	sinks := append([]StatsSink(nil), params.StatsSinks...)
+	if len(sinks) == 0 {
+		sinks = append(sinks, ConsoleSink{})
+	}
+	if params.StatsOutput != "" {
+		sinks = append(sinks, &FileSink{Format: params.StatsOutput, Path: params.StatsFile})
+	}
+	if params.Report != nil {
+		sinks = append(sinks, params.Report)
+	}
+	if params.HistoryDir != "" {
+		description := params.participants()
+		if params.RoomCount > 1 {
+			description = fmt.Sprintf("%d rooms with %s", params.RoomCount, description)
+		}
+		sinks = append(sinks, &HistorySink{History: &History{Dir: params.HistoryDir}, Description: description})
+	}
+	if params.SignResults {
+		signer := &resultSigner{apiKey: params.APIKey, apiSecret: params.APISecret}
+		for i, sink := range sinks {
+			if fileSink, ok := sink.(*FileSink); ok {
+				signed := *fileSink
+				signed.signer = signer
+				sinks[i] = &signed
+			}
+		}
+	}
+	return sinks
+}
+
+// writeStats writes results to every sink, continuing past failures
+func writeStats(ctx context.Context, sinks []StatsSink, results *Results) error {
+	var errs []error
+	for _, sink := range sinks {
+		if err := sink.WriteStats(ctx, results); err != nil {
+			errs = append(errs, fmt.Errorf("%T: %w", sink, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func postStats(ctx context.Context, method, u, contentType string, body []byte, header http.Header) error {
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", contentType)
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(res.Body, 512))
+		return fmt.Errorf("%s %s: %s %s", method, u, res.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+type resultMetric struct {
+	name  string
+	help  string
+	value func(*TesterResults) float64
+}
+
+var resultMetrics = []resultMetric{
+	{"tracks", "Tracks subscribed.", func(r *TesterResults) float64 { return float64(r.Tracks) }},
+	{"expected_tracks", "Tracks expected to be subscribed.", func(r *TesterResults) float64 { return float64(r.ExpectedTracks) }},
+	{"packets", "Media packets received.", func(r *TesterResults) float64 { return float64(r.Packets) }},
+	{"bytes", "Media bytes received.", func(r *TesterResults) float64 { return float64(r.Bytes) }},
+	{"dropped_packets", "Media packets never received.", func(r *TesterResults) float64 { return float64(r.Dropped) }},
+	{"bitrate_bps", "Average received bitrate.", func(r *TesterResults) float64 { return r.Bitrate }},
+	{"loss_rate", "Fraction of packets lost.", func(r *TesterResults) float64 { return r.LossRate }},
+	{"first_frame_ms", "Average time from publish to first frame.", func(r *TesterResults) float64 { return r.AvgFirstFrameMs }},
+	{"latency_p50_ms", "Median end-to-end latency.", func(r *TesterResults) float64 { return r.LatencyP50Ms }},
+	{"latency_p95_ms", "95th percentile end-to-end latency.", func(r *TesterResults) float64 { return r.LatencyP95Ms }},
+	{"latency_p99_ms", "99th percentile end-to-end latency.", func(r *TesterResults) float64 { return r.LatencyP99Ms }},
+	{"latency_p90_ms", "90th percentile end-to-end latency.", func(r *TesterResults) float64 { return r.LatencyP90Ms }},
+	{"latency_max_ms", "Highest end-to-end latency.", func(r *TesterResults) float64 { return r.LatencyMaxMs }},
+	{"jitter_p50_ms", "Median transit time variation of received packets.", func(r *TesterResults) float64 { return r.JitterP50Ms }},
+	{"jitter_p90_ms", "90th percentile transit time variation of received packets.", func(r *TesterResults) float64 { return r.JitterP90Ms }},
+	{"jitter_p99_ms", "99th percentile transit time variation of received packets.", func(r *TesterResults) float64 { return r.JitterP99Ms }},
+	{"jitter_max_ms", "Highest transit time variation of received packets.", func(r *TesterResults) float64 { return r.JitterMaxMs }},
+	{"errors", "Errors encountered.", func(r *TesterResults) float64 { return float64(r.Errors) }},
+}
+
+func resultMetricName(m resultMetric) string {
+	return "lk_loadtest_result_" + m.name
+}
+
+// writeResultMetrics writes the results in the Prometheus text exposition format, one sample per tester
+func writeResultMetrics(w io.Writer, results *Results) {
+	testers := append(results.Testers, results.Total)
+	for _, m := range resultMetrics {
+		name := resultMetricName(m)
+		writeHeader(w, name, "gauge", m.help)
+		for _, tester := range testers {
+			writeSample(w, name, fmt.Sprintf(`{tester=%q}`, tester.Name), m.value(tester))
+		}
+	}
+}
+
+func otlpRequest(results *Results, now time.Time) *metricsv1.ExportMetricsServiceRequest {
+	ts := uint64(now.UnixNano())
+	testers := append(results.Testers, results.Total)
+	metrics := make([]*otlpmetrics.Metric, 0, len(resultMetrics))
+	for _, m := range resultMetrics {
+		points := make([]*otlpmetrics.NumberDataPoint, 0, len(testers))
+		for _, tester := range testers {
+			points = append(points, &otlpmetrics.NumberDataPoint{
+				TimeUnixNano: ts,
+				Attributes: []*commonv1.KeyValue{{
+					Key:   "tester",
+					Value: &commonv1.AnyValue{Value: &commonv1.AnyValue_StringValue{StringValue: tester.Name}},
+				}},
+				Value: &otlpmetrics.NumberDataPoint_AsDouble{AsDouble: m.value(tester)},
+			})
+		}
+		metrics = append(metrics, &otlpmetrics.Metric{
+			Name:        resultMetricName(m),
+			Description: m.help,
+			Data:        &otlpmetrics.Metric_Gauge{Gauge: &otlpmetrics.Gauge{DataPoints: points}},
+		})
+	}
+	return &metricsv1.ExportMetricsServiceRequest{
+		ResourceMetrics: []*otlpmetrics.ResourceMetrics{{
+			ScopeMetrics: []*otlpmetrics.ScopeMetrics{{
+				Scope:   &commonv1.InstrumentationScope{Name: "lk load-test"},
+				Metrics: metrics,
+			}},
+		}},
+	}
+}
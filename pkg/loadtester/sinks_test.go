@@ -0,0 +1,118 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metricsv1 "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestParseStatsSink(t *testing.T) {
+	sink, err := ParseStatsSink("console")
+	require.NoError(t, err)
+	require.Equal(t, ConsoleSink{}, sink)
+
+	sink, err = ParseStatsSink("csv=out.csv")
+	require.NoError(t, err)
+	require.Equal(t, &FileSink{Format: StatsOutputCSV, Path: "out.csv"}, sink)
+
+	sink, err = ParseStatsSink("prometheus=http://pushgateway:9091")
+	require.NoError(t, err)
+	require.Equal(t, &PrometheusSink{PushURL: "http://pushgateway:9091"}, sink)
+
+	_, err = ParseStatsSink("otlp")
+	require.Error(t, err)
+	_, err = ParseStatsSink("statsd=localhost")
+	require.Error(t, err)
+}
+
+func TestStatsSinks(t *testing.T) {
+	sinks := statsSinks(Params{StatsOutput: StatsOutputJSON})
+	require.Equal(t, []StatsSink{ConsoleSink{}, &FileSink{Format: StatsOutputJSON}}, sinks)
+
+	custom := &PrometheusSink{PushURL: "http://localhost"}
+	require.Equal(t, []StatsSink{custom}, statsSinks(Params{StatsSinks: []StatsSink{custom}}))
+}
+
+type failingSink struct{}
+
+func (failingSink) WriteStats(context.Context, *Results) error {
+	return errors.New("failed")
+}
+
+type countingSink struct{ writes int }
+
+func (s *countingSink) WriteStats(context.Context, *Results) error {
+	s.writes++
+	return nil
+}
+
+func TestWriteStatsContinuesPastFailures(t *testing.T) {
+	counter := &countingSink{}
+	err := writeStats(context.Background(), []StatsSink{failingSink{}, counter}, testResults())
+	require.Error(t, err)
+	require.Equal(t, 1, counter.writes)
+}
+
+func TestPrometheusSink(t *testing.T) {
+	var path, body string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPut, r.Method)
+		path = r.URL.Path
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	}))
+	defer srv.Close()
+
+	sink := &PrometheusSink{PushURL: srv.URL, Job: "nightly"}
+	require.NoError(t, sink.WriteStats(context.Background(), testResults()))
+	require.Equal(t, "/metrics/job/nightly", path)
+	require.Contains(t, body, "# TYPE lk_loadtest_result_loss_rate gauge\n")
+	require.Contains(t, body, `lk_loadtest_result_tracks{tester="Sub 0"} 2`)
+	require.Contains(t, body, `lk_loadtest_result_tracks{tester="Total"} 2`)
+}
+
+func TestOTLPSink(t *testing.T) {
+	req := &metricsv1.ExportMetricsServiceRequest{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/metrics", r.URL.Path)
+		b, _ := io.ReadAll(r.Body)
+		require.NoError(t, proto.Unmarshal(b, req))
+	}))
+	defer srv.Close()
+
+	sink := &OTLPSink{Endpoint: srv.URL}
+	require.NoError(t, sink.WriteStats(context.Background(), testResults()))
+	metrics := req.ResourceMetrics[0].ScopeMetrics[0].Metrics
+	require.Len(t, metrics, len(resultMetrics))
+	require.Equal(t, "lk_loadtest_result_tracks", metrics[0].Name)
+	require.Len(t, metrics[0].GetGauge().DataPoints, 2)
+}
+
+func testResults() *Results {
+	tester := &TesterResults{Name: "Sub 0", Tracks: 2, ExpectedTracks: 2, Packets: 100}
+	return &Results{
+		Testers: []*TesterResults{tester},
+		Total:   &TesterResults{Name: "Total", Tracks: 2, ExpectedTracks: 2, Packets: 100},
+	}
+}
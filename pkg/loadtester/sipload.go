@@ -0,0 +1,375 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/livekit/protocol/livekit"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+// replaced with the index of the call in SIPLoadParams.CallTo and DTMF
+const sipCallIndexPattern = "{n}"
+
+type SIPLoadParams struct {
+	URL       string
+	APIKey    string
+	APISecret string
+	// outbound trunk the calls are placed through
+	TrunkID string
+	// number dialed by every call, {n} is replaced with the index of the call
+	CallTo string
+	// caller number, the trunk's default when empty
+	Number string
+	Calls  int
+	// calls placed per second
+	CallsPerSecond float64
+	// how long each call stays up after it is answered
+	Hold time.Duration
+	// how long a call may ring before it counts as failed
+	RingingTimeout time.Duration
+	// digits sent when the call is answered, {n} is replaced with the index of the call
+	// and 'w' pauses for half a second
+	DTMF string
+	// resend DTMF from the room at this interval while the call is up, 0 to disable
+	DTMFInterval time.Duration
+	// join a subscriber to each call's room to measure the audio coming out of the bridge
+	Monitor bool
+	// rooms are named RoomPrefix-{n}
+	RoomPrefix string
+	// fraction of the calls that may fail before the test does, it fails when all calls do regardless
+	MaxFailureRate float64
+}
+
+// SIPLoadTest places outbound calls through the SIP service, each into its own room, to load
+// test the telephony bridge instead of WebRTC joins
+type SIPLoadTest struct {
+	params SIPLoadParams
+}
+
+type sipCall struct {
+	index    int
+	room     string
+	identity string
+	// from the request until the call was answered
+	setup  time.Duration
+	status string
+	err    error
+	// DTMF sent from the room during the call
+	dtmfSent   int
+	dtmfErrors int
+	hangupErr  error
+	tracks     int
+	bytes      int64
+	packets    int64
+	dropped    int64
+	elapsed    time.Duration
+}
+
+func NewSIPLoadTest(params SIPLoadParams) *SIPLoadTest {
+	if params.CallsPerSecond == 0 {
+		params.CallsPerSecond = 1
+	}
+	if params.RingingTimeout == 0 {
+		params.RingingTimeout = 30 * time.Second
+	}
+	if params.RoomPrefix == "" {
+		params.RoomPrefix = "siptest-" + randStringRunes(5)
+	}
+	return &SIPLoadTest{params: params}
+}
+
+// ValidateSIPLoad checks the parameters of a SIP load test
+func ValidateSIPLoad(params SIPLoadParams) error {
+	if params.TrunkID == "" {
+		return errors.New("SIP load test needs an outbound trunk")
+	}
+	if params.CallTo == "" {
+		return errors.New("SIP load test needs a number to call")
+	}
+	if params.Calls <= 0 {
+		return errors.New("SIP load test needs at least one call")
+	}
+	if params.DTMFInterval > 0 && !params.Monitor {
+		return errors.New("sending DTMF during calls needs a monitor in each room")
+	}
+	if strings.Trim(strings.ReplaceAll(params.DTMF, sipCallIndexPattern, ""), "0123456789*#wABCD") != "" {
+		return fmt.Errorf("invalid DTMF %q", params.DTMF)
+	}
+	if params.MaxFailureRate < 0 || params.MaxFailureRate > 1 {
+		return fmt.Errorf("max failure rate must be between 0 and 1, got %v", params.MaxFailureRate)
+	}
+	return nil
+}
+
+// expandCallIndex replaces {n} in pattern with the index of the call
+func expandCallIndex(pattern string, index int) string {
+	return strings.ReplaceAll(pattern, sipCallIndexPattern, strconv.Itoa(index))
+}
+
+func (s *SIPLoadTest) Run(ctx context.Context) error {
+	p := s.params
+	if err := ValidateSIPLoad(p); err != nil {
+		return err
+	}
+	fmt.Printf("Placing %d calls through trunk %s, %s per second\n",
+		p.Calls, p.TrunkID, strconv.FormatFloat(p.CallsPerSecond, 'f', -1, 64))
+
+	sipClient := lksdk.NewSIPClient(p.URL, p.APIKey, p.APISecret)
+	roomClient := lksdk.NewRoomServiceClient(p.URL, p.APIKey, p.APISecret)
+	limiter := rate.NewLimiter(rate.Limit(p.CallsPerSecond), 1)
+
+	calls := make([]*sipCall, 0, p.Calls)
+	var wg sync.WaitGroup
+	for i := 0; i < p.Calls; i++ {
+		if limiter.Wait(ctx) != nil {
+			break
+		}
+		call := &sipCall{
+			index:    i,
+			room:     fmt.Sprintf("%s-%d", p.RoomPrefix, i),
+			identity: fmt.Sprintf("sip-%d", i),
+		}
+		calls = append(calls, call)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.place(ctx, sipClient, roomClient, call)
+		}()
+	}
+	wg.Wait()
+
+	printSIPCalls(calls, p.Monitor)
+	if len(calls) == 0 {
+		return ctx.Err()
+	}
+	return checkSIPFailures(calls, p.MaxFailureRate)
+}
+
+// checkSIPFailures fails the test when every call failed or more than maxRate of them did
+func checkSIPFailures(calls []*sipCall, maxRate float64) error {
+	var failed int
+	var firstErr error
+	for _, c := range calls {
+		if c.err != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = c.err
+			}
+		}
+	}
+	switch {
+	case failed == 0:
+		return nil
+	case failed == len(calls):
+		return fmt.Errorf("all %d calls failed: %w", failed, firstErr)
+	case float64(failed) > maxRate*float64(len(calls)):
+		return fmt.Errorf("%d of %d calls failed, more than the %s allowed: %w",
+			failed, len(calls), strconv.FormatFloat(maxRate*100, 'f', -1, 64)+"%", firstErr)
+	}
+	return nil
+}
+
+// place makes one call, keeps it up for Hold and hangs up
+func (s *SIPLoadTest) place(ctx context.Context, sipClient *lksdk.SIPClient, roomClient *lksdk.RoomServiceClient, call *sipCall) {
+	p := s.params
+	var monitor *LoadTester
+	if p.Monitor {
+		monitor = NewLoadTester(TesterParams{
+			URL:            p.URL,
+			APIKey:         p.APIKey,
+			APISecret:      p.APISecret,
+			Room:           call.room,
+			IdentityPrefix: "monitor",
+			Sequence:       call.index,
+			Subscribe:      true,
+			SubscribeAll:   true,
+			name:           fmt.Sprintf("Call %d", call.index),
+		})
+		if err := monitor.Start(); err != nil {
+			call.err = fmt.Errorf("monitor could not join: %w", err)
+			return
+		}
+		defer monitor.Stop()
+	}
+
+	req := &livekit.CreateSIPParticipantRequest{
+		SipTrunkId:          p.TrunkID,
+		SipCallTo:           expandCallIndex(p.CallTo, call.index),
+		SipNumber:           p.Number,
+		RoomName:            call.room,
+		ParticipantIdentity: call.identity,
+		Dtmf:                expandCallIndex(p.DTMF, call.index),
+		WaitUntilAnswered:   true,
+		RingingTimeout:      durationpb.New(p.RingingTimeout),
+		// the bridge hangs up on its own should the test stop before it could
+		MaxCallDuration: durationpb.New(p.Hold + time.Minute),
+	}
+	// the request returns once the call is answered, or fails to be
+	createCtx, cancel := context.WithTimeout(ctx, p.RingingTimeout+10*time.Second)
+	startedAt := time.Now()
+	_, err := sipClient.CreateSIPParticipant(createCtx, req)
+	cancel()
+	if err != nil {
+		call.err = err
+		call.status = sipStatus(err)
+		return
+	}
+	call.setup = time.Since(startedAt)
+	call.status = "200 OK"
+
+	answeredAt := time.Now()
+	if monitor != nil && p.DTMFInterval > 0 {
+		ticker := time.NewTicker(p.DTMFInterval)
+		hangup := time.After(p.Hold)
+	hold:
+		for {
+			select {
+			case <-ctx.Done():
+				break hold
+			case <-hangup:
+				break hold
+			case <-ticker.C:
+				call.sendDTMF(monitor, expandCallIndex(p.DTMF, call.index))
+			}
+		}
+		ticker.Stop()
+	} else {
+		sleepUntil(ctx, answeredAt.Add(p.Hold))
+	}
+
+	if monitor != nil {
+		call.elapsed = time.Since(answeredAt)
+		for _, ts := range monitor.getStats().trackStats {
+			call.tracks++
+			call.bytes += ts.bytes.Load()
+			call.packets += ts.packets.Load()
+			call.dropped += ts.dropped.Load()
+		}
+	}
+
+	hangupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+	_, call.hangupErr = roomClient.RemoveParticipant(hangupCtx, &livekit.RoomParticipantIdentity{
+		Room:     call.room,
+		Identity: call.identity,
+	})
+	_, _ = roomClient.DeleteRoom(hangupCtx, &livekit.DeleteRoomRequest{Room: call.room})
+}
+
+// sendDTMF sends digits into the call as SIP DTMF data packets, skipping pauses
+func (c *sipCall) sendDTMF(monitor *LoadTester, digits string) {
+	for _, d := range digits {
+		if d == 'w' {
+			continue
+		}
+		err := monitor.room.LocalParticipant.PublishDataPacket(
+			&livekit.SipDTMF{Digit: string(d)}, lksdk.WithDataPublishReliable(true))
+		if err != nil {
+			c.dtmfErrors++
+		} else {
+			c.dtmfSent++
+		}
+	}
+}
+
+// sipStatus describes how a call failed, by SIP status when the bridge reported one
+func sipStatus(err error) string {
+	if e := lksdk.SIPStatusFrom(err); e != nil {
+		msg := e.Status
+		if msg == "" {
+			msg = e.Code.ShortName()
+		}
+		return fmt.Sprintf("%d %s", int(e.Code), msg)
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return "timeout"
+	}
+	return "error"
+}
+
+func printSIPCalls(calls []*sipCall, monitor bool) {
+	if len(calls) == 0 {
+		return
+	}
+
+	byStatus := make(map[string]int)
+	var setups []time.Duration
+	var hangupErrors, dtmfSent, dtmfErrors int
+	for _, c := range calls {
+		byStatus[c.status]++
+		if c.err == nil {
+			setups = append(setups, c.setup)
+		}
+		if c.hangupErr != nil {
+			hangupErrors++
+		}
+		dtmfSent += c.dtmfSent
+		dtmfErrors += c.dtmfErrors
+	}
+
+	statuses := make([]string, 0, len(byStatus))
+	for status := range byStatus {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	statusTable := util.CreateTable().
+		Headers("SIP Status", "Calls")
+	for _, status := range statuses {
+		statusTable.Row(status, strconv.Itoa(byStatus[status]))
+	}
+	fmt.Println("\nSIP calls:")
+	fmt.Println(statusTable)
+
+	p := getLatencyPercentiles(setups)
+	fmt.Printf("%d/%d answered, setup p50 %s, p95 %s, p99 %s, %d hang-up errors\n",
+		len(setups), len(calls), p.p50.Round(time.Millisecond), p.p95.Round(time.Millisecond),
+		p.p99.Round(time.Millisecond), hangupErrors)
+	if dtmfSent+dtmfErrors > 0 {
+		fmt.Printf("DTMF sent during calls: %d, failed: %d\n", dtmfSent, dtmfErrors)
+	}
+
+	if !monitor {
+		return
+	}
+	audioTable := util.CreateTable().
+		Headers("Call", "Room", "Tracks", "Bitrate", "Pkt. Loss", "Error")
+	for _, c := range calls {
+		bitrate, errString := "-", ""
+		if c.elapsed > 0 {
+			bitrate = formatBitrate(c.bytes, c.elapsed)
+		}
+		if c.err != nil {
+			errString = c.err.Error()
+		}
+		audioTable.Row(strconv.Itoa(c.index), c.room, strconv.Itoa(c.tracks),
+			bitrate, formatLossRate(c.packets, c.dropped), errString)
+	}
+	fmt.Println("\nAudio from the bridge:")
+	fmt.Println(audioTable)
+}
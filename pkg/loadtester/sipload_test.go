@@ -0,0 +1,84 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+func TestValidateSIPLoad(t *testing.T) {
+	params := SIPLoadParams{TrunkID: "ST_trunk", CallTo: "+1555000{n}", Calls: 5, DTMF: "ww{n}#"}
+	require.NoError(t, ValidateSIPLoad(params))
+
+	invalid := params
+	invalid.TrunkID = ""
+	require.Error(t, ValidateSIPLoad(invalid))
+	invalid = params
+	invalid.Calls = 0
+	require.Error(t, ValidateSIPLoad(invalid))
+	invalid = params
+	invalid.DTMF = "1{x}"
+	require.Error(t, ValidateSIPLoad(invalid))
+	invalid = params
+	invalid.DTMFInterval = time.Second
+	require.Error(t, ValidateSIPLoad(invalid))
+	invalid.Monitor = true
+	require.NoError(t, ValidateSIPLoad(invalid))
+	invalid = params
+	invalid.MaxFailureRate = 1.5
+	require.Error(t, ValidateSIPLoad(invalid))
+}
+
+func TestExpandCallIndex(t *testing.T) {
+	require.Equal(t, "+15550007", expandCallIndex("+1555000{n}", 7))
+	require.Equal(t, "12#", expandCallIndex("{n}#", 12))
+	require.Equal(t, "+15550000", expandCallIndex("+15550000", 3))
+}
+
+func TestSIPStatus(t *testing.T) {
+	err := (&livekit.SIPStatus{Code: livekit.SIPStatusCode_SIP_STATUS_BUSY_HERE}).GRPCStatus().Err()
+	require.Equal(t, "486 BUSY_HERE", sipStatus(err))
+	require.Equal(t, "timeout", sipStatus(fmt.Errorf("call: %w", context.DeadlineExceeded)))
+	require.Equal(t, "error", sipStatus(errors.New("unknown trunk")))
+}
+
+func TestCheckSIPFailures(t *testing.T) {
+	busy := errors.New("486 busy")
+	calls := func(failed, total int) []*sipCall {
+		c := make([]*sipCall, total)
+		for i := range c {
+			c[i] = &sipCall{index: i}
+			if i < failed {
+				c[i].err = busy
+			}
+		}
+		return c
+	}
+
+	require.NoError(t, checkSIPFailures(calls(0, 10), 0))
+	require.NoError(t, checkSIPFailures(calls(1, 20), 0.05))
+	err := checkSIPFailures(calls(2, 20), 0.05)
+	require.ErrorIs(t, err, busy)
+	require.ErrorContains(t, err, "2 of 20 calls failed")
+	require.ErrorContains(t, checkSIPFailures(calls(3, 3), 1), "all 3 calls failed")
+}
@@ -0,0 +1,146 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/livekit/protocol/livekit"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+// onActiveSpeakersChanged moves the high quality slot of a speaker-follow layout to the loudest
+// remote speaker, subscribing to them first if they were not on screen
+func (t *LoadTester) onActiveSpeakersChanged(speakers []lksdk.Participant) {
+	if t.params.Layout != LayoutSpeakerFollow || !t.params.Subscribe {
+		return
+	}
+	var speaker *lksdk.RemoteParticipant
+	for _, p := range speakers {
+		if rp, ok := p.(*lksdk.RemoteParticipant); ok {
+			speaker = rp
+			break
+		}
+	}
+	if speaker == nil {
+		return
+	}
+
+	t.lock.Lock()
+	if speaker.Identity() == t.activeSpeaker {
+		t.lock.Unlock()
+		return
+	}
+	previous := t.subscribedParticipants[t.activeSpeaker]
+	t.activeSpeaker = speaker.Identity()
+
+	var evicted *lksdk.RemoteParticipant
+	if t.subscribedParticipants[speaker.Identity()] == nil {
+		if len(t.subscribedParticipants) >= t.numToSubscribe() {
+			// make room by dropping any thumbnail other than the previous speaker
+			for identity, rp := range t.subscribedParticipants {
+				if previous == nil || identity != previous.Identity() {
+					evicted = rp
+					delete(t.subscribedParticipants, identity)
+					delete(t.trackQualities, rp.SID())
+					break
+				}
+			}
+		}
+		t.subscribedParticipants[speaker.Identity()] = speaker
+	}
+	if previous != nil {
+		t.trackQualities[previous.SID()] = livekit.VideoQuality_LOW
+	}
+	t.trackQualities[speaker.SID()] = livekit.VideoQuality_HIGH
+	for _, pub := range speaker.TrackPublications() {
+		if pub.Kind() == lksdk.TrackKindVideo {
+			t.pendingSpeakerSwitches[pub.SID()] = time.Now()
+		}
+	}
+	t.lock.Unlock()
+
+	if evicted != nil {
+		for _, pub := range evicted.TrackPublications() {
+			if remotePub, ok := pub.(*lksdk.RemoteTrackPublication); ok {
+				_ = t.unsubscribe(remotePub)
+			}
+		}
+	}
+	if previous != nil {
+		for _, pub := range previous.TrackPublications() {
+			if remotePub, ok := pub.(*lksdk.RemoteTrackPublication); ok && remotePub.Kind() == lksdk.TrackKindVideo {
+				remotePub.SetVideoDimensions(lowWidth, lowHeight)
+			}
+		}
+	}
+	for _, pub := range speaker.TrackPublications() {
+		remotePub, ok := pub.(*lksdk.RemoteTrackPublication)
+		if !ok {
+			continue
+		}
+		if !remotePub.IsSubscribed() {
+			// quality is set once the track is subscribed
+			remotePub.SetSubscribed(true)
+		} else if remotePub.Kind() == lksdk.TrackKindVideo {
+			remotePub.SetVideoDimensions(highWidth, highHeight)
+		}
+	}
+}
+
+// onSpeakerKeyframe completes a speaker switch once the first keyframe after it is received,
+// which is when the SFU can move the track to the higher layer
+func (t *LoadTester) onSpeakerKeyframe(sid string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	switchedAt, ok := t.pendingSpeakerSwitches[sid]
+	if !ok {
+		return
+	}
+	t.speakerSwitches = append(t.speakerSwitches, time.Since(switchedAt))
+	delete(t.pendingSpeakerSwitches, sid)
+}
+
+func printSpeakerSwitchStats(stats map[string]*testerStats, names []string) {
+	table := util.CreateTable().
+		Headers("Tester", "Speaker Switches", "Avg. Switch", "Max. Switch")
+	rows := 0
+	for _, name := range names {
+		samples := stats[name].speakerSwitches
+		if len(samples) == 0 {
+			continue
+		}
+		var total, maxSwitch time.Duration
+		for _, s := range samples {
+			total += s
+			maxSwitch = max(maxSwitch, s)
+		}
+		table.Row(
+			name,
+			fmt.Sprint(len(samples)),
+			(total / time.Duration(len(samples))).Round(time.Millisecond).String(),
+			maxSwitch.Round(time.Millisecond).String(),
+		)
+		rows++
+	}
+	if rows > 0 {
+		fmt.Println("\nSpeaker follow:")
+		fmt.Println(table)
+	}
+}
@@ -15,23 +15,95 @@
 package loadtester
 
 import (
+	"context"
+	"fmt"
 	"math/rand"
+	"strings"
 	"time"
 
 	"github.com/frostbyte73/core"
+	"github.com/pion/webrtc/v4/pkg/media"
+	"go.uber.org/atomic"
 
 	lksdk "github.com/livekit/server-sdk-go/v2"
+
+	provider2 "github.com/livekit/livekit-cli/v2/pkg/provider"
 )
 
+// audio level of a tester that is not talking, the quietest level the audio level extension can carry
+const silentAudioLevel = 127
+
+// TalkDuration is the range a simulated speaker's turn lasts
+type TalkDuration struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+// DefaultTalkDuration is how long speakers of a load test talk when no talk duration is given
+var DefaultTalkDuration = TalkDuration{Min: 5 * time.Second, Max: 20 * time.Second}
+
+// ParseTalkDuration parses MIN-MAX, e.g. 5s-20s, or a single fixed DURATION
+func ParseTalkDuration(str string) (TalkDuration, error) {
+	minStr, maxStr, isRange := strings.Cut(strings.TrimSpace(str), "-")
+	if !isRange {
+		maxStr = minStr
+	}
+	minDuration, err := time.ParseDuration(minStr)
+	if err != nil || minDuration <= 0 {
+		return TalkDuration{}, fmt.Errorf("invalid talk duration %q, expected MIN-MAX, e.g. 5s-20s", str)
+	}
+	maxDuration, err := time.ParseDuration(maxStr)
+	if err != nil || maxDuration < minDuration {
+		return TalkDuration{}, fmt.Errorf("invalid talk duration %q, expected MIN-MAX, e.g. 5s-20s", str)
+	}
+	return TalkDuration{Min: minDuration, Max: maxDuration}, nil
+}
+
+func (d TalkDuration) String() string {
+	if d.Min == d.Max {
+		return d.Min.String()
+	}
+	return fmt.Sprintf("%s-%s", d.Min, d.Max)
+}
+
+func (d TalkDuration) random() time.Duration {
+	if d.Max <= d.Min {
+		return d.Min
+	}
+	return d.Min + time.Duration(rand.Int63n(int64(d.Max-d.Min)))
+}
+
+// ValidateSpeakerSimulation checks the talk pattern of simulated speakers
+func ValidateSpeakerSimulation(params Params) error {
+	if params.OverlapProb < 0 || params.OverlapProb > 1 {
+		return fmt.Errorf("overlap probability must be between 0 and 1")
+	}
+	if !params.SimulateSpeakers && (params.OverlapProb > 0 || params.TalkDuration != TalkDuration{}) {
+		return fmt.Errorf("talk duration and overlap probability need simulated speakers")
+	}
+	if params.TalkDuration.Min < 0 || params.TalkDuration.Max < params.TalkDuration.Min {
+		return fmt.Errorf("invalid talk duration %s", params.TalkDuration)
+	}
+	return nil
+}
+
 type SpeakerSimulatorParams struct {
 	Testers []*LoadTester
 	// amount of time between each speaker
 	Pause uint64
+	// when set, testers take turns talking for a duration in this range instead of
+	// firing random speaker events
+	TalkDuration TalkDuration
+	// probability that another tester talks over part of a turn
+	OverlapProb float64
 }
 
 type SpeakerSimulator struct {
 	params SpeakerSimulatorParams
 	fuse   *core.Fuse
+
+	turns    atomic.Int64
+	overlaps atomic.Int64
 }
 
 func NewSpeakerSimulator(params SpeakerSimulatorParams) *SpeakerSimulator {
@@ -48,7 +120,11 @@ func (s *SpeakerSimulator) Start() {
 		return
 	}
 	s.fuse = new(core.Fuse)
-	go s.worker()
+	if s.params.TalkDuration.Max > 0 {
+		go s.converse(s.fuse)
+	} else {
+		go s.worker(s.fuse)
+	}
 }
 
 func (s *SpeakerSimulator) Stop() {
@@ -59,12 +135,12 @@ func (s *SpeakerSimulator) Stop() {
 	s.fuse = nil
 }
 
-func (s *SpeakerSimulator) worker() {
+func (s *SpeakerSimulator) worker(fuse *core.Fuse) {
 	t := time.NewTicker(time.Duration(s.params.Pause) * time.Second)
 	defer t.Stop()
 	for {
 		select {
-		case <-s.fuse.Watch():
+		case <-fuse.Watch():
 			return
 		case <-t.C:
 			speaker := s.params.Testers[rand.Intn(len(s.params.Testers))]
@@ -73,3 +149,165 @@ func (s *SpeakerSimulator) worker() {
 		}
 	}
 }
+
+// converse hands the turn to a different tester after each talk duration. With OverlapProb, someone
+// else cuts in at a random point of a turn and talks until its end.
+func (s *SpeakerSimulator) converse(fuse *core.Fuse) {
+	var speaker *LoadTester
+	for {
+		speaker = s.nextSpeaker(speaker)
+		turn := s.params.TalkDuration.random()
+		s.turns.Inc()
+		speaker.setTalking(true)
+
+		var overlap *LoadTester
+		if len(s.params.Testers) > 1 && rand.Float64() < s.params.OverlapProb {
+			cutIn := time.Duration(rand.Int63n(int64(turn)))
+			if !s.wait(fuse, cutIn) {
+				speaker.setTalking(false)
+				return
+			}
+			overlap = s.nextSpeaker(speaker)
+			s.overlaps.Inc()
+			overlap.setTalking(true)
+			turn -= cutIn
+		}
+
+		done := !s.wait(fuse, turn)
+		speaker.setTalking(false)
+		if overlap != nil {
+			overlap.setTalking(false)
+		}
+		if done {
+			return
+		}
+	}
+}
+
+// nextSpeaker picks a random tester other than the current speaker
+func (s *SpeakerSimulator) nextSpeaker(current *LoadTester) *LoadTester {
+	if len(s.params.Testers) == 1 {
+		return s.params.Testers[0]
+	}
+	for {
+		if next := s.params.Testers[rand.Intn(len(s.params.Testers))]; next != current {
+			return next
+		}
+	}
+}
+
+func (s *SpeakerSimulator) wait(fuse *core.Fuse, d time.Duration) bool {
+	select {
+	case <-fuse.Watch():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+// setTalking starts or ends a simulated turn. Audio publishers are silent outside of their turns,
+// so the server detects the speaker from their audio, others have the server simulate a speaker update.
+func (t *LoadTester) setTalking(talking bool) {
+	t.talking.Store(talking)
+	if talking && !t.params.talkTurns && t.room != nil {
+		t.room.Simulate(lksdk.SimulateSpeakerUpdate)
+	}
+}
+
+// talkGate replaces the audio of a tester with silence outside of its turns
+type talkGate struct {
+	audioSource
+	talking *atomic.Bool
+}
+
+func (g *talkGate) NextSample(ctx context.Context) (media.Sample, error) {
+	sample, err := g.audioSource.NextSample(ctx)
+	if err == nil && !g.talking.Load() && len(sample.Data) > 0 {
+		sample.Data = provider2.OpusSilence(sample.Data[0])
+	}
+	return sample, err
+}
+
+func (g *talkGate) CurrentAudioLevel() uint8 {
+	if !g.talking.Load() {
+		return silentAudioLevel
+	}
+	return g.audioSource.CurrentAudioLevel()
+}
+
+// SpeakerResults are the turns of the simulated conversations, and the active speaker
+// updates they caused subscribers to receive
+type SpeakerResults struct {
+	Turns    int64 `json:"turns"`
+	Overlaps int64 `json:"overlaps"`
+	Updates  int64 `json:"speakerUpdates"`
+}
+
+// conversations simulate speakers in each room
+type conversations []*SpeakerSimulator
+
+// startConversations has the publishers of each room take turns talking
+func startConversations(params Params, testers []*LoadTester) conversations {
+	if !params.SimulateSpeakers {
+		return nil
+	}
+	var rooms []string
+	speakers := make(map[string][]*LoadTester)
+	for _, t := range testers {
+		if !t.params.Role.IsPublisher() || t.params.Role == RoleDataPublisher {
+			continue
+		}
+		if speakers[t.params.Room] == nil {
+			rooms = append(rooms, t.params.Room)
+		}
+		speakers[t.params.Room] = append(speakers[t.params.Room], t)
+	}
+	talkDuration := params.TalkDuration
+	if talkDuration.Max == 0 {
+		talkDuration = DefaultTalkDuration
+	}
+	fmt.Printf("Simulating speakers in %d rooms, taking %s turns with %.0f%% overlapping\n",
+		len(rooms), talkDuration, params.OverlapProb*100)
+	var c conversations
+	for _, room := range rooms {
+		sim := NewSpeakerSimulator(SpeakerSimulatorParams{
+			Testers:      speakers[room],
+			TalkDuration: talkDuration,
+			OverlapProb:  params.OverlapProb,
+		})
+		sim.Start()
+		c = append(c, sim)
+	}
+	return c
+}
+
+func (c conversations) finish(testers []*LoadTester) *SpeakerResults {
+	if len(c) == 0 {
+		return nil
+	}
+	res := &SpeakerResults{}
+	for _, sim := range c {
+		sim.Stop()
+		res.Turns += sim.turns.Load()
+		res.Overlaps += sim.overlaps.Load()
+	}
+	for _, t := range testers {
+		if t.params.Subscribe {
+			res.Updates += t.speakerUpdates.Load()
+		}
+	}
+	return res
+}
+
+func printSpeakerResults(res *SpeakerResults) {
+	if res == nil {
+		return
+	}
+	fmt.Println("\nSimulated speakers:")
+	fmt.Printf("  %d turns, %d with overlapping speakers\n", res.Turns, res.Overlaps)
+	perTurn := 0.0
+	if res.Turns > 0 {
+		perTurn = float64(res.Updates) / float64(res.Turns)
+	}
+	fmt.Printf("  %d active speaker updates reached subscribers, %.1f per turn\n", res.Updates, perTurn)
+}
@@ -0,0 +1,107 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+
+	provider2 "github.com/livekit/livekit-cli/v2/pkg/provider"
+)
+
+func TestParseTalkDuration(t *testing.T) {
+	d, err := ParseTalkDuration("5s-20s")
+	require.NoError(t, err)
+	require.Equal(t, TalkDuration{Min: 5 * time.Second, Max: 20 * time.Second}, d)
+	require.Equal(t, "5s-20s", d.String())
+
+	d, err = ParseTalkDuration("10s")
+	require.NoError(t, err)
+	require.Equal(t, TalkDuration{Min: 10 * time.Second, Max: 10 * time.Second}, d)
+	require.Equal(t, 10*time.Second, d.random())
+
+	for _, str := range []string{"", "5s-", "20s-5s", "0s-5s", "five"} {
+		_, err = ParseTalkDuration(str)
+		require.Error(t, err, str)
+	}
+}
+
+func TestValidateSpeakerSimulation(t *testing.T) {
+	require.NoError(t, ValidateSpeakerSimulation(Params{}))
+	require.NoError(t, ValidateSpeakerSimulation(Params{SimulateSpeakers: true, OverlapProb: 0.1}))
+	require.Error(t, ValidateSpeakerSimulation(Params{SimulateSpeakers: true, OverlapProb: 1.5}))
+	require.Error(t, ValidateSpeakerSimulation(Params{OverlapProb: 0.1}))
+	require.Error(t, ValidateSpeakerSimulation(Params{TalkDuration: DefaultTalkDuration}))
+}
+
+func TestTalkGate(t *testing.T) {
+	talking := atomic.NewBool(false)
+	gate := &talkGate{audioSource: provider2.NewOpusGenerator(1), talking: talking}
+
+	sample, err := gate.NextSample(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, opusSilence, sample.Data)
+	require.EqualValues(t, silentAudioLevel, gate.CurrentAudioLevel())
+
+	talking.Store(true)
+	sample, err = gate.NextSample(context.Background())
+	require.NoError(t, err)
+	require.Greater(t, len(sample.Data), len(opusSilence))
+	require.Less(t, gate.CurrentAudioLevel(), uint8(silentAudioLevel))
+}
+
+func TestConverse(t *testing.T) {
+	testers := []*LoadTester{
+		{params: TesterParams{talkTurns: true}},
+		{params: TesterParams{talkTurns: true}},
+		{params: TesterParams{talkTurns: true}},
+	}
+	sim := NewSpeakerSimulator(SpeakerSimulatorParams{
+		Testers:      testers,
+		TalkDuration: TalkDuration{Min: 5 * time.Millisecond, Max: 10 * time.Millisecond},
+		OverlapProb:  1,
+	})
+	sim.Start()
+
+	deadline := time.Now().Add(200 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		talking := 0
+		for _, tester := range testers {
+			if tester.talking.Load() {
+				talking++
+			}
+		}
+		// the speaker and at most one overlapping speaker
+		require.LessOrEqual(t, talking, 2)
+		time.Sleep(time.Millisecond)
+	}
+	sim.Stop()
+
+	require.Greater(t, sim.turns.Load(), int64(5))
+	// every turn is overlapped, except when stopped before the other speaker cut in
+	require.GreaterOrEqual(t, sim.overlaps.Load(), sim.turns.Load()-1)
+	require.Eventually(t, func() bool {
+		for _, tester := range testers {
+			if tester.talking.Load() {
+				return false
+			}
+		}
+		return true
+	}, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+)
+
+// tester lifecycle states
+const (
+	stateCreated      = "created"
+	stateToken        = "token"
+	stateConnected    = "connected"
+	statePublished    = "published"
+	stateSubscribed   = "subscribed"
+	stateError        = "error"
+	stateDisconnected = "disconnected"
+	stateClosed       = "closed"
+)
+
+type stateTransition struct {
+	Time     time.Time `json:"time"`
+	Tester   string    `json:"tester"`
+	Identity string    `json:"identity"`
+	Room     string    `json:"room"`
+	State    string    `json:"state"`
+	Detail   string    `json:"detail,omitempty"`
+	Error    string    `json:"error,omitempty"`
+}
+
+// stateLog writes tester state transitions to a file as JSON lines
+type stateLog struct {
+	lock sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+func newStateLog(path string) (*stateLog, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &stateLog{
+		file: f,
+		enc:  json.NewEncoder(f),
+	}, nil
+}
+
+func (l *stateLog) write(transition *stateTransition) {
+	if l == nil {
+		return
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	_ = l.enc.Encode(transition)
+}
+
+func (l *stateLog) Close() error {
+	if l == nil {
+		return nil
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	return l.file.Close()
+}
+
+func (t *LoadTester) setState(state, detail string, err error) {
+	t.logState(state, detail, err)
+	if t.params.stateLog == nil {
+		return
+	}
+	transition := &stateTransition{
+		Time:     time.Now(),
+		Tester:   t.params.name,
+		Identity: t.identity(),
+		Room:     t.params.Room,
+		State:    state,
+		Detail:   detail,
+	}
+	if err != nil {
+		transition.Error = err.Error()
+	}
+	t.params.stateLog.write(transition)
+}
+
+// logState adds a state change to the tester's error log, and writes the log out when the
+// tester failed or dropped while it was meant to be running
+func (t *LoadTester) logState(state, detail string, err error) {
+	if t.errorLog == nil {
+		return
+	}
+	line := state
+	if detail != "" {
+		line += " " + detail
+	}
+	if err != nil {
+		line += ": " + err.Error()
+	}
+	t.errorLog.logf("state %s", line)
+	switch {
+	case state == stateError:
+		t.errorLog.fail(line)
+	case state == stateDisconnected && t.IsRunning():
+		t.errorLog.fail("disconnected, reason " + detail)
+	}
+}
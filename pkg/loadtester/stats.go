@@ -25,16 +25,88 @@ import (
 type testerStats struct {
 	expectedTracks int
 	trackStats     map[string]*trackStats
-	err            error
+	resubscribes   []*resubscribeSample
+	// time from an active speaker change until the speaker's video arrived at high quality
+	speakerSwitches []time.Duration
+	// time from requesting a different video quality until a keyframe of the new layer arrived
+	layerSwitches        []time.Duration
+	pendingLayerSwitches int
+	// forced reconnects, successful ones in reconnects
+	reconnectAttempts int
+	reconnectFailures int
+	reconnects        []*reconnectSample
+	// published tracks with a different SID after resuming
+	unresumedTracks []string
+	// sampled publisher-to-subscriber latencies, out of latencyCount measured
+	latencies    []time.Duration
+	latencyCount int64
+	// all measured latencies, for the tail the samples miss
+	latencyHist *histogram
+	// sampled times for permission updates to reach the tester, out of permissionUpdateCount seen
+	permissionUpdates     []time.Duration
+	permissionUpdateCount int64
+	// data messages received by a subscriber, nil without data publishers
+	data *dataStats
+	// data messages sent by a publisher
+	dataSent   int64
+	dataErrors int64
+	role       Role
+	// ramp step the tester was started in
+	rampStep int
+	// subscribers measure the time from joining until the call is usable, 0 if it never was
+	interactiveMeasured bool
+	timeToInteractive   time.Duration
+	// features of the tester's cohort, empty without cohorts
+	cohort string
+	room   string
+	err    error
+	// location label of the tester's cohort, empty when unlabeled,
+	// and the URL it joined when testers were spread over several
+	label    string
+	endpoint string
+	// published tracks and layers with a target bitrate
+	sentLayers []*sentLayer
+	// backup codec tracks published on the server's request
+	backupTracks int
+	// times the tester failed and was replaced with a new one
+	replacements int
+	// failed attempts to join or publish that were transient, and attempts repeated after them
+	transientErrors int64
+	retries         int64
 }
 
 type trackStats struct {
-	trackID   string
-	kind      lksdk.TrackKind
-	startedAt atomic.Time
-	packets   atomic.Int64
-	bytes     atomic.Int64
-	dropped   atomic.Int64
+	trackID string
+	kind    lksdk.TrackKind
+	// when the subscriber learned about the publication
+	publishedAt  time.Time
+	startedAt    atomic.Time
+	firstFrameAt atomic.Time
+	packets      atomic.Int64
+	bytes        atomic.Int64
+	dropped      atomic.Int64
+	// audio packets missing from the received sequence
+	concealedPackets  atomic.Int64
+	concealmentEvents atomic.Int64
+	// runs of consecutive missing packets, by length
+	burstLoss burstHistogram
+	// pauses in received media longer than the tester's MaxMediaGap
+	lastPacketAt atomic.Int64
+	mediaGaps    atomic.Int64
+	longestGap   atomic.Int64
+	// received frames of encrypted tracks, by whether they could be decrypted
+	decryptedFrames atomic.Int64
+	decryptFailures atomic.Int64
+	// transit time variation of each received packet
+	jitter histogram
+	// stalls between video frames longer than the tester's FreezeThreshold, in total and the longest
+	freezes       atomic.Int64
+	frozen        atomic.Int64
+	longestFreeze atomic.Int64
+	// measures the freezes of the current subscription to a video track, nil for audio
+	freezeMeter atomic.Pointer[freezeMeter]
+	// codec the track was received in
+	mimeType atomic.String
 }
 
 type summary struct {
@@ -86,3 +158,15 @@ func getTesterSummary(testerStats *testerStats) *summary {
 	}
 	return s
 }
+
+// latencyHistogram is every latency the tester measured, or its samples when it has no histogram
+func (s *testerStats) latencyHistogram() *histogram {
+	if s.latencyHist != nil {
+		return s.latencyHist
+	}
+	h := &histogram{}
+	for _, d := range s.latencies {
+		h.record(d)
+	}
+	return h
+}
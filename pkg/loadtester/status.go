@@ -0,0 +1,210 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	phaseIdle     = "idle"
+	phaseRamping  = "ramping"
+	phaseRunning  = "running"
+	phaseFinished = "finished"
+
+	// a ramp that hasn't started a tester for this long is considered stuck
+	rampStallTimeout = time.Minute
+)
+
+// runStatus tracks the progress of the current run for the health endpoints
+type runStatus struct {
+	lock         sync.Mutex
+	phase        string
+	expected     int
+	testers      []*LoadTester
+	connectErrs  int
+	publishErrs  int
+	lastProgress time.Time
+	// when all testers had joined, and when they started leaving
+	runningAt  time.Time
+	finishedAt time.Time
+}
+
+type statusReport struct {
+	Phase           string  `json:"phase"`
+	ExpectedTesters int     `json:"expectedTesters"`
+	StartedTesters  int     `json:"startedTesters"`
+	ActiveTesters   int     `json:"activeTesters"`
+	Errors          int     `json:"errors"`
+	ErrorRate       float64 `json:"errorRate"`
+	RampProgress    float64 `json:"rampProgress"`
+	Stalled         bool    `json:"stalled,omitempty"`
+}
+
+func newRunStatus() *runStatus {
+	return &runStatus{phase: phaseIdle}
+}
+
+func (s *runStatus) begin(expected int) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.phase = phaseRamping
+	s.expected = expected
+	s.testers = nil
+	s.connectErrs = 0
+	s.publishErrs = 0
+	s.lastProgress = time.Now()
+	s.runningAt = time.Time{}
+	s.finishedAt = time.Time{}
+}
+
+func (s *runStatus) addTester(t *LoadTester) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.testers = append(s.testers, t)
+	s.lastProgress = time.Now()
+}
+
+// replaceTester swaps a failed tester for its replacement, keeping the count of started testers
+func (s *runStatus) replaceTester(failed, replacement *LoadTester) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for i, t := range s.testers {
+		if t == failed {
+			s.testers[i] = replacement
+			return
+		}
+	}
+	s.testers = append(s.testers, replacement)
+}
+
+func (s *runStatus) addConnectError() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.connectErrs++
+}
+
+func (s *runStatus) addPublishError() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.publishErrs++
+}
+
+func (s *runStatus) startedTesters() []*LoadTester {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]*LoadTester(nil), s.testers...)
+}
+
+func (s *runStatus) setPhase(phase string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.phase = phase
+	if phase == phaseRunning && s.runningAt.IsZero() {
+		s.runningAt = time.Now()
+	} else if phase == phaseFinished && !s.runningAt.IsZero() && s.finishedAt.IsZero() {
+		s.finishedAt = time.Now()
+	}
+}
+
+// heldFor is the time the testers were held once all of them had joined
+func (s *runStatus) heldFor() time.Duration {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.runningAt.IsZero() {
+		return 0
+	}
+	if !s.finishedAt.IsZero() {
+		return s.finishedAt.Sub(s.runningAt)
+	}
+	return time.Since(s.runningAt)
+}
+
+func (s *runStatus) report() *statusReport {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	r := &statusReport{
+		Phase:           s.phase,
+		ExpectedTesters: s.expected,
+		StartedTesters:  len(s.testers),
+		Errors:          s.connectErrs + s.publishErrs,
+		Stalled:         s.phase == phaseRamping && time.Since(s.lastProgress) > rampStallTimeout,
+	}
+	for _, t := range s.testers {
+		if t.IsRunning() {
+			r.ActiveTesters++
+		}
+	}
+	if r.StartedTesters > 0 {
+		r.ErrorRate = float64(r.Errors) / float64(r.StartedTesters)
+	}
+	if r.ExpectedTesters > 0 {
+		r.RampProgress = float64(r.StartedTesters) / float64(r.ExpectedTesters)
+	}
+	return r
+}
+
+// serveStatus serves Prometheus metrics and the health endpoints on addr until the returned function is called.
+// /healthz fails when the ramp has stalled, /readyz succeeds once all testers have joined.
+func (t *LoadTest) serveStatus(addr string) (func(), error) {
+	if addr == "" {
+		return func() {}, nil
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		report := t.status.report()
+		writeStatus(w, report, !report.Stalled)
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		report := t.status.report()
+		writeStatus(w, report, report.Phase == phaseRunning)
+	})
+	mux.HandleFunc("/metrics", t.serveMetrics)
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	server := &http.Server{Handler: mux}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Println("status server stopped:", err)
+		}
+	}()
+	fmt.Printf("Serving metrics and health checks on %s\n", listener.Addr())
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}, nil
+}
+
+func writeStatus(w http.ResponseWriter, report *statusReport, ok bool) {
+	w.Header().Set("Content-Type", "application/json")
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_ = json.NewEncoder(w).Encode(report)
+}
@@ -0,0 +1,59 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/livekit/livekit-cli/v2/pkg/provider"
+)
+
+const (
+	defaultTestPatternFPS = 30
+	maxTestPatternFPS     = 120
+	maxTestPatternSize    = 4096
+)
+
+// ParseTestPattern reads a --test-pattern size, WIDTHxHEIGHT with an optional @FPS, 30 by default
+func ParseTestPattern(s string) (*provider.TestPattern, error) {
+	if s == "" {
+		return nil, nil
+	}
+	size, fps, hasFPS := strings.Cut(s, "@")
+	width, height, ok := strings.Cut(size, "x")
+	if !ok {
+		return nil, fmt.Errorf("invalid test pattern %q, expected WIDTHxHEIGHT[@FPS], e.g. 1280x720@30", s)
+	}
+	pattern := &provider.TestPattern{FPS: defaultTestPatternFPS}
+	var err error
+	if pattern.Width, err = strconv.Atoi(width); err == nil {
+		pattern.Height, err = strconv.Atoi(height)
+	}
+	if err == nil && hasFPS {
+		pattern.FPS, err = strconv.Atoi(fps)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid test pattern %q, expected WIDTHxHEIGHT[@FPS], e.g. 1280x720@30", s)
+	}
+	if pattern.Width < 1 || pattern.Height < 1 || pattern.Width > maxTestPatternSize || pattern.Height > maxTestPatternSize {
+		return nil, fmt.Errorf("test pattern sides must be between 1 and %d pixels, got %dx%d", maxTestPatternSize, pattern.Width, pattern.Height)
+	}
+	if pattern.FPS < 1 || pattern.FPS > maxTestPatternFPS {
+		return nil, fmt.Errorf("test pattern frame rate must be between 1 and %d, got %d", maxTestPatternFPS, pattern.FPS)
+	}
+	return pattern, nil
+}
@@ -0,0 +1,46 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/livekit-cli/v2/pkg/provider"
+)
+
+func TestParseTestPattern(t *testing.T) {
+	pattern, err := ParseTestPattern("")
+	require.NoError(t, err)
+	require.Nil(t, pattern)
+
+	pattern, err = ParseTestPattern("1280x720")
+	require.NoError(t, err)
+	require.Equal(t, &provider.TestPattern{Width: 1280, Height: 720, FPS: 30}, pattern)
+
+	pattern, err = ParseTestPattern("640x360@15")
+	require.NoError(t, err)
+	require.Equal(t, &provider.TestPattern{Width: 640, Height: 360, FPS: 15}, pattern)
+
+	for _, s := range []string{"1280", "1280x", "axb", "1280x720@", "1280x720@fast"} {
+		_, err = ParseTestPattern(s)
+		require.EqualError(t, err, `invalid test pattern "`+s+`", expected WIDTHxHEIGHT[@FPS], e.g. 1280x720@30`)
+	}
+	_, err = ParseTestPattern("8192x720")
+	require.EqualError(t, err, "test pattern sides must be between 1 and 4096 pixels, got 8192x720")
+	_, err = ParseTestPattern("1280x720@240")
+	require.EqualError(t, err, "test pattern frame rate must be between 1 and 120, got 240")
+}
@@ -0,0 +1,72 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Thresholds fail a run whose results are worse, so that load tests can gate CI. Unset
+// thresholds are not checked.
+type Thresholds struct {
+	// highest packet loss of the reported testers combined, as a fraction
+	MaxPacketLoss float64
+	// most testers that may fail to connect or publish, nil to not check
+	MaxConnectFailures *int
+	// lowest average bitrate per reported tester, in bps
+	MinBitrate int64
+}
+
+// ValidateThresholds checks the --max-* and --min-* flags
+func ValidateThresholds(th Thresholds) error {
+	if th.MaxPacketLoss < 0 || th.MaxPacketLoss > 1 {
+		return fmt.Errorf("max packet loss must be a fraction between 0 and 1")
+	}
+	if th.MaxConnectFailures != nil && *th.MaxConnectFailures < 0 {
+		return fmt.Errorf("max connect failures cannot be negative")
+	}
+	if th.MinBitrate < 0 {
+		return fmt.Errorf("min bitrate cannot be negative")
+	}
+	return nil
+}
+
+// check returns an error naming every threshold the results violate
+func (th Thresholds) check(results *Results) error {
+	var failed []string
+	total := results.Total
+	if th.MaxPacketLoss > 0 && total.LossRate > th.MaxPacketLoss {
+		failed = append(failed, fmt.Sprintf("packet loss %.2f%% is above --max-packet-loss %.2f%%",
+			total.LossRate*100, th.MaxPacketLoss*100))
+	}
+	if th.MaxConnectFailures != nil && results.FailedTesters > *th.MaxConnectFailures {
+		failed = append(failed, fmt.Sprintf("%d testers failed to connect or publish, above --max-connect-failures %d",
+			results.FailedTesters, *th.MaxConnectFailures))
+	}
+	if th.MinBitrate > 0 {
+		avg := 0.0
+		if total.Testers > 0 {
+			avg = total.Bitrate / float64(total.Testers)
+		}
+		if avg < float64(th.MinBitrate) {
+			failed = append(failed, fmt.Sprintf("average bitrate %.0fbps is below --min-bitrate %dbps", avg, th.MinBitrate))
+		}
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	return fmt.Errorf("load test failed %d threshold(s):\n  %s", len(failed), strings.Join(failed, "\n  "))
+}
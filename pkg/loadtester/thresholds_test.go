@@ -0,0 +1,41 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestThresholds(t *testing.T) {
+	results := &Results{
+		Total:         &TesterResults{Name: "Total", Testers: 4, Bitrate: 2_000_000, LossRate: 0.03},
+		FailedTesters: 1,
+	}
+	require.NoError(t, Thresholds{}.check(results))
+
+	none, one := 0, 1
+	require.NoError(t, Thresholds{MaxPacketLoss: 0.05, MaxConnectFailures: &one, MinBitrate: 500_000}.check(results))
+
+	err := Thresholds{MaxPacketLoss: 0.02, MaxConnectFailures: &none, MinBitrate: 600_000}.check(results)
+	require.ErrorContains(t, err, "3 threshold(s)")
+	require.ErrorContains(t, err, "--max-packet-loss")
+	require.ErrorContains(t, err, "--max-connect-failures 0")
+	require.ErrorContains(t, err, "--min-bitrate 600000bps")
+
+	require.Error(t, ValidateThresholds(Thresholds{MaxPacketLoss: 2}))
+	require.NoError(t, ValidateThresholds(Thresholds{MaxPacketLoss: 0.01, MaxConnectFailures: &none}))
+}
@@ -0,0 +1,124 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// tokenClaims are the parts of a pre-minted access token testers need before joining
+type tokenClaims struct {
+	Identity string `json:"sub"`
+	Expires  int64  `json:"exp"`
+	Video    *struct {
+		Room string `json:"room"`
+	} `json:"video"`
+}
+
+// parseTokenClaims reads the claims of an access token without verifying it, since tokens
+// issued by an external auth service are signed with a secret the tester doesn't know
+func parseTokenClaims(token string) (*tokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("not a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT payload: %w", err)
+	}
+	claims := &tokenClaims{}
+	if err = json.Unmarshal(payload, claims); err != nil {
+		return nil, fmt.Errorf("invalid JWT claims: %w", err)
+	}
+	if claims.Identity == "" {
+		return nil, fmt.Errorf("token has no identity")
+	}
+	if claims.Video == nil || claims.Video.Room == "" {
+		return nil, fmt.Errorf("token of %s grants no room", claims.Identity)
+	}
+	if claims.Expires != 0 && time.Unix(claims.Expires, 0).Before(time.Now()) {
+		return nil, fmt.Errorf("token of %s expired at %s", claims.Identity, time.Unix(claims.Expires, 0).Format(time.RFC3339))
+	}
+	return claims, nil
+}
+
+// LoadTokens reads a pre-minted access token from each line of a file, skipping empty lines and # comments
+func LoadTokens(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var tokens []string
+	scanner := bufio.NewScanner(f)
+	// tokens with many attributes outgrow the default line limit
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		if _, err := parseTokenClaims(text); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		tokens = append(tokens, text)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("%s lists no tokens", path)
+	}
+	return tokens, nil
+}
+
+// ValidateTokens checks that pre-minted tokens aren't combined with options that need to mint their own
+func ValidateTokens(params Params) error {
+	if len(params.Tokens) == 0 {
+		return nil
+	}
+	if params.RotateAPIKey != "" {
+		return fmt.Errorf("API key rotation cannot be combined with pre-minted tokens")
+	}
+	return nil
+}
+
+// useToken makes the tester join with a pre-minted token, in the room and as the identity it grants
+func (p *TesterParams) useToken(tokens []string, i int) {
+	if i >= len(tokens) {
+		return
+	}
+	// LoadTokens checked the claims already
+	_ = p.SetToken(tokens[i])
+}
+
+// SetToken makes the tester join with a pre-minted token, in the room and as the identity it grants
+func (p *TesterParams) SetToken(token string) error {
+	claims, err := parseTokenClaims(token)
+	if err != nil {
+		return err
+	}
+	p.Token = token
+	p.Room = claims.Video.Room
+	p.tokenIdentity = claims.Identity
+	return nil
+}
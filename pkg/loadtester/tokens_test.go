@@ -0,0 +1,84 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/stretchr/testify/require"
+)
+
+func mintTestToken(t *testing.T, identity, room string) string {
+	at := auth.NewAccessToken("external", "externalsecretexternalsecret").
+		SetIdentity(identity).
+		SetVideoGrant(&auth.VideoGrant{RoomJoin: true, Room: room})
+	token, err := at.ToJWT()
+	require.NoError(t, err)
+	return token
+}
+
+func TestLoadTokens(t *testing.T) {
+	first := mintTestToken(t, "alice", "room_a")
+	second := mintTestToken(t, "bob", "room_b")
+	path := filepath.Join(t.TempDir(), "tokens.txt")
+	require.NoError(t, os.WriteFile(path, []byte("# issued by the auth service\n"+first+"\n\n"+second+"\n"), 0644))
+
+	tokens, err := LoadTokens(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{first, second}, tokens)
+
+	// signed by a service whose secret the tester doesn't know
+	claims := fmt.Sprintf(`{"sub":"carol","exp":%d,"video":{"room":"room_a"}}`, time.Now().Add(-time.Minute).Unix())
+	expired := "eyJhbGciOiJIUzI1NiJ9." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".c2ln"
+	require.NoError(t, os.WriteFile(path, []byte(first+"\n"+expired+"\n"), 0644))
+	_, err = LoadTokens(path)
+	require.ErrorContains(t, err, "tokens.txt:2: token of carol expired at")
+
+	require.NoError(t, os.WriteFile(path, []byte("not-a-token\n"), 0644))
+	_, err = LoadTokens(path)
+	require.ErrorContains(t, err, "tokens.txt:1: not a JWT")
+
+	roomless := mintTestToken(t, "dave", "")
+	_, err = parseTokenClaims(roomless)
+	require.EqualError(t, err, "token of dave grants no room")
+}
+
+func TestUseToken(t *testing.T) {
+	tokens := []string{mintTestToken(t, "alice", "room_a")}
+
+	params := TesterParams{Room: "testroom_0", IdentityPrefix: "abc", Role: RoleSubscriber}
+	params.useToken(tokens, 0)
+	tester := NewLoadTester(params)
+	require.Equal(t, "room_a", tester.params.Room)
+	require.Equal(t, "alice", tester.identity())
+	token, err := tester.token()
+	require.NoError(t, err)
+	require.Equal(t, tokens[0], token)
+
+	// past the end of the tokens, testers mint their own
+	params = TesterParams{Room: "testroom_0", IdentityPrefix: "abc", APIKey: "key", APISecret: "secretsecretsecretsecretsecret"}
+	params.useToken(tokens, 1)
+	tester = NewLoadTester(params)
+	require.Equal(t, "abc_0", tester.identity())
+	token, err = tester.token()
+	require.NoError(t, err)
+	require.NotEqual(t, tokens[0], token)
+}
@@ -0,0 +1,202 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/livekit/livekit-cli/v2/pkg/provider"
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+// DefaultTrackProbeMaxPublishers keeps a probe that never sees quality collapse from growing
+// without bounds
+const DefaultTrackProbeMaxPublishers = 50
+
+type TrackProbeParams struct {
+	VideoResolution string
+	VideoCodec      string
+	Simulcast       bool
+	// stop after this many publishers even if quality holds
+	MaxPublishers int
+	// time to let the room settle after adding each publisher
+	StepInterval time.Duration
+	// packet loss (0-1) seen by the monitoring subscriber that counts as quality collapse
+	MaxPacketLoss float64
+	TesterParams
+}
+
+// TrackProbe keeps adding video publishers to a single room until publishing fails
+// or quality collapses, to find the practical per-room track ceiling
+type TrackProbe struct {
+	params TrackProbeParams
+}
+
+type trackProbeStep struct {
+	publishers int
+	tracks     int
+	bytes      int64
+	packets    int64
+	dropped    int64
+	elapsed    time.Duration
+}
+
+func NewTrackProbe(params TrackProbeParams) *TrackProbe {
+	if params.MaxPublishers == 0 {
+		params.MaxPublishers = DefaultTrackProbeMaxPublishers
+	}
+	if params.StepInterval == 0 {
+		params.StepInterval = 5 * time.Second
+	}
+	if params.MaxPacketLoss == 0 {
+		params.MaxPacketLoss = 0.05
+	}
+	if params.Room == "" {
+		params.Room = fmt.Sprintf("trackprobe%d", rand.Int31n(1000))
+	}
+	if params.IdentityPrefix == "" {
+		params.IdentityPrefix = randStringRunes(5)
+	}
+	return &TrackProbe{
+		params: params,
+	}
+}
+
+func (p *TrackProbe) Run(ctx context.Context) error {
+	// the probe can reach MaxPublishers publishers, next to the monitoring subscriber
+	policy := Params{TesterParams: p.params.TesterParams, VideoPublishers: p.params.MaxPublishers, Subscribers: 1}
+	if err := validateParams(policy); err != nil {
+		return err
+	}
+	if err := checkUsagePolicy(policy); err != nil {
+		return err
+	}
+	if err := provider.CheckEmbeddedCodec(p.params.VideoCodec); err != nil {
+		return err
+	}
+
+	fmt.Printf("Probing track ceiling in room %s, adding a publisher every %s\n", p.params.Room, p.params.StepInterval)
+
+	monitorParams := p.params.TesterParams
+	monitorParams.IdentityPrefix += "_monitor"
+	monitorParams.name = "Monitor"
+	monitorParams.Subscribe = true
+	monitorParams.SubscribeAll = true
+	monitor := NewLoadTester(monitorParams)
+	if err := monitor.Start(); err != nil {
+		return err
+	}
+	defer monitor.Stop()
+
+	var (
+		publishers []*LoadTester
+		steps      []*trackProbeStep
+		reason     string
+		last       = &trackProbeStep{}
+	)
+	defer func() {
+		for _, pub := range publishers {
+			pub.Stop()
+		}
+	}()
+
+	for i := 0; i < p.params.MaxPublishers && reason == ""; i++ {
+		pubParams := p.params.TesterParams
+		pubParams.IdentityPrefix += "_pub"
+		pubParams.Sequence = i
+		pubParams.name = fmt.Sprintf("Pub %d", i)
+		pub := NewLoadTester(pubParams)
+		if err := pub.Start(); err != nil {
+			reason = fmt.Sprintf("publisher %d could not connect: %v", i+1, err)
+			break
+		}
+		publishers = append(publishers, pub)
+
+		var err error
+		if p.params.Simulcast {
+			_, err = pub.PublishSimulcastTrack("video-simulcast", p.params.VideoResolution, p.params.VideoCodec)
+		} else {
+			_, err = pub.PublishVideoTrack("video", p.params.VideoResolution, p.params.VideoCodec, false, -1, -1, -1, -1)
+		}
+		if err != nil {
+			reason = fmt.Sprintf("publisher %d could not publish: %v", i+1, err)
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			reason = "canceled"
+		case <-time.After(p.params.StepInterval):
+		}
+
+		step := p.measure(monitor, len(publishers))
+		delta := &trackProbeStep{
+			publishers: step.publishers,
+			tracks:     step.tracks,
+			bytes:      step.bytes - last.bytes,
+			packets:    step.packets - last.packets,
+			dropped:    step.dropped - last.dropped,
+			elapsed:    p.params.StepInterval,
+		}
+		last = step
+		steps = append(steps, delta)
+
+		if reason == "" {
+			loss := float64(delta.dropped) / float64(max(delta.packets+delta.dropped, 1))
+			if loss > p.params.MaxPacketLoss {
+				reason = fmt.Sprintf("packet loss %s%% exceeded %s%%",
+					formatPercentage(delta.dropped, delta.packets+delta.dropped),
+					strconv.FormatFloat(p.params.MaxPacketLoss*100, 'f', -1, 64))
+			}
+		}
+	}
+	if reason == "" {
+		reason = fmt.Sprintf("reached the maximum of %d publishers", p.params.MaxPublishers)
+	}
+
+	table := util.CreateTable().
+		Headers("Publishers", "Tracks Received", "Bitrate", "Pkt. Loss")
+	ceiling := 0
+	for _, step := range steps {
+		table.Row(
+			strconv.Itoa(step.publishers),
+			strconv.Itoa(step.tracks),
+			formatBitrate(step.bytes, step.elapsed),
+			formatLossRate(step.packets, step.dropped),
+		)
+		if step.tracks == step.publishers {
+			ceiling = step.publishers
+		}
+	}
+	fmt.Println("\nTrack probe:")
+	fmt.Println(table)
+	fmt.Printf("Track ceiling: %d video tracks (stopped: %s)\n", ceiling, reason)
+	return nil
+}
+
+func (p *TrackProbe) measure(monitor *LoadTester, publishers int) *trackProbeStep {
+	step := &trackProbeStep{publishers: publishers}
+	for _, ts := range monitor.getStats().trackStats {
+		step.tracks++
+		step.bytes += ts.bytes.Load()
+		step.packets += ts.packets.Load()
+		step.dropped += ts.dropped.Load()
+	}
+	return step
+}
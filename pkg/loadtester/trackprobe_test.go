@@ -0,0 +1,33 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrackProbeUsagePolicy(t *testing.T) {
+	probe := NewTrackProbe(TrackProbeParams{TesterParams: TesterParams{URL: "wss://project.livekit.cloud"}})
+	require.Equal(t, DefaultTrackProbeMaxPublishers, probe.params.MaxPublishers)
+
+	probe = NewTrackProbe(TrackProbeParams{
+		MaxPublishers: 200,
+		TesterParams:  TesterParams{URL: "wss://project.livekit.cloud"},
+	})
+	require.ErrorContains(t, probe.Run(context.Background()), "acceptable use policy")
+}
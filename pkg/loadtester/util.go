@@ -17,6 +17,7 @@ package loadtester
 import (
 	"fmt"
 	"math/rand"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -59,3 +60,37 @@ func formatBitrate(bytes int64, elapsed time.Duration) string {
 		return fmt.Sprintf("%.1fmbps", bps/1000000)
 	}
 }
+
+func formatFirstFrame(ts *trackStats) string {
+	firstFrameAt := ts.firstFrameAt.Load()
+	if ts.publishedAt.IsZero() || firstFrameAt.IsZero() {
+		return " - "
+	}
+	return firstFrameAt.Sub(ts.publishedAt).Round(time.Millisecond).String()
+}
+
+// ParseBitrate parses a bitrate such as "500kbps", "10mbps" or "64000" into bits per second
+func ParseBitrate(str string) (int64, error) {
+	s := strings.ToLower(strings.TrimSpace(str))
+	multiplier := float64(1)
+	for _, unit := range []struct {
+		suffix     string
+		multiplier float64
+	}{
+		{"gbps", 1e9},
+		{"mbps", 1e6},
+		{"kbps", 1e3},
+		{"bps", 1},
+	} {
+		if strings.HasSuffix(s, unit.suffix) {
+			s = strings.TrimSuffix(s, unit.suffix)
+			multiplier = unit.multiplier
+			break
+		}
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid bitrate %q", str)
+	}
+	return int64(value * multiplier), nil
+}
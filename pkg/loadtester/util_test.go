@@ -0,0 +1,41 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBitrate(t *testing.T) {
+	for str, expected := range map[string]int64{
+		"64000":    64000,
+		"500kbps":  500_000,
+		"10mbps":   10_000_000,
+		"1.5Mbps":  1_500_000,
+		"2gbps":    2_000_000_000,
+		" 300bps ": 300,
+	} {
+		bps, err := ParseBitrate(str)
+		require.NoError(t, err, str)
+		require.Equal(t, expected, bps, str)
+	}
+
+	for _, invalid := range []string{"", "fast", "-1mbps", "mbps"} {
+		_, err := ParseBitrate(invalid)
+		require.Error(t, err, invalid)
+	}
+}
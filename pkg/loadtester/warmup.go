@@ -0,0 +1,66 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// warmUp waits out the warm-up period once every tester has joined, then clears the stats
+// collected so far, so that the connection storm of the ramp, like initial keyframe requests
+// and bandwidth probing, doesn't skew the results. Stats are kept when ctx is done first.
+func warmUp(ctx context.Context, warmup time.Duration, testers []*LoadTester) {
+	if warmup <= 0 {
+		return
+	}
+	fmt.Printf("Warming up for %s, stats are collected afterwards\n", warmup)
+	if !sleepUntil(ctx, time.Now().Add(warmup)) {
+		return
+	}
+	for _, t := range testers {
+		t.Reset()
+	}
+}
+
+// reset clears the stats of a track that is still being received. The time of its first frame
+// is kept, it measures the join rather than the steady state.
+func (ts *trackStats) reset(now time.Time) {
+	ts.startedAt.Store(now)
+	ts.packets.Store(0)
+	ts.bytes.Store(0)
+	ts.dropped.Store(0)
+	ts.concealedPackets.Store(0)
+	ts.concealmentEvents.Store(0)
+	ts.burstLoss.reset()
+	ts.mediaGaps.Store(0)
+	ts.longestGap.Store(0)
+	ts.decryptedFrames.Store(0)
+	ts.decryptFailures.Store(0)
+	ts.jitter.reset()
+	ts.freezes.Store(0)
+	ts.frozen.Store(0)
+	ts.longestFreeze.Store(0)
+	if m := ts.freezeMeter.Load(); m != nil {
+		m.reset(now)
+	}
+}
+
+// reset clears what the sent layer measured, for its bitrate to be measured from now on
+func (l *sentLayer) reset() {
+	l.bytes.Store(0)
+	l.duration.Store(0)
+}
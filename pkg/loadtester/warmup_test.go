@@ -0,0 +1,104 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newWarmupTester() (*LoadTester, *trackStats) {
+	tester := NewLoadTester(TesterParams{IdentityPrefix: "warmup", Role: RoleSubscriber, Subscribe: true})
+	ts := &trackStats{trackID: "TR_a", publishedAt: time.Now()}
+	ts.firstFrameAt.Store(time.Now())
+	ts.packets.Store(100)
+	ts.bytes.Store(10000)
+	ts.dropped.Store(5)
+	ts.burstLoss.record(2)
+	ts.jitter.record(time.Millisecond)
+	tester.stats.Store(ts.trackID, ts)
+	tester.latencies.add(50 * time.Millisecond)
+	tester.latencyHist.record(50 * time.Millisecond)
+	layer := &sentLayer{name: "f", target: 1_000_000}
+	layer.bytes.Store(1000)
+	layer.duration.Store(time.Second)
+	tester.sentLayers = append(tester.sentLayers, layer)
+	tester.speakerSwitches = append(tester.speakerSwitches, time.Second)
+	tester.layerSwitches = append(tester.layerSwitches, time.Second)
+	tester.reconnectAttempts, tester.reconnectFailures = 2, 1
+	tester.reconnects = append(tester.reconnects, &reconnectSample{})
+	tester.permissionUpdates.add(time.Second)
+	tester.dataSenders["pub"] = &dataSender{first: 1, highest: 10, received: 9, outOfOrder: 1}
+	tester.dataSent.Store(10)
+	tester.sentBytes.Store(1000)
+	tester.retries.Store(1)
+	return tester, ts
+}
+
+func TestLoadTesterReset(t *testing.T) {
+	tester, ts := newWarmupTester()
+	firstFrameAt := ts.firstFrameAt.Load()
+	tester.Reset()
+
+	value, _ := tester.stats.Load("TR_a")
+	require.Same(t, ts, value)
+	require.Zero(t, ts.packets.Load())
+	require.Zero(t, ts.bytes.Load())
+	require.Zero(t, ts.dropped.Load())
+	require.Zero(t, ts.burstLoss.total())
+	require.Zero(t, ts.jitter.percentiles().count)
+	require.Equal(t, firstFrameAt, ts.firstFrameAt.Load())
+	require.WithinDuration(t, time.Now(), ts.startedAt.Load(), time.Second)
+	require.Empty(t, tester.latencies.samples)
+	require.Zero(t, tester.latencyHist.percentiles().count)
+	require.Zero(t, tester.sentLayers[0].bitrate())
+
+	// the tester's counters are cleared too, but not how it joined
+	stats := tester.getStats()
+	require.Empty(t, stats.speakerSwitches)
+	require.Empty(t, stats.layerSwitches)
+	require.Zero(t, stats.reconnectAttempts)
+	require.Zero(t, stats.reconnectFailures)
+	require.Empty(t, stats.reconnects)
+	require.Zero(t, stats.permissionUpdateCount)
+	require.Equal(t, &dataStats{}, stats.data)
+	require.Zero(t, stats.dataSent)
+	require.Zero(t, tester.sentBytes.Load())
+	require.Equal(t, int64(1), stats.retries)
+
+	// data is expected from the next packet on
+	payload := make([]byte, dataHeaderSize)
+	payload[7] = 13
+	tester.onDataMessage("pub", payload)
+	require.Equal(t, &dataStats{received: 1, expected: 3}, tester.getStats().data)
+
+	// tracks keep counting after a reset
+	ts.packets.Inc()
+	require.Equal(t, int64(1), tester.Results().Packets)
+}
+
+func TestWarmUp(t *testing.T) {
+	tester, ts := newWarmupTester()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	warmUp(ctx, time.Minute, []*LoadTester{tester})
+	require.Equal(t, int64(100), ts.packets.Load())
+
+	warmUp(context.Background(), 10*time.Millisecond, []*LoadTester{tester})
+	require.Zero(t, ts.packets.Load())
+}
@@ -0,0 +1,217 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/webhook"
+
+	"github.com/livekit/livekit-cli/v2/pkg/util"
+)
+
+const DefaultWebhookWait = 10 * time.Second
+
+// room lifecycle events, in the order they are expected to arrive
+var webhookEvents = []string{
+	webhook.EventRoomStarted,
+	webhook.EventParticipantJoined,
+	webhook.EventTrackPublished,
+	webhook.EventTrackUnpublished,
+	webhook.EventParticipantLeft,
+	webhook.EventRoomFinished,
+}
+
+// WebhookRecord is a webhook received for one of the test rooms
+type WebhookRecord struct {
+	ID          string    `json:"id"`
+	Event       string    `json:"event"`
+	Room        string    `json:"room"`
+	Participant string    `json:"participant,omitempty"`
+	Track       string    `json:"track,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	ReceivedAt  time.Time `json:"receivedAt"`
+}
+
+// webhookCapture receives the webhooks the server sends during a run. The server must be
+// configured to send webhooks to the capture address; they are verified with the test's API key.
+type webhookCapture struct {
+	keys auth.KeyProvider
+
+	lock    sync.Mutex
+	records []*WebhookRecord
+	invalid int
+}
+
+func newWebhookCapture(apiKey, apiSecret string) *webhookCapture {
+	return &webhookCapture{
+		keys: auth.NewSimpleKeyProvider(apiKey, apiSecret),
+	}
+}
+
+func (c *webhookCapture) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	event, err := webhook.ReceiveWebhookEvent(r, c.keys)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if err != nil {
+		c.invalid++
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	record := &WebhookRecord{
+		ID:         event.Id,
+		Event:      event.Event,
+		Room:       event.Room.GetName(),
+		CreatedAt:  time.Unix(event.CreatedAt, 0),
+		ReceivedAt: time.Now(),
+	}
+	if event.Participant != nil {
+		record.Participant = event.Participant.Identity
+	}
+	if event.Track != nil {
+		record.Track = event.Track.Sid
+	}
+	c.records = append(c.records, record)
+}
+
+// forRooms returns the webhooks received for rooms
+func (c *webhookCapture) forRooms(rooms []string) []*WebhookRecord {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	var records []*WebhookRecord
+	for _, r := range c.records {
+		if slices.Contains(rooms, r.Room) {
+			records = append(records, r)
+		}
+	}
+	return records
+}
+
+func (t *LoadTest) serveWebhooks(addr string) (func(), error) {
+	if addr == "" {
+		return func() {}, nil
+	}
+
+	capture := newWebhookCapture(t.Params.APIKey, t.Params.APISecret)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	server := &http.Server{Handler: capture}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Println("webhook server stopped:", err)
+		}
+	}()
+	fmt.Printf("Capturing webhooks on %s\n", listener.Addr())
+
+	t.lock.Lock()
+	t.webhooks = capture
+	t.lock.Unlock()
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}, nil
+}
+
+// awaitWebhooks leaves time for the server to send the webhooks of testers that just left
+func (t *LoadTest) awaitWebhooks() {
+	if t.webhooks == nil || t.Params.WebhookWait <= 0 {
+		return
+	}
+	fmt.Printf("Waiting %s for remaining webhooks\n", t.Params.WebhookWait)
+	time.Sleep(t.Params.WebhookWait)
+}
+
+type webhookCount struct {
+	received   int
+	duplicates int
+	expected   int
+}
+
+// countWebhooks compares the webhooks received to those expected for the rooms, connected testers
+// and published tracks of the run. room_finished is only expected once the rooms are empty.
+func countWebhooks(records []*WebhookRecord, rooms, testers, tracks int) map[string]*webhookCount {
+	counts := map[string]*webhookCount{
+		webhook.EventRoomStarted:       {expected: rooms},
+		webhook.EventParticipantJoined: {expected: testers},
+		webhook.EventTrackPublished:    {expected: tracks},
+		webhook.EventTrackUnpublished:  {expected: tracks},
+		webhook.EventParticipantLeft:   {expected: testers},
+		webhook.EventRoomFinished:      {expected: rooms},
+	}
+	seen := make(map[string]bool)
+	for _, r := range records {
+		c := counts[r.Event]
+		if c == nil {
+			c = &webhookCount{}
+			counts[r.Event] = c
+		}
+		if r.ID != "" && seen[r.ID] {
+			c.duplicates++
+			continue
+		}
+		seen[r.ID] = true
+		c.received++
+	}
+	return counts
+}
+
+func (t *LoadTest) printWebhooks() {
+	if t.webhooks == nil {
+		return
+	}
+	records := t.webhooks.forRooms(t.roomNames)
+	t.status.lock.Lock()
+	connected := len(t.status.testers) - t.status.connectErrs
+	t.status.lock.Unlock()
+	counts := countWebhooks(records, len(t.roomNames), connected, len(t.trackNames))
+
+	table := util.CreateTable().
+		Headers("Event", "Received", "Expected", "Duplicates")
+	var others []string
+	for event := range counts {
+		if !slices.Contains(webhookEvents, event) {
+			others = append(others, event)
+		}
+	}
+	slices.Sort(others)
+	for _, event := range append(slices.Clone(webhookEvents), others...) {
+		c := counts[event]
+		expected := "-"
+		if slices.Contains(webhookEvents, event) {
+			expected = fmt.Sprint(c.expected)
+		}
+		table.Row(event, fmt.Sprint(c.received), expected, fmt.Sprint(c.duplicates))
+	}
+	fmt.Printf("\nWebhooks (%d received", len(records))
+	t.webhooks.lock.Lock()
+	if t.webhooks.invalid > 0 {
+		fmt.Printf(", %d failed verification", t.webhooks.invalid)
+	}
+	t.webhooks.lock.Unlock()
+	fmt.Println("):")
+	fmt.Println(table)
+}
@@ -0,0 +1,80 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package loadtester
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/livekit/protocol/auth"
+	"github.com/livekit/protocol/livekit"
+	"github.com/livekit/protocol/webhook"
+)
+
+func signedWebhook(t *testing.T, secret string, event *livekit.WebhookEvent) *http.Request {
+	body, err := protojson.Marshal(event)
+	require.NoError(t, err)
+	sha := sha256.Sum256(body)
+	token, err := auth.NewAccessToken("key", secret).
+		SetSha256(base64.StdEncoding.EncodeToString(sha[:])).
+		ToJWT()
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(body))
+	req.Header.Set("Authorization", token)
+	return req
+}
+
+func TestWebhookCapture(t *testing.T) {
+	capture := newWebhookCapture("key", "secret")
+	send := func(secret string, event *livekit.WebhookEvent) int {
+		w := httptest.NewRecorder()
+		capture.ServeHTTP(w, signedWebhook(t, secret, event))
+		return w.Code
+	}
+
+	joined := &livekit.WebhookEvent{
+		Id:          "EV_1",
+		Event:       webhook.EventParticipantJoined,
+		Room:        &livekit.Room{Name: "test_0"},
+		Participant: &livekit.ParticipantInfo{Identity: "abc_sub-1"},
+	}
+	require.Equal(t, http.StatusOK, send("secret", joined))
+	// retried delivery of the same event
+	require.Equal(t, http.StatusOK, send("secret", joined))
+	require.Equal(t, http.StatusOK, send("secret", &livekit.WebhookEvent{
+		Id:    "EV_2",
+		Event: webhook.EventRoomStarted,
+		Room:  &livekit.Room{Name: "other"},
+	}))
+	require.Equal(t, http.StatusUnauthorized, send("wrong", joined))
+
+	records := capture.forRooms([]string{"test_0"})
+	require.Len(t, records, 2)
+	require.Equal(t, "abc_sub-1", records[0].Participant)
+	require.Equal(t, 1, capture.invalid)
+
+	counts := countWebhooks(records, 1, 2, 0)
+	require.Equal(t, 1, counts[webhook.EventParticipantJoined].received)
+	require.Equal(t, 1, counts[webhook.EventParticipantJoined].duplicates)
+	require.Equal(t, 2, counts[webhook.EventParticipantJoined].expected)
+	require.Equal(t, 0, counts[webhook.EventRoomStarted].received)
+}
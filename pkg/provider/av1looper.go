@@ -0,0 +1,140 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"time"
+
+	"github.com/pion/rtp/codecs/av1/obu"
+	"github.com/pion/webrtc/v4"
+	"github.com/pion/webrtc/v4/pkg/media"
+
+	"github.com/livekit/protocol/livekit"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+// AV1VideoLooper loops an IVF file of AV1 temporal units, each a sequence of OBUs with size fields
+type AV1VideoLooper struct {
+	lksdk.BaseSampleProvider
+	buffer        []byte
+	frameDuration time.Duration
+	spec          *videoSpec
+	// temporal units of buffer, indexed on first use, and the next one to send
+	frames [][]byte
+	next   int
+	err    error
+}
+
+func NewAV1VideoLooper(input io.Reader, spec *videoSpec) (*AV1VideoLooper, error) {
+	buf := bytes.NewBuffer(nil)
+	if _, err := io.Copy(buf, input); err != nil {
+		return nil, err
+	}
+	return newAV1VideoLooper(buf.Bytes(), spec), nil
+}
+
+// newAV1VideoLooper loops over buffer without copying it
+func newAV1VideoLooper(buffer []byte, spec *videoSpec) *AV1VideoLooper {
+	return &AV1VideoLooper{
+		buffer:        buffer,
+		spec:          spec,
+		frameDuration: time.Second / time.Duration(spec.fps),
+	}
+}
+
+func (l *AV1VideoLooper) Codec() webrtc.RTPCodecCapability {
+	return webrtc.RTPCodecCapability{
+		MimeType:  webrtc.MimeTypeAV1,
+		ClockRate: 90000,
+		RTCPFeedback: []webrtc.RTCPFeedback{
+			{Type: webrtc.TypeRTCPFBNACK},
+			{Type: webrtc.TypeRTCPFBNACK, Parameter: "pli"},
+		},
+	}
+}
+
+func (l *AV1VideoLooper) NextSample(_ context.Context) (media.Sample, error) {
+	return l.nextSample()
+}
+
+func (l *AV1VideoLooper) ToLayer(quality livekit.VideoQuality) *livekit.VideoLayer {
+	return l.spec.ToVideoLayer(quality)
+}
+
+func (l *AV1VideoLooper) index() error {
+	if l.frames == nil && l.err == nil {
+		if l.frames, l.err = splitIVF(l.buffer); l.err == nil && len(l.frames) == 0 {
+			l.err = io.EOF
+		}
+	}
+	return l.err
+}
+
+func (l *AV1VideoLooper) nextSample() (media.Sample, error) {
+	sample := media.Sample{}
+	if err := l.index(); err != nil {
+		return sample, err
+	}
+	if l.next >= len(l.frames) {
+		l.next = 0
+	}
+	sample.Data = l.frames[l.next]
+	l.next++
+	sample.Duration = l.frameDuration
+	return sample, nil
+}
+
+// SeekToKeyframeAfter makes the looper start at the first keyframe after the given
+// fraction (0-1) of the file, or at the beginning if there is none. Later loops start
+// from the beginning as usual.
+func (l *AV1VideoLooper) SeekToKeyframeAfter(fraction float64) {
+	if l.index() != nil {
+		return
+	}
+	var keyframes []int
+	for i, frame := range l.frames {
+		if isAV1Keyframe(frame) {
+			keyframes = append(keyframes, i)
+		}
+	}
+	l.next = firstAtOrAfter(keyframes, int(fraction*float64(len(l.frames))))
+}
+
+// isAV1Keyframe reports whether a temporal unit carries a sequence header, which encoders
+// emit with every keyframe to make it a random access point
+func isAV1Keyframe(frame []byte) bool {
+	for offset := 0; offset < len(frame); {
+		header, err := obu.ParseOBUHeader(frame[offset:])
+		if err != nil {
+			return false
+		}
+		if header.Type == obu.OBUSequenceHeader {
+			return true
+		}
+		if !header.HasSizeField {
+			return false
+		}
+		offset += header.Size()
+		size, n, err := obu.ReadLeb128(frame[offset:])
+		if err != nil {
+			return false
+		}
+		offset += int(n) + int(size)
+	}
+	return false
+}
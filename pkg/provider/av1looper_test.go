@@ -0,0 +1,31 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsAV1Keyframe(t *testing.T) {
+	temporalDelimiter := []byte{0x12, 0x00}
+	sequenceHeader := []byte{0x0a, 0x02, 0xaa, 0xbb}
+	frame := []byte{0x32, 0x03, 0x01, 0x02, 0x03}
+
+	require.True(t, isAV1Keyframe(append(append(append([]byte{}, temporalDelimiter...), sequenceHeader...), frame...)))
+	require.False(t, isAV1Keyframe(append(append([]byte{}, temporalDelimiter...), frame...)))
+	require.False(t, isAV1Keyframe(nil))
+}
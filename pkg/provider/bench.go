@@ -0,0 +1,120 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"runtime"
+	"strings"
+	"time"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+// samples without duration in a row before a provider is considered stuck, H.264 parameter
+// sets come without one
+const maxSamplesWithoutDuration = 1000
+
+// BenchmarkResult is how fast a sample provider produced media on this machine
+type BenchmarkResult struct {
+	Name    string
+	Samples int
+	Bytes   int64
+	// media time the samples cover, and how long producing them took
+	Media   time.Duration
+	Elapsed time.Duration
+	Allocs  uint64
+}
+
+// Realtime is how many times faster than real time the provider ran, roughly the number of
+// publishers of it that one core keeps fed
+func (r *BenchmarkResult) Realtime() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Media) / float64(r.Elapsed)
+}
+
+func (r *BenchmarkResult) AllocsPerSample() float64 {
+	if r.Samples == 0 {
+		return 0
+	}
+	return float64(r.Allocs) / float64(r.Samples)
+}
+
+// Benchmark takes samples from p as fast as it can, until they cover the given media time
+func Benchmark(ctx context.Context, name string, p lksdk.SampleProvider, media time.Duration) (*BenchmarkResult, error) {
+	res := &BenchmarkResult{Name: name}
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	withoutDuration := 0
+	for res.Media < media && ctx.Err() == nil {
+		sample, err := p.NextSample(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		res.Samples++
+		res.Bytes += int64(len(sample.Data))
+		res.Media += sample.Duration
+		if withoutDuration++; sample.Duration > 0 {
+			withoutDuration = 0
+		} else if withoutDuration > maxSamplesWithoutDuration {
+			return nil, fmt.Errorf("%s produces no media time", name)
+		}
+	}
+	res.Elapsed = time.Since(start)
+	runtime.ReadMemStats(&after)
+	res.Allocs = after.Mallocs - before.Mallocs
+	return res, ctx.Err()
+}
+
+// BenchmarkEmbedded benchmarks the highest quality of every embedded video clip, the embedded
+// speech, and generated audio
+func BenchmarkEmbedded(ctx context.Context, media time.Duration) ([]*BenchmarkResult, error) {
+	type candidate struct {
+		name     string
+		provider lksdk.SampleProvider
+	}
+	var candidates []candidate
+	for _, specs := range videoSpecs {
+		spec := specs[len(specs)-1]
+		looper, err := openEmbeddedLooper(spec.Name(), spec)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, candidate{strings.TrimPrefix(spec.Name(), "resources/"), looper})
+	}
+	speech, err := readEmbedded(path.Join("resources", audioNames[0]+".ogg"))
+	if err != nil {
+		return nil, err
+	}
+	candidates = append(candidates,
+		candidate{audioNames[0] + ".ogg", newOpusAudioLooper(speech)},
+		candidate{"generated stereo opus", NewOpusGenerator(2)},
+	)
+
+	results := make([]*BenchmarkResult, 0, len(candidates))
+	for _, c := range candidates {
+		res, err := Benchmark(ctx, c.name, c.provider, media)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pion/webrtc/v4/pkg/media"
+	"github.com/stretchr/testify/require"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+type stuckProvider struct {
+	lksdk.BaseSampleProvider
+}
+
+func (p *stuckProvider) NextSample(_ context.Context) (media.Sample, error) {
+	return media.Sample{Data: []byte{0}}, nil
+}
+
+func TestBenchmark(t *testing.T) {
+	res, err := Benchmark(context.Background(), "opus", NewOpusGenerator(1), time.Second)
+	require.NoError(t, err)
+	require.Equal(t, 50, res.Samples)
+	require.Equal(t, time.Second, res.Media)
+	require.Greater(t, res.Realtime(), 1.0)
+	require.Less(t, res.AllocsPerSample(), 1.0)
+
+	// parameter sets have no duration, frames do
+	looper := newH264VideoLooper(writeAnnexB(9, 50), circlesSpec(180, 200, 10))
+	res, err = Benchmark(context.Background(), "h264", looper, time.Second)
+	require.NoError(t, err)
+	require.Equal(t, 12, res.Samples)
+
+	_, err = Benchmark(context.Background(), "stuck", &stuckProvider{}, time.Second)
+	require.EqualError(t, err, "stuck produces no media time")
+}
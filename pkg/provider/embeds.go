@@ -17,8 +17,12 @@ package provider
 import (
 	"embed"
 	"fmt"
+	"io/fs"
 	"math"
+	"path"
 	"strconv"
+	"strings"
+	"sync"
 
 	"go.uber.org/atomic"
 
@@ -29,6 +33,7 @@ const (
 	h264Codec = "h264"
 	vp8Codec  = "vp8"
 	vp9Codec  = "vp9"
+	av1Codec  = "av1"
 )
 
 type videoSpec struct {
@@ -42,7 +47,7 @@ type videoSpec struct {
 
 func (v *videoSpec) Name() string {
 	ext := "h264"
-	if v.codec == vp8Codec {
+	if v.codec != h264Codec {
 		ext = "ivf"
 	}
 	size := strconv.Itoa(v.height)
@@ -129,30 +134,41 @@ func init() {
 	}
 }
 
-func randomVideoSpecsForCodec(videoCodec string) []*videoSpec {
+// CheckEmbeddedCodec checks that there are embedded clips for videoCodec, any codec when empty
+func CheckEmbeddedCodec(videoCodec string) error {
+	if len(embeddedSpecs(videoCodec)) == 0 {
+		return fmt.Errorf("no embedded %s videos, publish your own with --video-file", videoCodec)
+	}
+	return nil
+}
+
+func embeddedSpecs(videoCodec string) [][]*videoSpec {
 	filtered := make([][]*videoSpec, 0)
 	for _, specs := range videoSpecs {
 		if videoCodec == "" || specs[0].codec == videoCodec {
 			filtered = append(filtered, specs)
 		}
 	}
+	return filtered
+}
+
+func randomVideoSpecsForCodec(videoCodec string) ([]*videoSpec, error) {
+	if err := CheckEmbeddedCodec(videoCodec); err != nil {
+		return nil, err
+	}
+	filtered := embeddedSpecs(videoCodec)
 	chosen := int(videoIndex.Inc()) % len(filtered)
-	return filtered[chosen]
+	return filtered[chosen], nil
 }
 
 func CreateVideoLoopers(resolution string, codecFilter string, simulcast bool, isFairproc bool, videoWidth int, videoHeight int, frameRate int, birate int) ([]VideoLooper, error) {
 	var specs []*videoSpec
 	if !isFairproc {
-		specs = randomVideoSpecsForCodec(codecFilter)
-		numToKeep := 0
-		switch resolution {
-		case "medium":
-			numToKeep = 2
-		case "low":
-			numToKeep = 1
-		default:
-			numToKeep = 3
+		var err error
+		if specs, err = randomVideoSpecsForCodec(codecFilter); err != nil {
+			return nil, err
 		}
+		numToKeep := numLayers(resolution)
 		specs = specs[:numToKeep]
 		if !simulcast {
 			specs = specs[numToKeep-1:]
@@ -160,51 +176,110 @@ func CreateVideoLoopers(resolution string, codecFilter string, simulcast bool, i
 	} else {
 		specs = make([]*videoSpec, 0)
 		specs = append(specs, &videoSpec{
-			prefix: "butterfly",
+			prefix: fairprocClip,
 			codec:  codecFilter,
 			kbps:   birate,
 			fps:    frameRate,
 			height: videoHeight,
-			width:  videoHeight,
+			width:  videoWidth,
 		})
 	}
 	loopers := make([]VideoLooper, 0)
 	for _, spec := range specs {
-		f, err := res.Open(spec.Name())
+		looper, err := openEmbeddedLooper(spec.Name(), spec)
 		if err != nil {
 			return nil, err
 		}
-		defer f.Close()
-		if spec.codec == h264Codec {
-			looper, err := NewH264VideoLooper(f, spec)
-			if err != nil {
-				return nil, err
-			}
-			loopers = append(loopers, looper)
-		} else if spec.codec == vp8Codec {
-			looper, err := NewVPVideoLooper(f, spec, false)
-			if err != nil {
-				return nil, err
-			}
-			loopers = append(loopers, looper)
-		} else if spec.codec == vp9Codec {
-			looper, err := NewVPVideoLooper(f, spec, true)
-			if err != nil {
-				return nil, err
-			}
+		if looper != nil {
 			loopers = append(loopers, looper)
 		}
 	}
 	return loopers, nil
 }
 
+// fairproc video is published from the h264 encodings of this clip
+const fairprocClip = "butterfly"
+
+// CheckFairprocClip checks that a clip of the given size and bitrate is embedded for fairproc video
+func CheckFairprocClip(width, height, kbps int) error {
+	spec := &videoSpec{prefix: fairprocClip, codec: h264Codec, width: width, height: height, kbps: kbps}
+	if _, err := fs.Stat(res, spec.Name()); err == nil {
+		return nil
+	}
+	var available []string
+	files, _ := fs.Glob(res, fmt.Sprintf("resources/%s_*.h264", fairprocClip))
+	for _, file := range files {
+		var clipHeight, clipKbps int
+		if _, err := fmt.Sscanf(path.Base(file), fairprocClip+"_%d_%d.h264", &clipHeight, &clipKbps); err == nil {
+			available = append(available, fmt.Sprintf("%dp at %dkbps", clipHeight, clipKbps))
+		}
+	}
+	return fmt.Errorf("no embedded clip for %dx%d video at %dkbps, fairproc video must be one of %s",
+		width, height, kbps, strings.Join(available, ", "))
+}
+
+// CreateScreenShareLooper returns a looper of the highest quality embedded clip for codecFilter,
+// sent at the low frame rate of a typical screen share
+func CreateScreenShareLooper(codecFilter string) (VideoLooper, error) {
+	specs, err := randomVideoSpecsForCodec(codecFilter)
+	if err != nil {
+		return nil, err
+	}
+	clip := specs[len(specs)-1]
+	spec := *clip
+	spec.fps = ScreenShareFPS
+	spec.kbps = clip.kbps * ScreenShareFPS / clip.fps
+	return openEmbeddedLooper(clip.Name(), &spec)
+}
+
+// ScreenShareFPS is the frame rate screen shares are sent at
+const ScreenShareFPS = 5
+
+var (
+	embeddedLock sync.Mutex
+	embeddedData = make(map[string][]byte)
+)
+
+// readEmbedded reads an embedded file once, so that every looper of a clip shares its data
+// instead of holding its own copy
+func readEmbedded(name string) ([]byte, error) {
+	embeddedLock.Lock()
+	defer embeddedLock.Unlock()
+	if data, ok := embeddedData[name]; ok {
+		return data, nil
+	}
+	data, err := res.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	embeddedData[name] = data
+	return data, nil
+}
+
+func openEmbeddedLooper(name string, spec *videoSpec) (VideoLooper, error) {
+	data, err := readEmbedded(name)
+	if err != nil {
+		return nil, err
+	}
+	switch spec.codec {
+	case h264Codec:
+		return newH264VideoLooper(data, spec), nil
+	case vp8Codec:
+		return newVPVideoLooper(data, spec, false), nil
+	case vp9Codec:
+		return newVPVideoLooper(data, spec, true), nil
+	case av1Codec:
+		return newAV1VideoLooper(data, spec), nil
+	}
+	return nil, nil
+}
+
 func CreateAudioLooper() (*OpusAudioLooper, error) {
 	chosenName := audioNames[int(audioIndex.Load())%len(audioNames)]
 	audioIndex.Inc()
-	f, err := res.Open(fmt.Sprintf("resources/%s.ogg", chosenName))
+	data, err := readEmbedded(fmt.Sprintf("resources/%s.ogg", chosenName))
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
-	return NewOpusAudioLooper(f)
+	return newOpusAudioLooper(data), nil
 }
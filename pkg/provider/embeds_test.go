@@ -0,0 +1,68 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+func TestCreateScreenShareLooper(t *testing.T) {
+	looper, err := CreateScreenShareLooper(h264Codec)
+	require.NoError(t, err)
+	layer := looper.ToLayer(livekit.VideoQuality_HIGH)
+	require.EqualValues(t, 720, layer.Height)
+	require.Less(t, layer.Bitrate, uint32(500_000))
+	require.Equal(t, time.Second/ScreenShareFPS, looper.(*H264VideoLooper).frameDuration)
+
+	looper, err = CreateScreenShareLooper(vp8Codec)
+	require.NoError(t, err)
+	require.EqualValues(t, 1280, looper.ToLayer(livekit.VideoQuality_HIGH).Width)
+	require.Equal(t, time.Second/ScreenShareFPS, looper.(*VPVideoLooper).frameDuration)
+
+	_, err = CreateScreenShareLooper("unknown")
+	require.Error(t, err)
+}
+
+func TestCheckFairprocClip(t *testing.T) {
+	require.NoError(t, CheckFairprocClip(320, 180, 150))
+	require.NoError(t, CheckFairprocClip(1280, 720, 2000))
+
+	err := CheckFairprocClip(300, 200, 29)
+	require.ErrorContains(t, err, "300x200 video at 29kbps")
+	require.ErrorContains(t, err, "180p at 150kbps, 360p at 400kbps, 720p at 2000kbps")
+}
+
+func TestCreateFairprocLooper(t *testing.T) {
+	loopers, err := CreateVideoLoopers("high", h264Codec, false, true, 640, 360, 15, 400)
+	require.NoError(t, err)
+	require.Len(t, loopers, 1)
+	layer := loopers[0].ToLayer(livekit.VideoQuality_HIGH)
+	require.EqualValues(t, 640, layer.Width)
+	require.EqualValues(t, 360, layer.Height)
+}
+
+func TestCheckEmbeddedCodec(t *testing.T) {
+	for _, codec := range []string{"", h264Codec, vp8Codec} {
+		require.NoError(t, CheckEmbeddedCodec(codec), codec)
+	}
+	for _, codec := range []string{vp9Codec, av1Codec} {
+		require.ErrorContains(t, CheckEmbeddedCodec(codec), "--video-file", codec)
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/pion/webrtc/v4/pkg/media/ivfreader"
+	"github.com/pion/webrtc/v4/pkg/media/oggreader"
+)
+
+const maxFileFPS = 120
+
+// CreateVideoLoopersFromFiles builds loopers from VP8, VP9 or AV1 IVF files, H.264 MP4 or Annex B
+// files, or raw Y4M files, given lowest quality first. Y4M files are encoded to codec, VP8 or VP9
+// (VP8 when empty), which needs a build with libvpx; codec is ignored for other files.
+// Files are memory-mapped and shared by all loopers, so large files can be published many times.
+// Like CreateVideoLoopers, resolution decides how many tiers are used, and only the
+// highest of those is kept when simulcast is off.
+func CreateVideoLoopersFromFiles(paths []string, resolution, codec string, simulcast bool) ([]VideoLooper, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no video files")
+	}
+	if len(paths) > 3 {
+		return nil, fmt.Errorf("at most 3 video files can be used, got %d", len(paths))
+	}
+
+	numToKeep := min(numLayers(resolution), len(paths))
+	paths = paths[:numToKeep]
+	if !simulcast {
+		paths = paths[numToKeep-1:]
+	}
+
+	loopers := make([]VideoLooper, 0, len(paths))
+	fileCodec := ""
+	for _, path := range paths {
+		file, err := openMapped(path)
+		if err != nil {
+			return nil, err
+		}
+		if err = file.probe(path); err != nil {
+			return nil, err
+		}
+
+		var looper VideoLooper
+		switch {
+		case file.y4m != nil:
+			if looper, err = newY4MVideoLooper(file.y4m, codec); err != nil {
+				return nil, err
+			}
+		case file.h264 != nil:
+			looper = newH264FileLooper(file.h264)
+		case file.spec.codec == av1Codec:
+			looper = newAV1VideoLooper(file.data, file.spec)
+		default:
+			looper = newVPVideoLooper(file.data, file.spec, file.spec.codec == vp9Codec)
+		}
+		// Y4M files take the codec they are encoded to
+		looperCodec := strings.TrimPrefix(strings.ToLower(looper.Codec().MimeType), "video/")
+		if fileCodec != "" && looperCodec != fileCodec {
+			return nil, fmt.Errorf("%s is %s, but other video files are %s", path, looperCodec, fileCodec)
+		}
+		fileCodec = looperCodec
+		loopers = append(loopers, looper)
+	}
+	return loopers, nil
+}
+
+// CreateAudioLooperFromFile builds a looper from an Ogg Opus file
+func CreateAudioLooperFromFile(path string) (*OpusAudioLooper, error) {
+	file, err := openMapped(path)
+	if err != nil {
+		return nil, err
+	}
+	// validates the Opus ID header
+	if _, _, err = oggreader.NewWith(bytes.NewReader(file.data)); err != nil {
+		return nil, fmt.Errorf("%s is not an Ogg Opus file: %w", path, err)
+	}
+	return newOpusAudioLooper(file.data), nil
+}
+
+func numLayers(resolution string) int {
+	switch resolution {
+	case "medium":
+		return 2
+	case "low":
+		return 1
+	default:
+		return 3
+	}
+}
+
+// isIVF reports whether data starts with an IVF signature
+func isIVF(data []byte) bool {
+	return bytes.HasPrefix(data, []byte("DKIF"))
+}
+
+// probeIVF reads codec and dimensions from the IVF header, and frame rate and bitrate from the frames
+func probeIVF(path string, data []byte) (*videoSpec, error) {
+	reader, header, err := ivfreader.NewWith(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s is not an IVF file: %w", path, err)
+	}
+
+	spec := &videoSpec{
+		prefix: path,
+		width:  int(header.Width),
+		height: int(header.Height),
+	}
+	switch header.FourCC {
+	case "VP80":
+		spec.codec = vp8Codec
+	case "VP90":
+		spec.codec = vp9Codec
+	case "AV01":
+		spec.codec = av1Codec
+	default:
+		return nil, fmt.Errorf("%s has unsupported codec %q, expected VP80, VP90 or AV01", path, header.FourCC)
+	}
+	if header.TimebaseDenominator == 0 || header.TimebaseNumerator == 0 {
+		return nil, fmt.Errorf("%s has an invalid timebase", path)
+	}
+	timebase := float64(header.TimebaseNumerator) / float64(header.TimebaseDenominator)
+
+	var frames, size int
+	var first, last uint64
+	for {
+		frame, frameHeader, err := reader.ParseNextFrame()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s: %w", path, err)
+		}
+		// ivfreader scales timestamps by the inverse of the timebase, undo that to get the pts
+		pts := frameHeader.Timestamp * uint64(header.TimebaseNumerator) / uint64(header.TimebaseDenominator)
+		if frames == 0 {
+			first = pts
+		}
+		last = pts
+		frames++
+		size += len(frame)
+	}
+	if frames < 2 || last <= first {
+		return nil, fmt.Errorf("%s has too few frames", path)
+	}
+
+	// the last frame lasts as long as the average frame
+	duration := float64(last-first) * timebase * float64(frames) / float64(frames-1)
+	spec.fps = int(math.Round(float64(frames) / duration))
+	if spec.fps < 1 || spec.fps > maxFileFPS {
+		return nil, fmt.Errorf("%s has unsupported frame rate %d, expected 1-%d fps", path, spec.fps, maxFileFPS)
+	}
+	spec.kbps = int(float64(size*8) / duration / 1000)
+	return spec, nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+)
+
+// Loopers index their files once and hand out slices of the shared buffer, instead of parsing
+// each frame with the pion readers, which read a byte at a time and allocate every frame. The
+// slices are capped at their length, so that appending to a sample can't overwrite the next one.
+
+const (
+	ivfFileHeaderSize  = 32
+	ivfFrameHeaderSize = 12
+
+	oggPageHeaderSize = 27
+
+	nalUnitTypeSEI = 6
+)
+
+var (
+	errNotIVF     = errors.New("not an IVF file")
+	errNotAnnexB  = errors.New("not an H.264 Annex B stream")
+	errNotOgg     = errors.New("not an Ogg file")
+	annexBStart   = []byte{0, 0, 1}
+	oggPageMarker = []byte("OggS")
+	opusHead      = []byte("OpusHead")
+)
+
+// splitIVF returns the frames of an IVF file, leaving out a truncated last frame
+func splitIVF(buffer []byte) ([][]byte, error) {
+	if len(buffer) < ivfFileHeaderSize || !bytes.HasPrefix(buffer, []byte("DKIF")) {
+		return nil, errNotIVF
+	}
+	var frames [][]byte
+	offset := ivfFileHeaderSize
+	for offset+ivfFrameHeaderSize <= len(buffer) {
+		size := int(binary.LittleEndian.Uint32(buffer[offset:]))
+		offset += ivfFrameHeaderSize
+		if size > len(buffer)-offset {
+			break
+		}
+		frames = append(frames, buffer[offset:offset+size:offset+size])
+		offset += size
+	}
+	return frames, nil
+}
+
+// splitAnnexB returns the NAL units of an H.264 stream without their start codes, leaving
+// out SEI units like h264reader does
+func splitAnnexB(buffer []byte) ([][]byte, error) {
+	var nals [][]byte
+	start := -1
+	emit := func(end int) {
+		if start < 0 || end <= start || buffer[start]&0x1f == nalUnitTypeSEI {
+			return
+		}
+		nals = append(nals, buffer[start:end:end])
+	}
+	for offset := 0; offset < len(buffer); {
+		// bytes.Index is vectorized, on arm64 as well
+		i := bytes.Index(buffer[offset:], annexBStart)
+		if i < 0 {
+			break
+		}
+		i += offset
+		if start < 0 && len(bytes.Trim(buffer[:i], "\x00")) > 0 {
+			return nil, errNotAnnexB
+		}
+		end := i
+		// the zero byte of a four byte start code
+		if end > 0 && buffer[end-1] == 0 {
+			end--
+		}
+		emit(end)
+		start = i + len(annexBStart)
+		offset = start
+	}
+	emit(len(buffer))
+	if len(nals) == 0 {
+		return nil, errNotAnnexB
+	}
+	return nals, nil
+}
+
+type oggPage struct {
+	data    []byte
+	granule uint64
+}
+
+// splitOggPages returns the audio pages of an Ogg Opus file, leaving out the ID and comment
+// headers and a truncated last page
+func splitOggPages(buffer []byte) ([]oggPage, error) {
+	if !bytes.HasPrefix(buffer, oggPageMarker) {
+		return nil, errNotOgg
+	}
+	var pages []oggPage
+	offset := 0
+	for offset+oggPageHeaderSize <= len(buffer) && bytes.HasPrefix(buffer[offset:], oggPageMarker) {
+		granule := binary.LittleEndian.Uint64(buffer[offset+6:])
+		segments := int(buffer[offset+26])
+		offset += oggPageHeaderSize
+		if segments > len(buffer)-offset {
+			break
+		}
+		size := 0
+		for _, s := range buffer[offset : offset+segments] {
+			size += int(s)
+		}
+		offset += segments
+		if size > len(buffer)-offset {
+			break
+		}
+		data := buffer[offset : offset+size : offset+size]
+		offset += size
+		if bytes.HasPrefix(data, opusHead) || bytes.HasPrefix(data, opusTags) {
+			continue
+		}
+		pages = append(pages, oggPage{data: data, granule: granule})
+	}
+	return pages, nil
+}
@@ -0,0 +1,223 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/pion/webrtc/v4"
+	"github.com/pion/webrtc/v4/pkg/media/h264reader"
+	"github.com/pion/webrtc/v4/pkg/media/ivfreader"
+	"github.com/pion/webrtc/v4/pkg/media/oggreader"
+	"github.com/stretchr/testify/require"
+)
+
+// payload is size bytes without any zeros, so it never contains a start code
+func payload(header byte, size int) []byte {
+	data := make([]byte, size)
+	data[0] = header
+	for i := 1; i < size; i++ {
+		data[i] = byte(0x80 | i*31)
+	}
+	return data
+}
+
+// writeAnnexB writes a GOP of SPS, PPS, SEI and frame NAL units, mixing three and four byte start codes
+func writeAnnexB(frames, frameSize int) []byte {
+	var buf bytes.Buffer
+	for _, nal := range [][]byte{payload(0x67, 12), payload(0x68, 4), payload(0x06, 20)} {
+		buf.Write([]byte{0, 0, 0, 1})
+		buf.Write(nal)
+	}
+	for i := 0; i < frames; i++ {
+		header := byte(0x41)
+		if i == 0 {
+			header = 0x65
+		}
+		buf.Write([]byte{0, 0, 0, 1}[i%2:])
+		buf.Write(payload(header, frameSize))
+	}
+	// trailing zeros before the last start code
+	buf.Write([]byte{0, 0, 0, 0, 1})
+	buf.Write(payload(0x41, frameSize))
+	return buf.Bytes()
+}
+
+// writeIVF writes frames to a VP8 IVF file
+func writeIVF(frames [][]byte) []byte {
+	header := make([]byte, ivfFileHeaderSize)
+	copy(header, "DKIF")
+	binary.LittleEndian.PutUint16(header[6:], ivfFileHeaderSize)
+	copy(header[8:], "VP80")
+	binary.LittleEndian.PutUint32(header[16:], 30)
+	binary.LittleEndian.PutUint32(header[20:], 1)
+	binary.LittleEndian.PutUint32(header[24:], uint32(len(frames)))
+	buf := bytes.NewBuffer(header)
+	for i, frame := range frames {
+		frameHeader := make([]byte, ivfFrameHeaderSize)
+		binary.LittleEndian.PutUint32(frameHeader, uint32(len(frame)))
+		binary.LittleEndian.PutUint64(frameHeader[4:], uint64(i))
+		buf.Write(frameHeader)
+		buf.Write(frame)
+	}
+	return buf.Bytes()
+}
+
+func TestSplitMatchesReaders(t *testing.T) {
+	stream := writeAnnexB(10, 50)
+	reader, err := h264reader.NewReader(bytes.NewReader(stream))
+	require.NoError(t, err)
+	var nals [][]byte
+	for {
+		nal, err := reader.NextNAL()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		nals = append(nals, nal.Data)
+	}
+	split, err := splitAnnexB(stream)
+	require.NoError(t, err)
+	require.Equal(t, nals, split)
+	// SEI units are left out
+	require.Len(t, split, 13)
+	require.Equal(t, len(split[0]), cap(split[0]))
+
+	file := writeIVF([][]byte{payload(0x10, 100), payload(0x11, 30), {}, payload(0x11, 40)})
+	ivf, _, err := ivfreader.NewWith(bytes.NewReader(file))
+	require.NoError(t, err)
+	var frames [][]byte
+	for {
+		frame, _, err := ivf.ParseNextFrame()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		frames = append(frames, frame)
+	}
+	splitFrames, err := splitIVF(file)
+	require.NoError(t, err)
+	require.Equal(t, frames, splitFrames)
+
+	ogg := writeOgg(t, 2, [][]byte{payload(0xfc, 120), payload(0xfc, 80), OpusSilence(0xfc)})
+	oggReader, _, err := oggreader.NewWith(bytes.NewReader(ogg))
+	require.NoError(t, err)
+	var pages []oggPage
+	for {
+		page, header, err := oggReader.ParseNextPage()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		if !bytes.HasPrefix(page, opusTags) {
+			pages = append(pages, oggPage{data: page, granule: header.GranulePosition})
+		}
+	}
+	splitPages, err := splitOggPages(ogg)
+	require.NoError(t, err)
+	require.Equal(t, pages, splitPages)
+}
+
+func TestSplitInvalid(t *testing.T) {
+	_, err := splitIVF([]byte("not an IVF file, though long enough"))
+	require.ErrorIs(t, err, errNotIVF)
+	_, err = splitAnnexB([]byte{0x67, 0, 0, 1, 0x68})
+	require.ErrorIs(t, err, errNotAnnexB)
+	_, err = splitOggPages([]byte("OpusHead"))
+	require.ErrorIs(t, err, errNotOgg)
+
+	// a truncated last frame is left out
+	frames := [][]byte{payload(0x10, 100), payload(0x11, 30)}
+	file := writeIVF(frames)
+	split, err := splitIVF(file[:len(file)-1])
+	require.NoError(t, err)
+	require.Equal(t, frames[:1], split)
+}
+
+func TestLooperSeek(t *testing.T) {
+	looper := newH264VideoLooper(writeAnnexB(10, 50), circlesSpec(180, 200, 15))
+	// the stream starts with its only SPS
+	looper.SeekToKeyframeAfter(0.5)
+	sample, err := looper.NextSample(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 0x67, sample.Data[0])
+	require.Zero(t, sample.Duration)
+
+	// loops back to the start after the last NAL unit
+	for i := 0; i < 12; i++ {
+		_, err = looper.NextSample(context.Background())
+		require.NoError(t, err)
+	}
+	sample, err = looper.NextSample(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 0x67, sample.Data[0])
+
+	// VP8 keyframes have the low bit of their first byte cleared
+	vp8 := newVPVideoLooper(writeIVF([][]byte{{0x10}, {0x11}, {0x11}, {0x10}, {0x11}}), circlesSpec(180, 200, 15), false)
+	vp8.SeekToKeyframeAfter(0.5)
+	sample, err = vp8.NextSample(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x10}, sample.Data)
+	require.Equal(t, 3, vp8.next-1)
+}
+
+func TestIsInterFrame(t *testing.T) {
+	require.True(t, IsInterFrame(webrtc.MimeTypeH264, []byte{0x41}))
+	require.False(t, IsInterFrame(webrtc.MimeTypeH264, []byte{0x65}))
+	require.False(t, IsInterFrame(webrtc.MimeTypeH264, []byte{0x67}))
+	require.True(t, IsInterFrame(webrtc.MimeTypeVP8, []byte{0x11}))
+	require.False(t, IsInterFrame(webrtc.MimeTypeVP8, []byte{0x10}))
+	require.False(t, IsInterFrame(webrtc.MimeTypeOpus, []byte{0x11}))
+	require.False(t, IsInterFrame(webrtc.MimeTypeVP8, nil))
+}
+
+func benchmarkLooper(b *testing.B, looper Looper) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := looper.NextSample(context.Background()); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// frames of about the size a 2mbps 30fps clip has
+func BenchmarkH264Looper(b *testing.B) {
+	benchmarkLooper(b, newH264VideoLooper(writeAnnexB(300, 8000), circlesSpec(540, 2000, 30)))
+}
+
+func BenchmarkVP8Looper(b *testing.B) {
+	frames := make([][]byte, 300)
+	for i := range frames {
+		frames[i] = payload(0x11, 8000)
+	}
+	benchmarkLooper(b, newVPVideoLooper(writeIVF(frames), circlesSpec(540, 2000, 30), false))
+}
+
+func BenchmarkOpusLooper(b *testing.B) {
+	frames := make([][]byte, 500)
+	for i := range frames {
+		frames[i] = payload(0xfc, 120)
+	}
+	t := &testing.T{}
+	benchmarkLooper(b, newOpusAudioLooper(writeOgg(t, 2, frames)))
+}
+
+func BenchmarkOpusGenerator(b *testing.B) {
+	benchmarkLooper(b, NewOpusGenerator(2))
+}
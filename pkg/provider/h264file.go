@@ -0,0 +1,290 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+	"github.com/pion/webrtc/v4/pkg/media"
+	"github.com/pion/webrtc/v4/pkg/media/h264reader"
+
+	"github.com/livekit/protocol/livekit"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+// frame rate of Annex B streams without timing info in their SPS
+const defaultAnnexBFPS = 30
+
+// h264AccessUnit is the NAL units of a frame, starting with the parameter sets on keyframes
+type h264AccessUnit struct {
+	nals     [][]byte
+	keyframe bool
+	duration time.Duration
+}
+
+// h264File is an H.264 stream from an MP4 or Annex B file, split into frames
+type h264File struct {
+	units []h264AccessUnit
+	spec  *videoSpec
+	// from the SPS, or the default when it couldn't be read
+	profileLevelID string
+}
+
+// probeH264 splits an MP4 or Annex B file into frames, reading the dimensions from the SPS or
+// the MP4 track, and the frame rate and bitrate from the frames
+func probeH264(path string, data []byte) (*h264File, error) {
+	var units []h264AccessUnit
+	var sps *h264SPS
+	width, height := 0, 0
+	if isMP4(data) {
+		track, err := readMP4H264(data)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s: %w", path, err)
+		}
+		var parameterSets [][]byte
+		parameterSets = append(parameterSets, track.sps...)
+		parameterSets = append(parameterSets, track.pps...)
+		for i, sample := range track.samples {
+			nals, err := track.nals(sample.data)
+			if err != nil {
+				return nil, fmt.Errorf("could not read %s: frame %d: %w", path, i, err)
+			}
+			if i == 0 {
+				// out of band parameter sets go before the first frame
+				nals = append(parameterSets[:len(parameterSets):len(parameterSets)], nals...)
+			}
+			// frames are already split, only the parameter sets are taken from the access unit splitter
+			frame := splitAccessUnits(nals)
+			if len(frame) == 0 {
+				continue
+			}
+			merged := frame[0]
+			for _, u := range frame[1:] {
+				merged.nals = append(merged.nals, u.nals...)
+				merged.keyframe = merged.keyframe || u.keyframe
+			}
+			merged.duration = sample.duration
+			units = append(units, merged)
+		}
+		width, height = track.width, track.height
+		if len(track.sps) > 0 {
+			sps, _ = parseSPS(track.sps[0])
+		}
+	} else {
+		nals, err := splitAnnexB(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s is not an IVF, MP4, Y4M or H.264 Annex B file", path)
+		}
+		units = splitAccessUnits(nals)
+		for _, nal := range nals {
+			if nalType(nal) == h264reader.NalUnitTypeSPS {
+				if sps, err = parseSPS(nal); err != nil {
+					return nil, fmt.Errorf("could not read %s: %w", path, err)
+				}
+				break
+			}
+		}
+		if sps == nil {
+			return nil, fmt.Errorf("%s has no sequence parameter set", path)
+		}
+		fps := sps.fps
+		if fps <= 0 || fps > maxFileFPS {
+			fps = defaultAnnexBFPS
+		}
+		for i := range units {
+			units[i].duration = time.Duration(float64(time.Second) / fps)
+		}
+	}
+	if sps != nil {
+		width, height = sps.width, sps.height
+	}
+	repeatParameterSets(units)
+
+	var frames, size int
+	var duration time.Duration
+	for _, u := range units {
+		frames++
+		duration += u.duration
+		for _, nal := range u.nals {
+			size += len(nal)
+		}
+	}
+	if frames < 2 || duration <= 0 {
+		return nil, fmt.Errorf("%s has too few frames", path)
+	}
+	spec := &videoSpec{
+		codec:  h264Codec,
+		prefix: path,
+		width:  width,
+		height: height,
+		fps:    int(math.Round(float64(frames) / duration.Seconds())),
+		kbps:   int(float64(size*8) / duration.Seconds() / 1000),
+	}
+	if spec.fps < 1 || spec.fps > maxFileFPS {
+		return nil, fmt.Errorf("%s has unsupported frame rate %d, expected 1-%d fps", path, spec.fps, maxFileFPS)
+	}
+	// frames without a duration keep the average pace
+	for i := range units {
+		if units[i].duration <= 0 {
+			units[i].duration = duration / time.Duration(frames)
+		}
+	}
+	file := &h264File{units: units, spec: spec, profileLevelID: defaultH264ProfileLevelID}
+	if sps != nil {
+		file.profileLevelID = sps.profileLevelID
+	}
+	return file, nil
+}
+
+func nalType(nal []byte) h264reader.NalUnitType {
+	return h264reader.NalUnitType(nal[0] & 0x1f)
+}
+
+func isSlice(t h264reader.NalUnitType) bool {
+	switch t {
+	case h264reader.NalUnitTypeCodedSliceNonIdr,
+		h264reader.NalUnitTypeCodedSliceDataPartitionA,
+		h264reader.NalUnitTypeCodedSliceDataPartitionB,
+		h264reader.NalUnitTypeCodedSliceDataPartitionC,
+		h264reader.NalUnitTypeCodedSliceIdr:
+		return true
+	}
+	return false
+}
+
+// splitAccessUnits groups NAL units into frames. A frame ends at an access unit delimiter,
+// at a parameter set following its slices, or at the first slice of the next picture, whose
+// first_mb_in_slice is 0. Delimiters are left out, the packetizer would drop them anyway.
+func splitAccessUnits(nals [][]byte) []h264AccessUnit {
+	var units []h264AccessUnit
+	var current h264AccessUnit
+	hasSlice := false
+	flush := func() {
+		if hasSlice {
+			units = append(units, current)
+		} else if len(current.nals) > 0 && len(units) > 0 {
+			// parameter sets at the end of the stream belong to the last frame
+			last := &units[len(units)-1]
+			last.nals = append(last.nals, current.nals...)
+		}
+		current = h264AccessUnit{}
+		hasSlice = false
+	}
+	for _, nal := range nals {
+		t := nalType(nal)
+		switch {
+		case t == h264reader.NalUnitTypeAUD:
+			if hasSlice {
+				flush()
+			}
+			continue
+		case t == h264reader.NalUnitTypeSPS || t == h264reader.NalUnitTypePPS:
+			if hasSlice {
+				flush()
+			}
+		case isSlice(t):
+			// first_mb_in_slice is the first Exp-Golomb code of the slice header, 0 when its first bit is set
+			if hasSlice && len(nal) > 1 && nal[1]&0x80 != 0 {
+				flush()
+			}
+			hasSlice = true
+			current.keyframe = current.keyframe || t == h264reader.NalUnitTypeCodedSliceIdr
+		}
+		current.nals = append(current.nals, nal)
+	}
+	flush()
+	return units
+}
+
+// repeatParameterSets puts the last SPS and PPS in front of keyframes without them, so that
+// subscribers can decode from any keyframe, such as the one a looper starts or seeks to
+func repeatParameterSets(units []h264AccessUnit) {
+	var sps, pps []byte
+	for i := range units {
+		u := &units[i]
+		hasSPS, hasPPS := false, false
+		for _, nal := range u.nals {
+			switch nalType(nal) {
+			case h264reader.NalUnitTypeSPS:
+				sps, hasSPS = nal, true
+			case h264reader.NalUnitTypePPS:
+				pps, hasPPS = nal, true
+			}
+		}
+		if !u.keyframe || (hasSPS && hasPPS) || sps == nil || pps == nil {
+			continue
+		}
+		var prefix [][]byte
+		if !hasSPS {
+			prefix = append(prefix, sps)
+		}
+		if !hasPPS {
+			prefix = append(prefix, pps)
+		}
+		u.nals = append(prefix, u.nals...)
+	}
+}
+
+// H264FileLooper loops over the frames of an H.264 MP4 or Annex B file. Like H264VideoLooper,
+// it sends one NAL unit at a time, the last one of each frame carrying the frame's duration.
+type H264FileLooper struct {
+	lksdk.BaseSampleProvider
+	file *h264File
+	// next frame, and next NAL unit in it
+	unit int
+	nal  int
+}
+
+func newH264FileLooper(file *h264File) *H264FileLooper {
+	return &H264FileLooper{file: file}
+}
+
+func (l *H264FileLooper) Codec() webrtc.RTPCodecCapability {
+	return h264Capability(l.file.profileLevelID)
+}
+
+func (l *H264FileLooper) ToLayer(quality livekit.VideoQuality) *livekit.VideoLayer {
+	return l.file.spec.ToVideoLayer(quality)
+}
+
+func (l *H264FileLooper) NextSample(_ context.Context) (media.Sample, error) {
+	u := &l.file.units[l.unit]
+	sample := media.Sample{Data: u.nals[l.nal]}
+	l.nal++
+	if l.nal == len(u.nals) {
+		sample.Duration = u.duration
+		l.nal = 0
+		l.unit = (l.unit + 1) % len(l.file.units)
+	}
+	return sample, nil
+}
+
+// SeekToKeyframeAfter makes the looper start at the first keyframe after the given fraction (0-1)
+// of the file's frames, or at the beginning if there is none. Later loops start from the
+// beginning as usual.
+func (l *H264FileLooper) SeekToKeyframeAfter(fraction float64) {
+	var keyframes []int
+	for i, u := range l.file.units {
+		if u.keyframe {
+			keyframes = append(keyframes, i)
+		}
+	}
+	l.unit = firstAtOrAfter(keyframes, int(fraction*float64(len(l.file.units))))
+	l.nal = 0
+}
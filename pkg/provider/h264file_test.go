@@ -0,0 +1,164 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// slice is a slice NAL unit, starting a new picture when first is set
+func slice(header byte, first bool) []byte {
+	nal := payload(header, 8)
+	if !first {
+		// first_mb_in_slice of 1
+		nal[1] = 0x40
+	}
+	return nal
+}
+
+func nalTypes(u h264AccessUnit) []int {
+	var types []int
+	for _, nal := range u.nals {
+		types = append(types, int(nalType(nal)))
+	}
+	return types
+}
+
+func TestSplitAccessUnits(t *testing.T) {
+	sps, pps, aud := testSPS(66, 320, 240, 30), payload(0x68, 4), []byte{0x09, 0xf0}
+	units := splitAccessUnits([][]byte{
+		aud, sps, pps, slice(0x65, true), slice(0x65, false),
+		aud, slice(0x41, true),
+		// no delimiter, a new picture starts with its first slice
+		slice(0x41, true), slice(0x41, false),
+		// parameter sets start the next frame
+		sps, pps, slice(0x65, true),
+	})
+	require.Len(t, units, 4)
+	require.Equal(t, []int{7, 8, 5, 5}, nalTypes(units[0]))
+	require.True(t, units[0].keyframe)
+	require.Equal(t, []int{1}, nalTypes(units[1]))
+	require.False(t, units[1].keyframe)
+	require.Equal(t, []int{1, 1}, nalTypes(units[2]))
+	require.Equal(t, []int{7, 8, 5}, nalTypes(units[3]))
+	require.True(t, units[3].keyframe)
+}
+
+func TestRepeatParameterSets(t *testing.T) {
+	sps, pps := testSPS(66, 320, 240, 30), payload(0x68, 4)
+	units := splitAccessUnits([][]byte{
+		sps, pps, slice(0x65, true), slice(0x41, true),
+		slice(0x65, true), slice(0x41, true),
+		pps, slice(0x65, true),
+	})
+	require.Len(t, units, 5)
+	repeatParameterSets(units)
+	require.Equal(t, []int{7, 8, 5}, nalTypes(units[0]))
+	require.Equal(t, []int{1}, nalTypes(units[1]))
+	require.Equal(t, []int{7, 8, 5}, nalTypes(units[2]))
+	require.Equal(t, sps, units[2].nals[0])
+	require.Equal(t, []int{7, 8, 5}, nalTypes(units[4]))
+}
+
+// writeH264AnnexB writes GOPs of a keyframe with parameter sets only at the start, and delta frames
+func writeH264AnnexB(sps []byte, gops, gopSize int) []byte {
+	var buf bytes.Buffer
+	write := func(nal []byte) {
+		buf.Write([]byte{0, 0, 0, 1})
+		buf.Write(nal)
+	}
+	write(sps)
+	write(payload(0x68, 4))
+	for i := 0; i < gops*gopSize; i++ {
+		write([]byte{0x09, 0xf0})
+		if i%gopSize == 0 {
+			write(slice(0x65, true))
+		} else {
+			write(slice(0x41, true))
+		}
+	}
+	return buf.Bytes()
+}
+
+func TestProbeH264AnnexB(t *testing.T) {
+	file, err := probeH264("test.h264", writeH264AnnexB(testSPS(66, 640, 360, 25), 2, 25))
+	require.NoError(t, err)
+	require.Len(t, file.units, 50)
+	require.Equal(t, 640, file.spec.width)
+	require.Equal(t, 360, file.spec.height)
+	require.Equal(t, 25, file.spec.fps)
+	require.Equal(t, h264Codec, file.spec.codec)
+	require.Equal(t, 40*time.Millisecond, file.units[1].duration)
+	require.Equal(t, []int{7, 8, 5}, nalTypes(file.units[25]))
+
+	// without timing info the default frame rate is used
+	file, err = probeH264("test.h264", writeH264AnnexB(testSPS(66, 640, 360, 0), 2, 25))
+	require.NoError(t, err)
+	require.Equal(t, defaultAnnexBFPS, file.spec.fps)
+
+	// the codec has the profile of the stream
+	file, err = probeH264("test.h264", writeH264AnnexB(testSPS(100, 640, 360, 0), 2, 25))
+	require.NoError(t, err)
+	require.Contains(t, newH264FileLooper(file).Codec().SDPFmtpLine, "profile-level-id=64001f")
+
+	_, err = probeH264("test.bin", []byte("not a video"))
+	require.EqualError(t, err, "test.bin is not an IVF, MP4, Y4M or H.264 Annex B file")
+	_, err = probeH264("test.h264", append([]byte{0, 0, 0, 1}, slice(0x65, true)...))
+	require.EqualError(t, err, "test.h264 has no sequence parameter set")
+}
+
+func TestH264FileLooper(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.h264")
+	require.NoError(t, os.WriteFile(path, writeH264AnnexB(testSPS(66, 320, 240, 30), 2, 10), 0o644))
+	loopers, err := CreateVideoLoopersFromFiles([]string{path}, "high", "", false)
+	require.NoError(t, err)
+	require.Len(t, loopers, 1)
+	looper, ok := loopers[0].(*H264FileLooper)
+	require.True(t, ok)
+	require.Equal(t, "video/h264", looper.Codec().MimeType)
+	require.Contains(t, looper.Codec().SDPFmtpLine, "profile-level-id=42001f")
+	require.EqualValues(t, 320, looper.ToLayer(0).Width)
+
+	// parameter sets without duration, then the frame
+	var types []int
+	var durations []time.Duration
+	for i := 0; i < 4; i++ {
+		sample, err := looper.NextSample(context.Background())
+		require.NoError(t, err)
+		types = append(types, int(sample.Data[0]&0x1f))
+		durations = append(durations, sample.Duration)
+	}
+	require.Equal(t, []int{7, 8, 5, 1}, types)
+	frame := time.Second / 30
+	require.Equal(t, []time.Duration{0, 0, frame, frame}, durations)
+
+	// seeking lands on the second keyframe, which got the parameter sets
+	looper.SeekToKeyframeAfter(0.3)
+	sample, err := looper.NextSample(context.Background())
+	require.NoError(t, err)
+	require.EqualValues(t, 7, sample.Data[0]&0x1f)
+	require.Equal(t, 10, looper.unit)
+
+	// loops back to the start after the last frame
+	looper.SeekToKeyframeAfter(0.9)
+	require.Equal(t, 0, looper.unit)
+}
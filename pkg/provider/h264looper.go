@@ -17,6 +17,7 @@ package provider
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"io"
 	"time"
 
@@ -33,31 +34,47 @@ type H264VideoLooper struct {
 	buffer        []byte
 	frameDuration time.Duration
 	spec          *videoSpec
-	reader        *h264reader.H264Reader
+	// NAL units of buffer, indexed on first use, and the next one to send
+	nals [][]byte
+	next int
+	err  error
 }
 
 func NewH264VideoLooper(input io.Reader, spec *videoSpec) (*H264VideoLooper, error) {
-	l := &H264VideoLooper{
-		spec:          spec,
-		frameDuration: time.Second / time.Duration(spec.fps),
-	}
-
 	buf := bytes.NewBuffer(nil)
 
 	if _, err := io.Copy(buf, input); err != nil {
 		return nil, err
 	}
-	l.buffer = buf.Bytes()
+	return newH264VideoLooper(buf.Bytes(), spec), nil
+}
 
-	return l, nil
+// newH264VideoLooper loops over buffer without copying it
+func newH264VideoLooper(buffer []byte, spec *videoSpec) *H264VideoLooper {
+	return &H264VideoLooper{
+		buffer:        buffer,
+		spec:          spec,
+		frameDuration: time.Second / time.Duration(spec.fps),
+	}
 }
 
+// defaultH264ProfileLevelID is baseline level 3.1, for streams without a readable SPS
+const defaultH264ProfileLevelID = "42001f"
+
 func (l *H264VideoLooper) Codec() webrtc.RTPCodecCapability {
+	// an unreadable buffer fails on the first sample instead
+	_ = l.index()
+	return h264Capability(spsProfileLevelID(l.nals))
+}
+
+// h264Capability is the codec of H.264 loopers, with the profile and level of their SPS for
+// subscribers to know whether they can decode it
+func h264Capability(profileLevelID string) webrtc.RTPCodecCapability {
 	return webrtc.RTPCodecCapability{
 		MimeType:    "video/h264",
 		ClockRate:   90000,
 		Channels:    0,
-		SDPFmtpLine: "level-asymmetry-allowed=1;packetization-mode=1;profile-level-id=42001f",
+		SDPFmtpLine: fmt.Sprintf("level-asymmetry-allowed=1;packetization-mode=1;profile-level-id=%s", profileLevelID),
 		RTCPFeedback: []webrtc.RTCPFeedback{
 			{Type: webrtc.TypeRTCPFBNACK},
 			{Type: webrtc.TypeRTCPFBNACK, Parameter: "pli"},
@@ -66,33 +83,33 @@ func (l *H264VideoLooper) Codec() webrtc.RTPCodecCapability {
 }
 
 func (l *H264VideoLooper) NextSample(_ctx context.Context) (media.Sample, error) {
-	return l.nextSample(true)
+	return l.nextSample()
 }
 
 func (l *H264VideoLooper) ToLayer(quality livekit.VideoQuality) *livekit.VideoLayer {
 	return l.spec.ToVideoLayer(quality)
 }
 
-func (l *H264VideoLooper) nextSample(rewindEOF bool) (media.Sample, error) {
-	sample := media.Sample{}
-	if l.reader == nil {
-		var err error
-		l.reader, err = h264reader.NewReader(bytes.NewReader(l.buffer))
-		if err != nil {
-			return sample, err
-		}
-	}
-	nal, err := l.reader.NextNAL()
-	if err == io.EOF && rewindEOF {
-		l.reader = nil
-		return l.nextSample(false)
+func (l *H264VideoLooper) index() error {
+	if l.nals == nil && l.err == nil {
+		l.nals, l.err = splitAnnexB(l.buffer)
 	}
-	if err != nil {
+	return l.err
+}
+
+func (l *H264VideoLooper) nextSample() (media.Sample, error) {
+	sample := media.Sample{}
+	if err := l.index(); err != nil {
 		return sample, err
 	}
+	if l.next >= len(l.nals) {
+		l.next = 0
+	}
+	nal := l.nals[l.next]
+	l.next++
 
 	isFrame := false
-	switch nal.UnitType {
+	switch h264reader.NalUnitType(nal[0] & 0x1f) {
 	case h264reader.NalUnitTypeCodedSliceDataPartitionA,
 		h264reader.NalUnitTypeCodedSliceDataPartitionB,
 		h264reader.NalUnitTypeCodedSliceDataPartitionC,
@@ -101,7 +118,7 @@ func (l *H264VideoLooper) nextSample(rewindEOF bool) (media.Sample, error) {
 		isFrame = true
 	}
 
-	sample.Data = nal.Data
+	sample.Data = nal
 	if isFrame {
 		// return it without duration
 		sample.Duration = l.frameDuration
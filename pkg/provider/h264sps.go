@@ -0,0 +1,246 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pion/webrtc/v4/pkg/media/h264reader"
+)
+
+var errInvalidSPS = errors.New("invalid H.264 sequence parameter set")
+
+// h264SPS is what publishing needs from an H.264 sequence parameter set
+type h264SPS struct {
+	// profile_idc, constraint flags and level_idc in hex, as SDP's profile-level-id
+	profileLevelID string
+	width          int
+	height         int
+	// frame rate from the VUI timing info, 0 when absent
+	fps float64
+}
+
+// bitReader reads an RBSP, the payload of a NAL unit with emulation prevention bytes removed
+type bitReader struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func newRBSPReader(nal []byte) *bitReader {
+	rbsp := make([]byte, 0, len(nal))
+	zeros := 0
+	for _, b := range nal {
+		if zeros >= 2 && b == 3 {
+			zeros = 0
+			continue
+		}
+		if b == 0 {
+			zeros++
+		} else {
+			zeros = 0
+		}
+		rbsp = append(rbsp, b)
+	}
+	return &bitReader{data: rbsp}
+}
+
+func (r *bitReader) bits(n int) uint32 {
+	var v uint32
+	for i := 0; i < n; i++ {
+		if r.pos >= len(r.data)*8 {
+			r.err = errInvalidSPS
+			return 0
+		}
+		bit := (r.data[r.pos/8] >> (7 - r.pos%8)) & 1
+		v = v<<1 | uint32(bit)
+		r.pos++
+	}
+	return v
+}
+
+func (r *bitReader) flag() bool {
+	return r.bits(1) == 1
+}
+
+// ue reads an unsigned Exp-Golomb code
+func (r *bitReader) ue() uint32 {
+	zeros := 0
+	for !r.flag() {
+		if r.err != nil || zeros > 31 {
+			r.err = errInvalidSPS
+			return 0
+		}
+		zeros++
+	}
+	return 1<<zeros - 1 + r.bits(zeros)
+}
+
+// se reads a signed Exp-Golomb code
+func (r *bitReader) se() int32 {
+	v := r.ue()
+	if v%2 == 1 {
+		return int32(v/2 + 1)
+	}
+	return -int32(v / 2)
+}
+
+func (r *bitReader) skipScalingList(size int) {
+	last, next := int32(8), int32(8)
+	for j := 0; j < size && r.err == nil; j++ {
+		if next != 0 {
+			next = (last + r.se() + 256) % 256
+		}
+		if next != 0 {
+			last = next
+		}
+	}
+}
+
+// parseSPS reads the dimensions and frame rate from a sequence parameter set NAL unit
+func parseSPS(nal []byte) (*h264SPS, error) {
+	if len(nal) < 4 || nal[0]&0x1f != 7 {
+		return nil, errInvalidSPS
+	}
+	r := newRBSPReader(nal[1:])
+	profile := r.bits(8)
+	constraints, level := r.bits(8), r.bits(8)
+	r.ue() // seq_parameter_set_id
+	chromaFormat := uint32(1)
+	switch profile {
+	case 100, 110, 122, 244, 44, 83, 86, 118, 128, 138, 139, 134, 135:
+		chromaFormat = r.ue()
+		if chromaFormat == 3 {
+			r.flag() // separate_colour_plane_flag
+		}
+		r.ue()   // bit_depth_luma_minus8
+		r.ue()   // bit_depth_chroma_minus8
+		r.flag() // qpprime_y_zero_transform_bypass_flag
+		if r.flag() {
+			lists := 8
+			if chromaFormat == 3 {
+				lists = 12
+			}
+			for i := 0; i < lists; i++ {
+				if r.flag() {
+					if i < 6 {
+						r.skipScalingList(16)
+					} else {
+						r.skipScalingList(64)
+					}
+				}
+			}
+		}
+	}
+	r.ue() // log2_max_frame_num_minus4
+	switch r.ue() {
+	case 0:
+		r.ue() // log2_max_pic_order_cnt_lsb_minus4
+	case 1:
+		r.flag() // delta_pic_order_always_zero_flag
+		r.se()   // offset_for_non_ref_pic
+		r.se()   // offset_for_top_to_bottom_field
+		cycle := r.ue()
+		for i := uint32(0); i < cycle && r.err == nil; i++ {
+			r.se()
+		}
+	}
+	r.ue()   // max_num_ref_frames
+	r.flag() // gaps_in_frame_num_value_allowed_flag
+	widthMbs := int(r.ue()) + 1
+	heightMapUnits := int(r.ue()) + 1
+	frameMbsOnly := r.flag()
+	if !frameMbsOnly {
+		r.flag() // mb_adaptive_frame_field_flag
+	}
+	r.flag() // direct_8x8_inference_flag
+
+	fieldFactor := 2
+	if frameMbsOnly {
+		fieldFactor = 1
+	}
+	sps := &h264SPS{
+		profileLevelID: fmt.Sprintf("%02x%02x%02x", profile, constraints, level),
+		width:          widthMbs * 16,
+		height:         heightMapUnits * 16 * fieldFactor,
+	}
+	if r.flag() {
+		left, right, top, bottom := int(r.ue()), int(r.ue()), int(r.ue()), int(r.ue())
+		// crop units depend on chroma subsampling
+		cropX, cropY := 1, fieldFactor
+		switch chromaFormat {
+		case 1:
+			cropX, cropY = 2, 2*fieldFactor
+		case 2:
+			cropX = 2
+		}
+		sps.width -= cropX * (left + right)
+		sps.height -= cropY * (top + bottom)
+	}
+	if r.err == nil && r.flag() {
+		sps.fps = parseVUIFrameRate(r)
+	}
+	if r.err != nil || sps.width <= 0 || sps.height <= 0 {
+		return nil, errInvalidSPS
+	}
+	return sps, nil
+}
+
+// spsProfileLevelID returns the profile-level-id of the first SPS in nals, or the default
+// for streams without a readable one
+func spsProfileLevelID(nals [][]byte) string {
+	for _, nal := range nals {
+		if len(nal) > 0 && nalType(nal) == h264reader.NalUnitTypeSPS {
+			if sps, err := parseSPS(nal); err == nil {
+				return sps.profileLevelID
+			}
+			break
+		}
+	}
+	return defaultH264ProfileLevelID
+}
+
+// parseVUIFrameRate reads the frame rate from the VUI parameters up to their timing info
+func parseVUIFrameRate(r *bitReader) float64 {
+	if r.flag() { // aspect_ratio_info_present_flag
+		if r.bits(8) == 255 {
+			r.bits(32) // sar_width and sar_height
+		}
+	}
+	if r.flag() { // overscan_info_present_flag
+		r.flag()
+	}
+	if r.flag() { // video_signal_type_present_flag
+		r.bits(4)
+		if r.flag() {
+			r.bits(24)
+		}
+	}
+	if r.flag() { // chroma_loc_info_present_flag
+		r.ue()
+		r.ue()
+	}
+	if !r.flag() { // timing_info_present_flag
+		return 0
+	}
+	unitsInTick := r.bits(32)
+	timeScale := r.bits(32)
+	if r.err != nil || unitsInTick == 0 {
+		return 0
+	}
+	// a frame is two fields
+	return float64(timeScale) / float64(2*unitsInTick)
+}
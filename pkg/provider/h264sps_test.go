@@ -0,0 +1,153 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// bitWriter builds NAL units for tests
+type bitWriter struct {
+	data []byte
+	n    int
+}
+
+func (w *bitWriter) bits(v uint32, n int) {
+	for i := n - 1; i >= 0; i-- {
+		if w.n%8 == 0 {
+			w.data = append(w.data, 0)
+		}
+		w.data[len(w.data)-1] |= byte((v>>i)&1) << (7 - w.n%8)
+		w.n++
+	}
+}
+
+func (w *bitWriter) ue(v uint32) {
+	v++
+	size := 0
+	for x := v; x > 1; x >>= 1 {
+		size++
+	}
+	w.bits(0, size)
+	w.bits(v, size+1)
+}
+
+// nal adds the stop bit and emulation prevention bytes, and prepends the header
+func (w *bitWriter) nal(header byte) []byte {
+	w.bits(1, 1)
+	out := []byte{header}
+	zeros := 0
+	for _, b := range w.data {
+		if zeros >= 2 && b <= 3 {
+			out = append(out, 3)
+			zeros = 0
+		}
+		out = append(out, b)
+		if b == 0 {
+			zeros++
+		} else {
+			zeros = 0
+		}
+	}
+	return out
+}
+
+// testSPS writes an SPS for the given dimensions, cropping to them, with VUI timing info when fps is set
+func testSPS(profile uint32, width, height, fps int) []byte {
+	w := &bitWriter{}
+	w.bits(profile, 8)
+	w.bits(0x001f, 16) // constraint flags and level
+	w.ue(0)
+	if profile == 100 {
+		w.ue(1)      // chroma_format_idc
+		w.ue(0)      // bit_depth_luma_minus8
+		w.ue(0)      // bit_depth_chroma_minus8
+		w.bits(0, 1) // qpprime_y_zero_transform_bypass_flag
+		w.bits(1, 1) // seq_scaling_matrix_present_flag
+		w.bits(1, 1) // first list present, all deltas zero
+		for i := 0; i < 16; i++ {
+			w.ue(0)
+		}
+		w.bits(0, 7)
+	}
+	w.ue(0)      // log2_max_frame_num_minus4
+	w.ue(0)      // pic_order_cnt_type
+	w.ue(2)      // log2_max_pic_order_cnt_lsb_minus4
+	w.ue(1)      // max_num_ref_frames
+	w.bits(0, 1) // gaps_in_frame_num_value_allowed_flag
+	mbsWide, mbsHigh := (width+15)/16, (height+15)/16
+	w.ue(uint32(mbsWide - 1))
+	w.ue(uint32(mbsHigh - 1))
+	w.bits(1, 1) // frame_mbs_only_flag
+	w.bits(1, 1) // direct_8x8_inference_flag
+	if mbsWide*16 != width || mbsHigh*16 != height {
+		w.bits(1, 1)
+		w.ue(0)
+		w.ue(uint32(mbsWide*16-width) / 2)
+		w.ue(0)
+		w.ue(uint32(mbsHigh*16-height) / 2)
+	} else {
+		w.bits(0, 1)
+	}
+	if fps == 0 {
+		w.bits(0, 1)
+	} else {
+		w.bits(1, 1)
+		w.bits(0, 4) // no aspect ratio, overscan, signal type or chroma location
+		w.bits(1, 1) // timing_info_present_flag
+		w.bits(1, 32)
+		w.bits(uint32(2*fps), 32)
+		w.bits(1, 1)
+		w.bits(0, 3) // no HRD parameters or picture structure
+	}
+	return w.nal(0x67)
+}
+
+func TestParseSPS(t *testing.T) {
+	sps, err := parseSPS(testSPS(66, 1280, 720, 30))
+	require.NoError(t, err)
+	require.Equal(t, h264SPS{profileLevelID: "42001f", width: 1280, height: 720, fps: 30}, *sps)
+
+	// cropped, with scaling lists and no timing info
+	sps, err = parseSPS(testSPS(100, 1920, 1080, 0))
+	require.NoError(t, err)
+	require.Equal(t, h264SPS{profileLevelID: "64001f", width: 1920, height: 1080}, *sps)
+
+	// zero constraint flags and level followed by a long Exp-Golomb code need emulation prevention
+	w := &bitWriter{}
+	w.bits(66, 8)
+	w.bits(0, 16)
+	w.ue(63)
+	w.ue(0)
+	w.ue(0)
+	w.ue(0)
+	w.ue(1)
+	w.bits(0, 1)
+	w.ue(0)
+	w.ue(0)
+	w.bits(0x18, 5)
+	nal := w.nal(0x67)
+	require.Contains(t, string(nal), "\x00\x00\x03")
+	sps, err = parseSPS(nal)
+	require.NoError(t, err)
+	require.Equal(t, h264SPS{profileLevelID: "420000", width: 16, height: 16}, *sps)
+
+	_, err = parseSPS(testSPS(66, 1280, 720, 30)[:6])
+	require.ErrorIs(t, err, errInvalidSPS)
+	_, err = parseSPS([]byte{0x68, 0xce, 0x38, 0x80})
+	require.ErrorIs(t, err, errInvalidSPS)
+}
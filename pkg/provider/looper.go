@@ -29,4 +29,6 @@ type Looper interface {
 type VideoLooper interface {
 	Looper
 	ToLayer(quality livekit.VideoQuality) *livekit.VideoLayer
+	// SeekToKeyframeAfter starts the first loop at the first keyframe after a fraction of the file
+	SeekToKeyframeAfter(fraction float64)
 }
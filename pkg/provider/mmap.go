@@ -0,0 +1,86 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"os"
+	"sync"
+)
+
+var (
+	mappedLock  sync.Mutex
+	mappedFiles = make(map[string]*mappedFile)
+)
+
+// mappedFile is a source media file shared by every looper that reads it. The file is memory-mapped
+// where supported, so its pages are loaded by the OS as needed and shared between publishers instead
+// of being copied into the heap for each of them.
+type mappedFile struct {
+	data []byte
+
+	// probed once, on first use
+	probeOnce sync.Once
+	spec      *videoSpec
+	h264      *h264File
+	y4m       *y4mVideo
+	probeErr  error
+}
+
+// openMapped returns the contents of path, mapping it on first use. Mappings are kept for the
+// lifetime of the process since loopers keep reading them until their publisher stops.
+func openMapped(path string) (*mappedFile, error) {
+	mappedLock.Lock()
+	defer mappedLock.Unlock()
+	if m, ok := mappedFiles[path]; ok {
+		return m, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	data, err := mapFile(f, info.Size())
+	if err != nil {
+		return nil, err
+	}
+	m := &mappedFile{data: data}
+	mappedFiles[path] = m
+	return m, nil
+}
+
+// probe probes the mapped file once, setting the same spec for every caller. H.264 and Y4M
+// files are also split into frames, which are shared the same way.
+func (m *mappedFile) probe(path string) error {
+	m.probeOnce.Do(func() {
+		switch {
+		case isIVF(m.data):
+			m.spec, m.probeErr = probeIVF(path, m.data)
+		case isY4M(m.data):
+			if m.y4m, m.probeErr = probeY4M(path, m.data); m.y4m != nil {
+				m.spec = m.y4m.spec
+			}
+		default:
+			if m.h264, m.probeErr = probeH264(path, m.data); m.h264 != nil {
+				m.spec = m.h264.spec
+			}
+		}
+	})
+	return m.probeErr
+}
@@ -0,0 +1,58 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v4/pkg/media/oggwriter"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMappedFilesAreShared(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := oggwriter.NewWith(&buf, 48000, 1)
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, writer.WriteRTP(&rtp.Packet{
+			Header:  rtp.Header{SequenceNumber: uint16(i), Timestamp: uint32(i * 960)},
+			Payload: []byte{0xf8, 0xff, 0xfe},
+		}))
+	}
+	data := buf.Bytes()
+	path := filepath.Join(t.TempDir(), "audio.ogg")
+	require.NoError(t, os.WriteFile(path, data, 0644))
+
+	a, err := CreateAudioLooperFromFile(path)
+	require.NoError(t, err)
+	b, err := CreateAudioLooperFromFile(path)
+	require.NoError(t, err)
+	require.Equal(t, data, a.buffer)
+	require.Same(t, &a.buffer[0], &b.buffer[0])
+
+	sample, err := a.NextSample(context.Background())
+	require.NoError(t, err)
+	require.NotEmpty(t, sample.Data)
+
+	empty := filepath.Join(t.TempDir(), "empty.ivf")
+	require.NoError(t, os.WriteFile(empty, nil, 0644))
+	_, err = CreateVideoLoopersFromFiles([]string{empty}, "high", "", false)
+	require.Error(t, err)
+}
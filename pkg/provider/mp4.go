@@ -0,0 +1,400 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// A minimal reader of the H.264 track of progressive MP4 files: just the sample tables,
+// enough to hand out the frames in the mapped file with their durations.
+
+var errNotMP4 = errors.New("not an MP4 file")
+
+// mp4Sample is a frame of an MP4 track, its NAL units prefixed with their length
+type mp4Sample struct {
+	data     []byte
+	duration time.Duration
+}
+
+type mp4Track struct {
+	width, height int
+	// length of the NAL unit size prefix of the samples
+	lengthSize int
+	sps, pps   [][]byte
+	samples    []mp4Sample
+}
+
+func isMP4(data []byte) bool {
+	return len(data) >= 12 && string(data[4:8]) == "ftyp"
+}
+
+// mp4Boxes calls fn with the type and payload of each box in data, until it returns false
+func mp4Boxes(data []byte, fn func(kind string, payload []byte) bool) error {
+	for len(data) > 0 {
+		if len(data) < 8 {
+			return fmt.Errorf("truncated box")
+		}
+		size := uint64(binary.BigEndian.Uint32(data))
+		kind := string(data[4:8])
+		header := uint64(8)
+		switch size {
+		case 0:
+			size = uint64(len(data))
+		case 1:
+			if len(data) < 16 {
+				return fmt.Errorf("truncated %s box", kind)
+			}
+			size = binary.BigEndian.Uint64(data[8:])
+			header = 16
+		}
+		if size < header || size > uint64(len(data)) {
+			return fmt.Errorf("truncated %s box", kind)
+		}
+		if !fn(kind, data[header:size]) {
+			return nil
+		}
+		data = data[size:]
+	}
+	return nil
+}
+
+// mp4Child returns the payload of the first box of kind in data
+func mp4Child(data []byte, kind string) []byte {
+	var found []byte
+	_ = mp4Boxes(data, func(k string, payload []byte) bool {
+		if k == kind {
+			found = payload
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// mp4Path follows the first boxes of kinds down from data
+func mp4Path(data []byte, kinds ...string) []byte {
+	for _, kind := range kinds {
+		if data = mp4Child(data, kind); data == nil {
+			return nil
+		}
+	}
+	return data
+}
+
+// mp4Table returns the entries of a full box holding a count followed by entries of size bytes
+func mp4Table(box []byte, size int) ([]byte, int, error) {
+	if len(box) < 8 {
+		return nil, 0, fmt.Errorf("truncated sample table")
+	}
+	count := int(binary.BigEndian.Uint32(box[4:]))
+	if count < 0 || count > (len(box)-8)/size {
+		return nil, 0, fmt.Errorf("truncated sample table")
+	}
+	return box[8:], count, nil
+}
+
+// readMP4H264 returns the first H.264 video track of a progressive MP4 file
+func readMP4H264(data []byte) (*mp4Track, error) {
+	if !isMP4(data) {
+		return nil, errNotMP4
+	}
+	var moov []byte
+	if err := mp4Boxes(data, func(kind string, payload []byte) bool {
+		if kind == "moov" {
+			moov = payload
+			return false
+		}
+		return true
+	}); err != nil {
+		return nil, err
+	}
+	if moov == nil {
+		return nil, fmt.Errorf("no moov box")
+	}
+	var track *mp4Track
+	var trackErr error
+	_ = mp4Boxes(moov, func(kind string, trak []byte) bool {
+		if kind != "trak" {
+			return true
+		}
+		mdia := mp4Child(trak, "mdia")
+		if hdlr := mp4Child(mdia, "hdlr"); len(hdlr) < 12 || string(hdlr[8:12]) != "vide" {
+			return true
+		}
+		track, trackErr = readMP4VideoTrack(data, mdia)
+		return track == nil && trackErr == nil
+	})
+	if trackErr != nil {
+		return nil, trackErr
+	}
+	if track == nil {
+		return nil, fmt.Errorf("no H.264 video track")
+	}
+	return track, nil
+}
+
+// readMP4VideoTrack reads a video track, or returns nil if it isn't H.264
+func readMP4VideoTrack(file, mdia []byte) (*mp4Track, error) {
+	stbl := mp4Path(mdia, "minf", "stbl")
+	stsd := mp4Child(stbl, "stsd")
+	if len(stsd) < 8 {
+		return nil, fmt.Errorf("no sample description")
+	}
+	var entry []byte
+	var codec string
+	_ = mp4Boxes(stsd[8:], func(kind string, payload []byte) bool {
+		codec, entry = kind, payload
+		return false
+	})
+	if codec != "avc1" && codec != "avc3" {
+		return nil, nil
+	}
+	// visual sample entry fields before the child boxes
+	const visualSampleEntrySize = 78
+	if len(entry) < visualSampleEntrySize {
+		return nil, fmt.Errorf("truncated sample description")
+	}
+	track := &mp4Track{
+		width:  int(binary.BigEndian.Uint16(entry[24:])),
+		height: int(binary.BigEndian.Uint16(entry[26:])),
+	}
+	if err := track.readAVCC(mp4Child(entry[visualSampleEntrySize:], "avcC")); err != nil {
+		return nil, err
+	}
+
+	timescale, err := mp4Timescale(mp4Child(mdia, "mdhd"))
+	if err != nil {
+		return nil, err
+	}
+	if err = checkCompositionOffsets(mp4Child(stbl, "ctts")); err != nil {
+		return nil, err
+	}
+	sizes, err := mp4SampleSizes(mp4Child(stbl, "stsz"), len(file))
+	if err != nil {
+		return nil, err
+	}
+	offsets, err := mp4SampleOffsets(stbl, sizes)
+	if err != nil {
+		return nil, err
+	}
+	durations, err := mp4SampleDurations(mp4Child(stbl, "stts"), len(sizes), timescale)
+	if err != nil {
+		return nil, err
+	}
+	for i, size := range sizes {
+		end := offsets[i] + uint64(size)
+		if end > uint64(len(file)) {
+			return nil, fmt.Errorf("sample %d is past the end of the file", i)
+		}
+		track.samples = append(track.samples, mp4Sample{
+			data:     file[offsets[i]:end:end],
+			duration: durations[i],
+		})
+	}
+	if len(track.samples) == 0 {
+		return nil, fmt.Errorf("no samples, fragmented MP4 files are not supported")
+	}
+	return track, nil
+}
+
+// readAVCC reads the NAL unit length size and parameter sets of the decoder configuration
+func (t *mp4Track) readAVCC(avcC []byte) error {
+	if len(avcC) < 7 {
+		return fmt.Errorf("no AVC decoder configuration")
+	}
+	t.lengthSize = int(avcC[4]&0x03) + 1
+	data := avcC[5:]
+	readSets := func(count int) ([][]byte, error) {
+		var sets [][]byte
+		for i := 0; i < count; i++ {
+			if len(data) < 2 {
+				return nil, fmt.Errorf("truncated AVC decoder configuration")
+			}
+			size := int(binary.BigEndian.Uint16(data))
+			if len(data) < 2+size {
+				return nil, fmt.Errorf("truncated AVC decoder configuration")
+			}
+			sets = append(sets, data[2:2+size:2+size])
+			data = data[2+size:]
+		}
+		return sets, nil
+	}
+	var err error
+	count := int(data[0] & 0x1f)
+	data = data[1:]
+	if t.sps, err = readSets(count); err != nil {
+		return err
+	}
+	if len(data) < 1 {
+		return fmt.Errorf("truncated AVC decoder configuration")
+	}
+	count = int(data[0])
+	data = data[1:]
+	t.pps, err = readSets(count)
+	return err
+}
+
+// nals splits a sample into its NAL units
+func (t *mp4Track) nals(sample []byte) ([][]byte, error) {
+	var nals [][]byte
+	for len(sample) > 0 {
+		if len(sample) < t.lengthSize {
+			return nil, fmt.Errorf("truncated NAL unit")
+		}
+		var size int
+		for _, b := range sample[:t.lengthSize] {
+			size = size<<8 | int(b)
+		}
+		sample = sample[t.lengthSize:]
+		if size > len(sample) {
+			return nil, fmt.Errorf("truncated NAL unit")
+		}
+		if size > 0 {
+			nals = append(nals, sample[:size:size])
+		}
+		sample = sample[size:]
+	}
+	return nals, nil
+}
+
+func mp4Timescale(mdhd []byte) (uint32, error) {
+	offset := 12
+	if len(mdhd) > 0 && mdhd[0] == 1 {
+		offset = 20
+	}
+	if len(mdhd) < offset+4 {
+		return 0, fmt.Errorf("no media header")
+	}
+	timescale := binary.BigEndian.Uint32(mdhd[offset:])
+	if timescale == 0 {
+		return 0, fmt.Errorf("invalid timescale")
+	}
+	return timescale, nil
+}
+
+// checkCompositionOffsets fails on reordered frames: samples are sent in decoding order
+func checkCompositionOffsets(ctts []byte) error {
+	if ctts == nil {
+		return nil
+	}
+	entries, count, err := mp4Table(ctts, 8)
+	if err != nil {
+		return err
+	}
+	for i := 1; i < count; i++ {
+		if !bytes.Equal(entries[i*8+4:i*8+8], entries[4:8]) {
+			return fmt.Errorf("B-frames are not supported, encode without them, e.g. with ffmpeg -bf 0")
+		}
+	}
+	return nil
+}
+
+// mp4SampleSizes reads the sample sizes, which fit in the box or, when they are all the same,
+// in the file of fileSize bytes
+func mp4SampleSizes(stsz []byte, fileSize int) ([]uint32, error) {
+	if len(stsz) < 12 {
+		return nil, fmt.Errorf("no sample sizes")
+	}
+	fixed := binary.BigEndian.Uint32(stsz[4:])
+	count := int(binary.BigEndian.Uint32(stsz[8:]))
+	if fixed == 0 && (count < 0 || count > (len(stsz)-12)/4) {
+		return nil, fmt.Errorf("truncated sample sizes")
+	}
+	if fixed > 0 && uint64(count)*uint64(fixed) > uint64(fileSize) {
+		return nil, fmt.Errorf("%d samples of %d bytes do not fit in the file", count, fixed)
+	}
+	sizes := make([]uint32, count)
+	for i := range sizes {
+		if sizes[i] = fixed; fixed == 0 {
+			sizes[i] = binary.BigEndian.Uint32(stsz[12+i*4:])
+		}
+	}
+	return sizes, nil
+}
+
+// mp4SampleOffsets finds where each sample is in the file from the chunk offsets and the samples per chunk
+func mp4SampleOffsets(stbl []byte, sizes []uint32) ([]uint64, error) {
+	samples := len(sizes)
+	var chunks []uint64
+	if stco := mp4Child(stbl, "stco"); stco != nil {
+		entries, count, err := mp4Table(stco, 4)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < count; i++ {
+			chunks = append(chunks, uint64(binary.BigEndian.Uint32(entries[i*4:])))
+		}
+	} else if co64 := mp4Child(stbl, "co64"); co64 != nil {
+		entries, count, err := mp4Table(co64, 8)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < count; i++ {
+			chunks = append(chunks, binary.BigEndian.Uint64(entries[i*8:]))
+		}
+	}
+	entries, count, err := mp4Table(mp4Child(stbl, "stsc"), 12)
+	if err != nil {
+		return nil, err
+	}
+
+	offsets := make([]uint64, 0, samples)
+	for i := 0; i < count && len(offsets) < samples; i++ {
+		first := int(binary.BigEndian.Uint32(entries[i*12:])) - 1
+		perChunk := int(binary.BigEndian.Uint32(entries[i*12+4:]))
+		last := len(chunks)
+		if i+1 < count {
+			last = int(binary.BigEndian.Uint32(entries[(i+1)*12:])) - 1
+		}
+		if first < 0 || last > len(chunks) {
+			return nil, fmt.Errorf("invalid sample to chunk table")
+		}
+		for c := first; c < last && len(offsets) < samples; c++ {
+			offset := chunks[c]
+			for s := 0; s < perChunk && len(offsets) < samples; s++ {
+				offsets = append(offsets, offset)
+				offset += uint64(sizes[len(offsets)-1])
+			}
+		}
+	}
+	if len(offsets) < samples {
+		return nil, fmt.Errorf("chunks hold %d of %d samples", len(offsets), samples)
+	}
+	return offsets, nil
+}
+
+func mp4SampleDurations(stts []byte, samples int, timescale uint32) ([]time.Duration, error) {
+	entries, count, err := mp4Table(stts, 8)
+	if err != nil {
+		return nil, err
+	}
+	durations := make([]time.Duration, 0, samples)
+	for i := 0; i < count && len(durations) < samples; i++ {
+		n := int(binary.BigEndian.Uint32(entries[i*8:]))
+		delta := time.Duration(binary.BigEndian.Uint32(entries[i*8+4:])) * time.Second / time.Duration(timescale)
+		for j := 0; j < n && len(durations) < samples; j++ {
+			durations = append(durations, delta)
+		}
+	}
+	if len(durations) < samples {
+		return nil, fmt.Errorf("time to sample table covers %d of %d samples", len(durations), samples)
+	}
+	return durations, nil
+}
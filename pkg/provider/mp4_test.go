@@ -0,0 +1,163 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func box(kind string, children ...[]byte) []byte {
+	data := make([]byte, 8)
+	copy(data[4:], kind)
+	for _, child := range children {
+		data = append(data, child...)
+	}
+	binary.BigEndian.PutUint32(data, uint32(len(data)))
+	return data
+}
+
+func u32s(values ...uint32) []byte {
+	data := make([]byte, 0, 4*len(values))
+	for _, v := range values {
+		data = binary.BigEndian.AppendUint32(data, v)
+	}
+	return data
+}
+
+// lengthPrefixed writes NAL units with 4 byte lengths, as MP4 samples hold them
+func lengthPrefixed(nals ...[]byte) []byte {
+	var data []byte
+	for _, nal := range nals {
+		data = binary.BigEndian.AppendUint32(data, uint32(len(nal)))
+		data = append(data, nal...)
+	}
+	return data
+}
+
+// writeMP4 writes a progressive MP4 with an audio track and an H.264 track of frames at 30 fps,
+// one keyframe per gopSize frames. The samples are in two chunks, after the moov box.
+func writeMP4(sps []byte, frames, gopSize int, ctts []byte) []byte {
+	pps := payload(0x68, 4)
+	var samples [][]byte
+	var sizes []uint32
+	for i := 0; i < frames; i++ {
+		header := byte(0x41)
+		if i%gopSize == 0 {
+			header = 0x65
+		}
+		sample := lengthPrefixed([]byte{0x09, 0xf0}, slice(header, true))
+		samples = append(samples, sample)
+		sizes = append(sizes, uint32(len(sample)))
+	}
+
+	avcC := []byte{1, sps[1], sps[2], sps[3], 0xff, 0xe1}
+	avcC = binary.BigEndian.AppendUint16(avcC, uint16(len(sps)))
+	avcC = append(avcC, sps...)
+	avcC = append(avcC, 1)
+	avcC = binary.BigEndian.AppendUint16(avcC, uint16(len(pps)))
+	avcC = append(avcC, pps...)
+	entry := make([]byte, 78)
+	binary.BigEndian.PutUint16(entry[24:], 320)
+	binary.BigEndian.PutUint16(entry[26:], 240)
+
+	half := uint32(frames / 2)
+	build := func(mdatOffset uint32) []byte {
+		var secondChunk uint32
+		for _, size := range sizes[:half] {
+			secondChunk += size
+		}
+		stbl := [][]byte{
+			box("stsd", u32s(0, 1), box("avc1", entry, box("avcC", avcC))),
+			box("stts", u32s(0, 1, uint32(frames), 3000)),
+			box("stsc", u32s(0, 1, 1, half, 1)),
+			box("stsz", u32s(0, 0, uint32(frames)), u32s(sizes...)),
+			box("stco", u32s(0, 2, mdatOffset, mdatOffset+secondChunk)),
+		}
+		if ctts != nil {
+			stbl = append(stbl, box("ctts", ctts))
+		}
+		return append(
+			box("ftyp", []byte("isom"), u32s(0x200), []byte("isomavc1")),
+			box("moov",
+				box("mvhd", make([]byte, 100)),
+				box("trak", box("mdia",
+					box("hdlr", u32s(0, 0), []byte("soun"), make([]byte, 12)),
+				)),
+				box("trak", box("mdia",
+					box("mdhd", u32s(0, 0, 0, 90000, 0, 0)),
+					box("hdlr", u32s(0, 0), []byte("vide"), make([]byte, 12)),
+					box("minf", box("stbl", stbl...)),
+				)),
+			)...,
+		)
+	}
+	// the offsets don't change the size of the header
+	header := build(0)
+	data := build(uint32(len(header) + 8))
+	var mdat [][]byte
+	mdat = append(mdat, samples...)
+	return append(data, box("mdat", mdat...)...)
+}
+
+func TestReadMP4H264(t *testing.T) {
+	sps := testSPS(66, 640, 360, 0)
+	track, err := readMP4H264(writeMP4(sps, 30, 15, nil))
+	require.NoError(t, err)
+	require.Equal(t, 320, track.width)
+	require.Equal(t, 240, track.height)
+	require.Equal(t, [][]byte{sps}, track.sps)
+	require.Len(t, track.pps, 1)
+	require.Len(t, track.samples, 30)
+	require.Equal(t, time.Second/30, track.samples[29].duration)
+	nals, err := track.nals(track.samples[15].data)
+	require.NoError(t, err)
+	require.Len(t, nals, 2)
+	require.EqualValues(t, 5, nalType(nals[1]))
+
+	// dimensions come from the SPS rather than the sample entry
+	file, err := probeH264("test.mp4", writeMP4(sps, 30, 15, nil))
+	require.NoError(t, err)
+	require.Equal(t, 640, file.spec.width)
+	require.Equal(t, 360, file.spec.height)
+	require.Equal(t, 30, file.spec.fps)
+	require.Len(t, file.units, 30)
+	require.Equal(t, []int{7, 8, 5}, nalTypes(file.units[0]))
+	require.Equal(t, []int{1}, nalTypes(file.units[1]))
+	require.Equal(t, []int{7, 8, 5}, nalTypes(file.units[15]))
+	require.True(t, file.units[15].keyframe)
+
+	// a constant composition offset is fine, varying ones mean B-frames
+	_, err = readMP4H264(writeMP4(sps, 30, 15, u32s(0, 1, 30, 3000)))
+	require.NoError(t, err)
+	_, err = readMP4H264(writeMP4(sps, 30, 15, u32s(0, 2, 1, 6000, 29, 3000)))
+	require.ErrorContains(t, err, "B-frames are not supported")
+
+	_, err = readMP4H264(writeMP4(sps, 30, 15, nil)[:200])
+	require.Error(t, err)
+	// sample counts are checked before anything is allocated for them
+	_, err = mp4SampleSizes(u32s(0, 0, 0xffffffff), 1<<20)
+	require.ErrorContains(t, err, "truncated sample sizes")
+	_, err = mp4SampleSizes(u32s(0, 100, 0xffffffff), 1<<20)
+	require.ErrorContains(t, err, "do not fit in the file")
+	sizes, err := mp4SampleSizes(u32s(0, 100, 3), 1<<20)
+	require.NoError(t, err)
+	require.Equal(t, []uint32{100, 100, 100}, sizes)
+	_, err = readMP4H264([]byte("not an mp4 file"))
+	require.ErrorIs(t, err, errNotMP4)
+}
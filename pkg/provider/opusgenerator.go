@@ -0,0 +1,107 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"context"
+	cryptorand "crypto/rand"
+	"math/rand/v2"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+	"github.com/pion/webrtc/v4/pkg/media"
+
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+const (
+	// bitrate of generated Opus audio per channel
+	GeneratedOpusChannelBitrate = 32000
+
+	// a generated talker speaks for generatedTalkFrames, then pauses for generatedPauseFrames
+	generatedTalkFrames  = 150
+	generatedPauseFrames = 50
+
+	// TOC bits of a 20ms fullband CELT frame, and of its stereo variant
+	celtFrameTOC  = 0xf8
+	celtStereoTOC = 0x04
+	// size of a silent frame
+	opusSilenceSize = 3
+
+	// level of the generated noise, in -dBov
+	generatedAudioLevel = 20
+)
+
+// OpusGenerator synthesizes Opus audio with any number of channels, without an encoder.
+// Random bits after a CELT TOC byte decode to noise, so talk spurts are noise frames sized for
+// the bitrate, and pauses are silent frames.
+type OpusGenerator struct {
+	lksdk.BaseSampleProvider
+	channels int
+	frame    int
+	level    uint8
+	// reused for every noise frame, since samples are packetized before the next one is taken
+	buffer []byte
+	noise  *rand.ChaCha8
+}
+
+// NewOpusGenerator generates mono audio for 1 channel, and stereo audio for 2
+func NewOpusGenerator(channels int) *OpusGenerator {
+	var seed [32]byte
+	_, _ = cryptorand.Read(seed[:])
+	return &OpusGenerator{
+		channels: channels,
+		buffer:   make([]byte, channels*GeneratedOpusChannelBitrate/8*int(defaultOpusFrameDuration/time.Millisecond)/1000),
+		// noise needn't be unpredictable, and a seeded generator is much cheaper than crypto/rand
+		noise: rand.NewChaCha8(seed),
+	}
+}
+
+func (g *OpusGenerator) Codec() webrtc.RTPCodecCapability {
+	return webrtc.RTPCodecCapability{
+		MimeType: webrtc.MimeTypeOpus,
+		Channels: uint16(g.channels),
+	}
+}
+
+func (g *OpusGenerator) Channels() int {
+	return g.channels
+}
+
+func (g *OpusGenerator) NextSample(_ context.Context) (media.Sample, error) {
+	toc := byte(celtFrameTOC)
+	if g.channels > 1 {
+		toc |= celtStereoTOC
+	}
+	talking := g.frame < generatedTalkFrames
+	g.frame = (g.frame + 1) % (generatedTalkFrames + generatedPauseFrames)
+	if !talking {
+		g.level = silentAudioLevel
+		return media.Sample{Data: OpusSilence(toc), Duration: defaultOpusFrameDuration}, nil
+	}
+	g.level = generatedAudioLevel
+	g.buffer[0] = toc
+	_, _ = g.noise.Read(g.buffer[1:])
+	return media.Sample{Data: g.buffer, Duration: defaultOpusFrameDuration}, nil
+}
+
+func (g *OpusGenerator) CurrentAudioLevel() uint8 {
+	return g.level
+}
+
+// OpusSilence is a CELT frame with the given TOC byte that decodes to silence
+func OpusSilence(toc byte) []byte {
+	return []byte{toc, 0xff, 0xfe}
+}
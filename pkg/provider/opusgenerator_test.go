@@ -0,0 +1,49 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpusGenerator(t *testing.T) {
+	g := NewOpusGenerator(2)
+	require.EqualValues(t, 2, g.Codec().Channels)
+
+	talking, silent := 0, 0
+	for i := 0; i < generatedTalkFrames+generatedPauseFrames; i++ {
+		sample, err := g.NextSample(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, defaultOpusFrameDuration, sample.Duration)
+		// a stereo CELT frame
+		require.Equal(t, byte(0xfc), sample.Data[0])
+		if len(sample.Data) == 3 {
+			silent++
+		} else {
+			require.Len(t, sample.Data, 160)
+			talking++
+		}
+	}
+	require.Equal(t, generatedTalkFrames, talking)
+	require.Equal(t, generatedPauseFrames, silent)
+
+	sample, err := NewOpusGenerator(1).NextSample(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, byte(0xf8), sample.Data[0])
+	require.Len(t, sample.Data, 80)
+}
@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"context"
 	"io"
+	"math"
 	"time"
 
 	"github.com/pion/webrtc/v4"
@@ -29,65 +30,118 @@ import (
 
 const (
 	defaultOpusFrameDuration = 20 * time.Millisecond
+
+	// RFC 6464 audio levels, in -dBov: assumed for the loudest frames of a clip, and of silence
+	loudestAudioLevel = 10
+	silentAudioLevel  = 127
 )
 
+// signature of the Ogg Opus comment header, which follows the ID header
+var opusTags = []byte("OpusTags")
+
 type OpusAudioLooper struct {
 	lksdk.BaseSampleProvider
-	buffer      []byte
-	reader      *oggreader.OggReader
+	buffer []byte
+	// audio pages of buffer, the next one to send, and the granule position before it
+	pages       []oggPage
+	next        int
 	lastGranule uint64
+	err         error
+	// from the Opus ID header
+	channels int
+	// size of the largest page
+	loudest int
+	// estimated level of the last sample
+	level uint8
 }
 
 func NewOpusAudioLooper(input io.Reader) (*OpusAudioLooper, error) {
-	l := &OpusAudioLooper{}
-
 	buf := bytes.NewBuffer(nil)
 
 	if _, err := io.Copy(buf, input); err != nil {
 		return nil, err
 	}
-	l.buffer = buf.Bytes()
+	return newOpusAudioLooper(buf.Bytes()), nil
+}
 
-	return l, nil
+// newOpusAudioLooper loops over buffer without copying it
+func newOpusAudioLooper(buffer []byte) *OpusAudioLooper {
+	l := &OpusAudioLooper{buffer: buffer, channels: 1, level: silentAudioLevel}
+	_, header, err := oggreader.NewWith(bytes.NewReader(buffer))
+	if err != nil {
+		l.err = err
+		return l
+	}
+	if header.Channels > 0 {
+		l.channels = int(header.Channels)
+	}
+	if l.pages, l.err = splitOggPages(buffer); l.err == nil && len(l.pages) == 0 {
+		l.err = io.EOF
+	}
+	for _, page := range l.pages {
+		l.loudest = max(l.loudest, len(page.data))
+	}
+	return l
 }
 
 func (l *OpusAudioLooper) Codec() webrtc.RTPCodecCapability {
 	return webrtc.RTPCodecCapability{
 		MimeType: "audio/opus",
-		Channels: 1,
+		Channels: uint16(l.channels),
 	}
 }
 
-func (l *OpusAudioLooper) NextSample(_ctx context.Context) (media.Sample, error) {
-	return l.nextSample(true)
+// Channels is the number of channels the looped audio was encoded with
+func (l *OpusAudioLooper) Channels() int {
+	return l.channels
 }
 
-func (l *OpusAudioLooper) nextSample(rewindEOF bool) (media.Sample, error) {
+func (l *OpusAudioLooper) NextSample(_ctx context.Context) (media.Sample, error) {
 	sample := media.Sample{}
-	if l.reader == nil {
-		var err error
-		l.lastGranule = 0
-		l.reader, _, err = oggreader.NewWith(bytes.NewReader(l.buffer))
-		if err != nil {
-			return sample, err
-		}
+	if l.err != nil {
+		return sample, l.err
 	}
-
-	pageData, pageHeader, err := l.reader.ParseNextPage()
-	if err == io.EOF && rewindEOF {
-		l.reader = nil
-		return l.nextSample(false)
-	}
-	if err != nil {
-		return sample, err
+	if l.next >= len(l.pages) {
+		l.next = 0
+		l.lastGranule = 0
 	}
-	sampleCount := float64(pageHeader.GranulePosition - l.lastGranule)
-	l.lastGranule = pageHeader.GranulePosition
+	page := l.pages[l.next]
+	l.next++
+	sampleCount := float64(page.granule - l.lastGranule)
+	l.lastGranule = page.granule
 
-	sample.Data = pageData
+	sample.Data = page.data
+	l.level = opusAudioLevel(len(page.data), l.loudest)
 	sample.Duration = time.Duration((sampleCount/48000)*1000) * time.Millisecond
 	if sample.Duration == 0 {
 		sample.Duration = defaultOpusFrameDuration
 	}
 	return sample, nil
 }
+
+// CurrentAudioLevel estimates the level of the last sample, so that the server can detect voice
+// activity and active speakers in the recorded speech
+func (l *OpusAudioLooper) CurrentAudioLevel() uint8 {
+	return l.level
+}
+
+// SeekTo makes the looper start after the given fraction (0-1) of the file, so that publishers
+// of the same clip don't speak in unison. Later loops start from the beginning as usual.
+func (l *OpusAudioLooper) SeekTo(fraction float64) {
+	l.next = min(int(fraction*float64(len(l.pages))), len(l.pages))
+	l.lastGranule = 0
+	if l.next > 0 {
+		l.lastGranule = l.pages[l.next-1].granule
+	}
+}
+
+// opusAudioLevel estimates the RFC 6464 audio level of an Opus frame without decoding it,
+// from its size relative to the loudest frame. With VBR, frames grow with the loudness and
+// complexity of the speech.
+func opusAudioLevel(size, loudest int) uint8 {
+	if size <= opusSilenceSize || loudest <= 0 {
+		return silentAudioLevel
+	}
+	level := math.Round(loudestAudioLevel - 20*math.Log10(float64(size)/float64(loudest)))
+	return uint8(min(max(level, 0), silentAudioLevel))
+}
@@ -0,0 +1,70 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/pion/rtp"
+	"github.com/pion/webrtc/v4/pkg/media/oggwriter"
+	"github.com/stretchr/testify/require"
+)
+
+// writeOgg writes frames to an Ogg Opus file, one 20ms frame per page
+func writeOgg(t *testing.T, channels uint16, frames [][]byte) []byte {
+	buf := &bytes.Buffer{}
+	w, err := oggwriter.NewWith(buf, 48000, channels)
+	require.NoError(t, err)
+	for i, frame := range frames {
+		require.NoError(t, w.WriteRTP(&rtp.Packet{
+			Header:  rtp.Header{Timestamp: uint32(i * 960)},
+			Payload: frame,
+		}))
+	}
+	return buf.Bytes()
+}
+
+func TestOpusAudioLooper(t *testing.T) {
+	loud := bytes.Repeat([]byte{0x78}, 100)
+	quiet := bytes.Repeat([]byte{0x78}, 10)
+	silent := OpusSilence(0x78)
+	looper := newOpusAudioLooper(writeOgg(t, 2, [][]byte{loud, quiet, silent, loud}))
+	require.Equal(t, 2, looper.Channels())
+	require.EqualValues(t, 2, looper.Codec().Channels)
+	require.Len(t, looper.pages, 4)
+	require.Equal(t, 100, looper.loudest)
+
+	var levels []uint8
+	for i := 0; i < 4; i++ {
+		sample, err := looper.NextSample(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, defaultOpusFrameDuration, sample.Duration)
+		levels = append(levels, looper.CurrentAudioLevel())
+	}
+	// 20 dB below the loudest frame, and silence
+	require.Equal(t, []uint8{loudestAudioLevel, loudestAudioLevel + 20, silentAudioLevel, loudestAudioLevel}, levels)
+
+	// starts from the middle, then loops from the beginning
+	looper.SeekTo(0.5)
+	var sizes []int
+	for i := 0; i < 4; i++ {
+		sample, err := looper.NextSample(context.Background())
+		require.NoError(t, err)
+		sizes = append(sizes, len(sample.Data))
+	}
+	require.Equal(t, []int{3, 100, 100, 10}, sizes)
+}
@@ -0,0 +1,106 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"strings"
+
+	"github.com/pion/webrtc/v4"
+	"github.com/pion/webrtc/v4/pkg/media/h264reader"
+)
+
+// SeekToKeyframeAfter makes the looper start at the first keyframe after the given
+// fraction (0-1) of the file, or at the beginning if there is none. Later loops start
+// from the beginning as usual.
+func (l *VPVideoLooper) SeekToKeyframeAfter(fraction float64) {
+	if l.index() != nil {
+		return
+	}
+	var keyframes []int
+	for i, frame := range l.frames {
+		if l.isKeyframe(frame) {
+			keyframes = append(keyframes, i)
+		}
+	}
+	l.next = firstAtOrAfter(keyframes, int(fraction*float64(len(l.frames))))
+}
+
+func (l *VPVideoLooper) isKeyframe(frame []byte) bool {
+	return isVPKeyframe(frame, l.isVp9Encoding)
+}
+
+func isVPKeyframe(frame []byte, vp9 bool) bool {
+	if len(frame) == 0 {
+		return false
+	}
+	if !vp9 {
+		return frame[0]&0x01 == 0
+	}
+	// uncompressed header: frame_marker(2) profile_low_bit(1) profile_high_bit(1)
+	// [reserved_zero(1) for profile 3] show_existing_frame(1) frame_type(1)
+	b := frame[0]
+	shift := 3
+	if b&0x30 == 0x30 {
+		shift = 2
+	}
+	showExisting := (b >> shift) & 0x01
+	frameType := (b >> (shift - 1)) & 0x01
+	return showExisting == 0 && frameType == 0
+}
+
+// SeekToKeyframeAfter makes the looper start at the first SPS after the given fraction (0-1)
+// of the file's NAL units, or at the beginning if there is none. Later loops start from the
+// beginning as usual.
+func (l *H264VideoLooper) SeekToKeyframeAfter(fraction float64) {
+	if l.index() != nil {
+		return
+	}
+	var keyframes []int
+	for i, nal := range l.nals {
+		if h264reader.NalUnitType(nal[0]&0x1f) == h264reader.NalUnitTypeSPS {
+			keyframes = append(keyframes, i)
+		}
+	}
+	l.next = firstAtOrAfter(keyframes, int(fraction*float64(len(l.nals))))
+}
+
+// IsInterFrame reports whether a sample of a looper of the codec is a frame predicted from earlier
+// ones, rather than a keyframe or, in H.264, a parameter set or other NAL unit that isn't a slice
+func IsInterFrame(mimeType string, sample []byte) bool {
+	if len(sample) == 0 {
+		return false
+	}
+	switch {
+	case strings.EqualFold(mimeType, webrtc.MimeTypeH264):
+		return h264reader.NalUnitType(sample[0]&0x1f) == h264reader.NalUnitTypeCodedSliceNonIdr
+	case strings.EqualFold(mimeType, webrtc.MimeTypeVP8):
+		return !isVPKeyframe(sample, false)
+	case strings.EqualFold(mimeType, webrtc.MimeTypeVP9):
+		return !isVPKeyframe(sample, true)
+	case strings.EqualFold(mimeType, webrtc.MimeTypeAV1):
+		return !isAV1Keyframe(sample)
+	}
+	return false
+}
+
+// firstAtOrAfter returns the first of the sorted indexes that is at least target, or 0
+func firstAtOrAfter(indexes []int, target int) int {
+	for _, i := range indexes {
+		if i >= target {
+			return i
+		}
+	}
+	return 0
+}
@@ -0,0 +1,84 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"fmt"
+	"math"
+)
+
+// SimulcastLayer configures one layer of a simulcast ladder
+type SimulcastLayer struct {
+	// factor the top resolution is scaled down by, 1 for full resolution
+	ScaleDown float64
+	// highest bitrate in kbps the layer is sent at, unlimited when 0
+	MaxKbps int
+}
+
+// CreateSimulcastLoopers returns a looper for each layer of the ladder, lowest quality first. Each layer
+// uses the embedded clip scaled down from the resolution's top clip by the layer's factor. Layers over
+// their max bitrate are sent at a proportionally lower frame rate, like an encoder dropping frames.
+func CreateSimulcastLoopers(resolution, codecFilter string, ladder []SimulcastLayer) ([]VideoLooper, error) {
+	var candidates [][]*videoSpec
+	for _, specs := range videoSpecs {
+		if codecFilter != "" && specs[0].codec != codecFilter {
+			continue
+		}
+		if matchLadder(specs[:numLayers(resolution)], ladder) != nil {
+			candidates = append(candidates, specs)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("no embedded videos have the resolutions of the simulcast ladder")
+	}
+	chosen := candidates[int(videoIndex.Inc())%len(candidates)]
+
+	loopers := make([]VideoLooper, 0, len(ladder))
+	for i, clip := range matchLadder(chosen[:numLayers(resolution)], ladder) {
+		spec := *clip
+		if maxKbps := ladder[i].MaxKbps; maxKbps > 0 && maxKbps < clip.kbps {
+			spec.fps = max(1, clip.fps*maxKbps/clip.kbps)
+			spec.kbps = clip.kbps * spec.fps / clip.fps
+		}
+		looper, err := openEmbeddedLooper(clip.Name(), &spec)
+		if err != nil {
+			return nil, err
+		}
+		if looper == nil {
+			return nil, fmt.Errorf("unsupported codec %s", spec.codec)
+		}
+		loopers = append(loopers, looper)
+	}
+	return loopers, nil
+}
+
+// matchLadder returns the clip of specs for each layer, or nil if a layer has no clip of its resolution
+func matchLadder(specs []*videoSpec, ladder []SimulcastLayer) []*videoSpec {
+	top := specs[len(specs)-1]
+	matched := make([]*videoSpec, 0, len(ladder))
+	for _, layer := range ladder {
+		var found *videoSpec
+		for _, spec := range specs {
+			if math.Abs(float64(top.height)/layer.ScaleDown-float64(spec.height)) < 1 {
+				found = spec
+			}
+		}
+		if found == nil {
+			return nil
+		}
+		matched = append(matched, found)
+	}
+	return matched
+}
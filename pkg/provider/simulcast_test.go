@@ -0,0 +1,55 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/protocol/livekit"
+)
+
+func TestMatchLadder(t *testing.T) {
+	specs := createSpecs("test", vp8Codec, 150, 600, 2000)
+	matched := matchLadder(specs, []SimulcastLayer{{ScaleDown: 4}, {ScaleDown: 1}})
+	require.Equal(t, []*videoSpec{specs[0], specs[2]}, matched)
+	require.Nil(t, matchLadder(specs, []SimulcastLayer{{ScaleDown: 3}}))
+	// scales are relative to the top clip of the resolution
+	require.Equal(t, []*videoSpec{specs[0], specs[1]}, matchLadder(specs[:2], []SimulcastLayer{{ScaleDown: 2}, {ScaleDown: 1}}))
+
+	circles := []*videoSpec{circlesSpec(180, 200, 15), circlesSpec(360, 700, 20), circlesSpec(540, 2000, 30)}
+	require.Equal(t, []*videoSpec{circles[0], circles[2]}, matchLadder(circles, []SimulcastLayer{{ScaleDown: 3}, {ScaleDown: 1}}))
+}
+
+func TestCreateSimulcastLoopers(t *testing.T) {
+	loopers, err := CreateSimulcastLoopers("high", vp8Codec, []SimulcastLayer{
+		{ScaleDown: 2},
+		{ScaleDown: 1, MaxKbps: 1000},
+	})
+	require.NoError(t, err)
+	require.Len(t, loopers, 2)
+	require.EqualValues(t, 360, loopers[0].ToLayer(livekit.VideoQuality_LOW).Height)
+	require.EqualValues(t, 600_000, loopers[0].ToLayer(livekit.VideoQuality_LOW).Bitrate)
+
+	top := loopers[1].ToLayer(livekit.VideoQuality_MEDIUM)
+	require.EqualValues(t, 720, top.Height)
+	require.EqualValues(t, 1000_000, top.Bitrate)
+	require.Equal(t, time.Second/15, loopers[1].(*VPVideoLooper).frameDuration)
+
+	_, err = CreateSimulcastLoopers("medium", vp8Codec, []SimulcastLayer{{ScaleDown: 4}, {ScaleDown: 1}})
+	require.Error(t, err)
+}
@@ -0,0 +1,245 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+	"github.com/pion/webrtc/v4/pkg/media"
+
+	"github.com/livekit/protocol/livekit"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+// TestPattern is the size and frame rate of generated video
+type TestPattern struct {
+	Width  int
+	Height int
+	FPS    int
+}
+
+const (
+	testPatternPrefix = "test-pattern"
+	// smallest layer, so that the counter and clock stay legible
+	minTestPatternHeight = 64
+	// time the box takes to cross the frame
+	testPatternSweep = 2 * time.Second
+)
+
+// color bars, as Y, U and V
+var testPatternBars = [][3]byte{
+	{235, 128, 128}, // white
+	{210, 16, 146},  // yellow
+	{170, 166, 16},  // cyan
+	{145, 54, 34},   // green
+	{106, 202, 222}, // magenta
+	{81, 90, 240},   // red
+	{41, 240, 110},  // blue
+}
+
+// 5x7 glyphs of the counter and clock, one row per byte
+var testPatternFont = map[rune][7]byte{
+	'0': {0b01110, 0b10001, 0b10011, 0b10101, 0b11001, 0b10001, 0b01110},
+	'1': {0b00100, 0b01100, 0b00100, 0b00100, 0b00100, 0b00100, 0b01110},
+	'2': {0b01110, 0b10001, 0b00001, 0b00010, 0b00100, 0b01000, 0b11111},
+	'3': {0b11111, 0b00010, 0b00100, 0b00010, 0b00001, 0b10001, 0b01110},
+	'4': {0b00010, 0b00110, 0b01010, 0b10010, 0b11111, 0b00010, 0b00010},
+	'5': {0b11111, 0b10000, 0b11110, 0b00001, 0b00001, 0b10001, 0b01110},
+	'6': {0b00110, 0b01000, 0b10000, 0b11110, 0b10001, 0b10001, 0b01110},
+	'7': {0b11111, 0b00001, 0b00010, 0b00100, 0b01000, 0b01000, 0b01000},
+	'8': {0b01110, 0b10001, 0b10001, 0b01110, 0b10001, 0b10001, 0b01110},
+	'9': {0b01110, 0b10001, 0b10001, 0b01111, 0b00001, 0b00010, 0b01100},
+	':': {0b00000, 0b01100, 0b01100, 0b00000, 0b01100, 0b01100, 0b00000},
+	'.': {0b00000, 0b00000, 0b00000, 0b00000, 0b00000, 0b01100, 0b01100},
+	'#': {0b01010, 0b01010, 0b11111, 0b01010, 0b11111, 0b01010, 0b01010},
+}
+
+// CreateTestPatternLoopers builds loopers generating a test pattern, encoded to codec, VP8 or VP9
+// (VP8 when empty), which needs a build with libvpx. Like CreateVideoLoopers, resolution decides
+// how many layers are used, each half the size of the next, and only the highest of those is
+// kept when simulcast is off.
+func CreateTestPatternLoopers(pattern TestPattern, resolution, codec string, simulcast bool) ([]VideoLooper, error) {
+	if codec == "" {
+		codec = vp8Codec
+	}
+	if codec != vp8Codec && codec != vp9Codec {
+		return nil, fmt.Errorf("the test pattern can be encoded to vp8 or vp9, not %s", codec)
+	}
+	layers := numLayers(resolution)
+	first := 0
+	if !simulcast {
+		first = layers - 1
+	}
+	var loopers []VideoLooper
+	for i := first; i < layers; i++ {
+		scale := 1 << (layers - 1 - i)
+		// I420 needs even dimensions
+		width, height := (pattern.Width/scale)&^1, (pattern.Height/scale)&^1
+		if height < minTestPatternHeight {
+			return nil, fmt.Errorf("test pattern layers must be at least %d pixels high, %dx%d has a %dx%d layer",
+				minTestPatternHeight, pattern.Width, pattern.Height, width, height)
+		}
+		looper, err := newTestPatternLooper(&videoSpec{
+			codec:  codec,
+			prefix: testPatternPrefix,
+			width:  width,
+			height: height,
+			fps:    pattern.FPS,
+			kbps:   rawKbps(width, height, pattern.FPS),
+		})
+		if err != nil {
+			return nil, err
+		}
+		loopers = append(loopers, looper)
+	}
+	return loopers, nil
+}
+
+// TestPatternLooper generates color bars with a moving box, a frame counter and the wall clock time
+// burned in, encoding each frame as it is sent. Comparing the clock of a received frame with the
+// subscriber's shows the end to end latency, and gaps in the counter show dropped frames.
+type TestPatternLooper struct {
+	lksdk.BaseSampleProvider
+	spec          *videoSpec
+	frameDuration time.Duration
+	// the bars, drawn once
+	background []byte
+
+	lock    sync.Mutex
+	encoder videoEncoder
+	frame   []byte
+	count   int
+	// offset of the box along its sweep, in frames
+	phase int
+}
+
+func newTestPatternLooper(spec *videoSpec) (*TestPatternLooper, error) {
+	encoder, err := newVideoEncoder(spec.codec, spec.width, spec.height, spec.fps, spec.kbps)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode the test pattern: %w", err)
+	}
+	l := &TestPatternLooper{
+		spec:          spec,
+		frameDuration: time.Second / time.Duration(spec.fps),
+		encoder:       encoder,
+		background:    make([]byte, spec.width*spec.height*3/2),
+	}
+	l.frame = make([]byte, len(l.background))
+	for i, bar := range testPatternBars {
+		x0 := spec.width * i / len(testPatternBars)
+		x1 := spec.width * (i + 1) / len(testPatternBars)
+		l.fill(l.background, x0, 0, x1-x0, spec.height, bar)
+	}
+	return l, nil
+}
+
+func (l *TestPatternLooper) Codec() webrtc.RTPCodecCapability {
+	return vpCapability(l.spec.codec == vp9Codec)
+}
+
+func (l *TestPatternLooper) ToLayer(quality livekit.VideoQuality) *livekit.VideoLayer {
+	return l.spec.ToVideoLayer(quality)
+}
+
+func (l *TestPatternLooper) NextSample(_ context.Context) (media.Sample, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.render(l.count, time.Now())
+	data, err := l.encoder.encode(l.frame, l.count == 0)
+	if err != nil {
+		return media.Sample{}, err
+	}
+	l.count++
+	return media.Sample{Data: data, Duration: l.frameDuration}, nil
+}
+
+// SeekToKeyframeAfter moves the box the given fraction (0-1) along its sweep, so that publishers
+// don't all look the same. There are no keyframes to seek to, the first frame is always one.
+func (l *TestPatternLooper) SeekToKeyframeAfter(fraction float64) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.phase = int(fraction * testPatternSweep.Seconds() * float64(l.spec.fps))
+}
+
+// render draws frame count, sent at now
+func (l *TestPatternLooper) render(count int, now time.Time) {
+	copy(l.frame, l.background)
+	width, height := l.spec.width, l.spec.height
+
+	// a black box going back and forth across the middle
+	size := (height / 4) &^ 1
+	sweep := int(testPatternSweep.Seconds() * float64(l.spec.fps))
+	step := (count + l.phase) % (2 * sweep)
+	if step >= sweep {
+		step = 2*sweep - step
+	}
+	x := (width - size) * step / sweep
+	l.fill(l.frame, x, (height-size)/2, size, size, [3]byte{16, 128, 128})
+
+	// counter and clock in white on black, at the top left
+	scale := max(1, height/120)
+	lines := []string{fmt.Sprintf("#%07d", count), now.UTC().Format("15:04:05.000")}
+	charWidth, lineHeight := 6*scale, 9*scale
+	columns := max(len(lines[0]), len(lines[1]))
+	l.fill(l.frame, 0, 0, (columns+1)*charWidth, (len(lines)+1)*lineHeight, [3]byte{16, 128, 128})
+	for i, line := range lines {
+		for j, char := range line {
+			l.glyph(testPatternFont[char], charWidth/2+j*charWidth, lineHeight/2+i*lineHeight, scale)
+		}
+	}
+}
+
+// glyph draws a character in white with its top left corner at x, y, each dot scale pixels wide.
+// Dots past the right edge of narrow frames are left out.
+func (l *TestPatternLooper) glyph(rows [7]byte, x, y, scale int) {
+	for row, bits := range rows {
+		for col := 0; col < 5; col++ {
+			if bits&(1<<(4-col)) == 0 || x+(col+1)*scale > l.spec.width {
+				continue
+			}
+			for dy := 0; dy < scale; dy++ {
+				start := (y+row*scale+dy)*l.spec.width + x + col*scale
+				for dx := 0; dx < scale; dx++ {
+					l.frame[start+dx] = 235
+				}
+			}
+		}
+	}
+}
+
+// fill paints a rectangle of an I420 frame with color, clipped to the frame. The chroma planes
+// cover it at half resolution.
+func (l *TestPatternLooper) fill(frame []byte, x, y, w, h int, color [3]byte) {
+	width, height := l.spec.width, l.spec.height
+	x0, y0 := max(x, 0), max(y, 0)
+	x1, y1 := min(x+w, width), min(y+h, height)
+	for row := y0; row < y1; row++ {
+		for col := x0; col < x1; col++ {
+			frame[row*width+col] = color[0]
+		}
+	}
+	chroma := frame[width*height:]
+	planeSize := width * height / 4
+	for row := y0 / 2; row < (y1+1)/2; row++ {
+		for col := x0 / 2; col < (x1+1)/2; col++ {
+			chroma[row*width/2+col] = color[1]
+			chroma[planeSize+row*width/2+col] = color[2]
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateTestPatternLoopers(t *testing.T) {
+	useFakeEncoder(t)
+	loopers, err := CreateTestPatternLoopers(TestPattern{Width: 1280, Height: 720, FPS: 30}, "high", "", true)
+	require.NoError(t, err)
+	require.Len(t, loopers, 3)
+	for i, size := range [][2]uint32{{320, 180}, {640, 360}, {1280, 720}} {
+		layer := loopers[i].ToLayer(0)
+		require.Equal(t, size, [2]uint32{layer.Width, layer.Height})
+		require.Equal(t, "video/vp8", loopers[i].Codec().MimeType)
+	}
+	require.EqualValues(t, 1_935_000, loopers[2].ToLayer(0).Bitrate)
+
+	loopers, err = CreateTestPatternLoopers(TestPattern{Width: 1280, Height: 720, FPS: 30}, "medium", "vp9", false)
+	require.NoError(t, err)
+	require.Len(t, loopers, 1)
+	require.EqualValues(t, 720, loopers[0].ToLayer(0).Height)
+	require.Equal(t, "video/vp9", loopers[0].Codec().MimeType)
+
+	_, err = CreateTestPatternLoopers(TestPattern{Width: 1280, Height: 720, FPS: 30}, "high", "h264", false)
+	require.EqualError(t, err, "the test pattern can be encoded to vp8 or vp9, not h264")
+	_, err = CreateTestPatternLoopers(TestPattern{Width: 320, Height: 180, FPS: 30}, "high", "", true)
+	require.EqualError(t, err, "test pattern layers must be at least 64 pixels high, 320x180 has a 80x44 layer")
+}
+
+func TestTestPatternLooper(t *testing.T) {
+	useFakeEncoder(t)
+	loopers, err := CreateTestPatternLoopers(TestPattern{Width: 320, Height: 180, FPS: 30}, "low", "", false)
+	require.NoError(t, err)
+	looper := loopers[0].(*TestPatternLooper)
+
+	// only the first frame is forced to be a keyframe
+	for i, kind := range []byte{'K', 'D', 'D'} {
+		sample, err := looper.NextSample(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, kind, sample.Data[0], i)
+		require.Equal(t, time.Second/30, sample.Duration)
+	}
+
+	at := time.Date(2025, 1, 1, 12, 34, 56, 789e6, time.UTC)
+	looper.render(0, at)
+	first := bytes.Clone(looper.frame)
+	looper.render(0, at)
+	require.Equal(t, first, looper.frame)
+
+	// the '#' of the counter, white on black, at the top left
+	pixel := func(x, y int) byte { return first[y*320+x] }
+	require.Equal(t, byte(235), pixel(4, 4))
+	require.Equal(t, byte(16), pixel(3, 4))
+	// the box starts at the left edge
+	require.Equal(t, byte(16), pixel(0, 90))
+	require.NotEqual(t, byte(16), pixel(60, 90))
+
+	// the counter, clock and box change from frame to frame
+	looper.render(1, at)
+	require.NotEqual(t, first[:320*40], looper.frame[:320*40])
+	looper.render(0, at.Add(time.Millisecond))
+	require.NotEqual(t, first[:320*40], looper.frame[:320*40])
+	// a quarter of the way, at x 69
+	looper.render(15, at)
+	require.NotEqual(t, byte(16), looper.frame[90*320])
+	require.Equal(t, byte(16), looper.frame[90*320+90])
+
+	// halfway through its sweep, the box is in the middle
+	looper.SeekToKeyframeAfter(0.5)
+	looper.render(0, at)
+	require.Equal(t, byte(16), looper.frame[90*320+160])
+	require.NotEqual(t, byte(16), looper.frame[90*320])
+}
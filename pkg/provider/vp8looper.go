@@ -22,7 +22,6 @@ import (
 
 	"github.com/pion/webrtc/v4"
 	"github.com/pion/webrtc/v4/pkg/media"
-	"github.com/pion/webrtc/v4/pkg/media/ivfreader"
 
 	"github.com/livekit/protocol/livekit"
 	lksdk "github.com/livekit/server-sdk-go/v2"
@@ -33,32 +32,40 @@ type VPVideoLooper struct {
 	buffer        []byte
 	frameDuration time.Duration
 	spec          *videoSpec
-	reader        *ivfreader.IVFReader
-	ivfTimebase   float64
-	lastTimestamp uint64
 	isVp9Encoding bool
+	// frames of buffer, indexed on first use, and the next one to send
+	frames [][]byte
+	next   int
+	err    error
 }
 
 func NewVPVideoLooper(input io.Reader, spec *videoSpec, isVp9Encoding bool) (*VPVideoLooper, error) {
-	l := &VPVideoLooper{
-		spec:          spec,
-		frameDuration: time.Second / time.Duration(spec.fps),
-		isVp9Encoding: isVp9Encoding,
-	}
-
 	buf := bytes.NewBuffer(nil)
 
 	if _, err := io.Copy(buf, input); err != nil {
 		return nil, err
 	}
-	l.buffer = buf.Bytes()
+	return newVPVideoLooper(buf.Bytes(), spec, isVp9Encoding), nil
+}
 
-	return l, nil
+// newVPVideoLooper loops over buffer without copying it
+func newVPVideoLooper(buffer []byte, spec *videoSpec, isVp9Encoding bool) *VPVideoLooper {
+	return &VPVideoLooper{
+		buffer:        buffer,
+		spec:          spec,
+		frameDuration: time.Second / time.Duration(spec.fps),
+		isVp9Encoding: isVp9Encoding,
+	}
 }
 
 func (l *VPVideoLooper) Codec() webrtc.RTPCodecCapability {
+	return vpCapability(l.isVp9Encoding)
+}
+
+// vpCapability is the codec of VP8 or VP9 loopers
+func vpCapability(isVp9Encoding bool) webrtc.RTPCodecCapability {
 	encoding := "vp9"
-	if !l.isVp9Encoding {
+	if !isVp9Encoding {
 		encoding = "vp8"
 	}
 	return webrtc.RTPCodecCapability{
@@ -72,39 +79,33 @@ func (l *VPVideoLooper) Codec() webrtc.RTPCodecCapability {
 }
 
 func (l *VPVideoLooper) NextSample(_ctx context.Context) (media.Sample, error) {
-	return l.nextSample(true)
+	return l.nextSample()
 }
 
 func (l *VPVideoLooper) ToLayer(quality livekit.VideoQuality) *livekit.VideoLayer {
 	return l.spec.ToVideoLayer(quality)
 }
 
-func (l *VPVideoLooper) nextSample(rewindEOF bool) (media.Sample, error) {
-	sample := media.Sample{}
-	if l.reader == nil {
-		var err error
-		var ivfheader *ivfreader.IVFFileHeader
-
-		l.reader, ivfheader, err = ivfreader.NewWith(bytes.NewReader(l.buffer))
-		if err != nil {
-			return sample, err
+func (l *VPVideoLooper) index() error {
+	if l.frames == nil && l.err == nil {
+		if l.frames, l.err = splitIVF(l.buffer); l.err == nil && len(l.frames) == 0 {
+			l.err = io.EOF
 		}
-		l.ivfTimebase = float64(ivfheader.TimebaseNumerator) / float64(ivfheader.TimebaseDenominator)
 	}
+	return l.err
+}
 
-	frame, header, err := l.reader.ParseNextFrame()
-	if err == io.EOF && rewindEOF {
-		l.reader = nil
-		return l.nextSample(false)
-	}
-	if err != nil {
+func (l *VPVideoLooper) nextSample() (media.Sample, error) {
+	sample := media.Sample{}
+	if err := l.index(); err != nil {
 		return sample, err
 	}
-	delta := header.Timestamp - l.lastTimestamp
-	sample.Data = frame
-	// this should be correct too, but we'll use the known frame-rates below
-	sample.Duration = time.Duration(l.ivfTimebase*float64(delta)*1000) * time.Millisecond
-	l.lastTimestamp = header.Timestamp
+	if l.next >= len(l.frames) {
+		l.next = 0
+	}
+	sample.Data = l.frames[l.next]
+	l.next++
+	// IVF timestamps are ignored in favor of the known frame rate
 	sample.Duration = l.frameDuration
 	return sample, nil
 }
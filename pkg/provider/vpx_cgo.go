@@ -0,0 +1,150 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build vpx && cgo
+
+package provider
+
+/*
+#cgo pkg-config: vpx
+#include <stdlib.h>
+#include <string.h>
+#include <vpx/vpx_encoder.h>
+#include <vpx/vp8cx.h>
+
+typedef struct {
+	vpx_codec_ctx_t codec;
+	unsigned int width;
+	unsigned int height;
+	vpx_codec_pts_t pts;
+} lk_vpx_encoder;
+
+// lk_vpx_open sets up a single threaded real time CBR encoder, with a keyframe at least every 2 seconds
+static vpx_codec_err_t lk_vpx_open(lk_vpx_encoder *e, int vp9, unsigned int width, unsigned int height, unsigned int fps, unsigned int kbps) {
+	vpx_codec_iface_t *iface = vp9 ? vpx_codec_vp9_cx() : vpx_codec_vp8_cx();
+	vpx_codec_enc_cfg_t cfg;
+	vpx_codec_err_t err = vpx_codec_enc_config_default(iface, &cfg, 0);
+	if (err != VPX_CODEC_OK) {
+		return err;
+	}
+	cfg.g_w = width;
+	cfg.g_h = height;
+	cfg.g_timebase.num = 1;
+	cfg.g_timebase.den = fps;
+	cfg.g_threads = 1;
+	cfg.g_lag_in_frames = 0;
+	cfg.g_error_resilient = VPX_ERROR_RESILIENT_DEFAULT;
+	cfg.rc_end_usage = VPX_CBR;
+	cfg.rc_target_bitrate = kbps;
+	cfg.rc_dropframe_thresh = 0;
+	cfg.kf_mode = VPX_KF_AUTO;
+	cfg.kf_max_dist = 2 * fps;
+	err = vpx_codec_enc_init(&e->codec, iface, &cfg, 0);
+	if (err != VPX_CODEC_OK) {
+		return err;
+	}
+	vpx_codec_control(&e->codec, VP8E_SET_CPUUSED, vp9 ? 8 : 16);
+	e->width = width;
+	e->height = height;
+	return VPX_CODEC_OK;
+}
+
+// lk_vpx_encode compresses an I420 frame into out, which has room for size bytes
+static vpx_codec_err_t lk_vpx_encode(lk_vpx_encoder *e, unsigned char *frame, int keyframe, unsigned char *out, size_t size, size_t *written) {
+	vpx_image_t image;
+	vpx_img_wrap(&image, VPX_IMG_FMT_I420, e->width, e->height, 1, frame);
+	vpx_codec_err_t err = vpx_codec_encode(&e->codec, &image, e->pts++, 1, keyframe ? VPX_EFLAG_FORCE_KF : 0, VPX_DL_REALTIME);
+	if (err != VPX_CODEC_OK) {
+		return err;
+	}
+	*written = 0;
+	vpx_codec_iter_t iter = NULL;
+	const vpx_codec_cx_pkt_t *pkt;
+	while ((pkt = vpx_codec_get_cx_data(&e->codec, &iter)) != NULL) {
+		if (pkt->kind != VPX_CODEC_CX_FRAME_PKT) {
+			continue;
+		}
+		if (*written + pkt->data.frame.sz > size) {
+			return VPX_CODEC_MEM_ERROR;
+		}
+		memcpy(out + *written, pkt->data.frame.buf, pkt->data.frame.sz);
+		*written += pkt->data.frame.sz;
+	}
+	return VPX_CODEC_OK;
+}
+*/
+import "C"
+
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+)
+
+// CanEncodeVideo reports whether raw video, from Y4M files or test patterns, can be encoded
+const CanEncodeVideo = true
+
+// vpxEncoder encodes VP8 or VP9 with libvpx, freeing it once unreachable
+type vpxEncoder struct {
+	encoder *C.lk_vpx_encoder
+	// output of the last frame, a frame never compresses to more than its raw size
+	out []byte
+}
+
+func newVPXEncoder(codec string, width, height, fps, kbps int) (videoEncoder, error) {
+	encoder := (*C.lk_vpx_encoder)(C.calloc(1, C.sizeof_lk_vpx_encoder))
+	vp9 := C.int(0)
+	if codec == vp9Codec {
+		vp9 = 1
+	}
+	if err := C.lk_vpx_open(encoder, vp9, C.uint(width), C.uint(height), C.uint(fps), C.uint(kbps)); err != C.VPX_CODEC_OK {
+		C.free(unsafe.Pointer(encoder))
+		return nil, vpxError(err)
+	}
+	e := &vpxEncoder{
+		encoder: encoder,
+		out:     make([]byte, width*height*3/2+1024),
+	}
+	runtime.SetFinalizer(e, (*vpxEncoder).close)
+	return e, nil
+}
+
+func (e *vpxEncoder) encode(frame []byte, keyframe bool) ([]byte, error) {
+	force := C.int(0)
+	if keyframe {
+		force = 1
+	}
+	var written C.size_t
+	err := C.lk_vpx_encode(e.encoder, (*C.uchar)(unsafe.Pointer(&frame[0])), force,
+		(*C.uchar)(unsafe.Pointer(&e.out[0])), C.size_t(len(e.out)), &written)
+	// the finalizer frees the encoder, e must stay reachable until libvpx is done with it
+	runtime.KeepAlive(e)
+	if err != C.VPX_CODEC_OK {
+		return nil, vpxError(err)
+	}
+	if written == 0 {
+		return nil, nil
+	}
+	// the looper's consumer may keep the sample after the next frame is encoded
+	return append([]byte(nil), e.out[:written]...), nil
+}
+
+func (e *vpxEncoder) close() {
+	C.vpx_codec_destroy(&e.encoder.codec)
+	C.free(unsafe.Pointer(e.encoder))
+}
+
+func vpxError(err C.vpx_codec_err_t) error {
+	return fmt.Errorf("libvpx: %s", C.GoString(C.vpx_codec_err_to_string(err)))
+}
@@ -0,0 +1,28 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !vpx || !cgo
+
+package provider
+
+import "errors"
+
+// CanEncodeVideo reports whether raw video, from Y4M files or test patterns, can be encoded
+const CanEncodeVideo = false
+
+var errNoVPX = errors.New("encoding raw video needs libvpx, build lk with CGO_ENABLED=1 and -tags vpx")
+
+func newVPXEncoder(_ string, _, _, _, _ int) (videoEncoder, error) {
+	return nil, errNoVPX
+}
@@ -0,0 +1,42 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build vpx && cgo
+
+package provider
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVPXEncoder(t *testing.T) {
+	for _, codec := range []string{vp8Codec, vp9Codec} {
+		encoder, err := newVPXEncoder(codec, 320, 180, 30, 300)
+		require.NoError(t, err)
+		frame := bytes.Repeat([]byte{0x80}, 320*180*3/2)
+		for i := 0; i < 5; i++ {
+			frame[i*100] = 0xff
+			data, err := encoder.encode(frame, i == 0)
+			require.NoError(t, err)
+			require.NotEmpty(t, data, codec)
+			if codec == vp8Codec {
+				// the frame tag's low bit is clear on keyframes
+				require.Equal(t, i == 0, data[0]&1 == 0)
+			}
+		}
+	}
+}
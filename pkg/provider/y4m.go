@@ -0,0 +1,208 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pion/webrtc/v4"
+	"github.com/pion/webrtc/v4/pkg/media"
+
+	"github.com/livekit/protocol/livekit"
+	lksdk "github.com/livekit/server-sdk-go/v2"
+)
+
+const (
+	y4mSignature = "YUV4MPEG2 "
+	// bits per pixel that raw and generated video is encoded at, about 2 Mbps for 720p30
+	rawBitsPerPixel = 0.07
+	minRawKbps      = 100
+)
+
+// y4mVideo is the 4:2:0 frames of a YUV4MPEG2 file, which loopers encode as they send them
+type y4mVideo struct {
+	frames [][]byte
+	// spec of the encoded video, without a codec since that is chosen by each looper
+	spec *videoSpec
+}
+
+func isY4M(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(y4mSignature))
+}
+
+// probeY4M reads the dimensions, frame rate and frames of a YUV4MPEG2 file, and picks the bitrate
+// to encode it at
+func probeY4M(path string, data []byte) (*y4mVideo, error) {
+	end := bytes.IndexByte(data, '\n')
+	if !isY4M(data) || end < 0 {
+		return nil, fmt.Errorf("%s is not a Y4M file", path)
+	}
+	spec := &videoSpec{prefix: path}
+	fpsNum, fpsDen := 0, 0
+	for _, param := range strings.Fields(string(data[len(y4mSignature):end])) {
+		value := param[1:]
+		var err error
+		switch param[0] {
+		case 'W':
+			spec.width, err = strconv.Atoi(value)
+		case 'H':
+			spec.height, err = strconv.Atoi(value)
+		case 'F':
+			num, den, ok := strings.Cut(value, ":")
+			if !ok {
+				return nil, fmt.Errorf("%s has an invalid frame rate %q", path, value)
+			}
+			if fpsNum, err = strconv.Atoi(num); err == nil {
+				fpsDen, err = strconv.Atoi(den)
+			}
+		case 'C':
+			// 8 bit 4:2:0 with any chroma siting
+			switch value {
+			case "420", "420jpeg", "420mpeg2", "420paldv":
+			default:
+				return nil, fmt.Errorf("%s has unsupported colorspace %s, expected 420", path, value)
+			}
+		case 'I':
+			if value != "p" && value != "?" {
+				return nil, fmt.Errorf("%s is interlaced, expected progressive frames", path)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s has an invalid header parameter %q", path, param)
+		}
+	}
+	if spec.width <= 0 || spec.height <= 0 || spec.width%2 != 0 || spec.height%2 != 0 {
+		return nil, fmt.Errorf("%s has unsupported dimensions %dx%d, expected even ones", path, spec.width, spec.height)
+	}
+	if fpsNum <= 0 || fpsDen <= 0 {
+		return nil, fmt.Errorf("%s has no frame rate", path)
+	}
+	spec.fps = (fpsNum + fpsDen/2) / fpsDen
+	if spec.fps < 1 || spec.fps > maxFileFPS {
+		return nil, fmt.Errorf("%s has unsupported frame rate %d, expected 1-%d fps", path, spec.fps, maxFileFPS)
+	}
+	spec.kbps = rawKbps(spec.width, spec.height, spec.fps)
+
+	frameSize := spec.width * spec.height * 3 / 2
+	video := &y4mVideo{spec: spec}
+	for rest := data[end+1:]; len(rest) > 0; {
+		header := bytes.IndexByte(rest, '\n')
+		if header < 0 || !bytes.HasPrefix(rest, []byte("FRAME")) {
+			return nil, fmt.Errorf("could not read %s: invalid frame header", path)
+		}
+		rest = rest[header+1:]
+		if len(rest) < frameSize {
+			return nil, fmt.Errorf("could not read %s: truncated frame", path)
+		}
+		video.frames = append(video.frames, rest[:frameSize:frameSize])
+		rest = rest[frameSize:]
+	}
+	if len(video.frames) < 2 {
+		return nil, fmt.Errorf("%s has too few frames", path)
+	}
+	return video, nil
+}
+
+// rawKbps is the bitrate the tester encodes raw and generated video at
+func rawKbps(width, height, fps int) int {
+	return max(int(float64(width*height*fps)*rawBitsPerPixel/1000), minRawKbps)
+}
+
+// videoEncoder compresses I420 frames for the loopers of raw video
+type videoEncoder interface {
+	// encode compresses the next frame, as a keyframe when forced to. It returns no data when the
+	// encoder drops the frame.
+	encode(frame []byte, keyframe bool) ([]byte, error)
+}
+
+// newVideoEncoder creates an encoder of codec, replaced in tests
+var newVideoEncoder = newVPXEncoder
+
+// Y4MVideoLooper loops over the frames of a Y4M file, encoding them to VP8 or VP9 as it sends
+// them. Every looper has its own encoder, so publishing raw video costs CPU for each publisher.
+type Y4MVideoLooper struct {
+	lksdk.BaseSampleProvider
+	video         *y4mVideo
+	spec          *videoSpec
+	frameDuration time.Duration
+
+	lock    sync.Mutex
+	encoder videoEncoder
+	next    int
+	// a keyframe is forced at the start of each loop
+	keyframe bool
+}
+
+// newY4MVideoLooper encodes video to codec, VP8 when empty
+func newY4MVideoLooper(video *y4mVideo, codec string) (*Y4MVideoLooper, error) {
+	if codec == "" {
+		codec = vp8Codec
+	}
+	if codec != vp8Codec && codec != vp9Codec {
+		return nil, fmt.Errorf("%s is raw video, which can be encoded to vp8 or vp9, not %s", video.spec.prefix, codec)
+	}
+	spec := *video.spec
+	spec.codec = codec
+	encoder, err := newVideoEncoder(codec, spec.width, spec.height, spec.fps, spec.kbps)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode %s: %w", spec.prefix, err)
+	}
+	return &Y4MVideoLooper{
+		video:         video,
+		spec:          &spec,
+		frameDuration: time.Second / time.Duration(spec.fps),
+		encoder:       encoder,
+		keyframe:      true,
+	}, nil
+}
+
+func (l *Y4MVideoLooper) Codec() webrtc.RTPCodecCapability {
+	return vpCapability(l.spec.codec == vp9Codec)
+}
+
+func (l *Y4MVideoLooper) ToLayer(quality livekit.VideoQuality) *livekit.VideoLayer {
+	return l.spec.ToVideoLayer(quality)
+}
+
+func (l *Y4MVideoLooper) NextSample(_ context.Context) (media.Sample, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	data, err := l.encoder.encode(l.video.frames[l.next], l.keyframe)
+	if err != nil {
+		return media.Sample{}, err
+	}
+	l.next++
+	l.keyframe = l.next == len(l.video.frames)
+	if l.keyframe {
+		l.next = 0
+	}
+	// a dropped frame still takes its time
+	return media.Sample{Data: data, Duration: l.frameDuration}, nil
+}
+
+// SeekToKeyframeAfter makes the looper start at the given fraction (0-1) of the file's frames.
+// Any frame can start the stream, since the first one is encoded as a keyframe.
+func (l *Y4MVideoLooper) SeekToKeyframeAfter(fraction float64) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.next = min(int(fraction*float64(len(l.video.frames))), len(l.video.frames)-1)
+	l.keyframe = true
+}
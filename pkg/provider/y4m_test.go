@@ -0,0 +1,135 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package provider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeEncoder "encodes" a frame to its first byte, marking keyframes
+type fakeEncoder struct {
+	codec string
+	kbps  int
+}
+
+func (e *fakeEncoder) encode(frame []byte, keyframe bool) ([]byte, error) {
+	if keyframe {
+		return []byte{'K', frame[0]}, nil
+	}
+	return []byte{'D', frame[0]}, nil
+}
+
+func useFakeEncoder(t *testing.T) {
+	newVideoEncoder = func(codec string, _, _, _, kbps int) (videoEncoder, error) {
+		return &fakeEncoder{codec: codec, kbps: kbps}, nil
+	}
+	t.Cleanup(func() { newVideoEncoder = newVPXEncoder })
+}
+
+// writeY4M writes frames filled with their index
+func writeY4M(header string, width, height, frames int) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(header + "\n")
+	for i := 0; i < frames; i++ {
+		buf.WriteString("FRAME\n")
+		buf.Write(bytes.Repeat([]byte{byte(i)}, width*height*3/2))
+	}
+	return buf.Bytes()
+}
+
+func TestProbeY4M(t *testing.T) {
+	video, err := probeY4M("test.y4m", writeY4M("YUV4MPEG2 W320 H180 F30000:1001 Ip A1:1 C420jpeg XYSCSS=420JPEG", 320, 180, 5))
+	require.NoError(t, err)
+	require.Len(t, video.frames, 5)
+	require.Equal(t, 320, video.spec.width)
+	require.Equal(t, 180, video.spec.height)
+	require.Equal(t, 30, video.spec.fps)
+	require.Equal(t, 120, video.spec.kbps)
+	require.Equal(t, byte(4), video.frames[4][0])
+
+	video, err = probeY4M("test.y4m", writeY4M("YUV4MPEG2 W1280 H720 F25:1", 1280, 720, 2))
+	require.NoError(t, err)
+	require.Equal(t, 1612, video.spec.kbps)
+	video, err = probeY4M("test.y4m", writeY4M("YUV4MPEG2 W64 H64 F10:1", 64, 64, 2))
+	require.NoError(t, err)
+	require.Equal(t, minRawKbps, video.spec.kbps)
+
+	for header, expected := range map[string]string{
+		"YUV4MPEG2 W320 H180 F30:1 C444":    "test.y4m has unsupported colorspace 444, expected 420",
+		"YUV4MPEG2 W320 H180 F30:1 C420p10": "test.y4m has unsupported colorspace 420p10, expected 420",
+		"YUV4MPEG2 W320 H180 F30:1 It":      "test.y4m is interlaced, expected progressive frames",
+		"YUV4MPEG2 W321 H180 F30:1":         "test.y4m has unsupported dimensions 321x180, expected even ones",
+		"YUV4MPEG2 W320 H180":               "test.y4m has no frame rate",
+		"YUV4MPEG2 W320 H180 F240:1":        "test.y4m has unsupported frame rate 240, expected 1-120 fps",
+		"YUV4MPEG2 Wabc H180 F30:1":         `test.y4m has an invalid header parameter "Wabc"`,
+	} {
+		_, err = probeY4M("test.y4m", writeY4M(header, 320, 180, 3))
+		require.EqualError(t, err, expected, header)
+	}
+
+	data := writeY4M("YUV4MPEG2 W320 H180 F30:1", 320, 180, 3)
+	_, err = probeY4M("test.y4m", data[:len(data)-1])
+	require.EqualError(t, err, "could not read test.y4m: truncated frame")
+	_, err = probeY4M("test.y4m", writeY4M("YUV4MPEG2 W320 H180 F30:1", 320, 180, 1))
+	require.EqualError(t, err, "test.y4m has too few frames")
+}
+
+func TestY4MVideoLooper(t *testing.T) {
+	useFakeEncoder(t)
+	path := filepath.Join(t.TempDir(), "test.y4m")
+	require.NoError(t, os.WriteFile(path, writeY4M("YUV4MPEG2 W320 H180 F30:1", 320, 180, 4), 0o644))
+
+	loopers, err := CreateVideoLoopersFromFiles([]string{path}, "high", "", false)
+	require.NoError(t, err)
+	looper, ok := loopers[0].(*Y4MVideoLooper)
+	require.True(t, ok)
+	require.Equal(t, "video/vp8", looper.Codec().MimeType)
+	require.EqualValues(t, 120_000, looper.ToLayer(0).Bitrate)
+
+	// keyframes at the start of each loop
+	var frames []string
+	for i := 0; i < 6; i++ {
+		sample, err := looper.NextSample(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, time.Second/30, sample.Duration)
+		frames = append(frames, fmt.Sprintf("%c%d", sample.Data[0], sample.Data[1]))
+	}
+	require.Equal(t, []string{"K0", "D1", "D2", "D3", "K0", "D1"}, frames)
+
+	looper.SeekToKeyframeAfter(0.5)
+	sample, err := looper.NextSample(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []byte{'K', 2}, sample.Data)
+
+	loopers, err = CreateVideoLoopersFromFiles([]string{path}, "high", "vp9", false)
+	require.NoError(t, err)
+	require.Equal(t, "video/vp9", loopers[0].Codec().MimeType)
+	_, err = CreateVideoLoopersFromFiles([]string{path}, "high", "h264", false)
+	require.EqualError(t, err, path+" is raw video, which can be encoded to vp8 or vp9, not h264")
+
+	// a raw layer encoded to VP8 can't be mixed with H.264 ones
+	h264Path := filepath.Join(t.TempDir(), "test.h264")
+	require.NoError(t, os.WriteFile(h264Path, writeH264AnnexB(testSPS(66, 320, 240, 30), 2, 10), 0o644))
+	_, err = CreateVideoLoopersFromFiles([]string{h264Path, path}, "high", "", true)
+	require.EqualError(t, err, path+" is vp8, but other video files are h264")
+}
@@ -0,0 +1,243 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package testharness runs synthetic LiveKit participants from Go integration tests. The
+// participants publish the load tester's media, and a Report tells what each of them received,
+// so other projects can check a deployment without shelling out to lk.
+package testharness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"github.com/livekit/livekit-cli/v2/pkg/loadtester"
+	"github.com/livekit/protocol/auth"
+)
+
+const (
+	// DefaultTimeout is how long Wait gives subscribers to receive every track
+	DefaultTimeout = 30 * time.Second
+
+	pollInterval = 250 * time.Millisecond
+)
+
+// TokenFunc supplies the access token a participant joins with. The room and identity the
+// token grants take precedence over the requested ones.
+type TokenFunc func(room, identity string) (string, error)
+
+// Participant is a synthetic participant of the harness
+type Participant struct {
+	// identity to join as, participant_N when empty
+	Identity string
+	// publish an audio track
+	Audio bool
+	// publish a video track at high, medium or low resolution, no video when empty
+	Video string
+	// codec of the published video, any of the embedded ones when empty
+	VideoCodec string
+	Simulcast  bool
+	// subscribe to every track the other participants publish
+	Subscribe bool
+}
+
+// Config is where participants join and what they do there
+type Config struct {
+	URL string
+	// supplies participant tokens, minted with APIKey and APISecret when nil
+	Token     TokenFunc
+	APIKey    string
+	APISecret string
+	// room to join, a random one when empty
+	Room         string
+	Participants []Participant
+	// how long Wait gives subscribers to receive every track, DefaultTimeout when 0
+	Timeout time.Duration
+}
+
+// Harness is a set of joined participants
+type Harness struct {
+	config  Config
+	testers []*loadtester.LoadTester
+	// tracks each participant should receive
+	expected []int
+}
+
+// Start joins every participant and publishes its tracks. Participants that already joined
+// leave again when one of them fails.
+func Start(ctx context.Context, config Config) (*Harness, error) {
+	if config.URL == "" {
+		return nil, errors.New("URL is required")
+	}
+	if len(config.Participants) == 0 {
+		return nil, errors.New("no participants")
+	}
+	if config.Token == nil {
+		if config.APIKey == "" || config.APISecret == "" {
+			return nil, errors.New("either a token function or API key and secret are required")
+		}
+		config.Token = MintTokens(config.APIKey, config.APISecret)
+	}
+	if config.Room == "" {
+		config.Room = fmt.Sprintf("testharness_%d", rand.IntN(1000000))
+	}
+	if config.Timeout == 0 {
+		config.Timeout = DefaultTimeout
+	}
+
+	h := &Harness{
+		config:   config,
+		expected: expectedTracks(config.Participants),
+	}
+	for i, p := range config.Participants {
+		if err := ctx.Err(); err != nil {
+			h.Stop()
+			return nil, err
+		}
+		tester, err := h.join(i, p)
+		if err != nil {
+			h.Stop()
+			return nil, fmt.Errorf("participant %s: %w", participantIdentity(i, p), err)
+		}
+		h.testers = append(h.testers, tester)
+	}
+	return h, nil
+}
+
+func (h *Harness) join(i int, p Participant) (*loadtester.LoadTester, error) {
+	token, err := h.config.Token(h.config.Room, participantIdentity(i, p))
+	if err != nil {
+		return nil, fmt.Errorf("could not get token: %w", err)
+	}
+	params := loadtester.TesterParams{
+		URL:          h.config.URL,
+		Sequence:     i,
+		Subscribe:    p.Subscribe,
+		SubscribeAll: p.Subscribe,
+	}
+	if err = params.SetToken(token); err != nil {
+		return nil, err
+	}
+	tester := loadtester.NewLoadTester(params)
+	if err = tester.Start(); err != nil {
+		return nil, err
+	}
+	if p.Audio {
+		if _, err = tester.PublishAudioTrack("audio"); err != nil {
+			tester.Stop()
+			return nil, err
+		}
+	}
+	if p.Video != "" {
+		if p.Simulcast {
+			_, err = tester.PublishSimulcastTrack("video-simulcast", p.Video, p.VideoCodec)
+		} else {
+			_, err = tester.PublishVideoTrack("video", p.Video, p.VideoCodec, false, -1, -1, -1, -1)
+		}
+		if err != nil {
+			tester.Stop()
+			return nil, err
+		}
+	}
+	return tester, nil
+}
+
+// Wait returns once every subscriber receives all tracks of the other participants, or with an
+// error when they don't within the configured timeout
+func (h *Harness) Wait(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, h.config.Timeout)
+	defer cancel()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		missing := 0
+		for _, r := range h.Results() {
+			if r.Tracks < r.ExpectedTracks {
+				missing++
+			}
+		}
+		if missing == 0 {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%d participants are missing tracks: %w", missing, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// Results is what each participant received so far, in the order of Config.Participants
+func (h *Harness) Results() []*loadtester.TesterResults {
+	results := make([]*loadtester.TesterResults, 0, len(h.testers))
+	for i, tester := range h.testers {
+		r := tester.Results()
+		r.ExpectedTracks = h.expected[i]
+		results = append(results, r)
+	}
+	return results
+}
+
+// Check asserts the expectations on what each participant received so far
+func (h *Harness) Check(exp Expectations) *Report {
+	return check(h.Results(), exp)
+}
+
+// Stop makes every participant leave the room
+func (h *Harness) Stop() {
+	for _, tester := range h.testers {
+		tester.Stop()
+	}
+}
+
+// MintTokens returns a TokenFunc signing tokens with an API key and secret
+func MintTokens(apiKey, apiSecret string) TokenFunc {
+	return func(room, identity string) (string, error) {
+		at := auth.NewAccessToken(apiKey, apiSecret)
+		at.SetVideoGrant(&auth.VideoGrant{RoomJoin: true, Room: room}).
+			SetIdentity(identity)
+		return at.ToJWT()
+	}
+}
+
+func participantIdentity(i int, p Participant) string {
+	if p.Identity != "" {
+		return p.Identity
+	}
+	return fmt.Sprintf("participant_%d", i)
+}
+
+// expectedTracks counts the tracks each subscriber should receive from the others
+func expectedTracks(participants []Participant) []int {
+	published := make([]int, len(participants))
+	total := 0
+	for i, p := range participants {
+		if p.Audio {
+			published[i]++
+		}
+		if p.Video != "" {
+			published[i]++
+		}
+		total += published[i]
+	}
+	expected := make([]int, len(participants))
+	for i, p := range participants {
+		if p.Subscribe {
+			expected[i] = total - published[i]
+		}
+	}
+	return expected
+}
@@ -0,0 +1,63 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testharness
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/livekit-cli/v2/pkg/loadtester"
+)
+
+func TestExpectedTracks(t *testing.T) {
+	expected := expectedTracks([]Participant{
+		{Audio: true, Video: "high", Subscribe: true},
+		{Audio: true},
+		{Subscribe: true},
+		{Video: "low"},
+	})
+	require.Equal(t, []int{2, 0, 4, 0}, expected)
+}
+
+func TestMintTokens(t *testing.T) {
+	token, err := MintTokens("key", "secret")("room_1", "alice")
+	require.NoError(t, err)
+
+	params := loadtester.TesterParams{}
+	require.NoError(t, params.SetToken(token))
+	require.Equal(t, "room_1", params.Room)
+	require.Equal(t, "alice", loadtester.NewLoadTester(params).Identity())
+}
+
+func TestStartConfig(t *testing.T) {
+	ctx := context.Background()
+	_, err := Start(ctx, Config{Participants: []Participant{{}}})
+	require.EqualError(t, err, "URL is required")
+	_, err = Start(ctx, Config{URL: "ws://localhost:7880"})
+	require.EqualError(t, err, "no participants")
+	_, err = Start(ctx, Config{URL: "ws://localhost:7880", Participants: []Participant{{}}})
+	require.EqualError(t, err, "either a token function or API key and secret are required")
+
+	_, err = Start(ctx, Config{
+		URL:          "ws://localhost:7880",
+		Participants: []Participant{{Identity: "bob"}},
+		Token: func(room, identity string) (string, error) {
+			return "not-a-token", nil
+		},
+	})
+	require.EqualError(t, err, "participant bob: not a JWT")
+}
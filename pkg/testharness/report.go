@@ -0,0 +1,129 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testharness
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/livekit/livekit-cli/v2/pkg/loadtester"
+)
+
+// Expectations are checked against each participant's results. Unset expectations are
+// not checked.
+type Expectations struct {
+	// subscribers receive every track of the other participants
+	AllTracks bool
+	// highest packet loss of a participant, as a fraction
+	MaxPacketLoss float64
+	// lowest bitrate a subscriber receives, in bps
+	MinBitrate float64
+	// longest average time from publication to the first frame
+	MaxFirstFrame time.Duration
+	// most decode or receive errors of a participant
+	MaxErrors *int64
+}
+
+// Assertion is the outcome of one expectation for one participant
+type Assertion struct {
+	Participant string `json:"participant"`
+	Check       string `json:"check"`
+	Passed      bool   `json:"passed"`
+	Message     string `json:"message"`
+}
+
+// Report holds the results the assertions were checked against
+type Report struct {
+	Results    []*loadtester.TesterResults `json:"results"`
+	Assertions []*Assertion                `json:"assertions"`
+}
+
+// TB is the part of testing.TB a report is asserted with
+type TB interface {
+	Helper()
+	Errorf(format string, args ...any)
+}
+
+func check(results []*loadtester.TesterResults, exp Expectations) *Report {
+	report := &Report{Results: results}
+	add := func(r *loadtester.TesterResults, check string, passed bool, format string, args ...any) {
+		report.Assertions = append(report.Assertions, &Assertion{
+			Participant: r.Name,
+			Check:       check,
+			Passed:      passed,
+			Message:     fmt.Sprintf(format, args...),
+		})
+	}
+	for _, r := range results {
+		subscriber := r.ExpectedTracks > 0
+		if exp.AllTracks && subscriber {
+			add(r, "tracks", r.Tracks >= r.ExpectedTracks, "received %d of %d tracks", r.Tracks, r.ExpectedTracks)
+		}
+		if exp.MaxPacketLoss > 0 && subscriber {
+			add(r, "packet loss", r.LossRate <= exp.MaxPacketLoss, "packet loss %.2f%%, at most %.2f%%",
+				r.LossRate*100, exp.MaxPacketLoss*100)
+		}
+		if exp.MinBitrate > 0 && subscriber {
+			add(r, "bitrate", r.Bitrate >= exp.MinBitrate, "bitrate %.0fbps, at least %.0fbps", r.Bitrate, exp.MinBitrate)
+		}
+		if exp.MaxFirstFrame > 0 && subscriber {
+			maxMs := float64(exp.MaxFirstFrame.Milliseconds())
+			add(r, "first frame", r.AvgFirstFrameMs > 0 && r.AvgFirstFrameMs <= maxMs,
+				"first frame after %.0fms, at most %.0fms", r.AvgFirstFrameMs, maxMs)
+		}
+		if exp.MaxErrors != nil {
+			add(r, "errors", r.Errors <= *exp.MaxErrors, "%d errors, at most %d", r.Errors, *exp.MaxErrors)
+		}
+	}
+	return report
+}
+
+// Failed returns the assertions that did not pass
+func (r *Report) Failed() []*Assertion {
+	var failed []*Assertion
+	for _, a := range r.Assertions {
+		if !a.Passed {
+			failed = append(failed, a)
+		}
+	}
+	return failed
+}
+
+// Err names every failed assertion, nil when all passed
+func (r *Report) Err() error {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return nil
+	}
+	lines := make([]string, 0, len(failed))
+	for _, a := range failed {
+		lines = append(lines, a.String())
+	}
+	return fmt.Errorf("%d of %d assertions failed:\n  %s", len(failed), len(r.Assertions), strings.Join(lines, "\n  "))
+}
+
+// Assert reports every failed assertion to t, and returns whether all passed
+func (r *Report) Assert(t TB) bool {
+	t.Helper()
+	for _, a := range r.Failed() {
+		t.Errorf("%s", a)
+	}
+	return len(r.Failed()) == 0
+}
+
+func (a *Assertion) String() string {
+	return fmt.Sprintf("%s %s: %s", a.Participant, a.Check, a.Message)
+}
@@ -0,0 +1,67 @@
+// Copyright 2025 LiveKit, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testharness
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/livekit/livekit-cli/v2/pkg/loadtester"
+)
+
+type fakeTB struct {
+	errors []string
+}
+
+func (t *fakeTB) Helper() {}
+
+func (t *fakeTB) Errorf(format string, args ...any) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+func TestCheck(t *testing.T) {
+	results := []*loadtester.TesterResults{
+		{Name: "pub", Tracks: 0, ExpectedTracks: 0},
+		{Name: "sub_0", Tracks: 2, ExpectedTracks: 2, LossRate: 0.001, Bitrate: 900_000, AvgFirstFrameMs: 300},
+		{Name: "sub_1", Tracks: 1, ExpectedTracks: 2, LossRate: 0.2, Bitrate: 100_000},
+	}
+	maxErrors := int64(0)
+	report := check(results, Expectations{
+		AllTracks:     true,
+		MaxPacketLoss: 0.05,
+		MinBitrate:    500_000,
+		MaxFirstFrame: time.Second,
+		MaxErrors:     &maxErrors,
+	})
+	// publishers only get the errors check
+	require.Len(t, report.Assertions, 11)
+
+	failed := report.Failed()
+	require.Len(t, failed, 4)
+	for _, a := range failed {
+		require.Equal(t, "sub_1", a.Participant)
+	}
+	require.Equal(t, "sub_1 tracks: received 1 of 2 tracks", failed[0].String())
+	require.ErrorContains(t, report.Err(), "4 of 11 assertions failed")
+
+	tb := &fakeTB{}
+	require.False(t, report.Assert(tb))
+	require.Len(t, tb.errors, 4)
+
+	require.NoError(t, check(results[:2], Expectations{AllTracks: true}).Err())
+}
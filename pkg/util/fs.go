@@ -18,7 +18,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"path"
 	"path/filepath"
 
 	"github.com/livekit/protocol/utils/guid"
@@ -103,7 +102,7 @@ func MoveDir(src, dest string) error {
 // and a function to clean up the temporary path that should always be deferred
 // in the case of a failure to relocate.
 func UseTempPath(permanentPath string) (string, func() error, func() error) {
-	tempPath := path.Join(os.TempDir(), guid.New("LK_"))
+	tempPath := filepath.Join(os.TempDir(), guid.New("LK_"))
 	relocate := func() error {
 		return MoveDir(tempPath, permanentPath)
 	}